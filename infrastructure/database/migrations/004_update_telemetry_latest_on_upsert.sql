@@ -0,0 +1,27 @@
+-- Migration: Keep latest values in sync with idempotent inserts
+-- Description: Telemetry inserts now use ON CONFLICT (overwrite / keep-max
+-- policies), which updates rows in place. Refresh device_telemetry_latest on
+-- UPDATE as well, and accept a new value for the same timestamp.
+
+CREATE OR REPLACE FUNCTION update_telemetry_latest()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO device_telemetry_latest (device_id, metric_name, time, value, unit)
+    VALUES (NEW.device_id, NEW.metric_name, NEW.time, NEW.value, NEW.unit)
+    ON CONFLICT (device_id, metric_name)
+    DO UPDATE SET
+        time = EXCLUDED.time,
+        value = EXCLUDED.value,
+        unit = EXCLUDED.unit
+    WHERE EXCLUDED.time >= device_telemetry_latest.time;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_update_telemetry_latest ON device_telemetry;
+
+CREATE TRIGGER trg_update_telemetry_latest
+    AFTER INSERT OR UPDATE ON device_telemetry
+    FOR EACH ROW
+    EXECUTE FUNCTION update_telemetry_latest();
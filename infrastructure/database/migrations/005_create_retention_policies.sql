@@ -0,0 +1,67 @@
+-- Migration: Per-device-type and per-metric retention policies
+-- Description: Replaces the global 90-day retention policy with policies stored
+-- in the database and enforced by the data-collector retention job, which can
+-- downsample raw telemetry into rollups before deleting it.
+
+-- ============================================
+-- REMOVE GLOBAL RETENTION
+-- ============================================
+
+-- Retention is now enforced per policy by the data-collector
+SELECT remove_retention_policy('device_telemetry', if_exists => TRUE);
+
+-- ============================================
+-- RETENTION POLICIES
+-- ============================================
+
+-- A policy applies to a device type and/or a metric (NULL = any).
+-- When several policies match a row, the most specific one wins:
+-- type + metric > metric only > type only > default (both NULL).
+-- Durations are PostgreSQL intervals stored as text (e.g. '7 days', '2 years').
+CREATE TABLE telemetry_retention_policies (
+    id                   UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    device_type          VARCHAR(100),
+    metric_name          VARCHAR(100),
+    raw_retention        TEXT NOT NULL,
+    downsample_interval  TEXT,
+    downsample_retention TEXT,
+    created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT downsample_retention_requires_interval
+        CHECK (downsample_retention IS NULL OR downsample_interval IS NOT NULL)
+);
+
+-- One policy per (device_type, metric_name) scope
+CREATE UNIQUE INDEX idx_retention_policies_scope
+    ON telemetry_retention_policies (COALESCE(device_type, ''), COALESCE(metric_name, ''));
+
+-- Default policy, equivalent to the previous global retention
+INSERT INTO telemetry_retention_policies (device_type, metric_name, raw_retention)
+VALUES (NULL, NULL, '90 days');
+
+-- ============================================
+-- DOWNSAMPLED TELEMETRY (Hypertable)
+-- ============================================
+
+-- Rollups written by the retention job before raw rows are deleted.
+-- Unlike continuous aggregates, they survive the deletion of their source data.
+CREATE TABLE telemetry_downsampled (
+    bucket          TIMESTAMPTZ NOT NULL,
+    device_id       UUID NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    metric_name     VARCHAR(100) NOT NULL,
+    bucket_interval TEXT NOT NULL,
+    avg_value       DOUBLE PRECISION NOT NULL,
+    min_value       DOUBLE PRECISION NOT NULL,
+    max_value       DOUBLE PRECISION NOT NULL,
+    sample_count    BIGINT NOT NULL,
+
+    PRIMARY KEY (device_id, metric_name, bucket)
+);
+
+SELECT create_hypertable(
+    'telemetry_downsampled',
+    'bucket',
+    chunk_time_interval => INTERVAL '30 days',
+    if_not_exists => TRUE
+);
@@ -0,0 +1,35 @@
+-- Migration: Derived metrics (virtual sensors)
+-- Description: Definitions of metrics computed by the data-collector at ingest
+-- time from other metrics of the same device. Derived values are stored as
+-- ordinary rows in device_telemetry.
+
+-- ============================================
+-- DERIVED METRIC DEFINITIONS
+-- ============================================
+
+-- kind = 'expression':     expression over the device's metrics,
+--                          e.g. dew_point = f(temperature, humidity)
+-- kind = 'integral':       time integral of source_metric, in source unit x hours
+--                          (power in W -> energy in Wh)
+-- kind = 'moving_average': average of source_metric over the last window
+CREATE TABLE derived_metric_definitions (
+    id            UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    device_type   VARCHAR(100) NOT NULL,
+    name          VARCHAR(100) NOT NULL,
+    kind          VARCHAR(20) NOT NULL,
+    expression    TEXT,
+    source_metric VARCHAR(100),
+    window_size   TEXT,
+    unit          VARCHAR(50),
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT derived_metric_unique UNIQUE (device_type, name),
+    CONSTRAINT derived_metric_kind CHECK (kind IN ('expression', 'integral', 'moving_average')),
+    CONSTRAINT derived_metric_expression CHECK (kind <> 'expression' OR expression IS NOT NULL),
+    CONSTRAINT derived_metric_source CHECK (kind = 'expression' OR source_metric IS NOT NULL),
+    CONSTRAINT derived_metric_window CHECK (kind <> 'moving_average' OR window_size IS NOT NULL)
+);
+
+COMMENT ON TABLE derived_metric_definitions IS 'Metrics computed at ingest time from other metrics (virtual sensors)';
+COMMENT ON COLUMN derived_metric_definitions.window_size IS 'Moving average window as a Go duration (e.g. 15m)';
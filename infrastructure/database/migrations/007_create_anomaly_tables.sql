@@ -0,0 +1,49 @@
+-- Migration: Streaming anomaly detection
+-- Description: Detector state persisted by the data-collector so that it
+-- survives restarts, and the anomalies it detects.
+
+-- ============================================
+-- DETECTOR STATE
+-- ============================================
+
+-- Rolling statistics per (device, metric), flushed periodically by the data-collector
+CREATE TABLE anomaly_detector_state (
+    device_id    UUID NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    metric_name  VARCHAR(100) NOT NULL,
+    ewma_mean    DOUBLE PRECISION NOT NULL,
+    ewma_var     DOUBLE PRECISION NOT NULL,
+    sample_count BIGINT NOT NULL,
+    last_time    TIMESTAMPTZ NOT NULL,
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (device_id, metric_name)
+);
+
+-- ============================================
+-- ANOMALIES (Hypertable)
+-- ============================================
+
+CREATE TABLE telemetry_anomalies (
+    time        TIMESTAMPTZ NOT NULL,
+    device_id   UUID NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    metric_name VARCHAR(100) NOT NULL,
+    value       DOUBLE PRECISION NOT NULL,
+    expected    DOUBLE PRECISION NOT NULL,
+    score       DOUBLE PRECISION NOT NULL,
+    method      VARCHAR(20) NOT NULL,
+
+    PRIMARY KEY (device_id, metric_name, time)
+);
+
+SELECT create_hypertable(
+    'telemetry_anomalies',
+    'time',
+    chunk_time_interval => INTERVAL '7 days',
+    if_not_exists => TRUE
+);
+
+CREATE INDEX idx_anomalies_time ON telemetry_anomalies (time DESC);
+
+COMMENT ON TABLE anomaly_detector_state IS 'EWMA statistics of the data-collector anomaly detector';
+COMMENT ON TABLE telemetry_anomalies IS 'Telemetry points scored as anomalous at ingest time';
+COMMENT ON COLUMN telemetry_anomalies.score IS 'Signed z-score of the point against the expected value';
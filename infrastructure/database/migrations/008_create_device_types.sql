@@ -0,0 +1,46 @@
+-- Migration: Device type registry and metric catalog
+-- Description: Device types declare the metrics their devices report, with
+-- unit, data type, valid range and expected reporting interval. The
+-- data-collector validates incoming points against this catalog.
+
+-- ============================================
+-- DEVICE TYPES
+-- ============================================
+
+-- devices.type stays free-form: a device whose type is not registered is
+-- accepted, its telemetry is simply not validated.
+CREATE TABLE device_types (
+    name         VARCHAR(100) PRIMARY KEY,
+    display_name VARCHAR(255) NOT NULL,
+    description  TEXT,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT device_type_name_not_empty CHECK (name <> '')
+);
+
+-- ============================================
+-- METRIC CATALOG
+-- ============================================
+
+CREATE TABLE device_type_metrics (
+    device_type                VARCHAR(100) NOT NULL REFERENCES device_types(name) ON DELETE CASCADE,
+    name                       VARCHAR(100) NOT NULL,
+    display_name               VARCHAR(255) NOT NULL,
+    unit                       VARCHAR(50),
+    data_type                  VARCHAR(20) NOT NULL DEFAULT 'float',
+    min_value                  DOUBLE PRECISION,
+    max_value                  DOUBLE PRECISION,
+    reporting_interval_seconds INTEGER,
+
+    PRIMARY KEY (device_type, name),
+    CONSTRAINT metric_data_type CHECK (data_type IN ('float', 'integer', 'boolean')),
+    CONSTRAINT metric_range CHECK (min_value IS NULL OR max_value IS NULL OR min_value <= max_value),
+    CONSTRAINT metric_interval_positive CHECK (reporting_interval_seconds IS NULL OR reporting_interval_seconds > 0)
+);
+
+COMMENT ON TABLE device_types IS 'Registered device types';
+COMMENT ON COLUMN device_types.name IS 'Type identifier, as stored in devices.type';
+COMMENT ON TABLE device_type_metrics IS 'Metrics expected from devices of a type';
+COMMENT ON COLUMN device_type_metrics.data_type IS 'Value type: float, integer or boolean (0/1)';
+COMMENT ON COLUMN device_type_metrics.reporting_interval_seconds IS 'Expected time between two points';
@@ -0,0 +1,47 @@
+-- Migration: User sessions and rotating refresh tokens
+-- Description: A session is a login of a user on a device. It is kept alive
+-- by single-use refresh tokens: each refresh marks the presented token as
+-- used and issues its successor. The tokens of a session form a family;
+-- presenting an already used token revokes the whole session.
+
+-- ============================================
+-- SESSIONS
+-- ============================================
+
+CREATE TABLE sessions (
+    id           UUID PRIMARY KEY,
+    user_id      UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device       VARCHAR(255) NOT NULL DEFAULT '',
+    ip_address   VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent   TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at   TIMESTAMPTZ NOT NULL,
+    revoked_at   TIMESTAMPTZ
+);
+
+CREATE INDEX idx_sessions_user ON sessions(user_id) WHERE revoked_at IS NULL;
+
+-- ============================================
+-- REFRESH TOKENS
+-- ============================================
+
+-- Only hashes are stored: a database leak does not expose usable tokens
+CREATE TABLE refresh_tokens (
+    token_hash VARCHAR(64) PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    used_at    TIMESTAMPTZ
+);
+
+CREATE INDEX idx_refresh_tokens_session ON refresh_tokens(session_id);
+
+COMMENT ON TABLE sessions IS 'User sessions, one per login';
+COMMENT ON COLUMN sessions.device IS 'Device name given by the client at login';
+COMMENT ON COLUMN sessions.ip_address IS 'Client address of the last use';
+COMMENT ON COLUMN sessions.user_agent IS 'Client user agent of the last use';
+COMMENT ON COLUMN sessions.expires_at IS 'Expiry without use, extended by each refresh';
+COMMENT ON COLUMN sessions.revoked_at IS 'Logout, revocation or token reuse time';
+COMMENT ON TABLE refresh_tokens IS 'Refresh token family of each session';
+COMMENT ON COLUMN refresh_tokens.token_hash IS 'SHA-256 of the token, hex encoded';
+COMMENT ON COLUMN refresh_tokens.used_at IS 'Rotation time: the token may not be presented again';
@@ -0,0 +1,39 @@
+-- Migration: Roles and permissions
+-- Description: A role is a named set of permissions ("devices:read",
+-- "telemetry:read", "*" for all...). The built-in roles admin, user and
+-- device are seeded here and cannot be changed; custom roles are managed
+-- through the User Service. users.role references a role instead of being
+-- checked against a fixed list.
+
+-- ============================================
+-- ROLES
+-- ============================================
+
+CREATE TABLE roles (
+    name        VARCHAR(50) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    built_in    BOOLEAN NOT NULL DEFAULT false,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT role_name_format CHECK (name ~ '^[a-z][a-z0-9_-]*$')
+);
+
+INSERT INTO roles (name, description, permissions, built_in) VALUES
+    ('admin', 'Full access', ARRAY['*'], true),
+    ('user', 'Manage devices and read their telemetry', ARRAY['devices:read', 'devices:write', 'telemetry:read'], true),
+    ('device', 'Device account, access to its own device only', ARRAY[]::TEXT[], true);
+
+-- ============================================
+-- USERS
+-- ============================================
+
+ALTER TABLE users DROP CONSTRAINT valid_role;
+ALTER TABLE users
+    ADD CONSTRAINT users_role_fkey FOREIGN KEY (role) REFERENCES roles(name);
+
+COMMENT ON TABLE roles IS 'Roles assignable to users';
+COMMENT ON COLUMN roles.permissions IS 'Granted permissions, resource:action or * for all';
+COMMENT ON COLUMN roles.built_in IS 'Seeded role, read-only';
+COMMENT ON COLUMN users.role IS 'Role of the user, see roles';
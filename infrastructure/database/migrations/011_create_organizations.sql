@@ -0,0 +1,84 @@
+-- Migration: Organizations (multi-tenancy)
+-- Description: An organization owns devices and their telemetry. Users are
+-- members of one or more organizations with a role in each; users.role
+-- becomes their platform role (admin: every organization). Existing devices
+-- and users are moved to a default organization.
+--
+-- Services scope their queries by organization explicitly. Row-level security
+-- on devices is a safeguard on top: a transaction that sets app.org_id only
+-- sees the devices of that organization.
+
+-- ============================================
+-- ORGANIZATIONS
+-- ============================================
+
+CREATE TABLE organizations (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name       VARCHAR(255) NOT NULL,
+    slug       VARCHAR(63) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT organization_name_not_empty CHECK (name <> ''),
+    CONSTRAINT organization_slug_format CHECK (slug ~ '^[a-z0-9][a-z0-9-]*$')
+);
+
+INSERT INTO organizations (id, name, slug) VALUES
+    ('00000000-0000-0000-0000-000000000001', 'Default', 'default');
+
+-- ============================================
+-- MEMBERS
+-- ============================================
+
+CREATE TABLE organization_members (
+    org_id     UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role       VARCHAR(50) NOT NULL REFERENCES roles(name),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (org_id, user_id)
+);
+
+CREATE INDEX idx_organization_members_user ON organization_members(user_id);
+
+INSERT INTO organization_members (org_id, user_id, role)
+SELECT '00000000-0000-0000-0000-000000000001', id, role FROM users;
+
+-- ============================================
+-- DEVICES
+-- ============================================
+
+ALTER TABLE devices ADD COLUMN org_id UUID REFERENCES organizations(id) ON DELETE CASCADE;
+UPDATE devices SET org_id = '00000000-0000-0000-0000-000000000001';
+ALTER TABLE devices ALTER COLUMN org_id SET NOT NULL;
+
+CREATE INDEX idx_devices_org ON devices(org_id, created_at DESC);
+
+-- Without app.org_id (migrations, retention, internal lookups) every row is
+-- visible; with it, only the rows of the organization. FORCE applies the
+-- policy to the owner of the table too, which the services connect as.
+ALTER TABLE devices ENABLE ROW LEVEL SECURITY;
+ALTER TABLE devices FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY devices_org_isolation ON devices
+    USING (
+        COALESCE(current_setting('app.org_id', true), '') = ''
+        OR org_id = current_setting('app.org_id', true)::uuid
+    )
+    WITH CHECK (
+        COALESCE(current_setting('app.org_id', true), '') = ''
+        OR org_id = current_setting('app.org_id', true)::uuid
+    );
+
+-- ============================================
+-- SESSIONS
+-- ============================================
+
+ALTER TABLE sessions ADD COLUMN org_id UUID REFERENCES organizations(id) ON DELETE CASCADE;
+
+COMMENT ON TABLE organizations IS 'Tenants owning devices and their telemetry';
+COMMENT ON COLUMN organizations.slug IS 'URL-friendly unique identifier';
+COMMENT ON TABLE organization_members IS 'Membership of users in organizations';
+COMMENT ON COLUMN organization_members.role IS 'Role of the user in the organization, see roles';
+COMMENT ON COLUMN devices.org_id IS 'Organization owning the device';
+COMMENT ON COLUMN sessions.org_id IS 'Organization the session is signed in to';
+COMMENT ON COLUMN users.role IS 'Platform role of the user, admin for every organization';
@@ -0,0 +1,124 @@
+-- Migration: Device groups and location hierarchy
+-- Description: Devices are organised in groups, with a static list of
+-- members or a dynamic rule over type and metadata, and placed in a location
+-- hierarchy (site > building > floor > room). Groups and locations belong to
+-- an organization and filter device listings and telemetry queries.
+
+-- ============================================
+-- LOCATIONS
+-- ============================================
+
+-- The level of the parent (site for a building, building for a floor, floor
+-- for a room) is checked by the device-manager.
+CREATE TABLE locations (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id     UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    parent_id  UUID REFERENCES locations(id),
+    kind       VARCHAR(20) NOT NULL,
+    name       VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT location_kind CHECK (kind IN ('site', 'building', 'floor', 'room')),
+    CONSTRAINT location_parent CHECK ((kind = 'site') = (parent_id IS NULL)),
+    CONSTRAINT location_name_not_empty CHECK (name <> '')
+);
+
+CREATE INDEX idx_locations_org ON locations(org_id, kind, name);
+CREATE INDEX idx_locations_parent ON locations(parent_id);
+
+-- Every location with each of its ancestors, itself included: the devices
+-- of a site are those whose location has the site as ancestor.
+CREATE VIEW location_ancestors AS
+WITH RECURSIVE tree AS (
+    SELECT id AS location_id, id AS ancestor_id, kind AS ancestor_kind, parent_id
+    FROM locations
+    UNION ALL
+    SELECT tree.location_id, l.id, l.kind, l.parent_id
+    FROM tree
+    JOIN locations l ON l.id = tree.parent_id
+)
+SELECT location_id, ancestor_id, ancestor_kind FROM tree;
+
+ALTER TABLE devices ADD COLUMN location_id UUID REFERENCES locations(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_devices_location ON devices(location_id);
+
+-- ============================================
+-- DEVICE GROUPS
+-- ============================================
+
+-- A dynamic group contains the devices of its organization matching
+-- rule_type (any type if NULL) and containing rule_metadata.
+CREATE TABLE device_groups (
+    id            UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id        UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name          VARCHAR(255) NOT NULL,
+    description   TEXT,
+    kind          VARCHAR(20) NOT NULL,
+    rule_type     VARCHAR(100),
+    rule_metadata JSONB NOT NULL DEFAULT '{}',
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (org_id, name),
+    CONSTRAINT device_group_kind CHECK (kind IN ('static', 'dynamic')),
+    CONSTRAINT device_group_name_not_empty CHECK (name <> '')
+);
+
+-- Members of static groups
+CREATE TABLE device_group_members (
+    group_id   UUID NOT NULL REFERENCES device_groups(id) ON DELETE CASCADE,
+    device_id  UUID NOT NULL REFERENCES devices(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (group_id, device_id)
+);
+
+CREATE INDEX idx_device_group_members_device ON device_group_members(device_id);
+
+-- Devices of every group, static or dynamic
+CREATE VIEW device_group_devices AS
+SELECT m.group_id, m.device_id
+FROM device_group_members m
+UNION ALL
+SELECT g.id, d.id
+FROM device_groups g
+JOIN devices d ON d.org_id = g.org_id
+WHERE g.kind = 'dynamic'
+  AND (g.rule_type IS NULL OR d.type = g.rule_type)
+  AND d.metadata @> g.rule_metadata;
+
+-- Same isolation as devices, see 011_create_organizations.sql
+ALTER TABLE locations ENABLE ROW LEVEL SECURITY;
+ALTER TABLE locations FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY locations_org_isolation ON locations
+    USING (
+        COALESCE(current_setting('app.org_id', true), '') = ''
+        OR org_id = current_setting('app.org_id', true)::uuid
+    )
+    WITH CHECK (
+        COALESCE(current_setting('app.org_id', true), '') = ''
+        OR org_id = current_setting('app.org_id', true)::uuid
+    );
+
+ALTER TABLE device_groups ENABLE ROW LEVEL SECURITY;
+ALTER TABLE device_groups FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY device_groups_org_isolation ON device_groups
+    USING (
+        COALESCE(current_setting('app.org_id', true), '') = ''
+        OR org_id = current_setting('app.org_id', true)::uuid
+    )
+    WITH CHECK (
+        COALESCE(current_setting('app.org_id', true), '') = ''
+        OR org_id = current_setting('app.org_id', true)::uuid
+    );
+
+COMMENT ON TABLE locations IS 'Location hierarchy: site > building > floor > room';
+COMMENT ON COLUMN locations.kind IS 'Level in the hierarchy: site, building, floor or room';
+COMMENT ON COLUMN devices.location_id IS 'Location of the device, usually a room';
+COMMENT ON TABLE device_groups IS 'Named sets of devices, static or rule-based';
+COMMENT ON COLUMN device_groups.rule_type IS 'Dynamic groups: device type to match (any if NULL)';
+COMMENT ON COLUMN device_groups.rule_metadata IS 'Dynamic groups: metadata entries devices must contain';
+COMMENT ON TABLE device_group_members IS 'Members of static groups';
@@ -0,0 +1,67 @@
+-- Migration: Per-device access control lists
+-- Description: An entry grants a user, or the members of an organization
+-- with a role, read, write or command access to a device or to the devices
+-- of a group. A user with at least one entry in an organization, directly or
+-- through their role, only sees the devices granted by their entries; other
+-- users keep access to every device allowed by their role.
+
+-- ============================================
+-- ACL ENTRIES
+-- ============================================
+
+-- Exactly one subject (user_id or role) and one target (device_id or
+-- group_id) per entry
+CREATE TABLE device_acl_entries (
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id     UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id    UUID REFERENCES users(id) ON DELETE CASCADE,
+    role       VARCHAR(50) REFERENCES roles(name) ON DELETE CASCADE,
+    device_id  UUID REFERENCES devices(id) ON DELETE CASCADE,
+    group_id   UUID REFERENCES device_groups(id) ON DELETE CASCADE,
+    access     VARCHAR(20) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT device_acl_access CHECK (access IN ('read', 'write', 'command')),
+    CONSTRAINT device_acl_subject CHECK ((user_id IS NULL) <> (role IS NULL)),
+    CONSTRAINT device_acl_target CHECK ((device_id IS NULL) <> (group_id IS NULL))
+);
+
+CREATE UNIQUE INDEX idx_device_acl_unique ON device_acl_entries(
+    org_id,
+    COALESCE(user_id::text, ''),
+    COALESCE(role, ''),
+    COALESCE(device_id, group_id),
+    access
+);
+CREATE INDEX idx_device_acl_user ON device_acl_entries(org_id, user_id) WHERE user_id IS NOT NULL;
+CREATE INDEX idx_device_acl_role ON device_acl_entries(org_id, role) WHERE role IS NOT NULL;
+
+-- Devices granted by each entry, group entries expanded to the devices of
+-- the group (static members or dynamic rule, see device_group_devices)
+CREATE VIEW device_acl_devices AS
+SELECT e.org_id, e.user_id, e.role, e.access, e.device_id
+FROM device_acl_entries e
+WHERE e.device_id IS NOT NULL
+UNION ALL
+SELECT e.org_id, e.user_id, e.role, e.access, gd.device_id
+FROM device_acl_entries e
+JOIN device_group_devices gd ON gd.group_id = e.group_id
+WHERE e.group_id IS NOT NULL;
+
+-- Same isolation as devices, see 011_create_organizations.sql
+ALTER TABLE device_acl_entries ENABLE ROW LEVEL SECURITY;
+ALTER TABLE device_acl_entries FORCE ROW LEVEL SECURITY;
+
+CREATE POLICY device_acl_entries_org_isolation ON device_acl_entries
+    USING (
+        COALESCE(current_setting('app.org_id', true), '') = ''
+        OR org_id = current_setting('app.org_id', true)::uuid
+    )
+    WITH CHECK (
+        COALESCE(current_setting('app.org_id', true), '') = ''
+        OR org_id = current_setting('app.org_id', true)::uuid
+    );
+
+COMMENT ON TABLE device_acl_entries IS 'Access of users and roles to devices and device groups';
+COMMENT ON COLUMN device_acl_entries.role IS 'Members of the organization with this role';
+COMMENT ON COLUMN device_acl_entries.access IS 'read, write (implies read) or command (implies read)';
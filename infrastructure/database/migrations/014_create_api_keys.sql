@@ -0,0 +1,43 @@
+-- Migration: API keys and service accounts
+-- Description: API keys authenticate machine clients without a login. A key
+-- belongs to a user and acts in one organization, with the permissions of
+-- the user there, optionally narrowed to a subset. Service accounts are users
+-- for machine clients: they have no password and cannot log in.
+
+-- ============================================
+-- SERVICE ACCOUNTS
+-- ============================================
+
+ALTER TABLE users ADD COLUMN service_account BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN users.service_account IS 'Machine client without password, authenticated by API keys only';
+
+-- ============================================
+-- API KEYS
+-- ============================================
+
+-- Only hashes are stored: a database leak does not expose usable keys
+CREATE TABLE api_keys (
+    id           UUID PRIMARY KEY,
+    user_id      UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    org_id       UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name         VARCHAR(100) NOT NULL,
+    prefix       VARCHAR(16) NOT NULL,
+    key_hash     VARCHAR(64) NOT NULL UNIQUE,
+    permissions  TEXT[] NOT NULL DEFAULT '{}',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ
+);
+
+CREATE INDEX idx_api_keys_user ON api_keys(user_id, org_id);
+CREATE INDEX idx_api_keys_org ON api_keys(org_id);
+
+COMMENT ON TABLE api_keys IS 'API keys of users and service accounts';
+COMMENT ON COLUMN api_keys.org_id IS 'Organization the key acts in';
+COMMENT ON COLUMN api_keys.prefix IS 'First characters of the key, to recognize it';
+COMMENT ON COLUMN api_keys.key_hash IS 'SHA-256 of the key, hex encoded';
+COMMENT ON COLUMN api_keys.permissions IS 'Subset of the permissions of the user, empty for all of them';
+COMMENT ON COLUMN api_keys.expires_at IS 'Expiry, NULL for a key that does not expire';
+COMMENT ON COLUMN api_keys.last_used_at IS 'Last authentication, updated at most once a minute';
@@ -0,0 +1,8 @@
+-- Migration: Unit of downsampled telemetry
+-- Description: Aggregation queries read rollups alongside raw telemetry, so
+-- that data past its raw retention stays queryable. Rollups need the unit of
+-- their points to be converted like raw values.
+
+ALTER TABLE telemetry_downsampled ADD COLUMN unit VARCHAR(50);
+
+COMMENT ON COLUMN telemetry_downsampled.unit IS 'Unit of the rolled up points, NULL if none or if they mixed units';
@@ -9,9 +9,13 @@
 //   -devices   Number of devices to simulate (default: 5)
 //   -interval  Interval between messages in seconds (default: 5)
 //   -duration  Duration to run in seconds, 0 for infinite (default: 0)
+//   -api-key   API key sent in the X-API-Key header (default: $IOT_API_KEY)
+//
+// The GraphQL API requires authentication: use an API key with the
+// devices:read and devices:write permissions, e.g. of a service account.
 //
 // Example:
-//   go run scripts/simulate-devices.go -devices 10 -interval 2 -duration 60
+//   IOT_API_KEY=iotk_... go run scripts/simulate-devices.go -devices 10 -interval 2 -duration 60
 
 package main
 
@@ -151,7 +155,7 @@ func getSimulatedDeviceName(index int) (string, DeviceType) {
 }
 
 // GraphQL client helper
-func graphqlRequest(apiURL string, query string, variables map[string]interface{}) (*GraphQLResponse, error) {
+func graphqlRequest(apiURL, apiKey string, query string, variables map[string]interface{}) (*GraphQLResponse, error) {
 	reqBody := GraphQLRequest{
 		Query:     query,
 		Variables: variables,
@@ -162,7 +166,16 @@ func graphqlRequest(apiURL string, query string, variables map[string]interface{
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	resp, err := http.Post(apiURL, "application/json", bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
@@ -172,6 +185,9 @@ func graphqlRequest(apiURL string, query string, variables map[string]interface{
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("unauthorized: %s", bytes.TrimSpace(body))
+	}
 
 	var gqlResp GraphQLResponse
 	if err := json.Unmarshal(body, &gqlResp); err != nil {
@@ -186,7 +202,7 @@ func graphqlRequest(apiURL string, query string, variables map[string]interface{
 }
 
 // Fetch all existing devices to check for our simulated ones
-func fetchExistingDevices(apiURL string) (map[string]string, error) {
+func fetchExistingDevices(apiURL, apiKey string) (map[string]string, error) {
 	query := `
 		query {
 			devices(pageSize: 1000) {
@@ -198,7 +214,7 @@ func fetchExistingDevices(apiURL string) (map[string]string, error) {
 		}
 	`
 
-	resp, err := graphqlRequest(apiURL, query, nil)
+	resp, err := graphqlRequest(apiURL, apiKey, query, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -218,7 +234,7 @@ func fetchExistingDevices(apiURL string) (map[string]string, error) {
 }
 
 // Create a device via GraphQL
-func createDevice(apiURL string, name string, deviceType string) (string, error) {
+func createDevice(apiURL, apiKey string, name string, deviceType string) (string, error) {
 	query := `
 		mutation CreateDevice($input: CreateDeviceInput!) {
 			createDevice(input: $input) {
@@ -239,7 +255,7 @@ func createDevice(apiURL string, name string, deviceType string) (string, error)
 		},
 	}
 
-	resp, err := graphqlRequest(apiURL, query, variables)
+	resp, err := graphqlRequest(apiURL, apiKey, query, variables)
 	if err != nil {
 		return "", err
 	}
@@ -253,12 +269,12 @@ func createDevice(apiURL string, name string, deviceType string) (string, error)
 }
 
 // ensureDeviceExists checks if device exists, creates if not, returns ID
-func ensureDeviceExists(apiURL string, name string, deviceType string, existingDevices map[string]string) (string, bool, error) {
+func ensureDeviceExists(apiURL, apiKey string, name string, deviceType string, existingDevices map[string]string) (string, bool, error) {
 	if id, exists := existingDevices[name]; exists {
 		return id, false, nil // already exists
 	}
 
-	id, err := createDevice(apiURL, name, deviceType)
+	id, err := createDevice(apiURL, apiKey, name, deviceType)
 	if err != nil {
 		return "", false, err
 	}
@@ -273,6 +289,7 @@ func main() {
 	numDevices := flag.Int("devices", 5, "Number of devices to simulate")
 	interval := flag.Int("interval", 5, "Interval between messages in seconds")
 	duration := flag.Int("duration", 0, "Duration to run in seconds (0 for infinite)")
+	apiKey := flag.String("api-key", os.Getenv("IOT_API_KEY"), "API key sent in the X-API-Key header")
 	flag.Parse()
 
 	log.Printf("🚀 IoT Device Simulator")
@@ -289,7 +306,10 @@ func main() {
 
 	// Fetch existing devices from API
 	log.Printf("🔍 Checking existing devices...")
-	existingDevices, err := fetchExistingDevices(*apiURL)
+	if *apiKey == "" {
+		log.Printf("⚠️  No API key (-api-key or IOT_API_KEY): the API will likely reject the requests")
+	}
+	existingDevices, err := fetchExistingDevices(*apiURL, *apiKey)
 	if err != nil {
 		log.Fatalf("❌ Failed to fetch existing devices: %v", err)
 	}
@@ -319,7 +339,7 @@ func main() {
 	for i := 0; i < *numDevices; i++ {
 		name, deviceType := getSimulatedDeviceName(i)
 
-		deviceID, created, err := ensureDeviceExists(*apiURL, name, deviceType.Name, existingDevices)
+		deviceID, created, err := ensureDeviceExists(*apiURL, *apiKey, name, deviceType.Name, existingDevices)
 		if err != nil {
 			log.Fatalf("❌ Failed to ensure device %s exists: %v", name, err)
 		}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	userpb "github.com/yourusername/iot-platform/shared/proto/user"
+)
+
+// APIKeyAuthenticator returns the claims of a request authenticated by an API
+// key: ErrUnauthorized if the key is unknown, revoked or expired, any other
+// error if it could not be checked.
+type APIKeyAuthenticator func(ctx context.Context, key string) (*Claims, error)
+
+// UserClientAPIKeys authenticates API keys against the User Service. The
+// claims carry the permissions of the owner of the key in its organization,
+// narrowed to those of the key.
+func UserClientAPIKeys(client userpb.UserServiceClient) APIKeyAuthenticator {
+	return func(ctx context.Context, key string) (*Claims, error) {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		resp, err := client.AuthenticateApiKey(ctx, &userpb.AuthenticateApiKeyRequest{Key: key})
+		if status.Code(err) == codes.Unauthenticated {
+			return nil, ErrUnauthorized
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		claims := &Claims{
+			UserID:      resp.User.Id,
+			Email:       resp.User.Email,
+			Name:        resp.User.Name,
+			Role:        resp.User.Role,
+			Permissions: ScopePermissions(TokenPermissions(resp.User.Permissions, resp.Membership.Permissions), resp.ApiKey.Permissions),
+			OrgID:       resp.ApiKey.OrgId,
+			OrgRole:     resp.Membership.Role,
+			APIKeyID:    resp.ApiKey.Id,
+		}
+		if resp.ApiKey.ExpiresAt != 0 {
+			claims.ExpiresAt = jwt.NewNumericDate(time.Unix(resp.ApiKey.ExpiresAt, 0))
+		}
+		return claims, nil
+	}
+}
@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+type clientKey struct{}
+
+// Client describes where a request comes from, recorded on sessions
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+// ClientFromRequest returns the client of r. Proxy headers are trusted: the
+// address is informative and never used for access control.
+func ClientFromRequest(r *http.Request) Client {
+	client := Client{UserAgent: r.UserAgent()}
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		client.IP = strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	} else if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+		client.IP = realIP
+	} else if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		client.IP = host
+	} else {
+		client.IP = r.RemoteAddr
+	}
+	return client
+}
+
+// ClientMiddleware adds the client of requests to their context
+func ClientMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), clientKey{}, ClientFromRequest(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ClientFromContext returns the client added by ClientMiddleware
+func ClientFromContext(ctx context.Context) Client {
+	client, _ := ctx.Value(clientKey{}).(Client)
+	return client
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// Authorization directives of the schema. A field of Query, Mutation or
+// Subscription without any of them is public.
+var authDirectives = []string{"auth", "hasRole", "hasPermission"}
+
+// AuthDirective implements @auth: the field requires an authenticated user.
+func AuthDirective(ctx context.Context, _ any, next graphql.Resolver) (any, error) {
+	if _, err := RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	return next(ctx)
+}
+
+// HasRoleDirective implements @hasRole(role:): the field requires the role,
+// platform admins are always granted.
+func HasRoleDirective(ctx context.Context, _ any, next graphql.Resolver, role string) (any, error) {
+	if _, err := RequireRole(ctx, role); err != nil {
+		return nil, err
+	}
+	return next(ctx)
+}
+
+// HasPermissionDirective implements @hasPermission(perm:): the field requires
+// the permission.
+func HasPermissionDirective(ctx context.Context, _ any, next graphql.Resolver, perm string) (any, error) {
+	if _, err := RequirePermission(ctx, perm); err != nil {
+		return nil, err
+	}
+	return next(ctx)
+}
+
+// PublicFields returns the root fields of schema without authorization
+// directive, keyed by operation type and field name (e.g. "mutation.login").
+func PublicFields(schema *ast.Schema) map[string]bool {
+	public := make(map[string]bool)
+	for operation, def := range map[ast.Operation]*ast.Definition{
+		ast.Query:        schema.Query,
+		ast.Mutation:     schema.Mutation,
+		ast.Subscription: schema.Subscription,
+	} {
+		if def == nil {
+			continue
+		}
+		for _, field := range def.Fields {
+			if !hasAuthDirective(field) {
+				public[string(operation)+"."+field.Name] = true
+			}
+		}
+	}
+	return public
+}
+
+func hasAuthDirective(field *ast.FieldDefinition) bool {
+	for _, name := range authDirectives {
+		if field.Directives.ForName(name) != nil {
+			return true
+		}
+	}
+	return false
+}
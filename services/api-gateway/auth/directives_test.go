@@ -0,0 +1,101 @@
+// +build unit
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestDirectives(t *testing.T) {
+	admin := WithUser(context.Background(), &Claims{UserID: "admin-1", Role: "admin"})
+	user := WithUser(context.Background(), &Claims{UserID: "user-1", Role: "user"})
+	device := WithUser(context.Background(), &Claims{UserID: "dev-1", Role: "device"})
+	adminKey := WithUser(context.Background(), &Claims{UserID: "admin-1", Role: "admin", APIKeyID: "key-1", Permissions: []string{PermDevicesRead}})
+
+	tests := []struct {
+		name      string
+		directive func(ctx context.Context, next func(context.Context) (any, error)) (any, error)
+		ctx       context.Context
+		wantErr   error
+	}{
+		{"auth_anonymous", authDirective, context.Background(), ErrUnauthorized},
+		{"auth_user", authDirective, user, nil},
+		{"has_role_device", hasRole("device"), device, nil},
+		{"has_role_other", hasRole("device"), user, ErrForbidden},
+		{"has_role_admin", hasRole("device"), admin, nil},
+		{"has_role_admin_scoped_key", hasRole("admin"), adminKey, ErrForbidden},
+		{"has_role_anonymous", hasRole("device"), context.Background(), ErrUnauthorized},
+		{"has_permission_granted", hasPermission(PermDevicesWrite), user, nil},
+		{"has_permission_denied", hasPermission(PermUsersAdmin), user, ErrForbidden},
+		{"has_permission_anonymous", hasPermission(PermDevicesRead), context.Background(), ErrUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			res, err := tt.directive(tt.ctx, func(ctx context.Context) (any, error) {
+				called = true
+				return "ok", nil
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if called != (tt.wantErr == nil) {
+				t.Errorf("next called = %v", called)
+			}
+			if tt.wantErr == nil && res != "ok" {
+				t.Errorf("unexpected result %v", res)
+			}
+		})
+	}
+}
+
+func authDirective(ctx context.Context, next func(context.Context) (any, error)) (any, error) {
+	return AuthDirective(ctx, nil, next)
+}
+
+func hasRole(role string) func(context.Context, func(context.Context) (any, error)) (any, error) {
+	return func(ctx context.Context, next func(context.Context) (any, error)) (any, error) {
+		return HasRoleDirective(ctx, nil, next, role)
+	}
+}
+
+func hasPermission(perm string) func(context.Context, func(context.Context) (any, error)) (any, error) {
+	return func(ctx context.Context, next func(context.Context) (any, error)) (any, error) {
+		return HasPermissionDirective(ctx, nil, next, perm)
+	}
+}
+
+func TestPublicFields(t *testing.T) {
+	schema := gqlparser.MustLoadSchema(&ast.Source{Input: `
+		directive @auth on FIELD_DEFINITION
+		directive @hasRole(role: String!) on FIELD_DEFINITION
+		directive @hasPermission(perm: String!) on FIELD_DEFINITION
+
+		type Query {
+			health: String
+			me: String @auth
+			devices: [String!]! @hasPermission(perm: "devices:read")
+		}
+		type Mutation {
+			login: String
+			ping: String @hasRole(role: "device")
+		}
+	`})
+
+	want := map[string]bool{
+		"query.health":   true,
+		"query.__schema": true,
+		"query.__type":   true,
+		"mutation.login": true,
+	}
+	if got := PublicFields(schema); !reflect.DeepEqual(got, want) {
+		t.Errorf("PublicFields() = %v, want %v", got, want)
+	}
+}
@@ -9,48 +9,50 @@ import (
 	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
-// PublicOperations lists GraphQL operations that don't require authentication.
-// All other operations require a valid JWT token.
-var PublicOperations = map[string]bool{
-	"login":              true,// Subscription - auth handled via WebSocket connectionParams
-	"IntrospectionQuery": true, // For GraphQL tooling
-	"__schema":           true, // For GraphQL introspection
-	"__type":             true, // For GraphQL introspection
+// AuthExtension is a gqlgen extension that rejects unauthenticated operations
+// unless they only select public fields, the root fields without authorization
+// directive. The directives still check each field.
+type AuthExtension struct {
+	public map[string]bool
 }
 
-// AuthExtension is a gqlgen extension that enforces authentication
-// on all operations except those in PublicOperations.
-type AuthExtension struct{}
-
 var _ interface {
 	graphql.OperationInterceptor
 	graphql.HandlerExtension
-} = AuthExtension{}
+} = &AuthExtension{}
 
 // ExtensionName returns the name of this extension.
-func (AuthExtension) ExtensionName() string {
+func (*AuthExtension) ExtensionName() string {
 	return "AuthExtension"
 }
 
-// Validate is called when adding the extension to the server.
-func (AuthExtension) Validate(_ graphql.ExecutableSchema) error {
+// Validate is called when adding the extension to the server. It collects
+// the public fields of the schema.
+func (e *AuthExtension) Validate(schema graphql.ExecutableSchema) error {
+	e.public = PublicFields(schema.Schema())
 	return nil
 }
 
 // InterceptOperation is called before each GraphQL operation.
 // It checks if the operation requires authentication and validates the user.
-func (AuthExtension) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+func (e *AuthExtension) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
 	opCtx := graphql.GetOperationContext(ctx)
 
 	// Check if this is a public operation
-	if isPublicOperation(opCtx) {
+	if e.isPublicOperation(opCtx) {
 		return next(ctx)
 	}
 
 	// Require authentication for all other operations
 	_, ok := GetUserFromContext(ctx)
 	if !ok {
+		// Streaming transports read responses until nil: send the error once
+		sent := false
 		return func(ctx context.Context) *graphql.Response {
+			if sent {
+				return nil
+			}
+			sent = true
 			return &graphql.Response{
 				Errors: gqlerror.List{
 					&gqlerror.Error{
@@ -67,28 +69,21 @@ func (AuthExtension) InterceptOperation(ctx context.Context, next graphql.Operat
 	return next(ctx)
 }
 
-// isPublicOperation checks if the current operation is in the public list.
-func isPublicOperation(opCtx *graphql.OperationContext) bool {
-	// Check by operation name first (named operations like "IntrospectionQuery")
-	if opCtx.OperationName != "" {
-		if PublicOperations[opCtx.OperationName] {
-			return true
-		}
-	}
-
-	// Check the actual fields being queried/mutated
-	if opCtx.Operation == nil {
+// isPublicOperation checks if the operation only selects public fields.
+func (e *AuthExtension) isPublicOperation(opCtx *graphql.OperationContext) bool {
+	if opCtx.Operation == nil || len(opCtx.Operation.SelectionSet) == 0 {
 		return false
 	}
 
-	// Check each field in the selection set
 	for _, sel := range opCtx.Operation.SelectionSet {
-		if field, ok := sel.(*ast.Field); ok {
-			if PublicOperations[field.Name] {
-				return true
-			}
+		field, ok := sel.(*ast.Field)
+		if !ok {
+			return false
+		}
+		if field.Name != "__typename" && !e.public[string(opCtx.Operation.Operation)+"."+field.Name] {
+			return false
 		}
 	}
 
-	return false
-}
\ No newline at end of file
+	return true
+}
@@ -23,17 +23,36 @@ type Claims struct {
 	UserID string `json:"user_id"`
 	Email  string `json:"email"`
 	Name   string `json:"name"`
-	Role   string `json:"role"` // admin, user, device
+	Role   string `json:"role"` // Platform role: admin, user, device or a custom role
+	// Permissions granted by the platform role and the role in the
+	// organization when the token was issued, nil for tokens issued before
+	// roles had permissions
+	Permissions []string `json:"perms,omitempty"`
+	// SessionID is the User Service session the token was issued for, empty
+	// for tokens outside of a session
+	SessionID string `json:"sid,omitempty"`
+	// OrgID is the organization the token acts in, empty for tokens issued
+	// before organizations (see Organization)
+	OrgID string `json:"org,omitempty"`
+	// OrgRole is the role of the user in the organization, empty for tokens
+	// issued before ACLs; ACL entries granted to a role apply to it
+	OrgRole string `json:"org_role,omitempty"`
+	// APIKeyID is the API key the request was authenticated with, empty for
+	// tokens. Never part of a token.
+	APIKeyID string `json:"-"`
 	jwt.RegisteredClaims
 }
 
 // JWTManager handles JWT token operations
 type JWTManager struct {
-	secretKey     []byte
+	// secretKey signs and verifies HS256 tokens, nil to reject them
+	secretKey []byte
+	// keys signs tokens instead of secretKey when set
+	keys          *KeyRing
 	tokenDuration time.Duration
 }
 
-// NewJWTManager creates a new JWT manager
+// NewJWTManager creates a new JWT manager signing with an HS256 secret
 func NewJWTManager(secretKey string, tokenDuration time.Duration) *JWTManager {
 	return &JWTManager{
 		secretKey:     []byte(secretKey),
@@ -41,29 +60,64 @@ func NewJWTManager(secretKey string, tokenDuration time.Duration) *JWTManager {
 	}
 }
 
+// NewKeyRingJWTManager creates a JWT manager signing with the asymmetric keys
+// of ring. HS256 tokens signed with legacySecret are still accepted while
+// migrating, empty to reject them.
+func NewKeyRingJWTManager(ring *KeyRing, legacySecret string, tokenDuration time.Duration) *JWTManager {
+	m := &JWTManager{keys: ring, tokenDuration: tokenDuration}
+	if legacySecret != "" {
+		m.secretKey = []byte(legacySecret)
+	}
+	return m
+}
+
+// KeyRing returns the keys tokens are signed with, nil for HS256
+func (m *JWTManager) KeyRing() *KeyRing {
+	return m.keys
+}
+
 // GenerateToken creates a new JWT token for a user
 func (m *JWTManager) GenerateToken(userID, email, name, role string) (string, error) {
+	token, _, err := m.GenerateSessionToken(userID, email, name, role, "", "", "", nil)
+	return token, err
+}
+
+// GenerateSessionToken creates a new JWT token for a user session in an
+// organization, with the role and permissions of the user in it, and returns
+// its expiry
+func (m *JWTManager) GenerateSessionToken(userID, email, name, role, sessionID, orgID, orgRole string, permissions []string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(m.tokenDuration)
 	claims := &Claims{
-		UserID: userID,
-		Email:  email,
-		Name:   name,
-		Role:   role,
+		UserID:      userID,
+		Email:       email,
+		Name:        name,
+		Role:        role,
+		Permissions: permissions,
+		SessionID:   sessionID,
+		OrgID:       orgID,
+		OrgRole:     orgRole,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.tokenDuration)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "iot-platform-api-gateway",
 			Subject:   userID,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString(m.secretKey)
+	var signedToken string
+	var err error
+	if m.keys != nil {
+		signedToken, err = m.keys.sign(claims)
+	} else {
+		signedToken, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secretKey)
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to sign token: %w", err)
+		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
 	}
 
-	return signedToken, nil
+	return signedToken, expiresAt, nil
 }
 
 // ValidateToken validates a JWT token and returns the claims
@@ -73,10 +127,15 @@ func (m *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 		&Claims{},
 		func(token *jwt.Token) (interface{}, error) {
 			// Verify signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok && m.secretKey != nil {
+				return m.secretKey, nil
+			}
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok || m.keys == nil {
 				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 			}
-			return m.secretKey, nil
+			// Asymmetric tokens name their key
+			kid, _ := token.Header["kid"].(string)
+			return m.keys.verificationKey(kid, token.Method.Alg())
 		},
 	)
 
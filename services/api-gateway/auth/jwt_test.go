@@ -283,3 +283,42 @@ func TestJWTManager_DifferentSecrets(t *testing.T) {
 		t.Error("Token should be invalid with different secret")
 	}
 }
+
+func TestJWTManager_GenerateSessionToken(t *testing.T) {
+	manager := NewJWTManager("test-secret", 15*time.Minute)
+
+	token, expiresAt, err := manager.GenerateSessionToken("user-123", "test@example.com", "Test User", "user", "session-1", "org-1", "member", nil)
+	if err != nil {
+		t.Fatalf("GenerateSessionToken() failed: %v", err)
+	}
+	if d := time.Until(expiresAt); d < 14*time.Minute || d > 15*time.Minute {
+		t.Errorf("expiresAt = %v, want in 15 minutes", expiresAt)
+	}
+
+	claims, err := manager.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken() failed: %v", err)
+	}
+	if claims.SessionID != "session-1" {
+		t.Errorf("SessionID = %q, want session-1", claims.SessionID)
+	}
+	if claims.OrgID != "org-1" {
+		t.Errorf("OrgID = %q, want org-1", claims.OrgID)
+	}
+	if claims.OrgRole != "member" {
+		t.Errorf("OrgRole = %q, want member", claims.OrgRole)
+	}
+	if claims.ExpiresAt.Unix() != expiresAt.Unix() {
+		t.Errorf("exp = %v, want %v", claims.ExpiresAt.Time, expiresAt)
+	}
+
+	// Tokens outside of a session carry no sid, and act in the default organization
+	token, _ = manager.GenerateToken("user-123", "test@example.com", "Test User", "user")
+	claims, _ = manager.ValidateToken(token)
+	if claims.SessionID != "" {
+		t.Errorf("SessionID = %q, want empty", claims.SessionID)
+	}
+	if claims.Organization() != DefaultOrganizationID {
+		t.Errorf("Organization() = %q, want %q", claims.Organization(), DefaultOrganizationID)
+	}
+}
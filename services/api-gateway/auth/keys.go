@@ -0,0 +1,472 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Asymmetric signing algorithms of key rings
+const (
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgEdDSA = "EdDSA"
+)
+
+// ErrUnknownKey is returned when a token is signed with a key not in the ring
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// activeAtHeader is the PEM header holding when a key starts signing
+const activeAtHeader = "Active-At"
+
+// KeyRingConfig configures a KeyRing
+type KeyRingConfig struct {
+	// Algorithm of new keys: RS256, ES256 or EdDSA
+	Algorithm string
+	// Dir stores the private keys as PEM files, shared by the replicas. Empty
+	// keeps them in memory: tokens are then lost on restart.
+	Dir string
+	// RotationInterval is how long a key signs tokens, 0 to never rotate
+	RotationInterval time.Duration
+	// Overlap is how long a new key is published before it signs, and an old
+	// key after it stopped. It must exceed the access token lifetime and the
+	// JWKS cache duration.
+	Overlap time.Duration
+}
+
+// signingKey is a private key of the ring
+type signingKey struct {
+	id       string // kid, the file name without extension
+	alg      string
+	private  crypto.Signer
+	activeAt time.Time // signs tokens from then on
+}
+
+// KeyRing holds the asymmetric keys tokens are signed with, identified by
+// their kid. Keys rotate every RotationInterval: the next key is published
+// in the JWKS Overlap before it signs, and the previous one stays published
+// Overlap after, so that verifiers caching the JWKS accept every valid token.
+type KeyRing struct {
+	config KeyRingConfig
+	now    func() time.Time
+
+	mu   sync.RWMutex
+	keys []*signingKey // sorted by activeAt
+}
+
+// NewKeyRing loads the keys of config.Dir, and creates the first one if none
+// of config.Algorithm exists
+func NewKeyRing(config KeyRingConfig) (*KeyRing, error) {
+	if _, err := newPrivateKey(config.Algorithm); err != nil {
+		return nil, err
+	}
+	if config.RotationInterval > 0 && config.Overlap <= 0 {
+		return nil, errors.New("key overlap required with rotation")
+	}
+	if config.Dir != "" {
+		if err := os.MkdirAll(config.Dir, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create key directory: %w", err)
+		}
+	}
+
+	r := &KeyRing{config: config, now: time.Now}
+	if err := r.Maintain(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Algorithm returns the algorithm of new keys
+func (r *KeyRing) Algorithm() string {
+	return r.config.Algorithm
+}
+
+// Rotate creates a key that starts signing after the overlap
+func (r *KeyRing) Rotate() error {
+	_, err := r.addKey(r.now().Add(r.config.Overlap))
+	return err
+}
+
+// Maintain reloads the keys written by other replicas, creates the next key
+// when due and removes the keys no longer published
+func (r *KeyRing) Maintain() error {
+	if err := r.load(); err != nil {
+		return err
+	}
+	now := r.now()
+
+	r.mu.RLock()
+	var newest *signingKey
+	for _, key := range r.keys {
+		if key.alg == r.config.Algorithm {
+			newest = key
+		}
+	}
+	r.mu.RUnlock()
+
+	switch {
+	case newest == nil:
+		// First key, or first key of a new algorithm: sign right away
+		if _, err := r.addKey(now); err != nil {
+			return err
+		}
+	case r.config.RotationInterval > 0:
+		next := newest.activeAt.Add(r.config.RotationInterval)
+		if !now.Before(next.Add(-r.config.Overlap)) {
+			// Late, e.g. after downtime: the key must still be published first
+			if earliest := now.Add(r.config.Overlap); next.Before(earliest) {
+				next = earliest
+			}
+			if _, err := r.addKey(next); err != nil {
+				return err
+			}
+		}
+	}
+
+	r.prune(now)
+	return nil
+}
+
+// Run maintains the ring every interval until ctx is done
+func (r *KeyRing) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := r.Maintain(); err != nil {
+			log.Printf("⚠️  Failed to maintain JWT keys: %v", err)
+		}
+	}
+}
+
+// addKey creates a key of the configured algorithm signing from activeAt. Its
+// kid is derived from activeAt, so that replicas rotating at the same time
+// agree on a single key.
+func (r *KeyRing) addKey(activeAt time.Time) (*signingKey, error) {
+	private, err := newPrivateKey(r.config.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	activeAt = activeAt.UTC().Truncate(time.Second)
+	key := &signingKey{
+		id:       strings.ToLower(r.config.Algorithm) + "-" + activeAt.Format("20060102T150405Z"),
+		alg:      r.config.Algorithm,
+		private:  private,
+		activeAt: activeAt,
+	}
+
+	if r.config.Dir != "" {
+		created, err := r.writeKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if !created {
+			// Another replica was first: use its key
+			return nil, r.load()
+		}
+	}
+
+	r.mu.Lock()
+	r.keys = append(r.keys, key)
+	sortKeys(r.keys)
+	r.mu.Unlock()
+	log.Printf("🔑 JWT key %s created, signing from %s", key.id, key.activeAt.Format(time.RFC3339))
+	return key, nil
+}
+
+// writeKey stores key unless a key with the same kid exists
+func (r *KeyRing) writeKey(key *signingKey) (bool, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key.private)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode key: %w", err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{
+		Type:    "PRIVATE KEY",
+		Headers: map[string]string{activeAtHeader: key.activeAt.Format(time.RFC3339)},
+		Bytes:   der,
+	})
+
+	tmp, err := os.CreateTemp(r.config.Dir, ".key-*")
+	if err != nil {
+		return false, fmt.Errorf("failed to write key: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return false, fmt.Errorf("failed to write key: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return false, fmt.Errorf("failed to write key: %w", err)
+	}
+	// Link fails if the file exists, unlike Rename
+	err = os.Link(tmp.Name(), filepath.Join(r.config.Dir, key.id+".pem"))
+	if errors.Is(err, os.ErrExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to write key: %w", err)
+	}
+	return true, nil
+}
+
+// load replaces the keys with those of the directory
+func (r *KeyRing) load() error {
+	if r.config.Dir == "" {
+		return nil
+	}
+	paths, err := filepath.Glob(filepath.Join(r.config.Dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+
+	keys := make([]*signingKey, 0, len(paths))
+	for _, path := range paths {
+		key, err := readKey(path)
+		if err != nil {
+			log.Printf("⚠️  Ignoring JWT key %s: %v", path, err)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sortKeys(keys)
+
+	r.mu.Lock()
+	r.keys = keys
+	r.mu.Unlock()
+	return nil
+}
+
+func readKey(path string) (*signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, errors.New("no PKCS#8 private key")
+	}
+	activeAt, err := time.Parse(time.RFC3339, block.Headers[activeAtHeader])
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s header: %w", activeAtHeader, err)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	private, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("unsupported key type")
+	}
+	alg, err := keyAlgorithm(private.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &signingKey{
+		id:       strings.TrimSuffix(filepath.Base(path), ".pem"),
+		alg:      alg,
+		private:  private,
+		activeAt: activeAt,
+	}, nil
+}
+
+// prune removes the keys replaced for longer than the overlap
+func (r *KeyRing) prune(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.keys[:0]
+	for i, key := range r.keys {
+		if i+1 < len(r.keys) && r.keys[i+1].activeAt.Add(r.config.Overlap).Before(now) {
+			if r.config.Dir != "" {
+				if err := os.Remove(filepath.Join(r.config.Dir, key.id+".pem")); err != nil && !errors.Is(err, os.ErrNotExist) {
+					log.Printf("⚠️  Failed to remove JWT key %s: %v", key.id, err)
+				}
+			}
+			log.Printf("🔑 JWT key %s retired", key.id)
+			continue
+		}
+		kept = append(kept, key)
+	}
+	r.keys = kept
+}
+
+// signingKey returns the key new tokens are signed with: the latest active
+// key of the configured algorithm
+func (r *KeyRing) signingKey() *signingKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := r.now()
+	var current *signingKey
+	for _, key := range r.keys {
+		if key.alg != r.config.Algorithm {
+			continue
+		}
+		if current == nil || !key.activeAt.After(now) {
+			current = key
+		}
+	}
+	return current
+}
+
+// verificationKey returns the public key of kid, if signed with alg
+func (r *KeyRing) verificationKey(kid, alg string) (crypto.PublicKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range r.keys {
+		if key.id == kid {
+			if key.alg != alg {
+				return nil, fmt.Errorf("key %s does not sign %s", kid, alg)
+			}
+			return key.private.Public(), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+}
+
+// sign signs claims with the current key
+func (r *KeyRing) sign(claims jwt.Claims) (string, error) {
+	key := r.signingKey()
+	if key == nil {
+		return "", errors.New("no signing key")
+	}
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.alg), claims)
+	token.Header["kid"] = key.id
+	return token.SignedString(key.private)
+}
+
+// JWK is a public key in the JSON Web Key format (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC and OKP
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys of the ring, upcoming and retiring included
+func (r *KeyRing) JWKS() JWKS {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	set := JWKS{Keys: make([]JWK, 0, len(r.keys))}
+	for _, key := range r.keys {
+		jwk := JWK{KeyID: key.id, Use: "sig", Algorithm: key.alg}
+		b64 := base64.RawURLEncoding.EncodeToString
+		switch public := key.private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = b64(public.N.Bytes())
+			jwk.E = b64(big.NewInt(int64(public.E)).Bytes())
+		case *ecdsa.PublicKey:
+			ecdhKey, err := public.ECDH()
+			if err != nil {
+				continue
+			}
+			point := ecdhKey.Bytes() // 0x04 || X || Y
+			size := (len(point) - 1) / 2
+			jwk.KeyType = "EC"
+			jwk.Curve = public.Curve.Params().Name
+			jwk.X = b64(point[1 : 1+size])
+			jwk.Y = b64(point[1+size:])
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = b64(public)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// JWKSHandler serves the public keys of ring at /.well-known/jwks.json. The
+// response is cached for 5 minutes, less than the overlap.
+func JWKSHandler(ring *KeyRing) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		if err := json.NewEncoder(w).Encode(ring.JWKS()); err != nil {
+			log.Printf("⚠️  Failed to write JWKS: %v", err)
+		}
+	})
+}
+
+func newPrivateKey(alg string) (crypto.Signer, error) {
+	switch alg {
+	case AlgRS256:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case AlgES256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgEdDSA:
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		return private, err
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q: must be %s, %s or %s", alg, AlgRS256, AlgES256, AlgEdDSA)
+	}
+}
+
+func keyAlgorithm(public crypto.PublicKey) (string, error) {
+	switch public := public.(type) {
+	case *rsa.PublicKey:
+		return AlgRS256, nil
+	case *ecdsa.PublicKey:
+		if public.Curve != elliptic.P256() {
+			return "", errors.New("unsupported curve")
+		}
+		return AlgES256, nil
+	case ed25519.PublicKey:
+		return AlgEdDSA, nil
+	default:
+		return "", errors.New("unsupported key type")
+	}
+}
+
+func sortKeys(keys []*signingKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].activeAt.Equal(keys[j].activeAt) {
+			return keys[i].activeAt.Before(keys[j].activeAt)
+		}
+		return keys[i].id < keys[j].id
+	})
+}
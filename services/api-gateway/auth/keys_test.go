@@ -0,0 +1,273 @@
+// +build unit
+
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// newTestKeyRing creates a key ring whose clock is advanced by the returned
+// function
+func newTestKeyRing(t *testing.T, config KeyRingConfig) (*KeyRing, func(time.Duration)) {
+	t.Helper()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	ring := &KeyRing{config: config, now: func() time.Time { return now }}
+	if err := ring.Maintain(); err != nil {
+		t.Fatalf("Maintain() failed: %v", err)
+	}
+	return ring, func(d time.Duration) {
+		now = now.Add(d)
+		if err := ring.Maintain(); err != nil {
+			t.Fatalf("Maintain() failed: %v", err)
+		}
+	}
+}
+
+// publicKeyFromJWK decodes a JWK the way a verifying service would
+func publicKeyFromJWK(t *testing.T, jwk JWK) crypto.PublicKey {
+	t.Helper()
+	decode := func(s string) []byte {
+		b, err := base64.RawURLEncoding.DecodeString(s)
+		if err != nil {
+			t.Fatalf("invalid base64url %q: %v", s, err)
+		}
+		return b
+	}
+	switch jwk.KeyType {
+	case "RSA":
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(decode(jwk.N)), E: int(new(big.Int).SetBytes(decode(jwk.E)).Int64())}
+	case "EC":
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(decode(jwk.X)), Y: new(big.Int).SetBytes(decode(jwk.Y))}
+	case "OKP":
+		return ed25519.PublicKey(decode(jwk.X))
+	}
+	t.Fatalf("unexpected key type %q", jwk.KeyType)
+	return nil
+}
+
+func TestKeyRing_Algorithms(t *testing.T) {
+	for _, alg := range []string{AlgRS256, AlgES256, AlgEdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			ring, err := NewKeyRing(KeyRingConfig{Algorithm: alg})
+			if err != nil {
+				t.Fatalf("NewKeyRing() failed: %v", err)
+			}
+			manager := NewKeyRingJWTManager(ring, "", time.Hour)
+
+			token, err := manager.GenerateToken("user-123", "test@example.com", "Test User", "user")
+			if err != nil {
+				t.Fatalf("GenerateToken() failed: %v", err)
+			}
+			claims, err := manager.ValidateToken(token)
+			if err != nil {
+				t.Fatalf("ValidateToken() failed: %v", err)
+			}
+			if claims.UserID != "user-123" {
+				t.Errorf("UserID = %v, want user-123", claims.UserID)
+			}
+
+			// The JWKS alone verifies the token
+			jwks := ring.JWKS()
+			if len(jwks.Keys) != 1 {
+				t.Fatalf("expected 1 key, got %d", len(jwks.Keys))
+			}
+			parsed, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+				if token.Header["kid"] != jwks.Keys[0].KeyID {
+					return nil, errors.New("unexpected kid")
+				}
+				return publicKeyFromJWK(t, jwks.Keys[0]), nil
+			}, jwt.WithValidMethods([]string{jwks.Keys[0].Algorithm}))
+			if err != nil || !parsed.Valid {
+				t.Errorf("token not verified with the JWKS: %v", err)
+			}
+		})
+	}
+
+	if _, err := NewKeyRing(KeyRingConfig{Algorithm: "HS256"}); err == nil {
+		t.Error("expected error for a symmetric algorithm")
+	}
+}
+
+// TestKeyRing_Rotation tests that the next key is published before it signs
+// and the previous one kept until the tokens it signed have expired.
+func TestKeyRing_Rotation(t *testing.T) {
+	ring, advance := newTestKeyRing(t, KeyRingConfig{
+		Algorithm:        AlgES256,
+		RotationInterval: 24 * time.Hour,
+		Overlap:          time.Hour,
+	})
+	manager := NewKeyRingJWTManager(ring, "", 15*time.Minute)
+	first := ring.signingKey().id
+
+	// Not due yet
+	advance(22 * time.Hour)
+	if n := len(ring.JWKS().Keys); n != 1 {
+		t.Fatalf("expected 1 key before rotation, got %d", n)
+	}
+
+	// Published an hour before signing
+	advance(time.Hour)
+	if n := len(ring.JWKS().Keys); n != 2 {
+		t.Fatalf("expected 2 keys during pre-publication, got %d", n)
+	}
+	if ring.signingKey().id != first {
+		t.Error("next key should not sign before its activation")
+	}
+	oldToken, _, _ := manager.GenerateSessionToken("user-123", "test@example.com", "Test User", "user", "", "", "", nil)
+
+	// Signing with the next key, the previous one still published
+	advance(time.Hour)
+	second := ring.signingKey().id
+	if second == first {
+		t.Fatal("key was not rotated")
+	}
+	if n := len(ring.JWKS().Keys); n != 2 {
+		t.Fatalf("expected 2 keys during overlap, got %d", n)
+	}
+	newToken, _ := manager.GenerateToken("user-123", "test@example.com", "Test User", "user")
+	parsed, _, _ := jwt.NewParser().ParseUnverified(newToken, &Claims{})
+	if parsed.Header["kid"] != second {
+		t.Errorf("kid = %v, want %s", parsed.Header["kid"], second)
+	}
+	if _, err := manager.ValidateToken(oldToken); err != nil {
+		t.Errorf("token of the previous key should still be valid: %v", err)
+	}
+
+	// Retired after the overlap
+	advance(time.Hour + time.Minute)
+	jwks := ring.JWKS()
+	if len(jwks.Keys) != 1 || jwks.Keys[0].KeyID != second {
+		t.Fatalf("expected only %s, got %+v", second, jwks.Keys)
+	}
+	if _, err := ring.verificationKey(first, AlgES256); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("expected ErrUnknownKey for the retired key, got %v", err)
+	}
+	if _, err := manager.ValidateToken(oldToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected token of the retired key to be rejected, got %v", err)
+	}
+}
+
+// TestKeyRing_Dir tests that replicas sharing a directory share their keys
+// and agree on the next one.
+func TestKeyRing_Dir(t *testing.T) {
+	dir := t.TempDir()
+	config := KeyRingConfig{Algorithm: AlgEdDSA, Dir: dir, RotationInterval: 24 * time.Hour, Overlap: time.Hour}
+	replicaA, advanceA := newTestKeyRing(t, config)
+	replicaB, advanceB := newTestKeyRing(t, config)
+
+	if replicaA.signingKey().id != replicaB.signingKey().id {
+		t.Fatalf("replicas sign with different keys: %s and %s", replicaA.signingKey().id, replicaB.signingKey().id)
+	}
+	token, err := NewKeyRingJWTManager(replicaA, "", time.Hour).GenerateToken("user-123", "test@example.com", "Test User", "user")
+	if err != nil {
+		t.Fatalf("GenerateToken() failed: %v", err)
+	}
+	if _, err := NewKeyRingJWTManager(replicaB, "", time.Hour).ValidateToken(token); err != nil {
+		t.Errorf("token of replica A rejected by replica B: %v", err)
+	}
+
+	// Both rotate at the same time: a single key is created
+	advanceA(23 * time.Hour)
+	advanceB(23 * time.Hour)
+	files, _ := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if len(files) != 2 {
+		t.Errorf("expected 2 key files, got %d", len(files))
+	}
+	if len(replicaA.JWKS().Keys) != 2 || len(replicaB.JWKS().Keys) != 2 {
+		t.Errorf("replicas should publish both keys")
+	}
+	info, err := os.Stat(files[0])
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("key file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// Restart keeps the keys
+	restarted, err := NewKeyRing(config)
+	if err != nil {
+		t.Fatalf("NewKeyRing() failed: %v", err)
+	}
+	if len(restarted.JWKS().Keys) < 2 {
+		t.Errorf("restarted ring lost keys: %+v", restarted.JWKS().Keys)
+	}
+}
+
+// TestJWTManager_HS256Migration tests that HS256 tokens are accepted during
+// the migration only, and never verified with a public key.
+func TestJWTManager_HS256Migration(t *testing.T) {
+	legacy, _ := NewJWTManager("legacy-secret", time.Hour).GenerateToken("user-123", "test@example.com", "Test User", "user")
+	ring, err := NewKeyRing(KeyRingConfig{Algorithm: AlgRS256})
+	if err != nil {
+		t.Fatalf("NewKeyRing() failed: %v", err)
+	}
+
+	migrating := NewKeyRingJWTManager(ring, "legacy-secret", time.Hour)
+	if _, err := migrating.ValidateToken(legacy); err != nil {
+		t.Errorf("HS256 token rejected during migration: %v", err)
+	}
+	token, _ := migrating.GenerateToken("user-123", "test@example.com", "Test User", "user")
+	if parsed, _, _ := jwt.NewParser().ParseUnverified(token, &Claims{}); parsed.Method.Alg() != AlgRS256 {
+		t.Errorf("new tokens signed with %s, want RS256", parsed.Method.Alg())
+	}
+
+	migrated := NewKeyRingJWTManager(ring, "", time.Hour)
+	if _, err := migrated.ValidateToken(legacy); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected HS256 token to be rejected after migration, got %v", err)
+	}
+
+	// HS256 token signed with the public key of the ring
+	jwk := ring.JWKS().Keys[0]
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{UserID: "user-123", Email: "test@example.com", Role: "admin"})
+	forged.Header["kid"] = jwk.KeyID
+	forgedToken, _ := forged.SignedString([]byte(jwk.N))
+	if _, err := migrated.ValidateToken(forgedToken); err == nil {
+		t.Error("HS256 token with a ring kid should be rejected")
+	}
+	if _, err := migrating.ValidateToken(forgedToken); err == nil {
+		t.Error("HS256 token with a ring kid should be rejected during migration")
+	}
+}
+
+func TestJWKSHandler(t *testing.T) {
+	ring, err := NewKeyRing(KeyRingConfig{Algorithm: AlgES256})
+	if err != nil {
+		t.Fatalf("NewKeyRing() failed: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	JWKSHandler(ring).ServeHTTP(rec, httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if rec.Header().Get("Cache-Control") == "" {
+		t.Error("expected a Cache-Control header")
+	}
+	var jwks JWKS
+	if err := json.Unmarshal(rec.Body.Bytes(), &jwks); err != nil {
+		t.Fatalf("invalid JWKS: %v", err)
+	}
+	if len(jwks.Keys) != 1 || jwks.Keys[0].KeyType != "EC" || jwks.Keys[0].Curve != "P-256" || jwks.Keys[0].Use != "sig" {
+		t.Errorf("unexpected JWKS %+v", jwks)
+	}
+
+	rec = httptest.NewRecorder()
+	JWKSHandler(ring).ServeHTTP(rec, httptest.NewRequest("POST", "/.well-known/jwks.json", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want 405", rec.Code)
+	}
+}
@@ -3,8 +3,11 @@ package auth
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
 	"strings"
+	"time"
 )
 
 // contextKey is a custom type for context keys to avoid collisions
@@ -15,13 +18,44 @@ const (
 	UserContextKey contextKey = "user"
 )
 
-// Middleware creates an HTTP middleware that validates JWT tokens
-func Middleware(jwtManager *JWTManager) func(http.Handler) http.Handler {
+// Middleware creates an HTTP middleware that validates JWT tokens, and API
+// keys sent as "Authorization: ApiKey <key>" or in the X-API-Key header.
+// apiKeys may be nil to only accept tokens. checkUser, if not nil, is called
+// for each token to reject revoked sessions and deactivated users; wrap it
+// with CachedUserCheck to bound the calls to the User Service.
+func Middleware(jwtManager *JWTManager, apiKeys APIKeyAuthenticator, checkUser UserCheckFunc) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Extract token from Authorization header
 			authHeader := r.Header.Get("Authorization")
 
+			// API keys of machine clients
+			apiKey := strings.TrimPrefix(authHeader, "ApiKey ")
+			if apiKey == authHeader {
+				apiKey = ""
+			}
+			if authHeader == "" {
+				apiKey = r.Header.Get("X-API-Key")
+			}
+			if apiKey != "" {
+				if apiKeys == nil {
+					http.Error(w, "API keys are not supported", http.StatusUnauthorized)
+					return
+				}
+				claims, err := apiKeys(r.Context(), apiKey)
+				if errors.Is(err, ErrUnauthorized) {
+					http.Error(w, "Invalid or expired API key", http.StatusUnauthorized)
+					return
+				}
+				if err != nil {
+					log.Printf("❌ Failed to authenticate API key: %v", err)
+					http.Error(w, "Failed to authenticate API key", http.StatusBadGateway)
+					return
+				}
+				next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), claims)))
+				return
+			}
+
 			// If no auth header, continue without user context
 			// (some queries/mutations may be public like login/register)
 			if authHeader == "" {
@@ -43,6 +77,20 @@ func Middleware(jwtManager *JWTManager) func(http.Handler) http.Handler {
 				http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
 				return
 			}
+			if checkUser != nil {
+				ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+				err := checkUser(ctx, claims)
+				cancel()
+				if errors.Is(err, ErrUserInactive) {
+					http.Error(w, "Session revoked or user deactivated", http.StatusUnauthorized)
+					return
+				}
+				if err != nil {
+					log.Printf("❌ Failed to check user %s: %v", claims.UserID, err)
+					http.Error(w, "Failed to check user", http.StatusBadGateway)
+					return
+				}
+			}
 
 			// Add user claims to context
 			ctx := context.WithValue(r.Context(), UserContextKey, claims)
@@ -71,14 +119,19 @@ func RequireAuth(ctx context.Context) (*Claims, error) {
 	return user, nil
 }
 
-// RequireRole checks if user has the required role
+// RequireRole checks if user has the required role. Platform admins are
+// always granted; the admin role is only granted to them, not to API keys of
+// admins scoped to some permissions.
 func RequireRole(ctx context.Context, requiredRole string) (*Claims, error) {
 	user, err := RequireAuth(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	if user.Role != requiredRole && user.Role != "admin" {
+	if user.IsPlatformAdmin() {
+		return user, nil
+	}
+	if requiredRole == "admin" || user.Role != requiredRole {
 		return nil, ErrForbidden
 	}
 
@@ -4,6 +4,7 @@ package auth
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -12,7 +13,7 @@ import (
 
 func TestMiddleware_NoAuthHeader(t *testing.T) {
 	jwtManager := NewJWTManager("test-secret", 1*time.Hour)
-	middleware := Middleware(jwtManager)
+	middleware := Middleware(jwtManager, nil, nil)
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Check that user is not in context
@@ -37,7 +38,7 @@ func TestMiddleware_NoAuthHeader(t *testing.T) {
 
 func TestMiddleware_ValidToken(t *testing.T) {
 	jwtManager := NewJWTManager("test-secret", 1*time.Hour)
-	middleware := Middleware(jwtManager)
+	middleware := Middleware(jwtManager, nil, nil)
 
 	// Generate a valid token
 	userID := "user-123"
@@ -90,7 +91,7 @@ func TestMiddleware_ValidToken(t *testing.T) {
 
 func TestMiddleware_InvalidToken(t *testing.T) {
 	jwtManager := NewJWTManager("test-secret", 1*time.Hour)
-	middleware := Middleware(jwtManager)
+	middleware := Middleware(jwtManager, nil, nil)
 
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		t.Error("Handler should not be called with invalid token")
@@ -112,7 +113,7 @@ func TestMiddleware_InvalidToken(t *testing.T) {
 
 func TestMiddleware_ExpiredToken(t *testing.T) {
 	jwtManager := NewJWTManager("test-secret", -1*time.Hour) // Expired token
-	middleware := Middleware(NewJWTManager("test-secret", 1*time.Hour))
+	middleware := Middleware(NewJWTManager("test-secret", 1*time.Hour), nil, nil)
 
 	// Generate an expired token
 	token, err := jwtManager.GenerateToken("user-123", "test@example.com", "Test User", "user")
@@ -140,7 +141,7 @@ func TestMiddleware_ExpiredToken(t *testing.T) {
 
 func TestMiddleware_InvalidBearerFormat(t *testing.T) {
 	jwtManager := NewJWTManager("test-secret", 1*time.Hour)
-	middleware := Middleware(jwtManager)
+	middleware := Middleware(jwtManager, nil, nil)
 
 	tests := []struct {
 		name   string
@@ -335,6 +336,21 @@ func TestRequireRole(t *testing.T) {
 			requiredRole: "user",
 			wantErr:      false,
 		},
+		{
+			name: "admin API key scoped to some permissions cannot access admin role",
+			setupCtx: func() context.Context {
+				claims := &Claims{
+					UserID:      "admin-123",
+					Role:        "admin",
+					APIKeyID:    "key-1",
+					Permissions: []string{PermDevicesRead},
+				}
+				return context.WithValue(context.Background(), UserContextKey, claims)
+			},
+			requiredRole: "admin",
+			wantErr:      true,
+			wantErrType:  ErrForbidden,
+		},
 		{
 			name: "user cannot access admin role",
 			setupCtx: func() context.Context {
@@ -380,3 +396,166 @@ func TestRequireRole(t *testing.T) {
 		})
 	}
 }
+
+func TestMiddleware_APIKey(t *testing.T) {
+	jwtManager := NewJWTManager("test-secret", 1*time.Hour)
+	apiKeys := func(ctx context.Context, key string) (*Claims, error) {
+		switch key {
+		case "iotk_valid":
+			return &Claims{UserID: "bot-1", Role: "user", APIKeyID: "key-1"}, nil
+		case "iotk_unavailable":
+			return nil, errors.New("user service unavailable")
+		}
+		return nil, ErrUnauthorized
+	}
+
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		apiKeys  APIKeyAuthenticator
+		wantCode int
+	}{
+		{"authorization_header", map[string]string{"Authorization": "ApiKey iotk_valid"}, apiKeys, http.StatusOK},
+		{"x_api_key_header", map[string]string{"X-API-Key": "iotk_valid"}, apiKeys, http.StatusOK},
+		{"invalid_key", map[string]string{"X-API-Key": "iotk_invalid"}, apiKeys, http.StatusUnauthorized},
+		{"user_service_down", map[string]string{"X-API-Key": "iotk_unavailable"}, apiKeys, http.StatusBadGateway},
+		{"keys_disabled", map[string]string{"X-API-Key": "iotk_valid"}, nil, http.StatusUnauthorized},
+		{"bearer_wins", map[string]string{"Authorization": "Bearer invalid", "X-API-Key": "iotk_valid"}, apiKeys, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				claims, ok := GetUserFromContext(r.Context())
+				if !ok || claims.APIKeyID != "key-1" {
+					t.Errorf("unexpected claims %+v", claims)
+				}
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest("POST", "/query", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			rr := httptest.NewRecorder()
+			Middleware(jwtManager, tt.apiKeys, nil)(handler).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", rr.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestMiddleware_CheckUser(t *testing.T) {
+	jwtManager := NewJWTManager("test-secret", 1*time.Hour)
+	checkUser := func(ctx context.Context, claims *Claims) error {
+		switch claims.SessionID {
+		case "revoked":
+			return ErrUserInactive
+		case "unavailable":
+			return errors.New("user service unavailable")
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name      string
+		sessionID string
+		wantCode  int
+	}{
+		{"active", "active", http.StatusOK},
+		{"revoked", "revoked", http.StatusUnauthorized},
+		{"user_service_down", "unavailable", http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _, err := jwtManager.GenerateSessionToken("user-123", "test@example.com", "Test User", "user", tt.sessionID, "org-1", "member", nil)
+			if err != nil {
+				t.Fatalf("Failed to generate token: %v", err)
+			}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req := httptest.NewRequest("POST", "/query", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rr := httptest.NewRecorder()
+			Middleware(jwtManager, nil, checkUser)(handler).ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", rr.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestCachedUserCheck(t *testing.T) {
+	calls := 0
+	var result error
+	check := CachedUserCheck(func(ctx context.Context, claims *Claims) error {
+		calls++
+		return result
+	}, 50*time.Millisecond)
+	claims := &Claims{UserID: "user-123", SessionID: "session-1"}
+
+	// Transient errors are not cached
+	result = errors.New("user service unavailable")
+	if err := check(context.Background(), claims); err == nil {
+		t.Fatal("expected error")
+	}
+	result = nil
+	if err := check(context.Background(), claims); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+
+	// Results are cached per session until the ttl
+	result = ErrUserInactive
+	if err := check(context.Background(), claims); err != nil {
+		t.Fatalf("expected cached result, got %v", err)
+	}
+	if err := check(context.Background(), &Claims{UserID: "user-123", SessionID: "session-2"}); !errors.Is(err, ErrUserInactive) {
+		t.Fatalf("expected ErrUserInactive for another session, got %v", err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if err := check(context.Background(), claims); !errors.Is(err, ErrUserInactive) {
+		t.Fatalf("expected ErrUserInactive after ttl, got %v", err)
+	}
+	if calls != 4 {
+		t.Errorf("calls = %d, want 4", calls)
+	}
+}
+
+func TestClientFromRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"remote_addr", nil, "192.0.2.1"},
+		{"forwarded_for", map[string]string{"X-Forwarded-For": "203.0.113.7, 10.0.0.1"}, "203.0.113.7"},
+		{"real_ip", map[string]string{"X-Real-IP": "203.0.113.8"}, "203.0.113.8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/query", nil)
+			req.Header.Set("User-Agent", "test-agent")
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+
+			var client Client
+			ClientMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				client = ClientFromContext(r.Context())
+			})).ServeHTTP(httptest.NewRecorder(), req)
+
+			if client.IP != tt.want || client.UserAgent != "test-agent" {
+				t.Errorf("client = %+v, want IP %s", client, tt.want)
+			}
+		})
+	}
+}
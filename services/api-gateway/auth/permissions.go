@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+)
+
+// Permissions checked by the gateway. Roles grant them, see the User Service.
+const (
+	PermDevicesRead      = "devices:read"
+	PermDevicesWrite     = "devices:write"
+	PermDeviceTypesWrite = "device-types:write"
+	PermTelemetryRead    = "telemetry:read"
+	PermTelemetryWrite   = "telemetry:write"
+	PermMetricsWrite     = "metrics:write"
+	PermRetentionAdmin   = "retention:admin"
+	PermUsersAdmin       = "users:admin"
+	PermRolesAdmin       = "roles:admin"
+	PermSystemRead       = "system:read"
+	PermOrgsAdmin        = "orgs:admin"
+
+	// PermAll grants every permission
+	PermAll = "*"
+)
+
+// Scope tells where a permission applies
+type Scope string
+
+const (
+	// ScopeOrganization permissions apply to the organization of the token,
+	// granted by the role of the user in that organization
+	ScopeOrganization Scope = "organization"
+	// ScopePlatform permissions apply to shared configuration and every
+	// organization, granted by the platform role of the user
+	ScopePlatform Scope = "platform"
+)
+
+// Permission describes a permission roles may grant
+type Permission struct {
+	Name        string
+	Description string
+	Scope       Scope
+}
+
+// Permissions is the catalog of the permissions checked by the gateway
+var Permissions = []Permission{
+	{PermDevicesRead, "List devices, device types and statistics", ScopeOrganization},
+	{PermDevicesWrite, "Create, update and delete devices", ScopeOrganization},
+	{PermDeviceTypesWrite, "Change device types and their metric catalog", ScopePlatform},
+	{PermTelemetryRead, "Read, export and subscribe to telemetry of every device", ScopeOrganization},
+	{PermTelemetryWrite, "Import historical telemetry", ScopeOrganization},
+	{PermMetricsWrite, "Change derived metrics", ScopePlatform},
+	{PermRetentionAdmin, "View, change and run retention policies", ScopePlatform},
+	{PermUsersAdmin, "Add members, list them, change their role, grant them device access and revoke their sessions", ScopeOrganization},
+	{PermRolesAdmin, "Manage custom roles", ScopePlatform},
+	{PermSystemRead, "View gateway statistics", ScopePlatform},
+	{PermOrgsAdmin, "Create organizations and list all of them", ScopePlatform},
+}
+
+// IsPermission reports whether name is a permission of the catalog, or PermAll
+func IsPermission(name string) bool {
+	if name == PermAll {
+		return true
+	}
+	for _, p := range Permissions {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenPermissions returns the permissions of a token: the platform-scoped
+// permissions of the platform role and the organization-scoped permissions of
+// the role in the organization. A platform role granting PermAll grants
+// everything everywhere.
+func TokenPermissions(platform, organization []string) []string {
+	if contains(platform, PermAll) {
+		return []string{PermAll}
+	}
+
+	permissions := []string{}
+	for _, p := range Permissions {
+		granted := organization
+		if p.Scope == ScopePlatform {
+			granted = platform
+		}
+		if contains(granted, p.Name) || contains(granted, PermAll) {
+			permissions = append(permissions, p.Name)
+		}
+	}
+	return permissions
+}
+
+// ScopePermissions returns the permissions of granted that scope keeps, all
+// of them if scope is empty. API keys are scoped this way: they never grant
+// more than their owner has.
+func ScopePermissions(granted, scope []string) []string {
+	if len(scope) == 0 {
+		return granted
+	}
+	if contains(granted, PermAll) && contains(scope, PermAll) {
+		return []string{PermAll}
+	}
+
+	permissions := []string{}
+	for _, p := range Permissions {
+		if (contains(granted, p.Name) || contains(granted, PermAll)) && (contains(scope, p.Name) || contains(scope, PermAll)) {
+			permissions = append(permissions, p.Name)
+		}
+	}
+	return permissions
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultOrganizationID is the organization of tokens issued without one,
+// where existing users and devices were moved when organizations were added
+const DefaultOrganizationID = "00000000-0000-0000-0000-000000000001"
+
+// Organization returns the organization the token acts in
+func (c *Claims) Organization() string {
+	if c.OrgID == "" {
+		return DefaultOrganizationID
+	}
+	return c.OrgID
+}
+
+// IsPlatformAdmin reports whether the claims belong to an administrator of
+// every organization. API keys of admins scoped to some permissions are not.
+func (c *Claims) IsPlatformAdmin() bool {
+	return c.Role == "admin" && c.HasPermission(PermAll)
+}
+
+// builtInRolePermissions are the permissions of the built-in roles, for
+// tokens issued without a permissions claim
+var builtInRolePermissions = map[string][]string{
+	"admin":  {PermAll},
+	"user":   {PermDevicesRead, PermDevicesWrite, PermTelemetryRead},
+	"device": {},
+}
+
+// HasPermission reports whether the claims grant permission
+func (c *Claims) HasPermission(permission string) bool {
+	permissions := c.Permissions
+	if permissions == nil {
+		permissions = builtInRolePermissions[c.Role]
+	}
+	for _, p := range permissions {
+		if p == permission || p == PermAll {
+			return true
+		}
+	}
+	return false
+}
+
+// RequirePermission checks that the user of ctx is granted permission
+func RequirePermission(ctx context.Context, permission string) (*Claims, error) {
+	user, err := RequireAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.HasPermission(permission) {
+		return nil, fmt.Errorf("%w: %s required", ErrForbidden, permission)
+	}
+
+	return user, nil
+}
+
+// DeviceAuthorizer checks that the user of ctx may access deviceIDs with
+// permission, every device of the organization if deviceIDs is empty. It
+// applies the ACL entries of the user, see graph.Resolver.AuthorizeDevices.
+type DeviceAuthorizer func(ctx context.Context, deviceIDs []string, permission string) error
@@ -0,0 +1,155 @@
+// +build unit
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClaims_HasPermission(t *testing.T) {
+	tests := []struct {
+		name       string
+		claims     *Claims
+		permission string
+		want       bool
+	}{
+		{"admin_wildcard", &Claims{Role: "admin", Permissions: []string{PermAll}}, PermRolesAdmin, true},
+		{"granted", &Claims{Role: "viewer", Permissions: []string{PermDevicesRead}}, PermDevicesRead, true},
+		{"not_granted", &Claims{Role: "viewer", Permissions: []string{PermDevicesRead}}, PermDevicesWrite, false},
+		{"empty_permissions", &Claims{Role: "user", Permissions: []string{}}, PermDevicesRead, false},
+		{"legacy_admin", &Claims{Role: "admin"}, PermUsersAdmin, true},
+		{"legacy_user", &Claims{Role: "user"}, PermTelemetryRead, true},
+		{"legacy_user_admin_permission", &Claims{Role: "user"}, PermRetentionAdmin, false},
+		{"legacy_device", &Claims{Role: "device"}, PermDevicesRead, false},
+		{"legacy_unknown_role", &Claims{Role: "viewer"}, PermDevicesRead, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.claims.HasPermission(tt.permission); got != tt.want {
+				t.Errorf("HasPermission(%q) = %v, want %v", tt.permission, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	if _, err := RequirePermission(context.Background(), PermDevicesRead); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized without user, got %v", err)
+	}
+
+	ctx := WithUser(context.Background(), &Claims{UserID: "user-1", Role: "viewer", Permissions: []string{PermDevicesRead}})
+	user, err := RequirePermission(ctx, PermDevicesRead)
+	if err != nil {
+		t.Fatalf("RequirePermission failed: %v", err)
+	}
+	if user.UserID != "user-1" {
+		t.Errorf("expected user-1, got %s", user.UserID)
+	}
+
+	if _, err := RequirePermission(ctx, PermDevicesWrite); !errors.Is(err, ErrForbidden) {
+		t.Errorf("expected ErrForbidden, got %v", err)
+	}
+}
+
+func TestIsPermission(t *testing.T) {
+	for _, p := range Permissions {
+		if !IsPermission(p.Name) {
+			t.Errorf("catalog permission %s not recognized", p.Name)
+		}
+	}
+	if !IsPermission(PermAll) {
+		t.Error("wildcard should be a permission")
+	}
+	if IsPermission("devices:delete") {
+		t.Error("unknown permission should not be recognized")
+	}
+}
+
+func TestJWT_PermissionsClaim(t *testing.T) {
+	manager := NewJWTManager("test-secret", time.Hour)
+
+	token, _, err := manager.GenerateSessionToken("user-1", "viewer@example.com", "Viewer", "viewer", "session-1", "", "", []string{PermDevicesRead})
+	if err != nil {
+		t.Fatalf("GenerateSessionToken failed: %v", err)
+	}
+	claims, err := manager.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+	if len(claims.Permissions) != 1 || claims.Permissions[0] != PermDevicesRead {
+		t.Errorf("unexpected permissions %v", claims.Permissions)
+	}
+	if claims.HasPermission(PermDevicesWrite) {
+		t.Error("token should not grant devices:write")
+	}
+}
+
+func TestTokenPermissions(t *testing.T) {
+	tests := []struct {
+		name         string
+		platform     []string
+		organization []string
+		want         []string
+	}{
+		{"platform_admin", []string{PermAll}, nil, []string{PermAll}},
+		{"organization_admin", []string{PermDevicesRead}, []string{PermAll}, []string{PermDevicesRead, PermDevicesWrite, PermTelemetryRead, PermTelemetryWrite, PermUsersAdmin}},
+		{"organization_role", nil, []string{PermDevicesRead, PermTelemetryRead}, []string{PermDevicesRead, PermTelemetryRead}},
+		{"platform_scoped_from_organization", nil, []string{PermRetentionAdmin, PermDevicesRead}, []string{PermDevicesRead}},
+		{"organization_scoped_from_platform", []string{PermDevicesWrite, PermSystemRead}, nil, []string{PermSystemRead}},
+		{"none", nil, nil, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TokenPermissions(tt.platform, tt.organization)
+			if len(got) != len(tt.want) {
+				t.Fatalf("TokenPermissions() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("TokenPermissions() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestScopePermissions(t *testing.T) {
+	tests := []struct {
+		name    string
+		granted []string
+		scope   []string
+		want    []string
+	}{
+		{"unscoped", []string{PermDevicesRead, PermTelemetryRead}, nil, []string{PermDevicesRead, PermTelemetryRead}},
+		{"narrowed", []string{PermDevicesRead, PermTelemetryRead}, []string{PermTelemetryRead}, []string{PermTelemetryRead}},
+		{"not_granted", []string{PermDevicesRead}, []string{PermDevicesRead, PermUsersAdmin}, []string{PermDevicesRead}},
+		{"admin_narrowed", []string{PermAll}, []string{PermTelemetryWrite}, []string{PermTelemetryWrite}},
+		{"admin_wildcard", []string{PermAll}, []string{PermAll}, []string{PermAll}},
+		{"wildcard_scope", []string{PermDevicesRead}, []string{PermAll}, []string{PermDevicesRead}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ScopePermissions(tt.granted, tt.scope)
+			if len(got) != len(tt.want) {
+				t.Fatalf("ScopePermissions() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("ScopePermissions() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	// Scoped keys of admins do not administer every organization
+	claims := &Claims{Role: "admin", Permissions: ScopePermissions([]string{PermAll}, []string{PermDevicesRead})}
+	if claims.IsPlatformAdmin() {
+		t.Error("admin key scoped to devices:read is a platform admin")
+	}
+}
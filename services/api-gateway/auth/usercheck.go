@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	userpb "github.com/yourusername/iot-platform/shared/proto/user"
+)
+
+// ErrUserInactive is returned by user checks for deactivated or deleted users,
+// and for revoked sessions
+var ErrUserInactive = errors.New("user is deactivated")
+
+// UserCheckFunc reports whether the user of claims may still use the API: an
+// error wrapping ErrUserInactive if not, any other error if unknown.
+type UserCheckFunc func(ctx context.Context, claims *Claims) error
+
+// UserClientCheck checks users against the User Service, and the session of
+// tokens carrying one (sid claim) against the active sessions of the user
+func UserClientCheck(client userpb.UserServiceClient) UserCheckFunc {
+	return func(ctx context.Context, claims *Claims) error {
+		resp, err := client.GetUser(ctx, &userpb.GetUserRequest{Id: claims.UserID})
+		if status.Code(err) == codes.NotFound {
+			return ErrUserInactive
+		}
+		if err != nil {
+			return err
+		}
+		if !resp.User.IsActive {
+			return ErrUserInactive
+		}
+		if claims.SessionID == "" {
+			return nil
+		}
+
+		sessions, err := client.ListSessions(ctx, &userpb.ListSessionsRequest{UserId: claims.UserID})
+		if err != nil {
+			return err
+		}
+		for _, session := range sessions.Sessions {
+			if session.Id == claims.SessionID {
+				return nil
+			}
+		}
+		return ErrUserInactive
+	}
+}
+
+// CachedUserCheck caches the results of check for ttl per user and session,
+// so that checking every HTTP request costs at most one call per ttl. Errors
+// other than ErrUserInactive are not cached.
+func CachedUserCheck(check UserCheckFunc, ttl time.Duration) UserCheckFunc {
+	c := &userCheckCache{check: check, ttl: ttl, entries: make(map[string]userCheckEntry)}
+	return c.Check
+}
+
+type userCheckEntry struct {
+	err     error
+	expires time.Time
+}
+
+type userCheckCache struct {
+	check UserCheckFunc
+	ttl   time.Duration
+
+	mu        sync.Mutex
+	entries   map[string]userCheckEntry
+	lastSweep time.Time
+}
+
+func (c *userCheckCache) Check(ctx context.Context, claims *Claims) error {
+	key := claims.UserID + "/" + claims.SessionID
+	now := time.Now()
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.err
+	}
+
+	err := c.check(ctx, claims)
+	if err != nil && !errors.Is(err, ErrUserInactive) {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = userCheckEntry{err: err, expires: now.Add(c.ttl)}
+	// Drop expired entries once per ttl
+	if now.Sub(c.lastSweep) >= c.ttl {
+		for k, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		c.lastSweep = now
+	}
+	return err
+}
@@ -0,0 +1,246 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/gorilla/websocket"
+)
+
+// WebsocketAuth authenticates GraphQL WebSocket connections. The token, or
+// "ApiKey <key>", is read from the connectionParams Authorization key, or
+// else from the headers of the upgrade request. The connection is closed when
+// the token expires unless refreshed first, and when the user is deactivated.
+//
+// Close codes require the WebsocketCloser middleware; without it the
+// connection is closed with 1000.
+type WebsocketAuth struct {
+	JWTManager *JWTManager
+	// APIKeys authenticates API keys, nil to only accept tokens
+	APIKeys APIKeyAuthenticator
+	// CheckUser is called at connection and every CheckInterval, nil to skip
+	CheckUser     UserCheckFunc
+	CheckInterval time.Duration
+}
+
+type sessionKey struct{}
+
+// WebsocketSession is the authentication state of a WebSocket connection
+type WebsocketSession struct {
+	auth   *WebsocketAuth
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+
+	mu      sync.Mutex
+	claims  *Claims
+	expiry  *time.Timer
+	expired bool
+}
+
+// InitFunc authenticates the connection_init message. Connections without
+// valid token are closed with CloseUnauthorized or CloseForbidden.
+func (a *WebsocketAuth) InitFunc(ctx context.Context, initPayload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+	var claims *Claims
+	authorization := initPayload.Authorization()
+	if key := strings.TrimPrefix(authorization, "ApiKey "); key != authorization && a.APIKeys != nil {
+		var err error
+		claims, err = a.APIKeys(ctx, key)
+		if errors.Is(err, ErrUnauthorized) {
+			return ctx, nil, reject(ctx, CloseForbidden, "invalid API key")
+		}
+		if err != nil {
+			log.Printf("❌ Failed to authenticate API key: %v", err)
+			return ctx, nil, reject(ctx, websocket.CloseTryAgainLater, "API key check failed")
+		}
+	} else if token := strings.TrimPrefix(authorization, "Bearer "); token != "" {
+		var err error
+		claims, err = a.JWTManager.ValidateToken(token)
+		if errors.Is(err, ErrExpiredToken) {
+			return ctx, nil, reject(ctx, CloseForbidden, "token expired")
+		}
+		if err != nil {
+			return ctx, nil, reject(ctx, CloseForbidden, "invalid token")
+		}
+	} else if user, ok := GetUserFromContext(ctx); ok {
+		claims = user
+	} else {
+		return ctx, nil, reject(ctx, CloseUnauthorized, "missing token")
+	}
+
+	if err := a.checkUser(ctx, claims); errors.Is(err, ErrUserInactive) {
+		return ctx, nil, reject(ctx, CloseForbidden, "user deactivated")
+	} else if err != nil {
+		log.Printf("❌ Failed to check user %s: %v", claims.UserID, err)
+		return ctx, nil, reject(ctx, websocket.CloseTryAgainLater, "user check failed")
+	}
+
+	ctx, session := a.startSession(ctx, claims)
+	ctx = context.WithValue(WithUser(ctx, claims), sessionKey{}, session)
+	ack := transport.InitPayload{}
+	if claims.ExpiresAt != nil {
+		ack["expiresAt"] = claims.ExpiresAt.Unix()
+	}
+	return ctx, &ack, nil
+}
+
+// startSession returns a context of ctx ended when the token of claims
+// expires or the user is deactivated, and the session watching it
+func (a *WebsocketAuth) startSession(ctx context.Context, claims *Claims) (context.Context, *WebsocketSession) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	session := &WebsocketSession{auth: a, ctx: ctx, cancel: cancel, claims: claims}
+	session.startExpiry()
+	context.AfterFunc(ctx, session.stopExpiry)
+	if a.CheckUser != nil && a.CheckInterval > 0 {
+		go session.watchUser()
+	}
+	return ctx, session
+}
+
+// StreamContext applies the same rules to streams on other transports than
+// WebSocket, such as Server-Sent Events: it returns a context of ctx canceled
+// when the token of the request user expires or the user is deactivated,
+// with cause ErrExpiredToken or ErrUserInactive. Requests without user are
+// left to AuthExtension. An error wrapping ErrUserInactive is returned if the
+// user is already deactivated. Cancel releases the watch.
+func (a *WebsocketAuth) StreamContext(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	claims, ok := GetUserFromContext(ctx)
+	if !ok {
+		ctx, cancel := context.WithCancel(ctx)
+		return ctx, cancel, nil
+	}
+	if err := a.checkUser(ctx, claims); err != nil {
+		return nil, nil, err
+	}
+	ctx, session := a.startSession(ctx, claims)
+	return ctx, func() { session.cancel(context.Canceled) }, nil
+}
+
+// reject closes the connection with code and returns the error for gqlgen
+func reject(ctx context.Context, code int, reason string) error {
+	if err := CloseWebsocket(ctx, code, reason); err != nil && !errors.Is(err, ErrNoWebsocket) {
+		log.Printf("⚠️ Failed to close WebSocket: %v", err)
+	}
+	return errors.New(reason)
+}
+
+func (a *WebsocketAuth) checkUser(ctx context.Context, claims *Claims) error {
+	if a.CheckUser == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return a.CheckUser(ctx, claims)
+}
+
+// WebsocketSessionFromContext returns the session of the WebSocket connection
+// an operation runs on
+func WebsocketSessionFromContext(ctx context.Context) (*WebsocketSession, bool) {
+	session, ok := ctx.Value(sessionKey{}).(*WebsocketSession)
+	return session, ok
+}
+
+// Refresh replaces the token of the connection with a newer one of the same
+// user and role, and returns its expiry
+func (s *WebsocketSession) Refresh(ctx context.Context, token string) (time.Time, error) {
+	claims, err := s.auth.JWTManager.ValidateToken(strings.TrimPrefix(token, "Bearer "))
+	if err != nil {
+		return time.Time{}, err
+	}
+	s.mu.Lock()
+	current := s.claims
+	s.mu.Unlock()
+	// Operations read the claims the connection was opened with
+	// Subscriptions keep the organization they were opened in
+	if claims.UserID != current.UserID || claims.Role != current.Role || claims.Organization() != current.Organization() {
+		return time.Time{}, errors.New("token belongs to another user, role or organization: reconnect instead")
+	}
+	if err := s.auth.checkUser(ctx, claims); err != nil {
+		return time.Time{}, fmt.Errorf("user check failed: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.expired || (s.expiry != nil && !s.expiry.Stop()) {
+		return time.Time{}, ErrExpiredToken
+	}
+	s.claims = claims
+	s.startExpiryLocked()
+	if claims.ExpiresAt == nil {
+		return time.Time{}, nil
+	}
+	return claims.ExpiresAt.Time, nil
+}
+
+func (s *WebsocketSession) startExpiry() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.startExpiryLocked()
+}
+
+func (s *WebsocketSession) startExpiryLocked() {
+	if s.claims.ExpiresAt == nil {
+		return
+	}
+	s.expiry = time.AfterFunc(time.Until(s.claims.ExpiresAt.Time), func() {
+		s.mu.Lock()
+		s.expired = true
+		s.mu.Unlock()
+		s.terminate(CloseForbidden, "token expired", ErrExpiredToken)
+	})
+}
+
+func (s *WebsocketSession) stopExpiry() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.expiry != nil {
+		s.expiry.Stop()
+	}
+}
+
+// watchUser closes the connection once the user is deactivated. Failed
+// checks keep it open.
+func (s *WebsocketSession) watchUser() {
+	ticker := time.NewTicker(s.auth.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		s.mu.Lock()
+		claims := s.claims
+		s.mu.Unlock()
+		err := s.auth.checkUser(s.ctx, claims)
+		if errors.Is(err, ErrUserInactive) {
+			s.terminate(CloseForbidden, "user deactivated", ErrUserInactive)
+			return
+		}
+		if err != nil && s.ctx.Err() == nil {
+			log.Printf("⚠️ Failed to check user %s: %v", claims.UserID, err)
+		}
+	}
+}
+
+// terminate closes the connection with code, ending its subscriptions with
+// cause
+func (s *WebsocketSession) terminate(code int, reason string, cause error) {
+	if s.ctx.Err() != nil {
+		return
+	}
+	s.mu.Lock()
+	userID := s.claims.UserID
+	s.mu.Unlock()
+	log.Printf("🔒 Closing connection of user %s: %s", userID, reason)
+	if err := CloseWebsocket(s.ctx, code, reason); err != nil && !errors.Is(err, ErrNoWebsocket) {
+		log.Printf("⚠️ Failed to close WebSocket: %v", err)
+	}
+	s.cancel(cause)
+}
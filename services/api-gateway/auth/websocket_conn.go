@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket close codes of the graphql-transport-ws protocol
+const (
+	// CloseUnauthorized ends connections without token. graphql-ws clients
+	// do not reconnect after it.
+	CloseUnauthorized = 4401
+	// CloseForbidden ends connections with an invalid or expired token, or of
+	// a deactivated user. graphql-ws clients reconnect, with fresh
+	// connectionParams.
+	CloseForbidden = 4403
+)
+
+// ErrNoWebsocket is returned by CloseWebsocket outside of a WebSocket
+// connection tracked by WebsocketCloser
+var ErrNoWebsocket = errors.New("no websocket connection")
+
+type websocketKey struct{}
+
+// websocketHolder receives the connection of an upgrade request once hijacked
+type websocketHolder struct {
+	mu   sync.Mutex
+	conn *websocketConn
+}
+
+// WebsocketCloser is an HTTP middleware that keeps the connections of
+// WebSocket upgrade requests reachable from their context, so that
+// CloseWebsocket can end them with an application close code: gqlgen always
+// closes with 1000.
+func WebsocketCloser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !websocket.IsWebSocketUpgrade(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		holder := &websocketHolder{}
+		ctx := context.WithValue(r.Context(), websocketKey{}, holder)
+		next.ServeHTTP(&hijackResponseWriter{ResponseWriter: w, holder: holder}, r.WithContext(ctx))
+	})
+}
+
+// CloseWebsocket sends a close frame with code and reason on the WebSocket
+// connection of ctx. Messages written after it are discarded; the connection
+// itself is closed by its transport.
+func CloseWebsocket(ctx context.Context, code int, reason string) error {
+	holder, ok := ctx.Value(websocketKey{}).(*websocketHolder)
+	if !ok {
+		return ErrNoWebsocket
+	}
+	holder.mu.Lock()
+	conn := holder.conn
+	holder.mu.Unlock()
+	if conn == nil {
+		return ErrNoWebsocket
+	}
+	return conn.close(code, reason)
+}
+
+type hijackResponseWriter struct {
+	http.ResponseWriter
+	holder *websocketHolder
+}
+
+func (w *hijackResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response does not implement http.Hijacker")
+	}
+	netConn, brw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	conn := &websocketConn{Conn: netConn}
+	conn.frameDone = sync.NewCond(&conn.mu)
+	w.holder.mu.Lock()
+	w.holder.conn = conn
+	w.holder.mu.Unlock()
+	return conn, brw, nil
+}
+
+// websocketConn tracks the frames the WebSocket library writes, so that a
+// close frame can be inserted between two of them
+type websocketConn struct {
+	net.Conn
+
+	mu        sync.Mutex
+	frameDone *sync.Cond
+	upgraded  bool  // the handshake response has been written
+	pending   int64 // bytes of the current frame not written yet
+	closed    bool  // a close frame has been sent, or writing failed
+}
+
+func (c *websocketConn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return len(b), nil
+	}
+	if !c.upgraded {
+		c.upgraded = true
+		return c.Conn.Write(b)
+	}
+
+	c.track(b)
+	n, err := c.Conn.Write(b)
+	if err != nil {
+		c.closed = true
+	}
+	c.frameDone.Broadcast()
+	return n, err
+}
+
+// track advances the frame position over b. Server frames are unmasked and
+// their header is never split across writes.
+func (c *websocketConn) track(b []byte) {
+	for len(b) > 0 {
+		if c.pending == 0 {
+			size, ok := frameSize(b)
+			if !ok {
+				return
+			}
+			c.pending = size
+		}
+		n := min(int64(len(b)), c.pending)
+		c.pending -= n
+		b = b[n:]
+	}
+}
+
+// frameSize returns the size of the frame starting b, header included
+func frameSize(b []byte) (int64, bool) {
+	if len(b) < 2 {
+		return 0, false
+	}
+	switch length := int64(b[1] & 0x7f); length {
+	case 126:
+		if len(b) < 4 {
+			return 0, false
+		}
+		return 4 + int64(binary.BigEndian.Uint16(b[2:4])), true
+	case 127:
+		if len(b) < 10 {
+			return 0, false
+		}
+		return 10 + int64(binary.BigEndian.Uint64(b[2:10])), true
+	default:
+		return 2 + length, true
+	}
+}
+
+// close waits for the frame being written to complete and sends a close frame
+func (c *websocketConn) close(code int, reason string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.pending > 0 && !c.closed {
+		c.frameDone.Wait()
+	}
+	if c.closed || !c.upgraded {
+		return nil
+	}
+	c.closed = true
+
+	if len(reason) > 123 { // control frame payloads are limited to 125 bytes
+		reason = reason[:123]
+	}
+	payload := websocket.FormatCloseMessage(code, reason)
+	frame := append([]byte{0x80 | websocket.CloseMessage, byte(len(payload))}, payload...)
+	_ = c.Conn.SetWriteDeadline(time.Now().Add(time.Second))
+	_, err := c.Conn.Write(frame)
+	return err
+}
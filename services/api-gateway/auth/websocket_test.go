@@ -0,0 +1,283 @@
+// +build unit
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler/testserver"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/websocket"
+)
+
+type websocketServer struct {
+	*httptest.Server
+	gql      *testserver.TestServer
+	sessions chan *WebsocketSession
+}
+
+func newWebsocketServer(t *testing.T, wsAuth *WebsocketAuth) *websocketServer {
+	t.Helper()
+	s := &websocketServer{gql: testserver.New(), sessions: make(chan *WebsocketSession, 1)}
+	s.gql.AddTransport(transport.Websocket{
+		InitFunc: func(ctx context.Context, initPayload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+			ctx, ack, err := wsAuth.InitFunc(ctx, initPayload)
+			if session, ok := WebsocketSessionFromContext(ctx); ok {
+				s.sessions <- session
+			}
+			return ctx, ack, err
+		},
+	})
+	s.Server = httptest.NewServer(Middleware(wsAuth.JWTManager, wsAuth.APIKeys, nil)(WebsocketCloser(s.gql)))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// connect opens a graphql-transport-ws connection and sends connection_init
+// with payload
+func (s *websocketServer) connect(t *testing.T, payload string) *websocket.Conn {
+	t.Helper()
+	dialer := websocket.Dialer{Subprotocols: []string{"graphql-transport-ws"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(s.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"connection_init","payload":`+payload+`}`)); err != nil {
+		t.Fatalf("connection_init failed: %v", err)
+	}
+	return conn
+}
+
+// readClose reads messages until the connection is closed and returns the
+// close frame
+func readClose(t *testing.T, conn *websocket.Conn, timeout time.Duration) *websocket.CloseError {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(timeout))
+	for {
+		_, _, err := conn.ReadMessage()
+		if err == nil {
+			continue
+		}
+		var closeErr *websocket.CloseError
+		if !errors.As(err, &closeErr) {
+			t.Fatalf("expected close frame, got %v", err)
+		}
+		return closeErr
+	}
+}
+
+func expectAck(t *testing.T, conn *websocket.Conn) {
+	t.Helper()
+	var msg struct {
+		Type    string         `json:"type"`
+		Payload map[string]any `json:"payload"`
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("no connection_ack: %v", err)
+	}
+	if msg.Type != "connection_ack" || msg.Payload["expiresAt"] == nil {
+		t.Fatalf("expected connection_ack with expiresAt, got %+v", msg)
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+}
+
+func TestWebsocketAuth_RejectsBadTokens(t *testing.T) {
+	jwtManager := NewJWTManager("test-secret", time.Hour)
+	valid, _ := jwtManager.GenerateToken("user-1", "user@example.com", "User", "user")
+	inactive, _ := jwtManager.GenerateToken("user-2", "inactive@example.com", "Inactive", "user")
+	expired, _ := NewJWTManager("test-secret", -time.Hour).GenerateToken("user-1", "user@example.com", "User", "user")
+	forged, _ := NewJWTManager("other-secret", time.Hour).GenerateToken("user-1", "user@example.com", "User", "admin")
+
+	server := newWebsocketServer(t, &WebsocketAuth{
+		JWTManager: jwtManager,
+		CheckUser: func(ctx context.Context, claims *Claims) error {
+			if claims.UserID == "user-2" {
+				return ErrUserInactive
+			}
+			return nil
+		},
+	})
+
+	tests := []struct {
+		name    string
+		payload string
+		code    int
+		reason  string
+	}{
+		{"missing_token", `{}`, CloseUnauthorized, "missing token"},
+		{"invalid_token", `{"Authorization":"garbage"}`, CloseForbidden, "invalid token"},
+		{"forged_token", `{"Authorization":"` + forged + `"}`, CloseForbidden, "invalid token"},
+		{"expired_token", `{"Authorization":"` + expired + `"}`, CloseForbidden, "token expired"},
+		{"deactivated_user", `{"Authorization":"Bearer ` + inactive + `"}`, CloseForbidden, "user deactivated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := server.connect(t, tt.payload)
+			closeErr := readClose(t, conn, time.Second)
+			if closeErr.Code != tt.code || closeErr.Text != tt.reason {
+				t.Errorf("expected close %d %q, got %d %q", tt.code, tt.reason, closeErr.Code, closeErr.Text)
+			}
+		})
+	}
+
+	conn := server.connect(t, `{"Authorization":"`+valid+`"}`)
+	expectAck(t, conn)
+}
+
+// TestWebsocketAuth_Expiry checks that a connection streaming a subscription
+// is closed when its token expires, with a well-formed close frame.
+func TestWebsocketAuth_Expiry(t *testing.T) {
+	jwtManager := NewJWTManager("test-secret", 2*time.Second) // exp has a one second precision
+	token, _ := jwtManager.GenerateToken("user-1", "user@example.com", "User", "user")
+	server := newWebsocketServer(t, &WebsocketAuth{JWTManager: jwtManager})
+
+	conn := server.connect(t, `{"Authorization":"`+token+`"}`)
+	expectAck(t, conn)
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"id":"1","type":"subscribe","payload":{"query":"subscription { name }"}}`)); err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+
+	// Stream results until the connection closes, so that the close frame
+	// is sent while messages are being written
+	var stop atomic.Bool
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for !stop.Load() {
+			server.gql.SendNextSubscriptionMessage()
+		}
+	}()
+
+	closeErr := readClose(t, conn, 3*time.Second)
+	stop.Store(true)
+	<-done
+	if closeErr.Code != CloseForbidden || closeErr.Text != "token expired" {
+		t.Errorf("expected close %d token expired, got %d %q", CloseForbidden, closeErr.Code, closeErr.Text)
+	}
+}
+
+func TestWebsocketAuth_Refresh(t *testing.T) {
+	jwtManager := NewJWTManager("test-secret", 2*time.Second)
+	token, _ := jwtManager.GenerateToken("user-1", "user@example.com", "User", "user")
+	longManager := NewJWTManager("test-secret", time.Hour)
+	refreshed, _ := longManager.GenerateToken("user-1", "user@example.com", "User", "user")
+	other, _ := longManager.GenerateToken("user-2", "other@example.com", "Other", "user")
+	server := newWebsocketServer(t, &WebsocketAuth{JWTManager: jwtManager})
+
+	conn := server.connect(t, `{"Authorization":"`+token+`"}`)
+	expectAck(t, conn)
+	session := <-server.sessions
+
+	if _, err := session.Refresh(context.Background(), other); err == nil {
+		t.Error("expected error refreshing with another user's token")
+	}
+	if _, err := session.Refresh(context.Background(), "garbage"); err == nil {
+		t.Error("expected error refreshing with an invalid token")
+	}
+	expiresAt, err := session.Refresh(context.Background(), refreshed)
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if time.Until(expiresAt) < 59*time.Minute {
+		t.Errorf("unexpected expiry %v", expiresAt)
+	}
+
+	// Past the first token's expiry the connection is still open
+	_ = conn.SetReadDeadline(time.Now().Add(2500 * time.Millisecond))
+	_, _, err = conn.ReadMessage()
+	if netErr, ok := err.(interface{ Timeout() bool }); !ok || !netErr.Timeout() {
+		t.Errorf("expected connection to stay open, got %v", err)
+	}
+}
+
+func TestWebsocketAuth_Deactivation(t *testing.T) {
+	jwtManager := NewJWTManager("test-secret", time.Hour)
+	token, _ := jwtManager.GenerateToken("user-1", "user@example.com", "User", "user")
+	var active atomic.Bool
+	active.Store(true)
+	server := newWebsocketServer(t, &WebsocketAuth{
+		JWTManager: jwtManager,
+		CheckUser: func(ctx context.Context, claims *Claims) error {
+			if !active.Load() {
+				return ErrUserInactive
+			}
+			return nil
+		},
+		CheckInterval: 20 * time.Millisecond,
+	})
+
+	conn := server.connect(t, `{"Authorization":"`+token+`"}`)
+	expectAck(t, conn)
+	active.Store(false)
+
+	closeErr := readClose(t, conn, time.Second)
+	if closeErr.Code != CloseForbidden || closeErr.Text != "user deactivated" {
+		t.Errorf("expected close %d user deactivated, got %d %q", CloseForbidden, closeErr.Code, closeErr.Text)
+	}
+}
+
+func TestWebsocketAuth_UpgradeHeader(t *testing.T) {
+	jwtManager := NewJWTManager("test-secret", time.Hour)
+	token, _ := jwtManager.GenerateToken("user-1", "user@example.com", "User", "user")
+	server := newWebsocketServer(t, &WebsocketAuth{JWTManager: jwtManager})
+
+	dialer := websocket.Dialer{Subprotocols: []string{"graphql-transport-ws"}}
+	header := http.Header{"Authorization": []string{"Bearer " + token}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), header)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"connection_init"}`)); err != nil {
+		t.Fatalf("connection_init failed: %v", err)
+	}
+	expectAck(t, conn)
+}
+
+func TestWebsocketAuth_APIKey(t *testing.T) {
+	server := newWebsocketServer(t, &WebsocketAuth{
+		JWTManager: NewJWTManager("test-secret", time.Hour),
+		APIKeys: func(ctx context.Context, key string) (*Claims, error) {
+			if key != "iotk_valid" {
+				return nil, ErrUnauthorized
+			}
+			claims := &Claims{UserID: "bot-1", Role: "user", APIKeyID: "key-1"}
+			claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Hour))
+			return claims, nil
+		},
+	})
+
+	conn := server.connect(t, `{"Authorization":"ApiKey iotk_invalid"}`)
+	if closeErr := readClose(t, conn, time.Second); closeErr.Code != CloseForbidden || closeErr.Text != "invalid API key" {
+		t.Errorf("expected close %d, got %d %q", CloseForbidden, closeErr.Code, closeErr.Text)
+	}
+
+	conn = server.connect(t, `{"Authorization":"ApiKey iotk_valid"}`)
+	expectAck(t, conn)
+	if session := <-server.sessions; session.claims.APIKeyID != "key-1" {
+		t.Errorf("unexpected claims %+v", session.claims)
+	}
+
+	// Clients that set headers authenticate the upgrade request instead
+	dialer := websocket.Dialer{Subprotocols: []string{"graphql-transport-ws"}}
+	header := http.Header{"X-Api-Key": []string{"iotk_valid"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), header)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"connection_init"}`)); err != nil {
+		t.Fatalf("connection_init failed: %v", err)
+	}
+	expectAck(t, conn)
+}
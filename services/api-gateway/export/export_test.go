@@ -0,0 +1,330 @@
+// +build unit
+
+package export
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/auth"
+	telemetrypb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+)
+
+var testRecords = []*telemetrypb.TelemetryRecord{
+	{DeviceId: "dev-1", MetricName: "temperature", Time: 1700000000, Value: 21.5, Unit: "celsius"},
+	{DeviceId: "dev-2", MetricName: "humidity", Time: 1700000060, Value: 40},
+}
+
+// mockExportClient serves ExportTelemetry from a fixed list of chunks.
+type mockExportClient struct {
+	telemetrypb.TelemetryServiceClient
+
+	chunks  []*telemetrypb.ExportTelemetryChunk
+	err     error // returned after all chunks
+	lastReq *telemetrypb.ExportTelemetryRequest
+}
+
+func (m *mockExportClient) ExportTelemetry(ctx context.Context, req *telemetrypb.ExportTelemetryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[telemetrypb.ExportTelemetryChunk], error) {
+	m.lastReq = req
+	return &mockExportStream{chunks: m.chunks, err: m.err}, nil
+}
+
+type mockExportStream struct {
+	grpc.ClientStream
+
+	chunks []*telemetrypb.ExportTelemetryChunk
+	err    error
+}
+
+func (s *mockExportStream) Recv() (*telemetrypb.ExportTelemetryChunk, error) {
+	if len(s.chunks) == 0 {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	}
+	chunk := s.chunks[0]
+	s.chunks = s.chunks[1:]
+	return chunk, nil
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf, false)
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+	if err := w.Write(testRecords); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	want := "time,device_id,metric_name,value,unit\n" +
+		"2023-11-14T22:13:20Z,dev-1,temperature,21.5,celsius\n" +
+		"2023-11-14T22:14:20Z,dev-2,humidity,40,\n"
+	if buf.String() != want {
+		t.Errorf("unexpected CSV output:\n%s", buf.String())
+	}
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatNDJSON, &buf, false)
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+	if err := w.Write(testRecords); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	if lines[0] != `{"time":"2023-11-14T22:13:20Z","device_id":"dev-1","metric_name":"temperature","value":21.5,"unit":"celsius"}` {
+		t.Errorf("unexpected first line: %s", lines[0])
+	}
+	if strings.Contains(lines[1], "unit") {
+		t.Errorf("expected empty unit to be omitted: %s", lines[1])
+	}
+}
+
+func TestNewWriter_UnknownFormat(t *testing.T) {
+	if _, err := NewWriter(Format("xml"), io.Discard, false); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
+
+// allowDevices grants access to every device
+func allowDevices(ctx context.Context, deviceIDs []string, permission string) error {
+	return nil
+}
+
+func TestHandler(t *testing.T) {
+	claims := &auth.Claims{UserID: "user-1", Email: "user@example.com", Role: "user"}
+
+	tests := []struct {
+		name       string
+		query      string
+		gzip       bool
+		noAuth     bool
+		claims     *auth.Claims
+		client     *mockExportClient
+		authorize  auth.DeviceAuthorizer
+		wantStatus int
+		validate   func(t *testing.T, rec *httptest.ResponseRecorder, client *mockExportClient)
+	}{
+		{
+			name:  "csv_download",
+			query: "from=1000&to=2000&device_id=dev-1&device_id=dev-2&metric=temperature",
+			client: &mockExportClient{chunks: []*telemetrypb.ExportTelemetryChunk{
+				{Records: testRecords[:1], Cursor: "c1"},
+				{Records: testRecords[1:], Cursor: "c2"},
+			}},
+			wantStatus: http.StatusOK,
+			validate: func(t *testing.T, rec *httptest.ResponseRecorder, client *mockExportClient) {
+				if got := rec.Header().Get("Content-Type"); got != FormatCSV.ContentType() {
+					t.Errorf("unexpected content type %q", got)
+				}
+				if len(client.lastReq.DeviceIds) != 2 || client.lastReq.MetricNames[0] != "temperature" {
+					t.Errorf("unexpected request: %v", client.lastReq)
+				}
+				if strings.Count(rec.Body.String(), "\n") != 3 {
+					t.Errorf("expected header + 2 rows, got:\n%s", rec.Body.String())
+				}
+				if _, ok := rec.Header()[ResumeHeader]; ok {
+					t.Error("resume header sent without cursor")
+				}
+			},
+		},
+		{
+			name:       "gzip_ndjson",
+			query:      "from=1000&to=2000&format=ndjson&cursor=1700000000%7Cdev-1%7Ctemperature",
+			gzip:       true,
+			client:     &mockExportClient{chunks: []*telemetrypb.ExportTelemetryChunk{{Records: testRecords, Cursor: "c1"}}},
+			wantStatus: http.StatusOK,
+			validate: func(t *testing.T, rec *httptest.ResponseRecorder, client *mockExportClient) {
+				if rec.Header().Get("Content-Encoding") != "gzip" {
+					t.Fatal("expected gzip content encoding")
+				}
+				if client.lastReq.Cursor != "1700000000|dev-1|temperature" {
+					t.Errorf("expected cursor to be forwarded, got %q", client.lastReq.Cursor)
+				}
+				gz, err := gzip.NewReader(rec.Body)
+				if err != nil {
+					t.Fatalf("gzip.NewReader() error = %v", err)
+				}
+				body, err := io.ReadAll(gz)
+				if err != nil {
+					t.Fatalf("failed to read gzip body: %v", err)
+				}
+				if strings.Count(string(body), "\n") != 2 {
+					t.Errorf("expected 2 NDJSON lines, got:\n%s", body)
+				}
+			},
+		},
+		{
+			name:       "unauthenticated",
+			query:      "from=1000&to=2000",
+			noAuth:     true,
+			client:     &mockExportClient{},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "missing_permission",
+			query:      "from=1000&to=2000",
+			claims:     &auth.Claims{UserID: "dev-1", Role: "device"},
+			client:     &mockExportClient{},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "device_not_granted",
+			query:  "from=1000&to=2000&device_id=dev-1&device_id=dev-2",
+			client: &mockExportClient{},
+			authorize: func(ctx context.Context, deviceIDs []string, permission string) error {
+				if len(deviceIDs) != 2 || permission != auth.PermTelemetryRead {
+					t.Errorf("unexpected authorization of %v for %s", deviceIDs, permission)
+				}
+				return fmt.Errorf("%w: read access to device dev-2 not granted", auth.ErrForbidden)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "missing_range",
+			query:      "format=csv",
+			client:     &mockExportClient{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "unsupported_format",
+			query:      "from=1000&to=2000&format=xml",
+			client:     &mockExportClient{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid_cursor",
+			query:      "from=1000&to=2000&cursor=abc",
+			client:     &mockExportClient{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "parquet_resume",
+			query:      "from=1000&to=2000&format=parquet&cursor=1700000000%7Cdev-1%7Ctemperature",
+			client:     &mockExportClient{},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "collector_error_before_data",
+			query:      "from=1000&to=2000",
+			client:     &mockExportClient{err: errors.New("invalid cursor")},
+			wantStatus: http.StatusBadGateway,
+		},
+		{
+			name:  "collector_error_mid_stream",
+			query: "from=1000&to=2000",
+			client: &mockExportClient{
+				chunks: []*telemetrypb.ExportTelemetryChunk{{Records: testRecords[:1], Cursor: "c1"}},
+				err:    errors.New("connection reset"),
+			},
+			wantStatus: http.StatusOK,
+			validate: func(t *testing.T, rec *httptest.ResponseRecorder, client *mockExportClient) {
+				if !strings.HasSuffix(rec.Body.String(), "dev-1,temperature,21.5,celsius\n") {
+					t.Errorf("expected the records received before the error, got:\n%s", rec.Body.String())
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/export/telemetry?"+tt.query, nil)
+			if !tt.noAuth {
+				c := claims
+				if tt.claims != nil {
+					c = tt.claims
+				}
+				req = req.WithContext(auth.WithUser(req.Context(), c))
+			}
+			if tt.gzip {
+				req.Header.Set("Accept-Encoding", "gzip")
+			}
+			rec := httptest.NewRecorder()
+
+			authorize := tt.authorize
+			if authorize == nil {
+				authorize = allowDevices
+			}
+			Handler(tt.client, authorize).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.validate != nil {
+				tt.validate(t, rec, tt.client)
+			}
+		})
+	}
+}
+
+// TestHandler_ResumeCutOffCSV resumes a download cut in the middle of a row
+// from its last complete row, and checks the parts join into the full export
+func TestHandler_ResumeCutOffCSV(t *testing.T) {
+	claims := &auth.Claims{UserID: "user-1", Role: "user"}
+	download := func(query string, client *mockExportClient) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/export/telemetry?"+query, nil)
+		req = req.WithContext(auth.WithUser(req.Context(), claims))
+		rec := httptest.NewRecorder()
+		Handler(client, allowDevices).ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		return rec
+	}
+
+	full := download("from=1000&to=2000", &mockExportClient{chunks: []*telemetrypb.ExportTelemetryChunk{{Records: testRecords}}}).Body.String()
+
+	// Connection lost in the middle of the last row
+	cut := full[:len(full)-5]
+	complete := cut[:strings.LastIndex(cut, "\n")+1]
+	lines := strings.Split(strings.TrimSuffix(complete, "\n"), "\n")
+	last := strings.Split(lines[len(lines)-1], ",")
+	cursor := last[0] + "|" + last[1] + "|" + last[2]
+
+	client := &mockExportClient{chunks: []*telemetrypb.ExportTelemetryChunk{{Records: testRecords[1:]}}}
+	rec := download("from=1000&to=2000&cursor="+url.QueryEscape(cursor), client)
+
+	if client.lastReq.Cursor != "1700000000|dev-1|temperature" {
+		t.Errorf("expected the cursor of the last complete row, got %q", client.lastReq.Cursor)
+	}
+	if got := rec.Header().Get(ResumeHeader); got != client.lastReq.Cursor {
+		t.Errorf("expected resume header %q, got %q", client.lastReq.Cursor, got)
+	}
+	if resumed := complete + rec.Body.String(); resumed != full {
+		t.Errorf("resumed download differs from the full export:\n%s\nwant:\n%s", resumed, full)
+	}
+}
+
+func TestHandler_NoTelemetryClient(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/export/telemetry?from=1&to=2", nil)
+	req = req.WithContext(auth.WithUser(req.Context(), &auth.Claims{UserID: "user-1", Role: "user"}))
+	rec := httptest.NewRecorder()
+
+	Handler(nil, allowDevices).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,216 @@
+package export
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/auth"
+	telemetrypb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+)
+
+// ResumeHeader echoes the position a resumed download starts after, before the
+// body, so that clients can check the cursor was applied.
+const ResumeHeader = "X-Export-Resumed-After"
+
+// Handler serves GET /export/telemetry.
+//
+// Query parameters:
+//   - from, to: time range as Unix timestamps (required)
+//   - device_id: device UUID, repeatable (optional, default: all devices of
+//     the organization of the token)
+//   - metric: metric name, repeatable (optional, default: all metrics)
+//   - format: csv, ndjson or parquet (default: csv)
+//   - cursor: resume after this position ("<time>|<device_id>|<metric_name>",
+//     time as Unix timestamp or RFC3339), built from the last complete row
+//     received. CSV and NDJSON only: a cut-off Parquet file has no footer and
+//     cannot be completed, the export has to be restarted.
+//
+// The response is gzip-compressed when the client sends Accept-Encoding: gzip.
+// Requests require telemetry:read and access to the devices, checked by
+// authorize; wrap the handler with auth.Middleware.
+func Handler(client telemetrypb.TelemetryServiceClient, authorize auth.DeviceAuthorizer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		claims, ok := auth.GetUserFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Authentication required", http.StatusUnauthorized)
+			return
+		}
+		if !claims.HasPermission(auth.PermTelemetryRead) {
+			http.Error(w, "Permission telemetry:read required", http.StatusForbidden)
+			return
+		}
+		if client == nil {
+			http.Error(w, "Telemetry service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		req, format, err := parseRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := authorize(r.Context(), req.DeviceIds, auth.PermTelemetryRead); err != nil {
+			if errors.Is(err, auth.ErrForbidden) {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			log.Printf("❌ Failed to authorize telemetry export: %v", err)
+			http.Error(w, "Failed to check device access", http.StatusBadGateway)
+			return
+		}
+		req.OrgId = claims.Organization()
+
+		stream, err := client.ExportTelemetry(r.Context(), req)
+		if err != nil {
+			log.Printf("❌ Failed to start telemetry export: %v", err)
+			http.Error(w, "Failed to start export", http.StatusBadGateway)
+			return
+		}
+
+		// Read the first chunk before committing to a 200 so that request errors
+		// reported by the collector (bad cursor, bad range) still map to a status code.
+		first, err := stream.Recv()
+		if err != nil && !errors.Is(err, io.EOF) {
+			log.Printf("❌ Telemetry export failed: %v", err)
+			http.Error(w, "Export failed: "+err.Error(), http.StatusBadGateway)
+			return
+		}
+
+		filename := fmt.Sprintf("telemetry-%d-%d.%s", req.FromTime, req.ToTime, format)
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		if req.Cursor != "" {
+			w.Header().Set(ResumeHeader, req.Cursor)
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		var out io.Writer = w
+		if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			defer gz.Close()
+			out = gz
+		}
+		w.WriteHeader(http.StatusOK)
+
+		writer, err := NewWriter(format, out, req.Cursor != "")
+		if err != nil {
+			log.Printf("❌ Failed to create %s writer: %v", format, err)
+			return
+		}
+
+		total := 0
+		for chunk := first; chunk != nil; {
+			if err := writer.Write(chunk.Records); err != nil {
+				log.Printf("⚠️  Export aborted by client after %d records: %v", total, err)
+				return
+			}
+			total += len(chunk.Records)
+			flush(out, w)
+
+			chunk, err = stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				// Headers are already sent: the client resumes from the last
+				// complete row of the truncated body.
+				log.Printf("❌ Telemetry export interrupted after %d records: %v", total, err)
+				return
+			}
+		}
+
+		if err := writer.Close(); err != nil {
+			log.Printf("⚠️  Failed to finish export: %v", err)
+			return
+		}
+		flush(out, w)
+		log.Printf("✅ Exported %d telemetry records as %s", total, format)
+	})
+}
+
+// parseRequest builds the gRPC export request from the query string.
+func parseRequest(r *http.Request) (*telemetrypb.ExportTelemetryRequest, Format, error) {
+	q := r.URL.Query()
+
+	from, err := strconv.ParseInt(q.Get("from"), 10, 64)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid or missing 'from' parameter")
+	}
+	to, err := strconv.ParseInt(q.Get("to"), 10, 64)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid or missing 'to' parameter")
+	}
+	if to < from {
+		return nil, "", fmt.Errorf("'to' must be after 'from'")
+	}
+
+	format := Format(q.Get("format"))
+	switch format {
+	case "":
+		format = FormatCSV
+	case FormatCSV, FormatNDJSON, FormatParquet:
+	default:
+		return nil, "", fmt.Errorf("unsupported format %q (csv, ndjson or parquet)", format)
+	}
+
+	cursor := q.Get("cursor")
+	if cursor != "" {
+		if format == FormatParquet {
+			return nil, "", fmt.Errorf("parquet exports cannot be resumed, restart the export without 'cursor'")
+		}
+		if cursor, err = parseCursor(cursor); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return &telemetrypb.ExportTelemetryRequest{
+		DeviceIds:   q["device_id"],
+		MetricNames: q["metric"],
+		FromTime:    from,
+		ToTime:      to,
+		Cursor:      cursor,
+	}, format, nil
+}
+
+// parseCursor validates a resume cursor and returns it in the collector's
+// "<unix>|<device_id>|<metric_name>" form. The time may be given as exported
+// in CSV and NDJSON rows (RFC3339).
+func parseCursor(cursor string) (string, error) {
+	parts := strings.SplitN(cursor, "|", 3)
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return "", fmt.Errorf("invalid 'cursor' parameter: expected <time>|<device_id>|<metric_name>")
+	}
+	timestamp, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		t, err := time.Parse(time.RFC3339, parts[0])
+		if err != nil {
+			return "", fmt.Errorf("invalid 'cursor' time %q: expected a Unix timestamp or RFC3339", parts[0])
+		}
+		timestamp = t.Unix()
+	}
+	return fmt.Sprintf("%d|%s|%s", timestamp, parts[1], parts[2]), nil
+}
+
+// flush pushes buffered bytes (gzip and HTTP) to the client.
+func flush(out io.Writer, w http.ResponseWriter) {
+	if gz, ok := out.(*gzip.Writer); ok {
+		if err := gz.Flush(); err != nil {
+			return
+		}
+	}
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
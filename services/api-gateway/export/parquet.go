@@ -0,0 +1,326 @@
+package export
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+
+	telemetrypb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+)
+
+// Minimal streaming Parquet writer for the fixed telemetry schema.
+//
+// Records are buffered into row groups which are written as soon as they fill
+// up, so only the footer (a few bytes per row group) is kept until Close.
+// All columns are REQUIRED, PLAIN-encoded and uncompressed: transfer-level gzip
+// is applied by the HTTP handler instead.
+//
+// Reference: https://github.com/apache/parquet-format
+
+const (
+	parquetMagic        = "PAR1"
+	parquetRowGroupSize = 50000
+	parquetCreatedBy    = "iot-platform api-gateway"
+)
+
+// Parquet physical types, converted types and encodings used by the schema.
+const (
+	parquetTypeInt64     int32 = 2
+	parquetTypeDouble    int32 = 5
+	parquetTypeByteArray int32 = 6
+
+	parquetConvertedUTF8            int32 = 0
+	parquetConvertedTimestampMillis int32 = 9
+
+	parquetEncodingPlain int32 = 0
+	parquetEncodingRLE   int32 = 3
+)
+
+// parquetColumn describes one leaf of the schema.
+type parquetColumn struct {
+	name          string
+	physicalType  int32
+	convertedType int32 // -1 when none
+}
+
+var parquetSchema = []parquetColumn{
+	{name: "time", physicalType: parquetTypeInt64, convertedType: parquetConvertedTimestampMillis},
+	{name: "device_id", physicalType: parquetTypeByteArray, convertedType: parquetConvertedUTF8},
+	{name: "metric_name", physicalType: parquetTypeByteArray, convertedType: parquetConvertedUTF8},
+	{name: "value", physicalType: parquetTypeDouble, convertedType: -1},
+	{name: "unit", physicalType: parquetTypeByteArray, convertedType: parquetConvertedUTF8},
+}
+
+// columnChunkMeta is what the footer needs to know about a written column chunk.
+type columnChunkMeta struct {
+	offset int64
+	size   int64
+}
+
+type rowGroupMeta struct {
+	numRows int64
+	columns []columnChunkMeta
+}
+
+type parquetWriter struct {
+	w            io.Writer
+	rowGroupSize int // records per row group
+	offset       int64
+	pending      []*telemetrypb.TelemetryRecord
+	rowGroups    []rowGroupMeta
+	numRows      int64
+}
+
+func newParquetWriter(w io.Writer, rowGroupSize int) (*parquetWriter, error) {
+	pw := &parquetWriter{w: w, rowGroupSize: rowGroupSize}
+	if err := pw.write([]byte(parquetMagic)); err != nil {
+		return nil, err
+	}
+	return pw, nil
+}
+
+func (p *parquetWriter) Write(records []*telemetrypb.TelemetryRecord) error {
+	p.pending = append(p.pending, records...)
+	for len(p.pending) >= p.rowGroupSize {
+		if err := p.flushRowGroup(p.pending[:p.rowGroupSize]); err != nil {
+			return err
+		}
+		p.pending = p.pending[p.rowGroupSize:]
+	}
+	return nil
+}
+
+func (p *parquetWriter) Close() error {
+	if len(p.pending) > 0 {
+		if err := p.flushRowGroup(p.pending); err != nil {
+			return err
+		}
+		p.pending = nil
+	}
+
+	footer := p.fileMetaData()
+	if err := p.write(footer); err != nil {
+		return err
+	}
+
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(footer)))
+	if err := p.write(length[:]); err != nil {
+		return err
+	}
+	return p.write([]byte(parquetMagic))
+}
+
+func (p *parquetWriter) write(b []byte) error {
+	n, err := p.w.Write(b)
+	p.offset += int64(n)
+	return err
+}
+
+// flushRowGroup writes one data page per column for the given records.
+func (p *parquetWriter) flushRowGroup(records []*telemetrypb.TelemetryRecord) error {
+	group := rowGroupMeta{numRows: int64(len(records))}
+
+	for i := range parquetSchema {
+		data := encodeParquetColumn(i, records)
+		header := parquetPageHeader(len(records), len(data))
+
+		chunk := columnChunkMeta{offset: p.offset, size: int64(len(header) + len(data))}
+		if err := p.write(header); err != nil {
+			return err
+		}
+		if err := p.write(data); err != nil {
+			return err
+		}
+		group.columns = append(group.columns, chunk)
+	}
+
+	p.rowGroups = append(p.rowGroups, group)
+	p.numRows += group.numRows
+	return nil
+}
+
+// encodeParquetColumn PLAIN-encodes column i of the schema.
+func encodeParquetColumn(i int, records []*telemetrypb.TelemetryRecord) []byte {
+	var buf []byte
+	for _, r := range records {
+		switch i {
+		case 0:
+			buf = binary.LittleEndian.AppendUint64(buf, uint64(r.Time*1000))
+		case 1:
+			buf = appendByteArray(buf, r.DeviceId)
+		case 2:
+			buf = appendByteArray(buf, r.MetricName)
+		case 3:
+			buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(r.Value))
+		case 4:
+			buf = appendByteArray(buf, r.Unit)
+		}
+	}
+	return buf
+}
+
+func appendByteArray(buf []byte, s string) []byte {
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(s)))
+	return append(buf, s...)
+}
+
+// parquetPageHeader encodes a DATA_PAGE header (Thrift PageHeader).
+func parquetPageHeader(numValues, size int) []byte {
+	t := &compactWriter{}
+	t.structBegin()
+	t.i32(1, 0) // type: DATA_PAGE
+	t.i32(2, int32(size))
+	t.i32(3, int32(size))
+	t.fieldStruct(5) // data_page_header
+	t.i32(1, int32(numValues))
+	t.i32(2, parquetEncodingPlain)
+	t.i32(3, parquetEncodingRLE)
+	t.i32(4, parquetEncodingRLE)
+	t.structEnd()
+	t.structEnd()
+	return t.buf
+}
+
+// fileMetaData encodes the footer (Thrift FileMetaData).
+func (p *parquetWriter) fileMetaData() []byte {
+	t := &compactWriter{}
+	t.structBegin()
+	t.i32(1, 1) // version
+
+	t.listBegin(2, compactStruct, len(parquetSchema)+1)
+	t.structBegin() // root
+	t.binary(4, "schema")
+	t.i32(5, int32(len(parquetSchema)))
+	t.structEnd()
+	for _, col := range parquetSchema {
+		t.structBegin()
+		t.i32(1, col.physicalType)
+		t.i32(3, 0) // repetition_type: REQUIRED
+		t.binary(4, col.name)
+		if col.convertedType >= 0 {
+			t.i32(6, col.convertedType)
+		}
+		t.structEnd()
+	}
+
+	t.i64(3, p.numRows)
+
+	t.listBegin(4, compactStruct, len(p.rowGroups))
+	for _, group := range p.rowGroups {
+		var totalSize int64
+		t.structBegin()
+		t.listBegin(1, compactStruct, len(group.columns))
+		for i, chunk := range group.columns {
+			col := parquetSchema[i]
+			totalSize += chunk.size
+
+			t.structBegin() // ColumnChunk
+			t.i64(2, chunk.offset)
+			t.fieldStruct(3) // ColumnMetaData
+			t.i32(1, col.physicalType)
+			t.listBegin(2, compactI32, 2)
+			t.listI32(parquetEncodingPlain)
+			t.listI32(parquetEncodingRLE)
+			t.listBegin(3, compactBinary, 1)
+			t.listBinary(col.name)
+			t.i32(4, 0) // codec: UNCOMPRESSED
+			t.i64(5, group.numRows)
+			t.i64(6, chunk.size)
+			t.i64(7, chunk.size)
+			t.i64(9, chunk.offset)
+			t.structEnd()
+			t.structEnd()
+		}
+		t.i64(2, totalSize)
+		t.i64(3, group.numRows)
+		t.structEnd()
+	}
+
+	t.binary(6, parquetCreatedBy)
+	t.structEnd()
+	return t.buf
+}
+
+// Thrift compact protocol type identifiers.
+const (
+	compactI32    byte = 5
+	compactI64    byte = 6
+	compactBinary byte = 8
+	compactList   byte = 9
+	compactStruct byte = 12
+)
+
+// compactWriter is a write-only Thrift compact protocol encoder,
+// just large enough for the Parquet metadata structures above.
+type compactWriter struct {
+	buf       []byte
+	lastField []int16 // last field ID per open struct
+}
+
+func (c *compactWriter) structBegin() {
+	c.lastField = append(c.lastField, 0)
+}
+
+func (c *compactWriter) structEnd() {
+	c.buf = append(c.buf, 0) // STOP
+	c.lastField = c.lastField[:len(c.lastField)-1]
+}
+
+func (c *compactWriter) fieldHeader(id int16, typ byte) {
+	last := &c.lastField[len(c.lastField)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		c.buf = append(c.buf, byte(delta)<<4|typ)
+	} else {
+		c.buf = append(c.buf, typ)
+		c.varint(zigzag(int64(id)))
+	}
+	*last = id
+}
+
+func (c *compactWriter) fieldStruct(id int16) {
+	c.fieldHeader(id, compactStruct)
+	c.structBegin()
+}
+
+func (c *compactWriter) i32(id int16, v int32) {
+	c.fieldHeader(id, compactI32)
+	c.varint(zigzag(int64(v)))
+}
+
+func (c *compactWriter) i64(id int16, v int64) {
+	c.fieldHeader(id, compactI64)
+	c.varint(zigzag(v))
+}
+
+func (c *compactWriter) binary(id int16, s string) {
+	c.fieldHeader(id, compactBinary)
+	c.listBinary(s)
+}
+
+func (c *compactWriter) listBegin(id int16, elemType byte, size int) {
+	c.fieldHeader(id, compactList)
+	if size < 15 {
+		c.buf = append(c.buf, byte(size)<<4|elemType)
+	} else {
+		c.buf = append(c.buf, 0xF0|elemType)
+		c.varint(uint64(size))
+	}
+}
+
+func (c *compactWriter) listI32(v int32) {
+	c.varint(zigzag(int64(v)))
+}
+
+func (c *compactWriter) listBinary(s string) {
+	c.varint(uint64(len(s)))
+	c.buf = append(c.buf, s...)
+}
+
+func (c *compactWriter) varint(v uint64) {
+	c.buf = binary.AppendUvarint(c.buf, v)
+}
+
+func zigzag(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
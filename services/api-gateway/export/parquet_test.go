@@ -0,0 +1,263 @@
+// +build unit
+
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+
+	telemetrypb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+)
+
+// The reader below decodes files independently of the writer: field IDs and
+// enum values are taken from parquet.thrift, not from the writer's constants.
+// https://github.com/apache/parquet-format/blob/master/src/main/thrift/parquet.thrift
+
+// Parquet enum values, from parquet.thrift.
+const (
+	specTypeInt64                  = 2
+	specTypeDouble                 = 5
+	specTypeByteArray              = 6
+	specRequired                   = 0
+	specConvertedUTF8              = 0
+	specConvertedTimestampMillis   = 9
+	specEncodingPlain              = 0
+	specPageTypeDataPage           = 0
+	specCodecUncompressed          = 0
+	specMagic                      = "PAR1"
+	specFooterLengthAndMagicLength = 8
+)
+
+// thriftStruct is a decoded Thrift struct, by field ID.
+type thriftStruct map[int16]any
+
+// compactReader decodes the Thrift compact protocol.
+type compactReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *compactReader) byte() byte {
+	b := r.buf[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *compactReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	if n <= 0 {
+		panic(fmt.Sprintf("invalid varint at %d", r.pos))
+	}
+	r.pos += n
+	return v
+}
+
+func (r *compactReader) varint() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *compactReader) readStruct() thriftStruct {
+	s := thriftStruct{}
+	var last int16
+	for {
+		header := r.byte()
+		if header == 0 {
+			return s
+		}
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(r.varint())
+		}
+		s[id] = r.readValue(header & 0x0F)
+		last = id
+	}
+}
+
+func (r *compactReader) readValue(typ byte) any {
+	switch typ {
+	case 1, 2: // BOOLEAN_TRUE, BOOLEAN_FALSE
+		return typ == 1
+	case 5, 6: // I32, I64
+		return r.varint()
+	case 8: // BINARY
+		n := int(r.uvarint())
+		s := string(r.buf[r.pos : r.pos+n])
+		r.pos += n
+		return s
+	case 9: // LIST
+		header := r.byte()
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		list := make([]any, size)
+		for i := range list {
+			list[i] = r.readValue(header & 0x0F)
+		}
+		return list
+	case 12: // STRUCT
+		return r.readStruct()
+	default:
+		panic(fmt.Sprintf("unsupported compact type %d", typ))
+	}
+}
+
+// readParquet decodes a file of REQUIRED, PLAIN-encoded, uncompressed
+// columns into rows of column values, and returns the footer.
+func readParquet(t *testing.T, data []byte) (thriftStruct, []map[string]any) {
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte(specMagic)) || !bytes.HasSuffix(data, []byte(specMagic)) {
+		t.Fatal("file must start and end with PAR1")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-specFooterLengthAndMagicLength:]))
+	footerStart := len(data) - specFooterLengthAndMagicLength - footerLen
+	if footerLen <= 0 || footerStart < len(specMagic) {
+		t.Fatalf("invalid footer length %d for file of %d bytes", footerLen, len(data))
+	}
+	footer := (&compactReader{buf: data[footerStart : len(data)-specFooterLengthAndMagicLength]}).readStruct()
+
+	// FileMetaData: 2 schema, 3 num_rows, 4 row_groups
+	schema := footer[2].([]any)
+	root := schema[0].(thriftStruct)
+	if root[5].(int64) != int64(len(schema)-1) {
+		t.Fatalf("root declares %d children, schema has %d leaves", root[5], len(schema)-1)
+	}
+	types := make(map[string]int64)
+	for _, element := range schema[1:] {
+		// SchemaElement: 1 type, 3 repetition_type, 4 name
+		e := element.(thriftStruct)
+		if e[3].(int64) != specRequired {
+			t.Errorf("column %s is not REQUIRED", e[4])
+		}
+		types[e[4].(string)] = e[1].(int64)
+	}
+
+	var rows []map[string]any
+	for _, group := range footer[4].([]any) {
+		// RowGroup: 1 columns, 3 num_rows
+		g := group.(thriftStruct)
+		numRows := int(g[3].(int64))
+		start := len(rows)
+		for i := 0; i < numRows; i++ {
+			rows = append(rows, make(map[string]any))
+		}
+		for _, column := range g[1].([]any) {
+			// ColumnChunk: 3 meta_data; ColumnMetaData: 1 type, 3 path_in_schema,
+			// 4 codec, 5 num_values, 9 data_page_offset
+			meta := column.(thriftStruct)[3].(thriftStruct)
+			name := meta[3].([]any)[0].(string)
+			if meta[1].(int64) != types[name] {
+				t.Fatalf("column %s: chunk type %d, schema type %d", name, meta[1], types[name])
+			}
+			if meta[4].(int64) != specCodecUncompressed || meta[5].(int64) != int64(numRows) {
+				t.Fatalf("column %s: unexpected codec %d or value count %d", name, meta[4], meta[5])
+			}
+
+			// PageHeader: 1 type, 3 compressed_page_size, 5 data_page_header;
+			// DataPageHeader: 1 num_values, 2 encoding
+			r := &compactReader{buf: data, pos: int(meta[9].(int64))}
+			header := r.readStruct()
+			page := header[5].(thriftStruct)
+			if header[1].(int64) != specPageTypeDataPage || page[1].(int64) != int64(numRows) || page[2].(int64) != specEncodingPlain {
+				t.Fatalf("column %s: unexpected page header %v", name, header)
+			}
+			values := data[r.pos : r.pos+int(header[3].(int64))]
+			for i := 0; i < numRows; i++ {
+				switch types[name] {
+				case specTypeInt64:
+					rows[start+i][name] = int64(binary.LittleEndian.Uint64(values))
+					values = values[8:]
+				case specTypeDouble:
+					rows[start+i][name] = math.Float64frombits(binary.LittleEndian.Uint64(values))
+					values = values[8:]
+				case specTypeByteArray:
+					n := int(binary.LittleEndian.Uint32(values))
+					rows[start+i][name] = string(values[4 : 4+n])
+					values = values[4+n:]
+				default:
+					t.Fatalf("column %s: unexpected type %d", name, types[name])
+				}
+			}
+			if len(values) != 0 {
+				t.Fatalf("column %s: %d trailing bytes in page", name, len(values))
+			}
+		}
+	}
+	if footer[3].(int64) != int64(len(rows)) {
+		t.Fatalf("footer declares %d rows, row groups hold %d", footer[3], len(rows))
+	}
+	return footer, rows
+}
+
+// TestParquetWriter_RoundTrip decodes an export spanning several row groups
+func TestParquetWriter_RoundTrip(t *testing.T) {
+	var records []*telemetrypb.TelemetryRecord
+	for i := 0; i < 120; i++ {
+		record := &telemetrypb.TelemetryRecord{DeviceId: fmt.Sprintf("dev-%d", i%3), MetricName: "temperature", Time: 1700000000 + int64(i), Value: float64(i) / 4}
+		// Records without unit have an empty unit column
+		if i%2 == 0 {
+			record.Unit = "celsius"
+		}
+		records = append(records, record)
+	}
+
+	var buf bytes.Buffer
+	w, err := newParquetWriter(&buf, 50)
+	if err != nil {
+		t.Fatalf("newParquetWriter() error = %v", err)
+	}
+	for i := 0; i < len(records); i += 40 {
+		if err := w.Write(records[i : i+40]); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	footer, rows := readParquet(t, buf.Bytes())
+	if groups := len(footer[4].([]any)); groups != 3 {
+		t.Errorf("expected 3 row groups of at most 50 rows, got %d", groups)
+	}
+
+	// SchemaElement: 4 name, 6 converted_type
+	converted := make(map[string]any)
+	for _, element := range footer[2].([]any)[1:] {
+		e := element.(thriftStruct)
+		converted[e[4].(string)] = e[6]
+	}
+	want := map[string]any{"time": int64(specConvertedTimestampMillis), "device_id": int64(specConvertedUTF8), "metric_name": int64(specConvertedUTF8), "value": nil, "unit": int64(specConvertedUTF8)}
+	for name, typ := range want {
+		if converted[name] != typ {
+			t.Errorf("column %s: converted type %v, want %v", name, converted[name], typ)
+		}
+	}
+
+	if len(rows) != len(records) {
+		t.Fatalf("expected %d rows, got %d", len(records), len(rows))
+	}
+	for i, row := range rows {
+		r := records[i]
+		if row["time"] != r.Time*1000 || row["device_id"] != r.DeviceId || row["metric_name"] != r.MetricName || row["value"] != r.Value || row["unit"] != r.Unit {
+			t.Fatalf("row %d = %v, want %v", i, row, r)
+		}
+	}
+}
+
+func TestParquetWriter_Empty(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatParquet, &buf, false)
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, rows := readParquet(t, buf.Bytes()); len(rows) != 0 {
+		t.Errorf("expected no rows, got %d", len(rows))
+	}
+}
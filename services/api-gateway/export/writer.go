@@ -0,0 +1,135 @@
+// Package export streams telemetry downloads from the Telemetry Collector
+// as CSV, NDJSON or Parquet over HTTP.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	telemetrypb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+)
+
+// Format is an export file format.
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatNDJSON  Format = "ndjson"
+	FormatParquet Format = "parquet"
+)
+
+// ContentType returns the MIME type served for the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatParquet:
+		return "application/vnd.apache.parquet"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// RecordWriter encodes telemetry records to an output stream.
+type RecordWriter interface {
+	// Write encodes a batch of records.
+	Write(records []*telemetrypb.TelemetryRecord) error
+
+	// Close writes any trailing data (headers are written eagerly, footers here).
+	// It does not close the underlying writer.
+	Close() error
+}
+
+// NewWriter returns a RecordWriter for the given format. The CSV header row
+// is omitted when resuming, so that the output appends to the cut-off file.
+func NewWriter(format Format, w io.Writer, resume bool) (RecordWriter, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, !resume)
+	case FormatNDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	case FormatParquet:
+		return newParquetWriter(w, parquetRowGroupSize)
+	default:
+		return nil, fmt.Errorf("unsupported export format: %q", format)
+	}
+}
+
+// csvWriter writes one row per record after a header row.
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer, header bool) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w)}
+	if !header {
+		return cw, nil
+	}
+	if err := cw.w.Write([]string{"time", "device_id", "metric_name", "value", "unit"}); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (c *csvWriter) Write(records []*telemetrypb.TelemetryRecord) error {
+	for _, r := range records {
+		row := []string{
+			formatTime(r.Time),
+			r.DeviceId,
+			r.MetricName,
+			strconv.FormatFloat(r.Value, 'g', -1, 64),
+			r.Unit,
+		}
+		if err := c.w.Write(row); err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// ndjsonRecord is the JSON shape of one exported line.
+type ndjsonRecord struct {
+	Time       string  `json:"time"`
+	DeviceID   string  `json:"device_id"`
+	MetricName string  `json:"metric_name"`
+	Value      float64 `json:"value"`
+	Unit       string  `json:"unit,omitempty"`
+}
+
+// ndjsonWriter writes one JSON object per line.
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(records []*telemetrypb.TelemetryRecord) error {
+	for _, r := range records {
+		if err := n.enc.Encode(ndjsonRecord{
+			Time:       formatTime(r.Time),
+			DeviceID:   r.DeviceId,
+			MetricName: r.MetricName,
+			Value:      r.Value,
+			Unit:       r.Unit,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n *ndjsonWriter) Close() error {
+	return nil
+}
+
+func formatTime(unix int64) string {
+	return time.Unix(unix, 0).UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,172 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/auth"
+	devicepb "github.com/yourusername/iot-platform/shared/proto/device"
+	userpb "github.com/yourusername/iot-platform/shared/proto/user"
+)
+
+// authorizeDevice checks that the user of ctx may access the device deviceID
+// with permission, see authorizeDevices.
+func (r *Resolver) authorizeDevice(ctx context.Context, deviceID string, permission string) error {
+	return r.authorizeDevices(ctx, []string{deviceID}, permission)
+}
+
+// authorizeDevices checks that the user of ctx may access each of deviceIDs
+// with permission: users granted it access every device their ACL entries
+// allow, device accounts only the device registered under their own ID.
+func (r *Resolver) authorizeDevices(ctx context.Context, deviceIDs []string, permission string) error {
+	claims, err := auth.RequireAuth(ctx)
+	if err != nil {
+		return err
+	}
+	if claims.HasPermission(permission) {
+		return r.checkDeviceAccess(ctx, claims, deviceIDs, permission)
+	}
+	for _, deviceID := range deviceIDs {
+		if claims.Role != "device" || claims.UserID != deviceID {
+			return fmt.Errorf("%w: %s required for device %s", auth.ErrForbidden, permission, deviceID)
+		}
+	}
+	return nil
+}
+
+// AuthorizeDevices checks that the user of ctx may access deviceIDs with
+// permission, every device of the organization if deviceIDs is empty. It
+// implements auth.DeviceAuthorizer for the HTTP handlers of the gateway.
+func (r *Resolver) AuthorizeDevices(ctx context.Context, deviceIDs []string, permission string) error {
+	if len(deviceIDs) == 0 {
+		return r.authorizeAllDevices(ctx, permission)
+	}
+	return r.authorizeDevices(ctx, deviceIDs, permission)
+}
+
+// authorizeAllDevices checks that the user of ctx may access every device
+// with permission, for queries and subscriptions selecting devices by type or
+// metadata. Users restricted by ACL entries may not.
+func (r *Resolver) authorizeAllDevices(ctx context.Context, permission string) error {
+	claims, err := auth.RequirePermission(ctx, permission)
+	if err != nil {
+		return err
+	}
+	subject := accessSubject(claims)
+	if subject == nil {
+		return nil
+	}
+
+	resp, err := r.DeviceClient.CheckDeviceAccess(ctx, &devicepb.CheckDeviceAccessRequest{
+		OrgId:   claims.Organization(),
+		Subject: subject,
+		Access:  deviceAccessLevel(permission),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check device access: %w", err)
+	}
+	if resp.Restricted {
+		return fmt.Errorf("%w: access restricted to some devices, select them by ID", auth.ErrForbidden)
+	}
+	return nil
+}
+
+// checkDeviceAccess checks the ACL entries of the user of claims for
+// deviceIDs. Users without entries keep the access of their role.
+func (r *Resolver) checkDeviceAccess(ctx context.Context, claims *auth.Claims, deviceIDs []string, permission string) error {
+	subject := accessSubject(claims)
+	if subject == nil || len(deviceIDs) == 0 {
+		return nil
+	}
+
+	access := deviceAccessLevel(permission)
+	resp, err := r.DeviceClient.CheckDeviceAccess(ctx, &devicepb.CheckDeviceAccessRequest{
+		OrgId:     claims.Organization(),
+		Subject:   subject,
+		DeviceIds: deviceIDs,
+		Access:    access,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check device access: %w", err)
+	}
+	if len(resp.DeniedDeviceIds) > 0 {
+		return fmt.Errorf("%w: %s access to device %s not granted", auth.ErrForbidden, strings.ToLower(strings.TrimPrefix(access.String(), "ACCESS_")), resp.DeniedDeviceIds[0])
+	}
+	return nil
+}
+
+// accessSubject returns the user of claims as subject of ACL entries, nil for
+// platform admins, who are never restricted.
+func accessSubject(claims *auth.Claims) *devicepb.AccessSubject {
+	if claims.IsPlatformAdmin() {
+		return nil
+	}
+	return &devicepb.AccessSubject{UserId: claims.UserID, Role: claims.OrgRole}
+}
+
+// deviceAccessLevel returns the ACL access level required by permission:
+// write for devices:write, read otherwise.
+func deviceAccessLevel(permission string) devicepb.AccessLevel {
+	if permission == auth.PermDevicesWrite {
+		return devicepb.AccessLevel_ACCESS_WRITE
+	}
+	return devicepb.AccessLevel_ACCESS_READ
+}
+
+// errDeviceNotFound is returned for devices of another organization, which
+// are not distinguished from unknown devices
+var errDeviceNotFound = errors.New("device not found")
+
+// organization returns the organization the user of ctx acts in. Devices and
+// telemetry requests are scoped to it.
+func organization(ctx context.Context) (string, error) {
+	claims, err := auth.RequireAuth(ctx)
+	if err != nil {
+		return "", err
+	}
+	return claims.Organization(), nil
+}
+
+// checkDeviceOrganization checks that deviceID belongs to the organization of
+// ctx. Queries are scoped by the services; subscriptions are served by the
+// gateway and check their devices here.
+func (r *Resolver) checkDeviceOrganization(ctx context.Context, deviceID string) error {
+	orgID, err := organization(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = r.DeviceClient.GetDevice(ctx, &devicepb.GetDeviceRequest{Id: deviceID, OrgId: orgID})
+	if status.Code(err) == codes.NotFound {
+		return fmt.Errorf("%w: %s", errDeviceNotFound, deviceID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get device: %w", err)
+	}
+	return nil
+}
+
+// authorizeMember checks that userID is a member of the organization of ctx,
+// for users:admin operations on a user. Platform admins manage every user.
+func (r *Resolver) authorizeMember(ctx context.Context, userID string) error {
+	claims, err := auth.RequireAuth(ctx)
+	if err != nil {
+		return err
+	}
+	if claims.IsPlatformAdmin() {
+		return nil
+	}
+
+	_, err = r.UserClient.GetMembership(ctx, &userpb.GetMembershipRequest{OrgId: claims.Organization(), UserId: userID})
+	if status.Code(err) == codes.NotFound {
+		return fmt.Errorf("%w: user %s is not a member of the organization", auth.ErrForbidden, userID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get membership: %w", err)
+	}
+	return nil
+}
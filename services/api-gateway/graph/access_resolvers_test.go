@@ -0,0 +1,85 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/auth"
+	"github.com/yourusername/iot-platform/services/api-gateway/graph/model"
+	pb "github.com/yourusername/iot-platform/shared/proto/device"
+	userpb "github.com/yourusername/iot-platform/shared/proto/user"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (m *MockDeviceServiceClient) CreateAccessEntry(ctx context.Context, req *pb.CreateAccessEntryRequest, opts ...grpc.CallOption) (*pb.CreateAccessEntryResponse, error) {
+	if m.CreateAccessEntryFunc != nil {
+		return m.CreateAccessEntryFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("CreateAccessEntryFunc not implemented")
+}
+
+// TestGrantDeviceAccessImpl tests that access is only granted to members of
+// the organization of the caller, with exactly one subject.
+func TestGrantDeviceAccessImpl(t *testing.T) {
+	var saved *pb.CreateAccessEntryRequest
+	deviceClient := &MockDeviceServiceClient{
+		CreateAccessEntryFunc: func(ctx context.Context, req *pb.CreateAccessEntryRequest, opts ...grpc.CallOption) (*pb.CreateAccessEntryResponse, error) {
+			saved = req
+			return &pb.CreateAccessEntryResponse{Entry: &pb.AccessEntry{
+				Id:       "entry-1",
+				OrgId:    req.OrgId,
+				UserId:   req.UserId,
+				DeviceId: req.DeviceId,
+				Access:   req.Access,
+			}}, nil
+		},
+	}
+	userClient := &MockUserServiceClient{
+		GetMembershipFunc: func(ctx context.Context, req *userpb.GetMembershipRequest, opts ...grpc.CallOption) (*userpb.MemberResponse, error) {
+			if req.OrgId != "org-1" || req.UserId != "user-2" {
+				return nil, status.Error(codes.NotFound, "membership not found")
+			}
+			return &userpb.MemberResponse{Membership: &userpb.Membership{OrgId: req.OrgId, UserId: req.UserId, Role: "viewer"}}, nil
+		},
+	}
+	r := &mutationResolver{&Resolver{DeviceClient: deviceClient, UserClient: userClient}}
+	ctx := orgContext("org-1")
+
+	entry, err := r.GrantDeviceAccessImpl(ctx, model.GrantDeviceAccessInput{
+		UserID:   stringPtr("user-2"),
+		DeviceID: stringPtr("dev-1"),
+		Access:   model.DeviceAccessWrite,
+	})
+	if err != nil {
+		t.Fatalf("GrantDeviceAccessImpl failed: %v", err)
+	}
+	if saved.OrgId != "org-1" || saved.UserId != "user-2" || saved.Role != "" || saved.Access != pb.AccessLevel_ACCESS_WRITE {
+		t.Errorf("unexpected request %+v", saved)
+	}
+	if entry.Access != model.DeviceAccessWrite || *entry.DeviceID != "dev-1" || entry.GroupID != nil || entry.Role != nil {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+
+	saved = nil
+	_, err = r.GrantDeviceAccessImpl(ctx, model.GrantDeviceAccessInput{
+		UserID:   stringPtr("user-3"),
+		DeviceID: stringPtr("dev-1"),
+		Access:   model.DeviceAccessRead,
+	})
+	if !errors.Is(err, auth.ErrForbidden) || saved != nil {
+		t.Errorf("GrantDeviceAccessImpl to a non-member error = %v, want %v", err, auth.ErrForbidden)
+	}
+
+	_, err = r.GrantDeviceAccessImpl(ctx, model.GrantDeviceAccessInput{
+		UserID:   stringPtr("user-2"),
+		Role:     stringPtr("viewer"),
+		DeviceID: stringPtr("dev-1"),
+		Access:   model.DeviceAccessRead,
+	})
+	if err == nil || saved != nil {
+		t.Error("GrantDeviceAccessImpl with two subjects should fail")
+	}
+}
@@ -0,0 +1,206 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/auth"
+	"github.com/yourusername/iot-platform/services/api-gateway/graph/model"
+	"github.com/yourusername/iot-platform/services/api-gateway/pubsub"
+	pb "github.com/yourusername/iot-platform/shared/proto/device"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (m *MockDeviceServiceClient) CheckDeviceAccess(ctx context.Context, req *pb.CheckDeviceAccessRequest, opts ...grpc.CallOption) (*pb.CheckDeviceAccessResponse, error) {
+	if m.CheckDeviceAccessFunc != nil {
+		return m.CheckDeviceAccessFunc(ctx, req, opts...)
+	}
+	// Without ACL entries, users keep the access of their role
+	return &pb.CheckDeviceAccessResponse{}, nil
+}
+
+// userContext returns a context authenticated as a regular user.
+func userContext() context.Context {
+	return auth.WithUser(context.Background(), &auth.Claims{UserID: "user-1", Role: "user"})
+}
+
+// orgDeviceClient returns a device client where dev-3 belongs to another
+// organization than dev-1 and dev-2.
+func orgDeviceClient() *MockDeviceServiceClient {
+	return &MockDeviceServiceClient{
+		GetDeviceFunc: func(ctx context.Context, req *pb.GetDeviceRequest, opts ...grpc.CallOption) (*pb.GetDeviceResponse, error) {
+			if req.Id == "dev-3" && req.OrgId != "org-2" {
+				return nil, status.Error(codes.NotFound, "device not found")
+			}
+			return &pb.GetDeviceResponse{Device: &pb.Device{Id: req.Id, OrgId: req.OrgId}}, nil
+		},
+	}
+}
+
+// TestSubscriptionAuthorization tests that subscriptions only stream the
+// devices the user may see.
+func TestSubscriptionAuthorization(t *testing.T) {
+	deviceCtx := auth.WithUser(context.Background(), &auth.Claims{UserID: "dev-1", Role: "device"})
+	viewerCtx := auth.WithUser(context.Background(), &auth.Claims{UserID: "user-2", Role: "viewer", Permissions: []string{auth.PermDevicesRead}})
+	otherOrgCtx := auth.WithUser(context.Background(), &auth.Claims{UserID: "user-3", Role: "user", OrgID: "org-2"})
+	deviceType := "thermometer"
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		deviceID string
+		filter   model.TelemetryFilter
+		wantErr  error
+	}{
+		{name: "anonymous", ctx: context.Background(), deviceID: "dev-1", filter: model.TelemetryFilter{DeviceIds: []string{"dev-1"}}, wantErr: auth.ErrUnauthorized},
+		{name: "user", ctx: userContext(), deviceID: "dev-2", filter: model.TelemetryFilter{DeviceType: &deviceType}},
+		{name: "admin", ctx: adminContext(), deviceID: "dev-2", filter: model.TelemetryFilter{DeviceIds: []string{"dev-1", "dev-2"}}},
+		{name: "own_device", ctx: deviceCtx, deviceID: "dev-1", filter: model.TelemetryFilter{DeviceIds: []string{"dev-1"}}},
+		{name: "other_device", ctx: deviceCtx, deviceID: "dev-2", filter: model.TelemetryFilter{DeviceIds: []string{"dev-1", "dev-2"}}, wantErr: auth.ErrForbidden},
+		{name: "without_telemetry_read", ctx: viewerCtx, deviceID: "dev-1", filter: model.TelemetryFilter{DeviceIds: []string{"dev-1"}}, wantErr: auth.ErrForbidden},
+		{name: "device_wildcard", ctx: deviceCtx, deviceID: "dev-2", filter: model.TelemetryFilter{DeviceType: &deviceType}, wantErr: auth.ErrForbidden},
+		{name: "other_organization", ctx: userContext(), deviceID: "dev-3", filter: model.TelemetryFilter{DeviceIds: []string{"dev-1", "dev-3"}}, wantErr: errDeviceNotFound},
+		{name: "own_organization", ctx: otherOrgCtx, deviceID: "dev-3", filter: model.TelemetryFilter{DeviceIds: []string{"dev-3"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(tt.ctx)
+			defer cancel()
+			broker := pubsub.NewBroker()
+			resolver := &subscriptionResolver{&Resolver{Broker: broker, DeviceClient: orgDeviceClient()}}
+
+			_, err := resolver.TelemetryReceivedImpl(ctx, tt.deviceID, nil, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("TelemetryReceivedImpl() error = %v, want %v", err, tt.wantErr)
+			}
+			_, err = resolver.TelemetryImpl(ctx, tt.filter)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("TelemetryImpl() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil && broker.TotalSubscribers() != 0 {
+				t.Errorf("expected no subscriber, got %d", broker.TotalSubscribers())
+			}
+		})
+	}
+}
+
+// TestTelemetryWildcardOrganization tests that wildcard subscriptions only
+// receive the telemetry of devices of the organization.
+func TestTelemetryWildcardOrganization(t *testing.T) {
+	ctx, cancel := context.WithCancel(userContext())
+	defer cancel()
+	broker := pubsub.NewBroker()
+	broker.PublishDevice(&model.Device{ID: "dev-1", Type: "thermometer", OrganizationID: auth.DefaultOrganizationID})
+	broker.PublishDevice(&model.Device{ID: "dev-3", Type: "thermometer", OrganizationID: "org-2"})
+	resolver := &subscriptionResolver{&Resolver{Broker: broker, DeviceClient: orgDeviceClient()}}
+
+	deviceType := "thermometer"
+	ch, err := resolver.TelemetryImpl(ctx, model.TelemetryFilter{DeviceType: &deviceType})
+	if err != nil {
+		t.Fatalf("TelemetryImpl() failed: %v", err)
+	}
+	broker.Publish("dev-3", &model.TelemetryPoint{Value: 3})
+	broker.Publish("dev-1", &model.TelemetryPoint{Value: 1})
+
+	if point := <-ch; point.Value != 1 {
+		t.Errorf("received %v, want the point of dev-1", point.Value)
+	}
+}
+
+// aclDeviceClient returns a device client where user-1 is granted read access
+// to dev-1 and write access to dev-2, and other users have no ACL entry.
+func aclDeviceClient() *MockDeviceServiceClient {
+	mock := orgDeviceClient()
+	mock.CheckDeviceAccessFunc = func(ctx context.Context, req *pb.CheckDeviceAccessRequest, opts ...grpc.CallOption) (*pb.CheckDeviceAccessResponse, error) {
+		if req.Subject.UserId != "user-1" {
+			return &pb.CheckDeviceAccessResponse{}, nil
+		}
+		granted := map[string]bool{"dev-2": true}
+		if req.Access == pb.AccessLevel_ACCESS_READ {
+			granted["dev-1"] = true
+		}
+		resp := &pb.CheckDeviceAccessResponse{Restricted: true}
+		for _, id := range req.DeviceIds {
+			if !granted[id] {
+				resp.DeniedDeviceIds = append(resp.DeniedDeviceIds, id)
+			}
+		}
+		return resp, nil
+	}
+	return mock
+}
+
+// TestDeviceAccessControl tests that users with ACL entries only access the
+// devices granted to them, at the granted level.
+func TestDeviceAccessControl(t *testing.T) {
+	otherUserCtx := auth.WithUser(context.Background(), &auth.Claims{UserID: "user-2", Role: "user"})
+	resolver := &Resolver{Broker: pubsub.NewBroker(), DeviceClient: aclDeviceClient()}
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		deviceIDs  []string
+		permission string
+		wantErr    error
+	}{
+		{name: "granted_read", ctx: userContext(), deviceIDs: []string{"dev-1", "dev-2"}, permission: auth.PermTelemetryRead},
+		{name: "not_granted", ctx: userContext(), deviceIDs: []string{"dev-1", "dev-4"}, permission: auth.PermTelemetryRead, wantErr: auth.ErrForbidden},
+		{name: "granted_write", ctx: userContext(), deviceIDs: []string{"dev-2"}, permission: auth.PermDevicesWrite},
+		{name: "read_only", ctx: userContext(), deviceIDs: []string{"dev-1"}, permission: auth.PermDevicesWrite, wantErr: auth.ErrForbidden},
+		{name: "all_devices", ctx: userContext(), permission: auth.PermDevicesRead, wantErr: auth.ErrForbidden},
+		{name: "without_entries", ctx: otherUserCtx, deviceIDs: []string{"dev-4"}, permission: auth.PermDevicesWrite},
+		{name: "without_entries_all_devices", ctx: otherUserCtx, permission: auth.PermDevicesRead},
+		{name: "platform_admin", ctx: adminContext(), permission: auth.PermDevicesWrite},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := resolver.AuthorizeDevices(tt.ctx, tt.deviceIDs, tt.permission)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AuthorizeDevices() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(userContext())
+	defer cancel()
+	subscription := &subscriptionResolver{resolver}
+	if _, err := subscription.TelemetryReceivedImpl(ctx, "dev-4", nil, nil); !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("TelemetryReceivedImpl() on a device not granted error = %v, want %v", err, auth.ErrForbidden)
+	}
+	if _, err := subscription.TelemetryReceivedImpl(ctx, "dev-1", nil, nil); err != nil {
+		t.Errorf("TelemetryReceivedImpl() on a granted device failed: %v", err)
+	}
+}
+
+// TestDevicesImplAccessSubject tests that device listings are filtered by
+// the ACL entries of the caller, except for platform admins.
+func TestDevicesImplAccessSubject(t *testing.T) {
+	var saved *pb.ListDevicesRequest
+	mock := &MockDeviceServiceClient{
+		ListDevicesFunc: func(ctx context.Context, req *pb.ListDevicesRequest, opts ...grpc.CallOption) (*pb.ListDevicesResponse, error) {
+			saved = req
+			return &pb.ListDevicesResponse{}, nil
+		},
+	}
+	r := &queryResolver{newTestResolver(mock)}
+
+	ctx := auth.WithUser(context.Background(), &auth.Claims{UserID: "user-1", Role: "user", OrgID: "org-1", OrgRole: "operator"})
+	if _, err := r.DevicesImpl(ctx, nil, nil, nil, nil, nil, nil); err != nil {
+		t.Fatalf("DevicesImpl failed: %v", err)
+	}
+	if saved.Subject == nil || saved.Subject.UserId != "user-1" || saved.Subject.Role != "operator" {
+		t.Errorf("unexpected subject %+v", saved.Subject)
+	}
+
+	if _, err := r.DevicesImpl(adminContext(), nil, nil, nil, nil, nil, nil); err != nil {
+		t.Fatalf("DevicesImpl failed: %v", err)
+	}
+	if saved.Subject != nil {
+		t.Errorf("platform admin listed with subject %+v, want none", saved.Subject)
+	}
+}
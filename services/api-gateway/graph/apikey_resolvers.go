@@ -0,0 +1,175 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/auth"
+	"github.com/yourusername/iot-platform/services/api-gateway/graph/model"
+	userpb "github.com/yourusername/iot-platform/shared/proto/user"
+)
+
+func protoToGraphQLAPIKey(k *userpb.ApiKey) *model.APIKey {
+	key := &model.APIKey{
+		ID:          k.Id,
+		Name:        k.Name,
+		Prefix:      k.Prefix,
+		UserID:      k.UserId,
+		Permissions: append([]string{}, k.Permissions...),
+		CreatedAt:   int(k.CreatedAt),
+	}
+	if k.ExpiresAt != 0 {
+		key.ExpiresAt = intPtr(int(k.ExpiresAt))
+	}
+	if k.LastUsedAt != 0 {
+		key.LastUsedAt = intPtr(int(k.LastUsedAt))
+	}
+	return key
+}
+
+// apiKeyOwner returns the user whose keys the caller manages: themselves
+// by default, or another member with users:admin.
+func (r *Resolver) apiKeyOwner(ctx context.Context, userID *string) (string, error) {
+	claims, err := auth.RequireAuth(ctx)
+	if err != nil {
+		return "", err
+	}
+	if userID == nil || *userID == claims.UserID {
+		return claims.UserID, nil
+	}
+
+	if _, err := auth.RequirePermission(ctx, auth.PermUsersAdmin); err != nil {
+		return "", err
+	}
+	if err := r.authorizeMember(ctx, *userID); err != nil {
+		return "", err
+	}
+	return *userID, nil
+}
+
+// APIKeysImpl lists the active API keys of a user in the organization of the
+// caller.
+func (r *queryResolver) APIKeysImpl(ctx context.Context, userID *string) ([]*model.APIKey, error) {
+	owner, err := r.apiKeyOwner(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.UserClient.ListApiKeys(ctx, &userpb.ListApiKeysRequest{UserId: owner, OrgId: orgID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+
+	keys := make([]*model.APIKey, len(resp.ApiKeys))
+	for i, k := range resp.ApiKeys {
+		keys[i] = protoToGraphQLAPIKey(k)
+	}
+	return keys, nil
+}
+
+// CreateAPIKeyImpl creates an API key acting in the organization of the
+// caller, for the caller or for a service account of the organization. Keys
+// cannot create keys: a scoped key would otherwise mint an unscoped one.
+func (r *mutationResolver) CreateAPIKeyImpl(ctx context.Context, input model.CreateAPIKeyInput) (*model.APIKeyCreated, error) {
+	claims, err := auth.RequireAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if claims.APIKeyID != "" {
+		return nil, fmt.Errorf("%w: API keys cannot be created with an API key", auth.ErrForbidden)
+	}
+
+	owner, err := r.apiKeyOwner(ctx, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if owner != claims.UserID {
+		// Admins create keys for machine clients, not for other people
+		resp, err := r.UserClient.GetUser(ctx, &userpb.GetUserRequest{Id: owner})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		if !resp.User.ServiceAccount {
+			return nil, fmt.Errorf("%w: API keys of other users can only be created for service accounts", auth.ErrForbidden)
+		}
+	}
+
+	for _, p := range input.Permissions {
+		if !auth.IsPermission(p) {
+			return nil, fmt.Errorf("unknown permission %q", p)
+		}
+	}
+	req := &userpb.CreateApiKeyRequest{
+		UserId:      owner,
+		OrgId:       claims.Organization(),
+		Name:        input.Name,
+		Permissions: input.Permissions,
+	}
+	if input.ExpiresAt != nil {
+		req.ExpiresAt = int64(*input.ExpiresAt)
+	}
+
+	resp, err := r.UserClient.CreateApiKey(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	log.Printf("✅ API key created: %s for user %s", resp.ApiKey.Id, owner)
+	return &model.APIKeyCreated{
+		APIKey: protoToGraphQLAPIKey(resp.ApiKey),
+		Key:    resp.Key,
+	}, nil
+}
+
+// RevokeAPIKeyImpl revokes an API key of the organization of the caller: one
+// of their own, or any with users:admin.
+func (r *mutationResolver) RevokeAPIKeyImpl(ctx context.Context, id string) (*model.DeleteResult, error) {
+	claims, err := auth.RequireAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &userpb.RevokeApiKeyRequest{Id: id, OrgId: claims.Organization()}
+	if !claims.HasPermission(auth.PermUsersAdmin) {
+		req.UserId = claims.UserID
+	}
+	resp, err := r.UserClient.RevokeApiKey(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	log.Printf("✅ API key revoked: %s", id)
+	return &model.DeleteResult{
+		Success: resp.Success,
+		Message: fmt.Sprintf("API key %s revoked", id),
+	}, nil
+}
+
+// CreateServiceAccountImpl creates a service account as a member of the
+// organization of the caller. Its platform role is "user"; it acts with its
+// role in the organization, through API keys.
+func (r *mutationResolver) CreateServiceAccountImpl(ctx context.Context, input model.CreateServiceAccountInput) (*model.User, error) {
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.UserClient.Register(ctx, &userpb.RegisterRequest{
+		Email:          stringPtrToValue(input.Email),
+		Name:           input.Name,
+		OrgId:          orgID,
+		OrgRole:        input.Role,
+		ServiceAccount: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create service account: %w", err)
+	}
+
+	log.Printf("✅ Service account created: %s in organization %s", resp.User.Id, orgID)
+	return protoToGraphQLUser(resp.User), nil
+}
@@ -0,0 +1,118 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/auth"
+	"github.com/yourusername/iot-platform/services/api-gateway/graph/model"
+	userpb "github.com/yourusername/iot-platform/shared/proto/user"
+	"google.golang.org/grpc"
+)
+
+func (m *MockUserServiceClient) CreateApiKey(ctx context.Context, req *userpb.CreateApiKeyRequest, opts ...grpc.CallOption) (*userpb.CreateApiKeyResponse, error) {
+	if m.CreateApiKeyFunc != nil {
+		return m.CreateApiKeyFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("CreateApiKeyFunc not implemented")
+}
+
+func (m *MockUserServiceClient) RevokeApiKey(ctx context.Context, req *userpb.RevokeApiKeyRequest, opts ...grpc.CallOption) (*userpb.RevokeApiKeyResponse, error) {
+	if m.RevokeApiKeyFunc != nil {
+		return m.RevokeApiKeyFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("RevokeApiKeyFunc not implemented")
+}
+
+// TestCreateAPIKeyImpl tests that users create keys for themselves, and
+// admins for the service accounts of their organization only.
+func TestCreateAPIKeyImpl(t *testing.T) {
+	var saved *userpb.CreateApiKeyRequest
+	userClient := &MockUserServiceClient{
+		CreateApiKeyFunc: func(ctx context.Context, req *userpb.CreateApiKeyRequest, opts ...grpc.CallOption) (*userpb.CreateApiKeyResponse, error) {
+			saved = req
+			return &userpb.CreateApiKeyResponse{
+				ApiKey: &userpb.ApiKey{Id: "key-1", UserId: req.UserId, OrgId: req.OrgId, Name: req.Name, Prefix: "iotk_abcdefg", Permissions: req.Permissions},
+				Key:    "iotk_abcdefgsecret",
+			}, nil
+		},
+		GetMembershipFunc: func(ctx context.Context, req *userpb.GetMembershipRequest, opts ...grpc.CallOption) (*userpb.MemberResponse, error) {
+			return &userpb.MemberResponse{Membership: &userpb.Membership{OrgId: req.OrgId, UserId: req.UserId, Role: "user"}}, nil
+		},
+		GetUserFunc: func(ctx context.Context, req *userpb.GetUserRequest, opts ...grpc.CallOption) (*userpb.GetUserResponse, error) {
+			return &userpb.GetUserResponse{User: &userpb.User{Id: req.Id, ServiceAccount: req.Id == "bot-1"}}, nil
+		},
+	}
+	r := &mutationResolver{&Resolver{UserClient: userClient}}
+	adminCtx := auth.WithUser(context.Background(), &auth.Claims{UserID: "user-1", Role: "user", OrgID: "org-1", Permissions: []string{auth.PermUsersAdmin}})
+	keyCtx := auth.WithUser(context.Background(), &auth.Claims{UserID: "user-1", Role: "user", OrgID: "org-1", APIKeyID: "key-0"})
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		input   model.CreateAPIKeyInput
+		wantErr error
+		wantFor string
+	}{
+		{name: "own_key", ctx: orgContext("org-1"), input: model.CreateAPIKeyInput{Name: "CLI", Permissions: []string{auth.PermTelemetryRead}}, wantFor: "user-1"},
+		{name: "service_account", ctx: adminCtx, input: model.CreateAPIKeyInput{Name: "CI", UserID: stringPtr("bot-1")}, wantFor: "bot-1"},
+		{name: "other_user", ctx: adminCtx, input: model.CreateAPIKeyInput{Name: "CI", UserID: stringPtr("user-2")}, wantErr: auth.ErrForbidden},
+		{name: "without_users_admin", ctx: orgContext("org-1"), input: model.CreateAPIKeyInput{Name: "CI", UserID: stringPtr("bot-1")}, wantErr: auth.ErrForbidden},
+		{name: "with_api_key", ctx: keyCtx, input: model.CreateAPIKeyInput{Name: "CI"}, wantErr: auth.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved = nil
+			created, err := r.CreateAPIKeyImpl(tt.ctx, tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateAPIKeyImpl() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if saved != nil {
+					t.Errorf("unexpected request %+v", saved)
+				}
+				return
+			}
+			if saved.UserId != tt.wantFor || saved.OrgId != "org-1" {
+				t.Errorf("unexpected request %+v", saved)
+			}
+			if created.Key == "" || created.APIKey.UserID != tt.wantFor {
+				t.Errorf("unexpected key %+v", created)
+			}
+		})
+	}
+
+	if _, err := r.CreateAPIKeyImpl(orgContext("org-1"), model.CreateAPIKeyInput{Name: "CLI", Permissions: []string{"devices:delete"}}); err == nil {
+		t.Error("CreateAPIKeyImpl with an unknown permission should fail")
+	}
+}
+
+// TestRevokeAPIKeyImpl tests that users without users:admin only revoke their
+// own keys.
+func TestRevokeAPIKeyImpl(t *testing.T) {
+	var saved *userpb.RevokeApiKeyRequest
+	userClient := &MockUserServiceClient{
+		RevokeApiKeyFunc: func(ctx context.Context, req *userpb.RevokeApiKeyRequest, opts ...grpc.CallOption) (*userpb.RevokeApiKeyResponse, error) {
+			saved = req
+			return &userpb.RevokeApiKeyResponse{Success: true}, nil
+		},
+	}
+	r := &mutationResolver{&Resolver{UserClient: userClient}}
+
+	if _, err := r.RevokeAPIKeyImpl(orgContext("org-1"), "key-1"); err != nil {
+		t.Fatalf("RevokeAPIKeyImpl failed: %v", err)
+	}
+	if saved.UserId != "user-1" || saved.OrgId != "org-1" {
+		t.Errorf("unexpected request %+v", saved)
+	}
+
+	adminCtx := auth.WithUser(context.Background(), &auth.Claims{UserID: "user-1", Role: "user", OrgID: "org-1", Permissions: []string{auth.PermUsersAdmin}})
+	if _, err := r.RevokeAPIKeyImpl(adminCtx, "key-2"); err != nil {
+		t.Fatalf("RevokeAPIKeyImpl failed: %v", err)
+	}
+	if saved.UserId != "" || saved.OrgId != "org-1" {
+		t.Errorf("unexpected request %+v", saved)
+	}
+}
@@ -3,6 +3,7 @@ package graph
 import (
 	"context"
 	"fmt"
+	"log"
 
 	"github.com/yourusername/iot-platform/services/api-gateway/auth"
 	"github.com/yourusername/iot-platform/services/api-gateway/graph/model"
@@ -17,23 +18,28 @@ func protoToGraphQLUser(u *userpb.User) *model.User {
 	}
 
 	return &model.User{
-		ID:        u.Id,
-		Email:     u.Email,
-		Name:      u.Name,
-		Role:      u.Role,
-		CreatedAt: int(u.CreatedAt),
-		LastLogin: intPtr(int(u.LastLogin)),
-		IsActive:  u.IsActive,
+		ID:             u.Id,
+		Email:          u.Email,
+		Name:           u.Name,
+		Role:           u.Role,
+		CreatedAt:      int(u.CreatedAt),
+		LastLogin:      intPtr(int(u.LastLogin)),
+		IsActive:       u.IsActive,
+		Permissions:    append([]string{}, u.Permissions...),
+		OrgRole:        stringValuePtr(u.OrgRole),
+		ServiceAccount: u.ServiceAccount,
 	}
 }
 
 // Mutation resolvers for authentication
 
+// RegisterImpl creates a user as a member of the organization of the caller.
+// The role is the role in the organization; only platform admins also choose
+// the platform role, which defaults to "user" in user-service.
 func (r *mutationResolver) RegisterImpl(ctx context.Context, input model.RegisterInput) (*model.AuthPayload, error) {
-	// Only admins can create new users
-	claims, ok := auth.GetUserFromContext(ctx)
-	if !ok || claims.Role != "admin" {
-		return nil, fmt.Errorf("only admins can create users")
+	claims, err := auth.RequireAuth(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	// Prepare register request
@@ -41,11 +47,13 @@ func (r *mutationResolver) RegisterImpl(ctx context.Context, input model.Registe
 		Email:    input.Email,
 		Password: input.Password,
 		Name:     input.Name,
+		OrgId:    claims.Organization(),
 	}
-
-	// Set role if provided, otherwise defaults to "user" in user-service
 	if input.Role != nil {
-		req.Role = *input.Role
+		req.OrgRole = *input.Role
+		if claims.IsPlatformAdmin() {
+			req.Role = *input.Role
+		}
 	}
 
 	// Call User Service via gRPC
@@ -54,21 +62,14 @@ func (r *mutationResolver) RegisterImpl(ctx context.Context, input model.Registe
 		return nil, fmt.Errorf("failed to register user: %w", err)
 	}
 
-	// Generate JWT token
-	token, err := r.JWTManager.GenerateToken(
-		resp.User.Id,
-		resp.User.Email,
-		resp.User.Name,
-		resp.User.Role,
-	)
+	membership, err := r.UserClient.GetMembership(ctx, &userpb.GetMembershipRequest{OrgId: req.OrgId, UserId: resp.User.Id})
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate token: %w", err)
+		return nil, fmt.Errorf("failed to get membership: %w", err)
 	}
 
-	return &model.AuthPayload{
-		Token: token,
-		User:  protoToGraphQLUser(resp.User),
-	}, nil
+	// Issue a token outside of any session: the new user logs in to get a
+	// refresh token
+	return r.sessionPayload(resp.User, "", "", membership.Membership)
 }
 
 func (r *mutationResolver) LoginImpl(ctx context.Context, input model.LoginInput) (*model.AuthPayload, error) {
@@ -89,21 +90,172 @@ func (r *mutationResolver) LoginImpl(ctx context.Context, input model.LoginInput
 		return nil, fmt.Errorf("authentication failed: %s", resp.Message)
 	}
 
-	// Generate JWT token
-	token, err := r.JWTManager.GenerateToken(
-		resp.User.Id,
-		resp.User.Email,
-		resp.User.Name,
-		resp.User.Role,
+	// Open a session for the refresh token
+	client := auth.ClientFromContext(ctx)
+	sessionReq := &userpb.CreateSessionRequest{
+		UserId:    resp.User.Id,
+		IpAddress: client.IP,
+		UserAgent: client.UserAgent,
+		OrgId:     stringPtrToValue(input.OrganizationID),
+	}
+	if input.DeviceName != nil {
+		sessionReq.Device = *input.DeviceName
+	}
+	session, err := r.UserClient.CreateSession(ctx, sessionReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return r.sessionPayload(resp.User, session.Session.Id, session.RefreshToken, session.Membership)
+}
+
+// RefreshTokenImpl exchanges a refresh token for new access and refresh
+// tokens of the same session
+func (r *mutationResolver) RefreshTokenImpl(ctx context.Context, refreshToken string) (*model.AuthPayload, error) {
+	client := auth.ClientFromContext(ctx)
+	resp, err := r.UserClient.RefreshSession(ctx, &userpb.RefreshSessionRequest{
+		RefreshToken: refreshToken,
+		IpAddress:    client.IP,
+		UserAgent:    client.UserAgent,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+
+	return r.sessionPayload(resp.User, resp.Session.Id, resp.RefreshToken, resp.Membership)
+}
+
+// SwitchOrganizationImpl moves the session of the access token to another
+// organization of the user and issues an access token for it. The refresh
+// token of the session stays valid and now refreshes into that organization.
+func (r *mutationResolver) SwitchOrganizationImpl(ctx context.Context, organizationID string) (*model.AuthPayload, error) {
+	claims, err := auth.RequireAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if claims.SessionID == "" {
+		return nil, fmt.Errorf("token is not bound to a session")
+	}
+
+	resp, err := r.UserClient.SwitchSessionOrganization(ctx, &userpb.SwitchSessionOrganizationRequest{
+		SessionId: claims.SessionID,
+		UserId:    claims.UserID,
+		OrgId:     organizationID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to switch organization: %w", err)
+	}
+
+	log.Printf("🏢 Session %s of %s switched to organization %s", claims.SessionID, claims.Email, organizationID)
+	return r.sessionPayload(resp.User, resp.Session.Id, "", resp.Membership)
+}
+
+// sessionPayload issues an access token in the organization of membership,
+// bound to a session unless sessionID is empty. The token is granted the
+// platform permissions of the user and the permissions of the membership.
+func (r *mutationResolver) sessionPayload(user *userpb.User, sessionID, refreshToken string, membership *userpb.Membership) (*model.AuthPayload, error) {
+	if membership == nil {
+		return nil, fmt.Errorf("user %s has no organization", user.Id)
+	}
+	permissions := auth.TokenPermissions(user.Permissions, membership.Permissions)
+
+	token, expiresAt, err := r.JWTManager.GenerateSessionToken(
+		user.Id,
+		user.Email,
+		user.Name,
+		user.Role,
+		sessionID,
+		membership.OrgId,
+		membership.Role,
+		permissions,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
 
-	return &model.AuthPayload{
-		Token: token,
-		User:  protoToGraphQLUser(resp.User),
-	}, nil
+	payload := &model.AuthPayload{
+		Token:            token,
+		ExpiresAt:        int(expiresAt.Unix()),
+		RefreshToken:     stringValuePtr(refreshToken),
+		User:             protoToGraphQLUser(user),
+		OrganizationID:   membership.OrgId,
+		OrganizationRole: &membership.Role,
+	}
+	payload.User.Permissions = permissions
+	payload.User.OrgRole = &membership.Role
+	return payload, nil
+}
+
+// LogoutImpl revokes the session of the access token. The access token
+// itself stays valid until it expires.
+func (r *mutationResolver) LogoutImpl(ctx context.Context) (bool, error) {
+	claims, err := auth.RequireAuth(ctx)
+	if err != nil {
+		return false, err
+	}
+	if claims.SessionID == "" {
+		return false, fmt.Errorf("token is not bound to a session")
+	}
+
+	_, err = r.UserClient.RevokeSession(ctx, &userpb.RevokeSessionRequest{
+		Id:     claims.SessionID,
+		UserId: claims.UserID,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to logout: %w", err)
+	}
+	return true, nil
+}
+
+// LogoutAllSessionsImpl revokes all sessions of the current user
+func (r *mutationResolver) LogoutAllSessionsImpl(ctx context.Context) (int, error) {
+	claims, err := auth.RequireAuth(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := r.UserClient.RevokeUserSessions(ctx, &userpb.RevokeUserSessionsRequest{UserId: claims.UserID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return int(resp.Revoked), nil
+}
+
+// RevokeUserSessionsImpl revokes the sessions of a member signed in to the
+// current organization. Platform admins revoke them in every organization.
+func (r *mutationResolver) RevokeUserSessionsImpl(ctx context.Context, userID string) (int, error) {
+	claims, err := auth.RequireAuth(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err := r.authorizeMember(ctx, userID); err != nil {
+		return 0, err
+	}
+	req := &userpb.RevokeUserSessionsRequest{UserId: userID}
+	if !claims.IsPlatformAdmin() {
+		req.OrgId = claims.Organization()
+	}
+
+	resp, err := r.UserClient.RevokeUserSessions(ctx, req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return int(resp.Revoked), nil
+}
+
+// RefreshConnectionTokenImpl extends the WebSocket connection the mutation
+// is sent on with a newer token of the same user
+func (r *mutationResolver) RefreshConnectionTokenImpl(ctx context.Context, token string) (int, error) {
+	session, ok := auth.WebsocketSessionFromContext(ctx)
+	if !ok {
+		return 0, fmt.Errorf("refreshConnectionToken is only available on WebSocket connections")
+	}
+
+	expiresAt, err := session.Refresh(ctx, token)
+	if err != nil {
+		return 0, fmt.Errorf("failed to refresh connection token: %w", err)
+	}
+	return int(expiresAt.Unix()), nil
 }
 
 // Query resolver for current user
@@ -128,14 +280,57 @@ func (r *queryResolver) MeImpl(ctx context.Context) (*model.User, error) {
 	return protoToGraphQLUser(resp.User), nil
 }
 
+// SessionsImpl lists the active sessions of the current user
+func (r *queryResolver) SessionsImpl(ctx context.Context) ([]*model.Session, error) {
+	claims, err := auth.RequireAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.UserClient.ListSessions(ctx, &userpb.ListSessionsRequest{UserId: claims.UserID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	sessions := make([]*model.Session, len(resp.Sessions))
+	for i, s := range resp.Sessions {
+		sessions[i] = &model.Session{
+			ID:             s.Id,
+			Device:         s.Device,
+			IPAddress:      s.IpAddress,
+			UserAgent:      s.UserAgent,
+			CreatedAt:      int(s.CreatedAt),
+			LastUsedAt:     int(s.LastUsedAt),
+			ExpiresAt:      int(s.ExpiresAt),
+			Current:        s.Id == claims.SessionID,
+			OrganizationID: s.OrgId,
+		}
+	}
+	return sessions, nil
+}
+
 // Helper functions
 
 func intPtr(i int) *int {
 	return &i
 }
 
-// UsersImpl returns a paginated list of users (admin only)
+// stringValuePtr returns nil for an empty string
+func stringValuePtr(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// UsersImpl returns a paginated list of the members of the organization,
+// role filtering on their role in the organization
 func (r *queryResolver) UsersImpl(ctx context.Context, page *int, pageSize *int, role *string) (*model.UserConnection, error) {
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// Default values
 	p := int32(1)
 	ps := int32(20)
@@ -150,6 +345,7 @@ func (r *queryResolver) UsersImpl(ctx context.Context, page *int, pageSize *int,
 	req := &userpb.ListUsersRequest{
 		Page:     p,
 		PageSize: ps,
+		OrgId:    orgID,
 	}
 
 	// Set role filter if provided
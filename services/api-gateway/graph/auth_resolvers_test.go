@@ -0,0 +1,309 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/auth"
+	"github.com/yourusername/iot-platform/services/api-gateway/graph/model"
+	userpb "github.com/yourusername/iot-platform/shared/proto/user"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MockUserServiceClient is a mock implementation of userpb.UserServiceClient for testing.
+type MockUserServiceClient struct {
+	userpb.UserServiceClient
+
+	AuthenticateFunc       func(ctx context.Context, req *userpb.AuthenticateRequest, opts ...grpc.CallOption) (*userpb.AuthenticateResponse, error)
+	CreateSessionFunc      func(ctx context.Context, req *userpb.CreateSessionRequest, opts ...grpc.CallOption) (*userpb.CreateSessionResponse, error)
+	RefreshSessionFunc     func(ctx context.Context, req *userpb.RefreshSessionRequest, opts ...grpc.CallOption) (*userpb.RefreshSessionResponse, error)
+	RevokeSessionFunc      func(ctx context.Context, req *userpb.RevokeSessionRequest, opts ...grpc.CallOption) (*userpb.RevokeSessionResponse, error)
+	RevokeUserSessionsFunc func(ctx context.Context, req *userpb.RevokeUserSessionsRequest, opts ...grpc.CallOption) (*userpb.RevokeUserSessionsResponse, error)
+	ListSessionsFunc       func(ctx context.Context, req *userpb.ListSessionsRequest, opts ...grpc.CallOption) (*userpb.ListSessionsResponse, error)
+	GetUserFunc            func(ctx context.Context, req *userpb.GetUserRequest, opts ...grpc.CallOption) (*userpb.GetUserResponse, error)
+	RegisterFunc           func(ctx context.Context, req *userpb.RegisterRequest, opts ...grpc.CallOption) (*userpb.RegisterResponse, error)
+	UpsertRoleFunc         func(ctx context.Context, req *userpb.UpsertRoleRequest, opts ...grpc.CallOption) (*userpb.UpsertRoleResponse, error)
+
+	SwitchSessionOrganizationFunc func(ctx context.Context, req *userpb.SwitchSessionOrganizationRequest, opts ...grpc.CallOption) (*userpb.SwitchSessionOrganizationResponse, error)
+	ListOrganizationsFunc         func(ctx context.Context, req *userpb.ListOrganizationsRequest, opts ...grpc.CallOption) (*userpb.ListOrganizationsResponse, error)
+	AddMemberFunc                 func(ctx context.Context, req *userpb.AddMemberRequest, opts ...grpc.CallOption) (*userpb.MemberResponse, error)
+	UpdateMemberFunc              func(ctx context.Context, req *userpb.UpdateMemberRequest, opts ...grpc.CallOption) (*userpb.MemberResponse, error)
+	GetMembershipFunc             func(ctx context.Context, req *userpb.GetMembershipRequest, opts ...grpc.CallOption) (*userpb.MemberResponse, error)
+
+	CreateApiKeyFunc func(ctx context.Context, req *userpb.CreateApiKeyRequest, opts ...grpc.CallOption) (*userpb.CreateApiKeyResponse, error)
+	RevokeApiKeyFunc func(ctx context.Context, req *userpb.RevokeApiKeyRequest, opts ...grpc.CallOption) (*userpb.RevokeApiKeyResponse, error)
+}
+
+func (m *MockUserServiceClient) Authenticate(ctx context.Context, req *userpb.AuthenticateRequest, opts ...grpc.CallOption) (*userpb.AuthenticateResponse, error) {
+	if m.AuthenticateFunc != nil {
+		return m.AuthenticateFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("AuthenticateFunc not implemented")
+}
+
+func (m *MockUserServiceClient) CreateSession(ctx context.Context, req *userpb.CreateSessionRequest, opts ...grpc.CallOption) (*userpb.CreateSessionResponse, error) {
+	if m.CreateSessionFunc != nil {
+		return m.CreateSessionFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("CreateSessionFunc not implemented")
+}
+
+func (m *MockUserServiceClient) RefreshSession(ctx context.Context, req *userpb.RefreshSessionRequest, opts ...grpc.CallOption) (*userpb.RefreshSessionResponse, error) {
+	if m.RefreshSessionFunc != nil {
+		return m.RefreshSessionFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("RefreshSessionFunc not implemented")
+}
+
+func (m *MockUserServiceClient) RevokeSession(ctx context.Context, req *userpb.RevokeSessionRequest, opts ...grpc.CallOption) (*userpb.RevokeSessionResponse, error) {
+	if m.RevokeSessionFunc != nil {
+		return m.RevokeSessionFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("RevokeSessionFunc not implemented")
+}
+
+func (m *MockUserServiceClient) RevokeUserSessions(ctx context.Context, req *userpb.RevokeUserSessionsRequest, opts ...grpc.CallOption) (*userpb.RevokeUserSessionsResponse, error) {
+	if m.RevokeUserSessionsFunc != nil {
+		return m.RevokeUserSessionsFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("RevokeUserSessionsFunc not implemented")
+}
+
+func (m *MockUserServiceClient) ListSessions(ctx context.Context, req *userpb.ListSessionsRequest, opts ...grpc.CallOption) (*userpb.ListSessionsResponse, error) {
+	if m.ListSessionsFunc != nil {
+		return m.ListSessionsFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("ListSessionsFunc not implemented")
+}
+
+func (m *MockUserServiceClient) GetUser(ctx context.Context, req *userpb.GetUserRequest, opts ...grpc.CallOption) (*userpb.GetUserResponse, error) {
+	if m.GetUserFunc != nil {
+		return m.GetUserFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("GetUserFunc not implemented")
+}
+
+func (m *MockUserServiceClient) Register(ctx context.Context, req *userpb.RegisterRequest, opts ...grpc.CallOption) (*userpb.RegisterResponse, error) {
+	if m.RegisterFunc != nil {
+		return m.RegisterFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("RegisterFunc not implemented")
+}
+
+func (m *MockUserServiceClient) UpsertRole(ctx context.Context, req *userpb.UpsertRoleRequest, opts ...grpc.CallOption) (*userpb.UpsertRoleResponse, error) {
+	if m.UpsertRoleFunc != nil {
+		return m.UpsertRoleFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("UpsertRoleFunc not implemented")
+}
+
+func (m *MockUserServiceClient) SwitchSessionOrganization(ctx context.Context, req *userpb.SwitchSessionOrganizationRequest, opts ...grpc.CallOption) (*userpb.SwitchSessionOrganizationResponse, error) {
+	if m.SwitchSessionOrganizationFunc != nil {
+		return m.SwitchSessionOrganizationFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("SwitchSessionOrganizationFunc not implemented")
+}
+
+func (m *MockUserServiceClient) ListOrganizations(ctx context.Context, req *userpb.ListOrganizationsRequest, opts ...grpc.CallOption) (*userpb.ListOrganizationsResponse, error) {
+	if m.ListOrganizationsFunc != nil {
+		return m.ListOrganizationsFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("ListOrganizationsFunc not implemented")
+}
+
+func (m *MockUserServiceClient) AddMember(ctx context.Context, req *userpb.AddMemberRequest, opts ...grpc.CallOption) (*userpb.MemberResponse, error) {
+	if m.AddMemberFunc != nil {
+		return m.AddMemberFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("AddMemberFunc not implemented")
+}
+
+func (m *MockUserServiceClient) UpdateMember(ctx context.Context, req *userpb.UpdateMemberRequest, opts ...grpc.CallOption) (*userpb.MemberResponse, error) {
+	if m.UpdateMemberFunc != nil {
+		return m.UpdateMemberFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("UpdateMemberFunc not implemented")
+}
+
+func (m *MockUserServiceClient) GetMembership(ctx context.Context, req *userpb.GetMembershipRequest, opts ...grpc.CallOption) (*userpb.MemberResponse, error) {
+	if m.GetMembershipFunc != nil {
+		return m.GetMembershipFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("GetMembershipFunc not implemented")
+}
+
+var testUser = &userpb.User{Id: "user-1", Email: "user@example.com", Name: "User", Role: "user", IsActive: true}
+
+// testMembership is the membership of testUser in org-1
+var testMembership = &userpb.Membership{OrgId: "org-1", UserId: "user-1", Role: "user", Permissions: []string{auth.PermDevicesRead, auth.PermTelemetryRead}}
+
+// TestLoginImpl_CreatesSession tests that login opens a session with the
+// client details and binds the access token to it.
+func TestLoginImpl_CreatesSession(t *testing.T) {
+	var sessionReq *userpb.CreateSessionRequest
+	mock := &MockUserServiceClient{
+		AuthenticateFunc: func(ctx context.Context, req *userpb.AuthenticateRequest, opts ...grpc.CallOption) (*userpb.AuthenticateResponse, error) {
+			return &userpb.AuthenticateResponse{Success: true, User: testUser}, nil
+		},
+		CreateSessionFunc: func(ctx context.Context, req *userpb.CreateSessionRequest, opts ...grpc.CallOption) (*userpb.CreateSessionResponse, error) {
+			sessionReq = req
+			return &userpb.CreateSessionResponse{Session: &userpb.Session{Id: "session-1", OrgId: req.OrgId}, RefreshToken: "refresh-1", Membership: testMembership}, nil
+		},
+	}
+	jwtManager := auth.NewJWTManager("test-secret", 15*time.Minute)
+	r := &mutationResolver{&Resolver{UserClient: mock, JWTManager: jwtManager}}
+
+	// Context of a request through the client middleware
+	var ctx context.Context
+	req := httptest.NewRequest("POST", "/query", nil)
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	req.Header.Set("User-Agent", "test-agent")
+	auth.ClientMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	})).ServeHTTP(httptest.NewRecorder(), req)
+
+	deviceName, orgID := "Laptop", "org-1"
+	payload, err := r.LoginImpl(ctx, model.LoginInput{Email: testUser.Email, Password: "secret", DeviceName: &deviceName, OrganizationID: &orgID})
+	if err != nil {
+		t.Fatalf("LoginImpl failed: %v", err)
+	}
+	if sessionReq.UserId != "user-1" || sessionReq.Device != "Laptop" || sessionReq.IpAddress != "203.0.113.7" || sessionReq.UserAgent != "test-agent" || sessionReq.OrgId != "org-1" {
+		t.Errorf("unexpected session request %+v", sessionReq)
+	}
+	if payload.RefreshToken == nil || *payload.RefreshToken != "refresh-1" {
+		t.Errorf("expected refresh token refresh-1, got %v", payload.RefreshToken)
+	}
+	claims, err := jwtManager.ValidateToken(payload.Token)
+	if err != nil {
+		t.Fatalf("invalid access token: %v", err)
+	}
+	if claims.SessionID != "session-1" || int(claims.ExpiresAt.Unix()) != payload.ExpiresAt {
+		t.Errorf("unexpected claims %+v, expiresAt %d", claims, payload.ExpiresAt)
+	}
+	// The token acts in the organization of the session with its role
+	if claims.OrgID != "org-1" || payload.OrganizationID != "org-1" || *payload.OrganizationRole != "user" {
+		t.Errorf("unexpected organization %q, payload %+v", claims.OrgID, payload)
+	}
+	if !claims.HasPermission(auth.PermTelemetryRead) || claims.HasPermission(auth.PermDevicesWrite) {
+		t.Errorf("unexpected permissions %v", claims.Permissions)
+	}
+}
+
+func TestRefreshTokenImpl(t *testing.T) {
+	mock := &MockUserServiceClient{
+		RefreshSessionFunc: func(ctx context.Context, req *userpb.RefreshSessionRequest, opts ...grpc.CallOption) (*userpb.RefreshSessionResponse, error) {
+			if req.RefreshToken != "refresh-1" {
+				return nil, status.Error(codes.Unauthenticated, "refresh token reuse detected")
+			}
+			return &userpb.RefreshSessionResponse{Session: &userpb.Session{Id: "session-1"}, RefreshToken: "refresh-2", User: testUser, Membership: testMembership}, nil
+		},
+	}
+	jwtManager := auth.NewJWTManager("test-secret", 15*time.Minute)
+	r := &mutationResolver{&Resolver{UserClient: mock, JWTManager: jwtManager}}
+
+	payload, err := r.RefreshTokenImpl(context.Background(), "refresh-1")
+	if err != nil {
+		t.Fatalf("RefreshTokenImpl failed: %v", err)
+	}
+	if *payload.RefreshToken != "refresh-2" {
+		t.Errorf("expected rotated refresh token, got %s", *payload.RefreshToken)
+	}
+	if claims, err := jwtManager.ValidateToken(payload.Token); err != nil || claims.SessionID != "session-1" || claims.OrgID != "org-1" {
+		t.Errorf("unexpected access token: %+v, %v", claims, err)
+	}
+
+	_, err = r.RefreshTokenImpl(context.Background(), "refresh-0")
+	if status.Code(errors.Unwrap(err)) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated, got %v", err)
+	}
+}
+
+func TestLogoutImpl(t *testing.T) {
+	var revoked *userpb.RevokeSessionRequest
+	mock := &MockUserServiceClient{
+		RevokeSessionFunc: func(ctx context.Context, req *userpb.RevokeSessionRequest, opts ...grpc.CallOption) (*userpb.RevokeSessionResponse, error) {
+			revoked = req
+			return &userpb.RevokeSessionResponse{Success: true}, nil
+		},
+	}
+	r := &mutationResolver{&Resolver{UserClient: mock}}
+
+	if _, err := r.LogoutImpl(userContext()); err == nil {
+		t.Error("expected error for a token without session")
+	}
+
+	ctx := auth.WithUser(context.Background(), &auth.Claims{UserID: "user-1", Role: "user", SessionID: "session-1"})
+	ok, err := r.LogoutImpl(ctx)
+	if err != nil || !ok {
+		t.Fatalf("LogoutImpl = %v, %v", ok, err)
+	}
+	if revoked.Id != "session-1" || revoked.UserId != "user-1" {
+		t.Errorf("unexpected revoke request %+v", revoked)
+	}
+}
+
+func TestRevokeUserSessionsImpl(t *testing.T) {
+	var revokedUser, revokedOrg string
+	mock := &MockUserServiceClient{
+		RevokeUserSessionsFunc: func(ctx context.Context, req *userpb.RevokeUserSessionsRequest, opts ...grpc.CallOption) (*userpb.RevokeUserSessionsResponse, error) {
+			revokedUser, revokedOrg = req.UserId, req.OrgId
+			return &userpb.RevokeUserSessionsResponse{Revoked: 3}, nil
+		},
+		GetMembershipFunc: func(ctx context.Context, req *userpb.GetMembershipRequest, opts ...grpc.CallOption) (*userpb.MemberResponse, error) {
+			if req.UserId != "user-2" || req.OrgId != "org-1" {
+				return nil, status.Error(codes.NotFound, "membership not found")
+			}
+			return &userpb.MemberResponse{Membership: &userpb.Membership{OrgId: req.OrgId, UserId: req.UserId, Role: "user"}}, nil
+		},
+	}
+	r := &mutationResolver{&Resolver{UserClient: mock}}
+
+	revoked, err := r.RevokeUserSessionsImpl(adminContext(), "user-2")
+	if err != nil || revoked != 3 || revokedUser != "user-2" || revokedOrg != "" {
+		t.Errorf("RevokeUserSessionsImpl = %d, %v for %s in %q", revoked, err, revokedUser, revokedOrg)
+	}
+
+	// Organization admins only revoke the sessions of their members
+	orgAdminCtx := auth.WithUser(context.Background(), &auth.Claims{UserID: "user-1", Role: "user", OrgID: "org-1", Permissions: []string{auth.PermUsersAdmin}})
+	revokedUser = ""
+	if _, err := r.RevokeUserSessionsImpl(orgAdminCtx, "user-3"); !errors.Is(err, auth.ErrForbidden) || revokedUser != "" {
+		t.Errorf("expected ErrForbidden for a user of another organization, got %v", err)
+	}
+	// Only the sessions signed in to their organization
+	if _, err := r.RevokeUserSessionsImpl(orgAdminCtx, "user-2"); err != nil || revokedUser != "user-2" || revokedOrg != "org-1" {
+		t.Errorf("RevokeUserSessionsImpl = %v for %s in %q, want org-1", err, revokedUser, revokedOrg)
+	}
+
+	// Users revoke their own sessions only
+	revoked, err = r.LogoutAllSessionsImpl(userContext())
+	if err != nil || revoked != 3 || revokedUser != "user-1" {
+		t.Errorf("LogoutAllSessionsImpl = %d, %v for %s", revoked, err, revokedUser)
+	}
+}
+
+func TestSessionsImpl_MarksCurrent(t *testing.T) {
+	mock := &MockUserServiceClient{
+		ListSessionsFunc: func(ctx context.Context, req *userpb.ListSessionsRequest, opts ...grpc.CallOption) (*userpb.ListSessionsResponse, error) {
+			return &userpb.ListSessionsResponse{Sessions: []*userpb.Session{
+				{Id: "session-2", UserId: req.UserId, Device: "Phone"},
+				{Id: "session-1", UserId: req.UserId, Device: "Laptop"},
+			}}, nil
+		},
+	}
+	r := &queryResolver{&Resolver{UserClient: mock}}
+
+	ctx := auth.WithUser(context.Background(), &auth.Claims{UserID: "user-1", Role: "user", SessionID: "session-1"})
+	sessions, err := r.SessionsImpl(ctx)
+	if err != nil {
+		t.Fatalf("SessionsImpl failed: %v", err)
+	}
+	if len(sessions) != 2 || sessions[0].Current || !sessions[1].Current {
+		t.Errorf("unexpected sessions %+v", sessions)
+	}
+}
@@ -0,0 +1,97 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/graph/model"
+	telemetrypb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+)
+
+// derivedMetricKinds maps GraphQL kinds to their protobuf values.
+var derivedMetricKinds = map[model.DerivedMetricKind]telemetrypb.DerivedMetricKind{
+	model.DerivedMetricKindExpression:    telemetrypb.DerivedMetricKind_DERIVED_METRIC_EXPRESSION,
+	model.DerivedMetricKindIntegral:      telemetrypb.DerivedMetricKind_DERIVED_METRIC_INTEGRAL,
+	model.DerivedMetricKindMovingAverage: telemetrypb.DerivedMetricKind_DERIVED_METRIC_MOVING_AVERAGE,
+}
+
+func protoToGraphQLDerivedMetric(m *telemetrypb.DerivedMetric) *model.DerivedMetric {
+	metric := &model.DerivedMetric{
+		ID:         m.Id,
+		DeviceType: m.DeviceType,
+		Name:       m.Name,
+		Kind:       model.DerivedMetricKindExpression,
+		CreatedAt:  int(m.CreatedAt),
+		UpdatedAt:  int(m.UpdatedAt),
+	}
+	for kind, pbKind := range derivedMetricKinds {
+		if pbKind == m.Kind {
+			metric.Kind = kind
+		}
+	}
+	if m.Expression != "" {
+		metric.Expression = &m.Expression
+	}
+	if m.SourceMetric != "" {
+		metric.SourceMetric = &m.SourceMetric
+	}
+	if m.Window != "" {
+		metric.Window = &m.Window
+	}
+	if m.Unit != "" {
+		metric.Unit = &m.Unit
+	}
+	return metric
+}
+
+// DerivedMetricsImpl lists derived metric definitions.
+func (r *queryResolver) DerivedMetricsImpl(ctx context.Context, deviceType *string) ([]*model.DerivedMetric, error) {
+	resp, err := r.TelemetryClient.ListDerivedMetrics(ctx, &telemetrypb.ListDerivedMetricsRequest{
+		DeviceType: stringPtrToValue(deviceType),
+	})
+	if err != nil {
+		log.Printf("❌ Failed to list derived metrics: %v", err)
+		return nil, err
+	}
+
+	metrics := make([]*model.DerivedMetric, len(resp.Metrics))
+	for i, m := range resp.Metrics {
+		metrics[i] = protoToGraphQLDerivedMetric(m)
+	}
+	return metrics, nil
+}
+
+// UpsertDerivedMetricImpl creates or replaces a derived metric definition.
+func (r *mutationResolver) UpsertDerivedMetricImpl(ctx context.Context, input model.DerivedMetricInput) (*model.DerivedMetric, error) {
+	metric, err := r.TelemetryClient.UpsertDerivedMetric(ctx, &telemetrypb.UpsertDerivedMetricRequest{
+		Metric: &telemetrypb.DerivedMetric{
+			DeviceType:   input.DeviceType,
+			Name:         input.Name,
+			Kind:         derivedMetricKinds[input.Kind],
+			Expression:   stringPtrToValue(input.Expression),
+			SourceMetric: stringPtrToValue(input.SourceMetric),
+			Window:       stringPtrToValue(input.Window),
+			Unit:         stringPtrToValue(input.Unit),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save derived metric: %w", err)
+	}
+
+	log.Printf("✅ Derived metric saved: %s/%s", metric.DeviceType, metric.Name)
+	return protoToGraphQLDerivedMetric(metric), nil
+}
+
+// DeleteDerivedMetricImpl deletes a derived metric definition.
+func (r *mutationResolver) DeleteDerivedMetricImpl(ctx context.Context, id string) (*model.DeleteResult, error) {
+	resp, err := r.TelemetryClient.DeleteDerivedMetric(ctx, &telemetrypb.DeleteDerivedMetricRequest{Id: id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete derived metric: %w", err)
+	}
+
+	return &model.DeleteResult{
+		Success: resp.Success,
+		Message: "Derived metric deleted",
+	}, nil
+}
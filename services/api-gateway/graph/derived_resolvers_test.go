@@ -0,0 +1,80 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/graph/model"
+	telemetrypb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+	"google.golang.org/grpc"
+)
+
+// TestDerivedMetricsImpl tests the derivedMetrics query resolver.
+func TestDerivedMetricsImpl(t *testing.T) {
+	mock := &MockTelemetryServiceClient{
+		ListDerivedMetricsFunc: func(ctx context.Context, req *telemetrypb.ListDerivedMetricsRequest, opts ...grpc.CallOption) (*telemetrypb.ListDerivedMetricsResponse, error) {
+			if req.DeviceType != "hvac" {
+				t.Errorf("expected device type filter hvac, got %q", req.DeviceType)
+			}
+			return &telemetrypb.ListDerivedMetricsResponse{
+				Metrics: []*telemetrypb.DerivedMetric{
+					{Id: "d1", DeviceType: "hvac", Name: "dew_point", Expression: "temperature - (100 - humidity) / 5", Unit: "celsius"},
+					{Id: "d2", DeviceType: "hvac", Name: "energy", Kind: telemetrypb.DerivedMetricKind_DERIVED_METRIC_INTEGRAL, SourceMetric: "power", Unit: "Wh"},
+				},
+			}, nil
+		},
+	}
+	resolver := &queryResolver{&Resolver{TelemetryClient: mock}}
+
+	metrics, err := resolver.DerivedMetricsImpl(userContext(), stringPtr("hvac"))
+	if err != nil {
+		t.Fatalf("DerivedMetricsImpl() error = %v", err)
+	}
+	if len(metrics) != 2 {
+		t.Fatalf("expected 2 metrics, got %d", len(metrics))
+	}
+	if metrics[0].Kind != model.DerivedMetricKindExpression || metrics[0].Expression == nil || metrics[0].SourceMetric != nil {
+		t.Errorf("unexpected expression metric: %+v", metrics[0])
+	}
+	if metrics[1].Kind != model.DerivedMetricKindIntegral || metrics[1].SourceMetric == nil || *metrics[1].SourceMetric != "power" {
+		t.Errorf("unexpected integral metric: %+v", metrics[1])
+	}
+}
+
+// TestUpsertDerivedMetricImpl tests the upsertDerivedMetric mutation resolver.
+func TestUpsertDerivedMetricImpl(t *testing.T) {
+	input := model.DerivedMetricInput{
+		DeviceType:   "hvac",
+		Name:         "power_avg",
+		Kind:         model.DerivedMetricKindMovingAverage,
+		SourceMetric: stringPtr("power"),
+		Window:       stringPtr("15m"),
+	}
+
+	t.Run("admin", func(t *testing.T) {
+		mock := &MockTelemetryServiceClient{
+			UpsertDerivedMetricFunc: func(ctx context.Context, req *telemetrypb.UpsertDerivedMetricRequest, opts ...grpc.CallOption) (*telemetrypb.DerivedMetric, error) {
+				if req.Metric.Kind != telemetrypb.DerivedMetricKind_DERIVED_METRIC_MOVING_AVERAGE || req.Metric.Window != "15m" {
+					t.Errorf("unexpected metric: %v", req.Metric)
+				}
+				return &telemetrypb.DerivedMetric{
+					Id:           "d3",
+					DeviceType:   req.Metric.DeviceType,
+					Name:         req.Metric.Name,
+					Kind:         req.Metric.Kind,
+					SourceMetric: req.Metric.SourceMetric,
+					Window:       req.Metric.Window,
+				}, nil
+			},
+		}
+		resolver := &mutationResolver{&Resolver{TelemetryClient: mock}}
+
+		metric, err := resolver.UpsertDerivedMetricImpl(adminContext(), input)
+		if err != nil {
+			t.Fatalf("UpsertDerivedMetricImpl() error = %v", err)
+		}
+		if metric.ID != "d3" || metric.Kind != model.DerivedMetricKindMovingAverage || metric.Unit != nil {
+			t.Errorf("unexpected metric: %+v", metric)
+		}
+	})
+}
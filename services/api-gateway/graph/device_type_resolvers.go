@@ -0,0 +1,189 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/auth"
+	"github.com/yourusername/iot-platform/services/api-gateway/graph/model"
+	devicepb "github.com/yourusername/iot-platform/shared/proto/device"
+	telemetrypb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+)
+
+// metricDataTypes maps GraphQL data types to their protobuf values.
+var metricDataTypes = map[model.MetricDataType]devicepb.MetricDataType{
+	model.MetricDataTypeFloat:   devicepb.MetricDataType_METRIC_FLOAT,
+	model.MetricDataTypeInteger: devicepb.MetricDataType_METRIC_INTEGER,
+	model.MetricDataTypeBoolean: devicepb.MetricDataType_METRIC_BOOLEAN,
+}
+
+// catalogDataTypes maps the data types returned by the data-collector
+// ("float", "integer", "boolean") to GraphQL.
+var catalogDataTypes = map[string]model.MetricDataType{
+	"float":   model.MetricDataTypeFloat,
+	"integer": model.MetricDataTypeInteger,
+	"boolean": model.MetricDataTypeBoolean,
+}
+
+func protoToGraphQLDeviceType(t *devicepb.DeviceType) *model.DeviceType {
+	deviceType := &model.DeviceType{
+		Name:        t.Name,
+		DisplayName: t.DisplayName,
+		Metrics:     make([]*model.MetricDefinition, len(t.Metrics)),
+		CreatedAt:   int(t.CreatedAt),
+		UpdatedAt:   int(t.UpdatedAt),
+	}
+	if t.Description != "" {
+		deviceType.Description = &t.Description
+	}
+
+	for i, m := range t.Metrics {
+		metric := &model.MetricDefinition{
+			Name:        m.Name,
+			DisplayName: m.DisplayName,
+			DataType:    model.MetricDataTypeFloat,
+			MinValue:    m.MinValue,
+			MaxValue:    m.MaxValue,
+		}
+		for dataType, pbDataType := range metricDataTypes {
+			if pbDataType == m.DataType {
+				metric.DataType = dataType
+			}
+		}
+		if m.Unit != "" {
+			metric.Unit = &m.Unit
+		}
+		if m.ReportingIntervalSeconds > 0 {
+			metric.ReportingInterval = intPtr(int(m.ReportingIntervalSeconds))
+		}
+		deviceType.Metrics[i] = metric
+	}
+	return deviceType
+}
+
+// DeviceTypesImpl lists registered device types.
+func (r *queryResolver) DeviceTypesImpl(ctx context.Context) ([]*model.DeviceType, error) {
+	resp, err := r.DeviceClient.ListDeviceTypes(ctx, &devicepb.ListDeviceTypesRequest{})
+	if err != nil {
+		log.Printf("❌ Failed to list device types: %v", err)
+		return nil, err
+	}
+
+	deviceTypes := make([]*model.DeviceType, len(resp.DeviceTypes))
+	for i, t := range resp.DeviceTypes {
+		deviceTypes[i] = protoToGraphQLDeviceType(t)
+	}
+	return deviceTypes, nil
+}
+
+// DeviceTypeImpl retrieves a device type, or nil if it is not registered.
+func (r *queryResolver) DeviceTypeImpl(ctx context.Context, name string) (*model.DeviceType, error) {
+	resp, err := r.DeviceClient.GetDeviceType(ctx, &devicepb.GetDeviceTypeRequest{Name: name})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get device type: %w", err)
+	}
+
+	return protoToGraphQLDeviceType(resp.DeviceType), nil
+}
+
+// DeviceMetricCatalogImpl retrieves the metrics of a device with their catalog metadata.
+func (r *queryResolver) DeviceMetricCatalogImpl(ctx context.Context, deviceID string) ([]*model.MetricInfo, error) {
+	log.Printf("📊 Query deviceMetricCatalog: device=%s", deviceID)
+	if err := r.authorizeDevice(ctx, deviceID, auth.PermTelemetryRead); err != nil {
+		return nil, err
+	}
+
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.TelemetryClient.GetDeviceMetrics(ctx, &telemetrypb.GetDeviceMetricsRequest{
+		OrgId:    orgID,
+		DeviceId: deviceID,
+	})
+	if err != nil {
+		log.Printf("❌ Failed to get device metrics: %v", err)
+		return nil, err
+	}
+
+	metrics := make([]*model.MetricInfo, len(resp.Catalog))
+	for i, m := range resp.Catalog {
+		metric := &model.MetricInfo{
+			Name:        m.Name,
+			DisplayName: m.DisplayName,
+			DataType:    model.MetricDataTypeFloat,
+			MinValue:    m.MinValue,
+			MaxValue:    m.MaxValue,
+			Declared:    m.Declared,
+		}
+		if dataType, ok := catalogDataTypes[m.DataType]; ok {
+			metric.DataType = dataType
+		}
+		if m.Unit != "" {
+			metric.Unit = &m.Unit
+		}
+		if m.ReportingIntervalSeconds > 0 {
+			metric.ReportingInterval = intPtr(int(m.ReportingIntervalSeconds))
+		}
+		if m.LastTime > 0 {
+			metric.LastTime = intPtr(int(m.LastTime))
+		}
+		metrics[i] = metric
+	}
+	return metrics, nil
+}
+
+// UpsertDeviceTypeImpl creates or replaces a device type and its catalog.
+func (r *mutationResolver) UpsertDeviceTypeImpl(ctx context.Context, input model.DeviceTypeInput) (*model.DeviceType, error) {
+	deviceType := &devicepb.DeviceType{
+		Name:        input.Name,
+		DisplayName: stringPtrToValue(input.DisplayName),
+		Description: stringPtrToValue(input.Description),
+		Metrics:     make([]*devicepb.MetricDefinition, len(input.Metrics)),
+	}
+	for i, m := range input.Metrics {
+		metric := &devicepb.MetricDefinition{
+			Name:        m.Name,
+			DisplayName: stringPtrToValue(m.DisplayName),
+			Unit:        stringPtrToValue(m.Unit),
+			MinValue:    m.MinValue,
+			MaxValue:    m.MaxValue,
+		}
+		if m.DataType != nil {
+			metric.DataType = metricDataTypes[*m.DataType]
+		}
+		if m.ReportingInterval != nil {
+			metric.ReportingIntervalSeconds = int32(*m.ReportingInterval)
+		}
+		deviceType.Metrics[i] = metric
+	}
+
+	resp, err := r.DeviceClient.UpsertDeviceType(ctx, &devicepb.UpsertDeviceTypeRequest{DeviceType: deviceType})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save device type: %w", err)
+	}
+
+	log.Printf("✅ Device type saved: %s (%d metrics)", resp.DeviceType.Name, len(resp.DeviceType.Metrics))
+	return protoToGraphQLDeviceType(resp.DeviceType), nil
+}
+
+// DeleteDeviceTypeImpl deletes a device type.
+func (r *mutationResolver) DeleteDeviceTypeImpl(ctx context.Context, name string) (*model.DeleteResult, error) {
+	resp, err := r.DeviceClient.DeleteDeviceType(ctx, &devicepb.DeleteDeviceTypeRequest{Name: name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete device type: %w", err)
+	}
+
+	return &model.DeleteResult{
+		Success: resp.Success,
+		Message: resp.Message,
+	}, nil
+}
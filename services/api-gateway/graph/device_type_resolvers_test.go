@@ -0,0 +1,124 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/graph/model"
+	devicepb "github.com/yourusername/iot-platform/shared/proto/device"
+	telemetrypb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+)
+
+// TestDeviceTypeImpl tests the deviceType query resolver.
+func TestDeviceTypeImpl(t *testing.T) {
+	maxValue := 60.0
+	mock := &MockDeviceServiceClient{
+		GetDeviceTypeFunc: func(ctx context.Context, req *devicepb.GetDeviceTypeRequest, opts ...grpc.CallOption) (*devicepb.GetDeviceTypeResponse, error) {
+			if req.Name != "thermometer" {
+				return nil, status.Error(codes.NotFound, "device type not found")
+			}
+			return &devicepb.GetDeviceTypeResponse{
+				DeviceType: &devicepb.DeviceType{
+					Name:        "thermometer",
+					DisplayName: "Thermometer",
+					Metrics: []*devicepb.MetricDefinition{
+						{Name: "temperature", DisplayName: "Temperature", Unit: "celsius", MaxValue: &maxValue, ReportingIntervalSeconds: 60},
+						{Name: "door_open", DisplayName: "Door open", DataType: devicepb.MetricDataType_METRIC_BOOLEAN},
+					},
+				},
+			}, nil
+		},
+	}
+	resolver := &queryResolver{&Resolver{DeviceClient: mock}}
+
+	deviceType, err := resolver.DeviceTypeImpl(userContext(), "thermometer")
+	if err != nil {
+		t.Fatalf("DeviceTypeImpl() error = %v", err)
+	}
+	if deviceType.Description != nil || len(deviceType.Metrics) != 2 {
+		t.Fatalf("unexpected device type: %+v", deviceType)
+	}
+	temperature := deviceType.Metrics[0]
+	if temperature.Unit == nil || *temperature.Unit != "celsius" || temperature.MinValue != nil || *temperature.MaxValue != 60 || *temperature.ReportingInterval != 60 {
+		t.Errorf("unexpected temperature metric: %+v", temperature)
+	}
+	if deviceType.Metrics[1].DataType != model.MetricDataTypeBoolean || deviceType.Metrics[1].ReportingInterval != nil {
+		t.Errorf("unexpected door_open metric: %+v", deviceType.Metrics[1])
+	}
+
+	// Unknown types resolve to null
+	deviceType, err = resolver.DeviceTypeImpl(userContext(), "unknown")
+	if err != nil || deviceType != nil {
+		t.Errorf("DeviceTypeImpl(unknown) = %v, %v, want nil, nil", deviceType, err)
+	}
+}
+
+// TestUpsertDeviceTypeImpl tests the upsertDeviceType mutation resolver.
+func TestUpsertDeviceTypeImpl(t *testing.T) {
+	integer := model.MetricDataTypeInteger
+	minValue := 0.0
+	input := model.DeviceTypeInput{
+		Name: "counter",
+		Metrics: []*model.MetricDefinitionInput{
+			{Name: "pulses", DataType: &integer, MinValue: &minValue, ReportingInterval: intPtr(300)},
+		},
+	}
+
+	t.Run("admin", func(t *testing.T) {
+		mock := &MockDeviceServiceClient{
+			UpsertDeviceTypeFunc: func(ctx context.Context, req *devicepb.UpsertDeviceTypeRequest, opts ...grpc.CallOption) (*devicepb.UpsertDeviceTypeResponse, error) {
+				metric := req.DeviceType.Metrics[0]
+				if metric.DataType != devicepb.MetricDataType_METRIC_INTEGER || metric.GetMinValue() != 0 || metric.MinValue == nil || metric.ReportingIntervalSeconds != 300 {
+					t.Errorf("unexpected metric: %v", metric)
+				}
+				deviceType := req.DeviceType
+				deviceType.DisplayName = deviceType.Name
+				metric.DisplayName = metric.Name
+				return &devicepb.UpsertDeviceTypeResponse{DeviceType: deviceType}, nil
+			},
+		}
+		resolver := &mutationResolver{&Resolver{DeviceClient: mock}}
+
+		deviceType, err := resolver.UpsertDeviceTypeImpl(adminContext(), input)
+		if err != nil {
+			t.Fatalf("UpsertDeviceTypeImpl() error = %v", err)
+		}
+		if deviceType.DisplayName != "counter" || deviceType.Metrics[0].DataType != model.MetricDataTypeInteger {
+			t.Errorf("unexpected device type: %+v", deviceType)
+		}
+	})
+}
+
+// TestDeviceMetricCatalogImpl tests the deviceMetricCatalog query resolver.
+func TestDeviceMetricCatalogImpl(t *testing.T) {
+	mock := &MockTelemetryServiceClient{
+		GetDeviceMetricsFunc: func(ctx context.Context, req *telemetrypb.GetDeviceMetricsRequest, opts ...grpc.CallOption) (*telemetrypb.GetDeviceMetricsResponse, error) {
+			return &telemetrypb.GetDeviceMetricsResponse{
+				Metrics: []string{"humidity", "temperature"},
+				Catalog: []*telemetrypb.MetricInfo{
+					{Name: "humidity", DisplayName: "humidity", Unit: "percent", DataType: "float", LastTime: 1700000000},
+					{Name: "temperature", DisplayName: "Temperature", Unit: "celsius", DataType: "float", Declared: true},
+				},
+			}, nil
+		},
+	}
+	resolver := &queryResolver{&Resolver{TelemetryClient: mock, DeviceClient: &MockDeviceServiceClient{}}}
+
+	metrics, err := resolver.DeviceMetricCatalogImpl(userContext(), "device-1")
+	if err != nil {
+		t.Fatalf("DeviceMetricCatalogImpl() error = %v", err)
+	}
+	if len(metrics) != 2 {
+		t.Fatalf("expected 2 metrics, got %d", len(metrics))
+	}
+	if metrics[0].Declared || metrics[0].LastTime == nil || *metrics[0].LastTime != 1700000000 {
+		t.Errorf("unexpected undeclared metric: %+v", metrics[0])
+	}
+	if !metrics[1].Declared || metrics[1].DisplayName != "Temperature" || metrics[1].LastTime != nil {
+		t.Errorf("unexpected declared metric: %+v", metrics[1])
+	}
+}
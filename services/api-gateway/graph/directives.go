@@ -0,0 +1,19 @@
+package graph
+
+import (
+	"github.com/yourusername/iot-platform/services/api-gateway/auth"
+	"github.com/yourusername/iot-platform/services/api-gateway/graph/generated"
+)
+
+// NewConfig returns the configuration of the executable schema: the resolvers
+// and the authorization directives (@auth, @hasRole, @hasPermission).
+func NewConfig(resolver *Resolver) generated.Config {
+	return generated.Config{
+		Resolvers: resolver,
+		Directives: generated.DirectiveRoot{
+			Auth:          auth.AuthDirective,
+			HasRole:       auth.HasRoleDirective,
+			HasPermission: auth.HasPermissionDirective,
+		},
+	}
+}
@@ -0,0 +1,154 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/yourusername/iot-platform/services/api-gateway/auth"
+	"github.com/yourusername/iot-platform/services/api-gateway/graph/generated"
+	telemetrypb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+	userpb "github.com/yourusername/iot-platform/shared/proto/user"
+	"google.golang.org/grpc"
+)
+
+// executeAs runs query against the generated schema with the directives and
+// the auth extension, as the user of claims (anonymous if nil). It returns the
+// messages of the errors.
+func executeAs(t *testing.T, resolver *Resolver, claims *auth.Claims, query string) []string {
+	t.Helper()
+
+	srv := handler.New(generated.NewExecutableSchema(NewConfig(resolver)))
+	srv.AddTransport(transport.POST{})
+	srv.Use(&auth.AuthExtension{})
+
+	body, _ := json.Marshal(map[string]string{"query": query})
+	req := httptest.NewRequest("POST", "/query", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	if claims != nil {
+		req = req.WithContext(auth.WithUser(req.Context(), claims))
+	}
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	var resp struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response %s: %v", rec.Body.String(), err)
+	}
+	messages := make([]string, len(resp.Errors))
+	for i, e := range resp.Errors {
+		messages[i] = e.Message
+	}
+	return messages
+}
+
+func containsError(messages []string, substr string) bool {
+	for _, m := range messages {
+		if strings.Contains(m, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// TestSchema_PublicFields tests that every root field declares its
+// authorization, except the public ones.
+func TestSchema_PublicFields(t *testing.T) {
+	schema := generated.NewExecutableSchema(NewConfig(&Resolver{})).Schema()
+
+	want := map[string]bool{
+		"query.__schema":        true,
+		"query.__type":          true,
+		"mutation.login":        true,
+		"mutation.refreshToken": true,
+	}
+	if got := auth.PublicFields(schema); !reflect.DeepEqual(got, want) {
+		t.Errorf("public fields = %v, want %v", got, want)
+	}
+}
+
+// TestSchema_Directives tests the authorization directives through the
+// generated schema: a denied field never reaches its resolver.
+func TestSchema_Directives(t *testing.T) {
+	admin := &auth.Claims{UserID: "admin-1", Role: "admin"}
+	user := &auth.Claims{UserID: "user-1", Role: "user"}
+	device := &auth.Claims{UserID: "dev-1", Role: "device"}
+	viewer := &auth.Claims{UserID: "user-2", Role: "viewer", Permissions: []string{auth.PermDevicesRead}}
+
+	tests := []struct {
+		name      string
+		claims    *auth.Claims
+		query     string
+		wantError string // empty: the resolver is reached
+	}{
+		{"users_as_user", user, `{ users { total } }`, "insufficient permissions: users:admin required"},
+		{"register_as_user", user, `mutation { register(input: {email: "a@example.com", password: "secret", name: "A"}) { token } }`, "users:admin required"},
+		{"revoke_sessions_as_user", user, `mutation { revokeUserSessions(userId: "user-2") }`, "users:admin required"},
+		{"revoke_sessions_as_admin", admin, `mutation { revokeUserSessions(userId: "user-2") }`, ""},
+		{"upsert_role_as_user", user, `mutation { upsertRole(input: {name: "viewer", permissions: []}) { name } }`, "roles:admin required"},
+		{"upsert_role_as_admin", admin, `mutation { upsertRole(input: {name: "viewer", permissions: []}) { name } }`, ""},
+		{"devices_as_device", device, `{ devices { total } }`, "devices:read required"},
+		{"devices_as_viewer", viewer, `{ devices { total } }`, ""},
+		{"delete_device_as_viewer", viewer, `mutation { deleteDevice(id: "dev-1") { success } }`, "devices:write required"},
+		{"delete_device_as_user", user, `mutation { deleteDevice(id: "dev-1") { success } }`, ""},
+		{"delete_device_type_as_user", user, `mutation { deleteDeviceType(name: "thermometer") { success } }`, "device-types:write required"},
+		{"derived_metrics_as_device", device, `{ derivedMetrics { id } }`, "telemetry:read required"},
+		{"retention_as_user", user, `{ retentionPolicies { id } }`, "retention:admin required"},
+		{"retention_as_admin", admin, `{ retentionPolicies { id } }`, ""},
+		{"subscription_stats_as_user", user, `{ subscriptionStats { total } }`, "system:read required"},
+		{"me_anonymous", nil, `{ me { id } }`, "authentication required"},
+		// A public field does not make the rest of the operation public
+		{"login_with_private_field", nil, `mutation { login(input: {email: "a@example.com", password: "secret"}) { token } deleteDevice(id: "dev-1") { success } }`, "authentication required"},
+		{"refresh_token_anonymous", nil, `mutation { refreshToken(refreshToken: "refresh-1") { token } }`, ""},
+		{"introspection_anonymous", nil, `{ __schema { queryType { name } } }`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reached := false
+			resolver := &Resolver{
+				DeviceClient: &MockDeviceServiceClient{},
+				UserClient: &MockUserServiceClient{
+					RevokeUserSessionsFunc: func(ctx context.Context, req *userpb.RevokeUserSessionsRequest, opts ...grpc.CallOption) (*userpb.RevokeUserSessionsResponse, error) {
+						reached = true
+						return &userpb.RevokeUserSessionsResponse{Revoked: 1}, nil
+					},
+					UpsertRoleFunc: func(ctx context.Context, req *userpb.UpsertRoleRequest, opts ...grpc.CallOption) (*userpb.UpsertRoleResponse, error) {
+						reached = true
+						return &userpb.UpsertRoleResponse{Role: &userpb.Role{Name: req.Name}}, nil
+					},
+				},
+				TelemetryClient: &MockTelemetryServiceClient{
+					ListRetentionPoliciesFunc: func(ctx context.Context, req *telemetrypb.ListRetentionPoliciesRequest, opts ...grpc.CallOption) (*telemetrypb.ListRetentionPoliciesResponse, error) {
+						reached = true
+						return &telemetrypb.ListRetentionPoliciesResponse{}, nil
+					},
+				},
+			}
+
+			messages := executeAs(t, resolver, tt.claims, tt.query)
+
+			if tt.wantError != "" {
+				if !containsError(messages, tt.wantError) {
+					t.Errorf("expected error %q, got %v", tt.wantError, messages)
+				}
+				if reached {
+					t.Error("resolver should not be reached")
+				}
+				return
+			}
+			if containsError(messages, "insufficient permissions") || containsError(messages, "authentication required") {
+				t.Errorf("expected access, got %v", messages)
+			}
+		})
+	}
+}
@@ -0,0 +1,351 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/auth"
+	"github.com/yourusername/iot-platform/services/api-gateway/graph/model"
+	devicepb "github.com/yourusername/iot-platform/shared/proto/device"
+)
+
+// locationKinds maps GraphQL location kinds to their protobuf values.
+var locationKinds = map[model.LocationKind]devicepb.LocationKind{
+	model.LocationKindSite:     devicepb.LocationKind_LOCATION_SITE,
+	model.LocationKindBuilding: devicepb.LocationKind_LOCATION_BUILDING,
+	model.LocationKindFloor:    devicepb.LocationKind_LOCATION_FLOOR,
+	model.LocationKindRoom:     devicepb.LocationKind_LOCATION_ROOM,
+}
+
+// deviceGroupKinds maps GraphQL group kinds to their protobuf values.
+var deviceGroupKinds = map[model.DeviceGroupKind]devicepb.DeviceGroupKind{
+	model.DeviceGroupKindStatic:  devicepb.DeviceGroupKind_GROUP_STATIC,
+	model.DeviceGroupKindDynamic: devicepb.DeviceGroupKind_GROUP_DYNAMIC,
+}
+
+func protoToGraphQLLocation(l *devicepb.Location) *model.Location {
+	location := &model.Location{
+		ID:        l.Id,
+		Name:      l.Name,
+		Kind:      model.LocationKindSite,
+		ParentID:  stringValuePtr(l.ParentId),
+		CreatedAt: int(l.CreatedAt),
+	}
+	for kind, pbKind := range locationKinds {
+		if pbKind == l.Kind {
+			location.Kind = kind
+		}
+	}
+	return location
+}
+
+func protoToGraphQLDeviceGroup(g *devicepb.DeviceGroup) *model.DeviceGroup {
+	group := &model.DeviceGroup{
+		ID:          g.Id,
+		Name:        g.Name,
+		Description: stringValuePtr(g.Description),
+		Kind:        model.DeviceGroupKindStatic,
+		DeviceCount: int(g.DeviceCount),
+		CreatedAt:   int(g.CreatedAt),
+		UpdatedAt:   int(g.UpdatedAt),
+	}
+	if g.Kind == devicepb.DeviceGroupKind_GROUP_DYNAMIC {
+		group.Kind = model.DeviceGroupKindDynamic
+	}
+
+	if g.Rule != nil {
+		keys := make([]string, 0, len(g.Rule.Metadata))
+		for k := range g.Rule.Metadata {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		group.Rule = &model.DeviceGroupRule{
+			DeviceType: stringValuePtr(g.Rule.Type),
+			Metadata:   make([]*model.MetadataEntry, len(keys)),
+		}
+		for i, k := range keys {
+			group.Rule.Metadata[i] = &model.MetadataEntry{Key: k, Value: g.Rule.Metadata[k]}
+		}
+	}
+	return group
+}
+
+func graphQLToProtoGroupRule(rule *model.DeviceGroupRuleInput) *devicepb.DeviceGroupRule {
+	if rule == nil {
+		return nil
+	}
+
+	pbRule := &devicepb.DeviceGroupRule{
+		Type:     stringPtrToValue(rule.DeviceType),
+		Metadata: make(map[string]string, len(rule.Metadata)),
+	}
+	for _, kv := range rule.Metadata {
+		pbRule.Metadata[kv.Key] = kv.Value
+	}
+	return pbRule
+}
+
+// DeviceGroupsImpl lists the device groups of the organization of the caller,
+// or those containing deviceID.
+func (r *queryResolver) DeviceGroupsImpl(ctx context.Context, deviceID *string) ([]*model.DeviceGroup, error) {
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.DeviceClient.ListDeviceGroups(ctx, &devicepb.ListDeviceGroupsRequest{
+		OrgId:    orgID,
+		DeviceId: stringPtrToValue(deviceID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list device groups: %w", err)
+	}
+
+	groups := make([]*model.DeviceGroup, len(resp.Groups))
+	for i, g := range resp.Groups {
+		groups[i] = protoToGraphQLDeviceGroup(g)
+	}
+	return groups, nil
+}
+
+// DeviceGroupImpl retrieves a device group, or nil if it does not exist in
+// the organization of the caller.
+func (r *queryResolver) DeviceGroupImpl(ctx context.Context, id string) (*model.DeviceGroup, error) {
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.DeviceClient.GetDeviceGroup(ctx, &devicepb.GetDeviceGroupRequest{Id: id, OrgId: orgID})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get device group: %w", err)
+	}
+
+	return protoToGraphQLDeviceGroup(resp.Group), nil
+}
+
+// LocationsImpl lists the locations of the organization of the caller, or the
+// direct children of parentID.
+func (r *queryResolver) LocationsImpl(ctx context.Context, parentID *string) ([]*model.Location, error) {
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.DeviceClient.ListLocations(ctx, &devicepb.ListLocationsRequest{
+		OrgId:    orgID,
+		ParentId: stringPtrToValue(parentID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list locations: %w", err)
+	}
+
+	locations := make([]*model.Location, len(resp.Locations))
+	for i, l := range resp.Locations {
+		locations[i] = protoToGraphQLLocation(l)
+	}
+	return locations, nil
+}
+
+// LocationImpl retrieves a location, or nil if it does not exist in the
+// organization of the caller.
+func (r *queryResolver) LocationImpl(ctx context.Context, id string) (*model.Location, error) {
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.DeviceClient.GetLocation(ctx, &devicepb.GetLocationRequest{Id: id, OrgId: orgID})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get location: %w", err)
+	}
+
+	return protoToGraphQLLocation(resp.Location), nil
+}
+
+// authorizeGroupChange checks that the user of ctx may change device groups.
+// Groups are ACL targets, so users restricted by ACL entries may not change
+// them and widen their own access.
+func (r *mutationResolver) authorizeGroupChange(ctx context.Context) error {
+	return r.authorizeAllDevices(ctx, auth.PermDevicesWrite)
+}
+
+// CreateDeviceGroupImpl creates a device group in the organization of the caller.
+func (r *mutationResolver) CreateDeviceGroupImpl(ctx context.Context, input model.CreateDeviceGroupInput) (*model.DeviceGroup, error) {
+	if err := r.authorizeGroupChange(ctx); err != nil {
+		return nil, err
+	}
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.DeviceClient.CreateDeviceGroup(ctx, &devicepb.CreateDeviceGroupRequest{
+		OrgId:       orgID,
+		Name:        input.Name,
+		Description: stringPtrToValue(input.Description),
+		Kind:        deviceGroupKinds[input.Kind],
+		Rule:        graphQLToProtoGroupRule(input.Rule),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create device group: %w", err)
+	}
+
+	log.Printf("✅ Device group created: %s (%d devices)", resp.Group.Name, resp.Group.DeviceCount)
+	return protoToGraphQLDeviceGroup(resp.Group), nil
+}
+
+// UpdateDeviceGroupImpl changes the name, description or rule of a group.
+func (r *mutationResolver) UpdateDeviceGroupImpl(ctx context.Context, input model.UpdateDeviceGroupInput) (*model.DeviceGroup, error) {
+	if err := r.authorizeGroupChange(ctx); err != nil {
+		return nil, err
+	}
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.DeviceClient.UpdateDeviceGroup(ctx, &devicepb.UpdateDeviceGroupRequest{
+		Id:          input.ID,
+		Name:        stringPtrToValue(input.Name),
+		Description: input.Description,
+		Rule:        graphQLToProtoGroupRule(input.Rule),
+		OrgId:       orgID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update device group: %w", err)
+	}
+
+	return protoToGraphQLDeviceGroup(resp.Group), nil
+}
+
+// DeleteDeviceGroupImpl deletes a device group, keeping its devices.
+func (r *mutationResolver) DeleteDeviceGroupImpl(ctx context.Context, id string) (*model.DeleteResult, error) {
+	if err := r.authorizeGroupChange(ctx); err != nil {
+		return nil, err
+	}
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.DeviceClient.DeleteDeviceGroup(ctx, &devicepb.DeleteDeviceGroupRequest{Id: id, OrgId: orgID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete device group: %w", err)
+	}
+
+	return &model.DeleteResult{
+		Success: resp.Success,
+		Message: resp.Message,
+	}, nil
+}
+
+// AddDevicesToGroupImpl adds devices to a static group.
+func (r *mutationResolver) AddDevicesToGroupImpl(ctx context.Context, groupID string, deviceIds []string) (*model.DeviceGroup, error) {
+	if err := r.authorizeGroupChange(ctx); err != nil {
+		return nil, err
+	}
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.DeviceClient.AddGroupDevices(ctx, &devicepb.GroupDevicesRequest{
+		GroupId:   groupID,
+		DeviceIds: deviceIds,
+		OrgId:     orgID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add devices to group: %w", err)
+	}
+
+	return protoToGraphQLDeviceGroup(resp.Group), nil
+}
+
+// RemoveDevicesFromGroupImpl removes devices from a static group.
+func (r *mutationResolver) RemoveDevicesFromGroupImpl(ctx context.Context, groupID string, deviceIds []string) (*model.DeviceGroup, error) {
+	if err := r.authorizeGroupChange(ctx); err != nil {
+		return nil, err
+	}
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.DeviceClient.RemoveGroupDevices(ctx, &devicepb.GroupDevicesRequest{
+		GroupId:   groupID,
+		DeviceIds: deviceIds,
+		OrgId:     orgID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove devices from group: %w", err)
+	}
+
+	return protoToGraphQLDeviceGroup(resp.Group), nil
+}
+
+// CreateLocationImpl creates a location in the organization of the caller.
+func (r *mutationResolver) CreateLocationImpl(ctx context.Context, input model.CreateLocationInput) (*model.Location, error) {
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.DeviceClient.CreateLocation(ctx, &devicepb.CreateLocationRequest{
+		OrgId:    orgID,
+		ParentId: stringPtrToValue(input.ParentID),
+		Kind:     locationKinds[input.Kind],
+		Name:     input.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create location: %w", err)
+	}
+
+	log.Printf("✅ Location created: %s", resp.Location.Name)
+	return protoToGraphQLLocation(resp.Location), nil
+}
+
+// UpdateLocationImpl renames a location.
+func (r *mutationResolver) UpdateLocationImpl(ctx context.Context, id string, name string) (*model.Location, error) {
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.DeviceClient.UpdateLocation(ctx, &devicepb.UpdateLocationRequest{Id: id, Name: name, OrgId: orgID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update location: %w", err)
+	}
+
+	return protoToGraphQLLocation(resp.Location), nil
+}
+
+// DeleteLocationImpl deletes a location without sub-locations.
+func (r *mutationResolver) DeleteLocationImpl(ctx context.Context, id string) (*model.DeleteResult, error) {
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.DeviceClient.DeleteLocation(ctx, &devicepb.DeleteLocationRequest{Id: id, OrgId: orgID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete location: %w", err)
+	}
+
+	return &model.DeleteResult{
+		Success: resp.Success,
+		Message: resp.Message,
+	}, nil
+}
@@ -0,0 +1,157 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/auth"
+	"github.com/yourusername/iot-platform/services/api-gateway/graph/model"
+	pb "github.com/yourusername/iot-platform/shared/proto/device"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (m *MockDeviceServiceClient) CreateDeviceGroup(ctx context.Context, req *pb.CreateDeviceGroupRequest, opts ...grpc.CallOption) (*pb.CreateDeviceGroupResponse, error) {
+	if m.CreateDeviceGroupFunc != nil {
+		return m.CreateDeviceGroupFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("CreateDeviceGroupFunc not implemented")
+}
+
+func (m *MockDeviceServiceClient) GetDeviceGroup(ctx context.Context, req *pb.GetDeviceGroupRequest, opts ...grpc.CallOption) (*pb.GetDeviceGroupResponse, error) {
+	if m.GetDeviceGroupFunc != nil {
+		return m.GetDeviceGroupFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("GetDeviceGroupFunc not implemented")
+}
+
+func (m *MockDeviceServiceClient) AddGroupDevices(ctx context.Context, req *pb.GroupDevicesRequest, opts ...grpc.CallOption) (*pb.GroupDevicesResponse, error) {
+	if m.AddGroupDevicesFunc != nil {
+		return m.AddGroupDevicesFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("AddGroupDevicesFunc not implemented")
+}
+
+func (m *MockDeviceServiceClient) CreateLocation(ctx context.Context, req *pb.CreateLocationRequest, opts ...grpc.CallOption) (*pb.CreateLocationResponse, error) {
+	if m.CreateLocationFunc != nil {
+		return m.CreateLocationFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("CreateLocationFunc not implemented")
+}
+
+// orgContext returns a context authenticated as a user of organization orgID.
+func orgContext(orgID string) context.Context {
+	return auth.WithUser(context.Background(), &auth.Claims{UserID: "user-1", Role: "user", OrgID: orgID})
+}
+
+// TestCreateDeviceGroupImpl tests that dynamic groups are created with their
+// rule in the organization of the caller.
+func TestCreateDeviceGroupImpl(t *testing.T) {
+	var saved *pb.CreateDeviceGroupRequest
+	mock := &MockDeviceServiceClient{
+		CreateDeviceGroupFunc: func(ctx context.Context, req *pb.CreateDeviceGroupRequest, opts ...grpc.CallOption) (*pb.CreateDeviceGroupResponse, error) {
+			saved = req
+			return &pb.CreateDeviceGroupResponse{Group: &pb.DeviceGroup{
+				Id:          "group-1",
+				OrgId:       req.OrgId,
+				Name:        req.Name,
+				Kind:        req.Kind,
+				Rule:        req.Rule,
+				DeviceCount: 4,
+			}}, nil
+		},
+	}
+	r := &mutationResolver{newTestResolver(mock)}
+
+	group, err := r.CreateDeviceGroupImpl(orgContext("org-1"), model.CreateDeviceGroupInput{
+		Name: "Nord",
+		Kind: model.DeviceGroupKindDynamic,
+		Rule: &model.DeviceGroupRuleInput{
+			DeviceType: stringPtr("temperature"),
+			Metadata:   []*model.MetadataEntryInput{{Key: "zone", Value: "nord"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateDeviceGroupImpl failed: %v", err)
+	}
+	if saved.OrgId != "org-1" || saved.Kind != pb.DeviceGroupKind_GROUP_DYNAMIC || saved.Rule.Type != "temperature" || saved.Rule.Metadata["zone"] != "nord" {
+		t.Errorf("unexpected request %+v", saved)
+	}
+	if group.Kind != model.DeviceGroupKindDynamic || group.DeviceCount != 4 || group.Description != nil {
+		t.Errorf("unexpected group %+v", group)
+	}
+	if group.Rule == nil || *group.Rule.DeviceType != "temperature" || len(group.Rule.Metadata) != 1 {
+		t.Errorf("unexpected rule %+v", group.Rule)
+	}
+}
+
+// TestDeviceGroupImpl tests that groups of other organizations are not found.
+func TestDeviceGroupImpl(t *testing.T) {
+	mock := &MockDeviceServiceClient{
+		GetDeviceGroupFunc: func(ctx context.Context, req *pb.GetDeviceGroupRequest, opts ...grpc.CallOption) (*pb.GetDeviceGroupResponse, error) {
+			if req.OrgId != "org-1" {
+				return nil, status.Error(codes.NotFound, "device group not found")
+			}
+			return &pb.GetDeviceGroupResponse{Group: &pb.DeviceGroup{Id: req.Id, Name: "Pilote"}}, nil
+		},
+	}
+	r := &queryResolver{newTestResolver(mock)}
+
+	group, err := r.DeviceGroupImpl(orgContext("org-1"), "group-1")
+	if err != nil || group == nil || group.Kind != model.DeviceGroupKindStatic || group.Rule != nil {
+		t.Errorf("DeviceGroupImpl = %+v, %v; want static group-1", group, err)
+	}
+	group, err = r.DeviceGroupImpl(orgContext("org-2"), "group-1")
+	if err != nil || group != nil {
+		t.Errorf("DeviceGroupImpl from another organization = %+v, %v; want nil", group, err)
+	}
+}
+
+// TestAddDevicesToGroupImpl tests that membership changes are scoped to the
+// organization of the caller.
+func TestAddDevicesToGroupImpl(t *testing.T) {
+	mock := &MockDeviceServiceClient{
+		AddGroupDevicesFunc: func(ctx context.Context, req *pb.GroupDevicesRequest, opts ...grpc.CallOption) (*pb.GroupDevicesResponse, error) {
+			if req.OrgId != "org-1" || req.GroupId != "group-1" || len(req.DeviceIds) != 2 {
+				t.Errorf("unexpected request %+v", req)
+			}
+			return &pb.GroupDevicesResponse{Group: &pb.DeviceGroup{Id: req.GroupId, DeviceCount: 2}}, nil
+		},
+	}
+	r := &mutationResolver{newTestResolver(mock)}
+
+	group, err := r.AddDevicesToGroupImpl(orgContext("org-1"), "group-1", []string{"dev-1", "dev-2"})
+	if err != nil {
+		t.Fatalf("AddDevicesToGroupImpl failed: %v", err)
+	}
+	if group.DeviceCount != 2 {
+		t.Errorf("expected 2 devices, got %d", group.DeviceCount)
+	}
+}
+
+// TestCreateLocationImpl tests the conversion of location kinds.
+func TestCreateLocationImpl(t *testing.T) {
+	mock := &MockDeviceServiceClient{
+		CreateLocationFunc: func(ctx context.Context, req *pb.CreateLocationRequest, opts ...grpc.CallOption) (*pb.CreateLocationResponse, error) {
+			if req.Kind != pb.LocationKind_LOCATION_FLOOR || req.ParentId != "building-1" || req.OrgId != "org-1" {
+				t.Errorf("unexpected request %+v", req)
+			}
+			return &pb.CreateLocationResponse{Location: &pb.Location{Id: "floor-1", Name: req.Name, Kind: req.Kind, ParentId: req.ParentId}}, nil
+		},
+	}
+	r := &mutationResolver{newTestResolver(mock)}
+
+	location, err := r.CreateLocationImpl(orgContext("org-1"), model.CreateLocationInput{
+		Name:     "Étage 1",
+		Kind:     model.LocationKindFloor,
+		ParentID: stringPtr("building-1"),
+	})
+	if err != nil {
+		t.Fatalf("CreateLocationImpl failed: %v", err)
+	}
+	if location.Kind != model.LocationKindFloor || location.ParentID == nil || *location.ParentID != "building-1" {
+		t.Errorf("unexpected location %+v", location)
+	}
+}
@@ -9,15 +9,88 @@ import (
 	"strconv"
 )
 
+type AccessEntry struct {
+	ID        string       `json:"id"`
+	UserID    *string      `json:"userId,omitempty"`
+	Role      *string      `json:"role,omitempty"`
+	DeviceID  *string      `json:"deviceId,omitempty"`
+	GroupID   *string      `json:"groupId,omitempty"`
+	Access    DeviceAccess `json:"access"`
+	CreatedAt int          `json:"createdAt"`
+}
+
+type Anomaly struct {
+	DeviceID   string  `json:"deviceId"`
+	MetricName string  `json:"metricName"`
+	Time       int     `json:"time"`
+	Value      float64 `json:"value"`
+	Expected   float64 `json:"expected"`
+	Score      float64 `json:"score"`
+	Method     string  `json:"method"`
+}
+
+type APIKey struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Prefix      string   `json:"prefix"`
+	UserID      string   `json:"userId"`
+	Permissions []string `json:"permissions"`
+	CreatedAt   int      `json:"createdAt"`
+	ExpiresAt   *int     `json:"expiresAt,omitempty"`
+	LastUsedAt  *int     `json:"lastUsedAt,omitempty"`
+}
+
+type APIKeyCreated struct {
+	APIKey *APIKey `json:"apiKey"`
+	Key    string  `json:"key"`
+}
+
 type AuthPayload struct {
-	Token string `json:"token"`
-	User  *User  `json:"user"`
+	Token            string  `json:"token"`
+	ExpiresAt        int     `json:"expiresAt"`
+	RefreshToken     *string `json:"refreshToken,omitempty"`
+	User             *User   `json:"user"`
+	OrganizationID   string  `json:"organizationId"`
+	OrganizationRole *string `json:"organizationRole,omitempty"`
+}
+
+type CreateAPIKeyInput struct {
+	Name        string   `json:"name"`
+	UserID      *string  `json:"userId,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	ExpiresAt   *int     `json:"expiresAt,omitempty"`
+}
+
+type CreateDeviceGroupInput struct {
+	Name        string                `json:"name"`
+	Description *string               `json:"description,omitempty"`
+	Kind        DeviceGroupKind       `json:"kind"`
+	Rule        *DeviceGroupRuleInput `json:"rule,omitempty"`
 }
 
 type CreateDeviceInput struct {
-	Name     string                `json:"name"`
-	Type     string                `json:"type"`
-	Metadata []*MetadataEntryInput `json:"metadata,omitempty"`
+	Name       string                `json:"name"`
+	Type       string                `json:"type"`
+	Metadata   []*MetadataEntryInput `json:"metadata,omitempty"`
+	LocationID *string               `json:"locationId,omitempty"`
+}
+
+type CreateLocationInput struct {
+	Name     string       `json:"name"`
+	Kind     LocationKind `json:"kind"`
+	ParentID *string      `json:"parentId,omitempty"`
+}
+
+type CreateOrganizationInput struct {
+	Name    string  `json:"name"`
+	Slug    string  `json:"slug"`
+	OwnerID *string `json:"ownerId,omitempty"`
+}
+
+type CreateServiceAccountInput struct {
+	Name  string  `json:"name"`
+	Email *string `json:"email,omitempty"`
+	Role  string  `json:"role"`
 }
 
 type DeleteResult struct {
@@ -25,14 +98,39 @@ type DeleteResult struct {
 	Message string `json:"message"`
 }
 
+type DerivedMetric struct {
+	ID           string            `json:"id"`
+	DeviceType   string            `json:"deviceType"`
+	Name         string            `json:"name"`
+	Kind         DerivedMetricKind `json:"kind"`
+	Expression   *string           `json:"expression,omitempty"`
+	SourceMetric *string           `json:"sourceMetric,omitempty"`
+	Window       *string           `json:"window,omitempty"`
+	Unit         *string           `json:"unit,omitempty"`
+	CreatedAt    int               `json:"createdAt"`
+	UpdatedAt    int               `json:"updatedAt"`
+}
+
+type DerivedMetricInput struct {
+	DeviceType   string            `json:"deviceType"`
+	Name         string            `json:"name"`
+	Kind         DerivedMetricKind `json:"kind"`
+	Expression   *string           `json:"expression,omitempty"`
+	SourceMetric *string           `json:"sourceMetric,omitempty"`
+	Window       *string           `json:"window,omitempty"`
+	Unit         *string           `json:"unit,omitempty"`
+}
+
 type Device struct {
-	ID        string           `json:"id"`
-	Name      string           `json:"name"`
-	Type      string           `json:"type"`
-	Status    DeviceStatus     `json:"status"`
-	CreatedAt int              `json:"createdAt"`
-	LastSeen  int              `json:"lastSeen"`
-	Metadata  []*MetadataEntry `json:"metadata"`
+	ID             string           `json:"id"`
+	Name           string           `json:"name"`
+	Type           string           `json:"type"`
+	Status         DeviceStatus     `json:"status"`
+	CreatedAt      int              `json:"createdAt"`
+	LastSeen       int              `json:"lastSeen"`
+	Metadata       []*MetadataEntry `json:"metadata"`
+	OrganizationID string           `json:"organizationId"`
+	LocationID     *string          `json:"locationId,omitempty"`
 }
 
 type DeviceConnection struct {
@@ -42,9 +140,78 @@ type DeviceConnection struct {
 	PageSize int       `json:"pageSize"`
 }
 
+type DeviceGroup struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Description *string          `json:"description,omitempty"`
+	Kind        DeviceGroupKind  `json:"kind"`
+	Rule        *DeviceGroupRule `json:"rule,omitempty"`
+	DeviceCount int              `json:"deviceCount"`
+	CreatedAt   int              `json:"createdAt"`
+	UpdatedAt   int              `json:"updatedAt"`
+}
+
+type DeviceGroupRule struct {
+	DeviceType *string          `json:"deviceType,omitempty"`
+	Metadata   []*MetadataEntry `json:"metadata"`
+}
+
+type DeviceGroupRuleInput struct {
+	DeviceType *string               `json:"deviceType,omitempty"`
+	Metadata   []*MetadataEntryInput `json:"metadata,omitempty"`
+}
+
+type DeviceSubscriptionCount struct {
+	DeviceID      string `json:"deviceId"`
+	Subscriptions int    `json:"subscriptions"`
+	Replicas      int    `json:"replicas"`
+}
+
+type DeviceType struct {
+	Name        string              `json:"name"`
+	DisplayName string              `json:"displayName"`
+	Description *string             `json:"description,omitempty"`
+	Metrics     []*MetricDefinition `json:"metrics"`
+	CreatedAt   int                 `json:"createdAt"`
+	UpdatedAt   int                 `json:"updatedAt"`
+}
+
+type DeviceTypeInput struct {
+	Name        string                   `json:"name"`
+	DisplayName *string                  `json:"displayName,omitempty"`
+	Description *string                  `json:"description,omitempty"`
+	Metrics     []*MetricDefinitionInput `json:"metrics"`
+}
+
+type GrantDeviceAccessInput struct {
+	UserID   *string      `json:"userId,omitempty"`
+	Role     *string      `json:"role,omitempty"`
+	DeviceID *string      `json:"deviceId,omitempty"`
+	GroupID  *string      `json:"groupId,omitempty"`
+	Access   DeviceAccess `json:"access"`
+}
+
+type Location struct {
+	ID        string       `json:"id"`
+	Name      string       `json:"name"`
+	Kind      LocationKind `json:"kind"`
+	ParentID  *string      `json:"parentId,omitempty"`
+	CreatedAt int          `json:"createdAt"`
+}
+
 type LoginInput struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email          string  `json:"email"`
+	Password       string  `json:"password"`
+	DeviceName     *string `json:"deviceName,omitempty"`
+	OrganizationID *string `json:"organizationId,omitempty"`
+}
+
+type Membership struct {
+	OrganizationID string   `json:"organizationId"`
+	UserID         string   `json:"userId"`
+	Role           string   `json:"role"`
+	Permissions    []string `json:"permissions"`
+	CreatedAt      int      `json:"createdAt"`
 }
 
 type MetadataEntry struct {
@@ -57,9 +224,55 @@ type MetadataEntryInput struct {
 	Value string `json:"value"`
 }
 
+type MetricDefinition struct {
+	Name              string         `json:"name"`
+	DisplayName       string         `json:"displayName"`
+	Unit              *string        `json:"unit,omitempty"`
+	DataType          MetricDataType `json:"dataType"`
+	MinValue          *float64       `json:"minValue,omitempty"`
+	MaxValue          *float64       `json:"maxValue,omitempty"`
+	ReportingInterval *int           `json:"reportingInterval,omitempty"`
+}
+
+type MetricDefinitionInput struct {
+	Name              string          `json:"name"`
+	DisplayName       *string         `json:"displayName,omitempty"`
+	Unit              *string         `json:"unit,omitempty"`
+	DataType          *MetricDataType `json:"dataType,omitempty"`
+	MinValue          *float64        `json:"minValue,omitempty"`
+	MaxValue          *float64        `json:"maxValue,omitempty"`
+	ReportingInterval *int            `json:"reportingInterval,omitempty"`
+}
+
+type MetricInfo struct {
+	Name              string         `json:"name"`
+	DisplayName       string         `json:"displayName"`
+	Unit              *string        `json:"unit,omitempty"`
+	DataType          MetricDataType `json:"dataType"`
+	MinValue          *float64       `json:"minValue,omitempty"`
+	MaxValue          *float64       `json:"maxValue,omitempty"`
+	ReportingInterval *int           `json:"reportingInterval,omitempty"`
+	Declared          bool           `json:"declared"`
+	LastTime          *int           `json:"lastTime,omitempty"`
+}
+
 type Mutation struct {
 }
 
+type Organization struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Slug      string  `json:"slug"`
+	CreatedAt int     `json:"createdAt"`
+	Role      *string `json:"role,omitempty"`
+}
+
+type Permission struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Scope       PermissionScope `json:"scope"`
+}
+
 type Query struct {
 }
 
@@ -70,6 +283,67 @@ type RegisterInput struct {
 	Role     *string `json:"role,omitempty"`
 }
 
+type ReplicaSubscriptionStats struct {
+	Replica       string `json:"replica"`
+	Subscriptions int    `json:"subscriptions"`
+	Wildcards     int    `json:"wildcards"`
+	Devices       int    `json:"devices"`
+	UpdatedAt     int    `json:"updatedAt"`
+}
+
+type RetentionPolicy struct {
+	ID                  string  `json:"id"`
+	DeviceType          *string `json:"deviceType,omitempty"`
+	MetricName          *string `json:"metricName,omitempty"`
+	RawRetention        string  `json:"rawRetention"`
+	DownsampleInterval  *string `json:"downsampleInterval,omitempty"`
+	DownsampleRetention *string `json:"downsampleRetention,omitempty"`
+	CreatedAt           int     `json:"createdAt"`
+	UpdatedAt           int     `json:"updatedAt"`
+}
+
+type RetentionPolicyInput struct {
+	DeviceType          *string `json:"deviceType,omitempty"`
+	MetricName          *string `json:"metricName,omitempty"`
+	RawRetention        string  `json:"rawRetention"`
+	DownsampleInterval  *string `json:"downsampleInterval,omitempty"`
+	DownsampleRetention *string `json:"downsampleRetention,omitempty"`
+}
+
+type RetentionPolicyResult struct {
+	Policy            *RetentionPolicy `json:"policy"`
+	RawRowsDeleted    int              `json:"rawRowsDeleted"`
+	BucketsWritten    int              `json:"bucketsWritten"`
+	RollupRowsDeleted int              `json:"rollupRowsDeleted"`
+}
+
+type Role struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	BuiltIn     bool     `json:"builtIn"`
+	CreatedAt   int      `json:"createdAt"`
+	UpdatedAt   int      `json:"updatedAt"`
+}
+
+type RoleInput struct {
+	Name        string   `json:"name"`
+	Description *string  `json:"description,omitempty"`
+	Permissions []string `json:"permissions"`
+}
+
+type Session struct {
+	ID             string `json:"id"`
+	Device         string `json:"device"`
+	IPAddress      string `json:"ipAddress"`
+	UserAgent      string `json:"userAgent"`
+	CreatedAt      int    `json:"createdAt"`
+	LastUsedAt     int    `json:"lastUsedAt"`
+	ExpiresAt      int    `json:"expiresAt"`
+	Current        bool   `json:"current"`
+	OrganizationID string `json:"organizationId"`
+}
+
 type Stats struct {
 	TotalDevices   int `json:"totalDevices"`
 	OnlineDevices  int `json:"onlineDevices"`
@@ -80,6 +354,12 @@ type Stats struct {
 type Subscription struct {
 }
 
+type SubscriptionStats struct {
+	Total    int                         `json:"total"`
+	Replicas []*ReplicaSubscriptionStats `json:"replicas"`
+	Devices  []*DeviceSubscriptionCount  `json:"devices"`
+}
+
 type TelemetryAggregation struct {
 	Bucket string  `json:"bucket"`
 	Avg    float64 `json:"avg"`
@@ -88,10 +368,47 @@ type TelemetryAggregation struct {
 	Count  int     `json:"count"`
 }
 
+type TelemetryBatchInput struct {
+	DeviceIds    []string              `json:"deviceIds,omitempty"`
+	DeviceType   *string               `json:"deviceType,omitempty"`
+	Metadata     []*MetadataEntryInput `json:"metadata,omitempty"`
+	GroupIds     []string              `json:"groupIds,omitempty"`
+	LocationID   *string               `json:"locationId,omitempty"`
+	MetricNames  []string              `json:"metricNames"`
+	From         int                   `json:"from"`
+	To           int                   `json:"to"`
+	Interval     string                `json:"interval"`
+	GroupBy      *TelemetryGroupBy     `json:"groupBy,omitempty"`
+	LocationKind *LocationKind         `json:"locationKind,omitempty"`
+	Unit         *string               `json:"unit,omitempty"`
+}
+
+type TelemetryBatchSeries struct {
+	DeviceID     *string                 `json:"deviceId,omitempty"`
+	DeviceType   *string                 `json:"deviceType,omitempty"`
+	GroupID      *string                 `json:"groupId,omitempty"`
+	LocationID   *string                 `json:"locationId,omitempty"`
+	MetricName   string                  `json:"metricName"`
+	Aggregations []*TelemetryAggregation `json:"aggregations"`
+}
+
+type TelemetryFilter struct {
+	DeviceIds     []string              `json:"deviceIds,omitempty"`
+	DeviceType    *string               `json:"deviceType,omitempty"`
+	Metadata      []*MetadataEntryInput `json:"metadata,omitempty"`
+	MetricNames   []string              `json:"metricNames,omitempty"`
+	Value         *ValuePredicate       `json:"value,omitempty"`
+	MinIntervalMs *int                  `json:"minIntervalMs,omitempty"`
+	Overflow      *OverflowPolicy       `json:"overflow,omitempty"`
+}
+
 type TelemetryPoint struct {
-	Time  int     `json:"time"`
-	Value float64 `json:"value"`
-	Unit  *string `json:"unit,omitempty"`
+	Time       int     `json:"time"`
+	Value      float64 `json:"value"`
+	Unit       *string `json:"unit,omitempty"`
+	EventID    *string `json:"eventId,omitempty"`
+	DeviceID   *string `json:"deviceId,omitempty"`
+	MetricName *string `json:"metricName,omitempty"`
 }
 
 type TelemetrySeries struct {
@@ -99,21 +416,32 @@ type TelemetrySeries struct {
 	Points     []*TelemetryPoint `json:"points"`
 }
 
+type UpdateDeviceGroupInput struct {
+	ID          string                `json:"id"`
+	Name        *string               `json:"name,omitempty"`
+	Description *string               `json:"description,omitempty"`
+	Rule        *DeviceGroupRuleInput `json:"rule,omitempty"`
+}
+
 type UpdateDeviceInput struct {
-	ID       string                `json:"id"`
-	Name     *string               `json:"name,omitempty"`
-	Status   *DeviceStatus         `json:"status,omitempty"`
-	Metadata []*MetadataEntryInput `json:"metadata,omitempty"`
+	ID         string                `json:"id"`
+	Name       *string               `json:"name,omitempty"`
+	Status     *DeviceStatus         `json:"status,omitempty"`
+	Metadata   []*MetadataEntryInput `json:"metadata,omitempty"`
+	LocationID *string               `json:"locationId,omitempty"`
 }
 
 type User struct {
-	ID        string `json:"id"`
-	Email     string `json:"email"`
-	Name      string `json:"name"`
-	Role      string `json:"role"`
-	CreatedAt int    `json:"createdAt"`
-	LastLogin *int   `json:"lastLogin,omitempty"`
-	IsActive  bool   `json:"isActive"`
+	ID             string   `json:"id"`
+	Email          string   `json:"email"`
+	Name           string   `json:"name"`
+	Role           string   `json:"role"`
+	CreatedAt      int      `json:"createdAt"`
+	LastLogin      *int     `json:"lastLogin,omitempty"`
+	IsActive       bool     `json:"isActive"`
+	Permissions    []string `json:"permissions"`
+	OrgRole        *string  `json:"orgRole,omitempty"`
+	ServiceAccount bool     `json:"serviceAccount"`
 }
 
 type UserConnection struct {
@@ -123,6 +451,182 @@ type UserConnection struct {
 	PageSize int     `json:"pageSize"`
 }
 
+type ValuePredicate struct {
+	Gt  *float64 `json:"gt,omitempty"`
+	Gte *float64 `json:"gte,omitempty"`
+	Lt  *float64 `json:"lt,omitempty"`
+	Lte *float64 `json:"lte,omitempty"`
+}
+
+type DerivedMetricKind string
+
+const (
+	DerivedMetricKindExpression    DerivedMetricKind = "EXPRESSION"
+	DerivedMetricKindIntegral      DerivedMetricKind = "INTEGRAL"
+	DerivedMetricKindMovingAverage DerivedMetricKind = "MOVING_AVERAGE"
+)
+
+var AllDerivedMetricKind = []DerivedMetricKind{
+	DerivedMetricKindExpression,
+	DerivedMetricKindIntegral,
+	DerivedMetricKindMovingAverage,
+}
+
+func (e DerivedMetricKind) IsValid() bool {
+	switch e {
+	case DerivedMetricKindExpression, DerivedMetricKindIntegral, DerivedMetricKindMovingAverage:
+		return true
+	}
+	return false
+}
+
+func (e DerivedMetricKind) String() string {
+	return string(e)
+}
+
+func (e *DerivedMetricKind) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DerivedMetricKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DerivedMetricKind", str)
+	}
+	return nil
+}
+
+func (e DerivedMetricKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *DerivedMetricKind) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e DerivedMetricKind) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type DeviceAccess string
+
+const (
+	DeviceAccessRead    DeviceAccess = "READ"
+	DeviceAccessWrite   DeviceAccess = "WRITE"
+	DeviceAccessCommand DeviceAccess = "COMMAND"
+)
+
+var AllDeviceAccess = []DeviceAccess{
+	DeviceAccessRead,
+	DeviceAccessWrite,
+	DeviceAccessCommand,
+}
+
+func (e DeviceAccess) IsValid() bool {
+	switch e {
+	case DeviceAccessRead, DeviceAccessWrite, DeviceAccessCommand:
+		return true
+	}
+	return false
+}
+
+func (e DeviceAccess) String() string {
+	return string(e)
+}
+
+func (e *DeviceAccess) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DeviceAccess(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DeviceAccess", str)
+	}
+	return nil
+}
+
+func (e DeviceAccess) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *DeviceAccess) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e DeviceAccess) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type DeviceGroupKind string
+
+const (
+	DeviceGroupKindStatic  DeviceGroupKind = "STATIC"
+	DeviceGroupKindDynamic DeviceGroupKind = "DYNAMIC"
+)
+
+var AllDeviceGroupKind = []DeviceGroupKind{
+	DeviceGroupKindStatic,
+	DeviceGroupKindDynamic,
+}
+
+func (e DeviceGroupKind) IsValid() bool {
+	switch e {
+	case DeviceGroupKindStatic, DeviceGroupKindDynamic:
+		return true
+	}
+	return false
+}
+
+func (e DeviceGroupKind) String() string {
+	return string(e)
+}
+
+func (e *DeviceGroupKind) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = DeviceGroupKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid DeviceGroupKind", str)
+	}
+	return nil
+}
+
+func (e DeviceGroupKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *DeviceGroupKind) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e DeviceGroupKind) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
 type DeviceStatus string
 
 const (
@@ -183,3 +687,294 @@ func (e DeviceStatus) MarshalJSON() ([]byte, error) {
 	e.MarshalGQL(&buf)
 	return buf.Bytes(), nil
 }
+
+type LocationKind string
+
+const (
+	LocationKindSite     LocationKind = "SITE"
+	LocationKindBuilding LocationKind = "BUILDING"
+	LocationKindFloor    LocationKind = "FLOOR"
+	LocationKindRoom     LocationKind = "ROOM"
+)
+
+var AllLocationKind = []LocationKind{
+	LocationKindSite,
+	LocationKindBuilding,
+	LocationKindFloor,
+	LocationKindRoom,
+}
+
+func (e LocationKind) IsValid() bool {
+	switch e {
+	case LocationKindSite, LocationKindBuilding, LocationKindFloor, LocationKindRoom:
+		return true
+	}
+	return false
+}
+
+func (e LocationKind) String() string {
+	return string(e)
+}
+
+func (e *LocationKind) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = LocationKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid LocationKind", str)
+	}
+	return nil
+}
+
+func (e LocationKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *LocationKind) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e LocationKind) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type MetricDataType string
+
+const (
+	MetricDataTypeFloat   MetricDataType = "FLOAT"
+	MetricDataTypeInteger MetricDataType = "INTEGER"
+	MetricDataTypeBoolean MetricDataType = "BOOLEAN"
+)
+
+var AllMetricDataType = []MetricDataType{
+	MetricDataTypeFloat,
+	MetricDataTypeInteger,
+	MetricDataTypeBoolean,
+}
+
+func (e MetricDataType) IsValid() bool {
+	switch e {
+	case MetricDataTypeFloat, MetricDataTypeInteger, MetricDataTypeBoolean:
+		return true
+	}
+	return false
+}
+
+func (e MetricDataType) String() string {
+	return string(e)
+}
+
+func (e *MetricDataType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = MetricDataType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid MetricDataType", str)
+	}
+	return nil
+}
+
+func (e MetricDataType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *MetricDataType) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e MetricDataType) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type OverflowPolicy string
+
+const (
+	OverflowPolicyDropOldest OverflowPolicy = "DROP_OLDEST"
+	OverflowPolicyDropNewest OverflowPolicy = "DROP_NEWEST"
+	OverflowPolicyCoalesce   OverflowPolicy = "COALESCE"
+	OverflowPolicyDisconnect OverflowPolicy = "DISCONNECT"
+)
+
+var AllOverflowPolicy = []OverflowPolicy{
+	OverflowPolicyDropOldest,
+	OverflowPolicyDropNewest,
+	OverflowPolicyCoalesce,
+	OverflowPolicyDisconnect,
+}
+
+func (e OverflowPolicy) IsValid() bool {
+	switch e {
+	case OverflowPolicyDropOldest, OverflowPolicyDropNewest, OverflowPolicyCoalesce, OverflowPolicyDisconnect:
+		return true
+	}
+	return false
+}
+
+func (e OverflowPolicy) String() string {
+	return string(e)
+}
+
+func (e *OverflowPolicy) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = OverflowPolicy(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid OverflowPolicy", str)
+	}
+	return nil
+}
+
+func (e OverflowPolicy) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *OverflowPolicy) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e OverflowPolicy) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type PermissionScope string
+
+const (
+	PermissionScopeOrganization PermissionScope = "ORGANIZATION"
+	PermissionScopePlatform     PermissionScope = "PLATFORM"
+)
+
+var AllPermissionScope = []PermissionScope{
+	PermissionScopeOrganization,
+	PermissionScopePlatform,
+}
+
+func (e PermissionScope) IsValid() bool {
+	switch e {
+	case PermissionScopeOrganization, PermissionScopePlatform:
+		return true
+	}
+	return false
+}
+
+func (e PermissionScope) String() string {
+	return string(e)
+}
+
+func (e *PermissionScope) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PermissionScope(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PermissionScope", str)
+	}
+	return nil
+}
+
+func (e PermissionScope) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *PermissionScope) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e PermissionScope) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
+
+type TelemetryGroupBy string
+
+const (
+	TelemetryGroupByDevice     TelemetryGroupBy = "DEVICE"
+	TelemetryGroupByDeviceType TelemetryGroupBy = "DEVICE_TYPE"
+	TelemetryGroupByAll        TelemetryGroupBy = "ALL"
+	TelemetryGroupByGroup      TelemetryGroupBy = "GROUP"
+	TelemetryGroupByLocation   TelemetryGroupBy = "LOCATION"
+)
+
+var AllTelemetryGroupBy = []TelemetryGroupBy{
+	TelemetryGroupByDevice,
+	TelemetryGroupByDeviceType,
+	TelemetryGroupByAll,
+	TelemetryGroupByGroup,
+	TelemetryGroupByLocation,
+}
+
+func (e TelemetryGroupBy) IsValid() bool {
+	switch e {
+	case TelemetryGroupByDevice, TelemetryGroupByDeviceType, TelemetryGroupByAll, TelemetryGroupByGroup, TelemetryGroupByLocation:
+		return true
+	}
+	return false
+}
+
+func (e TelemetryGroupBy) String() string {
+	return string(e)
+}
+
+func (e *TelemetryGroupBy) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TelemetryGroupBy(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TelemetryGroupBy", str)
+	}
+	return nil
+}
+
+func (e TelemetryGroupBy) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+func (e *TelemetryGroupBy) UnmarshalJSON(b []byte) error {
+	s, err := strconv.Unquote(string(b))
+	if err != nil {
+		return err
+	}
+	return e.UnmarshalGQL(s)
+}
+
+func (e TelemetryGroupBy) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	e.MarshalGQL(&buf)
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,117 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/auth"
+	"github.com/yourusername/iot-platform/services/api-gateway/graph/model"
+	userpb "github.com/yourusername/iot-platform/shared/proto/user"
+)
+
+func protoToGraphQLOrganization(o *userpb.Organization) *model.Organization {
+	return &model.Organization{
+		ID:        o.Id,
+		Name:      o.Name,
+		Slug:      o.Slug,
+		CreatedAt: int(o.CreatedAt),
+		Role:      stringValuePtr(o.Role),
+	}
+}
+
+func protoToGraphQLMembership(m *userpb.Membership) *model.Membership {
+	return &model.Membership{
+		OrganizationID: m.OrgId,
+		UserID:         m.UserId,
+		Role:           m.Role,
+		Permissions:    append([]string{}, m.Permissions...),
+		CreatedAt:      int(m.CreatedAt),
+	}
+}
+
+// OrganizationsImpl lists the organizations of the current user, or every
+// organization with orgs:admin.
+func (r *queryResolver) OrganizationsImpl(ctx context.Context) ([]*model.Organization, error) {
+	claims, err := auth.RequireAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := r.UserClient.ListOrganizations(ctx, &userpb.ListOrganizationsRequest{UserId: claims.UserID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	if !claims.HasPermission(auth.PermOrgsAdmin) {
+		organizations := make([]*model.Organization, len(member.Organizations))
+		for i, o := range member.Organizations {
+			organizations[i] = protoToGraphQLOrganization(o)
+		}
+		return organizations, nil
+	}
+
+	all, err := r.UserClient.ListOrganizations(ctx, &userpb.ListOrganizationsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	roles := make(map[string]string, len(member.Organizations))
+	for _, o := range member.Organizations {
+		roles[o.Id] = o.Role
+	}
+	organizations := make([]*model.Organization, len(all.Organizations))
+	for i, o := range all.Organizations {
+		organizations[i] = protoToGraphQLOrganization(o)
+		organizations[i].Role = stringValuePtr(roles[o.Id])
+	}
+	return organizations, nil
+}
+
+// CreateOrganizationImpl creates an organization, with ownerId as its first
+// admin.
+func (r *mutationResolver) CreateOrganizationImpl(ctx context.Context, input model.CreateOrganizationInput) (*model.Organization, error) {
+	resp, err := r.UserClient.CreateOrganization(ctx, &userpb.CreateOrganizationRequest{
+		Name:    input.Name,
+		Slug:    input.Slug,
+		OwnerId: stringPtrToValue(input.OwnerID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	log.Printf("✅ Organization created: %s (%s)", resp.Organization.Slug, resp.Organization.Id)
+	return protoToGraphQLOrganization(resp.Organization), nil
+}
+
+// AddMemberImpl adds an existing user to the organization of the caller.
+func (r *mutationResolver) AddMemberImpl(ctx context.Context, userID string, role string) (*model.Membership, error) {
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.UserClient.AddMember(ctx, &userpb.AddMemberRequest{OrgId: orgID, UserId: userID, Role: role})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add member: %w", err)
+	}
+
+	log.Printf("✅ User %s added to organization %s as %s", userID, orgID, role)
+	return protoToGraphQLMembership(resp.Membership), nil
+}
+
+// RemoveMemberImpl removes a user from the organization of the caller. Their
+// sessions in the organization fail at their next refresh.
+func (r *mutationResolver) RemoveMemberImpl(ctx context.Context, userID string) (*model.DeleteResult, error) {
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := r.UserClient.RemoveMember(ctx, &userpb.RemoveMemberRequest{OrgId: orgID, UserId: userID}); err != nil {
+		return nil, fmt.Errorf("failed to remove member: %w", err)
+	}
+
+	return &model.DeleteResult{
+		Success: true,
+		Message: fmt.Sprintf("User %s removed from the organization", userID),
+	}, nil
+}
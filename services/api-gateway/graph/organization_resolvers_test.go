@@ -0,0 +1,150 @@
+package graph
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/auth"
+	"github.com/yourusername/iot-platform/services/api-gateway/graph/model"
+	userpb "github.com/yourusername/iot-platform/shared/proto/user"
+	"google.golang.org/grpc"
+)
+
+func TestSwitchOrganizationImpl(t *testing.T) {
+	var switched *userpb.SwitchSessionOrganizationRequest
+	mock := &MockUserServiceClient{
+		SwitchSessionOrganizationFunc: func(ctx context.Context, req *userpb.SwitchSessionOrganizationRequest, opts ...grpc.CallOption) (*userpb.SwitchSessionOrganizationResponse, error) {
+			switched = req
+			return &userpb.SwitchSessionOrganizationResponse{
+				Session:    &userpb.Session{Id: req.SessionId, OrgId: req.OrgId},
+				User:       testUser,
+				Membership: &userpb.Membership{OrgId: req.OrgId, UserId: req.UserId, Role: "admin", Permissions: []string{auth.PermAll}},
+			}, nil
+		},
+	}
+	jwtManager := auth.NewJWTManager("test-secret", 15*time.Minute)
+	r := &mutationResolver{&Resolver{UserClient: mock, JWTManager: jwtManager}}
+
+	if _, err := r.SwitchOrganizationImpl(userContext(), "org-2"); err == nil {
+		t.Error("expected error for a token without session")
+	}
+
+	ctx := auth.WithUser(context.Background(), &auth.Claims{UserID: "user-1", Role: "user", SessionID: "session-1", OrgID: "org-1"})
+	payload, err := r.SwitchOrganizationImpl(ctx, "org-2")
+	if err != nil {
+		t.Fatalf("SwitchOrganizationImpl failed: %v", err)
+	}
+	if switched.SessionId != "session-1" || switched.UserId != "user-1" || switched.OrgId != "org-2" {
+		t.Errorf("unexpected switch request %+v", switched)
+	}
+	if payload.RefreshToken != nil {
+		t.Errorf("expected no new refresh token, got %v", *payload.RefreshToken)
+	}
+
+	// An organization admin is granted the organization permissions only
+	claims, err := jwtManager.ValidateToken(payload.Token)
+	if err != nil {
+		t.Fatalf("invalid access token: %v", err)
+	}
+	if claims.OrgID != "org-2" || claims.SessionID != "session-1" {
+		t.Errorf("unexpected claims %+v", claims)
+	}
+	if !claims.HasPermission(auth.PermUsersAdmin) || claims.HasPermission(auth.PermRetentionAdmin) {
+		t.Errorf("unexpected permissions %v", claims.Permissions)
+	}
+}
+
+func TestOrganizationsImpl(t *testing.T) {
+	mock := &MockUserServiceClient{
+		ListOrganizationsFunc: func(ctx context.Context, req *userpb.ListOrganizationsRequest, opts ...grpc.CallOption) (*userpb.ListOrganizationsResponse, error) {
+			if req.UserId == "" {
+				return &userpb.ListOrganizationsResponse{Organizations: []*userpb.Organization{
+					{Id: "org-1", Slug: "acme"},
+					{Id: "org-2", Slug: "globex"},
+				}}, nil
+			}
+			return &userpb.ListOrganizationsResponse{Organizations: []*userpb.Organization{{Id: "org-1", Slug: "acme", Role: "user"}}}, nil
+		},
+	}
+	r := &queryResolver{&Resolver{UserClient: mock}}
+
+	organizations, err := r.OrganizationsImpl(userContext())
+	if err != nil {
+		t.Fatalf("OrganizationsImpl failed: %v", err)
+	}
+	if len(organizations) != 1 || *organizations[0].Role != "user" {
+		t.Errorf("unexpected organizations %+v", organizations)
+	}
+
+	// orgs:admin lists every organization, with the role where a member
+	organizations, err = r.OrganizationsImpl(adminContext())
+	if err != nil {
+		t.Fatalf("OrganizationsImpl failed: %v", err)
+	}
+	if len(organizations) != 2 || *organizations[0].Role != "user" || organizations[1].Role != nil {
+		t.Errorf("unexpected organizations %+v", organizations)
+	}
+}
+
+// TestRegisterImpl_Organization tests that registered users join the
+// organization of the caller, and that only platform admins choose the
+// platform role.
+func TestRegisterImpl_Organization(t *testing.T) {
+	var registered *userpb.RegisterRequest
+	mock := &MockUserServiceClient{
+		RegisterFunc: func(ctx context.Context, req *userpb.RegisterRequest, opts ...grpc.CallOption) (*userpb.RegisterResponse, error) {
+			registered = req
+			return &userpb.RegisterResponse{User: &userpb.User{Id: "user-2", Email: req.Email, Role: "user", OrgRole: req.OrgRole}}, nil
+		},
+		GetMembershipFunc: func(ctx context.Context, req *userpb.GetMembershipRequest, opts ...grpc.CallOption) (*userpb.MemberResponse, error) {
+			return &userpb.MemberResponse{Membership: &userpb.Membership{OrgId: req.OrgId, UserId: req.UserId, Role: registered.OrgRole, Permissions: []string{auth.PermAll}}}, nil
+		},
+	}
+	jwtManager := auth.NewJWTManager("test-secret", 15*time.Minute)
+	r := &mutationResolver{&Resolver{UserClient: mock, JWTManager: jwtManager}}
+	role := "admin"
+	input := model.RegisterInput{Email: "new@example.com", Password: "Password123!", Name: "New", Role: &role}
+
+	orgAdminCtx := auth.WithUser(context.Background(), &auth.Claims{UserID: "user-1", Role: "user", OrgID: "org-1", Permissions: []string{auth.PermUsersAdmin}})
+	payload, err := r.RegisterImpl(orgAdminCtx, input)
+	if err != nil {
+		t.Fatalf("RegisterImpl failed: %v", err)
+	}
+	if registered.OrgId != "org-1" || registered.OrgRole != "admin" || registered.Role != "" {
+		t.Errorf("unexpected register request %+v", registered)
+	}
+	if payload.OrganizationID != "org-1" || payload.RefreshToken != nil {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+
+	if _, err := r.RegisterImpl(adminContext(), input); err != nil {
+		t.Fatalf("RegisterImpl failed: %v", err)
+	}
+	if registered.OrgId != auth.DefaultOrganizationID || registered.Role != "admin" {
+		t.Errorf("unexpected register request %+v", registered)
+	}
+}
+
+func TestAddMemberImpl(t *testing.T) {
+	var added *userpb.AddMemberRequest
+	mock := &MockUserServiceClient{
+		AddMemberFunc: func(ctx context.Context, req *userpb.AddMemberRequest, opts ...grpc.CallOption) (*userpb.MemberResponse, error) {
+			added = req
+			return &userpb.MemberResponse{Membership: &userpb.Membership{OrgId: req.OrgId, UserId: req.UserId, Role: req.Role}}, nil
+		},
+	}
+	r := &mutationResolver{&Resolver{UserClient: mock}}
+
+	ctx := auth.WithUser(context.Background(), &auth.Claims{UserID: "user-1", Role: "user", OrgID: "org-1", Permissions: []string{auth.PermUsersAdmin}})
+	membership, err := r.AddMemberImpl(ctx, "user-2", "viewer")
+	if err != nil {
+		t.Fatalf("AddMemberImpl failed: %v", err)
+	}
+	if added.OrgId != "org-1" || added.UserId != "user-2" || added.Role != "viewer" {
+		t.Errorf("unexpected add request %+v", added)
+	}
+	if membership.OrganizationID != "org-1" || membership.Role != "viewer" {
+		t.Errorf("unexpected membership %+v", membership)
+	}
+}
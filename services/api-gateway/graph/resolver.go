@@ -20,4 +20,8 @@ type Resolver struct {
 	TelemetryClient telemetrypb.TelemetryServiceClient
 	JWTManager      *auth.JWTManager
 	Broker          *pubsub.Broker
+	// Replayer serves lastEventId resumption, nil with the pub/sub transport
+	Replayer pubsub.Replayer
+	// Cluster collects the subscription counts of all replicas, nil without event bus
+	Cluster *pubsub.Cluster
 }
@@ -4,8 +4,9 @@ import (
 	"context"
 	"fmt"
 
-	devicepb "github.com/yourusername/iot-platform/shared/proto/device"
+	"github.com/yourusername/iot-platform/services/api-gateway/auth"
 	"github.com/yourusername/iot-platform/services/api-gateway/graph/model"
+	devicepb "github.com/yourusername/iot-platform/shared/proto/device"
 )
 
 // Helper functions to convert between Protobuf and GraphQL types
@@ -24,15 +25,20 @@ func protoToGraphQLDevice(d *devicepb.Device) *model.Device {
 		})
 	}
 
-	return &model.Device{
-		ID:        d.Id,
-		Name:      d.Name,
-		Type:      d.Type,
-		Status:    protoToGraphQLStatus(d.Status),
-		CreatedAt: int(d.CreatedAt),
-		LastSeen:  int(d.LastSeen),
-		Metadata:  metadata,
+	device := &model.Device{
+		ID:             d.Id,
+		Name:           d.Name,
+		Type:           d.Type,
+		Status:         protoToGraphQLStatus(d.Status),
+		CreatedAt:      int(d.CreatedAt),
+		LastSeen:       int(d.LastSeen),
+		Metadata:       metadata,
+		OrganizationID: d.OrgId,
+	}
+	if d.LocationId != "" {
+		device.LocationID = &d.LocationId
 	}
+	return device
 }
 
 func protoToGraphQLStatus(s devicepb.DeviceStatus) model.DeviceStatus {
@@ -71,7 +77,17 @@ func graphQLToProtoStatus(s *model.DeviceStatus) devicepb.DeviceStatus {
 
 // Mutation resolvers
 
+// CreateDeviceImpl creates a device in the organization of the caller. Users
+// restricted by ACL entries could not see it and may not create devices.
 func (r *mutationResolver) CreateDeviceImpl(ctx context.Context, input model.CreateDeviceInput) (*model.Device, error) {
+	if err := r.authorizeAllDevices(ctx, auth.PermDevicesWrite); err != nil {
+		return nil, err
+	}
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// Convert GraphQL input to Protobuf request
 	// Convert slice to map
 	metadata := make(map[string]string)
@@ -82,9 +98,11 @@ func (r *mutationResolver) CreateDeviceImpl(ctx context.Context, input model.Cre
 	}
 
 	req := &devicepb.CreateDeviceRequest{
-		Name:     input.Name,
-		Type:     input.Type,
-		Metadata: metadata,
+		Name:       input.Name,
+		Type:       input.Type,
+		Metadata:   metadata,
+		OrgId:      orgID,
+		LocationId: stringPtrToValue(input.LocationID),
 	}
 
 	// Call Device Manager via gRPC
@@ -98,6 +116,14 @@ func (r *mutationResolver) CreateDeviceImpl(ctx context.Context, input model.Cre
 }
 
 func (r *mutationResolver) UpdateDeviceImpl(ctx context.Context, input model.UpdateDeviceInput) (*model.Device, error) {
+	if err := r.authorizeDevice(ctx, input.ID, auth.PermDevicesWrite); err != nil {
+		return nil, err
+	}
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// Convert metadata if provided
 	var metadata map[string]string
 	if input.Metadata != nil {
@@ -108,10 +134,12 @@ func (r *mutationResolver) UpdateDeviceImpl(ctx context.Context, input model.Upd
 	}
 
 	req := &devicepb.UpdateDeviceRequest{
-		Id:       input.ID,
-		Name:     stringPtrToValue(input.Name),
-		Status:   graphQLToProtoStatus(input.Status),
-		Metadata: metadata,
+		Id:         input.ID,
+		Name:       stringPtrToValue(input.Name),
+		Status:     graphQLToProtoStatus(input.Status),
+		Metadata:   metadata,
+		OrgId:      orgID,
+		LocationId: input.LocationID,
 	}
 
 	resp, err := r.DeviceClient.UpdateDevice(ctx, req)
@@ -123,8 +151,17 @@ func (r *mutationResolver) UpdateDeviceImpl(ctx context.Context, input model.Upd
 }
 
 func (r *mutationResolver) DeleteDeviceImpl(ctx context.Context, id string) (*model.DeleteResult, error) {
+	if err := r.authorizeDevice(ctx, id, auth.PermDevicesWrite); err != nil {
+		return nil, err
+	}
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	req := &devicepb.DeleteDeviceRequest{
-		Id: id,
+		Id:    id,
+		OrgId: orgID,
 	}
 
 	resp, err := r.DeviceClient.DeleteDevice(ctx, req)
@@ -141,8 +178,17 @@ func (r *mutationResolver) DeleteDeviceImpl(ctx context.Context, id string) (*mo
 // Query resolvers
 
 func (r *queryResolver) DeviceImpl(ctx context.Context, id string) (*model.Device, error) {
+	if err := r.authorizeDevice(ctx, id, auth.PermDevicesRead); err != nil {
+		return nil, err
+	}
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	req := &devicepb.GetDeviceRequest{
-		Id: id,
+		Id:    id,
+		OrgId: orgID,
 	}
 
 	resp, err := r.DeviceClient.GetDevice(ctx, req)
@@ -153,7 +199,15 @@ func (r *queryResolver) DeviceImpl(ctx context.Context, id string) (*model.Devic
 	return protoToGraphQLDevice(resp.Device), nil
 }
 
-func (r *queryResolver) DevicesImpl(ctx context.Context, page *int, pageSize *int, typeArg *string, status *model.DeviceStatus) (*model.DeviceConnection, error) {
+// DevicesImpl lists the devices of the organization of the caller, those
+// granted by their ACL entries if they have any. Filters are applied by the
+// Device Manager, so that the total is that of the matching devices.
+func (r *queryResolver) DevicesImpl(ctx context.Context, page *int, pageSize *int, typeArg *string, status *model.DeviceStatus, groupID *string, locationID *string) (*model.DeviceConnection, error) {
+	claims, err := auth.RequireAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// Default values
 	p := int32(1)
 	ps := int32(10)
@@ -166,8 +220,14 @@ func (r *queryResolver) DevicesImpl(ctx context.Context, page *int, pageSize *in
 	}
 
 	req := &devicepb.ListDevicesRequest{
-		Page:     p,
-		PageSize: ps,
+		Page:       p,
+		PageSize:   ps,
+		Type:       stringPtrToValue(typeArg),
+		Status:     graphQLToProtoStatus(status),
+		OrgId:      claims.Organization(),
+		GroupId:    stringPtrToValue(groupID),
+		LocationId: stringPtrToValue(locationID),
+		Subject:    accessSubject(claims),
 	}
 
 	resp, err := r.DeviceClient.ListDevices(ctx, req)
@@ -181,24 +241,6 @@ func (r *queryResolver) DevicesImpl(ctx context.Context, page *int, pageSize *in
 		devices[i] = protoToGraphQLDevice(d)
 	}
 
-	// Client-side filtering (TODO: move to server-side)
-	if typeArg != nil || status != nil {
-		filtered := make([]*model.Device, 0)
-		for _, d := range devices {
-			match := true
-			if typeArg != nil && d.Type != *typeArg {
-				match = false
-			}
-			if status != nil && d.Status != *status {
-				match = false
-			}
-			if match {
-				filtered = append(filtered, d)
-			}
-		}
-		devices = filtered
-	}
-
 	return &model.DeviceConnection{
 		Devices:  devices,
 		Total:    int(resp.Total),
@@ -208,10 +250,17 @@ func (r *queryResolver) DevicesImpl(ctx context.Context, page *int, pageSize *in
 }
 
 func (r *queryResolver) StatsImpl(ctx context.Context) (*model.Stats, error) {
+	claims, err := auth.RequireAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get all devices to compute stats
 	req := &devicepb.ListDevicesRequest{
 		Page:     1,
 		PageSize: 1000, // TODO: Implement server-side stats endpoint
+		OrgId:    claims.Organization(),
+		Subject:  accessSubject(claims),
 	}
 
 	resp, err := r.DeviceClient.ListDevices(ctx, req)
@@ -244,6 +293,46 @@ func (r *queryResolver) StatsImpl(ctx context.Context) (*model.Stats, error) {
 	}, nil
 }
 
+// DeviceUpdatedImpl streams created and updated devices of the organization of
+// the caller, as published by the Device Manager on the event bus. Users
+// restricted by ACL entries only receive the devices they are granted.
+func (r *subscriptionResolver) DeviceUpdatedImpl(ctx context.Context) (<-chan *model.Device, error) {
+	claims, err := auth.RequireAuth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	restricted := r.authorizeAllDevices(ctx, auth.PermDevicesRead) != nil
+
+	ch := r.Broker.SubscribeDevices(claims.Organization())
+
+	// Cleanup when context is done (client disconnects)
+	go func() {
+		<-ctx.Done()
+		r.Broker.UnsubscribeDevices(ch)
+	}()
+
+	if !restricted {
+		return ch, nil
+	}
+
+	// Devices gained or lost through groups are checked on every event
+	out := make(chan *model.Device, cap(ch))
+	go func() {
+		defer close(out)
+		for device := range ch {
+			if r.checkDeviceAccess(ctx, claims, []string{device.ID}, auth.PermDevicesRead) != nil {
+				continue
+			}
+			select {
+			case out <- device:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
 // Helper functions
 
 func stringPtrToValue(s *string) string {
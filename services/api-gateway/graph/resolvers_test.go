@@ -22,6 +22,17 @@ type MockDeviceServiceClient struct {
 	ListDevicesFunc  func(ctx context.Context, req *pb.ListDevicesRequest, opts ...grpc.CallOption) (*pb.ListDevicesResponse, error)
 	UpdateDeviceFunc func(ctx context.Context, req *pb.UpdateDeviceRequest, opts ...grpc.CallOption) (*pb.UpdateDeviceResponse, error)
 	DeleteDeviceFunc func(ctx context.Context, req *pb.DeleteDeviceRequest, opts ...grpc.CallOption) (*pb.DeleteDeviceResponse, error)
+
+	GetDeviceTypeFunc    func(ctx context.Context, req *pb.GetDeviceTypeRequest, opts ...grpc.CallOption) (*pb.GetDeviceTypeResponse, error)
+	UpsertDeviceTypeFunc func(ctx context.Context, req *pb.UpsertDeviceTypeRequest, opts ...grpc.CallOption) (*pb.UpsertDeviceTypeResponse, error)
+
+	CreateDeviceGroupFunc func(ctx context.Context, req *pb.CreateDeviceGroupRequest, opts ...grpc.CallOption) (*pb.CreateDeviceGroupResponse, error)
+	GetDeviceGroupFunc    func(ctx context.Context, req *pb.GetDeviceGroupRequest, opts ...grpc.CallOption) (*pb.GetDeviceGroupResponse, error)
+	AddGroupDevicesFunc   func(ctx context.Context, req *pb.GroupDevicesRequest, opts ...grpc.CallOption) (*pb.GroupDevicesResponse, error)
+	CreateLocationFunc    func(ctx context.Context, req *pb.CreateLocationRequest, opts ...grpc.CallOption) (*pb.CreateLocationResponse, error)
+
+	CheckDeviceAccessFunc func(ctx context.Context, req *pb.CheckDeviceAccessRequest, opts ...grpc.CallOption) (*pb.CheckDeviceAccessResponse, error)
+	CreateAccessEntryFunc func(ctx context.Context, req *pb.CreateAccessEntryRequest, opts ...grpc.CallOption) (*pb.CreateAccessEntryResponse, error)
 }
 
 func (m *MockDeviceServiceClient) CreateDevice(ctx context.Context, req *pb.CreateDeviceRequest, opts ...grpc.CallOption) (*pb.CreateDeviceResponse, error) {
@@ -59,6 +70,20 @@ func (m *MockDeviceServiceClient) DeleteDevice(ctx context.Context, req *pb.Dele
 	return nil, errors.New("DeleteDeviceFunc not implemented")
 }
 
+func (m *MockDeviceServiceClient) GetDeviceType(ctx context.Context, req *pb.GetDeviceTypeRequest, opts ...grpc.CallOption) (*pb.GetDeviceTypeResponse, error) {
+	if m.GetDeviceTypeFunc != nil {
+		return m.GetDeviceTypeFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("GetDeviceTypeFunc not implemented")
+}
+
+func (m *MockDeviceServiceClient) UpsertDeviceType(ctx context.Context, req *pb.UpsertDeviceTypeRequest, opts ...grpc.CallOption) (*pb.UpsertDeviceTypeResponse, error) {
+	if m.UpsertDeviceTypeFunc != nil {
+		return m.UpsertDeviceTypeFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("UpsertDeviceTypeFunc not implemented")
+}
+
 // Helper function to create a test resolver with mock client
 func newTestResolver(mock *MockDeviceServiceClient) *Resolver {
 	return &Resolver{
@@ -164,7 +189,7 @@ func TestCreateDeviceImpl(t *testing.T) {
 			resolver := newTestResolver(mock)
 			mutationResolver := &mutationResolver{resolver}
 
-			device, err := mutationResolver.CreateDeviceImpl(context.Background(), tt.input)
+			device, err := mutationResolver.CreateDeviceImpl(userContext(), tt.input)
 
 			if tt.wantErr {
 				if err == nil {
@@ -246,7 +271,7 @@ func TestGetDeviceImpl(t *testing.T) {
 			resolver := newTestResolver(mock)
 			queryResolver := &queryResolver{resolver}
 
-			device, err := queryResolver.DeviceImpl(context.Background(), tt.deviceID)
+			device, err := queryResolver.DeviceImpl(userContext(), tt.deviceID)
 
 			if tt.wantErr {
 				if err == nil {
@@ -275,14 +300,16 @@ func TestGetDeviceImpl(t *testing.T) {
 // TestListDevicesImpl tests the ListDevices query resolver.
 func TestListDevicesImpl(t *testing.T) {
 	tests := []struct {
-		name      string
-		page      *int
-		pageSize  *int
-		typeArg   *string
-		status    *model.DeviceStatus
-		mockSetup func(*MockDeviceServiceClient)
-		wantErr   bool
-		validate  func(t *testing.T, conn *model.DeviceConnection)
+		name       string
+		page       *int
+		pageSize   *int
+		typeArg    *string
+		status     *model.DeviceStatus
+		groupID    *string
+		locationID *string
+		mockSetup  func(*MockDeviceServiceClient)
+		wantErr    bool
+		validate   func(t *testing.T, conn *model.DeviceConnection)
 	}{
 		{
 			name: "default_pagination",
@@ -327,6 +354,9 @@ func TestListDevicesImpl(t *testing.T) {
 			name: "with_type_filter",
 			mockSetup: func(m *MockDeviceServiceClient) {
 				m.ListDevicesFunc = func(ctx context.Context, req *pb.ListDevicesRequest, opts ...grpc.CallOption) (*pb.ListDevicesResponse, error) {
+					if req.Type != "sensor" || req.Status != pb.DeviceStatus_ONLINE {
+						t.Errorf("expected type and status filters in the request, got %q %v", req.Type, req.Status)
+					}
 					return &pb.ListDevicesResponse{
 						Devices: []*pb.Device{
 							{
@@ -337,29 +367,43 @@ func TestListDevicesImpl(t *testing.T) {
 								CreatedAt: 1234567890,
 								LastSeen:  1234567890,
 							},
-							{
-								Id:        "id-2",
-								Name:      "Device 2",
-								Type:      "actuator",
-								Status:    pb.DeviceStatus_ONLINE,
-								CreatedAt: 1234567890,
-								LastSeen:  1234567890,
-							},
 						},
-						Total:    2,
+						Total:    1,
 						Page:     1,
 						PageSize: 10,
 					}, nil
 				}
 			},
 			typeArg: stringPtr("sensor"),
+			status:  statusPtr(model.DeviceStatusOnline),
 			wantErr: false,
 			validate: func(t *testing.T, conn *model.DeviceConnection) {
-				if len(conn.Devices) != 1 {
-					t.Errorf("expected 1 device after filtering, got %d", len(conn.Devices))
+				if len(conn.Devices) != 1 || conn.Total != 1 {
+					t.Errorf("expected 1 device, got %d (total %d)", len(conn.Devices), conn.Total)
+				}
+			},
+		},
+		{
+			name: "with_group_and_location_filters",
+			mockSetup: func(m *MockDeviceServiceClient) {
+				m.ListDevicesFunc = func(ctx context.Context, req *pb.ListDevicesRequest, opts ...grpc.CallOption) (*pb.ListDevicesResponse, error) {
+					if req.GroupId != "group-1" || req.LocationId != "site-1" {
+						t.Errorf("expected group and location filters in the request, got %q %q", req.GroupId, req.LocationId)
+					}
+					return &pb.ListDevicesResponse{
+						Devices:  []*pb.Device{{Id: "id-1", Name: "Device 1", Type: "sensor", LocationId: "room-1"}},
+						Total:    1,
+						Page:     1,
+						PageSize: 10,
+					}, nil
 				}
-				if conn.Devices[0].Type != "sensor" {
-					t.Errorf("expected type 'sensor', got %s", conn.Devices[0].Type)
+			},
+			groupID:    stringPtr("group-1"),
+			locationID: stringPtr("site-1"),
+			wantErr:    false,
+			validate: func(t *testing.T, conn *model.DeviceConnection) {
+				if len(conn.Devices) != 1 || conn.Devices[0].LocationID == nil || *conn.Devices[0].LocationID != "room-1" {
+					t.Errorf("expected the device of room-1, got %+v", conn.Devices)
 				}
 			},
 		},
@@ -392,7 +436,7 @@ func TestListDevicesImpl(t *testing.T) {
 			resolver := newTestResolver(mock)
 			queryResolver := &queryResolver{resolver}
 
-			conn, err := queryResolver.DevicesImpl(context.Background(), tt.page, tt.pageSize, tt.typeArg, tt.status)
+			conn, err := queryResolver.DevicesImpl(userContext(), tt.page, tt.pageSize, tt.typeArg, tt.status, tt.groupID, tt.locationID)
 
 			if tt.wantErr {
 				if err == nil {
@@ -504,7 +548,7 @@ func TestUpdateDeviceImpl(t *testing.T) {
 			resolver := newTestResolver(mock)
 			mutationResolver := &mutationResolver{resolver}
 
-			device, err := mutationResolver.UpdateDeviceImpl(context.Background(), tt.input)
+			device, err := mutationResolver.UpdateDeviceImpl(userContext(), tt.input)
 
 			if tt.wantErr {
 				if err == nil {
@@ -577,7 +621,7 @@ func TestDeleteDeviceImpl(t *testing.T) {
 			resolver := newTestResolver(mock)
 			mutationResolver := &mutationResolver{resolver}
 
-			result, err := mutationResolver.DeleteDeviceImpl(context.Background(), tt.deviceID)
+			result, err := mutationResolver.DeleteDeviceImpl(userContext(), tt.deviceID)
 
 			if tt.wantErr {
 				if err == nil {
@@ -669,7 +713,7 @@ func TestStatsImpl(t *testing.T) {
 			resolver := newTestResolver(mock)
 			queryResolver := &queryResolver{resolver}
 
-			stats, err := queryResolver.StatsImpl(context.Background())
+			stats, err := queryResolver.StatsImpl(userContext())
 
 			if tt.wantErr {
 				if err == nil {
@@ -0,0 +1,111 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/graph/model"
+	telemetrypb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+)
+
+func protoToGraphQLRetentionPolicy(p *telemetrypb.RetentionPolicy) *model.RetentionPolicy {
+	policy := &model.RetentionPolicy{
+		ID:           p.Id,
+		RawRetention: p.RawRetention,
+		CreatedAt:    int(p.CreatedAt),
+		UpdatedAt:    int(p.UpdatedAt),
+	}
+	if p.DeviceType != "" {
+		policy.DeviceType = &p.DeviceType
+	}
+	if p.MetricName != "" {
+		policy.MetricName = &p.MetricName
+	}
+	if p.DownsampleInterval != "" {
+		policy.DownsampleInterval = &p.DownsampleInterval
+	}
+	if p.DownsampleRetention != "" {
+		policy.DownsampleRetention = &p.DownsampleRetention
+	}
+	return policy
+}
+
+func protoToGraphQLRetentionResults(results []*telemetrypb.RetentionPolicyResult) []*model.RetentionPolicyResult {
+	out := make([]*model.RetentionPolicyResult, len(results))
+	for i, r := range results {
+		out[i] = &model.RetentionPolicyResult{
+			Policy:            protoToGraphQLRetentionPolicy(r.Policy),
+			RawRowsDeleted:    int(r.RawRowsDeleted),
+			BucketsWritten:    int(r.BucketsWritten),
+			RollupRowsDeleted: int(r.RollupRowsDeleted),
+		}
+	}
+	return out
+}
+
+// RetentionPoliciesImpl lists retention policies.
+func (r *queryResolver) RetentionPoliciesImpl(ctx context.Context) ([]*model.RetentionPolicy, error) {
+	resp, err := r.TelemetryClient.ListRetentionPolicies(ctx, &telemetrypb.ListRetentionPoliciesRequest{})
+	if err != nil {
+		log.Printf("❌ Failed to list retention policies: %v", err)
+		return nil, err
+	}
+
+	policies := make([]*model.RetentionPolicy, len(resp.Policies))
+	for i, p := range resp.Policies {
+		policies[i] = protoToGraphQLRetentionPolicy(p)
+	}
+	return policies, nil
+}
+
+// RetentionDryRunImpl reports how many rows each policy would remove.
+func (r *queryResolver) RetentionDryRunImpl(ctx context.Context) ([]*model.RetentionPolicyResult, error) {
+	resp, err := r.TelemetryClient.ApplyRetention(ctx, &telemetrypb.ApplyRetentionRequest{DryRun: true})
+	if err != nil {
+		log.Printf("❌ Failed to evaluate retention: %v", err)
+		return nil, err
+	}
+	return protoToGraphQLRetentionResults(resp.Results), nil
+}
+
+// UpsertRetentionPolicyImpl creates or replaces a retention policy.
+func (r *mutationResolver) UpsertRetentionPolicyImpl(ctx context.Context, input model.RetentionPolicyInput) (*model.RetentionPolicy, error) {
+	policy, err := r.TelemetryClient.UpsertRetentionPolicy(ctx, &telemetrypb.UpsertRetentionPolicyRequest{
+		Policy: &telemetrypb.RetentionPolicy{
+			DeviceType:          stringPtrToValue(input.DeviceType),
+			MetricName:          stringPtrToValue(input.MetricName),
+			RawRetention:        input.RawRetention,
+			DownsampleInterval:  stringPtrToValue(input.DownsampleInterval),
+			DownsampleRetention: stringPtrToValue(input.DownsampleRetention),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save retention policy: %w", err)
+	}
+
+	log.Printf("✅ Retention policy saved: %s", policy.Id)
+	return protoToGraphQLRetentionPolicy(policy), nil
+}
+
+// DeleteRetentionPolicyImpl deletes a retention policy.
+func (r *mutationResolver) DeleteRetentionPolicyImpl(ctx context.Context, id string) (*model.DeleteResult, error) {
+	resp, err := r.TelemetryClient.DeleteRetentionPolicy(ctx, &telemetrypb.DeleteRetentionPolicyRequest{Id: id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete retention policy: %w", err)
+	}
+
+	return &model.DeleteResult{
+		Success: resp.Success,
+		Message: "Retention policy deleted",
+	}, nil
+}
+
+// ApplyRetentionImpl enforces retention policies immediately.
+func (r *mutationResolver) ApplyRetentionImpl(ctx context.Context) ([]*model.RetentionPolicyResult, error) {
+	resp, err := r.TelemetryClient.ApplyRetention(ctx, &telemetrypb.ApplyRetentionRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply retention: %w", err)
+	}
+	return protoToGraphQLRetentionResults(resp.Results), nil
+}
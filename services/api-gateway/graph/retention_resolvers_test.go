@@ -0,0 +1,101 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/auth"
+	"github.com/yourusername/iot-platform/services/api-gateway/graph/model"
+	telemetrypb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+	"google.golang.org/grpc"
+)
+
+func adminContext() context.Context {
+	return auth.WithUser(context.Background(), &auth.Claims{UserID: "admin-1", Role: "admin"})
+}
+
+// TestRetentionPoliciesImpl tests that retention policies are mapped correctly.
+func TestRetentionPoliciesImpl(t *testing.T) {
+	mock := &MockTelemetryServiceClient{
+		ListRetentionPoliciesFunc: func(ctx context.Context, req *telemetrypb.ListRetentionPoliciesRequest, opts ...grpc.CallOption) (*telemetrypb.ListRetentionPoliciesResponse, error) {
+			return &telemetrypb.ListRetentionPoliciesResponse{
+				Policies: []*telemetrypb.RetentionPolicy{
+					{Id: "p1", MetricName: "vibration", RawRetention: "7 days", DownsampleInterval: "1 hour"},
+					{Id: "p0", RawRetention: "90 days"},
+				},
+			}, nil
+		},
+	}
+	resolver := &queryResolver{&Resolver{TelemetryClient: mock}}
+
+	policies, err := resolver.RetentionPoliciesImpl(adminContext())
+	if err != nil {
+		t.Fatalf("RetentionPoliciesImpl() error = %v", err)
+	}
+	if len(policies) != 2 {
+		t.Fatalf("expected 2 policies, got %d", len(policies))
+	}
+	if policies[0].MetricName == nil || *policies[0].MetricName != "vibration" || policies[0].DeviceType != nil {
+		t.Errorf("unexpected scope: %+v", policies[0])
+	}
+	if policies[0].DownsampleInterval == nil || *policies[0].DownsampleInterval != "1 hour" || policies[0].DownsampleRetention != nil {
+		t.Errorf("unexpected downsampling: %+v", policies[0])
+	}
+	if policies[1].MetricName != nil || policies[1].RawRetention != "90 days" {
+		t.Errorf("unexpected default policy: %+v", policies[1])
+	}
+}
+
+// TestUpsertRetentionPolicyImpl tests the upsertRetentionPolicy mutation resolver.
+func TestUpsertRetentionPolicyImpl(t *testing.T) {
+	mock := &MockTelemetryServiceClient{
+		UpsertRetentionPolicyFunc: func(ctx context.Context, req *telemetrypb.UpsertRetentionPolicyRequest, opts ...grpc.CallOption) (*telemetrypb.RetentionPolicy, error) {
+			if req.Policy.DeviceType != "vibration_sensor" || req.Policy.MetricName != "" || req.Policy.RawRetention != "2 years" {
+				t.Errorf("unexpected policy: %v", req.Policy)
+			}
+			return &telemetrypb.RetentionPolicy{
+				Id:           "p2",
+				DeviceType:   req.Policy.DeviceType,
+				RawRetention: req.Policy.RawRetention,
+			}, nil
+		},
+	}
+	resolver := &mutationResolver{&Resolver{TelemetryClient: mock}}
+
+	policy, err := resolver.UpsertRetentionPolicyImpl(adminContext(), model.RetentionPolicyInput{
+		DeviceType:   stringPtr("vibration_sensor"),
+		RawRetention: "2 years",
+	})
+	if err != nil {
+		t.Fatalf("UpsertRetentionPolicyImpl() error = %v", err)
+	}
+	if policy.ID != "p2" || policy.DeviceType == nil || *policy.DeviceType != "vibration_sensor" {
+		t.Errorf("unexpected policy: %+v", policy)
+	}
+}
+
+// TestRetentionDryRunImpl tests that the dry-run query never deletes data.
+func TestRetentionDryRunImpl(t *testing.T) {
+	mock := &MockTelemetryServiceClient{
+		ApplyRetentionFunc: func(ctx context.Context, req *telemetrypb.ApplyRetentionRequest, opts ...grpc.CallOption) (*telemetrypb.ApplyRetentionResponse, error) {
+			if !req.DryRun {
+				t.Error("expected dry_run to be set")
+			}
+			return &telemetrypb.ApplyRetentionResponse{
+				DryRun: true,
+				Results: []*telemetrypb.RetentionPolicyResult{
+					{Policy: &telemetrypb.RetentionPolicy{Id: "p1", RawRetention: "7 days"}, RawRowsDeleted: 1200, BucketsWritten: 24},
+				},
+			}, nil
+		},
+	}
+	resolver := &queryResolver{&Resolver{TelemetryClient: mock}}
+
+	results, err := resolver.RetentionDryRunImpl(adminContext())
+	if err != nil {
+		t.Fatalf("RetentionDryRunImpl() error = %v", err)
+	}
+	if len(results) != 1 || results[0].RawRowsDeleted != 1200 || results[0].BucketsWritten != 24 {
+		t.Errorf("unexpected results: %+v", results)
+	}
+}
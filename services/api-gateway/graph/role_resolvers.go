@@ -0,0 +1,115 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/auth"
+	"github.com/yourusername/iot-platform/services/api-gateway/graph/model"
+	userpb "github.com/yourusername/iot-platform/shared/proto/user"
+)
+
+func protoToGraphQLRole(r *userpb.Role) *model.Role {
+	return &model.Role{
+		Name:        r.Name,
+		Description: r.Description,
+		Permissions: append([]string{}, r.Permissions...),
+		BuiltIn:     r.BuiltIn,
+		CreatedAt:   int(r.CreatedAt),
+		UpdatedAt:   int(r.UpdatedAt),
+	}
+}
+
+// RolesImpl lists the built-in and custom roles.
+func (r *queryResolver) RolesImpl(ctx context.Context) ([]*model.Role, error) {
+	resp, err := r.UserClient.ListRoles(ctx, &userpb.ListRolesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	roles := make([]*model.Role, len(resp.Roles))
+	for i, role := range resp.Roles {
+		roles[i] = protoToGraphQLRole(role)
+	}
+	return roles, nil
+}
+
+// PermissionsImpl returns the catalog of permissions roles may grant.
+func (r *queryResolver) PermissionsImpl(ctx context.Context) ([]*model.Permission, error) {
+	permissions := make([]*model.Permission, len(auth.Permissions))
+	for i, p := range auth.Permissions {
+		permissions[i] = &model.Permission{Name: p.Name, Description: p.Description, Scope: model.PermissionScopeOrganization}
+		if p.Scope == auth.ScopePlatform {
+			permissions[i].Scope = model.PermissionScopePlatform
+		}
+	}
+	return permissions, nil
+}
+
+// UpsertRoleImpl creates or updates a custom role. Permissions must be in the
+// catalog of the gateway: the User Service only checks their format.
+func (r *mutationResolver) UpsertRoleImpl(ctx context.Context, input model.RoleInput) (*model.Role, error) {
+	for _, permission := range input.Permissions {
+		if !auth.IsPermission(permission) {
+			return nil, fmt.Errorf("unknown permission %q", permission)
+		}
+	}
+
+	req := &userpb.UpsertRoleRequest{
+		Name:        input.Name,
+		Permissions: input.Permissions,
+	}
+	if input.Description != nil {
+		req.Description = *input.Description
+	}
+
+	resp, err := r.UserClient.UpsertRole(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save role: %w", err)
+	}
+
+	log.Printf("✅ Role saved: %s %v", resp.Role.Name, resp.Role.Permissions)
+	return protoToGraphQLRole(resp.Role), nil
+}
+
+// DeleteRoleImpl deletes a custom role that is no longer assigned.
+func (r *mutationResolver) DeleteRoleImpl(ctx context.Context, name string) (*model.DeleteResult, error) {
+	if _, err := r.UserClient.DeleteRole(ctx, &userpb.DeleteRoleRequest{Name: name}); err != nil {
+		return nil, fmt.Errorf("failed to delete role: %w", err)
+	}
+
+	return &model.DeleteResult{
+		Success: true,
+		Message: fmt.Sprintf("Role %s deleted", name),
+	}, nil
+}
+
+// UpdateUserRoleImpl changes the role of a member in the organization of the
+// caller. Tokens already issued keep the previous permissions until they are
+// refreshed.
+func (r *mutationResolver) UpdateUserRoleImpl(ctx context.Context, userID string, role string) (*model.User, error) {
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.UserClient.UpdateMember(ctx, &userpb.UpdateMemberRequest{
+		OrgId:  orgID,
+		UserId: userID,
+		Role:   role,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update user role: %w", err)
+	}
+
+	user, err := r.UserClient.GetUser(ctx, &userpb.GetUserRequest{Id: userID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	log.Printf("✅ Role of user %s in organization %s set to %s", userID, orgID, role)
+	result := protoToGraphQLUser(user.User)
+	result.OrgRole = &resp.Membership.Role
+	return result, nil
+}
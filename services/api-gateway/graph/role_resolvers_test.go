@@ -0,0 +1,87 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/auth"
+	"github.com/yourusername/iot-platform/services/api-gateway/graph/model"
+	userpb "github.com/yourusername/iot-platform/shared/proto/user"
+	"google.golang.org/grpc"
+)
+
+func TestUpsertRoleImpl(t *testing.T) {
+	var saved *userpb.UpsertRoleRequest
+	mock := &MockUserServiceClient{
+		UpsertRoleFunc: func(ctx context.Context, req *userpb.UpsertRoleRequest, opts ...grpc.CallOption) (*userpb.UpsertRoleResponse, error) {
+			saved = req
+			return &userpb.UpsertRoleResponse{Role: &userpb.Role{Name: req.Name, Description: req.Description, Permissions: req.Permissions}}, nil
+		},
+	}
+	r := &mutationResolver{&Resolver{UserClient: mock}}
+
+	description := "Read-only access"
+	input := model.RoleInput{Name: "viewer", Description: &description, Permissions: []string{auth.PermDevicesRead, auth.PermTelemetryRead}}
+
+	role, err := r.UpsertRoleImpl(adminContext(), input)
+	if err != nil {
+		t.Fatalf("UpsertRoleImpl failed: %v", err)
+	}
+	if role.Name != "viewer" || role.Description != description || len(role.Permissions) != 2 {
+		t.Errorf("unexpected role %+v", role)
+	}
+
+	saved = nil
+	input.Permissions = []string{"devices:delete"}
+	if _, err := r.UpsertRoleImpl(adminContext(), input); err == nil {
+		t.Error("expected an error for an unknown permission")
+	}
+	if saved != nil {
+		t.Error("role with an unknown permission should not be saved")
+	}
+}
+
+// TestUpdateUserRoleImpl tests that the role is changed in the organization
+// of the caller.
+func TestUpdateUserRoleImpl(t *testing.T) {
+	var updated *userpb.UpdateMemberRequest
+	mock := &MockUserServiceClient{
+		GetUserFunc: func(ctx context.Context, req *userpb.GetUserRequest, opts ...grpc.CallOption) (*userpb.GetUserResponse, error) {
+			return &userpb.GetUserResponse{User: testUser}, nil
+		},
+		UpdateMemberFunc: func(ctx context.Context, req *userpb.UpdateMemberRequest, opts ...grpc.CallOption) (*userpb.MemberResponse, error) {
+			updated = req
+			return &userpb.MemberResponse{Membership: &userpb.Membership{OrgId: req.OrgId, UserId: req.UserId, Role: req.Role}}, nil
+		},
+	}
+	r := &mutationResolver{&Resolver{UserClient: mock}}
+
+	ctx := auth.WithUser(context.Background(), &auth.Claims{UserID: "admin-1", Role: "user", OrgID: "org-1", Permissions: []string{auth.PermUsersAdmin}})
+	user, err := r.UpdateUserRoleImpl(ctx, "user-1", "viewer")
+	if err != nil {
+		t.Fatalf("UpdateUserRoleImpl failed: %v", err)
+	}
+	if updated.OrgId != "org-1" || updated.UserId != "user-1" || updated.Role != "viewer" {
+		t.Errorf("unexpected update request %+v", updated)
+	}
+	if user.Role != testUser.Role || user.OrgRole == nil || *user.OrgRole != "viewer" {
+		t.Errorf("unexpected user %+v", user)
+	}
+}
+
+// TestPermissionsImpl tests that the catalog tells the scope of permissions.
+func TestPermissionsImpl(t *testing.T) {
+	r := &queryResolver{&Resolver{}}
+
+	permissions, err := r.PermissionsImpl(adminContext())
+	if err != nil {
+		t.Fatalf("PermissionsImpl failed: %v", err)
+	}
+	scopes := make(map[string]model.PermissionScope, len(permissions))
+	for _, p := range permissions {
+		scopes[p.Name] = p.Scope
+	}
+	if scopes[auth.PermDevicesRead] != model.PermissionScopeOrganization || scopes[auth.PermOrgsAdmin] != model.PermissionScopePlatform {
+		t.Errorf("unexpected scopes %v", scopes)
+	}
+}
@@ -7,7 +7,6 @@ package graph
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/yourusername/iot-platform/services/api-gateway/graph/generated"
 	"github.com/yourusername/iot-platform/services/api-gateway/graph/model"
@@ -23,6 +22,81 @@ func (r *mutationResolver) Login(ctx context.Context, input model.LoginInput) (*
 	return r.LoginImpl(ctx, input)
 }
 
+// RefreshToken is the resolver for the refreshToken field.
+func (r *mutationResolver) RefreshToken(ctx context.Context, refreshToken string) (*model.AuthPayload, error) {
+	return r.RefreshTokenImpl(ctx, refreshToken)
+}
+
+// Logout is the resolver for the logout field.
+func (r *mutationResolver) Logout(ctx context.Context) (bool, error) {
+	return r.LogoutImpl(ctx)
+}
+
+// LogoutAllSessions is the resolver for the logoutAllSessions field.
+func (r *mutationResolver) LogoutAllSessions(ctx context.Context) (int, error) {
+	return r.LogoutAllSessionsImpl(ctx)
+}
+
+// RevokeUserSessions is the resolver for the revokeUserSessions field.
+func (r *mutationResolver) RevokeUserSessions(ctx context.Context, userID string) (int, error) {
+	return r.RevokeUserSessionsImpl(ctx, userID)
+}
+
+// UpdateUserRole is the resolver for the updateUserRole field.
+func (r *mutationResolver) UpdateUserRole(ctx context.Context, userID string, role string) (*model.User, error) {
+	return r.UpdateUserRoleImpl(ctx, userID, role)
+}
+
+// SwitchOrganization is the resolver for the switchOrganization field.
+func (r *mutationResolver) SwitchOrganization(ctx context.Context, organizationID string) (*model.AuthPayload, error) {
+	return r.SwitchOrganizationImpl(ctx, organizationID)
+}
+
+// CreateOrganization is the resolver for the createOrganization field.
+func (r *mutationResolver) CreateOrganization(ctx context.Context, input model.CreateOrganizationInput) (*model.Organization, error) {
+	return r.CreateOrganizationImpl(ctx, input)
+}
+
+// AddMember is the resolver for the addMember field.
+func (r *mutationResolver) AddMember(ctx context.Context, userID string, role string) (*model.Membership, error) {
+	return r.AddMemberImpl(ctx, userID, role)
+}
+
+// RemoveMember is the resolver for the removeMember field.
+func (r *mutationResolver) RemoveMember(ctx context.Context, userID string) (*model.DeleteResult, error) {
+	return r.RemoveMemberImpl(ctx, userID)
+}
+
+// CreateServiceAccount is the resolver for the createServiceAccount field.
+func (r *mutationResolver) CreateServiceAccount(ctx context.Context, input model.CreateServiceAccountInput) (*model.User, error) {
+	return r.CreateServiceAccountImpl(ctx, input)
+}
+
+// CreateAPIKey is the resolver for the createApiKey field.
+func (r *mutationResolver) CreateAPIKey(ctx context.Context, input model.CreateAPIKeyInput) (*model.APIKeyCreated, error) {
+	return r.CreateAPIKeyImpl(ctx, input)
+}
+
+// RevokeAPIKey is the resolver for the revokeApiKey field.
+func (r *mutationResolver) RevokeAPIKey(ctx context.Context, id string) (*model.DeleteResult, error) {
+	return r.RevokeAPIKeyImpl(ctx, id)
+}
+
+// UpsertRole is the resolver for the upsertRole field.
+func (r *mutationResolver) UpsertRole(ctx context.Context, input model.RoleInput) (*model.Role, error) {
+	return r.UpsertRoleImpl(ctx, input)
+}
+
+// DeleteRole is the resolver for the deleteRole field.
+func (r *mutationResolver) DeleteRole(ctx context.Context, name string) (*model.DeleteResult, error) {
+	return r.DeleteRoleImpl(ctx, name)
+}
+
+// RefreshConnectionToken is the resolver for the refreshConnectionToken field.
+func (r *mutationResolver) RefreshConnectionToken(ctx context.Context, token string) (int, error) {
+	return r.RefreshConnectionTokenImpl(ctx, token)
+}
+
 // CreateDevice is the resolver for the createDevice field.
 func (r *mutationResolver) CreateDevice(ctx context.Context, input model.CreateDeviceInput) (*model.Device, error) {
 	return r.CreateDeviceImpl(ctx, input)
@@ -38,24 +112,159 @@ func (r *mutationResolver) DeleteDevice(ctx context.Context, id string) (*model.
 	return r.DeleteDeviceImpl(ctx, id)
 }
 
+// CreateDeviceGroup is the resolver for the createDeviceGroup field.
+func (r *mutationResolver) CreateDeviceGroup(ctx context.Context, input model.CreateDeviceGroupInput) (*model.DeviceGroup, error) {
+	return r.CreateDeviceGroupImpl(ctx, input)
+}
+
+// UpdateDeviceGroup is the resolver for the updateDeviceGroup field.
+func (r *mutationResolver) UpdateDeviceGroup(ctx context.Context, input model.UpdateDeviceGroupInput) (*model.DeviceGroup, error) {
+	return r.UpdateDeviceGroupImpl(ctx, input)
+}
+
+// DeleteDeviceGroup is the resolver for the deleteDeviceGroup field.
+func (r *mutationResolver) DeleteDeviceGroup(ctx context.Context, id string) (*model.DeleteResult, error) {
+	return r.DeleteDeviceGroupImpl(ctx, id)
+}
+
+// AddDevicesToGroup is the resolver for the addDevicesToGroup field.
+func (r *mutationResolver) AddDevicesToGroup(ctx context.Context, groupID string, deviceIds []string) (*model.DeviceGroup, error) {
+	return r.AddDevicesToGroupImpl(ctx, groupID, deviceIds)
+}
+
+// RemoveDevicesFromGroup is the resolver for the removeDevicesFromGroup field.
+func (r *mutationResolver) RemoveDevicesFromGroup(ctx context.Context, groupID string, deviceIds []string) (*model.DeviceGroup, error) {
+	return r.RemoveDevicesFromGroupImpl(ctx, groupID, deviceIds)
+}
+
+// GrantDeviceAccess is the resolver for the grantDeviceAccess field.
+func (r *mutationResolver) GrantDeviceAccess(ctx context.Context, input model.GrantDeviceAccessInput) (*model.AccessEntry, error) {
+	return r.GrantDeviceAccessImpl(ctx, input)
+}
+
+// RevokeDeviceAccess is the resolver for the revokeDeviceAccess field.
+func (r *mutationResolver) RevokeDeviceAccess(ctx context.Context, id string) (*model.DeleteResult, error) {
+	return r.RevokeDeviceAccessImpl(ctx, id)
+}
+
+// CreateLocation is the resolver for the createLocation field.
+func (r *mutationResolver) CreateLocation(ctx context.Context, input model.CreateLocationInput) (*model.Location, error) {
+	return r.CreateLocationImpl(ctx, input)
+}
+
+// UpdateLocation is the resolver for the updateLocation field.
+func (r *mutationResolver) UpdateLocation(ctx context.Context, id string, name string) (*model.Location, error) {
+	return r.UpdateLocationImpl(ctx, id, name)
+}
+
+// DeleteLocation is the resolver for the deleteLocation field.
+func (r *mutationResolver) DeleteLocation(ctx context.Context, id string) (*model.DeleteResult, error) {
+	return r.DeleteLocationImpl(ctx, id)
+}
+
+// UpsertRetentionPolicy is the resolver for the upsertRetentionPolicy field.
+func (r *mutationResolver) UpsertRetentionPolicy(ctx context.Context, input model.RetentionPolicyInput) (*model.RetentionPolicy, error) {
+	return r.UpsertRetentionPolicyImpl(ctx, input)
+}
+
+// DeleteRetentionPolicy is the resolver for the deleteRetentionPolicy field.
+func (r *mutationResolver) DeleteRetentionPolicy(ctx context.Context, id string) (*model.DeleteResult, error) {
+	return r.DeleteRetentionPolicyImpl(ctx, id)
+}
+
+// ApplyRetention is the resolver for the applyRetention field.
+func (r *mutationResolver) ApplyRetention(ctx context.Context) ([]*model.RetentionPolicyResult, error) {
+	return r.ApplyRetentionImpl(ctx)
+}
+
+// UpsertDerivedMetric is the resolver for the upsertDerivedMetric field.
+func (r *mutationResolver) UpsertDerivedMetric(ctx context.Context, input model.DerivedMetricInput) (*model.DerivedMetric, error) {
+	return r.UpsertDerivedMetricImpl(ctx, input)
+}
+
+// DeleteDerivedMetric is the resolver for the deleteDerivedMetric field.
+func (r *mutationResolver) DeleteDerivedMetric(ctx context.Context, id string) (*model.DeleteResult, error) {
+	return r.DeleteDerivedMetricImpl(ctx, id)
+}
+
+// UpsertDeviceType is the resolver for the upsertDeviceType field.
+func (r *mutationResolver) UpsertDeviceType(ctx context.Context, input model.DeviceTypeInput) (*model.DeviceType, error) {
+	return r.UpsertDeviceTypeImpl(ctx, input)
+}
+
+// DeleteDeviceType is the resolver for the deleteDeviceType field.
+func (r *mutationResolver) DeleteDeviceType(ctx context.Context, name string) (*model.DeleteResult, error) {
+	return r.DeleteDeviceTypeImpl(ctx, name)
+}
+
 // Me is the resolver for the me field.
 func (r *queryResolver) Me(ctx context.Context) (*model.User, error) {
 	return r.MeImpl(ctx)
 }
 
+// Sessions is the resolver for the sessions field.
+func (r *queryResolver) Sessions(ctx context.Context) ([]*model.Session, error) {
+	return r.SessionsImpl(ctx)
+}
+
+// Organizations is the resolver for the organizations field.
+func (r *queryResolver) Organizations(ctx context.Context) ([]*model.Organization, error) {
+	return r.OrganizationsImpl(ctx)
+}
+
 // Users is the resolver for the users field.
 func (r *queryResolver) Users(ctx context.Context, page *int, pageSize *int, role *string) (*model.UserConnection, error) {
 	return r.UsersImpl(ctx, page, pageSize, role)
 }
 
+// Roles is the resolver for the roles field.
+func (r *queryResolver) Roles(ctx context.Context) ([]*model.Role, error) {
+	return r.RolesImpl(ctx)
+}
+
+// Permissions is the resolver for the permissions field.
+func (r *queryResolver) Permissions(ctx context.Context) ([]*model.Permission, error) {
+	return r.PermissionsImpl(ctx)
+}
+
+// APIKeys is the resolver for the apiKeys field.
+func (r *queryResolver) APIKeys(ctx context.Context, userID *string) ([]*model.APIKey, error) {
+	return r.APIKeysImpl(ctx, userID)
+}
+
 // Device is the resolver for the device field.
 func (r *queryResolver) Device(ctx context.Context, id string) (*model.Device, error) {
 	return r.DeviceImpl(ctx, id)
 }
 
 // Devices is the resolver for the devices field.
-func (r *queryResolver) Devices(ctx context.Context, page *int, pageSize *int, typeArg *string, status *model.DeviceStatus) (*model.DeviceConnection, error) {
-	return r.DevicesImpl(ctx, page, pageSize, typeArg, status)
+func (r *queryResolver) Devices(ctx context.Context, page *int, pageSize *int, typeArg *string, status *model.DeviceStatus, groupID *string, locationID *string) (*model.DeviceConnection, error) {
+	return r.DevicesImpl(ctx, page, pageSize, typeArg, status, groupID, locationID)
+}
+
+// DeviceGroups is the resolver for the deviceGroups field.
+func (r *queryResolver) DeviceGroups(ctx context.Context, deviceID *string) ([]*model.DeviceGroup, error) {
+	return r.DeviceGroupsImpl(ctx, deviceID)
+}
+
+// DeviceGroup is the resolver for the deviceGroup field.
+func (r *queryResolver) DeviceGroup(ctx context.Context, id string) (*model.DeviceGroup, error) {
+	return r.DeviceGroupImpl(ctx, id)
+}
+
+// AccessEntries is the resolver for the accessEntries field.
+func (r *queryResolver) AccessEntries(ctx context.Context, userID *string, role *string, deviceID *string, groupID *string) ([]*model.AccessEntry, error) {
+	return r.AccessEntriesImpl(ctx, userID, role, deviceID, groupID)
+}
+
+// Locations is the resolver for the locations field.
+func (r *queryResolver) Locations(ctx context.Context, parentID *string) ([]*model.Location, error) {
+	return r.LocationsImpl(ctx, parentID)
+}
+
+// Location is the resolver for the location field.
+func (r *queryResolver) Location(ctx context.Context, id string) (*model.Location, error) {
+	return r.LocationImpl(ctx, id)
 }
 
 // Stats is the resolver for the stats field.
@@ -63,19 +272,24 @@ func (r *queryResolver) Stats(ctx context.Context) (*model.Stats, error) {
 	return r.StatsImpl(ctx)
 }
 
+// SubscriptionStats is the resolver for the subscriptionStats field.
+func (r *queryResolver) SubscriptionStats(ctx context.Context) (*model.SubscriptionStats, error) {
+	return r.SubscriptionStatsImpl(ctx)
+}
+
 // DeviceTelemetry is the resolver for the deviceTelemetry field.
-func (r *queryResolver) DeviceTelemetry(ctx context.Context, deviceID string, metricName string, from int, to int, limit *int) (*model.TelemetrySeries, error) {
-	return r.DeviceTelemetryImpl(ctx, deviceID, metricName, from, to, limit)
+func (r *queryResolver) DeviceTelemetry(ctx context.Context, deviceID string, metricName string, from int, to int, limit *int, unit *string) (*model.TelemetrySeries, error) {
+	return r.DeviceTelemetryImpl(ctx, deviceID, metricName, from, to, limit, unit)
 }
 
 // DeviceTelemetryAggregated is the resolver for the deviceTelemetryAggregated field.
-func (r *queryResolver) DeviceTelemetryAggregated(ctx context.Context, deviceID string, metricName string, from int, to int, interval string) ([]*model.TelemetryAggregation, error) {
-	return r.DeviceTelemetryAggregatedImpl(ctx, deviceID, metricName, from, to, interval)
+func (r *queryResolver) DeviceTelemetryAggregated(ctx context.Context, deviceID string, metricName string, from int, to int, interval string, unit *string) ([]*model.TelemetryAggregation, error) {
+	return r.DeviceTelemetryAggregatedImpl(ctx, deviceID, metricName, from, to, interval, unit)
 }
 
 // DeviceLatestMetric is the resolver for the deviceLatestMetric field.
-func (r *queryResolver) DeviceLatestMetric(ctx context.Context, deviceID string, metricName string) (*model.TelemetryPoint, error) {
-	return r.DeviceLatestMetricImpl(ctx, deviceID, metricName)
+func (r *queryResolver) DeviceLatestMetric(ctx context.Context, deviceID string, metricName string, unit *string) (*model.TelemetryPoint, error) {
+	return r.DeviceLatestMetricImpl(ctx, deviceID, metricName, unit)
 }
 
 // DeviceMetrics is the resolver for the deviceMetrics field.
@@ -83,23 +297,59 @@ func (r *queryResolver) DeviceMetrics(ctx context.Context, deviceID string) ([]s
 	return r.DeviceMetricsImpl(ctx, deviceID)
 }
 
+// DeviceMetricCatalog is the resolver for the deviceMetricCatalog field.
+func (r *queryResolver) DeviceMetricCatalog(ctx context.Context, deviceID string) ([]*model.MetricInfo, error) {
+	return r.DeviceMetricCatalogImpl(ctx, deviceID)
+}
+
+// DeviceTypes is the resolver for the deviceTypes field.
+func (r *queryResolver) DeviceTypes(ctx context.Context) ([]*model.DeviceType, error) {
+	return r.DeviceTypesImpl(ctx)
+}
+
+// DeviceType is the resolver for the deviceType field.
+func (r *queryResolver) DeviceType(ctx context.Context, name string) (*model.DeviceType, error) {
+	return r.DeviceTypeImpl(ctx, name)
+}
+
+// TelemetryBatch is the resolver for the telemetryBatch field.
+func (r *queryResolver) TelemetryBatch(ctx context.Context, input model.TelemetryBatchInput) ([]*model.TelemetryBatchSeries, error) {
+	return r.TelemetryBatchImpl(ctx, input)
+}
+
+// Anomalies is the resolver for the anomalies field.
+func (r *queryResolver) Anomalies(ctx context.Context, deviceID *string, metricName *string, from int, to int, minScore *float64, limit *int) ([]*model.Anomaly, error) {
+	return r.AnomaliesImpl(ctx, deviceID, metricName, from, to, minScore, limit)
+}
+
+// RetentionPolicies is the resolver for the retentionPolicies field.
+func (r *queryResolver) RetentionPolicies(ctx context.Context) ([]*model.RetentionPolicy, error) {
+	return r.RetentionPoliciesImpl(ctx)
+}
+
+// RetentionDryRun is the resolver for the retentionDryRun field.
+func (r *queryResolver) RetentionDryRun(ctx context.Context) ([]*model.RetentionPolicyResult, error) {
+	return r.RetentionDryRunImpl(ctx)
+}
+
+// DerivedMetrics is the resolver for the derivedMetrics field.
+func (r *queryResolver) DerivedMetrics(ctx context.Context, deviceType *string) ([]*model.DerivedMetric, error) {
+	return r.DerivedMetricsImpl(ctx, deviceType)
+}
+
 // DeviceUpdated is the resolver for the deviceUpdated field.
 func (r *subscriptionResolver) DeviceUpdated(ctx context.Context) (<-chan *model.Device, error) {
-	panic(fmt.Errorf("not implemented: DeviceUpdated - deviceUpdated"))
+	return r.DeviceUpdatedImpl(ctx)
 }
 
 // TelemetryReceived is the resolver for the telemetryReceived field.
-func (r *subscriptionResolver) TelemetryReceived(ctx context.Context, deviceID string) (<-chan *model.TelemetryPoint, error) {
-	// Subscribe to telemetry updates for this device
-	ch := r.Broker.Subscribe(deviceID)
-
-	// Cleanup when context is done (client disconnects)
-	go func() {
-		<-ctx.Done()
-		r.Broker.Unsubscribe(deviceID, ch)
-	}()
+func (r *subscriptionResolver) TelemetryReceived(ctx context.Context, deviceID string, lastEventID *string, overflow *model.OverflowPolicy) (<-chan *model.TelemetryPoint, error) {
+	return r.TelemetryReceivedImpl(ctx, deviceID, lastEventID, overflow)
+}
 
-	return ch, nil
+// Telemetry is the resolver for the telemetry field.
+func (r *subscriptionResolver) Telemetry(ctx context.Context, filter model.TelemetryFilter) (<-chan *model.TelemetryPoint, error) {
+	return r.TelemetryImpl(ctx, filter)
 }
 
 // Mutation returns generated.MutationResolver implementation.
@@ -2,27 +2,49 @@ package graph
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"sort"
+	"strings"
+	"time"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/auth"
 	"github.com/yourusername/iot-platform/services/api-gateway/graph/model"
+	"github.com/yourusername/iot-platform/services/api-gateway/pubsub"
+	"github.com/yourusername/iot-platform/shared/eventbus"
 	telemetrypb "github.com/yourusername/iot-platform/shared/proto/telemetry"
 )
 
-// DeviceTelemetryImpl retrieves raw telemetry data for a device.
-func (r *queryResolver) DeviceTelemetryImpl(ctx context.Context, deviceID string, metricName string, from int, to int, limit *int) (*model.TelemetrySeries, error) {
+// DeviceTelemetryImpl retrieves raw telemetry data for a device, converted to
+// unit when it is set.
+func (r *queryResolver) DeviceTelemetryImpl(ctx context.Context, deviceID string, metricName string, from int, to int, limit *int, unit *string) (*model.TelemetrySeries, error) {
 	log.Printf("📊 Query deviceTelemetry: device=%s, metric=%s", deviceID, metricName)
+	if err := r.authorizeDevice(ctx, deviceID, auth.PermTelemetryRead); err != nil {
+		return nil, err
+	}
 
 	limitValue := int32(1000)
 	if limit != nil {
 		limitValue = int32(*limit)
 	}
 
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	resp, err := r.TelemetryClient.GetTelemetry(ctx, &telemetrypb.GetTelemetryRequest{
+		OrgId:      orgID,
 		DeviceId:   deviceID,
 		MetricName: metricName,
 		FromTime:   int64(from),
 		ToTime:     int64(to),
 		Limit:      limitValue,
+		Unit:       stringPtrToValue(unit),
 	})
 	if err != nil {
 		log.Printf("❌ Failed to get telemetry: %v", err)
@@ -45,15 +67,25 @@ func (r *queryResolver) DeviceTelemetryImpl(ctx context.Context, deviceID string
 }
 
 // DeviceTelemetryAggregatedImpl retrieves aggregated telemetry data.
-func (r *queryResolver) DeviceTelemetryAggregatedImpl(ctx context.Context, deviceID string, metricName string, from int, to int, interval string) ([]*model.TelemetryAggregation, error) {
+func (r *queryResolver) DeviceTelemetryAggregatedImpl(ctx context.Context, deviceID string, metricName string, from int, to int, interval string, unit *string) ([]*model.TelemetryAggregation, error) {
 	log.Printf("📊 Query deviceTelemetryAggregated: device=%s, metric=%s, interval=%s", deviceID, metricName, interval)
+	if err := r.authorizeDevice(ctx, deviceID, auth.PermTelemetryRead); err != nil {
+		return nil, err
+	}
+
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	resp, err := r.TelemetryClient.GetTelemetryAggregated(ctx, &telemetrypb.GetTelemetryAggregatedRequest{
+		OrgId:      orgID,
 		DeviceId:   deviceID,
 		MetricName: metricName,
 		FromTime:   int64(from),
 		ToTime:     int64(to),
 		Interval:   interval,
+		Unit:       stringPtrToValue(unit),
 	})
 	if err != nil {
 		log.Printf("❌ Failed to get aggregated telemetry: %v", err)
@@ -75,15 +107,30 @@ func (r *queryResolver) DeviceTelemetryAggregatedImpl(ctx context.Context, devic
 }
 
 // DeviceLatestMetricImpl retrieves the latest value for a specific metric.
-func (r *queryResolver) DeviceLatestMetricImpl(ctx context.Context, deviceID string, metricName string) (*model.TelemetryPoint, error) {
+// An unknown or incompatible target unit is reported as an error.
+func (r *queryResolver) DeviceLatestMetricImpl(ctx context.Context, deviceID string, metricName string, unit *string) (*model.TelemetryPoint, error) {
 	log.Printf("📊 Query deviceLatestMetric: device=%s, metric=%s", deviceID, metricName)
+	if err := r.authorizeDevice(ctx, deviceID, auth.PermTelemetryRead); err != nil {
+		return nil, err
+	}
+
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	resp, err := r.TelemetryClient.GetLatestMetric(ctx, &telemetrypb.GetLatestMetricRequest{
+		OrgId:      orgID,
 		DeviceId:   deviceID,
 		MetricName: metricName,
+		Unit:       stringPtrToValue(unit),
 	})
 	if err != nil {
 		log.Printf("❌ Failed to get latest metric: %v", err)
+		switch status.Code(err) {
+		case codes.InvalidArgument, codes.FailedPrecondition:
+			return nil, err
+		}
 		return nil, nil // Return nil instead of error for optional field
 	}
 
@@ -101,8 +148,17 @@ func (r *queryResolver) DeviceLatestMetricImpl(ctx context.Context, deviceID str
 // DeviceMetricsImpl retrieves all available metrics for a device.
 func (r *queryResolver) DeviceMetricsImpl(ctx context.Context, deviceID string) ([]string, error) {
 	log.Printf("📊 Query deviceMetrics: device=%s", deviceID)
+	if err := r.authorizeDevice(ctx, deviceID, auth.PermTelemetryRead); err != nil {
+		return nil, err
+	}
+
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	resp, err := r.TelemetryClient.GetDeviceMetrics(ctx, &telemetrypb.GetDeviceMetricsRequest{
+		OrgId:    orgID,
 		DeviceId: deviceID,
 	})
 	if err != nil {
@@ -112,3 +168,399 @@ func (r *queryResolver) DeviceMetricsImpl(ctx context.Context, deviceID string)
 
 	return resp.Metrics, nil
 }
+
+// TelemetryBatchImpl retrieves aligned aggregated series for several devices and metrics.
+func (r *queryResolver) TelemetryBatchImpl(ctx context.Context, input model.TelemetryBatchInput) ([]*model.TelemetryBatchSeries, error) {
+	log.Printf("📊 Query telemetryBatch: devices=%d, metrics=%v, interval=%s", len(input.DeviceIds), input.MetricNames, input.Interval)
+	// Type, metadata, group and location filters match devices the user may
+	// not list
+	if len(input.DeviceIds) == 0 || input.DeviceType != nil || input.Metadata != nil ||
+		input.GroupIds != nil || input.LocationID != nil {
+		if err := r.authorizeAllDevices(ctx, auth.PermTelemetryRead); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.authorizeDevices(ctx, input.DeviceIds, auth.PermTelemetryRead); err != nil {
+		return nil, err
+	}
+
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &telemetrypb.GetTelemetryBatchRequest{
+		OrgId:       orgID,
+		DeviceIds:   input.DeviceIds,
+		MetricNames: input.MetricNames,
+		FromTime:    int64(input.From),
+		ToTime:      int64(input.To),
+		Interval:    input.Interval,
+		GroupBy:     graphQLToProtoGroupBy(input.GroupBy),
+		Unit:        stringPtrToValue(input.Unit),
+	}
+	if input.LocationKind != nil {
+		req.LocationKind = strings.ToLower(string(*input.LocationKind))
+	}
+	if input.DeviceType != nil || input.Metadata != nil || input.GroupIds != nil || input.LocationID != nil {
+		req.Filter = &telemetrypb.DeviceFilter{
+			Type:       stringPtrToValue(input.DeviceType),
+			Metadata:   make(map[string]string, len(input.Metadata)),
+			GroupIds:   input.GroupIds,
+			LocationId: stringPtrToValue(input.LocationID),
+		}
+		for _, kv := range input.Metadata {
+			req.Filter.Metadata[kv.Key] = kv.Value
+		}
+	}
+
+	resp, err := r.TelemetryClient.GetTelemetryBatch(ctx, req)
+	if err != nil {
+		log.Printf("❌ Failed to get telemetry batch: %v", err)
+		return nil, err
+	}
+
+	series := make([]*model.TelemetryBatchSeries, len(resp.Series))
+	for i, s := range resp.Series {
+		aggregations := make([]*model.TelemetryAggregation, len(s.Aggregations))
+		for j, a := range s.Aggregations {
+			aggregations[j] = &model.TelemetryAggregation{
+				Bucket: a.Bucket,
+				Avg:    a.Avg,
+				Min:    a.Min,
+				Max:    a.Max,
+				Count:  int(a.Count),
+			}
+		}
+
+		series[i] = &model.TelemetryBatchSeries{
+			MetricName:   s.MetricName,
+			Aggregations: aggregations,
+		}
+		if s.DeviceId != "" {
+			series[i].DeviceID = &s.DeviceId
+		}
+		if s.DeviceType != "" {
+			series[i].DeviceType = &s.DeviceType
+		}
+		if s.GroupId != "" {
+			series[i].GroupID = &s.GroupId
+		}
+		if s.LocationId != "" {
+			series[i].LocationID = &s.LocationId
+		}
+	}
+
+	return series, nil
+}
+
+func graphQLToProtoGroupBy(g *model.TelemetryGroupBy) telemetrypb.TelemetryGroupBy {
+	if g == nil {
+		return telemetrypb.TelemetryGroupBy_GROUP_BY_DEVICE
+	}
+
+	switch *g {
+	case model.TelemetryGroupByDeviceType:
+		return telemetrypb.TelemetryGroupBy_GROUP_BY_DEVICE_TYPE
+	case model.TelemetryGroupByAll:
+		return telemetrypb.TelemetryGroupBy_GROUP_BY_ALL
+	case model.TelemetryGroupByGroup:
+		return telemetrypb.TelemetryGroupBy_GROUP_BY_GROUP
+	case model.TelemetryGroupByLocation:
+		return telemetrypb.TelemetryGroupBy_GROUP_BY_LOCATION
+	default:
+		return telemetrypb.TelemetryGroupBy_GROUP_BY_DEVICE
+	}
+}
+
+// AnomaliesImpl retrieves anomalies detected at ingest time.
+func (r *queryResolver) AnomaliesImpl(ctx context.Context, deviceID *string, metricName *string, from int, to int, minScore *float64, limit *int) ([]*model.Anomaly, error) {
+	log.Printf("📊 Query anomalies: device=%s, metric=%s", stringPtrToValue(deviceID), stringPtrToValue(metricName))
+	if deviceID != nil {
+		if err := r.authorizeDevice(ctx, *deviceID, auth.PermTelemetryRead); err != nil {
+			return nil, err
+		}
+	} else if err := r.authorizeAllDevices(ctx, auth.PermTelemetryRead); err != nil {
+		return nil, err
+	}
+
+	orgID, err := organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &telemetrypb.GetAnomaliesRequest{
+		OrgId:      orgID,
+		DeviceId:   stringPtrToValue(deviceID),
+		MetricName: stringPtrToValue(metricName),
+		FromTime:   int64(from),
+		ToTime:     int64(to),
+		Limit:      100,
+	}
+	if minScore != nil {
+		req.MinScore = *minScore
+	}
+	if limit != nil {
+		req.Limit = int32(*limit)
+	}
+
+	resp, err := r.TelemetryClient.GetAnomalies(ctx, req)
+	if err != nil {
+		log.Printf("❌ Failed to get anomalies: %v", err)
+		return nil, err
+	}
+
+	anomalies := make([]*model.Anomaly, len(resp.Anomalies))
+	for i, a := range resp.Anomalies {
+		anomalies[i] = &model.Anomaly{
+			DeviceID:   a.DeviceId,
+			MetricName: a.MetricName,
+			Time:       int(a.Time),
+			Value:      a.Value,
+			Expected:   a.Expected,
+			Score:      a.Score,
+			Method:     a.Method,
+		}
+	}
+	return anomalies, nil
+}
+
+// TelemetryReceivedImpl streams the live telemetry of a device. With
+// lastEventID, the events published since that event are replayed from the
+// event bus first. The live subscription is opened before the replay and
+// live events already replayed are skipped, so that the switch loses no point.
+// Without lastEventID, the SSE Last-Event-ID header of a reconnecting client
+// is used when the event bus keeps history.
+func (r *subscriptionResolver) TelemetryReceivedImpl(ctx context.Context, deviceID string, lastEventID *string, overflow *model.OverflowPolicy) (<-chan *model.TelemetryPoint, error) {
+	if err := r.authorizeDevice(ctx, deviceID, auth.PermTelemetryRead); err != nil {
+		return nil, err
+	}
+	if err := r.checkDeviceOrganization(ctx, deviceID); err != nil {
+		return nil, err
+	}
+	if lastEventID == nil {
+		if id, ok := pubsub.LastEventID(ctx); ok && r.Replayer != nil && eventbus.ValidID(id) {
+			lastEventID = &id
+		}
+	}
+
+	// Subscribe to telemetry updates for this device
+	sub := r.Broker.Subscribe(pubsub.Filter{DeviceIDs: []string{deviceID}, Overflow: overflowPolicy(overflow)})
+	pubsub.Track(ctx, sub)
+	live := sub.C
+
+	if lastEventID == nil || *lastEventID == "" {
+		go r.unsubscribeOnDone(ctx, sub)
+		return live, nil
+	}
+
+	if r.Replayer == nil {
+		r.Broker.Unsubscribe(sub)
+		return nil, errors.New("lastEventId requires an event bus transport with history")
+	}
+	if !eventbus.ValidID(*lastEventID) {
+		r.Broker.Unsubscribe(sub)
+		return nil, errors.New("invalid lastEventId")
+	}
+	log.Printf("📡 Subscription telemetryReceived: device=%s, resuming after %s", deviceID, *lastEventID)
+
+	out := make(chan *model.TelemetryPoint, 10)
+	go func() {
+		defer close(out)
+		defer r.Broker.Unsubscribe(sub)
+
+		last := *lastEventID
+		send := func(point *model.TelemetryPoint) error {
+			select {
+			case out <- point:
+				if point.EventID != nil {
+					last = *point.EventID
+				}
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		err := r.Replayer.Replay(ctx, deviceID, last, send)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			// Keep streaming live events: the client only misses the gap
+			log.Printf("⚠️ Failed to replay telemetry for %s: %v", deviceID, err)
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sub.Done():
+				log.Printf("⚠️ Subscription disconnected: client too slow")
+				return
+			case point, ok := <-live:
+				if !ok {
+					return
+				}
+				if point.EventID != nil {
+					if order, ok := eventbus.CompareIDs(*point.EventID, last); ok && order <= 0 {
+						continue
+					}
+				}
+				if send(point) != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// maxFilterDevices caps the devices a telemetry subscription lists by ID
+const maxFilterDevices = 100
+
+// TelemetryImpl streams the live telemetry of several devices, selected by ID
+// or by type and metadata, filtered by metric and value and throttled per
+// device and metric.
+func (r *subscriptionResolver) TelemetryImpl(ctx context.Context, filter model.TelemetryFilter) (<-chan *model.TelemetryPoint, error) {
+	f, err := telemetryFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	// Type and metadata filters match devices the user may not list, and
+	// only those of the organization
+	if len(f.DeviceIDs) == 0 {
+		if err := r.authorizeAllDevices(ctx, auth.PermTelemetryRead); err != nil {
+			return nil, err
+		}
+		if f.OrgID, err = organization(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if err := r.authorizeDevices(ctx, f.DeviceIDs, auth.PermTelemetryRead); err != nil {
+		return nil, err
+	}
+	for _, deviceID := range f.DeviceIDs {
+		if err := r.checkDeviceOrganization(ctx, deviceID); err != nil {
+			return nil, err
+		}
+	}
+	log.Printf("📡 Subscription telemetry: devices=%v, type=%s, metadata=%v, metrics=%v", f.DeviceIDs, f.DeviceType, f.Metadata, f.MetricNames)
+
+	sub := r.Broker.Subscribe(f)
+	pubsub.Track(ctx, sub)
+	go r.unsubscribeOnDone(ctx, sub)
+
+	return sub.C, nil
+}
+
+// unsubscribeOnDone removes sub when the client disconnects or falls behind
+// with the disconnect policy. Closing sub.C then ends the GraphQL subscription.
+func (r *subscriptionResolver) unsubscribeOnDone(ctx context.Context, sub *pubsub.Subscription) {
+	select {
+	case <-ctx.Done():
+	case <-sub.Done():
+		log.Printf("⚠️ Subscription disconnected: client too slow")
+	}
+	r.Broker.Unsubscribe(sub)
+}
+
+// overflowPolicy converts a GraphQL overflow policy, nil for the broker default
+func overflowPolicy(policy *model.OverflowPolicy) pubsub.OverflowPolicy {
+	if policy == nil {
+		return ""
+	}
+	switch *policy {
+	case model.OverflowPolicyDropOldest:
+		return pubsub.OverflowDropOldest
+	case model.OverflowPolicyDropNewest:
+		return pubsub.OverflowDropNewest
+	case model.OverflowPolicyCoalesce:
+		return pubsub.OverflowCoalesce
+	case model.OverflowPolicyDisconnect:
+		return pubsub.OverflowDisconnect
+	default:
+		return ""
+	}
+}
+
+// telemetryFilter validates a GraphQL telemetry filter and converts it to a
+// broker filter.
+func telemetryFilter(filter model.TelemetryFilter) (pubsub.Filter, error) {
+	f := pubsub.Filter{
+		DeviceIDs:   filter.DeviceIds,
+		DeviceType:  stringPtrToValue(filter.DeviceType),
+		MetricNames: filter.MetricNames,
+		Overflow:    overflowPolicy(filter.Overflow),
+	}
+	if len(filter.Metadata) > 0 {
+		f.Metadata = make(map[string]string, len(filter.Metadata))
+		for _, entry := range filter.Metadata {
+			f.Metadata[entry.Key] = entry.Value
+		}
+	}
+
+	switch {
+	case len(f.DeviceIDs) > 0 && (f.DeviceType != "" || len(f.Metadata) > 0):
+		return f, errors.New("deviceIds cannot be combined with deviceType or metadata")
+	case len(f.DeviceIDs) == 0 && f.DeviceType == "" && len(f.Metadata) == 0:
+		return f, errors.New("filter requires deviceIds, deviceType or metadata")
+	case len(f.DeviceIDs) > maxFilterDevices:
+		return f, fmt.Errorf("at most %d deviceIds", maxFilterDevices)
+	}
+
+	if filter.Value != nil {
+		f.Value = &pubsub.Predicate{Gt: filter.Value.Gt, Gte: filter.Value.Gte, Lt: filter.Value.Lt, Lte: filter.Value.Lte}
+	}
+	if filter.MinIntervalMs != nil {
+		if *filter.MinIntervalMs < 0 {
+			return f, errors.New("minIntervalMs must not be negative")
+		}
+		f.MinInterval = time.Duration(*filter.MinIntervalMs) * time.Millisecond
+	}
+	return f, nil
+}
+
+// SubscriptionStatsImpl returns the active subscriptions of all the gateway
+// replicas, as last published on the event bus.
+func (r *queryResolver) SubscriptionStatsImpl(ctx context.Context) (*model.SubscriptionStats, error) {
+	if r.Cluster == nil {
+		return nil, errors.New("subscription stats require the event bus")
+	}
+
+	stats := &model.SubscriptionStats{}
+	devices := make(map[string]*model.DeviceSubscriptionCount)
+	for _, replica := range r.Cluster.Replicas() {
+		stats.Total += replica.Subscriptions
+		stats.Replicas = append(stats.Replicas, &model.ReplicaSubscriptionStats{
+			Replica:       replica.Replica,
+			Subscriptions: replica.Subscriptions,
+			Wildcards:     replica.Wildcards,
+			Devices:       len(replica.Devices),
+			UpdatedAt:     int(replica.UpdatedAt.Unix()),
+		})
+		for deviceID, count := range replica.Devices {
+			device, ok := devices[deviceID]
+			if !ok {
+				device = &model.DeviceSubscriptionCount{DeviceID: deviceID}
+				devices[deviceID] = device
+			}
+			device.Subscriptions += count
+			device.Replicas++
+		}
+	}
+
+	stats.Devices = make([]*model.DeviceSubscriptionCount, 0, len(devices))
+	for _, device := range devices {
+		stats.Devices = append(stats.Devices, device)
+	}
+	sort.Slice(stats.Devices, func(i, j int) bool {
+		a, b := stats.Devices[i], stats.Devices[j]
+		if a.Subscriptions != b.Subscriptions {
+			return a.Subscriptions > b.Subscriptions
+		}
+		return a.DeviceID < b.DeviceID
+	})
+	return stats, nil
+}
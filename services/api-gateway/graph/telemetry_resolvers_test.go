@@ -0,0 +1,644 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/auth"
+	"github.com/yourusername/iot-platform/services/api-gateway/graph/model"
+	"github.com/yourusername/iot-platform/services/api-gateway/pubsub"
+	"github.com/yourusername/iot-platform/shared/eventbus"
+	telemetrypb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MockTelemetryServiceClient is a mock implementation of telemetrypb.TelemetryServiceClient for testing.
+type MockTelemetryServiceClient struct {
+	telemetrypb.TelemetryServiceClient
+
+	// Mock function implementations
+	GetTelemetryBatchFunc     func(ctx context.Context, req *telemetrypb.GetTelemetryBatchRequest, opts ...grpc.CallOption) (*telemetrypb.GetTelemetryBatchResponse, error)
+	ListRetentionPoliciesFunc func(ctx context.Context, req *telemetrypb.ListRetentionPoliciesRequest, opts ...grpc.CallOption) (*telemetrypb.ListRetentionPoliciesResponse, error)
+	UpsertRetentionPolicyFunc func(ctx context.Context, req *telemetrypb.UpsertRetentionPolicyRequest, opts ...grpc.CallOption) (*telemetrypb.RetentionPolicy, error)
+	ApplyRetentionFunc        func(ctx context.Context, req *telemetrypb.ApplyRetentionRequest, opts ...grpc.CallOption) (*telemetrypb.ApplyRetentionResponse, error)
+	ListDerivedMetricsFunc    func(ctx context.Context, req *telemetrypb.ListDerivedMetricsRequest, opts ...grpc.CallOption) (*telemetrypb.ListDerivedMetricsResponse, error)
+	UpsertDerivedMetricFunc   func(ctx context.Context, req *telemetrypb.UpsertDerivedMetricRequest, opts ...grpc.CallOption) (*telemetrypb.DerivedMetric, error)
+	GetAnomaliesFunc          func(ctx context.Context, req *telemetrypb.GetAnomaliesRequest, opts ...grpc.CallOption) (*telemetrypb.GetAnomaliesResponse, error)
+	GetDeviceMetricsFunc      func(ctx context.Context, req *telemetrypb.GetDeviceMetricsRequest, opts ...grpc.CallOption) (*telemetrypb.GetDeviceMetricsResponse, error)
+	GetLatestMetricFunc       func(ctx context.Context, req *telemetrypb.GetLatestMetricRequest, opts ...grpc.CallOption) (*telemetrypb.GetLatestMetricResponse, error)
+}
+
+func (m *MockTelemetryServiceClient) GetTelemetryBatch(ctx context.Context, req *telemetrypb.GetTelemetryBatchRequest, opts ...grpc.CallOption) (*telemetrypb.GetTelemetryBatchResponse, error) {
+	if m.GetTelemetryBatchFunc != nil {
+		return m.GetTelemetryBatchFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("GetTelemetryBatchFunc not implemented")
+}
+
+func (m *MockTelemetryServiceClient) ListRetentionPolicies(ctx context.Context, req *telemetrypb.ListRetentionPoliciesRequest, opts ...grpc.CallOption) (*telemetrypb.ListRetentionPoliciesResponse, error) {
+	if m.ListRetentionPoliciesFunc != nil {
+		return m.ListRetentionPoliciesFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("ListRetentionPoliciesFunc not implemented")
+}
+
+func (m *MockTelemetryServiceClient) UpsertRetentionPolicy(ctx context.Context, req *telemetrypb.UpsertRetentionPolicyRequest, opts ...grpc.CallOption) (*telemetrypb.RetentionPolicy, error) {
+	if m.UpsertRetentionPolicyFunc != nil {
+		return m.UpsertRetentionPolicyFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("UpsertRetentionPolicyFunc not implemented")
+}
+
+func (m *MockTelemetryServiceClient) ApplyRetention(ctx context.Context, req *telemetrypb.ApplyRetentionRequest, opts ...grpc.CallOption) (*telemetrypb.ApplyRetentionResponse, error) {
+	if m.ApplyRetentionFunc != nil {
+		return m.ApplyRetentionFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("ApplyRetentionFunc not implemented")
+}
+
+func (m *MockTelemetryServiceClient) ListDerivedMetrics(ctx context.Context, req *telemetrypb.ListDerivedMetricsRequest, opts ...grpc.CallOption) (*telemetrypb.ListDerivedMetricsResponse, error) {
+	if m.ListDerivedMetricsFunc != nil {
+		return m.ListDerivedMetricsFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("ListDerivedMetricsFunc not implemented")
+}
+
+func (m *MockTelemetryServiceClient) UpsertDerivedMetric(ctx context.Context, req *telemetrypb.UpsertDerivedMetricRequest, opts ...grpc.CallOption) (*telemetrypb.DerivedMetric, error) {
+	if m.UpsertDerivedMetricFunc != nil {
+		return m.UpsertDerivedMetricFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("UpsertDerivedMetricFunc not implemented")
+}
+
+func (m *MockTelemetryServiceClient) GetAnomalies(ctx context.Context, req *telemetrypb.GetAnomaliesRequest, opts ...grpc.CallOption) (*telemetrypb.GetAnomaliesResponse, error) {
+	if m.GetAnomaliesFunc != nil {
+		return m.GetAnomaliesFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("GetAnomaliesFunc not implemented")
+}
+
+func (m *MockTelemetryServiceClient) GetDeviceMetrics(ctx context.Context, req *telemetrypb.GetDeviceMetricsRequest, opts ...grpc.CallOption) (*telemetrypb.GetDeviceMetricsResponse, error) {
+	if m.GetDeviceMetricsFunc != nil {
+		return m.GetDeviceMetricsFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("GetDeviceMetricsFunc not implemented")
+}
+
+func (m *MockTelemetryServiceClient) GetLatestMetric(ctx context.Context, req *telemetrypb.GetLatestMetricRequest, opts ...grpc.CallOption) (*telemetrypb.GetLatestMetricResponse, error) {
+	if m.GetLatestMetricFunc != nil {
+		return m.GetLatestMetricFunc(ctx, req, opts...)
+	}
+	return nil, errors.New("GetLatestMetricFunc not implemented")
+}
+
+// TestDeviceLatestMetricImpl tests the deviceLatestMetric query resolver.
+func TestDeviceLatestMetricImpl(t *testing.T) {
+	tests := []struct {
+		name      string
+		unit      *string
+		mockSetup func(*MockTelemetryServiceClient)
+		wantErr   bool
+		wantPoint bool
+	}{
+		{
+			name: "converted_to_unit",
+			unit: stringPtr("fahrenheit"),
+			mockSetup: func(m *MockTelemetryServiceClient) {
+				m.GetLatestMetricFunc = func(ctx context.Context, req *telemetrypb.GetLatestMetricRequest, opts ...grpc.CallOption) (*telemetrypb.GetLatestMetricResponse, error) {
+					if req.Unit != "fahrenheit" {
+						t.Errorf("expected unit fahrenheit, got %q", req.Unit)
+					}
+					return &telemetrypb.GetLatestMetricResponse{
+						Point: &telemetrypb.TelemetryPoint{Time: 1000, Value: 68, Unit: "fahrenheit"},
+					}, nil
+				}
+			},
+			wantPoint: true,
+		},
+		{
+			name: "incompatible_unit",
+			unit: stringPtr("psi"),
+			mockSetup: func(m *MockTelemetryServiceClient) {
+				m.GetLatestMetricFunc = func(ctx context.Context, req *telemetrypb.GetLatestMetricRequest, opts ...grpc.CallOption) (*telemetrypb.GetLatestMetricResponse, error) {
+					return nil, status.Error(codes.FailedPrecondition, `cannot convert "celsius" to "psi"`)
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "no_data",
+			mockSetup: func(m *MockTelemetryServiceClient) {
+				m.GetLatestMetricFunc = func(ctx context.Context, req *telemetrypb.GetLatestMetricRequest, opts ...grpc.CallOption) (*telemetrypb.GetLatestMetricResponse, error) {
+					if req.Unit != "" {
+						t.Errorf("expected no unit, got %q", req.Unit)
+					}
+					return nil, errors.New("no rows in result set")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockTelemetryServiceClient{}
+			tt.mockSetup(mock)
+
+			resolver := &queryResolver{&Resolver{TelemetryClient: mock, DeviceClient: &MockDeviceServiceClient{}}}
+			point, err := resolver.DeviceLatestMetricImpl(userContext(), "dev-1", "temperature", tt.unit)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DeviceLatestMetricImpl() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (point != nil) != tt.wantPoint {
+				t.Fatalf("expected point = %v, got %+v", tt.wantPoint, point)
+			}
+			if point != nil && (point.Value != 68 || *point.Unit != "fahrenheit") {
+				t.Errorf("unexpected point: value=%v unit=%v", point.Value, *point.Unit)
+			}
+		})
+	}
+}
+
+// TestTelemetryBatchImpl tests the telemetryBatch query resolver.
+func TestTelemetryBatchImpl(t *testing.T) {
+	groupByType := model.TelemetryGroupByDeviceType
+	groupByLocation := model.TelemetryGroupByLocation
+	site := model.LocationKindSite
+
+	tests := []struct {
+		name      string
+		input     model.TelemetryBatchInput
+		mockSetup func(*MockTelemetryServiceClient)
+		wantErr   bool
+		validate  func(t *testing.T, series []*model.TelemetryBatchSeries)
+	}{
+		{
+			name: "per_device_series",
+			input: model.TelemetryBatchInput{
+				DeviceIds:   []string{"dev-1", "dev-2"},
+				MetricNames: []string{"temperature"},
+				From:        1000,
+				To:          2000,
+				Interval:    "1 hour",
+			},
+			mockSetup: func(m *MockTelemetryServiceClient) {
+				m.GetTelemetryBatchFunc = func(ctx context.Context, req *telemetrypb.GetTelemetryBatchRequest, opts ...grpc.CallOption) (*telemetrypb.GetTelemetryBatchResponse, error) {
+					if req.Filter != nil {
+						t.Errorf("expected no filter, got %v", req.Filter)
+					}
+					if req.GroupBy != telemetrypb.TelemetryGroupBy_GROUP_BY_DEVICE {
+						t.Errorf("expected GROUP_BY_DEVICE, got %v", req.GroupBy)
+					}
+					return &telemetrypb.GetTelemetryBatchResponse{
+						Series: []*telemetrypb.TelemetryBatchSeries{
+							{DeviceId: "dev-1", DeviceType: "sensor", MetricName: "temperature", Aggregations: []*telemetrypb.TelemetryAggregation{{Bucket: "b1", Avg: 20, Count: 3}}},
+							{DeviceId: "dev-2", DeviceType: "sensor", MetricName: "temperature", Aggregations: []*telemetrypb.TelemetryAggregation{{Bucket: "b1", Count: 0}}},
+						},
+					}, nil
+				}
+			},
+			validate: func(t *testing.T, series []*model.TelemetryBatchSeries) {
+				if len(series) != 2 {
+					t.Fatalf("expected 2 series, got %d", len(series))
+				}
+				if series[0].DeviceID == nil || *series[0].DeviceID != "dev-1" {
+					t.Errorf("expected device dev-1, got %v", series[0].DeviceID)
+				}
+				if series[0].Aggregations[0].Avg != 20 || series[0].Aggregations[0].Count != 3 {
+					t.Errorf("unexpected aggregation: %+v", series[0].Aggregations[0])
+				}
+			},
+		},
+		{
+			name: "grouped_by_type_with_filter",
+			input: model.TelemetryBatchInput{
+				DeviceType:  stringPtr("temperature_sensor"),
+				Metadata:    []*model.MetadataEntryInput{{Key: "floor", Value: "2"}},
+				MetricNames: []string{"temperature"},
+				Interval:    "1 hour",
+				GroupBy:     &groupByType,
+				Unit:        stringPtr("kelvin"),
+			},
+			mockSetup: func(m *MockTelemetryServiceClient) {
+				m.GetTelemetryBatchFunc = func(ctx context.Context, req *telemetrypb.GetTelemetryBatchRequest, opts ...grpc.CallOption) (*telemetrypb.GetTelemetryBatchResponse, error) {
+					if req.Filter.GetType() != "temperature_sensor" || req.Filter.GetMetadata()["floor"] != "2" {
+						t.Errorf("unexpected filter: %v", req.Filter)
+					}
+					if req.GroupBy != telemetrypb.TelemetryGroupBy_GROUP_BY_DEVICE_TYPE {
+						t.Errorf("expected GROUP_BY_DEVICE_TYPE, got %v", req.GroupBy)
+					}
+					if req.Unit != "kelvin" {
+						t.Errorf("expected unit kelvin, got %q", req.Unit)
+					}
+					return &telemetrypb.GetTelemetryBatchResponse{
+						Series: []*telemetrypb.TelemetryBatchSeries{
+							{DeviceType: "temperature_sensor", MetricName: "temperature"},
+						},
+					}, nil
+				}
+			},
+			validate: func(t *testing.T, series []*model.TelemetryBatchSeries) {
+				if len(series) != 1 {
+					t.Fatalf("expected 1 series, got %d", len(series))
+				}
+				if series[0].DeviceID != nil {
+					t.Errorf("expected nil device ID, got %s", *series[0].DeviceID)
+				}
+				if series[0].DeviceType == nil || *series[0].DeviceType != "temperature_sensor" {
+					t.Errorf("expected device type temperature_sensor, got %v", series[0].DeviceType)
+				}
+			},
+		},
+		{
+			name: "grouped_by_site",
+			input: model.TelemetryBatchInput{
+				GroupIds:     []string{"group-1"},
+				LocationID:   stringPtr("site-1"),
+				MetricNames:  []string{"temperature"},
+				Interval:     "1 hour",
+				GroupBy:      &groupByLocation,
+				LocationKind: &site,
+			},
+			mockSetup: func(m *MockTelemetryServiceClient) {
+				m.GetTelemetryBatchFunc = func(ctx context.Context, req *telemetrypb.GetTelemetryBatchRequest, opts ...grpc.CallOption) (*telemetrypb.GetTelemetryBatchResponse, error) {
+					if len(req.Filter.GetGroupIds()) != 1 || req.Filter.GetLocationId() != "site-1" {
+						t.Errorf("unexpected filter: %v", req.Filter)
+					}
+					if req.GroupBy != telemetrypb.TelemetryGroupBy_GROUP_BY_LOCATION || req.LocationKind != "site" {
+						t.Errorf("expected GROUP_BY_LOCATION by site, got %v %q", req.GroupBy, req.LocationKind)
+					}
+					return &telemetrypb.GetTelemetryBatchResponse{
+						Series: []*telemetrypb.TelemetryBatchSeries{
+							{LocationId: "site-1", MetricName: "temperature"},
+						},
+					}, nil
+				}
+			},
+			validate: func(t *testing.T, series []*model.TelemetryBatchSeries) {
+				if len(series) != 1 || series[0].LocationID == nil || *series[0].LocationID != "site-1" {
+					t.Fatalf("expected the series of site-1, got %+v", series)
+				}
+				if series[0].GroupID != nil || series[0].DeviceType != nil {
+					t.Errorf("expected no group nor device type, got %+v", series[0])
+				}
+			},
+		},
+		{
+			name: "grpc_error",
+			input: model.TelemetryBatchInput{
+				DeviceIds:   []string{"dev-1"},
+				MetricNames: []string{"temperature"},
+			},
+			mockSetup: func(m *MockTelemetryServiceClient) {
+				m.GetTelemetryBatchFunc = func(ctx context.Context, req *telemetrypb.GetTelemetryBatchRequest, opts ...grpc.CallOption) (*telemetrypb.GetTelemetryBatchResponse, error) {
+					return nil, errors.New("connection refused")
+				}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockTelemetryServiceClient{}
+			tt.mockSetup(mock)
+
+			resolver := &queryResolver{&Resolver{TelemetryClient: mock, DeviceClient: &MockDeviceServiceClient{}}}
+			series, err := resolver.TelemetryBatchImpl(userContext(), tt.input)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TelemetryBatchImpl() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.validate != nil {
+				tt.validate(t, series)
+			}
+		})
+	}
+}
+
+// TestAnomaliesImpl tests the anomalies query resolver.
+func TestAnomaliesImpl(t *testing.T) {
+	mock := &MockTelemetryServiceClient{
+		GetAnomaliesFunc: func(ctx context.Context, req *telemetrypb.GetAnomaliesRequest, opts ...grpc.CallOption) (*telemetrypb.GetAnomaliesResponse, error) {
+			if req.DeviceId != "" || req.MetricName != "temperature" || req.MinScore != 5 || req.Limit != 100 {
+				t.Errorf("unexpected request: %v", req)
+			}
+			return &telemetrypb.GetAnomaliesResponse{
+				Anomalies: []*telemetrypb.Anomaly{
+					{DeviceId: "dev-1", MetricName: "temperature", Time: 1700000000, Value: 42, Expected: 21, Score: 7, Method: "ewma"},
+				},
+			}, nil
+		},
+	}
+	resolver := &queryResolver{&Resolver{TelemetryClient: mock, DeviceClient: &MockDeviceServiceClient{}}}
+
+	minScore := 5.0
+	anomalies, err := resolver.AnomaliesImpl(userContext(), nil, stringPtr("temperature"), 1000, 2000, &minScore, nil)
+	if err != nil {
+		t.Fatalf("AnomaliesImpl() error = %v", err)
+	}
+	if len(anomalies) != 1 {
+		t.Fatalf("expected 1 anomaly, got %d", len(anomalies))
+	}
+	if a := anomalies[0]; a.DeviceID != "dev-1" || a.Score != 7 || a.Expected != 21 || a.Method != "ewma" {
+		t.Errorf("unexpected anomaly: %+v", a)
+	}
+}
+
+// fakeReplayer replays fixed points, publishing a live point during the replay.
+type fakeReplayer struct {
+	points  []*model.TelemetryPoint
+	during  func()
+	afterID string
+}
+
+func (f *fakeReplayer) Replay(ctx context.Context, deviceID, afterID string, fn func(point *model.TelemetryPoint) error) error {
+	f.afterID = afterID
+	for _, point := range f.points {
+		if err := fn(point); err != nil {
+			return err
+		}
+	}
+	if f.during != nil {
+		f.during()
+	}
+	return nil
+}
+
+func telemetryEvent(id string, value float64) *model.TelemetryPoint {
+	return &model.TelemetryPoint{Time: 1000, Value: value, EventID: &id}
+}
+
+func receive(t *testing.T, ch <-chan *model.TelemetryPoint) *model.TelemetryPoint {
+	t.Helper()
+	select {
+	case point := <-ch:
+		return point
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for telemetry")
+		return nil
+	}
+}
+
+// TestTelemetryReceivedImpl_Resume tests resuming a subscription from an event ID.
+func TestTelemetryReceivedImpl_Resume(t *testing.T) {
+	broker := pubsub.NewBroker()
+	replayer := &fakeReplayer{
+		points: []*model.TelemetryPoint{telemetryEvent("100-0", 1), telemetryEvent("101-0", 2)},
+	}
+	// Published live while replaying: one already replayed, one new
+	replayer.during = func() {
+		broker.Publish("dev-1", telemetryEvent("101-0", 2))
+		broker.Publish("dev-1", telemetryEvent("102-0", 3))
+	}
+
+	ctx, cancel := context.WithCancel(userContext())
+	defer cancel()
+
+	resolver := &subscriptionResolver{&Resolver{Broker: broker, Replayer: replayer, DeviceClient: orgDeviceClient()}}
+	ch, err := resolver.TelemetryReceivedImpl(ctx, "dev-1", stringPtr("99-0"), nil)
+	if err != nil {
+		t.Fatalf("TelemetryReceivedImpl() error = %v", err)
+	}
+
+	for _, want := range []string{"100-0", "101-0", "102-0"} {
+		if got := receive(t, ch); *got.EventID != want {
+			t.Fatalf("expected event %s, got %s", want, *got.EventID)
+		}
+	}
+	if replayer.afterID != "99-0" {
+		t.Errorf("expected replay after 99-0, got %s", replayer.afterID)
+	}
+
+	cancel()
+	for range ch {
+	}
+	if n := broker.SubscriberCount("dev-1"); n != 0 {
+		t.Errorf("expected no subscriber after cancel, got %d", n)
+	}
+}
+
+// TestTelemetryReceivedImpl_ResumeErrors tests lastEventId validation.
+func TestTelemetryReceivedImpl_ResumeErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		replayer pubsub.Replayer
+		lastID   string
+	}{
+		{name: "pubsub_transport", lastID: "100-0"},
+		{name: "malformed_id", replayer: &fakeReplayer{}, lastID: "yesterday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := pubsub.NewBroker()
+			resolver := &subscriptionResolver{&Resolver{Broker: broker, Replayer: tt.replayer, DeviceClient: orgDeviceClient()}}
+
+			if _, err := resolver.TelemetryReceivedImpl(userContext(), "dev-1", &tt.lastID, nil); err == nil {
+				t.Fatal("expected error")
+			}
+			if n := broker.SubscriberCount("dev-1"); n != 0 {
+				t.Errorf("expected no subscriber, got %d", n)
+			}
+		})
+	}
+}
+
+// publishJSON publishes v on the bus, failing the test on error.
+func publishJSON(t *testing.T, bus eventbus.Bus, subject string, v any) {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.Publish(context.Background(), subject, data); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+}
+
+// TestSubscriptions_EventBus tests telemetryReceived resumption and
+// deviceUpdated over the in-process event bus.
+func TestSubscriptions_EventBus(t *testing.T) {
+	ctx, cancel := context.WithCancel(userContext())
+	defer cancel()
+
+	bus := eventbus.NewMemory(100)
+	defer bus.Close()
+	broker := pubsub.NewBroker()
+	subscriber, err := pubsub.NewSubscriber(ctx, bus, broker)
+	if err != nil {
+		t.Fatalf("NewSubscriber failed: %v", err)
+	}
+	defer subscriber.Close()
+	resolver := &subscriptionResolver{&Resolver{Broker: broker, Replayer: subscriber, DeviceClient: orgDeviceClient()}}
+
+	for i, value := range []float64{20, 21, 22} {
+		publishJSON(t, bus, "telemetry.dev-1", eventbus.TelemetryEvent{
+			DeviceID: "dev-1", MetricName: "temperature", Value: value, Unit: "celsius",
+			Timestamp: time.Unix(int64(1000+i), 0).UTC().Format(time.RFC3339),
+		})
+	}
+	publishJSON(t, bus, "telemetry.dev-2", eventbus.TelemetryEvent{DeviceID: "dev-2", Value: 99})
+
+	lastID := "1"
+	points, err := resolver.TelemetryReceivedImpl(ctx, "dev-1", &lastID, nil)
+	if err != nil {
+		t.Fatalf("TelemetryReceived failed: %v", err)
+	}
+	for _, want := range []float64{21, 22} {
+		select {
+		case point := <-points:
+			if point.Value != want {
+				t.Errorf("expected value %v, got %v", want, point.Value)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("replayed point %v not received", want)
+		}
+	}
+
+	devices, err := resolver.DeviceUpdatedImpl(ctx)
+	if err != nil {
+		t.Fatalf("DeviceUpdated failed: %v", err)
+	}
+	publishJSON(t, bus, "devices.dev-1", eventbus.DeviceEvent{Type: eventbus.DeviceDeleted, DeviceID: "dev-1"})
+	// Devices of other organizations are not streamed
+	publishJSON(t, bus, "devices.dev-3", eventbus.DeviceEvent{Type: eventbus.DeviceUpdated, DeviceID: "dev-3", OrgID: "org-2"})
+	publishJSON(t, bus, "devices.dev-1", eventbus.DeviceEvent{
+		Type: eventbus.DeviceUpdated, DeviceID: "dev-1", Name: "Renamed", Status: "OFFLINE",
+		Metadata: map[string]string{"room": "kitchen"}, OrgID: auth.DefaultOrganizationID,
+	})
+
+	select {
+	case device := <-devices:
+		if device.ID != "dev-1" || device.Name != "Renamed" || device.Status != model.DeviceStatusOffline {
+			t.Errorf("unexpected device %+v", device)
+		}
+		if len(device.Metadata) != 1 || device.Metadata[0].Key != "room" {
+			t.Errorf("unexpected metadata %+v", device.Metadata)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("device update not received")
+	}
+	select {
+	case device := <-devices:
+		t.Errorf("unexpected extra device event %+v", device)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestTelemetryImpl tests multi-device subscriptions and filter validation.
+func TestTelemetryImpl(t *testing.T) {
+	ctx, cancel := context.WithCancel(userContext())
+	defer cancel()
+
+	broker := pubsub.NewBroker()
+	resolver := &subscriptionResolver{&Resolver{Broker: broker, DeviceClient: orgDeviceClient()}}
+
+	threshold := 10.0
+	ch, err := resolver.TelemetryImpl(ctx, model.TelemetryFilter{
+		DeviceIds:   []string{"dev-1", "dev-2"},
+		MetricNames: []string{"temperature"},
+		Value:       &model.ValuePredicate{Gte: &threshold},
+	})
+	if err != nil {
+		t.Fatalf("Telemetry failed: %v", err)
+	}
+
+	for _, p := range []struct {
+		device, metric string
+		value          float64
+	}{
+		{"dev-1", "temperature", 12},
+		{"dev-2", "humidity", 50},
+		{"dev-2", "temperature", 5},
+		{"dev-2", "temperature", 15},
+	} {
+		device, metric := p.device, p.metric
+		broker.Publish(device, &model.TelemetryPoint{Value: p.value, DeviceID: &device, MetricName: &metric})
+	}
+
+	for _, want := range []string{"dev-1", "dev-2"} {
+		select {
+		case point := <-ch:
+			if *point.DeviceID != want || *point.MetricName != "temperature" || point.Value < threshold {
+				t.Errorf("unexpected point %+v", point)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("point from %s not received", want)
+		}
+	}
+
+	cancel()
+	for range ch {
+	}
+	if n := broker.TotalSubscribers(); n != 0 {
+		t.Errorf("expected no subscriber after cancel, got %d", n)
+	}
+
+	deviceType := "thermometer"
+	negative := -1
+	invalid := []model.TelemetryFilter{
+		{},
+		{DeviceIds: []string{"dev-1"}, DeviceType: &deviceType},
+		{DeviceType: &deviceType, MinIntervalMs: &negative},
+	}
+	for _, filter := range invalid {
+		if _, err := resolver.TelemetryImpl(userContext(), filter); err == nil {
+			t.Errorf("expected error for filter %+v", filter)
+		}
+	}
+	if n := broker.TotalSubscribers(); n != 0 {
+		t.Errorf("expected no subscriber after invalid filters, got %d", n)
+	}
+}
+
+// TestSubscriptionStatsImpl tests the cluster-wide subscription counts.
+func TestSubscriptionStatsImpl(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := eventbus.NewMemory(0)
+	defer bus.Close()
+	local, remote := pubsub.NewBroker(), pubsub.NewBroker()
+	cluster, err := pubsub.NewCluster(ctx, bus, local, "gw-a", time.Hour)
+	if err != nil {
+		t.Fatalf("NewCluster failed: %v", err)
+	}
+	defer cluster.Close()
+	remote.Subscribe(pubsub.Filter{DeviceIDs: []string{"dev-1", "dev-2"}})
+	remote.Subscribe(pubsub.Filter{DeviceIDs: []string{"dev-2"}})
+	remoteCluster, err := pubsub.NewCluster(ctx, bus, remote, "gw-b", time.Hour)
+	if err != nil {
+		t.Fatalf("NewCluster failed: %v", err)
+	}
+	defer remoteCluster.Close()
+	local.Subscribe(pubsub.Filter{DeviceIDs: []string{"dev-1"}})
+
+	resolver := &queryResolver{&Resolver{Broker: local, Cluster: cluster}}
+	var stats *model.SubscriptionStats
+	deadline := time.Now().Add(time.Second)
+	for stats == nil || len(stats.Replicas) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("remote replica stats not received")
+		}
+		time.Sleep(5 * time.Millisecond)
+		if stats, err = resolver.SubscriptionStatsImpl(adminContext()); err != nil {
+			t.Fatalf("SubscriptionStatsImpl() error = %v", err)
+		}
+	}
+
+	if stats.Total != 3 || stats.Replicas[0].Replica != "gw-a" || stats.Replicas[1].Devices != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if len(stats.Devices) != 2 || stats.Devices[0].DeviceID != "dev-1" || stats.Devices[0].Subscriptions != 2 || stats.Devices[0].Replicas != 2 {
+		t.Errorf("unexpected device counts %+v", stats.Devices[0])
+	}
+	if stats.Devices[1].DeviceID != "dev-2" || stats.Devices[1].Subscriptions != 2 || stats.Devices[1].Replicas != 1 {
+		t.Errorf("unexpected device counts %+v", stats.Devices[1])
+	}
+}
@@ -0,0 +1,208 @@
+package importer
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/auth"
+	telemetrypb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+)
+
+// chunkSize is the number of records sent per gRPC message.
+const chunkSize = 5000
+
+// conflictPolicies maps the on_conflict query parameter to the RPC policy.
+var conflictPolicies = map[string]telemetrypb.ImportConflictPolicy{
+	"":          telemetrypb.ImportConflictPolicy_IMPORT_CONFLICT_SKIP,
+	"skip":      telemetrypb.ImportConflictPolicy_IMPORT_CONFLICT_SKIP,
+	"overwrite": telemetrypb.ImportConflictPolicy_IMPORT_CONFLICT_OVERWRITE,
+	"fail":      telemetrypb.ImportConflictPolicy_IMPORT_CONFLICT_FAIL,
+}
+
+// Result is the JSON body returned after a successful import.
+type Result struct {
+	RowsReceived int64 `json:"rowsReceived"`
+	RowsWritten  int64 `json:"rowsWritten"`
+	RowsSkipped  int64 `json:"rowsSkipped"`
+	From         int64 `json:"from"`
+	To           int64 `json:"to"`
+}
+
+// Handler serves POST /import/telemetry.
+//
+// Query parameters:
+//   - format: csv or ndjson (default: csv)
+//   - on_conflict: skip, overwrite or fail (default: skip)
+//
+// The body may be gzip-compressed (Content-Encoding: gzip). The upload is
+// decoded as it is read and forwarded in chunks, so its size is not bounded
+// by memory. Every row must be a device of the organization of the token.
+// Importing requires telemetry:write on every device, checked by authorize:
+// users restricted by ACL entries may not import. Wrap the handler with
+// auth.Middleware.
+func Handler(client telemetrypb.TelemetryServiceClient, authorize auth.DeviceAuthorizer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		claims, ok := auth.GetUserFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Authentication required", http.StatusUnauthorized)
+			return
+		}
+		if !claims.HasPermission(auth.PermTelemetryWrite) {
+			http.Error(w, "Permission telemetry:write required", http.StatusForbidden)
+			return
+		}
+		if err := authorize(r.Context(), nil, auth.PermTelemetryWrite); err != nil {
+			if errors.Is(err, auth.ErrForbidden) {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			log.Printf("❌ Failed to authorize telemetry import: %v", err)
+			http.Error(w, "Failed to check device access", http.StatusBadGateway)
+			return
+		}
+		if client == nil {
+			http.Error(w, "Telemetry service unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		q := r.URL.Query()
+		format := Format(q.Get("format"))
+		if format == "" {
+			format = FormatCSV
+		}
+		policy, ok := conflictPolicies[q.Get("on_conflict")]
+		if !ok {
+			http.Error(w, "on_conflict must be skip, overwrite or fail", http.StatusBadRequest)
+			return
+		}
+
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				http.Error(w, "Invalid gzip body", http.StatusBadRequest)
+				return
+			}
+			defer gz.Close()
+			body = gz
+		}
+
+		reader, err := NewReader(format, body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		result, err := upload(r.Context(), client, reader, policy, claims.Organization())
+		if err != nil {
+			log.Printf("❌ Telemetry import failed: %v", err)
+			http.Error(w, errorMessage(err), errorStatus(err))
+			return
+		}
+
+		log.Printf("✅ %s imported %d telemetry rows (%d written, %d skipped)", claims.Email, result.RowsReceived, result.RowsWritten, result.RowsSkipped)
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			log.Printf("⚠️  Failed to write import response: %v", err)
+		}
+	})
+}
+
+// upload streams records of devices of the organization orgID to the
+// collector. On a parse error the RPC is cancelled so the collector rolls back
+// everything staged so far.
+func upload(ctx context.Context, client telemetrypb.TelemetryServiceClient, reader RecordReader, policy telemetrypb.ImportConflictPolicy, orgID string) (*Result, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := client.ImportTelemetry(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &telemetrypb.ImportTelemetryRequest{OnConflict: policy, OrgId: orgID}
+	send := func() error {
+		if err := stream.Send(req); err != nil {
+			if errors.Is(err, io.EOF) {
+				// The collector ended the stream: its status is returned by CloseAndRecv
+				_, err = stream.CloseAndRecv()
+			}
+			return err
+		}
+		req = &telemetrypb.ImportTelemetryRequest{}
+		return nil
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		req.Records = append(req.Records, record)
+		if len(req.Records) == chunkSize {
+			if err := send(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Always send the last message, even empty, so the policy reaches the collector
+	if err := send(); err != nil {
+		return nil, err
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		RowsReceived: resp.RowsReceived,
+		RowsWritten:  resp.RowsWritten,
+		RowsSkipped:  resp.RowsSkipped,
+		From:         resp.FromTime,
+		To:           resp.ToTime,
+	}, nil
+}
+
+// errorStatus maps upload errors to HTTP status codes.
+func errorStatus(err error) int {
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		return http.StatusBadRequest
+	}
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.FailedPrecondition:
+		return http.StatusUnprocessableEntity
+	case codes.AlreadyExists:
+		return http.StatusConflict
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// errorMessage returns the client-facing message for an upload error.
+func errorMessage(err error) string {
+	if s, ok := status.FromError(err); ok {
+		return s.Message()
+	}
+	return err.Error()
+}
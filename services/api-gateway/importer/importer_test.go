@@ -0,0 +1,316 @@
+// +build unit
+
+package importer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/auth"
+	telemetrypb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+)
+
+const deviceID = "0b9e8c1e-7d4a-4f3b-9c55-2a1f6d0e8b11"
+
+// mockImportClient records what the handler streams to ImportTelemetry.
+type mockImportClient struct {
+	telemetrypb.TelemetryServiceClient
+
+	requests []*telemetrypb.ImportTelemetryRequest
+	respErr  error
+}
+
+func (m *mockImportClient) ImportTelemetry(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[telemetrypb.ImportTelemetryRequest, telemetrypb.ImportTelemetryResponse], error) {
+	return &mockImportStream{client: m}, nil
+}
+
+type mockImportStream struct {
+	grpc.ClientStream
+	client *mockImportClient
+}
+
+func (s *mockImportStream) Send(req *telemetrypb.ImportTelemetryRequest) error {
+	s.client.requests = append(s.client.requests, req)
+	return nil
+}
+
+func (s *mockImportStream) CloseAndRecv() (*telemetrypb.ImportTelemetryResponse, error) {
+	if s.client.respErr != nil {
+		return nil, s.client.respErr
+	}
+	var received int64
+	for _, req := range s.client.requests {
+		received += int64(len(req.Records))
+	}
+	return &telemetrypb.ImportTelemetryResponse{RowsReceived: received, RowsWritten: received}, nil
+}
+
+func (m *mockImportClient) records() []*telemetrypb.TelemetryRecord {
+	var records []*telemetrypb.TelemetryRecord
+	for _, req := range m.requests {
+		records = append(records, req.Records...)
+	}
+	return records
+}
+
+func TestCSVReader(t *testing.T) {
+	input := "time,device_id,metric_name,value,unit\n" +
+		"2023-11-14T22:13:20Z," + deviceID + ",temperature,21.5,celsius\n" +
+		"1700000060," + deviceID + ",humidity,40,\n"
+
+	reader, err := NewReader(FormatCSV, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+
+	first, err := reader.Read()
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if first.Time != 1700000000 || first.Value != 21.5 || first.Unit != "celsius" || first.DeviceId != deviceID {
+		t.Errorf("unexpected first record: %v", first)
+	}
+
+	second, err := reader.Read()
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if second.Time != 1700000060 || second.MetricName != "humidity" || second.Unit != "" {
+		t.Errorf("unexpected second record: %v", second)
+	}
+
+	if _, err := reader.Read(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestCSVReader_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantLine int
+	}{
+		{name: "empty", input: "", wantLine: 1},
+		{name: "missing_column", input: "time,device_id,value\n", wantLine: 1},
+		{name: "bad_value", input: "time,device_id,metric_name,value\n1," + deviceID + ",t,abc\n", wantLine: 2},
+		{name: "bad_time", input: "time,device_id,metric_name,value\n1,x,t,1\nyesterday," + deviceID + ",t,1\n", wantLine: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := NewReader(FormatCSV, strings.NewReader(tt.input))
+			for err == nil {
+				_, err = reader.Read()
+			}
+
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected ParseError, got %v", err)
+			}
+			if parseErr.Line != tt.wantLine {
+				t.Errorf("expected line %d, got %d (%v)", tt.wantLine, parseErr.Line, err)
+			}
+		})
+	}
+}
+
+func TestNDJSONReader(t *testing.T) {
+	input := `{"time":"2023-11-14T22:13:20Z","device_id":"` + deviceID + `","metric_name":"temperature","value":21.5,"unit":"celsius"}` + "\n\n" +
+		`{"time":1700000060,"device_id":"` + deviceID + `","metric_name":"humidity","value":0}` + "\n" +
+		`{"time":1700000120,"device_id":"` + deviceID + `","metric_name":"humidity"}` + "\n"
+
+	reader, err := NewReader(FormatNDJSON, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+
+	first, err := reader.Read()
+	if err != nil || first.Time != 1700000000 || first.Unit != "celsius" {
+		t.Fatalf("unexpected first record: %v, %v", first, err)
+	}
+	second, err := reader.Read()
+	if err != nil || second.Time != 1700000060 || second.Value != 0 {
+		t.Fatalf("unexpected second record: %v, %v", second, err)
+	}
+
+	_, err = reader.Read()
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Line != 4 {
+		t.Errorf("expected missing value error on line 4, got %v", err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	admin := &auth.Claims{UserID: "admin-1", Email: "admin@example.com", Role: "admin"}
+	user := &auth.Claims{UserID: "user-1", Email: "user@example.com", Role: "user"}
+	csvBody := "time,device_id,metric_name,value\n1700000000," + deviceID + ",temperature,21.5\n"
+
+	tests := []struct {
+		name       string
+		query      string
+		body       string
+		gzip       bool
+		claims     *auth.Claims
+		restricted bool
+		respErr    error
+		wantStatus int
+		validate   func(t *testing.T, rec *httptest.ResponseRecorder, client *mockImportClient)
+	}{
+		{
+			name:       "csv_import",
+			query:      "on_conflict=overwrite",
+			body:       csvBody,
+			claims:     admin,
+			wantStatus: http.StatusOK,
+			validate: func(t *testing.T, rec *httptest.ResponseRecorder, client *mockImportClient) {
+				if client.requests[0].OnConflict != telemetrypb.ImportConflictPolicy_IMPORT_CONFLICT_OVERWRITE {
+					t.Errorf("expected overwrite policy, got %v", client.requests[0].OnConflict)
+				}
+				var result Result
+				if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+					t.Fatalf("invalid JSON response: %v", err)
+				}
+				if result.RowsReceived != 1 {
+					t.Errorf("expected 1 row, got %d", result.RowsReceived)
+				}
+			},
+		},
+		{
+			name:       "gzip_ndjson",
+			query:      "format=ndjson",
+			body:       `{"time":1700000000,"device_id":"` + deviceID + `","metric_name":"temperature","value":1}`,
+			gzip:       true,
+			claims:     admin,
+			wantStatus: http.StatusOK,
+			validate: func(t *testing.T, rec *httptest.ResponseRecorder, client *mockImportClient) {
+				if len(client.records()) != 1 {
+					t.Errorf("expected 1 record sent, got %d", len(client.records()))
+				}
+			},
+		},
+		{
+			name:       "chunked_upload",
+			body:       "time,device_id,metric_name,value\n" + strings.Repeat("1700000000,"+deviceID+",t,1\n", chunkSize+1),
+			claims:     admin,
+			wantStatus: http.StatusOK,
+			validate: func(t *testing.T, rec *httptest.ResponseRecorder, client *mockImportClient) {
+				if len(client.requests) != 2 {
+					t.Errorf("expected 2 messages, got %d", len(client.requests))
+				}
+				if len(client.records()) != chunkSize+1 {
+					t.Errorf("expected %d records, got %d", chunkSize+1, len(client.records()))
+				}
+			},
+		},
+		{
+			name:       "unauthenticated",
+			body:       csvBody,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "non_admin",
+			body:       csvBody,
+			claims:     user,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "restricted_by_acl",
+			body:       csvBody,
+			claims:     admin,
+			restricted: true,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "invalid_policy",
+			query:      "on_conflict=merge",
+			body:       csvBody,
+			claims:     admin,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "parse_error",
+			body:       csvBody + "oops\n",
+			claims:     admin,
+			wantStatus: http.StatusBadRequest,
+			validate: func(t *testing.T, rec *httptest.ResponseRecorder, client *mockImportClient) {
+				if !strings.Contains(rec.Body.String(), "line 3") {
+					t.Errorf("expected line number in error, got %q", rec.Body.String())
+				}
+			},
+		},
+		{
+			name:       "unknown_devices",
+			body:       csvBody,
+			claims:     admin,
+			respErr:    status.Error(codes.FailedPrecondition, "unknown devices: "+deviceID),
+			wantStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:       "conflict",
+			query:      "on_conflict=fail",
+			body:       csvBody,
+			claims:     admin,
+			respErr:    status.Error(codes.AlreadyExists, "imported telemetry conflicts with existing data"),
+			wantStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := []byte(tt.body)
+			if tt.gzip {
+				var buf bytes.Buffer
+				gz := gzip.NewWriter(&buf)
+				if _, err := gz.Write(body); err != nil {
+					t.Fatalf("gzip write error: %v", err)
+				}
+				if err := gz.Close(); err != nil {
+					t.Fatalf("gzip close error: %v", err)
+				}
+				body = buf.Bytes()
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/import/telemetry?"+tt.query, bytes.NewReader(body))
+			if tt.gzip {
+				req.Header.Set("Content-Encoding", "gzip")
+			}
+			if tt.claims != nil {
+				req = req.WithContext(auth.WithUser(req.Context(), tt.claims))
+			}
+			rec := httptest.NewRecorder()
+			client := &mockImportClient{respErr: tt.respErr}
+
+			authorize := func(ctx context.Context, deviceIDs []string, permission string) error {
+				if len(deviceIDs) != 0 || permission != auth.PermTelemetryWrite {
+					t.Errorf("unexpected authorization of %v for %s", deviceIDs, permission)
+				}
+				if tt.restricted {
+					return fmt.Errorf("%w: access restricted to some devices", auth.ErrForbidden)
+				}
+				return nil
+			}
+
+			Handler(client, authorize).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if tt.validate != nil {
+				tt.validate(t, rec, client)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// Package importer accepts bulk telemetry uploads (CSV or NDJSON) over HTTP
+// and streams them to the Telemetry Collector's ImportTelemetry RPC.
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	telemetrypb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+)
+
+// Format is an upload file format. Both formats use the same columns as the
+// telemetry export, so an export can be re-imported as is.
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+)
+
+// RecordReader decodes telemetry records from an upload.
+type RecordReader interface {
+	// Read returns the next record, or io.EOF once the input is exhausted.
+	Read() (*telemetrypb.TelemetryRecord, error)
+}
+
+// ParseError reports an invalid line in the upload.
+type ParseError struct {
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// NewReader returns a RecordReader for the given format.
+func NewReader(format Format, r io.Reader) (RecordReader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVReader(r)
+	case FormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		return &ndjsonReader{scanner: scanner}, nil
+	default:
+		return nil, fmt.Errorf("unsupported import format: %q", format)
+	}
+}
+
+// csvReader reads rows after a header naming the columns.
+// Required columns: time, device_id, metric_name, value. Optional: unit.
+type csvReader struct {
+	r       *csv.Reader
+	columns map[string]int
+}
+
+func newCSVReader(r io.Reader) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, &ParseError{Line: 1, Err: errors.New("missing header")}
+	}
+	if err != nil {
+		return nil, &ParseError{Line: 1, Err: err}
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+	for _, required := range []string{"time", "device_id", "metric_name", "value"} {
+		if _, ok := columns[required]; !ok {
+			return nil, &ParseError{Line: 1, Err: fmt.Errorf("missing column %q", required)}
+		}
+	}
+
+	return &csvReader{r: cr, columns: columns}, nil
+}
+
+func (c *csvReader) Read() (*telemetrypb.TelemetryRecord, error) {
+	row, err := c.r.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		var csvErr *csv.ParseError
+		if errors.As(err, &csvErr) {
+			return nil, &ParseError{Line: csvErr.Line, Err: csvErr.Err}
+		}
+		return nil, err
+	}
+	line, _ := c.r.FieldPos(0)
+
+	field := func(name string) string {
+		if i, ok := c.columns[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	timestamp, err := parseTime(field("time"))
+	if err != nil {
+		return nil, &ParseError{Line: line, Err: err}
+	}
+	value, err := strconv.ParseFloat(field("value"), 64)
+	if err != nil {
+		return nil, &ParseError{Line: line, Err: fmt.Errorf("invalid value %q", field("value"))}
+	}
+
+	return &telemetrypb.TelemetryRecord{
+		DeviceId:   field("device_id"),
+		MetricName: field("metric_name"),
+		Time:       timestamp,
+		Value:      value,
+		Unit:       field("unit"),
+	}, nil
+}
+
+// ndjsonLine is the JSON shape of one imported line.
+// time is either a Unix timestamp or an RFC3339 string.
+type ndjsonLine struct {
+	Time       json.RawMessage `json:"time"`
+	DeviceID   string          `json:"device_id"`
+	MetricName string          `json:"metric_name"`
+	Value      *float64        `json:"value"`
+	Unit       string          `json:"unit"`
+}
+
+// ndjsonReader reads one JSON object per line, skipping blank lines.
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (n *ndjsonReader) Read() (*telemetrypb.TelemetryRecord, error) {
+	for n.scanner.Scan() {
+		n.line++
+		text := strings.TrimSpace(n.scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var l ndjsonLine
+		if err := json.Unmarshal([]byte(text), &l); err != nil {
+			return nil, &ParseError{Line: n.line, Err: err}
+		}
+		if l.Value == nil {
+			return nil, &ParseError{Line: n.line, Err: errors.New("missing value")}
+		}
+
+		raw := strings.Trim(string(l.Time), `"`)
+		timestamp, err := parseTime(raw)
+		if err != nil {
+			return nil, &ParseError{Line: n.line, Err: err}
+		}
+
+		return &telemetrypb.TelemetryRecord{
+			DeviceId:   l.DeviceID,
+			MetricName: l.MetricName,
+			Time:       timestamp,
+			Value:      *l.Value,
+			Unit:       l.Unit,
+		}, nil
+	}
+
+	if err := n.scanner.Err(); err != nil {
+		return nil, &ParseError{Line: n.line + 1, Err: err}
+	}
+	return nil, io.EOF
+}
+
+// parseTime accepts a Unix timestamp in seconds or an RFC3339 date.
+func parseTime(s string) (int64, error) {
+	if s == "" {
+		return 0, errors.New("missing time")
+	}
+	if unix, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return unix, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q (expected Unix seconds or RFC3339)", s)
+	}
+	return t.Unix(), nil
+}
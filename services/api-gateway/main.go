@@ -15,12 +15,17 @@ import (
 	"github.com/99designs/gqlgen/graphql/handler/transport"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/yourusername/iot-platform/services/api-gateway/auth"
+	"github.com/yourusername/iot-platform/services/api-gateway/export"
 	"github.com/yourusername/iot-platform/services/api-gateway/graph"
 	"github.com/yourusername/iot-platform/services/api-gateway/graph/generated"
 	grpcClient "github.com/yourusername/iot-platform/services/api-gateway/grpc"
+	"github.com/yourusername/iot-platform/services/api-gateway/importer"
 	"github.com/yourusername/iot-platform/services/api-gateway/pubsub"
+	"github.com/yourusername/iot-platform/services/api-gateway/sse"
+	"github.com/yourusername/iot-platform/shared/eventbus"
 )
 
 const (
@@ -29,8 +34,13 @@ const (
 	defaultUserServiceAddr      = "localhost:8082"
 	defaultTelemetryServiceAddr = "localhost:8083"
 	defaultJWTSecret            = "dev-jwt-secret-NOT-FOR-PRODUCTION"
+	defaultAccessTokenTTL       = 15 * time.Minute
+	defaultJWTKeyRotation       = 30 * 24 * time.Hour
+	defaultJWTKeyOverlap        = time.Hour
 	defaultRedisHost            = "localhost"
 	defaultRedisPort            = 6379
+	defaultNATSURL              = "nats://localhost:4222"
+	clusterStatsInterval        = 10 * time.Second
 )
 
 // main configures and starts the HTTP GraphQL server.
@@ -38,7 +48,10 @@ const (
 // Endpoints:
 //   - /health  : Health check endpoint
 //   - /        : GraphQL Playground (dev only)
-//   - /query   : GraphQL API endpoint
+//   - /query   : GraphQL API endpoint (HTTP, WebSocket and Server-Sent Events)
+//   - /export/telemetry : Bulk telemetry download (CSV, NDJSON, Parquet)
+//   - /import/telemetry : Bulk historical telemetry upload (CSV, NDJSON, admin only)
+//   - /metrics : Prometheus metrics
 //
 // Configuration:
 //   - PORT: Server port (default: 8080)
@@ -46,8 +59,23 @@ const (
 //   - USER_SERVICE_ADDR: User Service address (default: localhost:8082)
 //   - TELEMETRY_SERVICE_ADDR: Telemetry Collector address (default: localhost:8083)
 //   - JWT_SECRET: Secret key for JWT tokens (default: dev-jwt-secret-NOT-FOR-PRODUCTION)
-//   - REDIS_HOST: Redis host for pub/sub (default: localhost)
+//   - ACCESS_TOKEN_TTL: Access token lifetime, renewed with refresh tokens (default: 15m)
+//   - JWT_SIGNING_ALG: HS256, RS256, ES256 or EdDSA (default: HS256). With an asymmetric algorithm,
+//     HS256 tokens are still accepted while JWT_SECRET is set
+//   - JWT_KEYS_DIR: Directory of the signing keys, shared by replicas (default: in memory)
+//   - JWT_KEY_ROTATION: Signing key lifetime, 0 to disable rotation (default: 720h)
+//   - JWT_KEY_OVERLAP: Publication of keys before and after they sign (default: 1h)
+//   - EVENT_BUS: Event bus transport: redis, nats or memory (default: redis)
+//   - EVENT_BUS_GROUP: Consumer group of this instance, stable across restarts (default: api-gateway-<hostname>).
+//     At startup, the api-gateway-* groups whose consumers have been idle for an hour are destroyed
+//   - EVENT_BUS_CONSUMER: Consumer name within the Redis group (default: <hostname>)
+//   - REDIS_HOST: Redis host (default: localhost)
 //   - REDIS_PORT: Redis port (default: 6379)
+//   - REDIS_TRANSPORT: Redis fan-out: pubsub or streams (default: streams). Either way, each
+//     replica only reads the telemetry of the devices its clients watch
+//   - NATS_URL: NATS server URL (default: nats://localhost:4222)
+//   - SUBSCRIPTION_BUFFER: Telemetry points buffered per subscription (default: 10)
+//   - SUBSCRIPTION_OVERFLOW_POLICY: drop-oldest, drop-newest, coalesce or disconnect (default: drop-newest)
 //
 // TODO Production:
 //   - Disable Playground in production
@@ -75,7 +103,8 @@ func main() {
 	}
 
 	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
+	signingAlg := getEnv("JWT_SIGNING_ALG", "HS256")
+	if jwtSecret == "" && signingAlg == "HS256" {
 		jwtSecret = defaultJWTSecret
 		log.Printf("⚠️  Using default JWT secret (dev only)")
 	}
@@ -114,25 +143,93 @@ func main() {
 		}()
 	}
 
-	// Initialize JWT manager (24 hours token duration)
-	jwtManager := auth.NewJWTManager(jwtSecret, 24*time.Hour)
+	// Initialize JWT manager. Access tokens are short-lived, clients renew
+	// them with the refresh token of their session.
+	accessTokenTTL := getEnvDuration("ACCESS_TOKEN_TTL", defaultAccessTokenTTL)
+	if accessTokenTTL <= 0 {
+		log.Fatalf("❌ Invalid ACCESS_TOKEN_TTL: %s", accessTokenTTL)
+	}
+	var jwtManager *auth.JWTManager
+	if signingAlg == "HS256" {
+		jwtManager = auth.NewJWTManager(jwtSecret, accessTokenTTL)
+	} else {
+		// Asymmetric keys, published at /.well-known/jwks.json for the
+		// services verifying tokens
+		keyOverlap := getEnvDuration("JWT_KEY_OVERLAP", defaultJWTKeyOverlap)
+		keyRing, err := auth.NewKeyRing(auth.KeyRingConfig{
+			Algorithm:        signingAlg,
+			Dir:              os.Getenv("JWT_KEYS_DIR"),
+			RotationInterval: getEnvDuration("JWT_KEY_ROTATION", defaultJWTKeyRotation),
+			Overlap:          keyOverlap,
+		})
+		if err != nil {
+			log.Fatalf("❌ Failed to load JWT keys: %v", err)
+		}
+		if os.Getenv("JWT_KEYS_DIR") == "" {
+			log.Printf("⚠️  JWT keys kept in memory: tokens are lost on restart and not shared between replicas")
+		}
+		if keyOverlap < accessTokenTTL+5*time.Minute {
+			log.Printf("⚠️  JWT_KEY_OVERLAP shorter than ACCESS_TOKEN_TTL plus the JWKS cache: tokens may outlive their key")
+		}
+		if jwtSecret != "" {
+			log.Printf("⚠️  HS256 tokens still accepted: unset JWT_SECRET once they have expired")
+		}
+		go keyRing.Run(context.Background(), time.Minute)
+		jwtManager = auth.NewKeyRingJWTManager(keyRing, jwtSecret, accessTokenTTL)
+	}
 
-	// Initialize pub/sub broker for real-time subscriptions
-	broker := pubsub.NewBroker()
+	// Initialize pub/sub broker for real-time subscriptions. Wildcard
+	// subscriptions look devices up in the Device Manager, cached for 5 minutes
+	// and refreshed by device events.
+	overflow, err := pubsub.ParseOverflowPolicy(getEnv("SUBSCRIPTION_OVERFLOW_POLICY", string(pubsub.OverflowDropNewest)))
+	if err != nil {
+		log.Fatalf("❌ Invalid SUBSCRIPTION_OVERFLOW_POLICY: %v", err)
+	}
+	broker := pubsub.NewBrokerWithConfig(pubsub.Config{
+		Index:      pubsub.NewDeviceIndex(pubsub.DeviceClientLoader(deviceClient.GetClient()), 5*time.Minute),
+		BufferSize: getEnvInt("SUBSCRIPTION_BUFFER", pubsub.DefaultBufferSize),
+		Overflow:   overflow,
+	})
 
-	// Initialize Redis subscriber
-	redisHost := getEnv("REDIS_HOST", defaultRedisHost)
-	redisPort := getEnvInt("REDIS_PORT", defaultRedisPort)
+	// Initialize event bus subscriber
+	hostname, _ := os.Hostname()
+	busConfig := eventbus.ConfigFromEnv(eventbus.Config{
+		Transport: eventbus.TransportRedis,
+		Redis: eventbus.RedisConfig{
+			Host:     defaultRedisHost,
+			Port:     defaultRedisPort,
+			Mode:     eventbus.RedisStreams,
+			Group:    "api-gateway-" + hostname,
+			Consumer: hostname,
+			// Container hostnames change on every restart: drop the groups
+			// of the replicas that are gone
+			StaleGroupPrefix: "api-gateway-",
+		},
+		NATS: eventbus.NATSConfig{URL: defaultNATSURL},
+	})
 
 	ctx := context.Background()
-	redisSubscriber, err := pubsub.NewRedisSubscriber(ctx, pubsub.Config{
-		Host: redisHost,
-		Port: redisPort,
-	}, broker)
+	var subscriber *pubsub.Subscriber
+	var cluster *pubsub.Cluster
+	bus, err := eventbus.New(ctx, busConfig)
 	if err != nil {
-		log.Printf("⚠️  Failed to connect to Redis: %v (subscriptions will not work)", err)
+		log.Printf("⚠️  Failed to connect to event bus: %v (subscriptions will not work)", err)
 	} else {
-		defer redisSubscriber.Close()
+		defer bus.Close()
+		subscriber, err = pubsub.NewSubscriber(ctx, bus, broker)
+		if err != nil {
+			log.Printf("⚠️  Failed to subscribe to event bus: %v (subscriptions will not work)", err)
+		} else {
+			defer subscriber.Close()
+		}
+
+		// Share subscription counts with the other replicas
+		cluster, err = pubsub.NewCluster(ctx, bus, broker, hostname, clusterStatsInterval)
+		if err != nil {
+			log.Printf("⚠️  Failed to share subscription stats: %v", err)
+		} else {
+			defer cluster.Close()
+		}
 	}
 
 	// Build resolver with available clients
@@ -141,19 +238,29 @@ func main() {
 		UserClient:   userClient.GetClient(),
 		JWTManager:   jwtManager,
 		Broker:       broker,
+		Cluster:      cluster,
+	}
+	// Redis pub/sub keeps no history to resume from
+	if subscriber != nil && !(busConfig.Transport == eventbus.TransportRedis && busConfig.Redis.Mode == eventbus.RedisPubSub) {
+		resolver.Replayer = subscriber
 	}
 	if telemetryClient != nil {
 		resolver.TelemetryClient = telemetryClient.GetClient()
 	}
 
 	// Create GraphQL server with WebSocket support for subscriptions
-	srv := handler.New(
-		generated.NewExecutableSchema(
-			generated.Config{
-				Resolvers: resolver,
-			},
-		),
-	)
+	srv := handler.New(generated.NewExecutableSchema(graph.NewConfig(resolver)))
+
+	// WebSocket and SSE authentication, re-checking every minute that the
+	// user is still active and the session not revoked
+	apiKeys := auth.UserClientAPIKeys(userClient.GetClient())
+	checkUser := auth.UserClientCheck(userClient.GetClient())
+	wsAuth := &auth.WebsocketAuth{
+		JWTManager:    jwtManager,
+		APIKeys:       apiKeys,
+		CheckUser:     checkUser,
+		CheckInterval: time.Minute,
+	}
 
 	// Add transports (order matters - WebSocket first for upgrade requests)
 	srv.AddTransport(&transport.Websocket{
@@ -165,17 +272,16 @@ func main() {
 			WriteBufferSize: 1024,
 		},
 		KeepAlivePingInterval: 10 * time.Second,
-		InitFunc: func(ctx context.Context, initPayload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
-			// Extract token from connection params for WebSocket auth
-			token := initPayload.Authorization()
-			if token != "" {
-				claims, err := jwtManager.ValidateToken(token)
-				if err == nil {
-					ctx = auth.WithUser(ctx, claims)
-				}
-			}
-			return ctx, &initPayload, nil
-		},
+		// Rejects connections without valid token, and closes them when the
+		// token expires unless refreshed (refreshConnectionToken mutation)
+		InitFunc: wsAuth.InitFunc,
+	})
+	// Server-Sent Events for clients behind proxies blocking WebSocket, before
+	// GET and POST which would accept its requests. Streams end when the
+	// token expires or the user is deactivated, like WebSocket connections.
+	srv.AddTransport(sse.Transport{
+		KeepAlivePingInterval: 10 * time.Second,
+		StreamContext:         wsAuth.StreamContext,
 	})
 	srv.AddTransport(transport.Options{})
 	srv.AddTransport(transport.GET{})
@@ -186,14 +292,17 @@ func main() {
 	srv.Use(extension.Introspection{})
 
 	// Add authentication extension (blocks unauthenticated requests except login/register)
-	srv.Use(auth.AuthExtension{})
+	srv.Use(&auth.AuthExtension{})
+
+	// Report events dropped for slow subscribers (extensions.missedEvents)
+	srv.Use(pubsub.MissedEvents{})
 
 	// CORS middleware
 	corsMiddleware := func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, Last-Event-ID")
 
 			// Handle preflight requests
 			if r.Method == "OPTIONS" {
@@ -205,9 +314,12 @@ func main() {
 		})
 	}
 
-	// Wrap GraphQL handler with JWT middleware and CORS
-	authMiddleware := auth.Middleware(jwtManager)
-	graphqlHandler := corsMiddleware(authMiddleware(srv))
+	// Wrap GraphQL handler with JWT middleware and CORS. WebsocketCloser lets
+	// WebSocket authentication close connections with 4401/4403, and
+	// ClientMiddleware records the IP and User-Agent of sessions. Tokens of
+	// revoked sessions and deactivated users are rejected within 10 seconds.
+	authMiddleware := auth.Middleware(jwtManager, apiKeys, auth.CachedUserCheck(checkUser, 10*time.Second))
+	graphqlHandler := corsMiddleware(authMiddleware(auth.ClientMiddleware(auth.WebsocketCloser(srv))))
 
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -222,18 +334,39 @@ func main() {
 	// GraphQL API endpoint with auth middleware and CORS
 	http.Handle("/query", graphqlHandler)
 
+	// Prometheus metrics
+	http.Handle("/metrics", promhttp.Handler())
+
+	// Public keys of asymmetric tokens
+	if keyRing := jwtManager.KeyRing(); keyRing != nil {
+		http.Handle("/.well-known/jwks.json", corsMiddleware(auth.JWKSHandler(keyRing)))
+	}
+
+	// Bulk telemetry export (streams from the Telemetry Collector)
+	http.Handle("/export/telemetry", corsMiddleware(authMiddleware(export.Handler(resolver.TelemetryClient, resolver.AuthorizeDevices))))
+
+	// Bulk historical import (streams to the Telemetry Collector)
+	http.Handle("/import/telemetry", corsMiddleware(authMiddleware(importer.Handler(resolver.TelemetryClient, resolver.AuthorizeDevices))))
+
 	log.Println("=====================================")
 	log.Printf("API Gateway Service")
 	log.Println("=====================================")
-	log.Printf("Protocol: GraphQL (HTTP + WebSocket)")
+	log.Printf("Protocol: GraphQL (HTTP + WebSocket + SSE)")
 	log.Printf("Port: %s", port)
 	log.Printf("Device Manager: %s", deviceManagerAddr)
 	log.Printf("User Service: %s", userServiceAddr)
 	log.Printf("Telemetry Collector: %s", telemetryServiceAddr)
-	log.Printf("Redis: %s:%d", redisHost, redisPort)
+	log.Printf("Event bus: %s", busConfig.Transport)
+	log.Printf("JWT signing: %s", signingAlg)
 	log.Println("-------------------------------------")
 	log.Printf("📊 GraphQL Playground: http://localhost:%s/", port)
 	log.Printf("🔗 GraphQL API: http://localhost:%s/query", port)
+	log.Printf("📦 Telemetry export: http://localhost:%s/export/telemetry", port)
+	log.Printf("📥 Telemetry import: http://localhost:%s/import/telemetry", port)
+	log.Printf("📈 Metrics: http://localhost:%s/metrics", port)
+	if jwtManager.KeyRing() != nil {
+		log.Printf("🔑 JWKS: http://localhost:%s/.well-known/jwks.json", port)
+	}
 	log.Printf("💚 Health check: http://localhost:%s/health", port)
 	log.Println("=====================================")
 	log.Printf("✅ Server started")
@@ -262,3 +395,15 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvDuration retrieves an environment variable as a duration or returns a default value.
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("❌ Invalid %s: %v", key, err)
+	}
+	return duration
+}
@@ -0,0 +1,24 @@
+// Package metrics defines the Prometheus metrics exported by the API Gateway.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// SubscriptionDropped counts telemetry events a subscription did not
+	// deliver because its client fell behind, by overflow policy.
+	SubscriptionDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "api_gateway",
+		Name:      "subscription_dropped_total",
+		Help:      "Telemetry events dropped for slow subscribers, by overflow policy.",
+	}, []string{"policy"})
+
+	// SubscriptionDisconnects counts subscriptions ended by the disconnect policy.
+	SubscriptionDisconnects = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "api_gateway",
+		Name:      "subscription_disconnects_total",
+		Help:      "Subscriptions ended because their client fell behind.",
+	})
+)
@@ -1,81 +1,515 @@
 package pubsub
 
 import (
+	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/yourusername/iot-platform/services/api-gateway/graph/model"
+	"github.com/yourusername/iot-platform/services/api-gateway/metrics"
 )
 
-// Broker manages subscriptions for real-time telemetry data
+// DefaultBufferSize is the number of points a subscription may lag behind
+// before its overflow policy applies
+const DefaultBufferSize = 10
+
+// OverflowPolicy decides what happens to a point published while a
+// subscription's buffer is full
+type OverflowPolicy string
+
+// Overflow policies
+const (
+	// OverflowDropOldest drops the oldest buffered point to make room
+	OverflowDropOldest OverflowPolicy = "drop-oldest"
+	// OverflowDropNewest drops the new point
+	OverflowDropNewest OverflowPolicy = "drop-newest"
+	// OverflowCoalesce keeps only the latest buffered point of each device/metric
+	OverflowCoalesce OverflowPolicy = "coalesce"
+	// OverflowDisconnect ends the subscription
+	OverflowDisconnect OverflowPolicy = "disconnect"
+)
+
+// ParseOverflowPolicy validates an overflow policy name
+func ParseOverflowPolicy(s string) (OverflowPolicy, error) {
+	switch policy := OverflowPolicy(s); policy {
+	case OverflowDropOldest, OverflowDropNewest, OverflowCoalesce, OverflowDisconnect:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid overflow policy %q (drop-oldest, drop-newest, coalesce or disconnect)", s)
+	}
+}
+
+// Filter selects the telemetry delivered to a subscription. Devices are
+// selected either by ID or, for wildcard subscriptions, by type and metadata.
+type Filter struct {
+	DeviceIDs  []string
+	DeviceType string
+	Metadata   map[string]string
+	// OrgID restricts a wildcard subscription to the devices of an
+	// organization, all if empty
+	OrgID string
+
+	// MetricNames restricts the metrics received, all if empty
+	MetricNames []string
+	// Value restricts the values received
+	Value *Predicate
+	// MinInterval throttles each device/metric pair to one point per interval
+	MinInterval time.Duration
+	// Overflow applies when the subscriber falls behind, the broker's default if empty
+	Overflow OverflowPolicy
+}
+
+// Wildcard reports whether the filter selects devices by attributes rather than ID
+func (f *Filter) Wildcard() bool {
+	return len(f.DeviceIDs) == 0
+}
+
+// matchDevice reports whether a device matches the type and metadata of a
+// wildcard filter
+func (f *Filter) matchDevice(info *DeviceInfo) bool {
+	if info == nil {
+		return false
+	}
+	if f.OrgID != "" && info.OrgID != f.OrgID {
+		return false
+	}
+	if f.DeviceType != "" && info.Type != f.DeviceType {
+		return false
+	}
+	for k, v := range f.Metadata {
+		if info.Metadata[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Predicate is a condition on a value; set bounds are combined
+type Predicate struct {
+	Gt, Gte, Lt, Lte *float64
+}
+
+// Match reports whether v satisfies every bound
+func (p *Predicate) Match(v float64) bool {
+	return (p.Gt == nil || v > *p.Gt) &&
+		(p.Gte == nil || v >= *p.Gte) &&
+		(p.Lt == nil || v < *p.Lt) &&
+		(p.Lte == nil || v <= *p.Lte)
+}
+
+// Subscription receives the telemetry points matching its filter on C
+type Subscription struct {
+	C chan *model.TelemetryPoint
+
+	filter  Filter
+	metrics map[string]struct{}
+	dropped atomic.Int64 // points dropped since the last TakeDropped
+	done    chan struct{}
+
+	mu           sync.Mutex
+	lastSent     map[string]time.Time // device/metric -> time of the last point sent
+	disconnected bool
+}
+
+// Done is closed when the disconnect policy ends the subscription; the
+// subscriber should then Unsubscribe.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// TakeDropped returns the number of points dropped since the previous call
+func (s *Subscription) TakeDropped() int64 {
+	return s.dropped.Swap(0)
+}
+
+// pointKey identifies the series of a point for throttling and coalescing
+func pointKey(deviceID string, point *model.TelemetryPoint) string {
+	metric := ""
+	if point.MetricName != nil {
+		metric = *point.MetricName
+	}
+	return deviceID + "\x00" + metric
+}
+
+// deliver sends a point if it passes the metric, value and throttling
+// filters. It never blocks.
+func (s *Subscription) deliver(deviceID string, point *model.TelemetryPoint) {
+	metric := ""
+	if point.MetricName != nil {
+		metric = *point.MetricName
+	}
+	if s.metrics != nil {
+		if _, ok := s.metrics[metric]; !ok {
+			return
+		}
+	}
+	if s.filter.Value != nil && !s.filter.Value.Match(point.Value) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.disconnected {
+		return
+	}
+
+	key := pointKey(deviceID, point)
+	if s.filter.MinInterval > 0 {
+		now := time.Now()
+		if last, ok := s.lastSent[key]; ok && now.Sub(last) < s.filter.MinInterval {
+			return
+		}
+		s.lastSent[key] = now
+	}
+
+	// Non-blocking send to avoid slow subscribers blocking others
+	select {
+	case s.C <- point:
+		return
+	default:
+	}
+
+	// Buffer full: apply the overflow policy
+	var dropped int
+	switch s.filter.Overflow {
+	case OverflowDropOldest:
+		dropped = s.dropOldest(point)
+	case OverflowCoalesce:
+		dropped = s.coalesce(key, point)
+	case OverflowDisconnect:
+		s.disconnected = true
+		close(s.done)
+		metrics.SubscriptionDisconnects.Inc()
+		return
+	default:
+		dropped = 1
+	}
+	s.dropped.Add(int64(dropped))
+	metrics.SubscriptionDropped.WithLabelValues(string(s.filter.Overflow)).Add(float64(dropped))
+}
+
+// dropOldest makes room for point by discarding buffered points, and returns
+// the number of points dropped. The reader may free room concurrently.
+func (s *Subscription) dropOldest(point *model.TelemetryPoint) int {
+	dropped := 0
+	for {
+		select {
+		case s.C <- point:
+			return dropped
+		default:
+		}
+		select {
+		case <-s.C:
+			dropped++
+		default:
+		}
+	}
+}
+
+// coalesce replaces the buffered points of each series by the latest one, in
+// order of their latest point, and returns the number of points dropped.
+// Points beyond the buffer, oldest first, are dropped too.
+func (s *Subscription) coalesce(key string, point *model.TelemetryPoint) int {
+	type entry struct {
+		key   string
+		point *model.TelemetryPoint
+	}
+	var buffered []entry
+	for drained := false; !drained; {
+		select {
+		case p := <-s.C:
+			buffered = append(buffered, entry{pointKey(stringValue(p.DeviceID), p), p})
+		default:
+			drained = true
+		}
+	}
+	buffered = append(buffered, entry{key, point})
+
+	// Keep the last point of each series
+	last := make(map[string]int, len(buffered))
+	for i, e := range buffered {
+		last[e.key] = i
+	}
+	kept := make([]*model.TelemetryPoint, 0, len(last))
+	for i, e := range buffered {
+		if last[e.key] == i {
+			kept = append(kept, e.point)
+		}
+	}
+	if len(kept) > cap(s.C) {
+		kept = kept[len(kept)-cap(s.C):]
+	}
+
+	for _, p := range kept {
+		s.C <- p // room guaranteed: only this goroutine writes, under s.mu
+	}
+	return len(buffered) - len(kept)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// Config configures a broker
+type Config struct {
+	// Index resolves the devices of wildcard subscriptions (default: only
+	// devices announced by device events)
+	Index *DeviceIndex
+	// BufferSize is the number of points buffered per subscription (default: 10)
+	BufferSize int
+	// Overflow is the default overflow policy (default: drop-newest)
+	Overflow OverflowPolicy
+}
+
+// Broker manages subscriptions for real-time telemetry data and device updates
 type Broker struct {
-	subscribers map[string]map[chan *model.TelemetryPoint]struct{} // deviceID -> set of channels
+	subscribers map[string]map[*Subscription]struct{} // deviceID -> subscriptions by ID
+	wildcards   map[*Subscription]struct{}
+	devices     map[chan *model.Device]string // organization of each device subscription
+	changes     chan struct{}                 // signaled when the watched devices change
+	cfg         Config
 	mu          sync.RWMutex
 }
 
-// NewBroker creates a new subscription broker
+// NewBroker creates a new subscription broker with the default configuration
 func NewBroker() *Broker {
+	return NewBrokerWithConfig(Config{})
+}
+
+// NewBrokerWithConfig creates a new subscription broker
+func NewBrokerWithConfig(cfg Config) *Broker {
+	if cfg.Index == nil {
+		cfg.Index = NewDeviceIndex(nil, time.Hour)
+	}
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = DefaultBufferSize
+	}
+	if cfg.Overflow == "" {
+		cfg.Overflow = OverflowDropNewest
+	}
 	return &Broker{
-		subscribers: make(map[string]map[chan *model.TelemetryPoint]struct{}),
+		subscribers: make(map[string]map[*Subscription]struct{}),
+		wildcards:   make(map[*Subscription]struct{}),
+		devices:     make(map[chan *model.Device]string),
+		changes:     make(chan struct{}, 1),
+		cfg:         cfg,
+	}
+}
+
+// Subscribe creates a new subscription for the telemetry matching filter
+func (b *Broker) Subscribe(filter Filter) *Subscription {
+	if filter.Overflow == "" {
+		filter.Overflow = b.cfg.Overflow
+	}
+	sub := &Subscription{
+		C:        make(chan *model.TelemetryPoint, b.cfg.BufferSize),
+		filter:   filter,
+		done:     make(chan struct{}),
+		lastSent: make(map[string]time.Time),
+	}
+	if len(filter.MetricNames) > 0 {
+		sub.metrics = make(map[string]struct{}, len(filter.MetricNames))
+		for _, name := range filter.MetricNames {
+			sub.metrics[name] = struct{}{}
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if filter.Wildcard() {
+		b.wildcards[sub] = struct{}{}
+		if len(b.wildcards) == 1 {
+			b.notify()
+		}
+		return sub
+	}
+	for _, deviceID := range filter.DeviceIDs {
+		if b.subscribers[deviceID] == nil {
+			b.subscribers[deviceID] = make(map[*Subscription]struct{})
+			b.notify()
+		}
+		b.subscribers[deviceID][sub] = struct{}{}
 	}
+	return sub
 }
 
-// Subscribe creates a new subscription channel for a device
-func (b *Broker) Subscribe(deviceID string) chan *model.TelemetryPoint {
+// Unsubscribe removes a subscription and closes its channel
+func (b *Broker) Unsubscribe(sub *Subscription) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	ch := make(chan *model.TelemetryPoint, 10) // buffered channel
+	if sub.filter.Wildcard() {
+		if _, ok := b.wildcards[sub]; !ok {
+			return
+		}
+		delete(b.wildcards, sub)
+		close(sub.C)
+		if len(b.wildcards) == 0 {
+			b.notify()
+		}
+		return
+	}
+
+	found := false
+	for _, deviceID := range sub.filter.DeviceIDs {
+		if subs, ok := b.subscribers[deviceID]; ok {
+			if _, ok := subs[sub]; ok {
+				found = true
+				delete(subs, sub)
+			}
+			// Clean up empty device entries
+			if len(subs) == 0 {
+				delete(b.subscribers, deviceID)
+				b.notify()
+			}
+		}
+	}
+	if found {
+		close(sub.C)
+	}
+}
 
-	if b.subscribers[deviceID] == nil {
-		b.subscribers[deviceID] = make(map[chan *model.TelemetryPoint]struct{})
+// Publish sends a telemetry point to all subscriptions matching it
+func (b *Broker) Publish(deviceID string, point *model.TelemetryPoint) {
+	// Resolve the device outside the lock: a miss may query the Device Manager
+	b.mu.RLock()
+	hasWildcards := len(b.wildcards) > 0
+	b.mu.RUnlock()
+	var info *DeviceInfo
+	if hasWildcards {
+		info = b.cfg.Index.Get(deviceID)
 	}
-	b.subscribers[deviceID][ch] = struct{}{}
 
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for sub := range b.subscribers[deviceID] {
+		sub.deliver(deviceID, point)
+	}
+	for sub := range b.wildcards {
+		if sub.filter.matchDevice(info) {
+			sub.deliver(deviceID, point)
+		}
+	}
+}
+
+// SubscribeDevices creates a new subscription channel for updates of the
+// devices of an organization, all if orgID is empty
+func (b *Broker) SubscribeDevices(orgID string) chan *model.Device {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan *model.Device, 10)
+	b.devices[ch] = orgID
 	return ch
 }
 
-// Unsubscribe removes a subscription channel
-func (b *Broker) Unsubscribe(deviceID string, ch chan *model.TelemetryPoint) {
+// UnsubscribeDevices removes a device updates subscription channel
+func (b *Broker) UnsubscribeDevices(ch chan *model.Device) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	if subs, ok := b.subscribers[deviceID]; ok {
-		delete(subs, ch)
+	if _, ok := b.devices[ch]; ok {
+		delete(b.devices, ch)
 		close(ch)
-
-		// Clean up empty device entries
-		if len(subs) == 0 {
-			delete(b.subscribers, deviceID)
-		}
 	}
 }
 
-// Publish sends a telemetry point to all subscribers of a device
-func (b *Broker) Publish(deviceID string, point *model.TelemetryPoint) {
+// PublishDevice records a created or updated device for wildcard
+// subscriptions and sends it to all device subscribers
+func (b *Broker) PublishDevice(device *model.Device) {
+	metadata := make(map[string]string, len(device.Metadata))
+	for _, entry := range device.Metadata {
+		metadata[entry.Key] = entry.Value
+	}
+	b.cfg.Index.Set(device.ID, &DeviceInfo{Type: device.Type, Metadata: metadata, OrgID: device.OrganizationID})
+
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
-	if subs, ok := b.subscribers[deviceID]; ok {
-		for ch := range subs {
-			// Non-blocking send to avoid slow subscribers blocking others
-			select {
-			case ch <- point:
-			default:
-				// Channel full, skip this message for this subscriber
-			}
+	for ch, orgID := range b.devices {
+		if orgID != "" && orgID != device.OrganizationID {
+			continue
+		}
+		// Non-blocking send to avoid slow subscribers blocking others
+		select {
+		case ch <- device:
+		default:
 		}
 	}
 }
 
-// SubscriberCount returns the number of active subscribers for a device
+// RemoveDevice forgets a deleted device
+func (b *Broker) RemoveDevice(deviceID string) {
+	b.cfg.Index.Set(deviceID, nil)
+}
+
+// SubscriberCount returns the number of active subscriptions to a device by ID
 func (b *Broker) SubscriberCount(deviceID string) int {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
-	if subs, ok := b.subscribers[deviceID]; ok {
-		return len(subs)
+	return len(b.subscribers[deviceID])
+}
+
+// notify signals a change of the watched devices without blocking
+func (b *Broker) notify() {
+	select {
+	case b.changes <- struct{}{}:
+	default:
 	}
-	return 0
+}
+
+// Changes is signaled when the devices with local subscribers change: a
+// device gains its first subscription or loses its last one, or wildcard
+// subscriptions start or stop. Signals coalesce; it has a single reader.
+func (b *Broker) Changes() <-chan struct{} {
+	return b.changes
+}
+
+// WatchedDevices returns the devices with local subscriptions, and whether
+// wildcard subscriptions need the telemetry of all devices
+func (b *Broker) WatchedDevices() (deviceIDs []string, all bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	deviceIDs = make([]string, 0, len(b.subscribers))
+	for deviceID := range b.subscribers {
+		deviceIDs = append(deviceIDs, deviceID)
+	}
+	return deviceIDs, len(b.wildcards) > 0
+}
+
+// Stats counts the subscriptions of a broker
+type Stats struct {
+	// Subscriptions is the number of active subscriptions
+	Subscriptions int
+	// Wildcards is the number of subscriptions selecting devices by type or metadata
+	Wildcards int
+	// Devices is the number of subscriptions per device ID, wildcards excluded
+	Devices map[string]int
+}
+
+// Stats returns the subscription counts of the broker
+func (b *Broker) Stats() Stats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	stats := Stats{Wildcards: len(b.wildcards), Devices: make(map[string]int, len(b.subscribers))}
+	subs := make(map[*Subscription]struct{})
+	for deviceID, deviceSubs := range b.subscribers {
+		stats.Devices[deviceID] = len(deviceSubs)
+		for sub := range deviceSubs {
+			subs[sub] = struct{}{}
+		}
+	}
+	stats.Subscriptions = len(subs) + len(b.wildcards)
+	return stats
 }
 
 // TotalSubscribers returns the total number of active subscriptions
@@ -83,9 +517,11 @@ func (b *Broker) TotalSubscribers() int {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
-	count := 0
-	for _, subs := range b.subscribers {
-		count += len(subs)
+	subs := make(map[*Subscription]struct{})
+	for _, deviceSubs := range b.subscribers {
+		for sub := range deviceSubs {
+			subs[sub] = struct{}{}
+		}
 	}
-	return count
+	return len(subs) + len(b.wildcards)
 }
@@ -0,0 +1,226 @@
+package pubsub
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/graph/model"
+)
+
+func point(deviceID, metric string, value float64) *model.TelemetryPoint {
+	return &model.TelemetryPoint{Time: 1, Value: value, DeviceID: &deviceID, MetricName: &metric}
+}
+
+// drain returns the points buffered on a subscription
+func drain(sub *Subscription) []*model.TelemetryPoint {
+	var points []*model.TelemetryPoint
+	for {
+		select {
+		case p := <-sub.C:
+			points = append(points, p)
+		default:
+			return points
+		}
+	}
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
+
+func TestBroker_MetricAndValueFilters(t *testing.T) {
+	broker := NewBroker()
+	sub := broker.Subscribe(Filter{
+		DeviceIDs:   []string{"dev-1", "dev-2"},
+		MetricNames: []string{"temperature"},
+		Value:       &Predicate{Gt: floatPtr(20), Lte: floatPtr(30)},
+	})
+	defer broker.Unsubscribe(sub)
+
+	broker.Publish("dev-1", point("dev-1", "temperature", 25))
+	broker.Publish("dev-1", point("dev-1", "humidity", 25))    // other metric
+	broker.Publish("dev-2", point("dev-2", "temperature", 20)) // not > 20
+	broker.Publish("dev-2", point("dev-2", "temperature", 30))
+	broker.Publish("dev-3", point("dev-3", "temperature", 25)) // other device
+
+	points := drain(sub)
+	if len(points) != 2 || points[0].Value != 25 || points[1].Value != 30 || *points[1].DeviceID != "dev-2" {
+		t.Errorf("unexpected points %+v", points)
+	}
+}
+
+func TestBroker_Throttling(t *testing.T) {
+	broker := NewBroker()
+	sub := broker.Subscribe(Filter{DeviceIDs: []string{"dev-1"}, MinInterval: 50 * time.Millisecond})
+	defer broker.Unsubscribe(sub)
+
+	broker.Publish("dev-1", point("dev-1", "temperature", 1))
+	broker.Publish("dev-1", point("dev-1", "temperature", 2)) // throttled
+	broker.Publish("dev-1", point("dev-1", "humidity", 3))    // other metric, own budget
+	time.Sleep(60 * time.Millisecond)
+	broker.Publish("dev-1", point("dev-1", "temperature", 4))
+
+	points := drain(sub)
+	if len(points) != 3 || points[0].Value != 1 || points[1].Value != 3 || points[2].Value != 4 {
+		t.Errorf("unexpected points %+v", points)
+	}
+}
+
+func TestBroker_Wildcards(t *testing.T) {
+	loads := 0
+	index := NewDeviceIndex(func(ctx context.Context, deviceID string) (*DeviceInfo, error) {
+		loads++
+		if deviceID == "dev-3" {
+			return &DeviceInfo{Type: "thermometer", Metadata: map[string]string{"group": "north"}}, nil
+		}
+		return nil, nil
+	}, time.Minute)
+	broker := NewBrokerWithConfig(Config{Index: index})
+
+	byType := broker.Subscribe(Filter{DeviceType: "thermometer"})
+	defer broker.Unsubscribe(byType)
+	byGroup := broker.Subscribe(Filter{DeviceType: "thermometer", Metadata: map[string]string{"group": "north"}})
+	defer broker.Unsubscribe(byGroup)
+
+	broker.PublishDevice(&model.Device{ID: "dev-1", Type: "thermometer"})
+	broker.PublishDevice(&model.Device{ID: "dev-2", Type: "thermometer", Metadata: []*model.MetadataEntry{{Key: "group", Value: "north"}}})
+
+	broker.Publish("dev-1", point("dev-1", "temperature", 1))
+	broker.Publish("dev-2", point("dev-2", "temperature", 2))
+	broker.Publish("dev-3", point("dev-3", "temperature", 3)) // loaded
+	broker.Publish("dev-4", point("dev-4", "temperature", 4)) // unknown
+	broker.Publish("dev-4", point("dev-4", "temperature", 5)) // unknown, cached
+
+	if points := drain(byType); len(points) != 3 {
+		t.Errorf("expected 3 points by type, got %d", len(points))
+	}
+	if points := drain(byGroup); len(points) != 2 || points[0].Value != 2 || points[1].Value != 3 {
+		t.Errorf("unexpected points by group %+v", points)
+	}
+	if loads != 2 {
+		t.Errorf("expected 2 device loads, got %d", loads)
+	}
+
+	// A deleted device no longer matches
+	broker.RemoveDevice("dev-1")
+	broker.Publish("dev-1", point("dev-1", "temperature", 6))
+	if points := drain(byType); len(points) != 0 {
+		t.Errorf("expected no point from a deleted device, got %+v", points)
+	}
+}
+
+func TestBroker_Unsubscribe(t *testing.T) {
+	broker := NewBroker()
+	sub := broker.Subscribe(Filter{DeviceIDs: []string{"dev-1", "dev-2"}})
+	wildcard := broker.Subscribe(Filter{DeviceType: "thermometer"})
+
+	if n := broker.TotalSubscribers(); n != 2 {
+		t.Errorf("expected 2 subscriptions, got %d", n)
+	}
+	broker.Unsubscribe(sub)
+	broker.Unsubscribe(sub) // idempotent
+	broker.Unsubscribe(wildcard)
+
+	if _, ok := <-sub.C; ok {
+		t.Error("expected closed channel")
+	}
+	if n := broker.SubscriberCount("dev-1") + broker.TotalSubscribers(); n != 0 {
+		t.Errorf("expected no subscription, got %d", n)
+	}
+}
+
+func TestBroker_OverflowPolicies(t *testing.T) {
+	tests := []struct {
+		policy      OverflowPolicy
+		want        []float64
+		wantDropped int64
+	}{
+		// Buffer of 2, points 1..4 on temperature then 5 on humidity
+		{OverflowDropNewest, []float64{1, 2}, 3},
+		{OverflowDropOldest, []float64{4, 5}, 3},
+		{OverflowCoalesce, []float64{4, 5}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			broker := NewBrokerWithConfig(Config{BufferSize: 2})
+			sub := broker.Subscribe(Filter{DeviceIDs: []string{"dev-1"}, Overflow: tt.policy})
+			defer broker.Unsubscribe(sub)
+
+			for i := 1; i <= 4; i++ {
+				broker.Publish("dev-1", point("dev-1", "temperature", float64(i)))
+			}
+			broker.Publish("dev-1", point("dev-1", "humidity", 5))
+
+			var values []float64
+			for _, p := range drain(sub) {
+				values = append(values, p.Value)
+			}
+			if len(values) != len(tt.want) || values[0] != tt.want[0] || values[1] != tt.want[1] {
+				t.Errorf("points = %v, want %v", values, tt.want)
+			}
+			if dropped := sub.TakeDropped(); dropped != tt.wantDropped {
+				t.Errorf("TakeDropped() = %d, want %d", dropped, tt.wantDropped)
+			}
+			if dropped := sub.TakeDropped(); dropped != 0 {
+				t.Errorf("TakeDropped() after take = %d, want 0", dropped)
+			}
+		})
+	}
+}
+
+func TestBroker_OverflowCoalesceKeepsSeriesOrder(t *testing.T) {
+	broker := NewBrokerWithConfig(Config{BufferSize: 3})
+	sub := broker.Subscribe(Filter{DeviceIDs: []string{"dev-1", "dev-2"}, Overflow: OverflowCoalesce})
+	defer broker.Unsubscribe(sub)
+
+	broker.Publish("dev-1", point("dev-1", "temperature", 1))
+	broker.Publish("dev-2", point("dev-2", "temperature", 2))
+	broker.Publish("dev-1", point("dev-1", "temperature", 3))
+	broker.Publish("dev-2", point("dev-2", "temperature", 4)) // full: coalesce
+
+	points := drain(sub)
+	if len(points) != 2 || points[0].Value != 3 || points[1].Value != 4 {
+		t.Errorf("unexpected points %+v", points)
+	}
+}
+
+func TestBroker_OverflowDisconnect(t *testing.T) {
+	broker := NewBrokerWithConfig(Config{BufferSize: 1, Overflow: OverflowDisconnect})
+	sub := broker.Subscribe(Filter{DeviceIDs: []string{"dev-1"}})
+
+	broker.Publish("dev-1", point("dev-1", "temperature", 1))
+	select {
+	case <-sub.Done():
+		t.Fatal("subscription disconnected before overflow")
+	default:
+	}
+
+	broker.Publish("dev-1", point("dev-1", "temperature", 2))
+	broker.Publish("dev-1", point("dev-1", "temperature", 3)) // ignored after disconnect
+	select {
+	case <-sub.Done():
+	default:
+		t.Fatal("subscription not disconnected on overflow")
+	}
+
+	broker.Unsubscribe(sub)
+	var values []float64
+	for p := range sub.C {
+		values = append(values, p.Value)
+	}
+	if len(values) != 1 || values[0] != 1 {
+		t.Errorf("points = %v, want [1]", values)
+	}
+}
+
+func TestParseOverflowPolicy(t *testing.T) {
+	for _, name := range []string{"drop-oldest", "drop-newest", "coalesce", "disconnect"} {
+		if policy, err := ParseOverflowPolicy(name); err != nil || string(policy) != name {
+			t.Errorf("ParseOverflowPolicy(%q) = %q, %v", name, policy, err)
+		}
+	}
+	if _, err := ParseOverflowPolicy("block"); err == nil {
+		t.Error("ParseOverflowPolicy(block) should fail")
+	}
+}
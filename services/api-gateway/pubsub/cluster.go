@@ -0,0 +1,144 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yourusername/iot-platform/shared/eventbus"
+)
+
+// ReplicaStats are the subscription counts of a gateway replica
+type ReplicaStats struct {
+	Replica string
+	Stats
+	UpdatedAt time.Time
+}
+
+// Cluster shares the subscription counts of the gateway replicas: each
+// replica publishes its broker's counts on "gateways.<replica>" every
+// interval, and collects the others'. A replica silent for three intervals
+// is considered gone.
+type Cluster struct {
+	bus      eventbus.Bus
+	broker   *Broker
+	replica  string
+	interval time.Duration
+	cancel   context.CancelFunc
+
+	mu       sync.RWMutex
+	replicas map[string]*ReplicaStats
+}
+
+// NewCluster subscribes to the counts of the other replicas and starts
+// publishing those of broker as replica
+func NewCluster(ctx context.Context, bus eventbus.Bus, broker *Broker, replica string, interval time.Duration) (*Cluster, error) {
+	clusterCtx, cancel := context.WithCancel(ctx)
+	c := &Cluster{
+		bus:      bus,
+		broker:   broker,
+		replica:  replica,
+		interval: interval,
+		cancel:   cancel,
+		replicas: make(map[string]*ReplicaStats),
+	}
+
+	pattern := eventbus.Subject(eventbus.SubjectGateways, ">")
+	if err := bus.Subscribe(clusterCtx, pattern, c.handleStats); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", pattern, err)
+	}
+	go c.publishLoop(clusterCtx)
+	return c, nil
+}
+
+// publishLoop publishes the local counts every interval
+func (c *Cluster) publishLoop(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.publish(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("⚠️ Failed to publish subscription stats: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Cluster) publish(ctx context.Context) error {
+	stats := c.broker.Stats()
+	data, err := json.Marshal(eventbus.SubscriptionStatsEvent{
+		Replica:       c.replica,
+		Subscriptions: stats.Subscriptions,
+		Wildcards:     stats.Wildcards,
+		Devices:       stats.Devices,
+		Timestamp:     time.Now().UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return err
+	}
+	return c.bus.Publish(ctx, eventbus.Subject(eventbus.SubjectGateways, c.replica), data)
+}
+
+// handleStats records the counts of a replica
+func (c *Cluster) handleStats(msg *eventbus.Message) {
+	var event eventbus.SubscriptionStatsEvent
+	if err := json.Unmarshal(msg.Data, &event); err != nil {
+		log.Printf("⚠️ Failed to unmarshal subscription stats %s: %v", msg.ID, err)
+		return
+	}
+	if event.Replica == "" || event.Replica == c.replica {
+		return
+	}
+	// Events replayed after a restart must not revive stopped replicas
+	updatedAt, err := time.Parse(time.RFC3339Nano, event.Timestamp)
+	if err != nil {
+		updatedAt = time.Now()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.replicas[event.Replica] = &ReplicaStats{
+		Replica: event.Replica,
+		Stats: Stats{
+			Subscriptions: event.Subscriptions,
+			Wildcards:     event.Wildcards,
+			Devices:       event.Devices,
+		},
+		UpdatedAt: updatedAt,
+	}
+}
+
+// Replicas returns the counts of the live replicas sorted by name, the local
+// replica's being current
+func (c *Cluster) Replicas() []ReplicaStats {
+	replicas := []ReplicaStats{{Replica: c.replica, Stats: c.broker.Stats(), UpdatedAt: time.Now()}}
+
+	expiry := time.Now().Add(-3 * c.interval)
+	c.mu.Lock()
+	for name, stats := range c.replicas {
+		if stats.UpdatedAt.Before(expiry) {
+			delete(c.replicas, name)
+			continue
+		}
+		replicas = append(replicas, *stats)
+	}
+	c.mu.Unlock()
+
+	sort.Slice(replicas, func(i, j int) bool { return replicas[i].Replica < replicas[j].Replica })
+	return replicas
+}
+
+// Close stops publishing. The bus is closed by its owner.
+func (c *Cluster) Close() error {
+	c.cancel()
+	return nil
+}
@@ -0,0 +1,275 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/yourusername/iot-platform/shared/eventbus"
+)
+
+// routingBus is a transport routing subjects
+type routingBus interface {
+	eventbus.Bus
+	eventbus.SubjectSubscriber
+}
+
+// replicaBus is the view of a shared bus from one gateway replica. It
+// records the telemetry subjects the replica subscribed to and the messages
+// it received.
+type replicaBus struct {
+	routingBus
+
+	mu       sync.Mutex
+	subjects map[string]struct{}
+	received []string
+}
+
+func newReplicaBus(bus routingBus) *replicaBus {
+	return &replicaBus{routingBus: bus, subjects: make(map[string]struct{})}
+}
+
+func (b *replicaBus) SubscribeSubjects(ctx context.Context, handler eventbus.Handler) (eventbus.SubjectSet, error) {
+	set, err := b.routingBus.SubscribeSubjects(ctx, func(msg *eventbus.Message) {
+		b.mu.Lock()
+		b.received = append(b.received, msg.Subject)
+		b.mu.Unlock()
+		handler(msg)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &replicaSubjects{SubjectSet: set, bus: b}, nil
+}
+
+// routed returns the subjects the replica subscribed to, sorted
+func (b *replicaBus) routed() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	subjects := make([]string, 0, len(b.subjects))
+	for subject := range b.subjects {
+		subjects = append(subjects, subject)
+	}
+	sort.Strings(subjects)
+	return subjects
+}
+
+// takeReceived returns the subjects received since the previous call
+func (b *replicaBus) takeReceived() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	received := b.received
+	b.received = nil
+	return received
+}
+
+type replicaSubjects struct {
+	eventbus.SubjectSet
+	bus *replicaBus
+}
+
+func (s *replicaSubjects) Add(ctx context.Context, subjects ...string) error {
+	if err := s.SubjectSet.Add(ctx, subjects...); err != nil {
+		return err
+	}
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	for _, subject := range subjects {
+		s.bus.subjects[subject] = struct{}{}
+	}
+	return nil
+}
+
+func (s *replicaSubjects) Remove(ctx context.Context, subjects ...string) error {
+	if err := s.SubjectSet.Remove(ctx, subjects...); err != nil {
+		return err
+	}
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	for _, subject := range subjects {
+		delete(s.bus.subjects, subject)
+	}
+	return nil
+}
+
+// eventually polls cond until it holds or a second has passed
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func waitRouted(t *testing.T, bus *replicaBus, want ...string) {
+	t.Helper()
+	eventually(t, "subjects "+join(want), func() bool { return join(bus.routed()) == join(want) })
+}
+
+func join(subjects []string) string {
+	return strings.Join(subjects, ",")
+}
+
+func publishTelemetry(t *testing.T, bus eventbus.Bus, deviceIDs ...string) {
+	t.Helper()
+	for _, deviceID := range deviceIDs {
+		data, _ := json.Marshal(eventbus.TelemetryEvent{DeviceID: deviceID, MetricName: "temperature", Value: 1})
+		if err := bus.Publish(context.Background(), eventbus.Subject(eventbus.SubjectTelemetry, deviceID), data); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+}
+
+// TestSubscriber_DynamicRouting runs two replicas on a shared bus and checks
+// that each only receives the telemetry of the devices its clients watch.
+func TestSubscriber_DynamicRouting(t *testing.T) {
+	shared := eventbus.NewMemory(0)
+	defer shared.Close()
+	testDynamicRouting(t, shared, newReplicaBus(shared), newReplicaBus(shared))
+}
+
+// TestSubscriber_DynamicRoutingRedis runs the replicas on the default Redis
+// transport (streams), each with its own connection and consumer group.
+func TestSubscriber_DynamicRoutingRedis(t *testing.T) {
+	s := miniredis.RunT(t)
+	port, _ := strconv.Atoi(s.Port())
+	connect := func(replica string) *eventbus.Redis {
+		bus, err := eventbus.NewRedis(context.Background(), eventbus.RedisConfig{
+			Host: s.Host(), Port: port, Group: "api-gateway-" + replica, Consumer: replica,
+		})
+		if err != nil {
+			t.Fatalf("NewRedis failed: %v", err)
+		}
+		t.Cleanup(func() { bus.Close() })
+		return bus
+	}
+	testDynamicRouting(t, connect("publisher"), newReplicaBus(connect("a")), newReplicaBus(connect("b")))
+}
+
+func testDynamicRouting(t *testing.T, publisher eventbus.Bus, busA, busB *replicaBus) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	brokerA, brokerB := NewBroker(), NewBroker()
+	for _, replica := range []struct {
+		bus    *replicaBus
+		broker *Broker
+	}{{busA, brokerA}, {busB, brokerB}} {
+		subscriber, err := NewSubscriber(ctx, replica.bus, replica.broker)
+		if err != nil {
+			t.Fatalf("NewSubscriber failed: %v", err)
+		}
+		defer subscriber.Close()
+	}
+	waitRouted(t, busA)
+
+	subA := brokerA.Subscribe(Filter{DeviceIDs: []string{"dev-1"}})
+	subA2 := brokerA.Subscribe(Filter{DeviceIDs: []string{"dev-1", "dev-2"}})
+	subB := brokerB.Subscribe(Filter{DeviceIDs: []string{"dev-2"}})
+	waitRouted(t, busA, "telemetry.dev-1", "telemetry.dev-2")
+	waitRouted(t, busB, "telemetry.dev-2")
+
+	publishTelemetry(t, publisher, "dev-1", "dev-2", "dev-3")
+	eventually(t, "points delivered", func() bool { return len(subA.C) == 1 && len(subA2.C) == 2 && len(subB.C) == 1 })
+	if received := busB.takeReceived(); join(received) != "telemetry.dev-2" {
+		t.Errorf("replica B received %v, want only telemetry.dev-2", received)
+	}
+	if received := busA.takeReceived(); join(received) != "telemetry.dev-1,telemetry.dev-2" {
+		t.Errorf("replica A received %v", received)
+	}
+
+	// dev-2 stays watched on A until its last subscription ends
+	brokerA.Unsubscribe(subA2)
+	waitRouted(t, busA, "telemetry.dev-1")
+	brokerA.Unsubscribe(subA)
+	waitRouted(t, busA)
+
+	// A wildcard subscription needs the telemetry of all devices
+	wildcard := brokerB.Subscribe(Filter{DeviceType: "thermometer"})
+	waitRouted(t, busB, "telemetry.>")
+	brokerB.Unsubscribe(wildcard)
+	waitRouted(t, busB, "telemetry.dev-2")
+	brokerB.Unsubscribe(subB)
+	waitRouted(t, busB)
+
+	busA.takeReceived()
+	busB.takeReceived()
+	publishTelemetry(t, publisher, "dev-1", "dev-2")
+	time.Sleep(500 * time.Millisecond)
+	if received := append(busA.takeReceived(), busB.takeReceived()...); len(received) != 0 {
+		t.Errorf("replicas without subscribers received %v", received)
+	}
+}
+
+// TestSubscriber_AllTelemetry checks the fallback for transports that do
+// not route subjects.
+func TestSubscriber_AllTelemetry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := struct{ eventbus.Bus }{eventbus.NewMemory(0)} // hides SubscribeSubjects
+	defer bus.Close()
+	broker := NewBroker()
+	subscriber, err := NewSubscriber(ctx, bus, broker)
+	if err != nil {
+		t.Fatalf("NewSubscriber failed: %v", err)
+	}
+	defer subscriber.Close()
+
+	sub := broker.Subscribe(Filter{DeviceIDs: []string{"dev-1"}})
+	defer broker.Unsubscribe(sub)
+	publishTelemetry(t, bus, "dev-1")
+	select {
+	case <-sub.C:
+	case <-time.After(time.Second):
+		t.Fatal("point not delivered")
+	}
+}
+
+func TestCluster_Replicas(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := eventbus.NewMemory(0)
+	defer bus.Close()
+	brokerA, brokerB := NewBroker(), NewBroker()
+	interval := 20 * time.Millisecond
+	clusterA, err := NewCluster(ctx, bus, brokerA, "gw-a", interval)
+	if err != nil {
+		t.Fatalf("NewCluster failed: %v", err)
+	}
+	defer clusterA.Close()
+	clusterB, err := NewCluster(ctx, bus, brokerB, "gw-b", interval)
+	if err != nil {
+		t.Fatalf("NewCluster failed: %v", err)
+	}
+
+	brokerA.Subscribe(Filter{DeviceIDs: []string{"dev-1", "dev-2"}})
+	brokerB.Subscribe(Filter{DeviceIDs: []string{"dev-1"}})
+	brokerB.Subscribe(Filter{DeviceType: "thermometer"})
+
+	eventually(t, "replica gw-b", func() bool {
+		replicas := clusterA.Replicas()
+		return len(replicas) == 2 && replicas[1].Subscriptions == 2
+	})
+	replicas := clusterA.Replicas()
+	if replicas[0].Replica != "gw-a" || replicas[0].Subscriptions != 1 || len(replicas[0].Devices) != 2 {
+		t.Errorf("unexpected local stats %+v", replicas[0])
+	}
+	if b := replicas[1]; b.Replica != "gw-b" || b.Wildcards != 1 || b.Devices["dev-1"] != 1 {
+		t.Errorf("unexpected stats for gw-b %+v", b)
+	}
+
+	// A stopped replica expires after three intervals
+	clusterB.Close()
+	eventually(t, "gw-b expiry", func() bool { return len(clusterA.Replicas()) == 1 })
+}
@@ -0,0 +1,99 @@
+package pubsub
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	devicepb "github.com/yourusername/iot-platform/shared/proto/device"
+)
+
+// DeviceInfo holds the device attributes wildcard subscriptions select on
+type DeviceInfo struct {
+	Type     string
+	Metadata map[string]string
+	OrgID    string
+}
+
+// DeviceLoader fetches a device, returning nil for an unknown device
+type DeviceLoader func(ctx context.Context, deviceID string) (*DeviceInfo, error)
+
+// DeviceClientLoader loads devices of every organization from the Device Manager
+func DeviceClientLoader(client devicepb.DeviceServiceClient) DeviceLoader {
+	return func(ctx context.Context, deviceID string) (*DeviceInfo, error) {
+		resp, err := client.GetDevice(ctx, &devicepb.GetDeviceRequest{Id: deviceID})
+		if status.Code(err) == codes.NotFound {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &DeviceInfo{Type: resp.Device.Type, Metadata: resp.Device.Metadata, OrgID: resp.Device.OrgId}, nil
+	}
+}
+
+// deviceLoadTimeout bounds a lookup done on the publish path
+const deviceLoadTimeout = 2 * time.Second
+
+type deviceEntry struct {
+	info    *DeviceInfo // nil: unknown device
+	expires time.Time
+}
+
+// DeviceIndex caches device attributes for wildcard subscriptions. It is kept
+// up to date by device events and falls back to the loader on a miss.
+type DeviceIndex struct {
+	loader DeviceLoader
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	entries map[string]deviceEntry
+}
+
+// NewDeviceIndex creates an index whose loaded entries expire after ttl. A nil
+// loader only knows devices announced by device events.
+func NewDeviceIndex(loader DeviceLoader, ttl time.Duration) *DeviceIndex {
+	return &DeviceIndex{
+		loader:  loader,
+		ttl:     ttl,
+		entries: make(map[string]deviceEntry),
+	}
+}
+
+// Get returns the attributes of a device, or nil if it is unknown or cannot
+// be loaded. Load failures are not cached.
+func (x *DeviceIndex) Get(deviceID string) *DeviceInfo {
+	x.mu.Lock()
+	entry, ok := x.entries[deviceID]
+	x.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.info
+	}
+	if x.loader == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), deviceLoadTimeout)
+	defer cancel()
+	info, err := x.loader(ctx, deviceID)
+	if err != nil {
+		log.Printf("⚠️ Failed to load device %s: %v", deviceID, err)
+		return nil
+	}
+
+	x.mu.Lock()
+	x.entries[deviceID] = deviceEntry{info: info, expires: time.Now().Add(x.ttl)}
+	x.mu.Unlock()
+	return info
+}
+
+// Set records the attributes of a device, e.g. from a device event
+func (x *DeviceIndex) Set(deviceID string, info *DeviceInfo) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.entries[deviceID] = deviceEntry{info: info, expires: time.Now().Add(x.ttl)}
+}
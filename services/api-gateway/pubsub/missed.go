@@ -0,0 +1,89 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+// MissedEventsKey is the response extension carrying the number of events a
+// subscription dropped since its previous response
+const MissedEventsKey = "missedEvents"
+
+type trackerKey struct{}
+
+// tracker collects the broker subscriptions opened by a GraphQL subscription
+type tracker struct {
+	mu   sync.Mutex
+	subs []*Subscription
+}
+
+// Track registers sub with the GraphQL subscription of ctx, so that its
+// dropped events are reported to the client. It does nothing outside a
+// MissedEvents operation.
+func Track(ctx context.Context, sub *Subscription) {
+	t, ok := ctx.Value(trackerKey{}).(*tracker)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	t.subs = append(t.subs, sub)
+	t.mu.Unlock()
+}
+
+func (t *tracker) takeDropped() int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var n int64
+	for _, sub := range t.subs {
+		n += sub.TakeDropped()
+	}
+	return n
+}
+
+// MissedEvents is a gqlgen extension that tells subscription clients how many
+// events they missed: the next response after a drop carries
+// extensions.missedEvents.
+type MissedEvents struct{}
+
+var _ interface {
+	graphql.OperationInterceptor
+	graphql.HandlerExtension
+} = MissedEvents{}
+
+// ExtensionName returns the name of this extension.
+func (MissedEvents) ExtensionName() string {
+	return "MissedEvents"
+}
+
+// Validate is called when adding the extension to the server.
+func (MissedEvents) Validate(_ graphql.ExecutableSchema) error {
+	return nil
+}
+
+// InterceptOperation tracks the broker subscriptions of subscription
+// operations and reports their drops in each response.
+func (MissedEvents) InterceptOperation(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+	opCtx := graphql.GetOperationContext(ctx)
+	if opCtx.Operation == nil || opCtx.Operation.Operation != ast.Subscription {
+		return next(ctx)
+	}
+
+	t := &tracker{}
+	responses := next(context.WithValue(ctx, trackerKey{}, t))
+	return func(ctx context.Context) *graphql.Response {
+		resp := responses(ctx)
+		if resp == nil {
+			return nil
+		}
+		if n := t.takeDropped(); n > 0 {
+			if resp.Extensions == nil {
+				resp.Extensions = make(map[string]interface{})
+			}
+			resp.Extensions[MissedEventsKey] = n
+		}
+		return resp
+	}
+}
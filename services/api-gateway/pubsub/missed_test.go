@@ -0,0 +1,41 @@
+package pubsub
+
+import (
+	"context"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+func TestMissedEvents(t *testing.T) {
+	broker := NewBrokerWithConfig(Config{BufferSize: 1})
+	var sub *Subscription
+	defer func() { broker.Unsubscribe(sub) }()
+
+	ctx := graphql.WithOperationContext(context.Background(), &graphql.OperationContext{
+		Operation: &ast.OperationDefinition{Operation: ast.Subscription},
+	})
+	responses := MissedEvents{}.InterceptOperation(ctx, func(ctx context.Context) graphql.ResponseHandler {
+		sub = broker.Subscribe(Filter{DeviceIDs: []string{"dev-1"}})
+		Track(ctx, sub)
+		return func(ctx context.Context) *graphql.Response {
+			<-sub.C
+			return &graphql.Response{}
+		}
+	})
+
+	broker.Publish("dev-1", point("dev-1", "temperature", 1))
+	broker.Publish("dev-1", point("dev-1", "temperature", 2)) // dropped
+	broker.Publish("dev-1", point("dev-1", "temperature", 3)) // dropped
+	resp := responses(ctx)
+	if got := resp.Extensions[MissedEventsKey]; got != int64(2) {
+		t.Errorf("missedEvents = %v, want 2", got)
+	}
+
+	broker.Publish("dev-1", point("dev-1", "temperature", 4))
+	resp = responses(ctx)
+	if _, ok := resp.Extensions[MissedEventsKey]; ok {
+		t.Errorf("missedEvents reported without drops: %v", resp.Extensions)
+	}
+}
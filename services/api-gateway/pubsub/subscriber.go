@@ -0,0 +1,272 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/graph/model"
+	"github.com/yourusername/iot-platform/shared/eventbus"
+)
+
+// Replayer reads the telemetry of a device published after a given event ID.
+type Replayer interface {
+	Replay(ctx context.Context, deviceID, afterID string, fn func(point *model.TelemetryPoint) error) error
+}
+
+type lastEventIDKey struct{}
+
+// WithLastEventID records in ctx the ID of the last event received by a
+// reconnecting client, from the SSE Last-Event-ID header
+func WithLastEventID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, lastEventIDKey{}, id)
+}
+
+// LastEventID returns the ID recorded by WithLastEventID
+func LastEventID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(lastEventIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// routeRetryDelay is the delay before retrying a failed routing update
+const routeRetryDelay = 5 * time.Second
+
+// Subscriber consumes telemetry and device events from the event bus and
+// dispatches them to the broker
+type Subscriber struct {
+	bus    eventbus.Bus
+	broker *Broker
+	cancel context.CancelFunc
+
+	// With a transport routing subjects, only the telemetry of the devices
+	// with local subscribers is received
+	subjects eventbus.SubjectSet
+	routed   map[string]struct{} // subjects in subjects, owned by route
+}
+
+// NewSubscriber subscribes to telemetry and device events on bus. When the
+// transport routes subjects (NATS, Redis), telemetry subscriptions
+// follow the devices watched by the broker; otherwise all telemetry is received.
+func NewSubscriber(ctx context.Context, bus eventbus.Bus, broker *Broker) (*Subscriber, error) {
+	subCtx, cancel := context.WithCancel(ctx)
+	subscriber := &Subscriber{bus: bus, broker: broker, cancel: cancel}
+
+	telemetry := eventbus.Subject(eventbus.SubjectTelemetry, ">")
+	if router, ok := bus.(eventbus.SubjectSubscriber); ok {
+		subjects, err := router.SubscribeSubjects(subCtx, subscriber.handleTelemetry)
+		switch {
+		case err == nil:
+			subscriber.subjects = subjects
+			subscriber.routed = make(map[string]struct{})
+			telemetry = "telemetry of watched devices"
+		case errors.Is(err, eventbus.ErrSubjectsUnsupported):
+			log.Printf("⚠️ Event bus transport does not route subjects: every replica receives all telemetry")
+		default:
+			cancel()
+			return nil, fmt.Errorf("failed to subscribe to telemetry: %w", err)
+		}
+	}
+	if subscriber.subjects == nil {
+		if err := bus.Subscribe(subCtx, telemetry, subscriber.handleTelemetry); err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to subscribe to %s: %w", telemetry, err)
+		}
+	}
+	devices := eventbus.Subject(eventbus.SubjectDevices, ">")
+	if err := bus.Subscribe(subCtx, devices, subscriber.handleDevice); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", devices, err)
+	}
+	if subscriber.subjects != nil {
+		go subscriber.route(subCtx)
+	}
+
+	log.Printf("📡 Subscribed to event bus: %s, %s", telemetry, devices)
+	return subscriber, nil
+}
+
+// route keeps the telemetry subjects in line with the devices watched by the
+// broker until ctx is cancelled
+func (s *Subscriber) route(ctx context.Context) {
+	for {
+		var retry <-chan time.Time
+		if err := s.reroute(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("⚠️ Failed to update telemetry subscriptions: %v (retrying in %s)", err, routeRetryDelay)
+			retry = time.After(routeRetryDelay)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.broker.Changes():
+		case <-retry:
+		}
+	}
+}
+
+// reroute subscribes to the telemetry of newly watched devices and
+// unsubscribes from the others. Wildcard subscriptions need all telemetry:
+// device subjects are then replaced by a single pattern. New subjects are
+// added before old ones are removed, so a point may be received twice while
+// switching but none is lost.
+func (s *Subscriber) reroute(ctx context.Context) error {
+	deviceIDs, all := s.broker.WatchedDevices()
+	want := make(map[string]struct{}, len(deviceIDs))
+	if all {
+		want[eventbus.Subject(eventbus.SubjectTelemetry, ">")] = struct{}{}
+	} else {
+		for _, deviceID := range deviceIDs {
+			want[eventbus.Subject(eventbus.SubjectTelemetry, deviceID)] = struct{}{}
+		}
+	}
+
+	var add, remove []string
+	for subject := range want {
+		if _, ok := s.routed[subject]; !ok {
+			add = append(add, subject)
+		}
+	}
+	for subject := range s.routed {
+		if _, ok := want[subject]; !ok {
+			remove = append(remove, subject)
+		}
+	}
+
+	if len(add) > 0 {
+		if err := s.subjects.Add(ctx, add...); err != nil {
+			return err
+		}
+		for _, subject := range add {
+			s.routed[subject] = struct{}{}
+		}
+	}
+	if len(remove) > 0 {
+		if err := s.subjects.Remove(ctx, remove...); err != nil {
+			return err
+		}
+		for _, subject := range remove {
+			delete(s.routed, subject)
+		}
+	}
+	return nil
+}
+
+// handleTelemetry dispatches a telemetry event to the device's subscribers
+func (s *Subscriber) handleTelemetry(msg *eventbus.Message) {
+	event, point, err := decodeTelemetry(msg)
+	if err != nil {
+		log.Printf("⚠️ Failed to unmarshal telemetry event %s: %v", msg.ID, err)
+		return
+	}
+	s.broker.Publish(event.DeviceID, point)
+}
+
+// handleDevice dispatches created and updated devices. Deletions only update
+// the broker's device index: the deviceUpdated subscription has no way to
+// express them.
+func (s *Subscriber) handleDevice(msg *eventbus.Message) {
+	var event eventbus.DeviceEvent
+	if err := json.Unmarshal(msg.Data, &event); err != nil {
+		log.Printf("⚠️ Failed to unmarshal device event %s: %v", msg.ID, err)
+		return
+	}
+	if event.Type == eventbus.DeviceDeleted {
+		s.broker.RemoveDevice(event.DeviceID)
+		return
+	}
+	s.broker.PublishDevice(eventToDevice(&event))
+}
+
+// Replay calls fn for each telemetry point of a device published after the
+// event afterID, in order. Events already trimmed from the bus are lost.
+func (s *Subscriber) Replay(ctx context.Context, deviceID, afterID string, fn func(point *model.TelemetryPoint) error) error {
+	subject := eventbus.Subject(eventbus.SubjectTelemetry, deviceID)
+	return s.bus.Replay(ctx, subject, afterID, func(msg *eventbus.Message) error {
+		_, point, err := decodeTelemetry(msg)
+		if err != nil {
+			log.Printf("⚠️ Failed to unmarshal telemetry event %s: %v", msg.ID, err)
+			return nil
+		}
+		return fn(point)
+	})
+}
+
+// Close stops the subscriptions. The bus is closed by its owner.
+func (s *Subscriber) Close() error {
+	s.cancel()
+	return nil
+}
+
+// decodeTelemetry converts a telemetry event to its GraphQL model, tagged
+// with the event ID when the transport provides one.
+func decodeTelemetry(msg *eventbus.Message) (*eventbus.TelemetryEvent, *model.TelemetryPoint, error) {
+	var event eventbus.TelemetryEvent
+	if err := json.Unmarshal(msg.Data, &event); err != nil {
+		return nil, nil, err
+	}
+
+	// Parse timestamp to Unix
+	var unixTime int
+	timestamp, err := time.Parse(time.RFC3339, event.Timestamp)
+	if err != nil {
+		unixTime = int(time.Now().Unix())
+	} else {
+		unixTime = int(timestamp.Unix())
+	}
+
+	// Convert to GraphQL model
+	unit := event.Unit
+	deviceID := event.DeviceID
+	metricName := event.MetricName
+	point := &model.TelemetryPoint{
+		Time:       unixTime,
+		Value:      event.Value,
+		Unit:       &unit,
+		DeviceID:   &deviceID,
+		MetricName: &metricName,
+	}
+	if msg.ID != "" {
+		id := msg.ID
+		point.EventID = &id
+	}
+
+	return &event, point, nil
+}
+
+// eventToDevice converts a device event to its GraphQL model
+func eventToDevice(event *eventbus.DeviceEvent) *model.Device {
+	keys := make([]string, 0, len(event.Metadata))
+	for k := range event.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	metadata := make([]*model.MetadataEntry, 0, len(keys))
+	for _, k := range keys {
+		metadata = append(metadata, &model.MetadataEntry{Key: k, Value: event.Metadata[k]})
+	}
+
+	status := model.DeviceStatus(strings.ToUpper(event.Status))
+	if !status.IsValid() {
+		status = model.DeviceStatusUnknown
+	}
+
+	device := &model.Device{
+		ID:             event.DeviceID,
+		Name:           event.Name,
+		Type:           event.DeviceType,
+		Status:         status,
+		CreatedAt:      int(event.CreatedAt),
+		LastSeen:       int(event.LastSeen),
+		Metadata:       metadata,
+		OrganizationID: event.OrgID,
+	}
+	if event.LocationID != "" {
+		device.LocationID = &event.LocationID
+	}
+	return device
+}
@@ -0,0 +1,258 @@
+// Package sse provides a GraphQL over Server-Sent Events transport, for
+// clients behind proxies that block WebSocket upgrades.
+//
+// It follows the "distinct connections" mode of the GraphQL over SSE
+// protocol: each operation is a POST (JSON body) or GET (query string)
+// request accepting text/event-stream, answered with one "next" event per
+// result and a final "complete" event. Results carrying a telemetry eventId
+// are sent with that id, so that a reconnecting client's Last-Event-ID header
+// resumes the stream.
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/pubsub"
+)
+
+// maxBodySize caps the size of a POST body
+const maxBodySize = 1 << 20
+
+// Transport serves GraphQL operations over Server-Sent Events. Register it
+// before transport.POST, which would otherwise accept its POST requests.
+type Transport struct {
+	// KeepAlivePingInterval is the idle time after which a comment is sent
+	// to keep proxies from closing the stream, 0 to disable
+	KeepAlivePingInterval time.Duration
+	// StreamContext, if set, returns the context operations run in, canceled
+	// with a cause to end the stream early, e.g. when the token expires
+	// (auth.WebsocketAuth.StreamContext). An error rejects the request with
+	// 403 Forbidden.
+	StreamContext func(ctx context.Context) (context.Context, context.CancelFunc, error)
+}
+
+var _ graphql.Transport = Transport{}
+
+// Supports accepts GET and JSON POST requests accepting text/event-stream.
+// Do rejects mutations sent with GET.
+func (t Transport) Supports(r *http.Request) bool {
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		return false
+	}
+	switch r.Method {
+	case http.MethodGet:
+		return true
+	case http.MethodPost:
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		return err == nil && mediaType == "application/json"
+	default:
+		return false
+	}
+}
+
+// Do executes the operation and streams its results.
+func (t Transport) Do(w http.ResponseWriter, r *http.Request, exec graphql.GraphExecutor) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		transport.SendErrorf(w, http.StatusInternalServerError, "streaming unsupported")
+		return
+	}
+
+	start := graphql.Now()
+	params, err := readParams(r)
+	if err != nil {
+		transport.SendErrorf(w, http.StatusBadRequest, "%s", err)
+		return
+	}
+	params.Headers = r.Header
+	params.ReadTime = graphql.TraceTiming{Start: start, End: graphql.Now()}
+
+	ctx := r.Context()
+	if t.StreamContext != nil {
+		var cancel context.CancelFunc
+		ctx, cancel, err = t.StreamContext(ctx)
+		if err != nil {
+			transport.SendErrorf(w, http.StatusForbidden, "%s", err)
+			return
+		}
+		defer cancel()
+	}
+	if id := r.Header.Get("Last-Event-ID"); id != "" {
+		ctx = pubsub.WithLastEventID(ctx, id)
+	}
+
+	rc, opErr := exec.CreateOperationContext(ctx, params)
+	// Like transport.GET, GET must not change state: a link or an image
+	// could trigger the request
+	if opErr == nil && r.Method == http.MethodGet &&
+		rc.Operation.Operation != ast.Query && rc.Operation.Operation != ast.Subscription {
+		transport.SendErrorf(w, http.StatusNotAcceptable, "GET requests only allow query and subscription operations")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // disable proxy buffering (nginx)
+	w.WriteHeader(http.StatusOK)
+
+	s := &stream{w: w, flusher: flusher}
+	s.comment("")
+
+	if t.KeepAlivePingInterval > 0 {
+		done := make(chan struct{})
+		defer close(done)
+		go s.keepAlive(done, t.KeepAlivePingInterval)
+	}
+
+	if opErr != nil {
+		s.next(exec.DispatchError(graphql.WithOperationContext(ctx, rc), opErr))
+	} else {
+		responses, opCtx := exec.DispatchOperation(ctx, rc)
+		for {
+			response := responses(opCtx)
+			if response == nil {
+				break
+			}
+			s.next(response)
+		}
+	}
+	// Stream ended by the server, not by the client: tell it why
+	if cause := context.Cause(ctx); cause != nil && r.Context().Err() == nil {
+		s.next(&graphql.Response{Errors: gqlerror.List{{Message: cause.Error()}}})
+	}
+	s.complete()
+}
+
+// readParams reads the operation from the query string (GET) or the JSON
+// body (POST)
+func readParams(r *http.Request) (*graphql.RawParams, error) {
+	params := &graphql.RawParams{}
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		params.Query = query.Get("query")
+		params.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &params.Variables); err != nil {
+				return nil, fmt.Errorf("variables could not be decoded: %w", err)
+			}
+		}
+		if extensions := query.Get("extensions"); extensions != "" {
+			if err := json.Unmarshal([]byte(extensions), &params.Extensions); err != nil {
+				return nil, fmt.Errorf("extensions could not be decoded: %w", err)
+			}
+		}
+		return params, nil
+	}
+
+	decoder := json.NewDecoder(io.LimitReader(r.Body, maxBodySize))
+	decoder.UseNumber()
+	if err := decoder.Decode(params); err != nil {
+		return nil, fmt.Errorf("json request body could not be decoded: %w", err)
+	}
+	return params, nil
+}
+
+// stream writes events, serialized with keep-alive comments
+type stream struct {
+	mu       sync.Mutex
+	w        io.Writer
+	flusher  http.Flusher
+	lastSent time.Time
+	closed   bool
+}
+
+// next sends a result, with the telemetry event ID it carries if any
+func (s *stream) next(response *graphql.Response) {
+	data, err := json.Marshal(response)
+	if err != nil {
+		log.Printf("⚠️ Failed to marshal SSE response: %v", err)
+		return
+	}
+	event := "event: next\n"
+	if id := eventID(response); id != "" {
+		event += "id: " + id + "\n"
+	}
+	s.write(event + "data: " + string(data) + "\n\n")
+}
+
+// complete ends the stream; nothing is written after it
+func (s *stream) complete() {
+	s.write("event: complete\ndata:\n\n")
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+}
+
+// comment sends an SSE comment, ignored by clients
+func (s *stream) comment(text string) {
+	s.write(":" + text + "\n\n")
+}
+
+func (s *stream) write(event string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if _, err := io.WriteString(s.w, event); err != nil {
+		return // client gone: the request context ends the operation
+	}
+	s.flusher.Flush()
+	s.lastSent = time.Now()
+}
+
+// keepAlive sends a ping comment whenever the stream has been idle for
+// interval, until done is closed
+func (s *stream) keepAlive(done <-chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			idle := time.Since(s.lastSent) >= interval
+			s.mu.Unlock()
+			if idle {
+				s.comment(" ping")
+			}
+		}
+	}
+}
+
+// eventID returns the telemetry event ID of a subscription result: the
+// eventId field of its root field, when selected.
+func eventID(response *graphql.Response) string {
+	if len(response.Data) == 0 {
+		return ""
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(response.Data, &fields); err != nil || len(fields) != 1 {
+		return ""
+	}
+	for _, field := range fields {
+		var point struct {
+			EventID string `json:"eventId"`
+		}
+		if err := json.Unmarshal(field, &point); err == nil && !strings.ContainsAny(point.EventID, "\r\n") {
+			return point.EventID
+		}
+	}
+	return ""
+}
@@ -0,0 +1,338 @@
+// +build unit
+
+package sse
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"google.golang.org/grpc"
+
+	"github.com/yourusername/iot-platform/services/api-gateway/auth"
+	"github.com/yourusername/iot-platform/services/api-gateway/graph"
+	"github.com/yourusername/iot-platform/services/api-gateway/graph/generated"
+	"github.com/yourusername/iot-platform/services/api-gateway/pubsub"
+	"github.com/yourusername/iot-platform/shared/eventbus"
+	devicepb "github.com/yourusername/iot-platform/shared/proto/device"
+)
+
+const subscription = `subscription { telemetryReceived(deviceId: "dev-1") { value eventId } }`
+
+// deviceClient knows every device, in the organization of the request, and
+// has no ACL entry
+type deviceClient struct {
+	devicepb.DeviceServiceClient
+}
+
+func (deviceClient) GetDevice(ctx context.Context, req *devicepb.GetDeviceRequest, opts ...grpc.CallOption) (*devicepb.GetDeviceResponse, error) {
+	return &devicepb.GetDeviceResponse{Device: &devicepb.Device{Id: req.Id, OrgId: req.OrgId}}, nil
+}
+
+func (deviceClient) CheckDeviceAccess(ctx context.Context, req *devicepb.CheckDeviceAccessRequest, opts ...grpc.CallOption) (*devicepb.CheckDeviceAccessResponse, error) {
+	return &devicepb.CheckDeviceAccessResponse{}, nil
+}
+
+// event is a parsed Server-Sent Event
+type event struct {
+	name, id, data, comment string
+}
+
+type testServer struct {
+	*httptest.Server
+	bus   eventbus.Bus
+	token string
+}
+
+// newTestServer serves the schema with tokens valid for ttl and users checked
+// with checkUser, if set
+func newTestServer(t *testing.T, ttl time.Duration, checkUser auth.UserCheckFunc) *testServer {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	// Hide SubscribeSubjects: all telemetry is received from the start
+	bus := struct{ eventbus.Bus }{eventbus.NewMemory(100)}
+	t.Cleanup(func() { bus.Close() })
+	broker := pubsub.NewBroker()
+	subscriber, err := pubsub.NewSubscriber(ctx, bus, broker)
+	if err != nil {
+		t.Fatalf("NewSubscriber failed: %v", err)
+	}
+	t.Cleanup(func() { subscriber.Close() })
+
+	jwtManager := auth.NewJWTManager("test-secret", ttl)
+	wsAuth := &auth.WebsocketAuth{JWTManager: jwtManager, CheckUser: checkUser, CheckInterval: 20 * time.Millisecond}
+
+	srv := handler.New(generated.NewExecutableSchema(graph.NewConfig(&graph.Resolver{Broker: broker, Replayer: subscriber, DeviceClient: deviceClient{}})))
+	srv.AddTransport(Transport{KeepAlivePingInterval: 50 * time.Millisecond, StreamContext: wsAuth.StreamContext})
+	srv.AddTransport(transport.POST{})
+	srv.Use(&auth.AuthExtension{})
+
+	token, err := jwtManager.GenerateToken("user-1", "user@example.com", "User", "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(auth.Middleware(jwtManager, nil, nil)(srv))
+	t.Cleanup(server.Close)
+	return &testServer{Server: server, bus: bus, token: token}
+}
+
+func (s *testServer) publish(t *testing.T, value float64) {
+	t.Helper()
+	data, _ := json.Marshal(eventbus.TelemetryEvent{DeviceID: "dev-1", MetricName: "temperature", Value: value})
+	if err := s.bus.Publish(context.Background(), "telemetry.dev-1", data); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+}
+
+// open sends req and returns the events of the response stream
+func open(t *testing.T, req *http.Request) <-chan event {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("unexpected response %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	events := make(chan event, 100)
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+		scanner := bufio.NewScanner(resp.Body)
+		var e event
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				events <- e
+				e = event{}
+			case strings.HasPrefix(line, ":"):
+				e.comment = strings.TrimPrefix(line, ":")
+			case strings.HasPrefix(line, "event: "):
+				e.name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "id: "):
+				e.id = strings.TrimPrefix(line, "id: ")
+			case strings.HasPrefix(line, "data:"):
+				e.data = strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			}
+		}
+	}()
+	return events
+}
+
+// nextEvent returns the next event other than a comment
+func nextEvent(t *testing.T, events <-chan event) event {
+	t.Helper()
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				t.Fatal("stream closed")
+			}
+			if e.name != "" {
+				return e
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("no event received")
+		}
+	}
+}
+
+func TestTransport_RequiresAuthentication(t *testing.T) {
+	server := newTestServer(t, time.Hour, nil)
+
+	body, _ := json.Marshal(map[string]string{"query": subscription})
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	events := open(t, req)
+
+	e := nextEvent(t, events)
+	if e.name != "next" || !strings.Contains(e.data, "UNAUTHENTICATED") {
+		t.Errorf("expected authentication error, got %+v", e)
+	}
+	if e := nextEvent(t, events); e.name != "complete" {
+		t.Errorf("expected complete event, got %+v", e)
+	}
+}
+
+func TestTransport_ResumesFromLastEventID(t *testing.T) {
+	server := newTestServer(t, time.Hour, nil)
+	for _, value := range []float64{1, 2, 3} {
+		server.publish(t, value)
+	}
+
+	// EventSource reconnection: GET with the Last-Event-ID header
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"?query="+url.QueryEscape(subscription), nil)
+	req.Header.Set("Authorization", "Bearer "+server.token)
+	req.Header.Set("Last-Event-ID", "1")
+	events := open(t, req)
+
+	for _, want := range []struct{ id, data string }{
+		{"2", `{"data":{"telemetryReceived":{"value":2,"eventId":"2"}}}`},
+		{"3", `{"data":{"telemetryReceived":{"value":3,"eventId":"3"}}}`},
+	} {
+		if e := nextEvent(t, events); e.name != "next" || e.id != want.id || e.data != want.data {
+			t.Errorf("expected event %s %s, got %+v", want.id, want.data, e)
+		}
+	}
+
+	server.publish(t, 4)
+	if e := nextEvent(t, events); e.id != "4" {
+		t.Errorf("expected live event 4, got %+v", e)
+	}
+
+	// Idle stream: keep-alive comments
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case e := <-events:
+			if e.comment == " ping" {
+				return
+			}
+		case <-deadline:
+			t.Fatal("no keep-alive received")
+		}
+	}
+}
+
+func TestTransport_RejectsMutationsOverGET(t *testing.T) {
+	server := newTestServer(t, time.Hour, nil)
+
+	for _, tt := range []struct {
+		query string
+		want  int
+	}{
+		{`mutation { login(input: {email: "a@example.com", password: "x"}) { token } }`, http.StatusNotAcceptable},
+		{subscription, http.StatusOK},
+	} {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"?query="+url.QueryEscape(tt.query), nil)
+		req.Header.Set("Authorization", "Bearer "+server.token)
+		req.Header.Set("Accept", "text/event-stream")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("GET %s: expected %d, got %d", tt.query, tt.want, resp.StatusCode)
+		}
+	}
+}
+
+func TestTransport_Supports(t *testing.T) {
+	tests := []struct {
+		method, accept, contentType string
+		want                        bool
+	}{
+		{http.MethodPost, "text/event-stream", "application/json", true},
+		{http.MethodGet, "text/event-stream", "", true},
+		{http.MethodPost, "application/json", "application/json", false},
+		{http.MethodPost, "text/event-stream", "text/plain", false},
+		{http.MethodPut, "text/event-stream", "application/json", false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/query", nil)
+		req.Header.Set("Accept", tt.accept)
+		req.Header.Set("Content-Type", tt.contentType)
+		if got := (Transport{}).Supports(req); got != tt.want {
+			t.Errorf("Supports(%s, %s, %s) = %v, want %v", tt.method, tt.accept, tt.contentType, got, tt.want)
+		}
+	}
+}
+
+// subscribe opens the test subscription over SSE as the test user
+func (s *testServer) subscribe(t *testing.T) <-chan event {
+	t.Helper()
+	body, _ := json.Marshal(map[string]string{"query": subscription})
+	req, _ := http.NewRequest(http.MethodPost, s.URL, strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	return open(t, req)
+}
+
+// expectEnd checks that the stream ends with an error containing message
+func expectEnd(t *testing.T, events <-chan event, within time.Duration, message string) {
+	t.Helper()
+	deadline := time.After(within)
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				t.Fatal("stream closed without error")
+			}
+			if e.name == "" {
+				continue
+			}
+			if e.name != "next" || !strings.Contains(e.data, message) {
+				t.Fatalf("expected error %q, got %+v", message, e)
+			}
+			if e := nextEvent(t, events); e.name != "complete" {
+				t.Errorf("expected complete event, got %+v", e)
+			}
+			return
+		case <-deadline:
+			t.Fatal("stream still open")
+		}
+	}
+}
+
+func TestTransport_EndsOnTokenExpiry(t *testing.T) {
+	server := newTestServer(t, 2*time.Second, nil) // exp has a one second precision
+	events := server.subscribe(t)
+
+	server.publish(t, 1)
+	if e := nextEvent(t, events); e.name != "next" || !strings.Contains(e.data, `"value":1`) {
+		t.Fatalf("expected live event, got %+v", e)
+	}
+	expectEnd(t, events, 3*time.Second, auth.ErrExpiredToken.Error())
+}
+
+func TestTransport_EndsOnDeactivation(t *testing.T) {
+	var active atomic.Bool
+	active.Store(true)
+	server := newTestServer(t, time.Hour, func(ctx context.Context, claims *auth.Claims) error {
+		if !active.Load() {
+			return auth.ErrUserInactive
+		}
+		return nil
+	})
+	events := server.subscribe(t)
+
+	server.publish(t, 1)
+	if e := nextEvent(t, events); e.name != "next" {
+		t.Fatalf("expected live event, got %+v", e)
+	}
+	active.Store(false)
+	expectEnd(t, events, time.Second, auth.ErrUserInactive.Error())
+
+	// Reconnecting is refused
+	body, _ := json.Marshal(map[string]string{"query": subscription})
+	req, _ := http.NewRequest(http.MethodPost, server.URL, strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+server.token)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected 403, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,250 @@
+// Package anomaly scores ingested telemetry against rolling statistics and
+// reports points that deviate too far from what is expected.
+//
+// Each (device, metric) series keeps an exponentially weighted moving average
+// (EWMA) of its value and variance. With the seasonal method, points are
+// instead compared with the usual value for the same hour of the day, computed
+// from telemetry_hourly, when enough history exists.
+package anomaly
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/yourusername/iot-platform/services/data-collector/storage"
+	pb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+)
+
+// Detection methods.
+const (
+	MethodEWMA     = "ewma"
+	MethodSeasonal = "seasonal"
+)
+
+// baselineTTL is how long a seasonal baseline is reused before being recomputed.
+const baselineTTL = 6 * time.Hour
+
+// minBaselineBuckets is the number of hourly buckets an hour-of-day slot needs
+// before the seasonal baseline is trusted.
+const minBaselineBuckets = 3
+
+// Config holds detector settings.
+type Config struct {
+	// Method is MethodEWMA or MethodSeasonal.
+	Method string
+	// Alpha is the EWMA smoothing factor in (0, 1]: higher adapts faster.
+	Alpha float64
+	// Threshold is the |z-score| from which a point is anomalous.
+	Threshold float64
+	// Warmup is the number of points observed before a series is scored.
+	Warmup int64
+	// SeasonalWeeks is the history used to compute seasonal baselines.
+	SeasonalWeeks int
+	// Metrics restricts detection to these metric names (empty: all).
+	Metrics []string
+}
+
+// Validate checks the configuration.
+func (c Config) Validate() error {
+	if c.Method != MethodEWMA && c.Method != MethodSeasonal {
+		return fmt.Errorf("invalid method %q (ewma or seasonal)", c.Method)
+	}
+	if c.Alpha <= 0 || c.Alpha > 1 {
+		return fmt.Errorf("alpha must be in (0, 1], got %v", c.Alpha)
+	}
+	if c.Threshold <= 0 {
+		return fmt.Errorf("threshold must be positive, got %v", c.Threshold)
+	}
+	if c.Method == MethodSeasonal && c.SeasonalWeeks <= 0 {
+		return fmt.Errorf("seasonal weeks must be positive, got %d", c.SeasonalWeeks)
+	}
+	return nil
+}
+
+type seriesKey struct {
+	deviceID   string
+	metricName string
+}
+
+type cachedBaseline struct {
+	slots   map[int]*storage.SeasonalBaseline
+	expires time.Time
+}
+
+// Detector scores points and keeps per-series state. State is loaded from
+// storage at startup and flushed periodically, so a restart does not reset
+// the statistics or require a new warmup.
+type Detector struct {
+	store   storage.Storage
+	config  Config
+	metrics map[string]bool
+
+	mu        sync.Mutex
+	states    map[seriesKey]*storage.AnomalyState
+	dirty     map[seriesKey]bool
+	baselines map[seriesKey]*cachedBaseline
+}
+
+// NewDetector creates a detector. Call Load before scoring points.
+func NewDetector(store storage.Storage, config Config) *Detector {
+	metrics := make(map[string]bool, len(config.Metrics))
+	for _, name := range config.Metrics {
+		metrics[name] = true
+	}
+
+	return &Detector{
+		store:     store,
+		config:    config,
+		metrics:   metrics,
+		states:    make(map[seriesKey]*storage.AnomalyState),
+		dirty:     make(map[seriesKey]bool),
+		baselines: make(map[seriesKey]*cachedBaseline),
+	}
+}
+
+// Load restores the persisted state of every series.
+func (d *Detector) Load(ctx context.Context) error {
+	states, err := d.store.LoadAnomalyStates(ctx)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, state := range states {
+		d.states[seriesKey{state.DeviceID, state.MetricName}] = state
+	}
+	log.Printf("✅ Anomaly detector state restored for %d series", len(states))
+	return nil
+}
+
+// Run flushes the state every interval until ctx is cancelled.
+func (d *Detector) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.Flush(ctx); err != nil {
+				log.Printf("❌ Failed to save anomaly detector state: %v", err)
+			}
+		}
+	}
+}
+
+// Flush persists the series updated since the last flush.
+func (d *Detector) Flush(ctx context.Context) error {
+	d.mu.Lock()
+	states := make([]*storage.AnomalyState, 0, len(d.dirty))
+	for key := range d.dirty {
+		state := *d.states[key]
+		states = append(states, &state)
+	}
+	d.dirty = make(map[seriesKey]bool)
+	d.mu.Unlock()
+
+	if err := d.store.SaveAnomalyStates(ctx, states); err != nil {
+		// Keep the series dirty so the next flush retries them
+		d.mu.Lock()
+		for _, state := range states {
+			d.dirty[seriesKey{state.DeviceID, state.MetricName}] = true
+		}
+		d.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Observe scores a point, then updates the series statistics with it.
+// It returns the anomaly if the point is anomalous, nil otherwise.
+// Points older than the last observed point of the series are ignored.
+func (d *Detector) Observe(ctx context.Context, deviceID, metricName string, value float64, timestamp int64) *pb.Anomaly {
+	if len(d.metrics) > 0 && !d.metrics[metricName] {
+		return nil
+	}
+	key := seriesKey{deviceID, metricName}
+
+	var baseline *storage.SeasonalBaseline
+	if d.config.Method == MethodSeasonal {
+		hour := time.Unix(timestamp, 0).UTC().Hour()
+		if slot := d.seasonalBaseline(ctx, key)[hour]; slot != nil && slot.Buckets >= minBaselineBuckets && slot.StdDev > 0 {
+			baseline = slot
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	state, ok := d.states[key]
+	if !ok {
+		state = &storage.AnomalyState{DeviceID: deviceID, MetricName: metricName, Mean: value}
+		d.states[key] = state
+	} else if timestamp <= state.LastTime {
+		return nil
+	}
+
+	var anomaly *pb.Anomaly
+	switch {
+	case baseline != nil:
+		anomaly = d.score(key, value, timestamp, baseline.Expected, baseline.StdDev, MethodSeasonal)
+	case state.Count >= d.config.Warmup && state.Variance > 0:
+		anomaly = d.score(key, value, timestamp, state.Mean, math.Sqrt(state.Variance), MethodEWMA)
+	}
+
+	// Incremental EWMA of mean and variance
+	diff := value - state.Mean
+	incr := d.config.Alpha * diff
+	state.Mean += incr
+	state.Variance = (1 - d.config.Alpha) * (state.Variance + diff*incr)
+	state.Count++
+	state.LastTime = timestamp
+	d.dirty[key] = true
+
+	return anomaly
+}
+
+// score returns an anomaly if value is at least Threshold standard deviations from expected.
+func (d *Detector) score(key seriesKey, value float64, timestamp int64, expected, stddev float64, method string) *pb.Anomaly {
+	z := (value - expected) / stddev
+	if math.Abs(z) < d.config.Threshold {
+		return nil
+	}
+	return &pb.Anomaly{
+		DeviceId:   key.deviceID,
+		MetricName: key.metricName,
+		Time:       timestamp,
+		Value:      value,
+		Expected:   expected,
+		Score:      z,
+		Method:     method,
+	}
+}
+
+// seasonalBaseline returns the cached baseline of a series, recomputing it
+// when it has expired. Failures are cached too, so that a broken query does not
+// run for every point.
+func (d *Detector) seasonalBaseline(ctx context.Context, key seriesKey) map[int]*storage.SeasonalBaseline {
+	d.mu.Lock()
+	cached, ok := d.baselines[key]
+	d.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.slots
+	}
+
+	slots, err := d.store.GetSeasonalBaseline(ctx, key.deviceID, key.metricName, d.config.SeasonalWeeks)
+	if err != nil {
+		log.Printf("⚠️  Failed to compute seasonal baseline for %s/%s: %v", key.deviceID, key.metricName, err)
+	}
+
+	d.mu.Lock()
+	d.baselines[key] = &cachedBaseline{slots: slots, expires: time.Now().Add(baselineTTL)}
+	d.mu.Unlock()
+	return slots
+}
@@ -0,0 +1,217 @@
+// +build unit
+
+package anomaly
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/iot-platform/services/data-collector/storage"
+)
+
+// fakeStore serves persisted states and seasonal baselines, and records saves
+type fakeStore struct {
+	storage.Storage
+
+	mu        sync.Mutex
+	states    []*storage.AnomalyState
+	baseline  map[int]*storage.SeasonalBaseline
+	baselines int
+	saved     []*storage.AnomalyState
+	saveErr   error
+}
+
+func (s *fakeStore) LoadAnomalyStates(ctx context.Context) ([]*storage.AnomalyState, error) {
+	return s.states, nil
+}
+
+func (s *fakeStore) SaveAnomalyStates(ctx context.Context, states []*storage.AnomalyState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+	s.saved = append(s.saved, states...)
+	return nil
+}
+
+func (s *fakeStore) GetSeasonalBaseline(ctx context.Context, deviceID, metricName string, weeks int) (map[int]*storage.SeasonalBaseline, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.baselines++
+	return s.baseline, nil
+}
+
+var testConfig = Config{Method: MethodEWMA, Alpha: 0.3, Threshold: 3, Warmup: 5, SeasonalWeeks: 4}
+
+// at returns the Unix time of hour:00 UTC on the given day of January 2024
+func at(day, hour int) int64 {
+	return time.Date(2024, time.January, day, hour, 0, 0, 0, time.UTC).Unix()
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr bool
+	}{
+		{"valid", func(c *Config) {}, false},
+		{"seasonal", func(c *Config) { c.Method = MethodSeasonal }, false},
+		{"alpha_one", func(c *Config) { c.Alpha = 1 }, false},
+		{"unknown_method", func(c *Config) { c.Method = "zscore" }, true},
+		{"alpha_zero", func(c *Config) { c.Alpha = 0 }, true},
+		{"alpha_above_one", func(c *Config) { c.Alpha = 1.5 }, true},
+		{"threshold_zero", func(c *Config) { c.Threshold = 0 }, true},
+		{"seasonal_without_history", func(c *Config) { c.Method = MethodSeasonal; c.SeasonalWeeks = 0 }, true},
+		{"ewma_ignores_weeks", func(c *Config) { c.SeasonalWeeks = 0 }, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig
+			tt.modify(&config)
+			if err := config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDetector_EWMA(t *testing.T) {
+	detector := NewDetector(&fakeStore{}, testConfig)
+	ctx := context.Background()
+
+	// Warmup: even a wild value is not scored
+	if anomaly := detector.Observe(ctx, "dev-1", "temperature", 10, 1); anomaly != nil {
+		t.Fatalf("unexpected anomaly on first point: %+v", anomaly)
+	}
+	if anomaly := detector.Observe(ctx, "dev-1", "temperature", 50, 2); anomaly != nil {
+		t.Fatalf("unexpected anomaly during warmup: %+v", anomaly)
+	}
+
+	// The statistics settle after the outlier, then regular points pass
+	timestamp := int64(3)
+	for i := 0; i < 30; i++ {
+		if anomaly := detector.Observe(ctx, "dev-1", "temperature", 20+float64(i%2)*2, timestamp); anomaly != nil && i > 10 {
+			t.Errorf("unexpected anomaly on a regular point %d: %+v", i, anomaly)
+		}
+		timestamp++
+	}
+
+	anomaly := detector.Observe(ctx, "dev-1", "temperature", 100, timestamp)
+	if anomaly == nil {
+		t.Fatal("expected an anomaly")
+	}
+	if anomaly.Method != MethodEWMA || anomaly.Score < testConfig.Threshold || anomaly.Time != timestamp || anomaly.Value != 100 {
+		t.Errorf("unexpected anomaly %+v", anomaly)
+	}
+	if math.Abs(anomaly.Expected-21) > 1 {
+		t.Errorf("expected value around 21, got %v", anomaly.Expected)
+	}
+
+	// Negative deviations have a negative score
+	for i := int64(1); i < 20; i++ {
+		detector.Observe(ctx, "dev-2", "temperature", 20+float64(i%2), i)
+	}
+	if anomaly = detector.Observe(ctx, "dev-2", "temperature", -50, 20); anomaly == nil || anomaly.Score > -testConfig.Threshold {
+		t.Errorf("expected a negative score, got %+v", anomaly)
+	}
+}
+
+func TestDetector_IgnoresOldPointsAndOtherMetrics(t *testing.T) {
+	config := testConfig
+	config.Metrics = []string{"temperature"}
+	detector := NewDetector(&fakeStore{}, config)
+	ctx := context.Background()
+
+	for i := int64(1); i <= 10; i++ {
+		detector.Observe(ctx, "dev-1", "temperature", 20+float64(i%2), i)
+	}
+	// Out of order: neither scored nor counted
+	if anomaly := detector.Observe(ctx, "dev-1", "temperature", 1000, 5); anomaly != nil {
+		t.Errorf("out of order point scored: %+v", anomaly)
+	}
+	if state := detector.states[seriesKey{"dev-1", "temperature"}]; state.Count != 10 || state.LastTime != 10 {
+		t.Errorf("out of order point updated the state: %+v", state)
+	}
+
+	// Not in Metrics
+	if anomaly := detector.Observe(ctx, "dev-1", "humidity", 1000, 11); anomaly != nil {
+		t.Errorf("metric outside Metrics scored: %+v", anomaly)
+	}
+	if _, ok := detector.states[seriesKey{"dev-1", "humidity"}]; ok {
+		t.Error("metric outside Metrics has a state")
+	}
+}
+
+func TestDetector_Seasonal(t *testing.T) {
+	store := &fakeStore{baseline: map[int]*storage.SeasonalBaseline{
+		14: {Expected: 20, StdDev: 1, Buckets: 5},
+		3:  {Expected: 20, StdDev: 1, Buckets: 1}, // not enough history
+	}}
+	config := testConfig
+	config.Method = MethodSeasonal
+	detector := NewDetector(store, config)
+	ctx := context.Background()
+
+	// Scored against the hour of day, without warmup
+	anomaly := detector.Observe(ctx, "dev-1", "temperature", 25, at(1, 14))
+	if anomaly == nil || anomaly.Method != MethodSeasonal || anomaly.Expected != 20 || anomaly.Score != 5 {
+		t.Fatalf("expected seasonal anomaly with score 5, got %+v", anomaly)
+	}
+	if anomaly := detector.Observe(ctx, "dev-1", "temperature", 21, at(2, 14)); anomaly != nil {
+		t.Errorf("unexpected anomaly within the baseline: %+v", anomaly)
+	}
+
+	// Untrusted or missing slots fall back to EWMA, still warming up
+	if anomaly := detector.Observe(ctx, "dev-1", "temperature", 25, at(3, 3)); anomaly != nil {
+		t.Errorf("untrusted slot scored: %+v", anomaly)
+	}
+	if anomaly := detector.Observe(ctx, "dev-1", "temperature", 25, at(3, 8)); anomaly != nil {
+		t.Errorf("missing slot scored: %+v", anomaly)
+	}
+
+	if store.baselines != 1 {
+		t.Errorf("expected the baseline to be computed once and cached, got %d queries", store.baselines)
+	}
+}
+
+// TestDetector_LoadAndFlush checks that restored state skips the warmup and
+// that flushed series are retried after a failed save
+func TestDetector_LoadAndFlush(t *testing.T) {
+	store := &fakeStore{states: []*storage.AnomalyState{
+		{DeviceID: "dev-1", MetricName: "temperature", Mean: 20, Variance: 1, Count: 100, LastTime: 1000},
+	}}
+	detector := NewDetector(store, testConfig)
+	ctx := context.Background()
+	if err := detector.Load(ctx); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if anomaly := detector.Observe(ctx, "dev-1", "temperature", 30, 1001); anomaly == nil || anomaly.Score != 10 {
+		t.Errorf("expected restored state to score immediately, got %+v", anomaly)
+	}
+	if anomaly := detector.Observe(ctx, "dev-1", "temperature", 30, 999); anomaly != nil {
+		t.Errorf("point older than the restored state scored: %+v", anomaly)
+	}
+
+	store.saveErr = errors.New("database down")
+	if err := detector.Flush(ctx); err == nil {
+		t.Fatal("expected Flush to fail")
+	}
+	store.saveErr = nil
+	if err := detector.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if len(store.saved) != 1 || store.saved[0].Count != 101 || store.saved[0].LastTime != 1001 {
+		t.Fatalf("expected the failed series to be saved on retry, got %+v", store.saved)
+	}
+
+	// Nothing changed since
+	if err := detector.Flush(ctx); err != nil || len(store.saved) != 1 {
+		t.Errorf("expected an empty flush, saved %d states, err %v", len(store.saved), err)
+	}
+}
@@ -0,0 +1,192 @@
+// Package catalog normalizes and validates ingested telemetry against the
+// metric catalog of each device type, as declared in the device-manager registry.
+//
+// Values are normalized to the unit declared in the catalog, or to the default
+// canonical unit of their dimension for undeclared metrics.
+//
+// Devices whose type is not registered are not validated. For a registered
+// type, a point is invalid when its metric is not declared, when its unit
+// differs from the declared one, when its value does not match the declared
+// data type, or when it falls outside the declared range.
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/yourusername/iot-platform/services/data-collector/storage"
+	"github.com/yourusername/iot-platform/services/data-collector/units"
+)
+
+// Validation failure reasons, used in point metadata and metric labels.
+const (
+	ReasonUndeclared   = "undeclared"
+	ReasonUnitMismatch = "unit_mismatch"
+	ReasonInvalidType  = "invalid_type"
+	ReasonOutOfRange   = "out_of_range"
+)
+
+// Validation modes.
+const (
+	// ModeFlag stores invalid points with a "validation" metadata entry.
+	ModeFlag = "flag"
+	// ModeReject drops invalid points.
+	ModeReject = "reject"
+	// ModeOff disables validation.
+	ModeOff = "off"
+)
+
+// ParseMode validates a mode name.
+func ParseMode(s string) (string, error) {
+	switch s {
+	case ModeFlag, ModeReject, ModeOff:
+		return s, nil
+	default:
+		return "", fmt.Errorf("invalid validation mode %q (flag, reject or off)", s)
+	}
+}
+
+// Violation describes why a point does not match the catalog.
+type Violation struct {
+	Reason string
+	Detail string
+}
+
+// Catalog normalizes and checks points. The catalog is held in memory and
+// reloaded periodically, so changes made in the device-manager apply after at
+// most one reload interval.
+type Catalog struct {
+	store       storage.Storage
+	deviceTypes *storage.DeviceCache
+
+	mu      sync.RWMutex
+	catalog map[string]map[string]*storage.MetricSpec
+}
+
+// New creates a catalog. Call Load before checking points.
+func New(store storage.Storage, deviceTypes *storage.DeviceCache) *Catalog {
+	return &Catalog{
+		store:       store,
+		deviceTypes: deviceTypes,
+		catalog:     make(map[string]map[string]*storage.MetricSpec),
+	}
+}
+
+// Load (re)loads the catalog from storage.
+func (c *Catalog) Load(ctx context.Context) error {
+	catalog, err := c.store.LoadMetricCatalog(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.catalog = catalog
+	c.mu.Unlock()
+	return nil
+}
+
+// Run reloads the catalog every interval until ctx is cancelled.
+func (c *Catalog) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Load(ctx); err != nil {
+				log.Printf("❌ Failed to reload metric catalog: %v", err)
+			}
+		}
+	}
+}
+
+// lookup returns the declared metric of a point, and whether the device's type
+// is registered. Lookup failures are logged and treated as unregistered.
+func (c *Catalog) lookup(ctx context.Context, deviceID, metricName string) (spec *storage.MetricSpec, registered bool) {
+	c.mu.RLock()
+	empty := len(c.catalog) == 0
+	c.mu.RUnlock()
+	if empty {
+		return nil, false
+	}
+
+	deviceType, err := c.deviceTypes.Type(ctx, deviceID)
+	if err != nil {
+		log.Printf("⚠️  Failed to get type of device %s: %v", deviceID, err)
+		return nil, false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	metrics, registered := c.catalog[deviceType]
+	return metrics[metricName], registered
+}
+
+// Normalize converts a value to the canonical unit of its metric: the unit
+// declared in the catalog when it measures the same quantity, else the default
+// unit of the value's dimension. Values in unknown units are returned as is.
+func (c *Catalog) Normalize(ctx context.Context, deviceID, metricName string, value float64, unit string) (float64, string) {
+	from, ok := units.Lookup(unit)
+	if !ok {
+		return value, unit
+	}
+
+	target, _ := units.Canonical(unit)
+	if spec, _ := c.lookup(ctx, deviceID, metricName); spec != nil {
+		if declared, ok := units.Lookup(spec.Unit); ok && declared.Dimension == from.Dimension {
+			// Keep the catalog's spelling so that stored units match the declaration
+			converted, _ := units.Convert(value, unit, spec.Unit)
+			return converted, spec.Unit
+		}
+	}
+
+	converted, _ := units.Convert(value, unit, target.Name)
+	return converted, target.Name
+}
+
+// Check returns the violation of a point, or nil if it is valid or cannot be
+// validated (unregistered device type, lookup failure).
+func (c *Catalog) Check(ctx context.Context, deviceID, metricName string, value float64, unit string) *Violation {
+	spec, registered := c.lookup(ctx, deviceID, metricName)
+	if !registered {
+		return nil
+	}
+	if spec == nil {
+		return &Violation{ReasonUndeclared, fmt.Sprintf("metric %s is not declared for this device type", metricName)}
+	}
+	return CheckSpec(spec, value, unit)
+}
+
+// CheckSpec validates a point against one declared metric. An empty unit
+// means the device did not send one and is not a mismatch, nor is a different
+// spelling of the declared unit ("°C" for "celsius").
+func CheckSpec(spec *storage.MetricSpec, value float64, unit string) *Violation {
+	if unit != "" && spec.Unit != "" && !units.Same(unit, spec.Unit) {
+		return &Violation{ReasonUnitMismatch, fmt.Sprintf("unit %q, expected %q", unit, spec.Unit)}
+	}
+
+	switch spec.DataType {
+	case "integer":
+		if value != math.Trunc(value) {
+			return &Violation{ReasonInvalidType, fmt.Sprintf("value %v is not an integer", value)}
+		}
+	case "boolean":
+		if value != 0 && value != 1 {
+			return &Violation{ReasonInvalidType, fmt.Sprintf("value %v is not a boolean (0 or 1)", value)}
+		}
+	}
+
+	if spec.MinValue != nil && value < *spec.MinValue {
+		return &Violation{ReasonOutOfRange, fmt.Sprintf("value %v below minimum %v", value, *spec.MinValue)}
+	}
+	if spec.MaxValue != nil && value > *spec.MaxValue {
+		return &Violation{ReasonOutOfRange, fmt.Sprintf("value %v above maximum %v", value, *spec.MaxValue)}
+	}
+	return nil
+}
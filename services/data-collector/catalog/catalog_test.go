@@ -0,0 +1,172 @@
+// +build unit
+
+package catalog
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/yourusername/iot-platform/services/data-collector/storage"
+)
+
+// fakeStore serves a catalog for the "thermostat" type; devices named in
+// types are registered
+type fakeStore struct {
+	storage.Storage
+	types map[string]string
+}
+
+func (s *fakeStore) LoadMetricCatalog(ctx context.Context) (map[string]map[string]*storage.MetricSpec, error) {
+	min, max := -40.0, 85.0
+	return map[string]map[string]*storage.MetricSpec{
+		"thermostat": {
+			"temperature": {Name: "temperature", Unit: "celsius", DataType: "float", MinValue: &min, MaxValue: &max},
+			"heating":     {Name: "heating", DataType: "boolean"},
+		},
+		"meter": {
+			"energy": {Name: "energy", Unit: "kWh", DataType: "float"},
+		},
+		"counter": {},
+	}, nil
+}
+
+func (s *fakeStore) GetDeviceInfo(ctx context.Context, deviceID string) (storage.DeviceInfo, error) {
+	deviceType, ok := s.types[deviceID]
+	if !ok {
+		return storage.DeviceInfo{}, storage.ErrDeviceNotFound
+	}
+	return storage.DeviceInfo{Type: deviceType, OrgID: "org-1"}, nil
+}
+
+func newTestCatalog(t *testing.T) *Catalog {
+	t.Helper()
+	store := &fakeStore{types: map[string]string{"thermo-1": "thermostat", "counter-1": "counter", "other-1": "camera", "meter-1": "meter"}}
+	c := New(store, storage.NewDeviceCache(store, time.Minute))
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	return c
+}
+
+func TestParseMode(t *testing.T) {
+	for _, mode := range []string{ModeFlag, ModeReject, ModeOff} {
+		if got, err := ParseMode(mode); err != nil || got != mode {
+			t.Errorf("ParseMode(%q) = %q, %v", mode, got, err)
+		}
+	}
+	for _, mode := range []string{"", "strict", "Reject"} {
+		if _, err := ParseMode(mode); err == nil {
+			t.Errorf("ParseMode(%q) should fail", mode)
+		}
+	}
+}
+
+func TestCheckSpec(t *testing.T) {
+	min, max := 0.0, 100.0
+	float := &storage.MetricSpec{Name: "humidity", Unit: "percent", DataType: "float", MinValue: &min, MaxValue: &max}
+	integer := &storage.MetricSpec{Name: "count", DataType: "integer"}
+	boolean := &storage.MetricSpec{Name: "open", DataType: "boolean"}
+
+	tests := []struct {
+		name   string
+		spec   *storage.MetricSpec
+		value  float64
+		unit   string
+		reason string // empty: valid
+	}{
+		{"valid", float, 55.5, "percent", ""},
+		{"alias_of_declared_unit", float, 55.5, "%", ""},
+		{"no_unit_sent", float, 55.5, "", ""},
+		{"unit_mismatch", float, 0.5, "fraction", ReasonUnitMismatch},
+		{"unknown_unit", float, 55.5, "RH", ReasonUnitMismatch},
+		{"at_minimum", float, 0, "", ""},
+		{"at_maximum", float, 100, "", ""},
+		{"below_minimum", float, -0.1, "", ReasonOutOfRange},
+		{"above_maximum", float, 100.1, "", ReasonOutOfRange},
+		{"integer", integer, 42, "", ""},
+		{"not_an_integer", integer, 4.2, "", ReasonInvalidType},
+		{"boolean_true", boolean, 1, "", ""},
+		{"boolean_false", boolean, 0, "", ""},
+		{"not_a_boolean", boolean, 2, "", ReasonInvalidType},
+		{"unbounded", integer, -1e9, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violation := CheckSpec(tt.spec, tt.value, tt.unit)
+			switch {
+			case tt.reason == "" && violation != nil:
+				t.Errorf("unexpected violation %+v", violation)
+			case tt.reason != "" && (violation == nil || violation.Reason != tt.reason):
+				t.Errorf("expected %s, got %+v", tt.reason, violation)
+			}
+		})
+	}
+}
+
+func TestCatalog_Check(t *testing.T) {
+	c := newTestCatalog(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		deviceID string
+		metric   string
+		value    float64
+		unit     string
+		reason   string
+	}{
+		{"declared", "thermo-1", "temperature", 21, "°C", ""},
+		{"out_of_range", "thermo-1", "temperature", 120, "celsius", ReasonOutOfRange},
+		{"invalid_type", "thermo-1", "heating", 0.5, "", ReasonInvalidType},
+		{"undeclared", "thermo-1", "humidity", 40, "percent", ReasonUndeclared},
+		{"empty_catalog", "counter-1", "count", 1, "", ReasonUndeclared},
+		// No catalog for the type, or device not registered: not validated
+		{"type_without_catalog", "other-1", "anything", 1, "", ""},
+		{"unregistered_device", "ghost-1", "temperature", 500, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violation := c.Check(ctx, tt.deviceID, tt.metric, tt.value, tt.unit)
+			switch {
+			case tt.reason == "" && violation != nil:
+				t.Errorf("unexpected violation %+v", violation)
+			case tt.reason != "" && (violation == nil || violation.Reason != tt.reason):
+				t.Errorf("expected %s, got %+v", tt.reason, violation)
+			}
+		})
+	}
+}
+
+func TestCatalog_Normalize(t *testing.T) {
+	c := newTestCatalog(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		deviceID  string
+		metric    string
+		value     float64
+		unit      string
+		wantValue float64
+		wantUnit  string
+	}{
+		{"declared_unit", "thermo-1", "temperature", 212, "°F", 100, "celsius"},
+		{"alias_of_declared_unit", "thermo-1", "temperature", 21, "°C", 21, "celsius"},
+		{"declared_spelling_kept", "meter-1", "energy", 1500, "Wh", 1.5, "kWh"},
+		{"declared_unit_of_another_dimension", "thermo-1", "temperature", 2, "bar", 2000, "hPa"},
+		{"undeclared_metric", "thermo-1", "pressure", 1, "bar", 1000, "hPa"},
+		{"unregistered_device", "ghost-1", "speed", 36, "km/h", 10, "m/s"},
+		{"unknown_unit", "thermo-1", "humidity", 40, "RH", 40, "RH"},
+		{"no_unit", "thermo-1", "temperature", 21, "", 21, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, unit := c.Normalize(ctx, tt.deviceID, tt.metric, tt.value, tt.unit)
+			if math.Abs(value-tt.wantValue) > 1e-9 || unit != tt.wantUnit {
+				t.Errorf("Normalize(%v %s) = %v %s, want %v %s", tt.value, tt.unit, value, unit, tt.wantValue, tt.wantUnit)
+			}
+		})
+	}
+}
@@ -0,0 +1,512 @@
+// Package derived computes derived metrics (virtual sensors) at ingest time.
+//
+// Definitions are stored per device type (see storage.ListDerivedMetrics).
+// For every ingested message the Engine evaluates the definitions of the
+// device's type and returns the derived points, which the caller stores and
+// publishes like native metrics.
+package derived
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/yourusername/iot-platform/services/data-collector/storage"
+	pb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+)
+
+// maxSeedPoints bounds the history read to rebuild a moving average after a restart.
+const maxSeedPoints = 10000
+
+// Sample is one metric value of an ingested message.
+type Sample struct {
+	Name  string
+	Value float64
+	Unit  string
+}
+
+// definition is a validated, compiled derived metric.
+type definition struct {
+	*pb.DerivedMetric
+	expr   *Expression
+	window time.Duration
+}
+
+// sameAs reports whether other computes the same metric the same way, so that
+// its windowed state carries over.
+func (d *definition) sameAs(other *definition) bool {
+	return d.DeviceType == other.DeviceType && d.Name == other.Name && d.Kind == other.Kind &&
+		d.Expression == other.Expression && d.SourceMetric == other.SourceMetric &&
+		d.Window == other.Window && d.Unit == other.Unit
+}
+
+// inputs returns the metrics the definition reads.
+func (d *definition) inputs() []string {
+	if d.expr != nil {
+		return d.expr.Variables()
+	}
+	return []string{d.SourceMetric}
+}
+
+// Validate checks a derived metric definition: kind-specific fields, expression
+// syntax and window.
+func Validate(metric *pb.DerivedMetric) error {
+	_, err := compile(metric)
+	return err
+}
+
+// compile validates a definition and parses its expression or window.
+func compile(metric *pb.DerivedMetric) (*definition, error) {
+	def := &definition{DerivedMetric: metric}
+
+	switch metric.Kind {
+	case pb.DerivedMetricKind_DERIVED_METRIC_EXPRESSION:
+		expr, err := ParseExpression(metric.Expression)
+		if err != nil {
+			return nil, err
+		}
+		if len(expr.Variables()) == 0 {
+			return nil, fmt.Errorf("expression must reference at least one metric")
+		}
+		def.expr = expr
+	case pb.DerivedMetricKind_DERIVED_METRIC_INTEGRAL:
+		if metric.SourceMetric == "" {
+			return nil, fmt.Errorf("source_metric required")
+		}
+	case pb.DerivedMetricKind_DERIVED_METRIC_MOVING_AVERAGE:
+		if metric.SourceMetric == "" {
+			return nil, fmt.Errorf("source_metric required")
+		}
+		window, err := time.ParseDuration(metric.Window)
+		if err != nil || window < time.Second {
+			return nil, fmt.Errorf("invalid window %q (expected a duration such as \"15m\")", metric.Window)
+		}
+		def.window = window
+	default:
+		return nil, fmt.Errorf("unknown kind %v", metric.Kind)
+	}
+
+	for _, input := range def.inputs() {
+		if input == metric.Name {
+			return nil, fmt.Errorf("%s cannot be computed from itself", metric.Name)
+		}
+	}
+	return def, nil
+}
+
+// timedValue is a metric value and the Unix time it was measured at.
+type timedValue struct {
+	time  int64
+	value float64
+}
+
+// integralState accumulates the trapezoidal integral of a source metric.
+type integralState struct {
+	total float64
+	last  *timedValue
+}
+
+// averageState holds the source points inside a moving average window.
+type averageState struct {
+	points []timedValue
+}
+
+// deviceState is the evaluation state of one device. Its lock serializes the
+// messages of the device, including the storage reads that seed windowed state.
+type deviceState struct {
+	mu        sync.Mutex
+	values    map[string]timedValue // last value of every metric, native or derived
+	integrals map[*definition]*integralState
+	averages  map[*definition]*averageState
+}
+
+// Engine evaluates derived metrics. Windowed state (integrals, moving averages)
+// lives in memory, keyed by definition, and is rebuilt lazily from stored
+// telemetry after a restart or a change of its definition.
+type Engine struct {
+	store       storage.Storage
+	deviceTypes *storage.DeviceCache
+	maxGap      time.Duration
+
+	mu      sync.Mutex // guards byType and devices, never held during I/O
+	byType  map[string][]*definition
+	devices map[string]*deviceState
+}
+
+// NewEngine creates an engine. maxGap is both the maximum age of a stored input
+// combined with fresh ones in an expression, and the longest gap an integral bridges.
+func NewEngine(store storage.Storage, deviceTypes *storage.DeviceCache, maxGap time.Duration) *Engine {
+	return &Engine{
+		store:       store,
+		deviceTypes: deviceTypes,
+		maxGap:      maxGap,
+		byType:      make(map[string][]*definition),
+		devices:     make(map[string]*deviceState),
+	}
+}
+
+// Load (re)loads definitions from storage. Unchanged definitions keep their
+// windowed state; the state of changed and removed ones is dropped.
+// Invalid definitions and dependency cycles are logged and skipped.
+func (e *Engine) Load(ctx context.Context) error {
+	metrics, err := e.store.ListDerivedMetrics(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	previous := make(map[string]*definition)
+	for _, defs := range e.byType {
+		for _, def := range defs {
+			previous[def.DeviceType+"/"+def.Name] = def
+		}
+	}
+	e.mu.Unlock()
+
+	grouped := make(map[string][]*definition)
+	for _, metric := range metrics {
+		def, err := compile(metric)
+		if err != nil {
+			log.Printf("⚠️  Skipping derived metric %s/%s: %v", metric.DeviceType, metric.Name, err)
+			continue
+		}
+		if old, ok := previous[metric.DeviceType+"/"+metric.Name]; ok && old.sameAs(def) {
+			def = old
+		}
+		grouped[metric.DeviceType] = append(grouped[metric.DeviceType], def)
+	}
+
+	byType := make(map[string][]*definition, len(grouped))
+	live := make(map[*definition]bool)
+	for deviceType, defs := range grouped {
+		byType[deviceType] = orderDefinitions(deviceType, defs)
+		for _, def := range byType[deviceType] {
+			live[def] = true
+		}
+	}
+
+	e.mu.Lock()
+	e.byType = byType
+	states := make([]*deviceState, 0, len(e.devices))
+	for _, state := range e.devices {
+		states = append(states, state)
+	}
+	e.mu.Unlock()
+
+	// Values computed by a stale definition must not feed the expressions
+	// of the new ones
+	stale := make(map[string]bool)
+	for _, def := range previous {
+		if !live[def] {
+			stale[def.Name] = true
+		}
+	}
+	for _, state := range states {
+		state.prune(live, stale)
+	}
+	return nil
+}
+
+// Run reloads definitions every interval until ctx is cancelled, so that
+// changes made through another replica are picked up.
+func (e *Engine) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := e.Load(ctx); err != nil {
+				log.Printf("❌ Failed to reload derived metrics: %v", err)
+			}
+		}
+	}
+}
+
+// orderDefinitions sorts definitions so that a derived metric used as an input
+// of another one is computed first. Definitions in a cycle are dropped.
+func orderDefinitions(deviceType string, defs []*definition) []*definition {
+	byName := make(map[string]*definition, len(defs))
+	for _, def := range defs {
+		byName[def.Name] = def
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	marks := make(map[string]int, len(defs))
+	ordered := make([]*definition, 0, len(defs))
+
+	var visit func(def *definition) bool
+	visit = func(def *definition) bool {
+		switch marks[def.Name] {
+		case visiting:
+			return false
+		case done:
+			return true
+		}
+		marks[def.Name] = visiting
+		for _, input := range def.inputs() {
+			if dep, ok := byName[input]; ok && !visit(dep) {
+				return false
+			}
+		}
+		marks[def.Name] = done
+		ordered = append(ordered, def)
+		return true
+	}
+
+	for _, def := range defs {
+		if marks[def.Name] == 0 && !visit(def) {
+			log.Printf("⚠️  Skipping derived metric %s/%s: dependency cycle", deviceType, def.Name)
+		}
+	}
+	return ordered
+}
+
+// Evaluate returns the derived points triggered by the samples of one message,
+// all measured at timestamp. A definition is evaluated when at least one of its
+// inputs is in the message.
+func (e *Engine) Evaluate(ctx context.Context, deviceID string, timestamp int64, samples []Sample) []Sample {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	defs := e.definitionsFor(ctx, deviceID)
+	if len(defs) == 0 {
+		return nil
+	}
+
+	state := e.deviceState(deviceID)
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	fresh := make(map[string]float64, len(samples))
+	for _, sample := range samples {
+		fresh[sample.Name] = sample.Value
+		state.remember(sample.Name, timestamp, sample.Value)
+	}
+
+	var derived []Sample
+	for _, def := range defs {
+		value, ok, err := e.evaluate(ctx, deviceID, def, state, fresh, timestamp)
+		if err != nil {
+			log.Printf("⚠️  Derived metric %s failed for device %s: %v", def.Name, deviceID, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		fresh[def.Name] = value
+		state.remember(def.Name, timestamp, value)
+		derived = append(derived, Sample{Name: def.Name, Value: value, Unit: def.Unit})
+	}
+	return derived
+}
+
+// evaluate computes one definition. ok is false when the definition is not
+// triggered by this message or lacks inputs.
+func (e *Engine) evaluate(ctx context.Context, deviceID string, def *definition, state *deviceState, fresh map[string]float64, timestamp int64) (float64, bool, error) {
+	switch def.Kind {
+	case pb.DerivedMetricKind_DERIVED_METRIC_EXPRESSION:
+		return e.evaluateExpression(def, state, fresh, timestamp)
+
+	case pb.DerivedMetricKind_DERIVED_METRIC_INTEGRAL:
+		value, ok := fresh[def.SourceMetric]
+		if !ok {
+			return 0, false, nil
+		}
+		integral, ok := state.integrals[def]
+		if !ok {
+			integral = e.seedIntegral(ctx, deviceID, def)
+			state.integrals[def] = integral
+		}
+		return integral.add(timedValue{timestamp, value}, e.maxGap)
+
+	default:
+		value, ok := fresh[def.SourceMetric]
+		if !ok {
+			return 0, false, nil
+		}
+		average, ok := state.averages[def]
+		if !ok {
+			average = e.seedAverage(ctx, deviceID, def, timestamp)
+			state.averages[def] = average
+		}
+		return average.add(timedValue{timestamp, value}, def.window)
+	}
+}
+
+// evaluateExpression combines the message's values with the last known values
+// of the other inputs, provided they are not older than maxGap.
+func (e *Engine) evaluateExpression(def *definition, state *deviceState, fresh map[string]float64, timestamp int64) (float64, bool, error) {
+	triggered := false
+	vars := make(map[string]float64, len(def.expr.Variables()))
+	for _, name := range def.expr.Variables() {
+		if value, ok := fresh[name]; ok {
+			vars[name] = value
+			triggered = true
+			continue
+		}
+		last, ok := state.values[name]
+		if !ok || time.Duration(timestamp-last.time)*time.Second > e.maxGap {
+			return 0, false, nil
+		}
+		vars[name] = last.value
+	}
+	if !triggered {
+		return 0, false, nil
+	}
+
+	value, err := def.expr.Eval(vars)
+	if err != nil {
+		return 0, false, err
+	}
+	return value, true, nil
+}
+
+// seedIntegral resumes an integral from its last stored value, using the
+// source point stored at the same time as the starting point.
+func (e *Engine) seedIntegral(ctx context.Context, deviceID string, def *definition) *integralState {
+	integral := &integralState{}
+
+	last, err := e.store.GetLatestMetric(ctx, deviceID, def.Name)
+	if err != nil {
+		return integral
+	}
+	integral.total = last.Value
+
+	points, err := e.store.GetTelemetry(ctx, deviceID, def.SourceMetric, last.Time, last.Time, 1)
+	if err == nil && len(points) == 1 {
+		integral.last = &timedValue{points[0].Time, points[0].Value}
+	}
+	return integral
+}
+
+// seedAverage reloads the source points of the window preceding timestamp.
+func (e *Engine) seedAverage(ctx context.Context, deviceID string, def *definition, timestamp int64) *averageState {
+	average := &averageState{}
+
+	from := timestamp - int64(def.window/time.Second)
+	points, err := e.store.GetTelemetry(ctx, deviceID, def.SourceMetric, from+1, timestamp-1, maxSeedPoints)
+	if err != nil {
+		log.Printf("⚠️  Failed to seed moving average %s for device %s: %v", def.Name, deviceID, err)
+		return average
+	}
+
+	// GetTelemetry returns the most recent points first
+	for i := len(points) - 1; i >= 0; i-- {
+		average.points = append(average.points, timedValue{points[i].Time, points[i].Value})
+	}
+	return average
+}
+
+// add integrates the source up to point, in source unit x hours.
+// Gaps longer than maxGap are not bridged: integration restarts from point.
+func (s *integralState) add(point timedValue, maxGap time.Duration) (float64, bool, error) {
+	if s.last != nil {
+		if point.time <= s.last.time {
+			// Out of order or already integrated
+			return 0, false, nil
+		}
+		dt := time.Duration(point.time-s.last.time) * time.Second
+		if dt <= maxGap {
+			s.total += (s.last.value + point.value) / 2 * dt.Hours()
+		}
+	}
+	s.last = &point
+	return s.total, true, nil
+}
+
+// add appends point and returns the average of the points inside window.
+func (s *averageState) add(point timedValue, window time.Duration) (float64, bool, error) {
+	if n := len(s.points); n > 0 && point.time <= s.points[n-1].time {
+		return 0, false, nil
+	}
+	s.points = append(s.points, point)
+
+	cutoff := point.time - int64(window/time.Second)
+	start := 0
+	for start < len(s.points) && s.points[start].time <= cutoff {
+		start++
+	}
+	s.points = s.points[start:]
+
+	sum := 0.0
+	for _, p := range s.points {
+		sum += p.value
+	}
+	return sum / float64(len(s.points)), true, nil
+}
+
+// prune drops the windowed state of definitions that are not live and the last
+// values of stale derived metrics.
+func (s *deviceState) prune(live map[*definition]bool, stale map[string]bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for def := range s.integrals {
+		if !live[def] {
+			delete(s.integrals, def)
+		}
+	}
+	for def := range s.averages {
+		if !live[def] {
+			delete(s.averages, def)
+		}
+	}
+	for name := range stale {
+		delete(s.values, name)
+	}
+}
+
+// remember records the latest value of a metric.
+func (s *deviceState) remember(name string, timestamp int64, value float64) {
+	if last, ok := s.values[name]; ok && last.time > timestamp {
+		return
+	}
+	s.values[name] = timedValue{timestamp, value}
+}
+
+// deviceState returns the state of a device, creating it if needed.
+func (e *Engine) deviceState(deviceID string) *deviceState {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	state, ok := e.devices[deviceID]
+	if !ok {
+		state = &deviceState{
+			values:    make(map[string]timedValue),
+			integrals: make(map[*definition]*integralState),
+			averages:  make(map[*definition]*averageState),
+		}
+		e.devices[deviceID] = state
+	}
+	return state
+}
+
+// definitionsFor returns the definitions for the device's type.
+func (e *Engine) definitionsFor(ctx context.Context, deviceID string) []*definition {
+	e.mu.Lock()
+	if len(e.byType) == 0 {
+		e.mu.Unlock()
+		return nil
+	}
+	e.mu.Unlock()
+
+	deviceType, err := e.deviceTypes.Type(ctx, deviceID)
+	if err != nil {
+		log.Printf("⚠️  Failed to get type of device %s: %v", deviceID, err)
+		return nil
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.byType[deviceType]
+}
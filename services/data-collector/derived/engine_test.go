@@ -0,0 +1,266 @@
+// +build unit
+
+package derived
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/iot-platform/services/data-collector/storage"
+	pb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+)
+
+const testDeviceType = "meter"
+
+// fakeStore serves derived metric definitions and registers every device with
+// testDeviceType. It stores no telemetry, so windowed state starts empty.
+type fakeStore struct {
+	storage.Storage
+
+	mu      sync.Mutex
+	metrics []*pb.DerivedMetric
+	seeds   int
+}
+
+func (s *fakeStore) ListDerivedMetrics(ctx context.Context, deviceType string) ([]*pb.DerivedMetric, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.metrics, nil
+}
+
+func (s *fakeStore) setMetrics(metrics ...*pb.DerivedMetric) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metrics = metrics
+}
+
+func (s *fakeStore) GetDeviceInfo(ctx context.Context, deviceID string) (storage.DeviceInfo, error) {
+	return storage.DeviceInfo{Type: testDeviceType, OrgID: "org-1"}, nil
+}
+
+func (s *fakeStore) GetLatestMetric(ctx context.Context, deviceID, metricName string) (*pb.TelemetryPoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seeds++
+	return nil, fmt.Errorf("no telemetry found for device %s metric %s", deviceID, metricName)
+}
+
+func (s *fakeStore) GetTelemetry(ctx context.Context, deviceID, metricName string, fromTime, toTime int64, limit int) ([]*pb.TelemetryPoint, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seeds++
+	return nil, nil
+}
+
+func expression(name, src string) *pb.DerivedMetric {
+	return &pb.DerivedMetric{DeviceType: testDeviceType, Name: name, Kind: pb.DerivedMetricKind_DERIVED_METRIC_EXPRESSION, Expression: src}
+}
+
+func integral(name, source string) *pb.DerivedMetric {
+	return &pb.DerivedMetric{DeviceType: testDeviceType, Name: name, Kind: pb.DerivedMetricKind_DERIVED_METRIC_INTEGRAL, SourceMetric: source, Unit: "Wh"}
+}
+
+func movingAverage(name, source, window string) *pb.DerivedMetric {
+	return &pb.DerivedMetric{DeviceType: testDeviceType, Name: name, Kind: pb.DerivedMetricKind_DERIVED_METRIC_MOVING_AVERAGE, SourceMetric: source, Window: window}
+}
+
+func newTestEngine(t *testing.T, metrics ...*pb.DerivedMetric) (*Engine, *fakeStore) {
+	t.Helper()
+	store := &fakeStore{metrics: metrics}
+	engine := NewEngine(store, storage.NewDeviceCache(store, time.Minute), time.Hour)
+	if err := engine.Load(context.Background()); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	return engine, store
+}
+
+// evaluate returns the derived values of one message, by name
+func evaluate(engine *Engine, timestamp int64, samples ...Sample) map[string]float64 {
+	values := make(map[string]float64)
+	for _, sample := range engine.Evaluate(context.Background(), "dev-1", timestamp, samples) {
+		values[sample.Name] = sample.Value
+	}
+	return values
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		metric  *pb.DerivedMetric
+		wantErr bool
+	}{
+		{"expression", expression("power", "voltage * current"), false},
+		{"integral", integral("energy", "power"), false},
+		{"moving_average", movingAverage("avg", "temperature", "15m"), false},
+		{"syntax_error", expression("power", "voltage *"), true},
+		{"no_metric", expression("constant", "1 + 2"), true},
+		{"self_reference", expression("power", "power * 2"), true},
+		{"integral_of_itself", integral("energy", "energy"), true},
+		{"integral_without_source", integral("energy", ""), true},
+		{"average_without_source", movingAverage("avg", "", "15m"), true},
+		{"average_invalid_window", movingAverage("avg", "temperature", "15"), true},
+		{"average_short_window", movingAverage("avg", "temperature", "10ms"), true},
+		{"unknown_kind", &pb.DerivedMetric{Name: "x", SourceMetric: "y"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.metric); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOrderDefinitions(t *testing.T) {
+	var defs []*definition
+	for _, metric := range []*pb.DerivedMetric{
+		integral("energy", "power"),
+		expression("cost", "energy * 0.2"),
+		expression("power", "voltage * current"),
+		// Cycle, dropped
+		expression("x", "y + 1"),
+		expression("y", "x + 1"),
+		expression("z", "y * 2"),
+	} {
+		def, err := compile(metric)
+		if err != nil {
+			t.Fatalf("compile(%s) failed: %v", metric.Name, err)
+		}
+		defs = append(defs, def)
+	}
+
+	ordered := orderDefinitions(testDeviceType, defs)
+	position := make(map[string]int)
+	for i, def := range ordered {
+		position[def.Name] = i
+	}
+	if len(ordered) != 3 {
+		t.Fatalf("expected power, energy and cost, got %d definitions", len(ordered))
+	}
+	for _, name := range []string{"x", "y", "z"} {
+		if _, ok := position[name]; ok {
+			t.Errorf("%s depends on a cycle and should be dropped", name)
+		}
+	}
+	if !(position["power"] < position["energy"] && position["energy"] < position["cost"]) {
+		t.Errorf("inputs must be computed first, got order %v", position)
+	}
+}
+
+func TestEngine_Expression(t *testing.T) {
+	engine, _ := newTestEngine(t,
+		expression("power", "voltage * current"),
+		expression("cost", "power * 0.5"),
+	)
+
+	values := evaluate(engine, 1000, Sample{Name: "voltage", Value: 230}, Sample{Name: "current", Value: 2})
+	if values["power"] != 460 || values["cost"] != 230 {
+		t.Errorf("unexpected derived values %v", values)
+	}
+
+	// The last current, 5 minutes old, is combined with the new voltage
+	values = evaluate(engine, 1300, Sample{Name: "voltage", Value: 220})
+	if values["power"] != 440 {
+		t.Errorf("expected power 440 from the last current, got %v", values)
+	}
+
+	// Past maxGap, the last current is too old
+	values = evaluate(engine, 5000, Sample{Name: "voltage", Value: 230})
+	if _, ok := values["power"]; ok {
+		t.Errorf("expected no power with a stale current, got %v", values)
+	}
+
+	// Not triggered by unrelated metrics
+	if values := evaluate(engine, 5100, Sample{Name: "temperature", Value: 20}); len(values) != 0 {
+		t.Errorf("expected no derived values, got %v", values)
+	}
+}
+
+func TestEngine_Integral(t *testing.T) {
+	engine, _ := newTestEngine(t, integral("energy", "power"))
+
+	steps := []struct {
+		time  int64
+		power float64
+		want  float64
+	}{
+		{0, 100, 0},
+		{1800, 300, 100},        // 30 min at an average of 200 W
+		{3600, 300, 250},        // 30 min at 300 W
+		{3600 + 4000, 500, 250}, // gap longer than maxGap: not bridged
+		{3600 + 4300, 700, 300}, // 5 min at 600 W
+	}
+	for _, step := range steps {
+		values := evaluate(engine, step.time, Sample{Name: "power", Value: step.power})
+		if math.Abs(values["energy"]-step.want) > 1e-9 {
+			t.Errorf("energy at %d = %v, want %v", step.time, values["energy"], step.want)
+		}
+	}
+
+	// Out of order points are not integrated
+	if values := evaluate(engine, 1000, Sample{Name: "power", Value: 1000}); len(values) != 0 {
+		t.Errorf("expected out of order point to be skipped, got %v", values)
+	}
+}
+
+func TestEngine_MovingAverage(t *testing.T) {
+	engine, _ := newTestEngine(t, movingAverage("avg", "temperature", "1m"))
+
+	steps := []struct {
+		time int64
+		temp float64
+		want float64
+	}{
+		{0, 10, 10},
+		{30, 20, 15},
+		{59, 30, 20},
+		{90, 40, 35}, // 0 and 30 left the window
+	}
+	for _, step := range steps {
+		values := evaluate(engine, step.time, Sample{Name: "temperature", Value: step.temp})
+		if values["avg"] != step.want {
+			t.Errorf("average at %d = %v, want %v", step.time, values["avg"], step.want)
+		}
+	}
+}
+
+// TestEngine_ReloadKeepsState checks that periodic reloads keep the windowed
+// state of unchanged definitions, and drop that of changed ones
+func TestEngine_ReloadKeepsState(t *testing.T) {
+	engine, store := newTestEngine(t, integral("energy", "power"), expression("double", "energy * 2"))
+	ctx := context.Background()
+
+	evaluate(engine, 0, Sample{Name: "power", Value: 100})
+	evaluate(engine, 3600, Sample{Name: "power", Value: 100})
+	seeds := store.seeds
+
+	if err := engine.Load(ctx); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	values := evaluate(engine, 7200, Sample{Name: "power", Value: 100})
+	if values["energy"] != 200 || values["double"] != 400 {
+		t.Errorf("expected the integral to carry over the reload, got %v", values)
+	}
+	if store.seeds != seeds {
+		t.Errorf("unchanged definition re-seeded from storage")
+	}
+
+	// A changed definition starts over
+	changed := integral("energy", "power")
+	changed.Unit = "kWh"
+	store.setMetrics(changed)
+	if err := engine.Load(ctx); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	values = evaluate(engine, 10800, Sample{Name: "power", Value: 100})
+	if values["energy"] != 0 {
+		t.Errorf("expected a changed integral to restart, got %v", values)
+	}
+	if _, ok := values["double"]; ok {
+		t.Errorf("removed definition still evaluated: %v", values)
+	}
+}
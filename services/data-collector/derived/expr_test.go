@@ -0,0 +1,108 @@
+// +build unit
+
+package derived
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpression_Eval(t *testing.T) {
+	vars := map[string]float64{"a": 10, "b": 3, "c": 2}
+	tests := []struct {
+		src  string
+		want float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"a - b - c", 5},
+		{"a / 4", 2.5},
+		{"2 ^ 3 ^ 2", 512},
+		{"-2 ^ 2", -4},
+		{"--a", 10},
+		{"1e3 + 2.5E-1", 1000.25},
+		{".5 * a", 5},
+		{"sqrt(16) + abs(-c)", 6},
+		{"pow(c, 10)", 1024},
+		{"min(a, b, c)", 2},
+		{"max(a)", 10},
+		{"ln(exp(b))", 3},
+		{"log10(1000)", 3},
+		{"a * b / (c + 1)", 10},
+	}
+	for _, tt := range tests {
+		expr, err := ParseExpression(tt.src)
+		if err != nil {
+			t.Errorf("ParseExpression(%q) failed: %v", tt.src, err)
+			continue
+		}
+		got, err := expr.Eval(vars)
+		if err != nil {
+			t.Errorf("Eval(%q) failed: %v", tt.src, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Eval(%q) = %v, want %v", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestExpression_Variables(t *testing.T) {
+	expr, err := ParseExpression("voltage * current + max(voltage, offset_2)")
+	if err != nil {
+		t.Fatalf("ParseExpression failed: %v", err)
+	}
+	want := []string{"current", "offset_2", "voltage"}
+	if got := expr.Variables(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Variables() = %v, want %v", got, want)
+	}
+	if expr.String() != "voltage * current + max(voltage, offset_2)" {
+		t.Errorf("String() = %q", expr.String())
+	}
+}
+
+func TestParseExpression_Errors(t *testing.T) {
+	tests := []struct {
+		src     string
+		message string
+	}{
+		{"", "unexpected end of expression"},
+		{"1 +", "unexpected end of expression"},
+		{"(a + b", "expected )"},
+		{"a b", `unexpected "b"`},
+		{"a $ b", `unexpected "$"`},
+		{"foo(a)", "unknown function foo"},
+		{"pow(a)", "pow expects 2 argument(s), got 1"},
+		{"sqrt(a, b)", "sqrt expects 1 argument(s), got 2"},
+		{"max(a, )", "unexpected \")\""},
+		{"1..2", `invalid number "1..2"`},
+	}
+	for _, tt := range tests {
+		_, err := ParseExpression(tt.src)
+		if err == nil || !strings.Contains(err.Error(), tt.message) {
+			t.Errorf("ParseExpression(%q) error = %v, want %q", tt.src, err, tt.message)
+		}
+	}
+}
+
+func TestExpression_EvalErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		vars map[string]float64
+	}{
+		{"a / b", map[string]float64{"a": 1, "b": 0}},
+		{"sqrt(a)", map[string]float64{"a": -1}},
+		{"ln(a)", map[string]float64{"a": 0}},
+		{"a + b", map[string]float64{"a": 1}},
+	}
+	for _, tt := range tests {
+		expr, err := ParseExpression(tt.src)
+		if err != nil {
+			t.Fatalf("ParseExpression(%q) failed: %v", tt.src, err)
+		}
+		if v, err := expr.Eval(tt.vars); err == nil {
+			t.Errorf("Eval(%q, %v) = %v, want error", tt.src, tt.vars, v)
+		}
+	}
+}
@@ -4,19 +4,35 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"math"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"github.com/yourusername/iot-platform/services/data-collector/anomaly"
+	"github.com/yourusername/iot-platform/services/data-collector/catalog"
+	"github.com/yourusername/iot-platform/services/data-collector/derived"
+	"github.com/yourusername/iot-platform/services/data-collector/metrics"
 	"github.com/yourusername/iot-platform/services/data-collector/mqtt"
 	"github.com/yourusername/iot-platform/services/data-collector/publisher"
+	"github.com/yourusername/iot-platform/services/data-collector/retention"
 	"github.com/yourusername/iot-platform/services/data-collector/storage"
+	"github.com/yourusername/iot-platform/services/data-collector/units"
+	"github.com/yourusername/iot-platform/shared/eventbus"
 	pb "github.com/yourusername/iot-platform/shared/proto/telemetry"
 )
 
@@ -24,33 +40,124 @@ import (
 type TelemetryServer struct {
 	pb.UnimplementedTelemetryServiceServer
 	storage storage.Storage
+	derived *derived.Engine
+	// normalizer converts imported values to canonical units, nil when disabled
+	normalizer *catalog.Catalog
+	// validator checks imported values against the catalog in validationMode,
+	// nil when disabled
+	validator      *catalog.Catalog
+	validationMode string
+	hub            *publisher.Hub
+	devices        *storage.DeviceCache
 }
 
 // NewTelemetryServer creates a new server instance with the given storage backend.
-func NewTelemetryServer(store storage.Storage) *TelemetryServer {
+// The derived metrics engine is reloaded when definitions change. Imported rows
+// are normalized and validated like live telemetry when normalizer and validator
+// are not nil. Live streams are fed by hub. devices resolves the organization of
+// the devices requested.
+func NewTelemetryServer(store storage.Storage, engine *derived.Engine, normalizer, validator *catalog.Catalog, validationMode string, hub *publisher.Hub, devices *storage.DeviceCache) *TelemetryServer {
 	return &TelemetryServer{
-		storage: store,
+		storage:        store,
+		derived:        engine,
+		normalizer:     normalizer,
+		validator:      validator,
+		validationMode: validationMode,
+		hub:            hub,
+		devices:        devices,
 	}
 }
 
+// checkDeviceOrg returns NotFound when orgID is set and the device belongs to
+// another organization, so that other tenants' devices look unregistered.
+// An empty orgID (internal calls) allows every device.
+func (s *TelemetryServer) checkDeviceOrg(ctx context.Context, orgID, deviceID string) error {
+	if orgID == "" {
+		return nil
+	}
+	deviceOrg, err := s.devices.Org(ctx, deviceID)
+	if err != nil {
+		return err
+	}
+	if deviceOrg != orgID {
+		return status.Errorf(codes.NotFound, "device %s not found", deviceID)
+	}
+	return nil
+}
+
 // GetTelemetry retrieves telemetry data for a device within a time range.
+// With a target unit, points are converted; those without a unit, with an
+// unknown one or in another dimension keep their raw value and their own unit.
 func (s *TelemetryServer) GetTelemetry(ctx context.Context, req *pb.GetTelemetryRequest) (*pb.GetTelemetryResponse, error) {
-	log.Printf("📥 GetTelemetry: device=%s, metric=%s", req.DeviceId, req.MetricName)
+	log.Printf("📥 GetTelemetry: org=%s, device=%s, metric=%s, unit=%s", req.OrgId, req.DeviceId, req.MetricName, req.Unit)
+
+	target, err := targetUnit(req.Unit)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkDeviceOrg(ctx, req.OrgId, req.DeviceId); err != nil {
+		return nil, err
+	}
 
 	points, err := s.storage.GetTelemetry(ctx, req.DeviceId, req.MetricName, req.FromTime, req.ToTime, int(req.Limit))
 	if err != nil {
 		return nil, err
 	}
 
+	unconverted := 0
+	if target != nil {
+		for _, point := range points {
+			if !convertPoint(point, target) {
+				unconverted++
+			}
+		}
+	}
+	if unconverted > 0 {
+		log.Printf("⚠️  %d telemetry points cannot be converted to %s, returned in their own unit", unconverted, target.Name)
+	}
+
 	log.Printf("✅ Found %d telemetry points", len(points))
 	return &pb.GetTelemetryResponse{Points: points}, nil
 }
 
+// targetUnit resolves the unit requested by a query, nil if none.
+func targetUnit(name string) (*units.Unit, error) {
+	if name == "" {
+		return nil, nil
+	}
+	unit, ok := units.Lookup(name)
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown unit %q", name)
+	}
+	return unit, nil
+}
+
+// convertPoint converts a point in place to target. It returns false if the
+// point's unit is unknown or measures another quantity.
+func convertPoint(point *pb.TelemetryPoint, target *units.Unit) bool {
+	value, err := units.Convert(point.Value, point.Unit, target.Name)
+	if err != nil {
+		return false
+	}
+	point.Value, point.Unit = value, target.Name
+	return true
+}
+
 // GetTelemetryAggregated retrieves aggregated telemetry data.
 func (s *TelemetryServer) GetTelemetryAggregated(ctx context.Context, req *pb.GetTelemetryAggregatedRequest) (*pb.GetTelemetryAggregatedResponse, error) {
-	log.Printf("📥 GetTelemetryAggregated: device=%s, metric=%s, interval=%s", req.DeviceId, req.MetricName, req.Interval)
+	log.Printf("📥 GetTelemetryAggregated: org=%s, device=%s, metric=%s, interval=%s, unit=%s", req.OrgId, req.DeviceId, req.MetricName, req.Interval, req.Unit)
 
-	aggregations, err := s.storage.GetTelemetryAggregated(ctx, req.DeviceId, req.MetricName, req.FromTime, req.ToTime, req.Interval)
+	if _, err := targetUnit(req.Unit); err != nil {
+		return nil, err
+	}
+	if err := s.checkDeviceOrg(ctx, req.OrgId, req.DeviceId); err != nil {
+		return nil, err
+	}
+
+	aggregations, err := s.storage.GetTelemetryAggregated(ctx, req.DeviceId, req.MetricName, req.FromTime, req.ToTime, req.Interval, req.Unit)
+	if errors.Is(err, units.ErrIncompatibleUnits) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -61,28 +168,713 @@ func (s *TelemetryServer) GetTelemetryAggregated(ctx context.Context, req *pb.Ge
 
 // GetLatestMetric retrieves the latest value for a specific metric.
 func (s *TelemetryServer) GetLatestMetric(ctx context.Context, req *pb.GetLatestMetricRequest) (*pb.GetLatestMetricResponse, error) {
-	log.Printf("📥 GetLatestMetric: device=%s, metric=%s", req.DeviceId, req.MetricName)
+	log.Printf("📥 GetLatestMetric: org=%s, device=%s, metric=%s, unit=%s", req.OrgId, req.DeviceId, req.MetricName, req.Unit)
+
+	target, err := targetUnit(req.Unit)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkDeviceOrg(ctx, req.OrgId, req.DeviceId); err != nil {
+		return nil, err
+	}
 
 	point, err := s.storage.GetLatestMetric(ctx, req.DeviceId, req.MetricName)
 	if err != nil {
 		return nil, err
 	}
+	if target != nil && !convertPoint(point, target) {
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot convert %q to %q", point.Unit, req.Unit)
+	}
 
 	log.Printf("✅ Latest value: %v", point.Value)
 	return &pb.GetLatestMetricResponse{Point: point}, nil
 }
 
-// GetDeviceMetrics retrieves all available metrics for a device.
+// GetDeviceMetrics retrieves the metrics of a device: those declared in its
+// type's catalog and those it has reported.
 func (s *TelemetryServer) GetDeviceMetrics(ctx context.Context, req *pb.GetDeviceMetricsRequest) (*pb.GetDeviceMetricsResponse, error) {
-	log.Printf("📥 GetDeviceMetrics: device=%s", req.DeviceId)
+	log.Printf("📥 GetDeviceMetrics: org=%s, device=%s", req.OrgId, req.DeviceId)
+
+	if err := s.checkDeviceOrg(ctx, req.OrgId, req.DeviceId); err != nil {
+		return nil, err
+	}
+
+	catalog, err := s.storage.GetDeviceMetrics(ctx, req.DeviceId)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(catalog))
+	for i, info := range catalog {
+		names[i] = info.Name
+	}
+
+	log.Printf("✅ Found %d metrics", len(catalog))
+	return &pb.GetDeviceMetricsResponse{Metrics: names, Catalog: catalog}, nil
+}
+
+// Limits for batch queries, to keep a single request from scanning the whole hypertable.
+const (
+	maxBatchDevices = 100
+	maxBatchMetrics = 20
+)
+
+// GetTelemetryBatch retrieves aligned aggregated series for several devices and metrics.
+func (s *TelemetryServer) GetTelemetryBatch(ctx context.Context, req *pb.GetTelemetryBatchRequest) (*pb.GetTelemetryBatchResponse, error) {
+	log.Printf("📥 GetTelemetryBatch: org=%s, devices=%d, groups=%d, location=%s, metrics=%v, interval=%s, groupBy=%s",
+		req.OrgId, len(req.DeviceIds), len(req.Filter.GetGroupIds()), req.Filter.GetLocationId(), req.MetricNames, req.Interval, req.GroupBy)
+
+	filter := req.Filter
+	if len(req.DeviceIds) == 0 && filter.GetType() == "" && len(filter.GetMetadata()) == 0 &&
+		len(filter.GetGroupIds()) == 0 && filter.GetLocationId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "device_ids or filter required")
+	}
+	if req.GroupBy == pb.TelemetryGroupBy_GROUP_BY_GROUP && len(filter.GetGroupIds()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "filter.group_ids required to group by group")
+	}
+	if req.LocationKind != "" && !locationKinds[req.LocationKind] {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown location kind %q", req.LocationKind)
+	}
+	if len(req.DeviceIds) > maxBatchDevices {
+		return nil, status.Errorf(codes.InvalidArgument, "too many devices: maximum is %d", maxBatchDevices)
+	}
+	if len(req.MetricNames) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "metric_names required")
+	}
+	if len(req.MetricNames) > maxBatchMetrics {
+		return nil, status.Errorf(codes.InvalidArgument, "too many metrics: maximum is %d", maxBatchMetrics)
+	}
+	if req.ToTime < req.FromTime {
+		return nil, status.Error(codes.InvalidArgument, "to_time must be after from_time")
+	}
+	if _, err := targetUnit(req.Unit); err != nil {
+		return nil, err
+	}
+
+	series, err := s.storage.GetTelemetryBatch(ctx, &storage.BatchQuery{
+		OrgID:        req.OrgId,
+		DeviceIDs:    req.DeviceIds,
+		DeviceType:   filter.GetType(),
+		Metadata:     filter.GetMetadata(),
+		GroupIDs:     filter.GetGroupIds(),
+		LocationID:   filter.GetLocationId(),
+		MetricNames:  req.MetricNames,
+		FromTime:     req.FromTime,
+		ToTime:       req.ToTime,
+		Interval:     req.Interval,
+		GroupBy:      req.GroupBy,
+		LocationKind: req.LocationKind,
+		Unit:         req.Unit,
+	})
+	if errors.Is(err, storage.ErrBatchTooLarge) {
+		return nil, status.Errorf(codes.InvalidArgument, "%v: narrow the range, the filter or the metrics, or use a wider interval", err)
+	}
+	if errors.Is(err, units.ErrIncompatibleUnits) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Found %d series", len(series))
+	return &pb.GetTelemetryBatchResponse{Series: series}, nil
+}
+
+// locationKinds lists the levels of the location hierarchy that batch
+// queries can group by, see migration 012_create_device_groups.sql.
+var locationKinds = map[string]bool{
+	"site":     true,
+	"building": true,
+	"floor":    true,
+	"room":     true,
+}
+
+// Page sizes for telemetry exports.
+const (
+	defaultExportPageSize = 5000
+	maxExportPageSize     = 50000
+)
+
+// ExportTelemetry streams raw telemetry in pages read through a server-side cursor.
+// Each chunk carries the position of its last record so an interrupted export can
+// be resumed by passing that cursor back.
+func (s *TelemetryServer) ExportTelemetry(req *pb.ExportTelemetryRequest, stream grpc.ServerStreamingServer[pb.ExportTelemetryChunk]) error {
+	log.Printf("📥 ExportTelemetry: org=%s, devices=%d, metrics=%v, from=%d, to=%d", req.OrgId, len(req.DeviceIds), req.MetricNames, req.FromTime, req.ToTime)
+
+	if req.ToTime < req.FromTime {
+		return status.Error(codes.InvalidArgument, "to_time must be after from_time")
+	}
+
+	pageSize := int(req.PageSize)
+	if pageSize <= 0 {
+		pageSize = defaultExportPageSize
+	}
+	if pageSize > maxExportPageSize {
+		pageSize = maxExportPageSize
+	}
+
+	query := &storage.ExportQuery{
+		OrgID:       req.OrgId,
+		DeviceIDs:   req.DeviceIds,
+		MetricNames: req.MetricNames,
+		FromTime:    req.FromTime,
+		ToTime:      req.ToTime,
+	}
+	if req.Cursor != "" {
+		after, err := parseExportCursor(req.Cursor)
+		if err != nil {
+			return status.Errorf(codes.InvalidArgument, "invalid cursor: %v", err)
+		}
+		query.After = after
+	}
+
+	total := 0
+	err := s.storage.ExportTelemetry(stream.Context(), query, pageSize, func(records []*pb.TelemetryRecord) error {
+		total += len(records)
+		last := records[len(records)-1]
+		return stream.Send(&pb.ExportTelemetryChunk{
+			Records: records,
+			Cursor:  formatExportCursor(last.Time, last.DeviceId, last.MetricName),
+		})
+	})
+	if err != nil {
+		log.Printf("❌ Export failed after %d records: %v", total, err)
+		return err
+	}
+
+	log.Printf("✅ Exported %d records", total)
+	return nil
+}
+
+// formatExportCursor encodes an export position as "<unix>|<device_id>|<metric_name>".
+// The format is deliberately readable so clients can rebuild it from the last row they received.
+func formatExportCursor(timestamp int64, deviceID, metricName string) string {
+	return fmt.Sprintf("%d|%s|%s", timestamp, deviceID, metricName)
+}
+
+// parseExportCursor decodes a cursor produced by formatExportCursor.
+func parseExportCursor(cursor string) (*storage.ExportPosition, error) {
+	parts := strings.SplitN(cursor, "|", 3)
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return nil, fmt.Errorf("expected <unix>|<device_id>|<metric_name>")
+	}
+
+	timestamp, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp: %w", err)
+	}
+
+	return &storage.ExportPosition{
+		Time:       timestamp,
+		DeviceID:   parts[1],
+		MetricName: parts[2],
+	}, nil
+}
+
+// Limits on imported values, matching the device_telemetry column sizes.
+const (
+	maxMetricNameLength = 100
+	maxUnitLength       = 50
+)
+
+// ImportTelemetry bulk-loads historical telemetry streamed by the client.
+// Rows are validated as they arrive, written in a single transaction through
+// COPY, and deliberately not published on the event bus: live subscribers only see
+// real-time data. Continuous aggregates are refreshed over the imported range.
+// With an organization, every record must belong to one of its devices.
+func (s *TelemetryServer) ImportTelemetry(stream grpc.ClientStreamingServer[pb.ImportTelemetryRequest, pb.ImportTelemetryResponse]) error {
+	ctx := stream.Context()
+
+	first, err := stream.Recv()
+	if err == io.EOF {
+		return status.Error(codes.InvalidArgument, "empty import")
+	}
+	if err != nil {
+		return err
+	}
+	policy, orgID := first.OnConflict, first.OrgId
+	log.Printf("📥 ImportTelemetry: org=%s, onConflict=%s", orgID, policy)
+
+	pending := first
+	row := 0
+	next := func() ([]*storage.TelemetryPoint, error) {
+		req := pending
+		pending = nil
+		if req == nil {
+			var err error
+			if req, err = stream.Recv(); err != nil {
+				return nil, err
+			}
+		}
+
+		points := make([]*storage.TelemetryPoint, 0, len(req.Records))
+		for _, record := range req.Records {
+			row++
+			if err := validateImportRecord(record); err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "row %d: %v", row, err)
+			}
+			if err := s.checkDeviceOrg(ctx, orgID, record.DeviceId); err != nil {
+				if status.Code(err) == codes.NotFound {
+					return nil, status.Errorf(codes.FailedPrecondition, "row %d: unknown device %s", row, record.DeviceId)
+				}
+				return nil, err
+			}
+			metric := normalize(ctx, s.normalizer, record.DeviceId, mqtt.Metric{
+				Name:  record.MetricName,
+				Value: record.Value,
+				Unit:  record.Unit,
+			})
+			// The import is atomic: in reject mode, one invalid row fails it
+			metadata, rejected := validate(ctx, s.validator, s.validationMode, record.DeviceId, metric)
+			if rejected != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "row %d: metric %s: %s", row, record.MetricName, rejected.Detail)
+			}
+			points = append(points, &storage.TelemetryPoint{
+				DeviceID:   record.DeviceId,
+				MetricName: metric.Name,
+				Value:      metric.Value,
+				Unit:       metric.Unit,
+				Timestamp:  record.Time,
+				Metadata:   metadata,
+			})
+		}
+		return points, nil
+	}
+
+	result, err := s.storage.ImportTelemetry(ctx, policy, next)
+	if err != nil {
+		log.Printf("❌ Import failed: %v", err)
+		var unknown *storage.UnknownDevicesError
+		switch {
+		case errors.As(err, &unknown):
+			return status.Error(codes.FailedPrecondition, err.Error())
+		case errors.Is(err, storage.ErrImportConflict):
+			return status.Error(codes.AlreadyExists, err.Error())
+		}
+		return err
+	}
+
+	if result.Received > 0 {
+		if err := s.storage.RefreshAggregates(ctx, result.FromTime, result.ToTime); err != nil {
+			log.Printf("⚠️ Imported rows committed but aggregate refresh failed: %v", err)
+		}
+	}
+
+	log.Printf("✅ Imported %d rows (%d written, %d skipped)", result.Received, result.Written, result.Skipped)
+	return stream.SendAndClose(&pb.ImportTelemetryResponse{
+		RowsReceived: result.Received,
+		RowsWritten:  result.Written,
+		RowsSkipped:  result.Skipped,
+		FromTime:     result.FromTime,
+		ToTime:       result.ToTime,
+	})
+}
+
+// streamBufferSize is the number of live points a stream may lag behind
+// ingestion before it is disconnected.
+const streamBufferSize = 1024
+
+// streamReplayOverlap is how far back, in device time, the replay that follows
+// the live subscription starts: points of late or clock-skewed devices stored
+// while the bulk of the history was sent are replayed too.
+const streamReplayOverlap = time.Minute
+
+// streamKey identifies a point sent by a stream, to send it only once.
+type streamKey struct {
+	deviceID, metricName string
+	time                 int64
+}
+
+// StreamTelemetry sends points as this instance ingests them, derived metrics
+// included. Points ingested by other data-collector replicas are not sent
+// live; they are only part of the replay. With from_time, stored points are
+// replayed first: history is read up to the current time, then the live
+// subscription is opened and the last minute and the seconds ingested
+// meanwhile are replayed again. Points are deduplicated on (device, metric,
+// time) around the switch, whatever their device time, so that it loses no
+// point. A client that falls behind is disconnected with ResourceExhausted and
+// can resume with from_time set to the last time it received. With an
+// organization, only the points of its devices are sent.
+func (s *TelemetryServer) StreamTelemetry(req *pb.StreamTelemetryRequest, stream grpc.ServerStreamingServer[pb.StreamTelemetryEvent]) error {
+	log.Printf("📥 StreamTelemetry: org=%s, devices=%d, metrics=%v, from=%d", req.OrgId, len(req.DeviceIds), req.MetricNames, req.FromTime)
+	ctx := stream.Context()
+
+	if req.FromTime < 0 {
+		return status.Error(codes.InvalidArgument, "from_time must not be negative")
+	}
+	for _, deviceID := range req.DeviceIds {
+		if err := s.checkDeviceOrg(ctx, req.OrgId, deviceID); err != nil {
+			return err
+		}
+	}
+	// Listed devices were checked above, a wildcard stream is filtered point by point
+	inOrg := func(deviceID string) bool {
+		if req.OrgId == "" || len(req.DeviceIds) > 0 {
+			return true
+		}
+		deviceOrg, err := s.devices.Org(ctx, deviceID)
+		return err == nil && deviceOrg == req.OrgId
+	}
+
+	metrics.TelemetryStreams.Inc()
+	defer metrics.TelemetryStreams.Dec()
+
+	// Points sent since overlapFrom, which the second replay and the first
+	// live points may send again
+	var overlapFrom int64
+	sent := make(map[streamKey]bool)
+
+	replayed := 0
+	replay := func(from, to int64) error {
+		if from > to {
+			return nil
+		}
+		query := &storage.ExportQuery{
+			OrgID:       req.OrgId,
+			DeviceIDs:   req.DeviceIds,
+			MetricNames: req.MetricNames,
+			FromTime:    from,
+			ToTime:      to,
+		}
+		return s.storage.ExportTelemetry(ctx, query, defaultExportPageSize, func(records []*pb.TelemetryRecord) error {
+			for _, record := range records {
+				if record.Time >= overlapFrom {
+					key := streamKey{record.DeviceId, record.MetricName, record.Time}
+					if sent[key] {
+						continue
+					}
+					sent[key] = true
+				}
+				if err := stream.Send(&pb.StreamTelemetryEvent{Record: record, Replay: true}); err != nil {
+					return err
+				}
+				replayed++
+			}
+			return nil
+		})
+	}
+
+	// Bulk of the history, before subscribing so that live points do not pile up
+	if req.FromTime > 0 {
+		replayEnd := time.Now().Unix()
+		overlapFrom = max(req.FromTime, replayEnd-int64(streamReplayOverlap/time.Second))
+		if err := replay(req.FromTime, replayEnd); err != nil {
+			log.Printf("❌ Stream replay failed after %d records: %v", replayed, err)
+			return err
+		}
+	}
+
+	sub := s.hub.Subscribe(req.DeviceIds, req.MetricNames, streamBufferSize)
+	defer sub.Close()
+
+	// Points stored while the bulk was sent, late ones included. Live points
+	// already replayed are skipped below.
+	if req.FromTime > 0 {
+		if err := replay(overlapFrom, time.Now().Unix()); err != nil {
+			log.Printf("❌ Stream replay failed after %d records: %v", replayed, err)
+			return err
+		}
+		log.Printf("✅ Replayed %d records, streaming live", replayed)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("✅ Stream closed by client")
+			return nil
+		case record, ok := <-sub.C:
+			if !ok {
+				log.Printf("⚠️  Stream dropped: client fell behind")
+				return status.Error(codes.ResourceExhausted, "stream fell behind ingestion, resume with from_time")
+			}
+			if sent[streamKey{record.DeviceId, record.MetricName, record.Time}] || !inOrg(record.DeviceId) {
+				continue
+			}
+			if err := stream.Send(&pb.StreamTelemetryEvent{Record: record}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// validateImportRecord checks a record before it is staged for COPY.
+// Device existence is checked by storage once the whole import is staged.
+func validateImportRecord(record *pb.TelemetryRecord) error {
+	if !isUUID(record.DeviceId) {
+		return fmt.Errorf("invalid device_id %q", record.DeviceId)
+	}
+	if record.MetricName == "" || len(record.MetricName) > maxMetricNameLength {
+		return fmt.Errorf("metric_name must be 1-%d characters", maxMetricNameLength)
+	}
+	if len(record.Unit) > maxUnitLength {
+		return fmt.Errorf("unit must be at most %d characters", maxUnitLength)
+	}
+	if record.Time <= 0 {
+		return fmt.Errorf("invalid time %d", record.Time)
+	}
+	if math.IsNaN(record.Value) || math.IsInf(record.Value, 0) {
+		return fmt.Errorf("invalid value %v", record.Value)
+	}
+	return nil
+}
+
+// isUUID reports whether s is a canonical 8-4-4-4-12 hex UUID.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, c := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ListRetentionPolicies returns all retention policies.
+func (s *TelemetryServer) ListRetentionPolicies(ctx context.Context, req *pb.ListRetentionPoliciesRequest) (*pb.ListRetentionPoliciesResponse, error) {
+	log.Printf("📥 ListRetentionPolicies")
+
+	policies, err := s.storage.ListRetentionPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Found %d retention policies", len(policies))
+	return &pb.ListRetentionPoliciesResponse{Policies: policies}, nil
+}
+
+// UpsertRetentionPolicy creates or replaces the policy for a (device type, metric) scope.
+func (s *TelemetryServer) UpsertRetentionPolicy(ctx context.Context, req *pb.UpsertRetentionPolicyRequest) (*pb.RetentionPolicy, error) {
+	log.Printf("📥 UpsertRetentionPolicy: type=%q, metric=%q", req.Policy.GetDeviceType(), req.Policy.GetMetricName())
+
+	policy, err := validateRetentionPolicy(req.Policy)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 
-	metrics, err := s.storage.GetDeviceMetrics(ctx, req.DeviceId)
+	saved, err := s.storage.UpsertRetentionPolicy(ctx, policy)
 	if err != nil {
 		return nil, err
 	}
 
-	log.Printf("✅ Found %d metrics", len(metrics))
-	return &pb.GetDeviceMetricsResponse{Metrics: metrics}, nil
+	log.Printf("✅ Retention policy saved: %s (raw: %s)", saved.Id, saved.RawRetention)
+	return saved, nil
+}
+
+// DeleteRetentionPolicy deletes a retention policy.
+func (s *TelemetryServer) DeleteRetentionPolicy(ctx context.Context, req *pb.DeleteRetentionPolicyRequest) (*pb.DeleteRetentionPolicyResponse, error) {
+	log.Printf("📥 DeleteRetentionPolicy: id=%s", req.Id)
+
+	if !isUUID(req.Id) {
+		return nil, status.Error(codes.InvalidArgument, "invalid policy id")
+	}
+
+	err := s.storage.DeleteRetentionPolicy(ctx, req.Id)
+	switch {
+	case errors.Is(err, storage.ErrRetentionPolicyNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, storage.ErrDefaultRetentionPolicy):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case err != nil:
+		return nil, err
+	}
+
+	log.Printf("✅ Retention policy deleted: %s", req.Id)
+	return &pb.DeleteRetentionPolicyResponse{Success: true}, nil
+}
+
+// ApplyRetention enforces retention policies immediately, or reports what
+// each policy would remove when dry_run is set.
+func (s *TelemetryServer) ApplyRetention(ctx context.Context, req *pb.ApplyRetentionRequest) (*pb.ApplyRetentionResponse, error) {
+	log.Printf("📥 ApplyRetention: dryRun=%v", req.DryRun)
+
+	results, err := s.storage.ApplyRetention(ctx, req.DryRun)
+	if errors.Is(err, storage.ErrRetentionInProgress) {
+		return nil, status.Error(codes.Aborted, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Retention evaluated for %d policies", len(results))
+	return &pb.ApplyRetentionResponse{Results: results, DryRun: req.DryRun}, nil
+}
+
+// validateRetentionPolicy checks a policy and returns a copy with durations in canonical form.
+func validateRetentionPolicy(policy *pb.RetentionPolicy) (*pb.RetentionPolicy, error) {
+	if policy == nil {
+		return nil, fmt.Errorf("policy required")
+	}
+	if len(policy.DeviceType) > 100 || len(policy.MetricName) > maxMetricNameLength {
+		return nil, fmt.Errorf("device_type or metric_name too long")
+	}
+
+	raw, err := storage.ParseRetentionDuration(policy.RawRetention)
+	if err != nil {
+		return nil, fmt.Errorf("raw_retention: %w", err)
+	}
+
+	validated := &pb.RetentionPolicy{
+		DeviceType:   policy.DeviceType,
+		MetricName:   policy.MetricName,
+		RawRetention: raw,
+	}
+
+	if policy.DownsampleInterval != "" {
+		if !storage.IsValidInterval(policy.DownsampleInterval) {
+			return nil, fmt.Errorf("downsample_interval: unsupported interval %q", policy.DownsampleInterval)
+		}
+		validated.DownsampleInterval = policy.DownsampleInterval
+	}
+	if policy.DownsampleRetention != "" {
+		if validated.DownsampleInterval == "" {
+			return nil, fmt.Errorf("downsample_retention requires downsample_interval")
+		}
+		if validated.DownsampleRetention, err = storage.ParseRetentionDuration(policy.DownsampleRetention); err != nil {
+			return nil, fmt.Errorf("downsample_retention: %w", err)
+		}
+	}
+
+	return validated, nil
+}
+
+// GetAnomalies retrieves anomalies detected at ingest time.
+func (s *TelemetryServer) GetAnomalies(ctx context.Context, req *pb.GetAnomaliesRequest) (*pb.GetAnomaliesResponse, error) {
+	log.Printf("📥 GetAnomalies: org=%s, device=%s, metric=%s", req.OrgId, req.DeviceId, req.MetricName)
+
+	if req.ToTime < req.FromTime {
+		return nil, status.Error(codes.InvalidArgument, "to_time must be after from_time")
+	}
+	if req.DeviceId != "" && !isUUID(req.DeviceId) {
+		return nil, status.Error(codes.InvalidArgument, "invalid device_id")
+	}
+
+	anomalies, err := s.storage.GetAnomalies(ctx, &storage.AnomalyQuery{
+		OrgID:      req.OrgId,
+		DeviceID:   req.DeviceId,
+		MetricName: req.MetricName,
+		FromTime:   req.FromTime,
+		ToTime:     req.ToTime,
+		MinScore:   req.MinScore,
+		Limit:      int(req.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Found %d anomalies", len(anomalies))
+	return &pb.GetAnomaliesResponse{Anomalies: anomalies}, nil
+}
+
+// ListDerivedMetrics returns derived metric definitions.
+func (s *TelemetryServer) ListDerivedMetrics(ctx context.Context, req *pb.ListDerivedMetricsRequest) (*pb.ListDerivedMetricsResponse, error) {
+	log.Printf("📥 ListDerivedMetrics: type=%s", req.DeviceType)
+
+	metrics, err := s.storage.ListDerivedMetrics(ctx, req.DeviceType)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Found %d derived metrics", len(metrics))
+	return &pb.ListDerivedMetricsResponse{Metrics: metrics}, nil
+}
+
+// UpsertDerivedMetric creates or replaces a derived metric definition.
+// It applies to points ingested from now on; past telemetry is not recomputed.
+func (s *TelemetryServer) UpsertDerivedMetric(ctx context.Context, req *pb.UpsertDerivedMetricRequest) (*pb.DerivedMetric, error) {
+	log.Printf("📥 UpsertDerivedMetric: type=%s, name=%s", req.Metric.GetDeviceType(), req.Metric.GetName())
+
+	metric, err := validateDerivedMetric(req.Metric)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	saved, err := s.storage.UpsertDerivedMetric(ctx, metric)
+	if err != nil {
+		return nil, err
+	}
+	s.reloadDerivedMetrics(ctx)
+
+	log.Printf("✅ Derived metric saved: %s/%s", saved.DeviceType, saved.Name)
+	return saved, nil
+}
+
+// DeleteDerivedMetric deletes a derived metric definition.
+func (s *TelemetryServer) DeleteDerivedMetric(ctx context.Context, req *pb.DeleteDerivedMetricRequest) (*pb.DeleteDerivedMetricResponse, error) {
+	log.Printf("📥 DeleteDerivedMetric: id=%s", req.Id)
+
+	if !isUUID(req.Id) {
+		return nil, status.Error(codes.InvalidArgument, "invalid derived metric id")
+	}
+
+	err := s.storage.DeleteDerivedMetric(ctx, req.Id)
+	if errors.Is(err, storage.ErrDerivedMetricNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.reloadDerivedMetrics(ctx)
+
+	log.Printf("✅ Derived metric deleted: %s", req.Id)
+	return &pb.DeleteDerivedMetricResponse{Success: true}, nil
+}
+
+// reloadDerivedMetrics applies definition changes immediately on this instance.
+// Other replicas pick them up on their next periodic reload.
+func (s *TelemetryServer) reloadDerivedMetrics(ctx context.Context) {
+	if s.derived == nil {
+		return
+	}
+	if err := s.derived.Load(ctx); err != nil {
+		log.Printf("⚠️  Failed to reload derived metrics: %v", err)
+	}
+}
+
+// validateDerivedMetric checks a definition and returns a copy holding only
+// the fields used by its kind.
+func validateDerivedMetric(metric *pb.DerivedMetric) (*pb.DerivedMetric, error) {
+	if metric == nil {
+		return nil, fmt.Errorf("metric required")
+	}
+	if metric.DeviceType == "" || len(metric.DeviceType) > 100 {
+		return nil, fmt.Errorf("device_type required (max 100 characters)")
+	}
+	if metric.Name == "" || len(metric.Name) > maxMetricNameLength {
+		return nil, fmt.Errorf("name required (max %d characters)", maxMetricNameLength)
+	}
+	if len(metric.Unit) > maxUnitLength {
+		return nil, fmt.Errorf("unit too long: maximum is %d characters", maxUnitLength)
+	}
+
+	validated := &pb.DerivedMetric{
+		DeviceType: metric.DeviceType,
+		Name:       metric.Name,
+		Kind:       metric.Kind,
+		Unit:       metric.Unit,
+	}
+	switch metric.Kind {
+	case pb.DerivedMetricKind_DERIVED_METRIC_EXPRESSION:
+		validated.Expression = strings.TrimSpace(metric.Expression)
+	case pb.DerivedMetricKind_DERIVED_METRIC_MOVING_AVERAGE:
+		validated.SourceMetric = metric.SourceMetric
+		validated.Window = metric.Window
+	default:
+		validated.SourceMetric = metric.SourceMetric
+	}
+
+	if err := derived.Validate(validated); err != nil {
+		return nil, err
+	}
+	return validated, nil
 }
 
 // main initializes and starts the Telemetry Collector service.
@@ -91,17 +883,37 @@ func (s *TelemetryServer) GetDeviceMetrics(ctx context.Context, req *pb.GetDevic
 //   - TELEMETRY_GRPC_PORT: gRPC server port (default: 8083)
 //   - MQTT_BROKER: MQTT broker URL (default: tcp://localhost:1883)
 //   - MQTT_CLIENT_ID: MQTT client ID (default: data-collector)
-//   - MQTT_TOPIC: MQTT topic pattern (default: devices/+/telemetry)
+//   - MQTT_TOPIC: comma-separated MQTT topic patterns (default: orgs/+/devices/+/telemetry,devices/+/telemetry)
 //   - DB_HOST: PostgreSQL host (default: localhost)
 //   - DB_PORT: PostgreSQL port (default: 5432)
 //   - DB_NAME: Database name (default: iot_platform)
 //   - DB_USER: Database user (default: iot_user)
 //   - DB_PASSWORD: Database password (default: iot_password)
 //   - DB_SSLMODE: SSL mode (default: disable)
+//   - EVENT_BUS: Event bus transport: redis, nats or memory (default: redis)
+//   - EVENT_BUS_MAXLEN: Approximate number of events kept per stream (default: 100000)
 //   - REDIS_HOST: Redis host (default: localhost)
 //   - REDIS_PORT: Redis port (default: 6379)
 //   - REDIS_PASSWORD: Redis password (default: "")
 //   - REDIS_DB: Redis database (default: 0)
+//   - REDIS_TRANSPORT: Redis fan-out: pubsub, streams or both (default: both)
+//   - NATS_URL: NATS server URL when EVENT_BUS=nats (default: nats://localhost:4222)
+//   - NATS_STREAM: JetStream stream name (default: IOT)
+//   - TELEMETRY_CONFLICT_POLICY: Duplicate point handling: ignore, overwrite or keep-max (default: ignore)
+//   - METRICS_PORT: Prometheus /metrics HTTP port (default: 9083)
+//   - RETENTION_INTERVAL: How often retention policies are enforced, 0 to disable (default: 1h)
+//   - DERIVED_MAX_GAP: Max age of inputs combined in derived metrics and max gap bridged by integrals (default: 10m)
+//   - DERIVED_RELOAD_INTERVAL: How often derived metric definitions are reloaded (default: 1m)
+//   - ANOMALY_METHOD: Anomaly detection method: ewma, seasonal or off (default: ewma)
+//   - ANOMALY_ALPHA: EWMA smoothing factor (default: 0.05)
+//   - ANOMALY_THRESHOLD: |z-score| from which a point is anomalous (default: 4)
+//   - ANOMALY_WARMUP: Points observed before a series is scored (default: 30)
+//   - ANOMALY_SEASONAL_WEEKS: History used for seasonal baselines (default: 4)
+//   - ANOMALY_METRICS: Comma-separated metrics to score (default: all)
+//   - ANOMALY_FLUSH_INTERVAL: How often detector state is persisted (default: 30s)
+//   - METRIC_VALIDATION: Points not matching the device type catalog: flag, reject or off (default: flag)
+//   - CATALOG_RELOAD_INTERVAL: How often the metric catalog is reloaded (default: 1m)
+//   - UNIT_NORMALIZATION: Convert values to the canonical unit of their metric: on or off (default: on)
 func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -119,43 +931,131 @@ func main() {
 		getEnv("DB_SSLMODE", "disable"),
 	)
 
+	conflictPolicy, err := storage.ParseConflictPolicy(getEnv("TELEMETRY_CONFLICT_POLICY", string(storage.ConflictIgnore)))
+	if err != nil {
+		log.Fatalf("❌ Invalid configuration: %v", err)
+	}
+
 	// Initialize storage
-	store, err := storage.NewTimescaleStorage(ctx, dsn)
+	store, err := storage.NewTimescaleStorage(ctx, dsn, conflictPolicy)
 	if err != nil {
 		log.Fatalf("❌ Failed to connect to TimescaleDB: %v", err)
 	}
 	defer store.Close()
 	log.Printf("✅ Connected to TimescaleDB")
 
-	// Initialize Redis publisher
-	redisPublisher, err := publisher.NewRedisPublisher(ctx, publisher.Config{
-		Host:     getEnv("REDIS_HOST", "localhost"),
-		Port:     getEnvInt("REDIS_PORT", 6379),
-		Password: getEnv("REDIS_PASSWORD", ""),
-		DB:       getEnvInt("REDIS_DB", 0),
+	// Initialize event bus publisher
+	busConfig := eventbus.ConfigFromEnv(eventbus.Config{
+		Transport: eventbus.TransportRedis,
+		Redis: eventbus.RedisConfig{
+			Host:   "localhost",
+			Port:   6379,
+			Mode:   eventbus.RedisBoth,
+			MaxLen: 100000,
+		},
+		NATS: eventbus.NATSConfig{URL: "nats://localhost:4222"},
 	})
+	bus, err := eventbus.New(ctx, busConfig)
 	if err != nil {
-		log.Fatalf("❌ Failed to connect to Redis: %v", err)
+		log.Fatalf("❌ Failed to connect to event bus: %v", err)
 	}
-	defer redisPublisher.Close()
+	eventPublisher := publisher.NewPublisher(bus)
+	defer eventPublisher.Close()
+
+	// Load derived metric definitions
+	derivedMaxGap, err := time.ParseDuration(getEnv("DERIVED_MAX_GAP", "10m"))
+	if err != nil {
+		log.Fatalf("❌ Invalid DERIVED_MAX_GAP: %v", err)
+	}
+	devices := storage.NewDeviceCache(store, deviceTTL)
+	derivedEngine := derived.NewEngine(store, devices, derivedMaxGap)
+	if err := derivedEngine.Load(ctx); err != nil {
+		log.Fatalf("❌ Failed to load derived metrics: %v", err)
+	}
+
+	// Load the metric catalog declared in the device type registry
+	validationMode, err := catalog.ParseMode(getEnv("METRIC_VALIDATION", catalog.ModeFlag))
+	if err != nil {
+		log.Fatalf("❌ Invalid configuration: %v", err)
+	}
+	unitNormalization := getEnv("UNIT_NORMALIZATION", "on")
+	if unitNormalization != "on" && unitNormalization != "off" {
+		log.Fatalf("❌ Invalid UNIT_NORMALIZATION: %q (on or off)", unitNormalization)
+	}
+	var metricCatalog, normalizer *catalog.Catalog
+	if validationMode != catalog.ModeOff || unitNormalization == "on" {
+		metricCatalog = catalog.New(store, devices)
+		if err := metricCatalog.Load(ctx); err != nil {
+			log.Fatalf("❌ Failed to load metric catalog: %v", err)
+		}
+	}
+	if unitNormalization == "on" {
+		normalizer = metricCatalog
+	}
+
+	// Restore anomaly detector state
+	var detector *anomaly.Detector
+	anomalyMethod := getEnv("ANOMALY_METHOD", anomaly.MethodEWMA)
+	if anomalyMethod != "off" {
+		anomalyConfig := anomaly.Config{
+			Method:        anomalyMethod,
+			Alpha:         getEnvFloat("ANOMALY_ALPHA", 0.05),
+			Threshold:     getEnvFloat("ANOMALY_THRESHOLD", 4),
+			Warmup:        int64(getEnvInt("ANOMALY_WARMUP", 30)),
+			SeasonalWeeks: getEnvInt("ANOMALY_SEASONAL_WEEKS", 4),
+			Metrics:       splitList(getEnv("ANOMALY_METRICS", "")),
+		}
+		if err := anomalyConfig.Validate(); err != nil {
+			log.Fatalf("❌ Invalid anomaly detection configuration: %v", err)
+		}
+		detector = anomaly.NewDetector(store, anomalyConfig)
+		if err := detector.Load(ctx); err != nil {
+			log.Fatalf("❌ Failed to load anomaly detector state: %v", err)
+		}
+	}
+
+	// Fan out ingested points to StreamTelemetry clients
+	hub := publisher.NewHub()
 
 	// Initialize MQTT client
 	mqttBroker := getEnv("MQTT_BROKER", "tcp://localhost:1883")
 	mqttClientID := getEnv("MQTT_CLIENT_ID", "data-collector")
-	mqttTopic := getEnv("MQTT_TOPIC", "devices/+/telemetry")
+	mqttTopic := getEnv("MQTT_TOPIC", mqtt.DefaultTopic)
 
 	mqttClient, err := mqtt.NewClient(mqtt.Config{
 		BrokerURL: mqttBroker,
 		ClientID:  mqttClientID,
 		Topic:     mqttTopic,
-		OnMessage: func(deviceID, metricName string, value float64, unit string, timestamp int64, metadata map[string]string) {
-			if err := store.InsertTelemetry(ctx, deviceID, metricName, value, unit, timestamp, metadata); err != nil {
-				log.Printf("❌ Failed to insert telemetry: %v", err)
-				return
+		OnMessage: func(orgID, deviceID string, timestamp int64, metrics []mqtt.Metric) {
+			// A device may only publish under its own organization's topics
+			if orgID != "" {
+				deviceOrg, err := devices.Org(ctx, deviceID)
+				if err != nil {
+					log.Printf("❌ Failed to look up device %s: %v", deviceID, err)
+					return
+				}
+				if deviceOrg != orgID {
+					log.Printf("⚠️  Dropping telemetry of device %s published under organization %s", deviceID, orgID)
+					return
+				}
 			}
-			// Publish to Redis after successful DB insert
-			if err := redisPublisher.PublishTelemetry(ctx, deviceID, metricName, value, unit, timestamp); err != nil {
-				log.Printf("⚠️ Failed to publish to Redis: %v", err)
+			ingested := make([]derived.Sample, 0, len(metrics))
+			for _, metric := range metrics {
+				metric = normalize(ctx, normalizer, deviceID, metric)
+				metadata, rejected := validate(ctx, metricCatalog, validationMode, deviceID, metric)
+				if rejected != nil {
+					continue
+				}
+				if ingest(ctx, store, eventPublisher, hub, deviceID, metric.Name, metric.Value, metric.Unit, timestamp, metadata) {
+					ingested = append(ingested, derived.Sample{Name: metric.Name, Value: metric.Value, Unit: metric.Unit})
+					detectAnomaly(ctx, detector, store, eventPublisher, deviceID, metric.Name, metric.Value, timestamp)
+				}
+			}
+			// Derived metrics are stored and published like native ones
+			for _, point := range derivedEngine.Evaluate(ctx, deviceID, timestamp, ingested) {
+				if ingest(ctx, store, eventPublisher, hub, deviceID, point.Name, point.Value, point.Unit, timestamp, derivedMetadata) {
+					detectAnomaly(ctx, detector, store, eventPublisher, deviceID, point.Name, point.Value, timestamp)
+				}
 			}
 		},
 	})
@@ -180,8 +1080,53 @@ func main() {
 		log.Fatalf("❌ Failed to create listener: %v", err)
 	}
 
+	// Enforce retention policies periodically
+	retentionInterval, err := time.ParseDuration(getEnv("RETENTION_INTERVAL", "1h"))
+	if err != nil {
+		log.Fatalf("❌ Invalid RETENTION_INTERVAL: %v", err)
+	}
+	if retentionInterval > 0 {
+		go retention.Run(ctx, store, retentionInterval)
+	}
+
+	// Pick up derived metric changes made through other replicas
+	derivedReloadInterval, err := time.ParseDuration(getEnv("DERIVED_RELOAD_INTERVAL", "1m"))
+	if err != nil || derivedReloadInterval <= 0 {
+		log.Fatalf("❌ Invalid DERIVED_RELOAD_INTERVAL: %q", getEnv("DERIVED_RELOAD_INTERVAL", "1m"))
+	}
+	go derivedEngine.Run(ctx, derivedReloadInterval)
+
+	// Pick up catalog changes made in the device-manager
+	catalogReloadInterval, err := time.ParseDuration(getEnv("CATALOG_RELOAD_INTERVAL", "1m"))
+	if err != nil || catalogReloadInterval <= 0 {
+		log.Fatalf("❌ Invalid CATALOG_RELOAD_INTERVAL: %q", getEnv("CATALOG_RELOAD_INTERVAL", "1m"))
+	}
+	if metricCatalog != nil {
+		go metricCatalog.Run(ctx, catalogReloadInterval)
+	}
+
+	// Persist anomaly detector state periodically
+	anomalyFlushInterval, err := time.ParseDuration(getEnv("ANOMALY_FLUSH_INTERVAL", "30s"))
+	if err != nil || anomalyFlushInterval <= 0 {
+		log.Fatalf("❌ Invalid ANOMALY_FLUSH_INTERVAL: %q", getEnv("ANOMALY_FLUSH_INTERVAL", "30s"))
+	}
+	if detector != nil {
+		go detector.Run(ctx, anomalyFlushInterval)
+	}
+
+	// Expose Prometheus metrics
+	metricsPort := getEnvInt("METRICS_PORT", 9083)
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle("/metrics", promhttp.Handler())
+	metricsServer := &http.Server{Addr: fmt.Sprintf(":%d", metricsPort), Handler: metricsMux}
+	go func() {
+		if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("❌ Metrics server error: %v", err)
+		}
+	}()
+
 	grpcServer := grpc.NewServer()
-	telemetryServer := NewTelemetryServer(store)
+	telemetryServer := NewTelemetryServer(store, derivedEngine, normalizer, metricCatalog, validationMode, hub, devices)
 	pb.RegisterTelemetryServiceServer(grpcServer, telemetryServer)
 
 	// Graceful shutdown
@@ -192,8 +1137,14 @@ func main() {
 		<-sigChan
 		log.Println("⏳ Shutting down gracefully...")
 		grpcServer.GracefulStop()
+		metricsServer.Close()
 		mqttClient.Disconnect()
-		redisPublisher.Close()
+		if detector != nil {
+			if err := detector.Flush(context.Background()); err != nil {
+				log.Printf("⚠️  Failed to save anomaly detector state: %v", err)
+			}
+		}
+		eventPublisher.Close()
 		store.Close()
 		cancel()
 	}()
@@ -204,8 +1155,14 @@ func main() {
 	log.Printf("gRPC Port: %d", grpcPort)
 	log.Printf("MQTT Broker: %s", mqttBroker)
 	log.Printf("MQTT Topic: %s", mqttTopic)
-	log.Printf("Database: TimescaleDB")
-	log.Printf("Redis: %s:%d", getEnv("REDIS_HOST", "localhost"), getEnvInt("REDIS_PORT", 6379))
+	log.Printf("Database: TimescaleDB (conflict policy: %s)", conflictPolicy)
+	log.Printf("Metrics: http://localhost:%d/metrics", metricsPort)
+	log.Printf("Retention job: every %s", retentionInterval)
+	log.Printf("Derived metrics: reloaded every %s (max gap %s)", derivedReloadInterval, derivedMaxGap)
+	log.Printf("Metric validation: %s", validationMode)
+	log.Printf("Unit normalization: %s", unitNormalization)
+	log.Printf("Anomaly detection: %s", anomalyMethod)
+	log.Printf("Event bus: %s", busConfig.Transport)
 	log.Println("-------------------------------------")
 	log.Printf("✅ Server started")
 	log.Printf("⏳ Waiting for telemetry data...")
@@ -216,6 +1173,135 @@ func main() {
 	}
 }
 
+// derivedMetadata marks points computed by the data-collector.
+var derivedMetadata = map[string]string{"derived": "true"}
+
+// deviceTTL is how long the type and organization of a device are cached.
+const deviceTTL = 5 * time.Minute
+
+// normalize converts a point to the canonical unit of its metric. The unit and
+// value as sent by the device are kept in "original_unit" and "original_value"
+// metadata entries. Points are returned as is when normalizer is nil.
+func normalize(ctx context.Context, normalizer *catalog.Catalog, deviceID string, metric mqtt.Metric) mqtt.Metric {
+	if normalizer == nil {
+		return metric
+	}
+	value, unit := normalizer.Normalize(ctx, deviceID, metric.Name, metric.Value, metric.Unit)
+	if unit == metric.Unit {
+		return metric
+	}
+
+	metadata := make(map[string]string, len(metric.Metadata)+2)
+	for k, v := range metric.Metadata {
+		metadata[k] = v
+	}
+	metadata["original_unit"] = metric.Unit
+	metadata["original_value"] = strconv.FormatFloat(metric.Value, 'g', -1, 64)
+	return mqtt.Metric{Name: metric.Name, Value: value, Unit: unit, Metadata: metadata}
+}
+
+// validate checks a point against the metric catalog and returns the metadata
+// to store it with. Invalid points are flagged with a "validation" metadata
+// entry, or rejected (the violation is returned) in reject mode.
+func validate(ctx context.Context, validator *catalog.Catalog, mode, deviceID string, metric mqtt.Metric) (metadata map[string]string, rejected *catalog.Violation) {
+	if validator == nil || mode == catalog.ModeOff {
+		return metric.Metadata, nil
+	}
+	violation := validator.Check(ctx, deviceID, metric.Name, metric.Value, metric.Unit)
+	if violation == nil {
+		return metric.Metadata, nil
+	}
+
+	metrics.ValidationFailures.WithLabelValues(violation.Reason).Inc()
+	if mode == catalog.ModeReject {
+		log.Printf("⚠️  Rejected %s/%s: %s", deviceID, metric.Name, violation.Detail)
+		return nil, violation
+	}
+
+	metadata = make(map[string]string, len(metric.Metadata)+1)
+	for k, v := range metric.Metadata {
+		metadata[k] = v
+	}
+	metadata["validation"] = violation.Reason
+	return metadata, nil
+}
+
+// ingest stores one point and publishes it on the event bus and to live streams. It
+// returns true if the point is new data, false if it failed or was an ignored duplicate.
+func ingest(ctx context.Context, store storage.Storage, eventPublisher *publisher.Publisher, hub *publisher.Hub, deviceID, metricName string, value float64, unit string, timestamp int64, metadata map[string]string) bool {
+	outcome, err := store.InsertTelemetry(ctx, deviceID, metricName, value, unit, timestamp, metadata)
+	recordInsertOutcome(outcome, err)
+	if err != nil {
+		log.Printf("❌ Failed to insert telemetry: %v", err)
+		return false
+	}
+	// A redelivered point that changed nothing was already published
+	if outcome == storage.OutcomeIgnored {
+		return false
+	}
+	// Publish on the event bus after successful DB insert
+	if err := eventPublisher.PublishTelemetry(ctx, deviceID, metricName, value, unit, timestamp); err != nil {
+		log.Printf("⚠️ Failed to publish telemetry event: %v", err)
+	}
+	hub.Publish(&pb.TelemetryRecord{
+		DeviceId:   deviceID,
+		MetricName: metricName,
+		Time:       timestamp,
+		Value:      value,
+		Unit:       unit,
+	})
+	return true
+}
+
+// detectAnomaly scores an ingested point and stores and publishes it if it is
+// anomalous. detector is nil when anomaly detection is disabled.
+func detectAnomaly(ctx context.Context, detector *anomaly.Detector, store storage.Storage, eventPublisher *publisher.Publisher, deviceID, metricName string, value float64, timestamp int64) {
+	if detector == nil {
+		return
+	}
+	found := detector.Observe(ctx, deviceID, metricName, value, timestamp)
+	if found == nil {
+		return
+	}
+
+	log.Printf("🚨 Anomaly: device=%s, %s=%v (expected %.2f, score %.1f, %s)",
+		deviceID, metricName, value, found.Expected, found.Score, found.Method)
+	metrics.AnomaliesDetected.WithLabelValues(found.Method).Inc()
+
+	if err := store.InsertAnomaly(ctx, found); err != nil {
+		log.Printf("❌ Failed to insert anomaly: %v", err)
+	}
+	event := eventbus.AnomalyEvent{
+		DeviceID:   found.DeviceId,
+		MetricName: found.MetricName,
+		Value:      found.Value,
+		Expected:   found.Expected,
+		Score:      found.Score,
+		Method:     found.Method,
+		Timestamp:  time.Unix(found.Time, 0).UTC().Format(time.RFC3339),
+	}
+	if err := eventPublisher.PublishAnomaly(ctx, event); err != nil {
+		log.Printf("⚠️ Failed to publish anomaly event: %v", err)
+	}
+}
+
+// recordInsertOutcome updates the ingestion metrics for one inserted point.
+// Duplicates are expected with MQTT QoS 1 and are reported here, not as errors.
+func recordInsertOutcome(outcome storage.InsertOutcome, err error) {
+	if err != nil {
+		metrics.InsertFailures.Inc()
+		return
+	}
+	switch outcome {
+	case storage.OutcomeInserted:
+		metrics.PointsInserted.Inc()
+	case storage.OutcomeUpdated:
+		metrics.Duplicates.WithLabelValues("updated").Inc()
+	case storage.OutcomeIgnored:
+		metrics.Duplicates.WithLabelValues("ignored").Inc()
+	}
+}
+
 // getEnv retrieves an environment variable or returns a default value.
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -224,6 +1310,27 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvFloat retrieves an environment variable as a float or returns a default value.
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // getEnvInt retrieves an environment variable as an integer or returns a default value.
 func getEnvInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
@@ -0,0 +1,381 @@
+// +build unit
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/yourusername/iot-platform/services/data-collector/catalog"
+	"github.com/yourusername/iot-platform/services/data-collector/mqtt"
+	"github.com/yourusername/iot-platform/services/data-collector/publisher"
+	"github.com/yourusername/iot-platform/services/data-collector/storage"
+	"github.com/yourusername/iot-platform/services/data-collector/units"
+	pb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+)
+
+// fakeStore registers every device as a "thermostat" with a catalog
+// declaring temperature in celsius, and serves points as stored telemetry
+type fakeStore struct {
+	storage.Storage
+	points []*pb.TelemetryPoint
+}
+
+func (s fakeStore) GetTelemetry(ctx context.Context, deviceID, metricName string, fromTime, toTime int64, limit int) ([]*pb.TelemetryPoint, error) {
+	points := make([]*pb.TelemetryPoint, len(s.points))
+	for i, point := range s.points {
+		points[i] = &pb.TelemetryPoint{Time: point.Time, Value: point.Value, Unit: point.Unit}
+	}
+	return points, nil
+}
+
+func (s fakeStore) GetTelemetryAggregated(ctx context.Context, deviceID, metricName string, fromTime, toTime int64, interval, unit string) ([]*pb.TelemetryAggregation, error) {
+	return nil, fmt.Errorf("%w: 2 points cannot be converted", units.ErrIncompatibleUnits)
+}
+
+func (s fakeStore) GetLatestMetric(ctx context.Context, deviceID, metricName string) (*pb.TelemetryPoint, error) {
+	point := s.points[len(s.points)-1]
+	return &pb.TelemetryPoint{Time: point.Time, Value: point.Value, Unit: point.Unit}, nil
+}
+
+func (fakeStore) LoadMetricCatalog(ctx context.Context) (map[string]map[string]*storage.MetricSpec, error) {
+	max := 85.0
+	return map[string]map[string]*storage.MetricSpec{
+		"thermostat": {"temperature": {Name: "temperature", Unit: "celsius", DataType: "float", MaxValue: &max}},
+	}, nil
+}
+
+func (fakeStore) GetDeviceInfo(ctx context.Context, deviceID string) (storage.DeviceInfo, error) {
+	return storage.DeviceInfo{Type: "thermostat", OrgID: "org-1"}, nil
+}
+
+func newTestCatalog(t *testing.T) *catalog.Catalog {
+	t.Helper()
+	store := fakeStore{}
+	c := catalog.New(store, storage.NewDeviceCache(store, time.Minute))
+	if err := c.Load(context.Background()); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	return c
+}
+
+// TestValidate checks how each validation mode handles valid and invalid points
+func TestValidate(t *testing.T) {
+	c := newTestCatalog(t)
+	ctx := context.Background()
+	valid := mqtt.Metric{Name: "temperature", Value: 21, Unit: "celsius", Metadata: map[string]string{"sensor": "a"}}
+	invalid := mqtt.Metric{Name: "temperature", Value: 120, Unit: "celsius", Metadata: map[string]string{"sensor": "a"}}
+
+	tests := []struct {
+		name       string
+		validator  *catalog.Catalog
+		mode       string
+		metric     mqtt.Metric
+		wantOK     bool
+		validation string // expected "validation" metadata entry
+	}{
+		{"flag_valid", c, catalog.ModeFlag, valid, true, ""},
+		{"flag_invalid", c, catalog.ModeFlag, invalid, true, catalog.ReasonOutOfRange},
+		{"reject_valid", c, catalog.ModeReject, valid, true, ""},
+		{"reject_invalid", c, catalog.ModeReject, invalid, false, ""},
+		{"off", c, catalog.ModeOff, invalid, true, ""},
+		{"no_catalog", nil, catalog.ModeReject, invalid, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metadata, rejected := validate(ctx, tt.validator, tt.mode, "dev-1", tt.metric)
+			if (rejected == nil) != tt.wantOK {
+				t.Fatalf("rejected = %+v, want ok %v", rejected, tt.wantOK)
+			}
+			if rejected != nil {
+				if rejected.Reason != catalog.ReasonOutOfRange {
+					t.Errorf("rejected for %s, want %s", rejected.Reason, catalog.ReasonOutOfRange)
+				}
+				return
+			}
+			if metadata["validation"] != tt.validation {
+				t.Errorf("validation = %q, want %q", metadata["validation"], tt.validation)
+			}
+			if metadata["sensor"] != "a" {
+				t.Errorf("metadata of the device lost: %v", metadata)
+			}
+		})
+	}
+
+	// Flagging does not modify the metadata of the message
+	if _, ok := invalid.Metadata["validation"]; ok {
+		t.Error("validate modified the metadata of the point")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	c := newTestCatalog(t)
+	ctx := context.Background()
+
+	metric := normalize(ctx, c, "dev-1", mqtt.Metric{Name: "temperature", Value: 212, Unit: "°F", Metadata: map[string]string{"sensor": "a"}})
+	if metric.Value != 100 || metric.Unit != "celsius" {
+		t.Errorf("expected 100 celsius, got %v %s", metric.Value, metric.Unit)
+	}
+	if metric.Metadata["original_unit"] != "°F" || metric.Metadata["original_value"] != "212" || metric.Metadata["sensor"] != "a" {
+		t.Errorf("unexpected metadata %v", metric.Metadata)
+	}
+
+	// Already canonical, or unknown unit: unchanged, without metadata
+	for _, unit := range []string{"celsius", "RH"} {
+		metric := normalize(ctx, c, "dev-1", mqtt.Metric{Name: "temperature", Value: 21, Unit: unit})
+		if metric.Value != 21 || metric.Unit != unit || metric.Metadata != nil {
+			t.Errorf("%s: expected the point unchanged, got %+v", unit, metric)
+		}
+	}
+
+	// Normalization disabled
+	if metric := normalize(ctx, nil, "dev-1", mqtt.Metric{Name: "temperature", Value: 212, Unit: "°F"}); metric.Unit != "°F" {
+		t.Errorf("expected no normalization without catalog, got %+v", metric)
+	}
+}
+
+// TestGetTelemetry_KeepsUnconvertiblePoints checks that conversion never
+// shrinks a series: points that cannot be converted keep their own unit
+func TestGetTelemetry_KeepsUnconvertiblePoints(t *testing.T) {
+	store := fakeStore{points: []*pb.TelemetryPoint{
+		{Time: 3, Value: 212, Unit: "fahrenheit"},
+		{Time: 2, Value: 50, Unit: ""},
+		{Time: 1, Value: 1013, Unit: "hPa"},
+	}}
+	server := NewTelemetryServer(store, nil, nil, nil, "", nil, storage.NewDeviceCache(store, time.Minute))
+	ctx := context.Background()
+
+	resp, err := server.GetTelemetry(ctx, &pb.GetTelemetryRequest{DeviceId: "dev-1", MetricName: "temperature", Unit: "celsius"})
+	if err != nil {
+		t.Fatalf("GetTelemetry failed: %v", err)
+	}
+	want := []struct {
+		value float64
+		unit  string
+	}{{100, "celsius"}, {50, ""}, {1013, "hPa"}}
+	if len(resp.Points) != len(want) {
+		t.Fatalf("expected %d points, got %d", len(want), len(resp.Points))
+	}
+	for i, point := range resp.Points {
+		if point.Value != want[i].value || point.Unit != want[i].unit {
+			t.Errorf("point %d = %v %s, want %v %s", i, point.Value, point.Unit, want[i].value, want[i].unit)
+		}
+	}
+
+	if _, err := server.GetTelemetry(ctx, &pb.GetTelemetryRequest{DeviceId: "dev-1", Unit: "furlong"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an unknown unit, got %v", err)
+	}
+	if _, err := server.GetTelemetryAggregated(ctx, &pb.GetTelemetryAggregatedRequest{DeviceId: "dev-1", Unit: "celsius"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition for unconvertible points, got %v", err)
+	}
+	if _, err := server.GetLatestMetric(ctx, &pb.GetLatestMetricRequest{DeviceId: "dev-1", Unit: "celsius"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition for an unconvertible latest point, got %v", err)
+	}
+}
+
+// importStore stores the points of an import in memory
+type importStore struct {
+	fakeStore
+	points []*storage.TelemetryPoint
+}
+
+func (s *importStore) ImportTelemetry(ctx context.Context, policy pb.ImportConflictPolicy, next func() ([]*storage.TelemetryPoint, error)) (*storage.ImportResult, error) {
+	for {
+		points, err := next()
+		if err == io.EOF {
+			return &storage.ImportResult{Received: int64(len(s.points)), Written: int64(len(s.points))}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		s.points = append(s.points, points...)
+	}
+}
+
+func (s *importStore) RefreshAggregates(ctx context.Context, fromTime, toTime int64) error {
+	return nil
+}
+
+// importStream sends requests to ImportTelemetry
+type importStream struct {
+	grpc.ServerStream
+	requests []*pb.ImportTelemetryRequest
+	response *pb.ImportTelemetryResponse
+}
+
+func (s *importStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *importStream) Recv() (*pb.ImportTelemetryRequest, error) {
+	if len(s.requests) == 0 {
+		return nil, io.EOF
+	}
+	req := s.requests[0]
+	s.requests = s.requests[1:]
+	return req, nil
+}
+
+func (s *importStream) SendAndClose(resp *pb.ImportTelemetryResponse) error {
+	s.response = resp
+	return nil
+}
+
+// TestImportTelemetry_ValidatesRows checks that imported rows go through the
+// catalog like live telemetry: flagged, or failing the import in reject mode
+func TestImportTelemetry_ValidatesRows(t *testing.T) {
+	const deviceID = "6f1c2a4e-8b3d-4c5e-9f7a-1b2c3d4e5f60"
+	records := []*pb.TelemetryRecord{
+		{DeviceId: deviceID, MetricName: "temperature", Time: 1700000000, Value: 21, Unit: "celsius"},
+		{DeviceId: deviceID, MetricName: "temperature", Time: 1700000060, Value: 120, Unit: "celsius"},
+	}
+	c := newTestCatalog(t)
+
+	tests := []struct {
+		mode       string
+		wantCode   codes.Code
+		validation []string // expected "validation" metadata of each stored point
+	}{
+		{catalog.ModeFlag, codes.OK, []string{"", catalog.ReasonOutOfRange}},
+		{catalog.ModeReject, codes.InvalidArgument, nil},
+		{catalog.ModeOff, codes.OK, []string{"", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			store := &importStore{}
+			server := NewTelemetryServer(store, nil, c, c, tt.mode, nil, storage.NewDeviceCache(store, time.Minute))
+			stream := &importStream{requests: []*pb.ImportTelemetryRequest{{OrgId: "org-1", Records: records}}}
+
+			err := server.ImportTelemetry(stream)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("ImportTelemetry() error = %v, want %v", err, tt.wantCode)
+			}
+			if err != nil {
+				if !strings.Contains(err.Error(), "row 2") {
+					t.Errorf("expected the invalid row in the error, got %v", err)
+				}
+				return
+			}
+			if len(store.points) != len(tt.validation) {
+				t.Fatalf("expected %d stored points, got %d", len(tt.validation), len(store.points))
+			}
+			for i, point := range store.points {
+				if point.Metadata["validation"] != tt.validation[i] {
+					t.Errorf("point %d: validation = %q, want %q", i, point.Metadata["validation"], tt.validation[i])
+				}
+			}
+		})
+	}
+}
+
+// exportStore answers each ExportTelemetry call with the next page of
+// exports, after running the matching hook if any
+type exportStore struct {
+	storage.Storage
+	exports [][]*pb.TelemetryRecord
+	hooks   []func()
+	queries []*storage.ExportQuery
+}
+
+func (s *exportStore) ExportTelemetry(ctx context.Context, q *storage.ExportQuery, pageSize int, fn func(records []*pb.TelemetryRecord) error) error {
+	call := len(s.queries)
+	s.queries = append(s.queries, q)
+	if call < len(s.hooks) && s.hooks[call] != nil {
+		s.hooks[call]()
+	}
+	if call >= len(s.exports) {
+		return nil
+	}
+	return fn(s.exports[call])
+}
+
+// recordingStream collects the events sent, and ends the stream once it
+// has received want of them
+type recordingStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	cancel context.CancelFunc
+	want   int
+	events []*pb.StreamTelemetryEvent
+}
+
+func (s *recordingStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *recordingStream) Send(event *pb.StreamTelemetryEvent) error {
+	s.events = append(s.events, event)
+	if len(s.events) == s.want {
+		s.cancel()
+	}
+	return nil
+}
+
+// TestStreamTelemetry_SwitchToLive checks that a point stored late, with a
+// device time before the end of the first replay, is still sent, and that
+// points are sent once across the replays and the live feed
+func TestStreamTelemetry_SwitchToLive(t *testing.T) {
+	now := time.Now().Unix()
+	point := func(time int64, value float64) *pb.TelemetryRecord {
+		return &pb.TelemetryRecord{DeviceId: "dev-1", MetricName: "temperature", Time: time, Value: value}
+	}
+	old, recent, late, live := point(now-3600, 1), point(now-10, 2), point(now-5, 3), point(now+1, 4)
+
+	hub := publisher.NewHub()
+	store := &exportStore{
+		exports: [][]*pb.TelemetryRecord{
+			{old, recent},
+			// Second replay: the late point was stored after the first one
+			{recent, late},
+		},
+		hooks: []func(){
+			nil,
+			// Ingested while replaying, so also received live
+			func() {
+				hub.Publish(late)
+				hub.Publish(live)
+			},
+		},
+	}
+	server := NewTelemetryServer(store, nil, nil, nil, "", hub, storage.NewDeviceCache(store, time.Minute))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := &recordingStream{ctx: ctx, cancel: cancel, want: 4}
+	if err := server.StreamTelemetry(&pb.StreamTelemetryRequest{DeviceIds: []string{"dev-1"}, FromTime: now - 7200}, stream); err != nil {
+		t.Fatalf("StreamTelemetry failed: %v", err)
+	}
+
+	want := []struct {
+		value  float64
+		replay bool
+	}{{1, true}, {2, true}, {3, true}, {4, false}}
+	if len(stream.events) != len(want) {
+		t.Fatalf("expected %d events, got %d", len(want), len(stream.events))
+	}
+	for i, event := range stream.events {
+		if event.Record.Value != want[i].value || event.Replay != want[i].replay {
+			t.Errorf("event %d = %v (replay %v), want %v (replay %v)", i, event.Record.Value, event.Replay, want[i].value, want[i].replay)
+		}
+	}
+
+	// The second replay overlaps the end of the first one
+	if len(store.queries) != 2 {
+		t.Fatalf("expected 2 replays, got %d", len(store.queries))
+	}
+	first, second := store.queries[0], store.queries[1]
+	if second.FromTime > first.ToTime-int64(streamReplayOverlap/time.Second) || second.ToTime < first.ToTime {
+		t.Errorf("second replay [%d, %d] does not overlap the first one [%d, %d]", second.FromTime, second.ToTime, first.FromTime, first.ToTime)
+	}
+	if hub.Subscribers() != 0 {
+		t.Error("subscription not closed with the stream")
+	}
+}
@@ -0,0 +1,53 @@
+// Package metrics defines the Prometheus metrics exported by the Data Collector.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// PointsInserted counts telemetry points stored as new rows.
+	PointsInserted = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "data_collector",
+		Name:      "telemetry_points_inserted_total",
+		Help:      "Telemetry points stored as new rows.",
+	})
+
+	// Duplicates counts points whose (device_id, metric_name, time) already existed,
+	// labelled by what the conflict policy did with them ("ignored" or "updated").
+	Duplicates = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "data_collector",
+		Name:      "telemetry_duplicates_total",
+		Help:      "Telemetry points that hit an existing row, by resolution.",
+	}, []string{"resolution"})
+
+	// InsertFailures counts points that could not be stored.
+	InsertFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "data_collector",
+		Name:      "telemetry_insert_failures_total",
+		Help:      "Telemetry points rejected by the database.",
+	})
+
+	// AnomaliesDetected counts points scored as anomalous, by detection method.
+	AnomaliesDetected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "data_collector",
+		Name:      "anomalies_detected_total",
+		Help:      "Telemetry points scored as anomalous, by detection method.",
+	}, []string{"method"})
+
+	// ValidationFailures counts points that do not match their device type's
+	// metric catalog, by reason (undeclared, unit_mismatch, invalid_type, out_of_range).
+	ValidationFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "data_collector",
+		Name:      "metric_validation_failures_total",
+		Help:      "Telemetry points not matching the metric catalog, by reason.",
+	}, []string{"reason"})
+
+	// TelemetryStreams tracks open StreamTelemetry calls.
+	TelemetryStreams = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "data_collector",
+		Name:      "telemetry_streams",
+		Help:      "Open StreamTelemetry calls.",
+	})
+)
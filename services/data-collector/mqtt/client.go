@@ -26,14 +26,20 @@ type Metric struct {
 	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
-// MessageHandler is called for each received telemetry point.
-type MessageHandler func(deviceID, metricName string, value float64, unit string, timestamp int64, metadata map[string]string)
+// MessageHandler is called once per received message with all of its metrics,
+// which share the message timestamp. orgID is the organization named by the
+// topic, empty on legacy topics.
+type MessageHandler func(orgID, deviceID string, timestamp int64, metrics []Metric)
+
+// DefaultTopic subscribes to the organization-aware topics and to the legacy
+// ones, which devices provisioned before organizations still publish to.
+const DefaultTopic = "orgs/+/devices/+/telemetry,devices/+/telemetry"
 
 // Config holds MQTT client configuration.
 type Config struct {
 	BrokerURL string
 	ClientID  string
-	Topic     string
+	Topic     string // Comma-separated topic filters
 	Username  string
 	Password  string
 	OnMessage MessageHandler
@@ -54,7 +60,7 @@ func NewClient(config Config) (*Client, error) {
 		config.ClientID = "data-collector"
 	}
 	if config.Topic == "" {
-		config.Topic = "devices/+/telemetry"
+		config.Topic = DefaultTopic
 	}
 	if config.OnMessage == nil {
 		return nil, fmt.Errorf("message handler is required")
@@ -111,13 +117,19 @@ func (c *Client) Connect() error {
 	return nil
 }
 
-// Subscribe subscribes to the telemetry topic.
+// Subscribe subscribes to the telemetry topics.
 func (c *Client) Subscribe() error {
 	return c.subscribe()
 }
 
 func (c *Client) subscribe() error {
-	token := c.pahoClient.Subscribe(c.config.Topic, 1, c.handleMessage)
+	filters := make(map[string]byte)
+	for _, topic := range strings.Split(c.config.Topic, ",") {
+		if topic = strings.TrimSpace(topic); topic != "" {
+			filters[topic] = 1
+		}
+	}
+	token := c.pahoClient.SubscribeMultiple(filters, c.handleMessage)
 	if token.Wait() && token.Error() != nil {
 		return fmt.Errorf("failed to subscribe: %w", token.Error())
 	}
@@ -131,8 +143,8 @@ func (c *Client) handleMessage(client pahomqtt.Client, msg pahomqtt.Message) {
 
 	log.Printf("📨 Received message on topic: %s", topic)
 
-	// Extract device ID from topic (devices/{device_id}/telemetry)
-	deviceID := extractDeviceID(topic)
+	// Extract organization and device ID from topic
+	orgID, deviceID := parseTopic(topic)
 	if deviceID == "" {
 		log.Printf("⚠️  Could not extract device ID from topic: %s", topic)
 		return
@@ -164,28 +176,26 @@ func (c *Client) handleMessage(client pahomqtt.Client, msg pahomqtt.Message) {
 		timestamp = time.Now().Unix()
 	}
 
-	// Process each metric
+	// Process all metrics together so that metrics computed from several of
+	// them (derived metrics) see a consistent message
+	c.config.OnMessage(orgID, telemetry.DeviceID, timestamp, telemetry.Metrics)
 	for _, metric := range telemetry.Metrics {
-		c.config.OnMessage(
-			telemetry.DeviceID,
-			metric.Name,
-			metric.Value,
-			metric.Unit,
-			timestamp,
-			metric.Metadata,
-		)
 		log.Printf("📊 Metric: device=%s, %s=%v %s", telemetry.DeviceID, metric.Name, metric.Value, metric.Unit)
 	}
 }
 
-// extractDeviceID extracts the device ID from the MQTT topic.
-// Expected format: devices/{device_id}/telemetry
-func extractDeviceID(topic string) string {
+// parseTopic extracts the organization and device ID from the MQTT topic.
+// Expected formats: orgs/{org_id}/devices/{device_id}/telemetry, or the legacy
+// devices/{device_id}/telemetry which carries no organization.
+func parseTopic(topic string) (orgID, deviceID string) {
 	parts := strings.Split(topic, "/")
+	if len(parts) >= 5 && parts[0] == "orgs" && parts[2] == "devices" && parts[4] == "telemetry" {
+		return parts[1], parts[3]
+	}
 	if len(parts) >= 3 && parts[0] == "devices" && parts[2] == "telemetry" {
-		return parts[1]
+		return "", parts[1]
 	}
-	return ""
+	return "", ""
 }
 
 // Disconnect gracefully disconnects from the MQTT broker.
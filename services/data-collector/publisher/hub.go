@@ -0,0 +1,117 @@
+package publisher
+
+import (
+	"sync"
+	"sync/atomic"
+
+	pb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+)
+
+// Hub fans out ingested telemetry to in-process subscribers, such as
+// StreamTelemetry clients. Publishing never blocks ingestion: a subscriber
+// whose buffer is full is dropped and its channel closed.
+type Hub struct {
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+}
+
+// Subscription receives the points matching its filters on C until it is
+// closed, either by Close or because it fell behind.
+type Subscription struct {
+	C <-chan *pb.TelemetryRecord
+
+	ch         chan *pb.TelemetryRecord
+	devices    map[string]bool
+	metrics    map[string]bool
+	hub        *Hub
+	overflowed atomic.Bool
+}
+
+// NewHub creates an empty hub.
+func NewHub() *Hub {
+	return &Hub{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscribe registers a subscriber with room for buffer pending points.
+// Empty deviceIDs or metricNames match every device or metric.
+func (h *Hub) Subscribe(deviceIDs, metricNames []string, buffer int) *Subscription {
+	ch := make(chan *pb.TelemetryRecord, buffer)
+	sub := &Subscription{
+		C:       ch,
+		ch:      ch,
+		devices: toSet(deviceIDs),
+		metrics: toSet(metricNames),
+		hub:     h,
+	}
+
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+// Publish delivers a point to every matching subscriber.
+func (h *Hub) Publish(record *pb.TelemetryRecord) {
+	var behind []*Subscription
+
+	h.mu.RLock()
+	for sub := range h.subs {
+		if !sub.matches(record) {
+			continue
+		}
+		select {
+		case sub.ch <- record:
+		default:
+			behind = append(behind, sub)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, sub := range behind {
+		sub.overflowed.Store(true)
+		h.remove(sub)
+	}
+}
+
+// Subscribers returns the number of active subscriptions.
+func (h *Hub) Subscribers() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subs)
+}
+
+// remove unregisters a subscription and closes its channel, once.
+func (h *Hub) remove(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// Close unregisters the subscription.
+func (s *Subscription) Close() {
+	s.hub.remove(s)
+}
+
+// Overflowed reports whether the subscription was dropped for falling behind.
+func (s *Subscription) Overflowed() bool {
+	return s.overflowed.Load()
+}
+
+// matches reports whether a point passes the subscription's filters.
+func (s *Subscription) matches(record *pb.TelemetryRecord) bool {
+	return (len(s.devices) == 0 || s.devices[record.DeviceId]) && (len(s.metrics) == 0 || s.metrics[record.MetricName])
+}
+
+func toSet(values []string) map[string]bool {
+	if len(values) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
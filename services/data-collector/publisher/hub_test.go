@@ -0,0 +1,109 @@
+// +build unit
+
+package publisher
+
+import (
+	"testing"
+
+	pb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+)
+
+func record(deviceID, metricName string, value float64) *pb.TelemetryRecord {
+	return &pb.TelemetryRecord{DeviceId: deviceID, MetricName: metricName, Value: value}
+}
+
+// received drains the points pending on a subscription
+func received(sub *Subscription) []*pb.TelemetryRecord {
+	var records []*pb.TelemetryRecord
+	for {
+		select {
+		case r, ok := <-sub.C:
+			if !ok {
+				return records
+			}
+			records = append(records, r)
+		default:
+			return records
+		}
+	}
+}
+
+func TestHub_Filters(t *testing.T) {
+	hub := NewHub()
+	all := hub.Subscribe(nil, nil, 10)
+	device := hub.Subscribe([]string{"dev-1"}, nil, 10)
+	metric := hub.Subscribe(nil, []string{"temperature"}, 10)
+	both := hub.Subscribe([]string{"dev-1", "dev-2"}, []string{"humidity"}, 10)
+
+	hub.Publish(record("dev-1", "temperature", 1))
+	hub.Publish(record("dev-2", "humidity", 2))
+	hub.Publish(record("dev-3", "temperature", 3))
+
+	for _, tt := range []struct {
+		name string
+		sub  *Subscription
+		want []float64
+	}{
+		{"all", all, []float64{1, 2, 3}},
+		{"device", device, []float64{1}},
+		{"metric", metric, []float64{1, 3}},
+		{"device_and_metric", both, []float64{2}},
+	} {
+		records := received(tt.sub)
+		if len(records) != len(tt.want) {
+			t.Errorf("%s: received %d points, want %d", tt.name, len(records), len(tt.want))
+			continue
+		}
+		for i, r := range records {
+			if r.Value != tt.want[i] {
+				t.Errorf("%s: point %d = %v, want %v", tt.name, i, r.Value, tt.want[i])
+			}
+		}
+	}
+}
+
+// TestHub_DropsSlowSubscribers checks that a full buffer never blocks
+// publishing: the subscriber is dropped instead
+func TestHub_DropsSlowSubscribers(t *testing.T) {
+	hub := NewHub()
+	slow := hub.Subscribe(nil, nil, 2)
+	fast := hub.Subscribe(nil, nil, 10)
+
+	for i := 0; i < 3; i++ {
+		hub.Publish(record("dev-1", "temperature", float64(i)))
+	}
+
+	if !slow.Overflowed() {
+		t.Error("expected the slow subscriber to overflow")
+	}
+	if records := received(slow); len(records) != 2 {
+		t.Errorf("expected the buffered points then a closed channel, got %d points", len(records))
+	}
+	if _, ok := <-slow.C; ok {
+		t.Error("expected the channel of the slow subscriber to be closed")
+	}
+	if fast.Overflowed() || len(received(fast)) != 3 {
+		t.Error("the other subscriber must receive every point")
+	}
+	if n := hub.Subscribers(); n != 1 {
+		t.Errorf("Subscribers() = %d, want 1", n)
+	}
+}
+
+func TestSubscription_Close(t *testing.T) {
+	hub := NewHub()
+	sub := hub.Subscribe(nil, nil, 1)
+	sub.Close()
+	sub.Close() // idempotent
+
+	if _, ok := <-sub.C; ok {
+		t.Error("expected a closed channel")
+	}
+	if sub.Overflowed() {
+		t.Error("a closed subscription did not overflow")
+	}
+	hub.Publish(record("dev-1", "temperature", 1))
+	if n := hub.Subscribers(); n != 0 {
+		t.Errorf("Subscribers() = %d, want 0", n)
+	}
+}
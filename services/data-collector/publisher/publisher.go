@@ -0,0 +1,53 @@
+package publisher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/yourusername/iot-platform/shared/eventbus"
+)
+
+// Publisher publishes telemetry and anomaly events on the event bus
+type Publisher struct {
+	bus eventbus.Bus
+}
+
+// NewPublisher creates a publisher over bus
+func NewPublisher(bus eventbus.Bus) *Publisher {
+	return &Publisher{bus: bus}
+}
+
+// PublishTelemetry publishes a telemetry event on telemetry.<device_id>
+func (p *Publisher) PublishTelemetry(ctx context.Context, deviceID, metricName string, value float64, unit string, timestamp int64) error {
+	event := eventbus.TelemetryEvent{
+		DeviceID:   deviceID,
+		MetricName: metricName,
+		Value:      value,
+		Unit:       unit,
+		Timestamp:  time.Unix(timestamp, 0).UTC().Format(time.RFC3339),
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal telemetry event: %w", err)
+	}
+
+	return p.bus.Publish(ctx, eventbus.Subject(eventbus.SubjectTelemetry, deviceID), payload)
+}
+
+// PublishAnomaly publishes an anomaly event on anomalies.<device_id>
+func (p *Publisher) PublishAnomaly(ctx context.Context, event eventbus.AnomalyEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal anomaly event: %w", err)
+	}
+
+	return p.bus.Publish(ctx, eventbus.Subject(eventbus.SubjectAnomalies, event.DeviceID), payload)
+}
+
+// Close closes the underlying bus
+func (p *Publisher) Close() error {
+	return p.bus.Close()
+}
@@ -0,0 +1,51 @@
+// Package retention runs the periodic enforcement of telemetry retention policies.
+package retention
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/yourusername/iot-platform/services/data-collector/storage"
+)
+
+// Run applies retention policies every interval until ctx is cancelled.
+// The first run happens one interval after startup, not at boot, so that
+// restarts do not trigger heavy deletes.
+func Run(ctx context.Context, store storage.Storage, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			apply(ctx, store)
+		}
+	}
+}
+
+func apply(ctx context.Context, store storage.Storage) {
+	start := time.Now()
+
+	results, err := store.ApplyRetention(ctx, false)
+	switch {
+	case errors.Is(err, storage.ErrRetentionInProgress):
+		// Another replica runs the job: it will apply the remaining policies
+		log.Printf("🧹 Retention: %d policies applied, the others are being applied by another replica", len(results))
+		return
+	case err != nil:
+		log.Printf("❌ Retention job failed: %v", err)
+	}
+
+	var raw, buckets, rollups int64
+	for _, r := range results {
+		raw += r.RawRowsDeleted
+		buckets += r.BucketsWritten
+		rollups += r.RollupRowsDeleted
+	}
+	log.Printf("🧹 Retention: %d policies applied in %s (%d raw rows deleted, %d buckets written, %d rollups deleted)",
+		len(results), time.Since(start).Round(time.Millisecond), raw, buckets, rollups)
+}
@@ -0,0 +1,109 @@
+// +build unit
+
+package retention
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/iot-platform/services/data-collector/storage"
+	pb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+)
+
+// fakeStore records retention runs
+type fakeStore struct {
+	storage.Storage
+
+	mu     sync.Mutex
+	runs   []time.Time
+	dryRun bool
+	err    error
+}
+
+func (s *fakeStore) ApplyRetention(ctx context.Context, dryRun bool) ([]*pb.RetentionPolicyResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runs = append(s.runs, time.Now())
+	s.dryRun = s.dryRun || dryRun
+	return []*pb.RetentionPolicyResult{{Policy: &pb.RetentionPolicy{Id: "p1"}, RawRowsDeleted: 10}}, s.err
+}
+
+func (s *fakeStore) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.runs)
+}
+
+func TestRun_AppliesEveryInterval(t *testing.T) {
+	store := &fakeStore{}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	start := time.Now()
+	go func() {
+		defer close(done)
+		Run(ctx, store, 50*time.Millisecond)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for store.count() < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if len(store.runs) < 3 {
+		t.Fatalf("expected at least 3 runs, got %d", len(store.runs))
+	}
+	// Not at boot: restarts must not trigger deletes
+	if first := store.runs[0].Sub(start); first < 50*time.Millisecond {
+		t.Errorf("first run %v after start, expected one interval", first)
+	}
+	if store.dryRun {
+		t.Error("scheduled runs must not be dry runs")
+	}
+}
+
+func TestRun_StopsOnCancel(t *testing.T) {
+	store := &fakeStore{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Run(ctx, store, time.Millisecond)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+}
+
+// TestRun_KeepsRunningAfterErrors checks that a failed run, or one stopped
+// by another replica holding the lock, does not end the schedule
+func TestRun_KeepsRunningAfterErrors(t *testing.T) {
+	for _, err := range []error{storage.ErrRetentionInProgress, context.DeadlineExceeded} {
+		store := &fakeStore{err: err}
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			Run(ctx, store, 10*time.Millisecond)
+		}()
+
+		deadline := time.Now().Add(2 * time.Second)
+		for store.count() < 2 && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+		cancel()
+		<-done
+		if store.count() < 2 {
+			t.Errorf("%v: expected runs to continue, got %d", err, store.count())
+		}
+	}
+}
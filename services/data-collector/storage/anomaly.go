@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	pb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+)
+
+// LoadAnomalyStates returns the persisted detector state of every (device, metric).
+func (s *TimescaleStorage) LoadAnomalyStates(ctx context.Context) ([]*AnomalyState, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT device_id::text, metric_name, ewma_mean, ewma_var, sample_count, last_time
+		FROM anomaly_detector_state
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query anomaly detector state: %w", err)
+	}
+	defer rows.Close()
+
+	var states []*AnomalyState
+	for rows.Next() {
+		var lastTime time.Time
+		state := &AnomalyState{}
+		if err := rows.Scan(&state.DeviceID, &state.MetricName, &state.Mean, &state.Variance, &state.Count, &lastTime); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		state.LastTime = lastTime.Unix()
+		states = append(states, state)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return states, nil
+}
+
+// SaveAnomalyStates upserts detector states in one round trip.
+func (s *TimescaleStorage) SaveAnomalyStates(ctx context.Context, states []*AnomalyState) error {
+	if len(states) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, state := range states {
+		batch.Queue(`
+			INSERT INTO anomaly_detector_state
+				(device_id, metric_name, ewma_mean, ewma_var, sample_count, last_time, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, NOW())
+			ON CONFLICT (device_id, metric_name) DO UPDATE SET
+				ewma_mean = EXCLUDED.ewma_mean,
+				ewma_var = EXCLUDED.ewma_var,
+				sample_count = EXCLUDED.sample_count,
+				last_time = EXCLUDED.last_time,
+				updated_at = NOW()
+		`, state.DeviceID, state.MetricName, state.Mean, state.Variance, state.Count, time.Unix(state.LastTime, 0))
+	}
+
+	if err := s.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to save anomaly detector state: %w", err)
+	}
+	return nil
+}
+
+// GetSeasonalBaseline computes the expected value of a metric for each hour of
+// the day (UTC) from the last weeks of telemetry_hourly.
+//
+// The spread combines the variation of hourly averages across days with the
+// spread inside each hour, estimated as (max - min) / 4.
+func (s *TimescaleStorage) GetSeasonalBaseline(ctx context.Context, deviceID, metricName string, weeks int) (map[int]*SeasonalBaseline, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT
+			EXTRACT(HOUR FROM bucket AT TIME ZONE 'UTC')::int AS hour,
+			AVG(avg_value),
+			SQRT(COALESCE(VAR_SAMP(avg_value), 0) + AVG(POWER((max_value - min_value) / 4, 2))),
+			COUNT(*)
+		FROM telemetry_hourly
+		WHERE device_id = $1
+		  AND metric_name = $2
+		  AND bucket >= NOW() - make_interval(weeks => $3)
+		GROUP BY hour
+	`, deviceID, metricName, weeks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query seasonal baseline: %w", err)
+	}
+	defer rows.Close()
+
+	baseline := make(map[int]*SeasonalBaseline)
+	for rows.Next() {
+		var hour int
+		slot := &SeasonalBaseline{}
+		if err := rows.Scan(&hour, &slot.Expected, &slot.StdDev, &slot.Buckets); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		baseline[hour] = slot
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return baseline, nil
+}
+
+// InsertAnomaly stores a detected anomaly. Re-detecting the same point is a no-op.
+func (s *TimescaleStorage) InsertAnomaly(ctx context.Context, anomaly *pb.Anomaly) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO telemetry_anomalies (time, device_id, metric_name, value, expected, score, method)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (device_id, metric_name, time) DO NOTHING
+	`, time.Unix(anomaly.Time, 0), anomaly.DeviceId, anomaly.MetricName,
+		anomaly.Value, anomaly.Expected, anomaly.Score, anomaly.Method)
+	if err != nil {
+		return fmt.Errorf("failed to insert anomaly: %w", err)
+	}
+	return nil
+}
+
+// GetAnomalies returns anomalies matching query, most recent first.
+func (s *TimescaleStorage) GetAnomalies(ctx context.Context, query *AnomalyQuery) ([]*pb.Anomaly, error) {
+	limit := query.Limit
+	if limit <= 0 || limit > 10000 {
+		limit = 1000
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT time, device_id::text, metric_name, value, expected, score, method
+		FROM telemetry_anomalies
+		WHERE time >= $1
+		  AND time <= $2
+		  AND ($3 = '' OR device_id::text = $3)
+		  AND ($4 = '' OR metric_name = $4)
+		  AND ABS(score) >= $5
+		  AND ($7 = '' OR device_id IN (SELECT id FROM devices WHERE org_id::text = $7))
+		ORDER BY time DESC
+		LIMIT $6
+	`, time.Unix(query.FromTime, 0), time.Unix(query.ToTime, 0), query.DeviceID, query.MetricName, query.MinScore, limit, query.OrgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query anomalies: %w", err)
+	}
+	defer rows.Close()
+
+	var anomalies []*pb.Anomaly
+	for rows.Next() {
+		var ts time.Time
+		anomaly := &pb.Anomaly{}
+		if err := rows.Scan(&ts, &anomaly.DeviceId, &anomaly.MetricName, &anomaly.Value,
+			&anomaly.Expected, &anomaly.Score, &anomaly.Method); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		anomaly.Time = ts.Unix()
+		anomalies = append(anomalies, anomaly)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return anomalies, nil
+}
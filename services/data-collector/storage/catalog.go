@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+)
+
+// GetDeviceMetrics returns the catalog of the device's type joined with the
+// latest point of each reported metric. The catalog is maintained by the
+// device-manager in device_type_metrics.
+func (s *TimescaleStorage) GetDeviceMetrics(ctx context.Context, deviceID string) ([]*pb.MetricInfo, error) {
+	rows, err := s.pool.Query(ctx, `
+		WITH declared AS (
+			SELECT m.*
+			FROM devices d
+			JOIN device_type_metrics m ON m.device_type = d.type
+			WHERE d.id = $1
+		),
+		reported AS (
+			SELECT metric_name, unit, time
+			FROM device_telemetry_latest
+			WHERE device_id = $1
+		)
+		SELECT
+			COALESCE(c.name, r.metric_name),
+			COALESCE(c.display_name, r.metric_name),
+			COALESCE(c.unit, r.unit, ''),
+			COALESCE(c.data_type, 'float'),
+			c.min_value,
+			c.max_value,
+			COALESCE(c.reporting_interval_seconds, 0),
+			c.name IS NOT NULL,
+			r.time
+		FROM declared c
+		FULL OUTER JOIN reported r ON r.metric_name = c.name
+		ORDER BY 1
+	`, deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query device metrics: %w", err)
+	}
+	defer rows.Close()
+
+	var metrics []*pb.MetricInfo
+	for rows.Next() {
+		var lastTime *time.Time
+		info := &pb.MetricInfo{}
+		if err := rows.Scan(&info.Name, &info.DisplayName, &info.Unit, &info.DataType,
+			&info.MinValue, &info.MaxValue, &info.ReportingIntervalSeconds, &info.Declared, &lastTime); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		if lastTime != nil {
+			info.LastTime = lastTime.Unix()
+		}
+		metrics = append(metrics, info)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return metrics, nil
+}
+
+// LoadMetricCatalog returns every declared metric, indexed by device type then name.
+func (s *TimescaleStorage) LoadMetricCatalog(ctx context.Context) (map[string]map[string]*MetricSpec, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT t.name, m.name, m.display_name, COALESCE(m.unit, ''), m.data_type, m.min_value, m.max_value
+		FROM device_types t
+		LEFT JOIN device_type_metrics m ON m.device_type = t.name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query metric catalog: %w", err)
+	}
+	defer rows.Close()
+
+	catalog := make(map[string]map[string]*MetricSpec)
+	for rows.Next() {
+		var deviceType string
+		var name, displayName, unit, dataType *string
+		spec := &MetricSpec{}
+		if err := rows.Scan(&deviceType, &name, &displayName, &unit, &dataType, &spec.MinValue, &spec.MaxValue); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		// A registered type without metrics has an empty catalog
+		if catalog[deviceType] == nil {
+			catalog[deviceType] = make(map[string]*MetricSpec)
+		}
+		if name == nil {
+			continue
+		}
+		spec.Name, spec.DisplayName, spec.Unit, spec.DataType = *name, *displayName, *unit, *dataType
+		catalog[deviceType][spec.Name] = spec
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return catalog, nil
+}
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	pb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+)
+
+// derivedKinds maps derived metric kinds to their database representation.
+var derivedKinds = map[pb.DerivedMetricKind]string{
+	pb.DerivedMetricKind_DERIVED_METRIC_EXPRESSION:     "expression",
+	pb.DerivedMetricKind_DERIVED_METRIC_INTEGRAL:       "integral",
+	pb.DerivedMetricKind_DERIVED_METRIC_MOVING_AVERAGE: "moving_average",
+}
+
+const derivedMetricColumns = `
+	id::text, device_type, name, kind, COALESCE(expression, ''),
+	COALESCE(source_metric, ''), COALESCE(window_size, ''), COALESCE(unit, ''),
+	created_at, updated_at`
+
+func scanDerivedMetric(row pgx.Row) (*pb.DerivedMetric, error) {
+	var kind string
+	var createdAt, updatedAt time.Time
+	metric := &pb.DerivedMetric{}
+
+	err := row.Scan(&metric.Id, &metric.DeviceType, &metric.Name, &kind, &metric.Expression,
+		&metric.SourceMetric, &metric.Window, &metric.Unit, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range derivedKinds {
+		if v == kind {
+			metric.Kind = k
+		}
+	}
+	metric.CreatedAt = createdAt.Unix()
+	metric.UpdatedAt = updatedAt.Unix()
+	return metric, nil
+}
+
+// ListDerivedMetrics returns derived metric definitions, optionally for one device type.
+func (s *TimescaleStorage) ListDerivedMetrics(ctx context.Context, deviceType string) ([]*pb.DerivedMetric, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+derivedMetricColumns+`
+		FROM derived_metric_definitions
+		WHERE $1 = '' OR device_type = $1
+		ORDER BY device_type, name
+	`, deviceType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query derived metrics: %w", err)
+	}
+	defer rows.Close()
+
+	var metrics []*pb.DerivedMetric
+	for rows.Next() {
+		metric, err := scanDerivedMetric(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		metrics = append(metrics, metric)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return metrics, nil
+}
+
+// UpsertDerivedMetric creates or replaces the definition of (device_type, name).
+func (s *TimescaleStorage) UpsertDerivedMetric(ctx context.Context, metric *pb.DerivedMetric) (*pb.DerivedMetric, error) {
+	row := s.pool.QueryRow(ctx, `
+		INSERT INTO derived_metric_definitions
+			(device_type, name, kind, expression, source_metric, window_size, unit)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (device_type, name) DO UPDATE SET
+			kind = EXCLUDED.kind,
+			expression = EXCLUDED.expression,
+			source_metric = EXCLUDED.source_metric,
+			window_size = EXCLUDED.window_size,
+			unit = EXCLUDED.unit,
+			updated_at = NOW()
+		RETURNING `+derivedMetricColumns,
+		metric.DeviceType, metric.Name, derivedKinds[metric.Kind], nullIfEmpty(metric.Expression),
+		nullIfEmpty(metric.SourceMetric), nullIfEmpty(metric.Window), nullIfEmpty(metric.Unit))
+
+	saved, err := scanDerivedMetric(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert derived metric: %w", err)
+	}
+	return saved, nil
+}
+
+// DeleteDerivedMetric deletes a definition. Values already computed stay in device_telemetry.
+func (s *TimescaleStorage) DeleteDerivedMetric(ctx context.Context, id string) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM derived_metric_definitions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete derived metric: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrDerivedMetricNotFound
+	}
+	return nil
+}
+
+// GetDeviceInfo returns the type and organization of a registered device.
+func (s *TimescaleStorage) GetDeviceInfo(ctx context.Context, deviceID string) (DeviceInfo, error) {
+	var info DeviceInfo
+	err := s.pool.QueryRow(ctx, `SELECT type, org_id::text FROM devices WHERE id = $1`, deviceID).Scan(&info.Type, &info.OrgID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return DeviceInfo{}, ErrDeviceNotFound
+	}
+	if err != nil {
+		return DeviceInfo{}, fmt.Errorf("failed to get device: %w", err)
+	}
+	return info, nil
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+type cachedDevice struct {
+	info    DeviceInfo
+	expires time.Time
+}
+
+// DeviceCache caches the type and organization of each device, so that
+// per-point lookups done at ingest time do not hit the database. Unregistered
+// devices are cached with an empty type and organization.
+type DeviceCache struct {
+	store Storage
+	ttl   time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cachedDevice
+}
+
+// NewDeviceCache creates a cache whose entries expire after ttl.
+func NewDeviceCache(store Storage, ttl time.Duration) *DeviceCache {
+	return &DeviceCache{
+		store:   store,
+		ttl:     ttl,
+		entries: make(map[string]cachedDevice),
+	}
+}
+
+// Get returns the type and organization of a device, both empty if the device
+// is not registered. Lookup failures other than ErrDeviceNotFound are returned
+// and not cached.
+func (c *DeviceCache) Get(ctx context.Context, deviceID string) (DeviceInfo, error) {
+	c.mu.Lock()
+	cached, ok := c.entries[deviceID]
+	c.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.info, nil
+	}
+
+	info, err := c.store.GetDeviceInfo(ctx, deviceID)
+	if err != nil && !errors.Is(err, ErrDeviceNotFound) {
+		return DeviceInfo{}, err
+	}
+
+	c.mu.Lock()
+	c.entries[deviceID] = cachedDevice{info: info, expires: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+	return info, nil
+}
+
+// Type returns the type of a device, or "" if the device is not registered.
+func (c *DeviceCache) Type(ctx context.Context, deviceID string) (string, error) {
+	info, err := c.Get(ctx, deviceID)
+	return info.Type, err
+}
+
+// Org returns the organization of a device, or "" if the device is not registered.
+func (c *DeviceCache) Org(ctx context.Context, deviceID string) (string, error) {
+	info, err := c.Get(ctx, deviceID)
+	return info.OrgID, err
+}
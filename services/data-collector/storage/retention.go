@@ -0,0 +1,314 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	pb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+)
+
+// retentionLockKey serializes retention runs across data-collector replicas
+// (pg_try_advisory_xact_lock key).
+const retentionLockKey = 7240301
+
+// retentionDurationPattern matches durations such as "7 days" or "2 years".
+var retentionDurationPattern = regexp.MustCompile(`^(\d+)\s*(hour|day|week|month|year)s?$`)
+
+// ParseRetentionDuration validates a retention duration and returns it in
+// canonical form ("1 day", "7 days", "2 years").
+func ParseRetentionDuration(s string) (string, error) {
+	m := retentionDurationPattern.FindStringSubmatch(s)
+	if m == nil {
+		return "", fmt.Errorf("invalid duration %q (expected e.g. \"7 days\", \"2 years\")", s)
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil || n <= 0 {
+		return "", fmt.Errorf("invalid duration %q: must be positive", s)
+	}
+	if n == 1 {
+		return "1 " + m[2], nil
+	}
+	return fmt.Sprintf("%d %ss", n, m[2]), nil
+}
+
+// IsValidInterval reports whether interval can be used as an aggregation bucket.
+func IsValidInterval(interval string) bool {
+	_, ok := validIntervals[interval]
+	return ok
+}
+
+const retentionPolicyColumns = `
+	id::text, COALESCE(device_type, ''), COALESCE(metric_name, ''),
+	raw_retention, COALESCE(downsample_interval, ''), COALESCE(downsample_retention, ''),
+	created_at, updated_at`
+
+func scanRetentionPolicy(row pgx.Row) (*pb.RetentionPolicy, error) {
+	var createdAt, updatedAt time.Time
+	policy := &pb.RetentionPolicy{}
+
+	err := row.Scan(&policy.Id, &policy.DeviceType, &policy.MetricName,
+		&policy.RawRetention, &policy.DownsampleInterval, &policy.DownsampleRetention,
+		&createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	policy.CreatedAt = createdAt.Unix()
+	policy.UpdatedAt = updatedAt.Unix()
+	return policy, nil
+}
+
+// ListRetentionPolicies returns all policies, most specific first.
+func (s *TimescaleStorage) ListRetentionPolicies(ctx context.Context) ([]*pb.RetentionPolicy, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+retentionPolicyColumns+`
+		FROM telemetry_retention_policies
+		ORDER BY metric_name IS NULL, device_type IS NULL, device_type, metric_name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query retention policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []*pb.RetentionPolicy
+	for rows.Next() {
+		policy, err := scanRetentionPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+
+	return policies, nil
+}
+
+// UpsertRetentionPolicy creates or replaces the policy for a (device_type, metric_name) scope.
+func (s *TimescaleStorage) UpsertRetentionPolicy(ctx context.Context, policy *pb.RetentionPolicy) (*pb.RetentionPolicy, error) {
+	row := s.pool.QueryRow(ctx, `
+		INSERT INTO telemetry_retention_policies
+			(device_type, metric_name, raw_retention, downsample_interval, downsample_retention)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT ((COALESCE(device_type, '')), (COALESCE(metric_name, ''))) DO UPDATE SET
+			raw_retention = EXCLUDED.raw_retention,
+			downsample_interval = EXCLUDED.downsample_interval,
+			downsample_retention = EXCLUDED.downsample_retention,
+			updated_at = NOW()
+		RETURNING `+retentionPolicyColumns,
+		nullIfEmpty(policy.DeviceType), nullIfEmpty(policy.MetricName), policy.RawRetention,
+		nullIfEmpty(policy.DownsampleInterval), nullIfEmpty(policy.DownsampleRetention))
+
+	saved, err := scanRetentionPolicy(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert retention policy: %w", err)
+	}
+	return saved, nil
+}
+
+// DeleteRetentionPolicy deletes a policy. The default policy (no device type,
+// no metric) is the fallback for every row and cannot be removed.
+func (s *TimescaleStorage) DeleteRetentionPolicy(ctx context.Context, id string) error {
+	var isDefault bool
+	err := s.pool.QueryRow(ctx, `
+		SELECT device_type IS NULL AND metric_name IS NULL
+		FROM telemetry_retention_policies
+		WHERE id = $1
+	`, id).Scan(&isDefault)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrRetentionPolicyNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get retention policy: %w", err)
+	}
+	if isDefault {
+		return ErrDefaultRetentionPolicy
+	}
+
+	if _, err := s.pool.Exec(ctx, `DELETE FROM telemetry_retention_policies WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete retention policy: %w", err)
+	}
+	return nil
+}
+
+// ApplyRetention enforces each policy in its own transaction. A row is governed
+// only by the most specific matching policy (see retentionScope). Runs are
+// serialized across replicas: when another replica is applying a policy, the
+// run stops with ErrRetentionInProgress. Dry runs only read and take no lock.
+func (s *TimescaleStorage) ApplyRetention(ctx context.Context, dryRun bool) ([]*pb.RetentionPolicyResult, error) {
+	policies, err := s.ListRetentionPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*pb.RetentionPolicyResult, 0, len(policies))
+	for _, policy := range policies {
+		result, err := s.applyRetentionPolicy(ctx, policy, dryRun)
+		if errors.Is(err, ErrRetentionInProgress) {
+			return results, err
+		}
+		if err != nil {
+			return results, fmt.Errorf("policy %s: %w", policy.Id, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (s *TimescaleStorage) applyRetentionPolicy(ctx context.Context, policy *pb.RetentionPolicy, dryRun bool) (*pb.RetentionPolicyResult, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin retention transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if !dryRun {
+		var locked bool
+		if err := tx.QueryRow(ctx, "SELECT pg_try_advisory_xact_lock($1)", retentionLockKey).Scan(&locked); err != nil {
+			return nil, fmt.Errorf("failed to acquire retention lock: %w", err)
+		}
+		if !locked {
+			return nil, ErrRetentionInProgress
+		}
+	}
+
+	result := &pb.RetentionPolicyResult{Policy: policy}
+	scopeArgs := []any{nullIfEmpty(policy.DeviceType), nullIfEmpty(policy.MetricName), policy.Id, retentionSpecificity(policy)}
+	rawScope := retentionScope("t")
+
+	// Raw rows older than the cutoff expire. When downsampling, the cutoff is
+	// aligned to a bucket boundary so that only complete buckets are rolled up.
+	cutoff := "NOW() - $5::interval"
+	args := append(scopeArgs, policy.RawRetention)
+	if policy.DownsampleInterval != "" {
+		cutoff = "time_bucket($6::interval, NOW() - $5::interval)"
+		args = append(args, policy.DownsampleInterval)
+	}
+	expired := fmt.Sprintf(`
+		FROM device_telemetry t
+		JOIN devices d ON d.id = t.device_id
+		WHERE t.time < %s AND %s`, cutoff, rawScope)
+
+	if policy.DownsampleInterval != "" {
+		rollup := `
+			SELECT time_bucket($6::interval, t.time) AS bucket, t.device_id, t.metric_name,
+				AVG(t.value) AS avg_value, MIN(t.value) AS min_value, MAX(t.value) AS max_value,
+				COUNT(*) AS sample_count,
+				-- A bucket of mixed units has none: its values cannot be converted
+				CASE WHEN COUNT(DISTINCT COALESCE(t.unit, '')) = 1 THEN MIN(t.unit) END AS unit` + expired + `
+			GROUP BY 1, 2, 3`
+
+		if dryRun {
+			err = tx.QueryRow(ctx, "SELECT COUNT(*) FROM ("+rollup+") r", args...).Scan(&result.BucketsWritten)
+		} else {
+			// Buckets rolled up by a previous run (late data) are merged, not replaced
+			var tag pgconn.CommandTag
+			tag, err = tx.Exec(ctx, `
+				INSERT INTO telemetry_downsampled
+					(bucket, device_id, metric_name, bucket_interval, avg_value, min_value, max_value, sample_count, unit)
+				SELECT bucket, device_id, metric_name, $7, avg_value, min_value, max_value, sample_count, unit
+				FROM (`+rollup+`) r
+				ON CONFLICT (device_id, metric_name, bucket) DO UPDATE SET
+					avg_value = (telemetry_downsampled.avg_value * telemetry_downsampled.sample_count
+						+ EXCLUDED.avg_value * EXCLUDED.sample_count)
+						/ (telemetry_downsampled.sample_count + EXCLUDED.sample_count),
+					min_value = LEAST(telemetry_downsampled.min_value, EXCLUDED.min_value),
+					max_value = GREATEST(telemetry_downsampled.max_value, EXCLUDED.max_value),
+					sample_count = telemetry_downsampled.sample_count + EXCLUDED.sample_count,
+					unit = CASE WHEN telemetry_downsampled.unit IS NOT DISTINCT FROM EXCLUDED.unit
+						THEN EXCLUDED.unit END
+			`, append(args[:6:6], policy.DownsampleInterval)...)
+			result.BucketsWritten = tag.RowsAffected()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to downsample telemetry: %w", err)
+		}
+	}
+
+	if dryRun {
+		err = tx.QueryRow(ctx, "SELECT COUNT(*)"+expired, args...).Scan(&result.RawRowsDeleted)
+	} else {
+		var tag pgconn.CommandTag
+		tag, err = tx.Exec(ctx, fmt.Sprintf(`
+			DELETE FROM device_telemetry t
+			USING devices d
+			WHERE d.id = t.device_id AND t.time < %s AND %s
+		`, cutoff, rawScope), args...)
+		result.RawRowsDeleted = tag.RowsAffected()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete expired telemetry: %w", err)
+	}
+
+	if policy.DownsampleRetention != "" {
+		rollupArgs := append(scopeArgs[:4:4], policy.DownsampleRetention)
+		rollupScope := retentionScope("r")
+		if dryRun {
+			err = tx.QueryRow(ctx, `
+				SELECT COUNT(*)
+				FROM telemetry_downsampled r
+				JOIN devices d ON d.id = r.device_id
+				WHERE r.bucket < NOW() - $5::interval AND `+rollupScope, rollupArgs...).Scan(&result.RollupRowsDeleted)
+		} else {
+			var tag pgconn.CommandTag
+			tag, err = tx.Exec(ctx, `
+				DELETE FROM telemetry_downsampled r
+				USING devices d
+				WHERE d.id = r.device_id AND r.bucket < NOW() - $5::interval AND `+rollupScope, rollupArgs...)
+			result.RollupRowsDeleted = tag.RowsAffected()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete expired rollups: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit retention: %w", err)
+	}
+	return result, nil
+}
+
+// retentionScope returns the condition selecting the rows of alias (joined with
+// devices d) governed by the policy in $1 (device type), $2 (metric), $3 (id) and
+// $4 (specificity): the policy must match, and no more specific policy may.
+func retentionScope(alias string) string {
+	return fmt.Sprintf(`($1::text IS NULL OR d.type = $1)
+		AND ($2::text IS NULL OR %[1]s.metric_name = $2)
+		AND NOT EXISTS (
+			SELECT 1 FROM telemetry_retention_policies o
+			WHERE o.id <> $3::uuid
+			  AND (o.device_type IS NULL OR o.device_type = d.type)
+			  AND (o.metric_name IS NULL OR o.metric_name = %[1]s.metric_name)
+			  AND (CASE WHEN o.metric_name IS NOT NULL THEN 2 ELSE 0 END
+			     + CASE WHEN o.device_type IS NOT NULL THEN 1 ELSE 0 END) > $4
+		)`, alias)
+}
+
+// retentionSpecificity ranks policies: type + metric (3) > metric (2) > type (1) > default (0).
+func retentionSpecificity(policy *pb.RetentionPolicy) int {
+	specificity := 0
+	if policy.MetricName != "" {
+		specificity += 2
+	}
+	if policy.DeviceType != "" {
+		specificity++
+	}
+	return specificity
+}
+
+// nullIfEmpty maps empty strings to SQL NULL.
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,97 @@
+// +build unit
+
+package storage
+
+import (
+	"strings"
+	"testing"
+
+	pb "github.com/yourusername/iot-platform/shared/proto/telemetry"
+)
+
+func TestParseRetentionDuration(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"1 day", "1 day", false},
+		{"1 days", "1 day", false},
+		{"7 days", "7 days", false},
+		{"7days", "7 days", false},
+		{"2 year", "2 years", false},
+		{"12 hours", "12 hours", false},
+		{"3 weeks", "3 weeks", false},
+		{"6 months", "6 months", false},
+		{"0 days", "", true},
+		{"-1 days", "", true},
+		{"7 d", "", true},
+		{"1 minute", "", true},
+		{"7 days; DROP TABLE devices", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRetentionDuration(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseRetentionDuration(%q) = %q, %v; want %q, error %v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestIsValidInterval(t *testing.T) {
+	for _, interval := range []string{"1 minute", "1 hour", "1 day", "1 week"} {
+		if !IsValidInterval(interval) {
+			t.Errorf("IsValidInterval(%q) = false, want true", interval)
+		}
+	}
+	for _, interval := range []string{"", "2 hours", "1 month", "1 hour'; --"} {
+		if IsValidInterval(interval) {
+			t.Errorf("IsValidInterval(%q) = true, want false", interval)
+		}
+	}
+}
+
+// TestRetentionSpecificity checks the precedence of policies: the most
+// specific matching policy governs a row
+func TestRetentionSpecificity(t *testing.T) {
+	tests := []struct {
+		policy *pb.RetentionPolicy
+		want   int
+	}{
+		{&pb.RetentionPolicy{}, 0},
+		{&pb.RetentionPolicy{DeviceType: "sensor"}, 1},
+		{&pb.RetentionPolicy{MetricName: "temperature"}, 2},
+		{&pb.RetentionPolicy{DeviceType: "sensor", MetricName: "temperature"}, 3},
+	}
+	for _, tt := range tests {
+		if got := retentionSpecificity(tt.policy); got != tt.want {
+			t.Errorf("retentionSpecificity(%q, %q) = %d, want %d", tt.policy.DeviceType, tt.policy.MetricName, got, tt.want)
+		}
+	}
+}
+
+// TestSamplesQuery_ReadsRollups checks that aggregates cover the rollups of
+// expired raw data, and that rollups weigh by their sample count
+func TestSamplesQuery_ReadsRollups(t *testing.T) {
+	query, err := samplesQuery("1 hour", "", "t.device_id = $1", "r.device_id = $1")
+	if err != nil {
+		t.Fatalf("samplesQuery failed: %v", err)
+	}
+	for _, want := range []string{
+		"FROM device_telemetry t",
+		"WHERE t.device_id = $1",
+		"UNION ALL",
+		"FROM telemetry_downsampled r",
+		"WHERE r.device_id = $1",
+		"(r.avg_value) * r.sample_count",
+		"time_bucket('1 hour', t.time)",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query does not contain %q:\n%s", want, query)
+		}
+	}
+
+	if _, err := samplesQuery("1 hour", "furlong", "TRUE", "TRUE"); err == nil {
+		t.Error("expected error for an unknown unit")
+	}
+}
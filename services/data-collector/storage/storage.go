@@ -3,29 +3,103 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	pb "github.com/yourusername/iot-platform/shared/proto/telemetry"
 )
 
 // Storage defines the interface for telemetry data persistence.
 type Storage interface {
-	// InsertTelemetry inserts a single telemetry point.
-	InsertTelemetry(ctx context.Context, deviceID, metricName string, value float64, unit string, timestamp int64, metadata map[string]string) error
+	// InsertTelemetry inserts a single telemetry point. A point whose
+	// (device_id, metric_name, time) already exists is resolved by the
+	// storage's ConflictPolicy and is not an error.
+	InsertTelemetry(ctx context.Context, deviceID, metricName string, value float64, unit string, timestamp int64, metadata map[string]string) (InsertOutcome, error)
 
-	// InsertTelemetryBatch inserts multiple telemetry points in a single transaction.
-	InsertTelemetryBatch(ctx context.Context, points []*TelemetryPoint) error
+	// InsertTelemetryBatch inserts multiple telemetry points. Rows that fail are
+	// counted in the result instead of failing the whole batch.
+	InsertTelemetryBatch(ctx context.Context, points []*TelemetryPoint) (*InsertResult, error)
 
 	// GetTelemetry retrieves telemetry data for a device within a time range.
 	GetTelemetry(ctx context.Context, deviceID, metricName string, fromTime, toTime int64, limit int) ([]*pb.TelemetryPoint, error)
 
-	// GetTelemetryAggregated retrieves aggregated telemetry data.
-	GetTelemetryAggregated(ctx context.Context, deviceID, metricName string, fromTime, toTime int64, interval string) ([]*pb.TelemetryAggregation, error)
+	// GetTelemetryAggregated retrieves aggregated telemetry data, converted to
+	// unit when it is not empty. It fails with units.ErrIncompatibleUnits if
+	// some points in range cannot be converted.
+	GetTelemetryAggregated(ctx context.Context, deviceID, metricName string, fromTime, toTime int64, interval, unit string) ([]*pb.TelemetryAggregation, error)
 
 	// GetLatestMetric retrieves the latest value for a specific metric.
 	GetLatestMetric(ctx context.Context, deviceID, metricName string) (*pb.TelemetryPoint, error)
 
-	// GetDeviceMetrics retrieves all available metrics for a device.
-	GetDeviceMetrics(ctx context.Context, deviceID string) ([]string, error)
+	// GetDeviceMetrics returns the metrics declared in the device type's
+	// catalog merged with those the device has reported, sorted by name.
+	GetDeviceMetrics(ctx context.Context, deviceID string) ([]*pb.MetricInfo, error)
+
+	// LoadMetricCatalog returns the declared metrics of every device type,
+	// indexed by device type then metric name.
+	LoadMetricCatalog(ctx context.Context) (map[string]map[string]*MetricSpec, error)
+
+	// GetTelemetryBatch retrieves aligned aggregated series for several devices and metrics.
+	GetTelemetryBatch(ctx context.Context, query *BatchQuery) ([]*pb.TelemetryBatchSeries, error)
+
+	// ExportTelemetry pages through raw telemetry ordered by (time, device_id, metric_name),
+	// calling fn once per page until the range is exhausted or fn returns an error.
+	ExportTelemetry(ctx context.Context, query *ExportQuery, pageSize int, fn func(records []*pb.TelemetryRecord) error) error
+
+	// ImportTelemetry bulk-loads historical points returned by next (until it
+	// returns io.EOF) and applies policy to rows that already exist.
+	// The import is atomic: either every row is applied or none is.
+	ImportTelemetry(ctx context.Context, policy pb.ImportConflictPolicy, next func() ([]*TelemetryPoint, error)) (*ImportResult, error)
+
+	// RefreshAggregates recomputes the continuous aggregates covering [fromTime, toTime].
+	RefreshAggregates(ctx context.Context, fromTime, toTime int64) error
+
+	// ListRetentionPolicies returns all retention policies.
+	ListRetentionPolicies(ctx context.Context) ([]*pb.RetentionPolicy, error)
+
+	// UpsertRetentionPolicy creates or replaces the policy for the policy's
+	// (device type, metric) scope. Durations must already be validated.
+	UpsertRetentionPolicy(ctx context.Context, policy *pb.RetentionPolicy) (*pb.RetentionPolicy, error)
+
+	// DeleteRetentionPolicy deletes a policy. The default policy cannot be deleted.
+	DeleteRetentionPolicy(ctx context.Context, id string) error
+
+	// ApplyRetention enforces every policy, downsampling then deleting expired
+	// raw points. With dryRun, nothing is modified and the counts are estimates
+	// of what would be affected. Fails with ErrRetentionInProgress, after the
+	// policies already applied, when another replica is applying retention.
+	ApplyRetention(ctx context.Context, dryRun bool) ([]*pb.RetentionPolicyResult, error)
+
+	// ListDerivedMetrics returns derived metric definitions, for one device
+	// type or for all types when deviceType is empty.
+	ListDerivedMetrics(ctx context.Context, deviceType string) ([]*pb.DerivedMetric, error)
+
+	// UpsertDerivedMetric creates or replaces the definition of (device type, name).
+	// The definition must already be validated.
+	UpsertDerivedMetric(ctx context.Context, metric *pb.DerivedMetric) (*pb.DerivedMetric, error)
+
+	// DeleteDerivedMetric deletes a derived metric definition.
+	DeleteDerivedMetric(ctx context.Context, id string) error
+
+	// GetDeviceInfo returns the type and organization of a registered device.
+	GetDeviceInfo(ctx context.Context, deviceID string) (DeviceInfo, error)
+
+	// LoadAnomalyStates returns the persisted anomaly detector state.
+	LoadAnomalyStates(ctx context.Context) ([]*AnomalyState, error)
+
+	// SaveAnomalyStates persists anomaly detector states.
+	SaveAnomalyStates(ctx context.Context, states []*AnomalyState) error
+
+	// GetSeasonalBaseline returns the expected value of a metric per hour of the
+	// day (0-23, UTC), computed from the last weeks of hourly aggregates.
+	GetSeasonalBaseline(ctx context.Context, deviceID, metricName string, weeks int) (map[int]*SeasonalBaseline, error)
+
+	// InsertAnomaly stores a detected anomaly.
+	InsertAnomaly(ctx context.Context, anomaly *pb.Anomaly) error
+
+	// GetAnomalies retrieves detected anomalies, most recent first.
+	GetAnomalies(ctx context.Context, query *AnomalyQuery) ([]*pb.Anomaly, error)
 
 	// Close closes the storage connection.
 	Close() error
@@ -40,3 +114,186 @@ type TelemetryPoint struct {
 	Timestamp  int64
 	Metadata   map[string]string
 }
+
+// ConflictPolicy decides what happens when an ingested point has the same
+// (device_id, metric_name, time) as a stored row, e.g. after an MQTT QoS 1 redelivery.
+type ConflictPolicy string
+
+const (
+	// ConflictIgnore keeps the stored row.
+	ConflictIgnore ConflictPolicy = "ignore"
+	// ConflictOverwrite replaces the stored row with the new point.
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictKeepMax keeps whichever value is greater.
+	ConflictKeepMax ConflictPolicy = "keep-max"
+)
+
+// ParseConflictPolicy validates a policy name.
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	switch policy := ConflictPolicy(s); policy {
+	case ConflictIgnore, ConflictOverwrite, ConflictKeepMax:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid conflict policy %q (ignore, overwrite or keep-max)", s)
+	}
+}
+
+// InsertOutcome describes what happened to an inserted point.
+type InsertOutcome int
+
+const (
+	// OutcomeInserted means the point was stored as a new row.
+	OutcomeInserted InsertOutcome = iota
+	// OutcomeUpdated means the point was a duplicate that replaced the stored row.
+	OutcomeUpdated
+	// OutcomeIgnored means the point was a duplicate and the stored row was kept.
+	OutcomeIgnored
+)
+
+// InsertResult summarizes a batch insert.
+type InsertResult struct {
+	Inserted int
+	Updated  int
+	Ignored  int
+	Failed   int
+	// FirstError is the error of the first failed row, if any.
+	FirstError error
+}
+
+// Duplicates returns the number of points that hit an existing row.
+func (r *InsertResult) Duplicates() int {
+	return r.Updated + r.Ignored
+}
+
+// DeviceInfo describes a registered device.
+type DeviceInfo struct {
+	Type  string
+	OrgID string
+}
+
+// BatchQuery describes a multi-device, multi-metric aggregated query.
+// Devices are selected by explicit IDs, by type/metadata, by group or
+// location, or a combination of them.
+// A non-empty OrgID restricts the query to the devices of that organization.
+type BatchQuery struct {
+	OrgID       string
+	DeviceIDs   []string
+	DeviceType  string
+	Metadata    map[string]string
+	GroupIDs    []string
+	LocationID  string
+	MetricNames []string
+	FromTime    int64
+	ToTime      int64
+	Interval    string
+	GroupBy     pb.TelemetryGroupBy
+	// LocationKind is the level of the hierarchy series are grouped by with
+	// GROUP_BY_LOCATION; empty groups by the location of each device.
+	LocationKind string
+	// Unit converts aggregated values; the query fails with
+	// units.ErrIncompatibleUnits if some points cannot be converted.
+	Unit string
+}
+
+// ExportQuery describes a raw telemetry export.
+// Empty DeviceIDs or MetricNames match every device or metric.
+// When After is set, export resumes strictly after that position.
+// A non-empty OrgID restricts the export to the devices of that organization.
+type ExportQuery struct {
+	OrgID       string
+	DeviceIDs   []string
+	MetricNames []string
+	FromTime    int64
+	ToTime      int64
+	After       *ExportPosition
+}
+
+// ExportPosition identifies a row in export order.
+type ExportPosition struct {
+	Time       int64
+	DeviceID   string
+	MetricName string
+}
+
+// ImportResult summarizes a bulk import.
+type ImportResult struct {
+	Received int64
+	Written  int64
+	Skipped  int64
+	FromTime int64
+	ToTime   int64
+}
+
+// AnomalyState holds the rolling statistics of one (device, metric) series.
+type AnomalyState struct {
+	DeviceID   string
+	MetricName string
+	Mean       float64 // EWMA of the value
+	Variance   float64 // EWMA of the squared deviation
+	Count      int64   // Points observed
+	LastTime   int64   // Unix timestamp of the last observed point
+}
+
+// SeasonalBaseline is the expected value of a metric for one hour of the day.
+type SeasonalBaseline struct {
+	Expected float64
+	StdDev   float64
+	Buckets  int64 // Hourly buckets the baseline is computed from
+}
+
+// AnomalyQuery describes a search for detected anomalies.
+// Empty DeviceID or MetricName match every device or metric.
+// A non-empty OrgID restricts the search to the devices of that organization.
+type AnomalyQuery struct {
+	OrgID      string
+	DeviceID   string
+	MetricName string
+	FromTime   int64
+	ToTime     int64
+	MinScore   float64
+	Limit      int
+}
+
+// ErrImportConflict is returned when an import with IMPORT_CONFLICT_FAIL
+// hits a row that already exists.
+var ErrImportConflict = errors.New("imported telemetry conflicts with existing data")
+
+// Retention policy errors.
+var (
+	ErrRetentionPolicyNotFound = errors.New("retention policy not found")
+	ErrDefaultRetentionPolicy  = errors.New("the default retention policy cannot be deleted")
+	ErrRetentionInProgress     = errors.New("retention is being applied by another replica")
+)
+
+// ErrDerivedMetricNotFound is returned when a derived metric definition does not exist.
+var ErrDerivedMetricNotFound = errors.New("derived metric not found")
+
+// MaxBatchPoints bounds the buckets a batch query may return, all series
+// included, since gapfilling emits a bucket per series even without data.
+const MaxBatchPoints = 200000
+
+// ErrBatchTooLarge is returned when a batch query could return more than
+// MaxBatchPoints buckets.
+var ErrBatchTooLarge = errors.New("batch query too large")
+
+// ErrDeviceNotFound is returned when a device is not registered.
+var ErrDeviceNotFound = errors.New("device not found")
+
+// UnknownDevicesError is returned when an import references devices that are not registered.
+type UnknownDevicesError struct {
+	DeviceIDs []string
+}
+
+func (e *UnknownDevicesError) Error() string {
+	return fmt.Sprintf("unknown devices: %s", strings.Join(e.DeviceIDs, ", "))
+}
+
+// MetricSpec is a metric declared in a device type's catalog.
+type MetricSpec struct {
+	Name        string
+	DisplayName string
+	Unit        string
+	DataType    string // "float", "integer" or "boolean"
+	MinValue    *float64
+	MaxValue    *float64
+}
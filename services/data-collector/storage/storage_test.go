@@ -0,0 +1,33 @@
+// +build unit
+
+package storage
+
+import "testing"
+
+func TestParseConflictPolicy(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    ConflictPolicy
+		wantErr bool
+	}{
+		{"ignore", ConflictIgnore, false},
+		{"overwrite", ConflictOverwrite, false},
+		{"keep-max", ConflictKeepMax, false},
+		{"", "", true},
+		{"Overwrite", "", true},
+		{"keep_max", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseConflictPolicy(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseConflictPolicy(%q) = %q, %v; want %q, error %v", tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestInsertResult_Duplicates(t *testing.T) {
+	result := &InsertResult{Inserted: 5, Updated: 2, Ignored: 3, Failed: 1}
+	if got := result.Duplicates(); got != 5 {
+		t.Errorf("Duplicates() = %d, want 5", got)
+	}
+}
@@ -3,22 +3,29 @@ package storage
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/yourusername/iot-platform/services/data-collector/units"
 	pb "github.com/yourusername/iot-platform/shared/proto/telemetry"
 )
 
 // TimescaleStorage implements Storage using TimescaleDB.
 type TimescaleStorage struct {
-	pool *pgxpool.Pool
+	pool        *pgxpool.Pool
+	insertQuery string
 }
 
 // NewTimescaleStorage creates a new TimescaleDB storage connection.
-func NewTimescaleStorage(ctx context.Context, dsn string) (*TimescaleStorage, error) {
+// policy decides how inserts resolve rows that already exist.
+func NewTimescaleStorage(ctx context.Context, dsn string, policy ConflictPolicy) (*TimescaleStorage, error) {
 	config, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse DSN: %w", err)
@@ -41,11 +48,11 @@ func NewTimescaleStorage(ctx context.Context, dsn string) (*TimescaleStorage, er
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &TimescaleStorage{pool: pool}, nil
+	return &TimescaleStorage{pool: pool, insertQuery: buildInsertQuery(policy)}, nil
 }
 
-// InsertTelemetry inserts a single telemetry point.
-func (s *TimescaleStorage) InsertTelemetry(ctx context.Context, deviceID, metricName string, value float64, unit string, timestamp int64, metadata map[string]string) error {
+// InsertTelemetry inserts a single telemetry point, resolving duplicates with the conflict policy.
+func (s *TimescaleStorage) InsertTelemetry(ctx context.Context, deviceID, metricName string, value float64, unit string, timestamp int64, metadata map[string]string) (InsertOutcome, error) {
 	metadataJSON, err := json.Marshal(metadata)
 	if err != nil {
 		metadataJSON = []byte("{}")
@@ -53,22 +60,20 @@ func (s *TimescaleStorage) InsertTelemetry(ctx context.Context, deviceID, metric
 
 	ts := time.Unix(timestamp, 0)
 
-	_, err = s.pool.Exec(ctx, `
-		INSERT INTO device_telemetry (time, device_id, metric_name, value, unit, metadata)
-		VALUES ($1, $2, $3, $4, $5, $6)
-	`, ts, deviceID, metricName, value, unit, metadataJSON)
-
-	if err != nil {
-		return fmt.Errorf("failed to insert telemetry: %w", err)
-	}
-
-	return nil
+	var inserted bool
+	err = s.pool.QueryRow(ctx, s.insertQuery, ts, deviceID, metricName, value, unit, metadataJSON).Scan(&inserted)
+	return insertOutcome(inserted, err)
 }
 
-// InsertTelemetryBatch inserts multiple telemetry points in a single transaction.
-func (s *TimescaleStorage) InsertTelemetryBatch(ctx context.Context, points []*TelemetryPoint) error {
+// InsertTelemetryBatch inserts multiple telemetry points in one round trip.
+//
+// A pgx.Batch runs as a single implicit transaction, so one bad row (unknown
+// device, oversized value...) aborts every statement in it. When that happens
+// the points are retried one by one so that only the bad rows are lost.
+func (s *TimescaleStorage) InsertTelemetryBatch(ctx context.Context, points []*TelemetryPoint) (*InsertResult, error) {
+	result := &InsertResult{}
 	if len(points) == 0 {
-		return nil
+		return result, nil
 	}
 
 	batch := &pgx.Batch{}
@@ -79,22 +84,98 @@ func (s *TimescaleStorage) InsertTelemetryBatch(ctx context.Context, points []*T
 		}
 
 		ts := time.Unix(point.Timestamp, 0)
-		batch.Queue(`
-			INSERT INTO device_telemetry (time, device_id, metric_name, value, unit, metadata)
-			VALUES ($1, $2, $3, $4, $5, $6)
-		`, ts, point.DeviceID, point.MetricName, point.Value, point.Unit, metadataJSON)
+		batch.Queue(s.insertQuery, ts, point.DeviceID, point.MetricName, point.Value, point.Unit, metadataJSON)
 	}
 
+	outcomes := make([]InsertOutcome, 0, len(points))
 	br := s.pool.SendBatch(ctx, batch)
-	defer br.Close()
-
 	for range points {
-		if _, err := br.Exec(); err != nil {
-			return fmt.Errorf("failed to execute batch insert: %w", err)
+		var inserted bool
+		err := br.QueryRow().Scan(&inserted)
+		outcome, err := insertOutcome(inserted, err)
+		if err != nil {
+			outcomes = nil
+			break
 		}
+		outcomes = append(outcomes, outcome)
+	}
+	if err := br.Close(); err != nil && outcomes != nil {
+		// The implicit transaction failed to commit: nothing was stored
+		outcomes = nil
 	}
 
-	return nil
+	if outcomes == nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("failed to execute batch insert: %w", ctx.Err())
+		}
+		for _, point := range points {
+			outcome, err := s.InsertTelemetry(ctx, point.DeviceID, point.MetricName, point.Value, point.Unit, point.Timestamp, point.Metadata)
+			if err != nil {
+				result.Failed++
+				if result.FirstError == nil {
+					result.FirstError = err
+				}
+				continue
+			}
+			outcomes = append(outcomes, outcome)
+		}
+	}
+
+	for _, outcome := range outcomes {
+		switch outcome {
+		case OutcomeInserted:
+			result.Inserted++
+		case OutcomeUpdated:
+			result.Updated++
+		case OutcomeIgnored:
+			result.Ignored++
+		}
+	}
+
+	return result, nil
+}
+
+// buildInsertQuery returns the idempotent insert statement for a conflict policy.
+// RETURNING (xmax = 0) is true for a fresh row and false for an updated one;
+// no row is returned when the conflicting row is left untouched.
+func buildInsertQuery(policy ConflictPolicy) string {
+	var onConflict string
+	switch policy {
+	case ConflictOverwrite:
+		onConflict = `DO UPDATE SET
+			value = EXCLUDED.value,
+			unit = EXCLUDED.unit,
+			metadata = EXCLUDED.metadata`
+	case ConflictKeepMax:
+		onConflict = `DO UPDATE SET
+			value = EXCLUDED.value,
+			unit = EXCLUDED.unit,
+			metadata = EXCLUDED.metadata
+		WHERE EXCLUDED.value > device_telemetry.value`
+	default:
+		onConflict = "DO NOTHING"
+	}
+
+	return fmt.Sprintf(`
+		INSERT INTO device_telemetry (time, device_id, metric_name, value, unit, metadata)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (device_id, metric_name, time) %s
+		RETURNING (xmax = 0) AS inserted
+	`, onConflict)
+}
+
+// insertOutcome interprets the result of an insert built by buildInsertQuery.
+func insertOutcome(inserted bool, err error) (InsertOutcome, error) {
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return OutcomeIgnored, nil
+	case err != nil:
+		return 0, fmt.Errorf("failed to insert telemetry: %w", err)
+	case inserted:
+		return OutcomeInserted, nil
+	default:
+		return OutcomeUpdated, nil
+	}
 }
 
 // GetTelemetry retrieves telemetry data for a device within a time range.
@@ -149,43 +230,34 @@ func (s *TimescaleStorage) GetTelemetry(ctx context.Context, deviceID, metricNam
 	return points, nil
 }
 
-// GetTelemetryAggregated retrieves aggregated telemetry data.
-func (s *TimescaleStorage) GetTelemetryAggregated(ctx context.Context, deviceID, metricName string, fromTime, toTime int64, interval string) ([]*pb.TelemetryAggregation, error) {
+// GetTelemetryAggregated retrieves aggregated telemetry data, rollups of
+// expired raw data included (see samplesQuery).
+func (s *TimescaleStorage) GetTelemetryAggregated(ctx context.Context, deviceID, metricName string, fromTime, toTime int64, interval, unit string) ([]*pb.TelemetryAggregation, error) {
 	fromTS := time.Unix(fromTime, 0)
 	toTS := time.Unix(toTime, 0)
 
 	// Validate interval to prevent SQL injection
-	validIntervals := map[string]bool{
-		"1 minute":  true,
-		"5 minutes": true,
-		"15 minutes": true,
-		"30 minutes": true,
-		"1 hour":    true,
-		"6 hours":   true,
-		"12 hours":  true,
-		"1 day":     true,
-		"1 week":    true,
-	}
+	interval = normalizeInterval(interval)
 
-	if !validIntervals[interval] {
-		interval = "1 hour" // Default to 1 hour if invalid
+	samples, err := samplesQuery(interval, unit,
+		"t.device_id = $1 AND t.metric_name = $2 AND t.time >= $3 AND t.time <= $4",
+		"r.device_id = $1 AND r.metric_name = $2 AND r.bucket >= $3 AND r.bucket <= $4")
+	if err != nil {
+		return nil, err
 	}
 
 	query := fmt.Sprintf(`
 		SELECT
-			time_bucket('%s', time) AS bucket,
-			AVG(value) AS avg_value,
-			MIN(value) AS min_value,
-			MAX(value) AS max_value,
-			COUNT(*) AS sample_count
-		FROM device_telemetry
-		WHERE device_id = $1
-		  AND metric_name = $2
-		  AND time >= $3
-		  AND time <= $4
+			time_bucket('%s', s.time) AS bucket,
+			SUM(s.total) / NULLIF(SUM(s.sample_count), 0) AS avg_value,
+			MIN(s.min_value) AS min_value,
+			MAX(s.max_value) AS max_value,
+			SUM(s.sample_count)::bigint AS sample_count,
+			SUM(s.unconverted)::bigint AS unconverted
+		FROM (%s) s
 		GROUP BY bucket
 		ORDER BY bucket DESC
-	`, interval)
+	`, interval, samples)
 
 	rows, err := s.pool.Query(ctx, query, deviceID, metricName, fromTS, toTS)
 	if err != nil {
@@ -194,20 +266,25 @@ func (s *TimescaleStorage) GetTelemetryAggregated(ctx context.Context, deviceID,
 	defer rows.Close()
 
 	var aggregations []*pb.TelemetryAggregation
+	var unconverted int64
 	for rows.Next() {
 		var bucket time.Time
-		var avg, min, max float64
-		var count int64
+		var avg, min, max *float64
+		var count, skipped int64
 
-		if err := rows.Scan(&bucket, &avg, &min, &max, &count); err != nil {
+		if err := rows.Scan(&bucket, &avg, &min, &max, &count, &skipped); err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
+		unconverted += skipped
+		if count == 0 {
+			continue
+		}
 
 		aggregations = append(aggregations, &pb.TelemetryAggregation{
 			Bucket: bucket.Format(time.RFC3339),
-			Avg:    avg,
-			Min:    min,
-			Max:    max,
+			Avg:    *avg,
+			Min:    *min,
+			Max:    *max,
 			Count:  count,
 		})
 	}
@@ -215,10 +292,478 @@ func (s *TimescaleStorage) GetTelemetryAggregated(ctx context.Context, deviceID,
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("row iteration error: %w", err)
 	}
+	if unconverted > 0 {
+		return nil, unconvertedError(unconverted, unit)
+	}
 
 	return aggregations, nil
 }
 
+// unconvertedError reports points that could not be converted to unit:
+// aggregating without them would silently shrink the series.
+func unconvertedError(n int64, unit string) error {
+	return fmt.Errorf("%w: %d points have no unit, an unknown one or one of another quantity than %q",
+		units.ErrIncompatibleUnits, n, unit)
+}
+
+// batchFilter returns the grouping columns, join and device conditions of a
+// batch query, numbering its arguments after args. Conditions only reference
+// the devices table (alias d), so that they also serve to count series.
+func batchFilter(q *BatchQuery, args []any) (cols [3]string, join string, where []string, _ []any, err error) {
+	// Grouping columns: device_id and type are blanked out when aggregating
+	// across them, group_key holds the group or location of the series
+	cols = [3]string{"d.id::text", "d.type", "''"}
+	switch q.GroupBy {
+	case pb.TelemetryGroupBy_GROUP_BY_DEVICE_TYPE:
+		cols[0] = "''"
+	case pb.TelemetryGroupBy_GROUP_BY_ALL:
+		cols[0], cols[1] = "''", "''"
+	case pb.TelemetryGroupBy_GROUP_BY_GROUP:
+		// A device in several of the groups counts in each of them
+		args = append(args, q.GroupIDs)
+		cols = [3]string{"''", "''", "gd.group_id::text"}
+		join = fmt.Sprintf("JOIN device_group_devices gd ON gd.device_id = d.id AND gd.group_id = ANY($%d::uuid[])", len(args))
+	case pb.TelemetryGroupBy_GROUP_BY_LOCATION:
+		cols = [3]string{"''", "''", "COALESCE(d.location_id::text, '')"}
+		if q.LocationKind != "" {
+			// Devices below that level of the hierarchy are left out
+			args = append(args, q.LocationKind)
+			cols[2] = "la.ancestor_id::text"
+			join = fmt.Sprintf("JOIN location_ancestors la ON la.location_id = d.location_id AND la.ancestor_kind = $%d", len(args))
+		}
+	}
+	if q.OrgID != "" {
+		args = append(args, q.OrgID)
+		where = append(where, fmt.Sprintf("d.org_id = $%d::uuid", len(args)))
+	}
+	if len(q.DeviceIDs) > 0 {
+		args = append(args, q.DeviceIDs)
+		where = append(where, fmt.Sprintf("d.id = ANY($%d::uuid[])", len(args)))
+	}
+	if q.DeviceType != "" {
+		args = append(args, q.DeviceType)
+		where = append(where, fmt.Sprintf("d.type = $%d", len(args)))
+	}
+	if len(q.Metadata) > 0 {
+		metadataJSON, err := json.Marshal(q.Metadata)
+		if err != nil {
+			return cols, "", nil, nil, fmt.Errorf("failed to marshal metadata filter: %w", err)
+		}
+		args = append(args, metadataJSON)
+		where = append(where, fmt.Sprintf("d.metadata @> $%d::jsonb", len(args)))
+	}
+	if len(q.GroupIDs) > 0 && q.GroupBy != pb.TelemetryGroupBy_GROUP_BY_GROUP {
+		args = append(args, q.GroupIDs)
+		where = append(where, fmt.Sprintf("d.id IN (SELECT device_id FROM device_group_devices WHERE group_id = ANY($%d::uuid[]))", len(args)))
+	}
+	if q.LocationID != "" {
+		args = append(args, q.LocationID)
+		where = append(where, fmt.Sprintf("d.location_id IN (SELECT location_id FROM location_ancestors WHERE ancestor_id = $%d::uuid)", len(args)))
+	}
+	return cols, join, where, args, nil
+}
+
+// samplesQuery returns a query of partial aggregates over raw telemetry (alias
+// t) and rollups (alias r) matching the given conditions, in columns time,
+// device_id, metric_name, total, min_value, max_value and sample_count. Raw
+// points are pre-aggregated into buckets of interval; rollups keep their own
+// bucket, so ranges older than the raw retention of a policy are served at
+// the resolution of its downsampling. The retention job writes rollups and
+// deletes their raw points in one transaction, so the two never overlap.
+// Values are converted to unit target like valueExpr, if set; unconverted
+// counts the points that could not be.
+func samplesQuery(interval, target, rawWhere, rollupWhere string) (string, error) {
+	value, err := valueExpr("t", target)
+	if err != nil {
+		return "", err
+	}
+	avg, err := convertExpr("r.avg_value", "r.unit", target)
+	if err != nil {
+		return "", err
+	}
+	min, _ := convertExpr("r.min_value", "r.unit", target)
+	max, _ := convertExpr("r.max_value", "r.unit", target)
+
+	return fmt.Sprintf(`
+		SELECT time_bucket('%s', t.time) AS time, t.device_id, t.metric_name,
+			SUM(%[2]s) AS total, MIN(%[2]s) AS min_value, MAX(%[2]s) AS max_value,
+			COUNT(%[2]s) AS sample_count, COUNT(*) - COUNT(%[2]s) AS unconverted
+		FROM device_telemetry t
+		WHERE %[3]s
+		GROUP BY 1, 2, 3
+		UNION ALL
+		SELECT r.bucket, r.device_id, r.metric_name,
+			(%[5]s) * r.sample_count, %[6]s, %[7]s,
+			CASE WHEN (%[5]s) IS NULL THEN 0 ELSE r.sample_count END,
+			CASE WHEN (%[5]s) IS NULL THEN r.sample_count ELSE 0 END
+		FROM telemetry_downsampled r
+		WHERE %[4]s`, interval, value, rawWhere, rollupWhere, avg, min, max), nil
+}
+
+// batchBuckets returns the number of buckets of width interval that
+// time_bucket_gapfill produces over [fromTime, toTime].
+func batchBuckets(fromTime, toTime int64, interval string) int64 {
+	width := int64(validIntervals[interval] / time.Second)
+	return (toTime-fromTime)/width + 2 // partial buckets at both ends
+}
+
+// GetTelemetryBatch retrieves aggregated series for several devices and metrics
+// in a single query, rollups of expired raw data included (see samplesQuery).
+// Buckets are gap-filled over [FromTime, ToTime] so that every returned series
+// shares the same timeline; empty buckets have a zero count.
+// Queries that could return more than MaxBatchPoints buckets fail with
+// ErrBatchTooLarge before scanning any telemetry.
+func (s *TimescaleStorage) GetTelemetryBatch(ctx context.Context, q *BatchQuery) ([]*pb.TelemetryBatchSeries, error) {
+	interval := normalizeInterval(q.Interval)
+
+	// Gapfilling emits every bucket of every series, data or not: bound the
+	// result by the series the filter can match before running the query
+	cols, join, where, countArgs, err := batchFilter(q, nil)
+	if err != nil {
+		return nil, err
+	}
+	var groups int64
+	err = s.pool.QueryRow(ctx, fmt.Sprintf(`
+		SELECT COUNT(*) FROM (
+			SELECT DISTINCT %s, %s, %s FROM devices d %s WHERE %s
+		) series
+	`, cols[0], cols[1], cols[2], join, strings.Join(append(where, "TRUE"), " AND ")), countArgs...).Scan(&groups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count batch series: %w", err)
+	}
+	buckets := batchBuckets(q.FromTime, q.ToTime, interval)
+	if groups*int64(len(q.MetricNames))*buckets > MaxBatchPoints {
+		return nil, fmt.Errorf("%w: %d series of %d metrics over %d buckets of %s, maximum is %d points",
+			ErrBatchTooLarge, groups, len(q.MetricNames), buckets, interval, MaxBatchPoints)
+	}
+
+	args := []any{time.Unix(q.FromTime, 0), time.Unix(q.ToTime, 0), q.MetricNames}
+	cols, join, where, args, err = batchFilter(q, args)
+	if err != nil {
+		return nil, err
+	}
+	// Devices are selected before aggregating, so that only their telemetry is read
+	devices := "SELECT d.id FROM devices d WHERE " + strings.Join(append(where, "TRUE"), " AND ")
+	samples, err := samplesQuery(interval, q.Unit,
+		"t.time >= $1 AND t.time <= $2 AND t.metric_name = ANY($3) AND t.device_id IN ("+devices+")",
+		"r.bucket >= $1 AND r.bucket <= $2 AND r.metric_name = ANY($3) AND r.device_id IN ("+devices+")")
+	if err != nil {
+		return nil, err
+	}
+
+	// Gapfilled rows have NULL aggregates, SUM included
+	query := fmt.Sprintf(`
+		SELECT
+			time_bucket_gapfill('%s', s.time, $1::timestamptz, $2::timestamptz) AS bucket,
+			%s AS group_device,
+			%s AS group_type,
+			%s AS group_key,
+			s.metric_name,
+			SUM(s.total) / NULLIF(SUM(s.sample_count), 0) AS avg_value,
+			MIN(s.min_value) AS min_value,
+			MAX(s.max_value) AS max_value,
+			COALESCE(SUM(s.sample_count), 0)::bigint AS sample_count,
+			COALESCE(SUM(s.unconverted), 0)::bigint AS unconverted
+		FROM (%[5]s) s
+		JOIN devices d ON d.id = s.device_id
+		%[6]s
+		GROUP BY bucket, group_device, group_type, group_key, s.metric_name
+		ORDER BY group_device, group_type, group_key, s.metric_name, bucket
+	`, interval, cols[0], cols[1], cols[2], samples, join)
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query telemetry batch: %w", err)
+	}
+	defer rows.Close()
+
+	var series []*pb.TelemetryBatchSeries
+	var current *pb.TelemetryBatchSeries
+	var unconverted int64
+	for rows.Next() {
+		var bucket time.Time
+		var deviceID, deviceType, groupKey, metricName string
+		var avg, min, max *float64
+		var count, skipped int64
+
+		if err := rows.Scan(&bucket, &deviceID, &deviceType, &groupKey, &metricName, &avg, &min, &max, &count, &skipped); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		unconverted += skipped
+
+		// Rows are ordered by group, so a new series starts whenever the group changes
+		if current == nil || current.DeviceId != deviceID || current.DeviceType != deviceType ||
+			current.GroupId+current.LocationId != groupKey || current.MetricName != metricName {
+			current = &pb.TelemetryBatchSeries{
+				DeviceId:   deviceID,
+				DeviceType: deviceType,
+				MetricName: metricName,
+			}
+			switch q.GroupBy {
+			case pb.TelemetryGroupBy_GROUP_BY_GROUP:
+				current.GroupId = groupKey
+			case pb.TelemetryGroupBy_GROUP_BY_LOCATION:
+				current.LocationId = groupKey
+			}
+			series = append(series, current)
+		}
+
+		aggregation := &pb.TelemetryAggregation{
+			Bucket: bucket.Format(time.RFC3339),
+			Count:  count,
+		}
+		if count > 0 {
+			aggregation.Avg, aggregation.Min, aggregation.Max = *avg, *min, *max
+		}
+		current.Aggregations = append(current.Aggregations, aggregation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("row iteration error: %w", err)
+	}
+	if unconverted > 0 {
+		return nil, unconvertedError(unconverted, q.Unit)
+	}
+
+	return series, nil
+}
+
+// ExportTelemetry streams raw telemetry through a server-side cursor so that
+// arbitrarily large ranges can be exported without loading them in memory.
+// The cursor lives in a read-only transaction for the duration of the export.
+func (s *TimescaleStorage) ExportTelemetry(ctx context.Context, q *ExportQuery, pageSize int, fn func(records []*pb.TelemetryRecord) error) error {
+	args := []any{time.Unix(q.FromTime, 0), time.Unix(q.ToTime, 0)}
+	where := []string{
+		"time >= $1",
+		"time <= $2",
+	}
+	if q.OrgID != "" {
+		args = append(args, q.OrgID)
+		where = append(where, fmt.Sprintf("device_id IN (SELECT id FROM devices WHERE org_id = $%d::uuid)", len(args)))
+	}
+	if len(q.DeviceIDs) > 0 {
+		args = append(args, q.DeviceIDs)
+		where = append(where, fmt.Sprintf("device_id = ANY($%d::uuid[])", len(args)))
+	}
+	if len(q.MetricNames) > 0 {
+		args = append(args, q.MetricNames)
+		where = append(where, fmt.Sprintf("metric_name = ANY($%d)", len(args)))
+	}
+	if q.After != nil {
+		args = append(args, time.Unix(q.After.Time, 0), q.After.DeviceID, q.After.MetricName)
+		where = append(where, fmt.Sprintf("(time, device_id, metric_name) > ($%d, $%d::uuid, $%d)", len(args)-2, len(args)-1, len(args)))
+	}
+
+	tx, err := s.pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return fmt.Errorf("failed to begin export transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, fmt.Sprintf(`
+		DECLARE export_cursor NO SCROLL CURSOR FOR
+		SELECT time, device_id::text, metric_name, value, unit
+		FROM device_telemetry
+		WHERE %s
+		ORDER BY time, device_id, metric_name
+	`, strings.Join(where, " AND ")), args...)
+	if err != nil {
+		return fmt.Errorf("failed to declare export cursor: %w", err)
+	}
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM export_cursor", pageSize)
+	for {
+		rows, err := tx.Query(ctx, fetch)
+		if err != nil {
+			return fmt.Errorf("failed to fetch export page: %w", err)
+		}
+
+		records := make([]*pb.TelemetryRecord, 0, pageSize)
+		for rows.Next() {
+			var ts time.Time
+			var unit *string
+			record := &pb.TelemetryRecord{}
+
+			if err := rows.Scan(&ts, &record.DeviceId, &record.MetricName, &record.Value, &unit); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan row: %w", err)
+			}
+
+			record.Time = ts.Unix()
+			if unit != nil {
+				record.Unit = *unit
+			}
+			records = append(records, record)
+		}
+		rows.Close()
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("row iteration error: %w", err)
+		}
+		if len(records) == 0 {
+			break
+		}
+		if err := fn(records); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ImportTelemetry loads points with COPY into a temporary staging table, checks
+// that every device exists, then moves the rows into device_telemetry with the
+// requested ON CONFLICT behaviour. Duplicates inside the import itself are
+// collapsed, keeping the last occurrence.
+func (s *TimescaleStorage) ImportTelemetry(ctx context.Context, policy pb.ImportConflictPolicy, next func() ([]*TelemetryPoint, error)) (*ImportResult, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin import transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		CREATE TEMP TABLE import_staging (
+			seq         BIGSERIAL,
+			time        TIMESTAMPTZ NOT NULL,
+			device_id   UUID NOT NULL,
+			metric_name VARCHAR(100) NOT NULL,
+			value       DOUBLE PRECISION NOT NULL,
+			unit        VARCHAR(50),
+			metadata    JSONB
+		) ON COMMIT DROP
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging table: %w", err)
+	}
+
+	result := &ImportResult{}
+	columns := []string{"time", "device_id", "metric_name", "value", "unit", "metadata"}
+	for {
+		points, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		rows := make([][]any, 0, len(points))
+		for _, point := range points {
+			metadataJSON, err := json.Marshal(point.Metadata)
+			if err != nil {
+				metadataJSON = []byte("{}")
+			}
+
+			var unit *string
+			if point.Unit != "" {
+				unit = &point.Unit
+			}
+			rows = append(rows, []any{time.Unix(point.Timestamp, 0), point.DeviceID, point.MetricName, point.Value, unit, metadataJSON})
+
+			if result.Received == 0 || point.Timestamp < result.FromTime {
+				result.FromTime = point.Timestamp
+			}
+			if result.Received == 0 || point.Timestamp > result.ToTime {
+				result.ToTime = point.Timestamp
+			}
+			result.Received++
+		}
+
+		if _, err := tx.CopyFrom(ctx, pgx.Identifier{"import_staging"}, columns, pgx.CopyFromRows(rows)); err != nil {
+			return nil, fmt.Errorf("failed to copy telemetry: %w", err)
+		}
+	}
+
+	if result.Received == 0 {
+		return result, nil
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT DISTINCT s.device_id::text
+		FROM import_staging s
+		LEFT JOIN devices d ON d.id = s.device_id
+		WHERE d.id IS NULL
+		ORDER BY 1
+		LIMIT 20
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check devices: %w", err)
+	}
+	unknown, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to check devices: %w", err)
+	}
+	if len(unknown) > 0 {
+		return nil, &UnknownDevicesError{DeviceIDs: unknown}
+	}
+
+	var onConflict string
+	switch policy {
+	case pb.ImportConflictPolicy_IMPORT_CONFLICT_OVERWRITE:
+		onConflict = "ON CONFLICT (device_id, metric_name, time) DO UPDATE SET value = EXCLUDED.value, unit = EXCLUDED.unit, metadata = EXCLUDED.metadata"
+	case pb.ImportConflictPolicy_IMPORT_CONFLICT_FAIL:
+		onConflict = ""
+	default:
+		onConflict = "ON CONFLICT (device_id, metric_name, time) DO NOTHING"
+	}
+
+	tag, err := tx.Exec(ctx, fmt.Sprintf(`
+		INSERT INTO device_telemetry (time, device_id, metric_name, value, unit, metadata)
+		SELECT DISTINCT ON (device_id, metric_name, time)
+			time, device_id, metric_name, value, unit, COALESCE(metadata, '{}'::jsonb)
+		FROM import_staging
+		ORDER BY device_id, metric_name, time, seq DESC
+		%s
+	`, onConflict))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+			return nil, ErrImportConflict
+		}
+		return nil, fmt.Errorf("failed to insert imported telemetry: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+
+	result.Written = tag.RowsAffected()
+	result.Skipped = result.Received - result.Written
+	return result, nil
+}
+
+// uniqueViolation is the PostgreSQL SQLSTATE for unique_violation.
+const uniqueViolation = "23505"
+
+// continuousAggregates lists the continuous aggregates built on device_telemetry
+// with their bucket width, which refresh windows must be aligned to.
+var continuousAggregates = []struct {
+	view   string
+	bucket time.Duration
+}{
+	{view: "telemetry_hourly", bucket: time.Hour},
+	{view: "telemetry_daily", bucket: 24 * time.Hour},
+}
+
+// RefreshAggregates recomputes the continuous aggregates over [fromTime, toTime].
+// TimescaleDB only refreshes buckets fully inside the window, so the window is
+// widened to bucket boundaries. Must not run inside a transaction.
+func (s *TimescaleStorage) RefreshAggregates(ctx context.Context, fromTime, toTime int64) error {
+	for _, agg := range continuousAggregates {
+		start := time.Unix(fromTime, 0).UTC().Truncate(agg.bucket)
+		end := time.Unix(toTime, 0).UTC().Truncate(agg.bucket).Add(agg.bucket)
+
+		_, err := s.pool.Exec(ctx,
+			fmt.Sprintf("CALL refresh_continuous_aggregate('%s', $1::timestamptz, $2::timestamptz)", agg.view),
+			start, end)
+		if err != nil {
+			return fmt.Errorf("failed to refresh %s: %w", agg.view, err)
+		}
+	}
+	return nil
+}
+
 // GetLatestMetric retrieves the latest value for a specific metric.
 func (s *TimescaleStorage) GetLatestMetric(ctx context.Context, deviceID, metricName string) (*pb.TelemetryPoint, error) {
 	// Try to get from the latest cache table first
@@ -261,37 +806,31 @@ func (s *TimescaleStorage) GetLatestMetric(ctx context.Context, deviceID, metric
 	return point, nil
 }
 
-// GetDeviceMetrics retrieves all available metrics for a device.
-func (s *TimescaleStorage) GetDeviceMetrics(ctx context.Context, deviceID string) ([]string, error) {
-	rows, err := s.pool.Query(ctx, `
-		SELECT DISTINCT metric_name
-		FROM device_telemetry
-		WHERE device_id = $1
-		ORDER BY metric_name
-	`, deviceID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to query device metrics: %w", err)
-	}
-	defer rows.Close()
-
-	var metrics []string
-	for rows.Next() {
-		var metricName string
-		if err := rows.Scan(&metricName); err != nil {
-			return nil, fmt.Errorf("failed to scan row: %w", err)
-		}
-		metrics = append(metrics, metricName)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("row iteration error: %w", err)
-	}
-
-	return metrics, nil
-}
-
 // Close closes the storage connection.
 func (s *TimescaleStorage) Close() error {
 	s.pool.Close()
 	return nil
 }
+
+// validIntervals lists the time_bucket intervals accepted by aggregation
+// queries, with their width. Intervals are interpolated into SQL, so only
+// these values may be used.
+var validIntervals = map[string]time.Duration{
+	"1 minute":   time.Minute,
+	"5 minutes":  5 * time.Minute,
+	"15 minutes": 15 * time.Minute,
+	"30 minutes": 30 * time.Minute,
+	"1 hour":     time.Hour,
+	"6 hours":    6 * time.Hour,
+	"12 hours":   12 * time.Hour,
+	"1 day":      24 * time.Hour,
+	"1 week":     7 * 24 * time.Hour,
+}
+
+// normalizeInterval returns interval if it is allowed, or "1 hour" otherwise.
+func normalizeInterval(interval string) string {
+	if _, ok := validIntervals[interval]; !ok {
+		return "1 hour"
+	}
+	return interval
+}
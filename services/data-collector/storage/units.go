@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/iot-platform/services/data-collector/units"
+)
+
+// valueExpr returns the SQL expression of the value column of table alias t,
+// converted to unit target. Rows whose unit is not convertible to target
+// evaluate to NULL; samplesQuery counts them so that queries report them
+// instead of aggregating a shrunk series. An empty target returns the raw value.
+func valueExpr(t, target string) (string, error) {
+	return convertExpr(t+".value", t+".unit", target)
+}
+
+// convertExpr returns the SQL expression of value, measured in the unit held
+// by column unit, converted to target like valueExpr.
+func convertExpr(value, unitColumn, target string) (string, error) {
+	if target == "" {
+		return value, nil
+	}
+	unit, ok := units.Lookup(target)
+	if !ok {
+		return "", fmt.Errorf("%w %q", units.ErrUnknownUnit, target)
+	}
+
+	var expr strings.Builder
+	fmt.Fprintf(&expr, "CASE %s", unitColumn)
+	for _, name := range units.Names(unit.Dimension) {
+		a, b, err := units.Linear(name, target)
+		if err != nil {
+			return "", err
+		}
+		// Unit names come from the units table, never from the request
+		fmt.Fprintf(&expr, " WHEN '%s' THEN %s * %s + %s",
+			strings.ReplaceAll(name, "'", "''"), value,
+			strconv.FormatFloat(a, 'g', -1, 64), strconv.FormatFloat(b, 'g', -1, 64))
+	}
+	expr.WriteString(" END")
+	return expr.String(), nil
+}
@@ -0,0 +1,78 @@
+// +build unit
+
+package storage
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/iot-platform/services/data-collector/units"
+)
+
+func TestValueExpr(t *testing.T) {
+	expr, err := valueExpr("t", "")
+	if err != nil || expr != "t.value" {
+		t.Errorf(`valueExpr("t", "") = %q, %v; want the raw value`, expr, err)
+	}
+
+	expr, err = valueExpr("t", "°C")
+	if err != nil {
+		t.Fatalf("valueExpr failed: %v", err)
+	}
+	for _, want := range []string{
+		"CASE t.unit ",
+		"WHEN 'celsius' THEN t.value * 1 + 0",
+		"WHEN '°C' THEN t.value * 1 + 0",
+		"WHEN 'kelvin' THEN t.value * 1 + -273.15",
+		"WHEN 'fahrenheit' THEN t.value * 0.5555",
+	} {
+		if !strings.Contains(expr, want) {
+			t.Errorf("expression does not contain %q:\n%s", want, expr)
+		}
+	}
+	// Points in another unit evaluate to NULL, counted as unconverted
+	if !strings.HasSuffix(expr, " END") || strings.Contains(expr, "ELSE") {
+		t.Errorf("expected NULL for other units:\n%s", expr)
+	}
+	if strings.Contains(expr, "'hPa'") {
+		t.Errorf("units of another dimension must not be converted:\n%s", expr)
+	}
+
+	if _, err := valueExpr("t", "furlong"); !errors.Is(err, units.ErrUnknownUnit) {
+		t.Errorf("expected ErrUnknownUnit, got %v", err)
+	}
+}
+
+func TestConvertExpr_CoversEveryAlias(t *testing.T) {
+	expr, err := convertExpr("r.avg_value", "r.unit", "hPa")
+	if err != nil {
+		t.Fatalf("convertExpr failed: %v", err)
+	}
+	for _, name := range units.Names("pressure") {
+		if !strings.Contains(expr, "WHEN '"+name+"' THEN r.avg_value * ") {
+			t.Errorf("unit %q not converted:\n%s", name, expr)
+		}
+	}
+}
+
+// TestSamplesQuery_CountsUnconverted checks that points that cannot be
+// converted are counted, so that queries report them instead of shrinking
+func TestSamplesQuery_CountsUnconverted(t *testing.T) {
+	query, err := samplesQuery("1 hour", "celsius", "TRUE", "TRUE")
+	if err != nil {
+		t.Fatalf("samplesQuery failed: %v", err)
+	}
+	for _, want := range []string{"AS unconverted", "COUNT(*) - COUNT(CASE t.unit", "THEN r.sample_count ELSE 0 END"} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query does not contain %q:\n%s", want, query)
+		}
+	}
+}
+
+func TestUnconvertedError(t *testing.T) {
+	err := unconvertedError(3, "celsius")
+	if !errors.Is(err, units.ErrIncompatibleUnits) || !strings.Contains(err.Error(), "3 points") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+// Package units converts telemetry values between units of the same physical
+// quantity, e.g. fahrenheit to celsius or psi to hPa.
+//
+// Every unit is an affine function of its dimension's base unit, so any two
+// units of a dimension convert with value*a + b. Units are matched by exact
+// spelling against a list of aliases ("°C", "C", "celsius"...); each unit has
+// a canonical name, which is what the data-collector stores after normalization.
+package units
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Errors returned by conversions.
+var (
+	ErrUnknownUnit       = errors.New("unknown unit")
+	ErrIncompatibleUnits = errors.New("incompatible units")
+)
+
+// Unit is a unit of measurement.
+type Unit struct {
+	// Name is the canonical spelling of the unit.
+	Name string
+	// Dimension is the physical quantity measured, e.g. "temperature".
+	Dimension string
+
+	// A value v in this unit is v*scale + offset in the dimension's base unit.
+	scale  float64
+	offset float64
+}
+
+// definition declares a unit and its alternative spellings.
+type definition struct {
+	name      string
+	dimension string
+	scale     float64
+	offset    float64
+	aliases   []string
+}
+
+// definitions lists the supported units. The first unit of each dimension is
+// its default canonical unit.
+var definitions = []definition{
+	// Temperature (base: celsius)
+	{"celsius", "temperature", 1, 0, []string{"°C", "C", "degC", "Celsius"}},
+	{"fahrenheit", "temperature", 5.0 / 9, -32 * 5.0 / 9, []string{"°F", "F", "degF", "Fahrenheit"}},
+	{"kelvin", "temperature", 1, -273.15, []string{"K", "Kelvin"}},
+
+	// Pressure (base: hPa)
+	{"hPa", "pressure", 1, 0, []string{"hpa", "mbar", "millibar"}},
+	{"Pa", "pressure", 0.01, 0, []string{"pa"}},
+	{"kPa", "pressure", 10, 0, []string{"kpa"}},
+	{"bar", "pressure", 1000, 0, nil},
+	{"psi", "pressure", 68.9475729, 0, []string{"PSI"}},
+	{"atm", "pressure", 1013.25, 0, nil},
+	{"mmHg", "pressure", 1.33322387, 0, []string{"torr", "Torr"}},
+	{"inHg", "pressure", 33.8638866, 0, nil},
+
+	// Ratio (base: percent)
+	{"percent", "ratio", 1, 0, []string{"%", "pct"}},
+	{"fraction", "ratio", 100, 0, nil},
+
+	// Energy (base: Wh)
+	{"Wh", "energy", 1, 0, nil},
+	{"kWh", "energy", 1000, 0, []string{"kwh"}},
+	{"MWh", "energy", 1e6, 0, nil},
+	{"J", "energy", 1.0 / 3600, 0, []string{"joule"}},
+	{"kJ", "energy", 1.0 / 3.6, 0, nil},
+
+	// Power (base: W)
+	{"W", "power", 1, 0, []string{"watt"}},
+	{"mW", "power", 0.001, 0, nil},
+	{"kW", "power", 1000, 0, []string{"kw"}},
+
+	// Voltage (base: V)
+	{"V", "voltage", 1, 0, []string{"volt"}},
+	{"mV", "voltage", 0.001, 0, nil},
+	{"kV", "voltage", 1000, 0, nil},
+
+	// Current (base: A)
+	{"A", "current", 1, 0, []string{"amp", "ampere"}},
+	{"mA", "current", 0.001, 0, nil},
+
+	// Speed (base: m/s)
+	{"m/s", "speed", 1, 0, []string{"mps"}},
+	{"km/h", "speed", 1 / 3.6, 0, []string{"kph", "kmh"}},
+	{"mph", "speed", 0.44704, 0, nil},
+	{"kn", "speed", 0.514444, 0, []string{"knot", "knots", "kt"}},
+
+	// Length (base: m)
+	{"m", "length", 1, 0, []string{"meter", "metre"}},
+	{"mm", "length", 0.001, 0, nil},
+	{"cm", "length", 0.01, 0, nil},
+	{"km", "length", 1000, 0, nil},
+	{"in", "length", 0.0254, 0, []string{"inch"}},
+	{"ft", "length", 0.3048, 0, []string{"foot", "feet"}},
+
+	// Duration (base: s)
+	{"s", "duration", 1, 0, []string{"sec", "second", "seconds"}},
+	{"ms", "duration", 0.001, 0, nil},
+	{"min", "duration", 60, 0, []string{"minute", "minutes"}},
+	{"h", "duration", 3600, 0, []string{"hour", "hours"}},
+
+	// Gas concentration (base: ppm)
+	{"ppm", "concentration", 1, 0, nil},
+	{"ppb", "concentration", 0.001, 0, nil},
+
+	// Mass concentration (base: µg/m³)
+	{"µg/m³", "mass_concentration", 1, 0, []string{"ug/m3", "µg/m3", "ug/m³"}},
+	{"mg/m³", "mass_concentration", 1000, 0, []string{"mg/m3"}},
+
+	// Illuminance (base: lux)
+	{"lux", "illuminance", 1, 0, []string{"lx"}},
+}
+
+var (
+	// byName indexes units by canonical name and aliases.
+	byName = make(map[string]*Unit)
+	// canonical is the default canonical unit of each dimension.
+	canonical = make(map[string]*Unit)
+)
+
+func init() {
+	for _, def := range definitions {
+		unit := &Unit{Name: def.name, Dimension: def.dimension, scale: def.scale, offset: def.offset}
+		for _, name := range append([]string{def.name}, def.aliases...) {
+			if _, exists := byName[name]; exists {
+				panic(fmt.Sprintf("units: duplicate unit name %q", name))
+			}
+			byName[name] = unit
+		}
+		if canonical[def.dimension] == nil {
+			canonical[def.dimension] = unit
+		}
+	}
+}
+
+// Lookup returns the unit with this name or alias.
+func Lookup(name string) (*Unit, bool) {
+	unit, ok := byName[name]
+	return unit, ok
+}
+
+// Canonical returns the default canonical unit of the dimension of name.
+func Canonical(name string) (*Unit, bool) {
+	unit, ok := byName[name]
+	if !ok {
+		return nil, false
+	}
+	return canonical[unit.Dimension], true
+}
+
+// Same reports whether two names designate the same unit, e.g. "°C" and "celsius".
+// Unknown names are only equal to themselves.
+func Same(a, b string) bool {
+	if a == b {
+		return true
+	}
+	ua, okA := byName[a]
+	ub, okB := byName[b]
+	return okA && okB && ua == ub
+}
+
+// Linear returns a and b such that a value in unit from is value*a + b in unit to.
+func Linear(from, to string) (a, b float64, err error) {
+	uf, ok := byName[from]
+	if !ok {
+		return 0, 0, fmt.Errorf("%w %q", ErrUnknownUnit, from)
+	}
+	ut, ok := byName[to]
+	if !ok {
+		return 0, 0, fmt.Errorf("%w %q", ErrUnknownUnit, to)
+	}
+	if uf.Dimension != ut.Dimension {
+		return 0, 0, fmt.Errorf("%w: %s (%s) to %s (%s)", ErrIncompatibleUnits, from, uf.Dimension, to, ut.Dimension)
+	}
+	if uf == ut {
+		return 1, 0, nil
+	}
+	return uf.scale / ut.scale, (uf.offset - ut.offset) / ut.scale, nil
+}
+
+// Convert converts a value from one unit to another.
+func Convert(value float64, from, to string) (float64, error) {
+	a, b, err := Linear(from, to)
+	if err != nil {
+		return 0, err
+	}
+	return value*a + b, nil
+}
+
+// Names returns every name and alias of the units of a dimension, sorted.
+func Names(dimension string) []string {
+	var names []string
+	for name, unit := range byName {
+		if unit.Dimension == dimension {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,168 @@
+// +build unit
+
+package units
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		value    float64
+		from, to string
+		want     float64
+	}{
+		{0, "celsius", "fahrenheit", 32},
+		{100, "°C", "°F", 212},
+		{212, "F", "celsius", 100},
+		{-40, "fahrenheit", "celsius", -40},
+		{0, "kelvin", "celsius", -273.15},
+		{25, "celsius", "K", 298.15},
+		{32, "degF", "kelvin", 273.15},
+		{1, "bar", "hPa", 1000},
+		{1, "atm", "Pa", 101325},
+		{14.6959488, "psi", "atm", 1},
+		{760, "mmHg", "atm", 1},
+		{1013.25, "mbar", "kPa", 101.325},
+		{0.5, "fraction", "%", 50},
+		{1, "kWh", "J", 3.6e6},
+		{1, "MWh", "kWh", 1000},
+		{1500, "W", "kW", 1.5},
+		{3300, "mV", "V", 3.3},
+		{250, "mA", "A", 0.25},
+		{36, "km/h", "m/s", 10},
+		{1, "mph", "km/h", 1.609344},
+		{1, "ft", "in", 12},
+		{2.54, "cm", "in", 1},
+		{1, "h", "min", 60},
+		{1500, "ms", "s", 1.5},
+		{1, "ppm", "ppb", 1000},
+		{1, "mg/m³", "ug/m3", 1000},
+		{42, "lux", "lx", 42},
+	}
+	for _, tt := range tests {
+		got, err := Convert(tt.value, tt.from, tt.to)
+		if err != nil {
+			t.Errorf("Convert(%v, %s, %s) failed: %v", tt.value, tt.from, tt.to, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-6*math.Max(1, math.Abs(tt.want)) {
+			t.Errorf("Convert(%v, %s, %s) = %v, want %v", tt.value, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestConvert_Errors(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     error
+	}{
+		{"celsius", "hPa", ErrIncompatibleUnits},
+		{"W", "Wh", ErrIncompatibleUnits},
+		{"ppm", "µg/m³", ErrIncompatibleUnits},
+		{"furlong", "m", ErrUnknownUnit},
+		{"m", "furlong", ErrUnknownUnit},
+		{"", "celsius", ErrUnknownUnit},
+		{"CELSIUS", "celsius", ErrUnknownUnit}, // exact spelling only
+	}
+	for _, tt := range tests {
+		if _, err := Convert(1, tt.from, tt.to); !errors.Is(err, tt.want) {
+			t.Errorf("Convert(1, %q, %q) error = %v, want %v", tt.from, tt.to, err, tt.want)
+		}
+	}
+}
+
+// TestDefinitions_RoundTrip converts a value between every pair of units of a
+// dimension and back, which catches a wrong scale or offset in the table
+func TestDefinitions_RoundTrip(t *testing.T) {
+	for _, from := range definitions {
+		for _, to := range definitions {
+			if from.dimension != to.dimension {
+				continue
+			}
+			there, err := Convert(123.456, from.name, to.name)
+			if err != nil {
+				t.Fatalf("Convert(%s, %s) failed: %v", from.name, to.name, err)
+			}
+			back, err := Convert(there, to.name, from.name)
+			if err != nil {
+				t.Fatalf("Convert(%s, %s) failed: %v", to.name, from.name, err)
+			}
+			if math.Abs(back-123.456) > 1e-9 {
+				t.Errorf("%s -> %s -> %s: got %v", from.name, to.name, from.name, back)
+			}
+		}
+		if from.scale <= 0 {
+			t.Errorf("%s: scale must be positive, got %v", from.name, from.scale)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	for _, name := range []string{"°C", "C", "degC", "Celsius", "celsius"} {
+		unit, ok := Lookup(name)
+		if !ok || unit.Name != "celsius" || unit.Dimension != "temperature" {
+			t.Errorf("Lookup(%q) = %+v, %v", name, unit, ok)
+		}
+	}
+	if _, ok := Lookup("c"); ok {
+		t.Error(`Lookup("c") should not match celsius`)
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	tests := map[string]string{
+		"°F":    "celsius",
+		"psi":   "hPa",
+		"%":     "percent",
+		"kWh":   "Wh",
+		"mph":   "m/s",
+		"ug/m3": "µg/m³",
+	}
+	for name, want := range tests {
+		unit, ok := Canonical(name)
+		if !ok || unit.Name != want {
+			t.Errorf("Canonical(%q) = %+v, %v; want %s", name, unit, ok, want)
+		}
+	}
+	if _, ok := Canonical("furlong"); ok {
+		t.Error("Canonical of an unknown unit should fail")
+	}
+}
+
+func TestSame(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"°C", "celsius", true},
+		{"mbar", "hPa", true},
+		{"celsius", "kelvin", false},
+		{"RH", "RH", true}, // unknown names are equal to themselves
+		{"RH", "%", false},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		if got := Same(tt.a, tt.b); got != tt.want {
+			t.Errorf("Same(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	names := Names("ratio")
+	want := []string{"%", "fraction", "pct", "percent"}
+	if len(names) != len(want) {
+		t.Fatalf("Names(ratio) = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("Names(ratio) = %v, want %v", names, want)
+		}
+	}
+	if names := Names("flux capacitance"); len(names) != 0 {
+		t.Errorf("Names of an unknown dimension = %v", names)
+	}
+}
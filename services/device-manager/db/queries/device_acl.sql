@@ -0,0 +1,43 @@
+-- IoT Platform - Per-device access control lists
+-- A NULL org_id selects the entries of every organization (internal calls)
+
+-- name: CreateDeviceAclEntry :one
+INSERT INTO device_acl_entries (
+    org_id,
+    user_id,
+    role,
+    device_id,
+    group_id,
+    access
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- Filters are optional
+-- name: ListDeviceAclEntries :many
+SELECT * FROM device_acl_entries
+WHERE (sqlc.narg(org_id)::uuid IS NULL OR org_id = sqlc.narg(org_id))
+  AND (sqlc.narg(user_id)::uuid IS NULL OR user_id = sqlc.narg(user_id))
+  AND (sqlc.narg(role)::text IS NULL OR role = sqlc.narg(role))
+  AND (sqlc.narg(device_id)::uuid IS NULL OR device_id = sqlc.narg(device_id))
+  AND (sqlc.narg(group_id)::uuid IS NULL OR group_id = sqlc.narg(group_id))
+ORDER BY created_at;
+
+-- name: DeleteDeviceAclEntry :execrows
+DELETE FROM device_acl_entries
+WHERE id = sqlc.arg(id)
+  AND (sqlc.narg(org_id)::uuid IS NULL OR org_id = sqlc.narg(org_id));
+
+-- Entries of a user, directly or through their role
+-- name: CountSubjectAclEntries :one
+SELECT COUNT(*) FROM device_acl_entries
+WHERE org_id = sqlc.arg(org_id)
+  AND (user_id = sqlc.narg(user_id) OR role = sqlc.narg(role));
+
+-- Devices among device_ids granted to a user with one of the access levels
+-- name: ListSubjectAclDevices :many
+SELECT DISTINCT device_id FROM device_acl_devices
+WHERE org_id = sqlc.arg(org_id)
+  AND (user_id = sqlc.narg(user_id) OR role = sqlc.narg(role))
+  AND access = ANY(sqlc.arg(accesses)::text[])
+  AND device_id = ANY(sqlc.arg(device_ids)::uuid[]);
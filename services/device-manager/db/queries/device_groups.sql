@@ -0,0 +1,69 @@
+-- IoT Platform - Device groups, static or rule-based
+-- A NULL org_id selects the groups of every organization (internal calls)
+
+-- name: CreateDeviceGroup :one
+INSERT INTO device_groups (
+    org_id,
+    name,
+    description,
+    kind,
+    rule_type,
+    rule_metadata
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetDeviceGroup :one
+SELECT * FROM device_groups
+WHERE id = sqlc.arg(id)
+  AND (sqlc.narg(org_id)::uuid IS NULL OR org_id = sqlc.narg(org_id));
+
+-- name: ListDeviceGroups :many
+SELECT * FROM device_groups
+WHERE (sqlc.narg(org_id)::uuid IS NULL OR org_id = sqlc.narg(org_id))
+  AND (sqlc.narg(device_id)::uuid IS NULL OR id IN (
+        SELECT group_id FROM device_group_devices WHERE device_id = sqlc.narg(device_id)))
+ORDER BY name;
+
+-- name: UpdateDeviceGroup :one
+UPDATE device_groups
+SET
+    name = sqlc.arg(name),
+    description = sqlc.narg(description),
+    rule_type = sqlc.narg(rule_type),
+    rule_metadata = sqlc.arg(rule_metadata),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+  AND (sqlc.narg(org_id)::uuid IS NULL OR org_id = sqlc.narg(org_id))
+RETURNING *;
+
+-- name: DeleteDeviceGroup :execrows
+DELETE FROM device_groups
+WHERE id = sqlc.arg(id)
+  AND (sqlc.narg(org_id)::uuid IS NULL OR org_id = sqlc.narg(org_id));
+
+-- name: CountDeviceGroupDevices :many
+SELECT group_id, COUNT(*) AS device_count FROM device_group_devices
+WHERE group_id = ANY(sqlc.arg(group_ids)::uuid[])
+GROUP BY group_id;
+
+-- Only devices of the organization of the group are added
+-- name: AddDeviceGroupMembers :execrows
+INSERT INTO device_group_members (group_id, device_id)
+SELECT g.id, d.id
+FROM device_groups g
+JOIN devices d ON d.org_id = g.org_id
+WHERE g.id = sqlc.arg(group_id)
+  AND d.id = ANY(sqlc.arg(device_ids)::uuid[])
+ON CONFLICT DO NOTHING;
+
+-- name: CountGroupOrgDevices :one
+SELECT COUNT(*) FROM devices d
+JOIN device_groups g ON g.org_id = d.org_id
+WHERE g.id = sqlc.arg(group_id)
+  AND d.id = ANY(sqlc.arg(device_ids)::uuid[]);
+
+-- name: RemoveDeviceGroupMembers :execrows
+DELETE FROM device_group_members
+WHERE group_id = sqlc.arg(group_id)
+  AND device_id = ANY(sqlc.arg(device_ids)::uuid[]);
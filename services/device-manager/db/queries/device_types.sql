@@ -0,0 +1,54 @@
+-- IoT Platform - Device type registry and metric catalog
+
+-- name: UpsertDeviceType :one
+INSERT INTO device_types (
+    name,
+    display_name,
+    description
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (name) DO UPDATE SET
+    display_name = EXCLUDED.display_name,
+    description = EXCLUDED.description,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetDeviceType :one
+SELECT * FROM device_types
+WHERE name = $1;
+
+-- name: ListDeviceTypes :many
+SELECT * FROM device_types
+ORDER BY name;
+
+-- name: DeleteDeviceType :execrows
+DELETE FROM device_types
+WHERE name = $1;
+
+-- name: InsertDeviceTypeMetric :exec
+INSERT INTO device_type_metrics (
+    device_type,
+    name,
+    display_name,
+    unit,
+    data_type,
+    min_value,
+    max_value,
+    reporting_interval_seconds
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+);
+
+-- name: DeleteDeviceTypeMetrics :exec
+DELETE FROM device_type_metrics
+WHERE device_type = $1;
+
+-- name: ListDeviceTypeMetrics :many
+SELECT * FROM device_type_metrics
+WHERE device_type = $1
+ORDER BY name;
+
+-- name: ListAllDeviceTypeMetrics :many
+SELECT * FROM device_type_metrics
+ORDER BY device_type, name;
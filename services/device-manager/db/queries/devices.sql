@@ -1,5 +1,6 @@
 -- IoT Platform - Device Manager Queries
 -- SQL queries with sqlc annotations for type-safe code generation
+-- A NULL org_id selects the devices of every organization (internal calls)
 
 -- name: CreateDevice :one
 INSERT INTO devices (
@@ -9,22 +10,61 @@ INSERT INTO devices (
     status,
     created_at,
     last_seen,
-    metadata
+    metadata,
+    org_id,
+    location_id
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
 ) RETURNING *;
 
 -- name: GetDevice :one
 SELECT * FROM devices
-WHERE id = $1;
+WHERE id = sqlc.arg(id)
+  AND (sqlc.narg(org_id)::uuid IS NULL OR org_id = sqlc.narg(org_id));
 
+-- Filters are optional; location_id also selects the devices of sub-locations.
+-- acl_user_id and acl_role restrict to the devices granted by their ACL
+-- entries in the organization, if they have any
 -- name: ListDevices :many
 SELECT * FROM devices
+WHERE (sqlc.narg(org_id)::uuid IS NULL OR org_id = sqlc.narg(org_id))
+  AND (sqlc.narg(type)::text IS NULL OR type = sqlc.narg(type))
+  AND (sqlc.narg(status)::device_status IS NULL OR status = sqlc.narg(status))
+  AND (sqlc.narg(group_id)::uuid IS NULL OR id IN (
+        SELECT device_id FROM device_group_devices WHERE group_id = sqlc.narg(group_id)))
+  AND (sqlc.narg(location_id)::uuid IS NULL OR location_id IN (
+        SELECT location_id FROM location_ancestors WHERE ancestor_id = sqlc.narg(location_id)))
+  AND ((sqlc.narg(acl_user_id)::uuid IS NULL AND sqlc.narg(acl_role)::text IS NULL)
+    OR NOT EXISTS (
+        SELECT 1 FROM device_acl_entries
+        WHERE org_id = sqlc.narg(org_id)
+          AND (user_id = sqlc.narg(acl_user_id) OR role = sqlc.narg(acl_role)))
+    OR id IN (
+        SELECT device_id FROM device_acl_devices
+        WHERE org_id = sqlc.narg(org_id)
+          AND (user_id = sqlc.narg(acl_user_id) OR role = sqlc.narg(acl_role))))
 ORDER BY created_at DESC
-LIMIT $1 OFFSET $2;
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
 
+-- Same filters as ListDevices, ACL included, so that totals match the pages
 -- name: CountDevices :one
-SELECT COUNT(*) FROM devices;
+SELECT COUNT(*) FROM devices
+WHERE (sqlc.narg(org_id)::uuid IS NULL OR org_id = sqlc.narg(org_id))
+  AND (sqlc.narg(type)::text IS NULL OR type = sqlc.narg(type))
+  AND (sqlc.narg(status)::device_status IS NULL OR status = sqlc.narg(status))
+  AND (sqlc.narg(group_id)::uuid IS NULL OR id IN (
+        SELECT device_id FROM device_group_devices WHERE group_id = sqlc.narg(group_id)))
+  AND (sqlc.narg(location_id)::uuid IS NULL OR location_id IN (
+        SELECT location_id FROM location_ancestors WHERE ancestor_id = sqlc.narg(location_id)))
+  AND ((sqlc.narg(acl_user_id)::uuid IS NULL AND sqlc.narg(acl_role)::text IS NULL)
+    OR NOT EXISTS (
+        SELECT 1 FROM device_acl_entries
+        WHERE org_id = sqlc.narg(org_id)
+          AND (user_id = sqlc.narg(acl_user_id) OR role = sqlc.narg(acl_role)))
+    OR id IN (
+        SELECT device_id FROM device_acl_devices
+        WHERE org_id = sqlc.narg(org_id)
+          AND (user_id = sqlc.narg(acl_user_id) OR role = sqlc.narg(acl_role))));
 
 -- name: UpdateDevice :one
 UPDATE devices
@@ -32,13 +72,16 @@ SET
     name = sqlc.arg(name),
     status = sqlc.arg(status),
     metadata = sqlc.arg(metadata),
-    last_seen = sqlc.arg(last_seen)
+    last_seen = sqlc.arg(last_seen),
+    location_id = sqlc.narg(location_id)
 WHERE id = sqlc.arg(id)
+  AND (sqlc.narg(org_id)::uuid IS NULL OR org_id = sqlc.narg(org_id))
 RETURNING *;
 
--- name: DeleteDevice :exec
+-- name: DeleteDevice :execrows
 DELETE FROM devices
-WHERE id = $1;
+WHERE id = sqlc.arg(id)
+  AND (sqlc.narg(org_id)::uuid IS NULL OR org_id = sqlc.narg(org_id));
 
 -- name: ListDevicesByType :many
 SELECT * FROM devices
@@ -0,0 +1,35 @@
+-- IoT Platform - Location hierarchy (site > building > floor > room)
+-- A NULL org_id selects the locations of every organization (internal calls)
+
+-- name: CreateLocation :one
+INSERT INTO locations (
+    org_id,
+    parent_id,
+    kind,
+    name
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetLocation :one
+SELECT * FROM locations
+WHERE id = sqlc.arg(id)
+  AND (sqlc.narg(org_id)::uuid IS NULL OR org_id = sqlc.narg(org_id));
+
+-- name: ListLocations :many
+SELECT * FROM locations
+WHERE (sqlc.narg(org_id)::uuid IS NULL OR org_id = sqlc.narg(org_id))
+  AND (sqlc.narg(parent_id)::uuid IS NULL OR parent_id = sqlc.narg(parent_id))
+ORDER BY name, id;
+
+-- name: UpdateLocation :one
+UPDATE locations
+SET name = sqlc.arg(name)
+WHERE id = sqlc.arg(id)
+  AND (sqlc.narg(org_id)::uuid IS NULL OR org_id = sqlc.narg(org_id))
+RETURNING *;
+
+-- name: DeleteLocation :execrows
+DELETE FROM locations
+WHERE id = sqlc.arg(id)
+  AND (sqlc.narg(org_id)::uuid IS NULL OR org_id = sqlc.narg(org_id));
@@ -12,11 +12,46 @@ import (
 )
 
 const countDevices = `-- name: CountDevices :one
+
 SELECT COUNT(*) FROM devices
+WHERE ($1::uuid IS NULL OR org_id = $1)
+  AND ($2::text IS NULL OR type = $2)
+  AND ($3::device_status IS NULL OR status = $3)
+  AND ($4::uuid IS NULL OR id IN (
+        SELECT device_id FROM device_group_devices WHERE group_id = $4))
+  AND ($5::uuid IS NULL OR location_id IN (
+        SELECT location_id FROM location_ancestors WHERE ancestor_id = $5))
+  AND (($6::uuid IS NULL AND $7::text IS NULL)
+    OR NOT EXISTS (
+        SELECT 1 FROM device_acl_entries
+        WHERE org_id = $1
+          AND (user_id = $6 OR role = $7))
+    OR id IN (
+        SELECT device_id FROM device_acl_devices
+        WHERE org_id = $1
+          AND (user_id = $6 OR role = $7)))
 `
 
-func (q *Queries) CountDevices(ctx context.Context) (int64, error) {
-	row := q.db.QueryRow(ctx, countDevices)
+type CountDevicesParams struct {
+	OrgID      pgtype.UUID      `json:"org_id"`
+	Type       *string          `json:"type"`
+	Status     NullDeviceStatus `json:"status"`
+	GroupID    pgtype.UUID      `json:"group_id"`
+	LocationID pgtype.UUID      `json:"location_id"`
+	AclUserID  pgtype.UUID      `json:"acl_user_id"`
+	AclRole    *string          `json:"acl_role"`
+}
+
+func (q *Queries) CountDevices(ctx context.Context, arg CountDevicesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countDevices,
+		arg.OrgID,
+		arg.Type,
+		arg.Status,
+		arg.GroupID,
+		arg.LocationID,
+		arg.AclUserID,
+		arg.AclRole,
+	)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -43,24 +78,29 @@ INSERT INTO devices (
     status,
     created_at,
     last_seen,
-    metadata
+    metadata,
+    org_id,
+    location_id
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
-) RETURNING id, name, type, status, created_at, last_seen, metadata
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING id, name, type, status, created_at, last_seen, metadata, org_id, location_id
 `
 
 type CreateDeviceParams struct {
-	ID        pgtype.UUID        `json:"id"`
-	Name      string             `json:"name"`
-	Type      string             `json:"type"`
-	Status    DeviceStatus       `json:"status"`
-	CreatedAt pgtype.Timestamptz `json:"created_at"`
-	LastSeen  pgtype.Timestamptz `json:"last_seen"`
-	Metadata  []byte             `json:"metadata"`
+	ID         pgtype.UUID        `json:"id"`
+	Name       string             `json:"name"`
+	Type       string             `json:"type"`
+	Status     DeviceStatus       `json:"status"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	LastSeen   pgtype.Timestamptz `json:"last_seen"`
+	Metadata   []byte             `json:"metadata"`
+	OrgID      pgtype.UUID        `json:"org_id"`
+	LocationID pgtype.UUID        `json:"location_id"`
 }
 
 // IoT Platform - Device Manager Queries
 // SQL queries with sqlc annotations for type-safe code generation
+// A NULL org_id selects the devices of every organization (internal calls)
 func (q *Queries) CreateDevice(ctx context.Context, arg CreateDeviceParams) (Device, error) {
 	row := q.db.QueryRow(ctx, createDevice,
 		arg.ID,
@@ -70,6 +110,8 @@ func (q *Queries) CreateDevice(ctx context.Context, arg CreateDeviceParams) (Dev
 		arg.CreatedAt,
 		arg.LastSeen,
 		arg.Metadata,
+		arg.OrgID,
+		arg.LocationID,
 	)
 	var i Device
 	err := row.Scan(
@@ -80,27 +122,44 @@ func (q *Queries) CreateDevice(ctx context.Context, arg CreateDeviceParams) (Dev
 		&i.CreatedAt,
 		&i.LastSeen,
 		&i.Metadata,
+		&i.OrgID,
+		&i.LocationID,
 	)
 	return i, err
 }
 
-const deleteDevice = `-- name: DeleteDevice :exec
+const deleteDevice = `-- name: DeleteDevice :execrows
 DELETE FROM devices
 WHERE id = $1
+  AND ($2::uuid IS NULL OR org_id = $2)
 `
 
-func (q *Queries) DeleteDevice(ctx context.Context, id pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, deleteDevice, id)
-	return err
+type DeleteDeviceParams struct {
+	ID    pgtype.UUID `json:"id"`
+	OrgID pgtype.UUID `json:"org_id"`
+}
+
+func (q *Queries) DeleteDevice(ctx context.Context, arg DeleteDeviceParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteDevice, arg.ID, arg.OrgID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const getDevice = `-- name: GetDevice :one
-SELECT id, name, type, status, created_at, last_seen, metadata FROM devices
+SELECT id, name, type, status, created_at, last_seen, metadata, org_id, location_id FROM devices
 WHERE id = $1
+  AND ($2::uuid IS NULL OR org_id = $2)
 `
 
-func (q *Queries) GetDevice(ctx context.Context, id pgtype.UUID) (Device, error) {
-	row := q.db.QueryRow(ctx, getDevice, id)
+type GetDeviceParams struct {
+	ID    pgtype.UUID `json:"id"`
+	OrgID pgtype.UUID `json:"org_id"`
+}
+
+func (q *Queries) GetDevice(ctx context.Context, arg GetDeviceParams) (Device, error) {
+	row := q.db.QueryRow(ctx, getDevice, arg.ID, arg.OrgID)
 	var i Device
 	err := row.Scan(
 		&i.ID,
@@ -110,23 +169,62 @@ func (q *Queries) GetDevice(ctx context.Context, id pgtype.UUID) (Device, error)
 		&i.CreatedAt,
 		&i.LastSeen,
 		&i.Metadata,
+		&i.OrgID,
+		&i.LocationID,
 	)
 	return i, err
 }
 
 const listDevices = `-- name: ListDevices :many
-SELECT id, name, type, status, created_at, last_seen, metadata FROM devices
+
+SELECT id, name, type, status, created_at, last_seen, metadata, org_id, location_id FROM devices
+WHERE ($1::uuid IS NULL OR org_id = $1)
+  AND ($2::text IS NULL OR type = $2)
+  AND ($3::device_status IS NULL OR status = $3)
+  AND ($4::uuid IS NULL OR id IN (
+        SELECT device_id FROM device_group_devices WHERE group_id = $4))
+  AND ($5::uuid IS NULL OR location_id IN (
+        SELECT location_id FROM location_ancestors WHERE ancestor_id = $5))
+  AND (($6::uuid IS NULL AND $7::text IS NULL)
+    OR NOT EXISTS (
+        SELECT 1 FROM device_acl_entries
+        WHERE org_id = $1
+          AND (user_id = $6 OR role = $7))
+    OR id IN (
+        SELECT device_id FROM device_acl_devices
+        WHERE org_id = $1
+          AND (user_id = $6 OR role = $7)))
 ORDER BY created_at DESC
-LIMIT $1 OFFSET $2
+LIMIT $8 OFFSET $9
 `
 
 type ListDevicesParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	OrgID      pgtype.UUID      `json:"org_id"`
+	Type       *string          `json:"type"`
+	Status     NullDeviceStatus `json:"status"`
+	GroupID    pgtype.UUID      `json:"group_id"`
+	LocationID pgtype.UUID      `json:"location_id"`
+	AclUserID  pgtype.UUID      `json:"acl_user_id"`
+	AclRole    *string          `json:"acl_role"`
+	PageLimit  int32            `json:"page_limit"`
+	PageOffset int32            `json:"page_offset"`
 }
 
+// Filters are optional; location_id also selects the devices of sub-locations.
+// acl_user_id and acl_role restrict to the devices granted by their ACL
+// entries in the organization, if they have any
 func (q *Queries) ListDevices(ctx context.Context, arg ListDevicesParams) ([]Device, error) {
-	rows, err := q.db.Query(ctx, listDevices, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listDevices,
+		arg.OrgID,
+		arg.Type,
+		arg.Status,
+		arg.GroupID,
+		arg.LocationID,
+		arg.AclUserID,
+		arg.AclRole,
+		arg.PageLimit,
+		arg.PageOffset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -142,6 +240,8 @@ func (q *Queries) ListDevices(ctx context.Context, arg ListDevicesParams) ([]Dev
 			&i.CreatedAt,
 			&i.LastSeen,
 			&i.Metadata,
+			&i.OrgID,
+			&i.LocationID,
 		); err != nil {
 			return nil, err
 		}
@@ -154,7 +254,7 @@ func (q *Queries) ListDevices(ctx context.Context, arg ListDevicesParams) ([]Dev
 }
 
 const listDevicesByStatus = `-- name: ListDevicesByStatus :many
-SELECT id, name, type, status, created_at, last_seen, metadata FROM devices
+SELECT id, name, type, status, created_at, last_seen, metadata, org_id, location_id FROM devices
 WHERE status = $1
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
@@ -183,6 +283,8 @@ func (q *Queries) ListDevicesByStatus(ctx context.Context, arg ListDevicesByStat
 			&i.CreatedAt,
 			&i.LastSeen,
 			&i.Metadata,
+			&i.OrgID,
+			&i.LocationID,
 		); err != nil {
 			return nil, err
 		}
@@ -195,7 +297,7 @@ func (q *Queries) ListDevicesByStatus(ctx context.Context, arg ListDevicesByStat
 }
 
 const listDevicesByType = `-- name: ListDevicesByType :many
-SELECT id, name, type, status, created_at, last_seen, metadata FROM devices
+SELECT id, name, type, status, created_at, last_seen, metadata, org_id, location_id FROM devices
 WHERE type = $1
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
@@ -224,6 +326,8 @@ func (q *Queries) ListDevicesByType(ctx context.Context, arg ListDevicesByTypePa
 			&i.CreatedAt,
 			&i.LastSeen,
 			&i.Metadata,
+			&i.OrgID,
+			&i.LocationID,
 		); err != nil {
 			return nil, err
 		}
@@ -241,17 +345,21 @@ SET
     name = $1,
     status = $2,
     metadata = $3,
-    last_seen = $4
-WHERE id = $5
-RETURNING id, name, type, status, created_at, last_seen, metadata
+    last_seen = $4,
+    location_id = $5
+WHERE id = $6
+  AND ($7::uuid IS NULL OR org_id = $7)
+RETURNING id, name, type, status, created_at, last_seen, metadata, org_id, location_id
 `
 
 type UpdateDeviceParams struct {
-	Name     string             `json:"name"`
-	Status   DeviceStatus       `json:"status"`
-	Metadata []byte             `json:"metadata"`
-	LastSeen pgtype.Timestamptz `json:"last_seen"`
-	ID       pgtype.UUID        `json:"id"`
+	Name       string             `json:"name"`
+	Status     DeviceStatus       `json:"status"`
+	Metadata   []byte             `json:"metadata"`
+	LastSeen   pgtype.Timestamptz `json:"last_seen"`
+	LocationID pgtype.UUID        `json:"location_id"`
+	ID         pgtype.UUID        `json:"id"`
+	OrgID      pgtype.UUID        `json:"org_id"`
 }
 
 func (q *Queries) UpdateDevice(ctx context.Context, arg UpdateDeviceParams) (Device, error) {
@@ -260,7 +368,9 @@ func (q *Queries) UpdateDevice(ctx context.Context, arg UpdateDeviceParams) (Dev
 		arg.Status,
 		arg.Metadata,
 		arg.LastSeen,
+		arg.LocationID,
 		arg.ID,
+		arg.OrgID,
 	)
 	var i Device
 	err := row.Scan(
@@ -271,6 +381,8 @@ func (q *Queries) UpdateDevice(ctx context.Context, arg UpdateDeviceParams) (Dev
 		&i.CreatedAt,
 		&i.LastSeen,
 		&i.Metadata,
+		&i.OrgID,
+		&i.LocationID,
 	)
 	return i, err
 }
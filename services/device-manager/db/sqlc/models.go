@@ -72,6 +72,74 @@ type Device struct {
 	LastSeen pgtype.Timestamptz `json:"last_seen"`
 	// Flexible key-value storage for device-specific data
 	Metadata []byte `json:"metadata"`
+	// Organization owning the device
+	OrgID pgtype.UUID `json:"org_id"`
+	// Location of the device, usually a room
+	LocationID pgtype.UUID `json:"location_id"`
+}
+
+// Access of users and roles to devices and device groups
+type DeviceAclEntry struct {
+	ID     pgtype.UUID `json:"id"`
+	OrgID  pgtype.UUID `json:"org_id"`
+	UserID pgtype.UUID `json:"user_id"`
+	// Members of the organization with this role
+	Role     *string     `json:"role"`
+	DeviceID pgtype.UUID `json:"device_id"`
+	GroupID  pgtype.UUID `json:"group_id"`
+	// read, write (implies read) or command (implies read)
+	Access    string             `json:"access"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+// Named sets of devices, static or rule-based
+type DeviceGroup struct {
+	ID          pgtype.UUID `json:"id"`
+	OrgID       pgtype.UUID `json:"org_id"`
+	Name        string      `json:"name"`
+	Description *string     `json:"description"`
+	Kind        string      `json:"kind"`
+	// Dynamic groups: device type to match (any if NULL)
+	RuleType *string `json:"rule_type"`
+	// Dynamic groups: metadata entries devices must contain
+	RuleMetadata []byte             `json:"rule_metadata"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
+}
+
+// Registered device types
+type DeviceType struct {
+	// Type identifier, as stored in devices.type
+	Name        string             `json:"name"`
+	DisplayName string             `json:"display_name"`
+	Description *string            `json:"description"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
+// Metrics expected from devices of a type
+type DeviceTypeMetric struct {
+	DeviceType  string  `json:"device_type"`
+	Name        string  `json:"name"`
+	DisplayName string  `json:"display_name"`
+	Unit        *string `json:"unit"`
+	// Value type: float, integer or boolean (0/1)
+	DataType string   `json:"data_type"`
+	MinValue *float64 `json:"min_value"`
+	MaxValue *float64 `json:"max_value"`
+	// Expected time between two points
+	ReportingIntervalSeconds *int32 `json:"reporting_interval_seconds"`
+}
+
+// Location hierarchy: site > building > floor > room
+type Location struct {
+	ID       pgtype.UUID `json:"id"`
+	OrgID    pgtype.UUID `json:"org_id"`
+	ParentID pgtype.UUID `json:"parent_id"`
+	// Level in the hierarchy: site, building, floor or room
+	Kind      string             `json:"kind"`
+	Name      string             `json:"name"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
 // User accounts for authentication and authorization
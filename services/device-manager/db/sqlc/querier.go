@@ -11,17 +11,59 @@ import (
 )
 
 type Querier interface {
-	CountDevices(ctx context.Context) (int64, error)
+	// Only devices of the organization of the group are added
+	AddDeviceGroupMembers(ctx context.Context, arg AddDeviceGroupMembersParams) (int64, error)
+	CountDeviceGroupDevices(ctx context.Context, groupIds []pgtype.UUID) ([]CountDeviceGroupDevicesRow, error)
+	CountDevices(ctx context.Context, arg CountDevicesParams) (int64, error)
 	CountDevicesByStatus(ctx context.Context, status DeviceStatus) (int64, error)
+	CountGroupOrgDevices(ctx context.Context, arg CountGroupOrgDevicesParams) (int64, error)
+	// Entries of a user, directly or through their role
+	CountSubjectAclEntries(ctx context.Context, arg CountSubjectAclEntriesParams) (int64, error)
 	// IoT Platform - Device Manager Queries
 	// SQL queries with sqlc annotations for type-safe code generation
+	// A NULL org_id selects the devices of every organization (internal calls)
 	CreateDevice(ctx context.Context, arg CreateDeviceParams) (Device, error)
-	DeleteDevice(ctx context.Context, id pgtype.UUID) error
-	GetDevice(ctx context.Context, id pgtype.UUID) (Device, error)
+	// IoT Platform - Per-device access control lists
+	// A NULL org_id selects the entries of every organization (internal calls)
+	CreateDeviceAclEntry(ctx context.Context, arg CreateDeviceAclEntryParams) (DeviceAclEntry, error)
+	// IoT Platform - Device groups, static or rule-based
+	// A NULL org_id selects the groups of every organization (internal calls)
+	CreateDeviceGroup(ctx context.Context, arg CreateDeviceGroupParams) (DeviceGroup, error)
+	// IoT Platform - Location hierarchy (site > building > floor > room)
+	// A NULL org_id selects the locations of every organization (internal calls)
+	CreateLocation(ctx context.Context, arg CreateLocationParams) (Location, error)
+	DeleteDevice(ctx context.Context, arg DeleteDeviceParams) (int64, error)
+	DeleteDeviceAclEntry(ctx context.Context, arg DeleteDeviceAclEntryParams) (int64, error)
+	DeleteDeviceGroup(ctx context.Context, arg DeleteDeviceGroupParams) (int64, error)
+	DeleteDeviceType(ctx context.Context, name string) (int64, error)
+	DeleteDeviceTypeMetrics(ctx context.Context, deviceType string) error
+	DeleteLocation(ctx context.Context, arg DeleteLocationParams) (int64, error)
+	GetDevice(ctx context.Context, arg GetDeviceParams) (Device, error)
+	GetDeviceGroup(ctx context.Context, arg GetDeviceGroupParams) (DeviceGroup, error)
+	GetDeviceType(ctx context.Context, name string) (DeviceType, error)
+	GetLocation(ctx context.Context, arg GetLocationParams) (Location, error)
+	InsertDeviceTypeMetric(ctx context.Context, arg InsertDeviceTypeMetricParams) error
+	ListAllDeviceTypeMetrics(ctx context.Context) ([]DeviceTypeMetric, error)
+	// Filters are optional
+	ListDeviceAclEntries(ctx context.Context, arg ListDeviceAclEntriesParams) ([]DeviceAclEntry, error)
+	ListDeviceGroups(ctx context.Context, arg ListDeviceGroupsParams) ([]DeviceGroup, error)
+	ListDeviceTypeMetrics(ctx context.Context, deviceType string) ([]DeviceTypeMetric, error)
+	ListDeviceTypes(ctx context.Context) ([]DeviceType, error)
+	// Filters are optional; location_id also selects the devices of sub-locations.
+	// acl_user_id and acl_role restrict to the devices granted by their ACL
+	// entries in the organization, if they have any
 	ListDevices(ctx context.Context, arg ListDevicesParams) ([]Device, error)
 	ListDevicesByStatus(ctx context.Context, arg ListDevicesByStatusParams) ([]Device, error)
 	ListDevicesByType(ctx context.Context, arg ListDevicesByTypeParams) ([]Device, error)
+	ListLocations(ctx context.Context, arg ListLocationsParams) ([]Location, error)
+	// Devices among device_ids granted to a user with one of the access levels
+	ListSubjectAclDevices(ctx context.Context, arg ListSubjectAclDevicesParams) ([]pgtype.UUID, error)
+	RemoveDeviceGroupMembers(ctx context.Context, arg RemoveDeviceGroupMembersParams) (int64, error)
 	UpdateDevice(ctx context.Context, arg UpdateDeviceParams) (Device, error)
+	UpdateDeviceGroup(ctx context.Context, arg UpdateDeviceGroupParams) (DeviceGroup, error)
+	UpdateLocation(ctx context.Context, arg UpdateLocationParams) (Location, error)
+	// IoT Platform - Device type registry and metric catalog
+	UpsertDeviceType(ctx context.Context, arg UpsertDeviceTypeParams) (DeviceType, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/yourusername/iot-platform/shared/proto/device"
+)
+
+// CreateDeviceGroup creates a static group, whose devices are added
+// explicitly, or a dynamic group, whose devices are those matching its rule.
+func (s *DeviceServer) CreateDeviceGroup(ctx context.Context, req *pb.CreateDeviceGroupRequest) (*pb.CreateDeviceGroupResponse, error) {
+	log.Printf("📥 CreateDeviceGroup: name=%s, kind=%s, org_id=%s", req.Name, req.Kind, req.OrgId)
+
+	if req.OrgId == "" {
+		return nil, status.Error(codes.InvalidArgument, "org_id required")
+	}
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name required")
+	}
+	if _, ok := pb.DeviceGroupKind_name[int32(req.Kind)]; !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown group kind %d", req.Kind)
+	}
+	if err := validateGroupRule(req.Kind, req.Rule); err != nil {
+		return nil, err
+	}
+
+	group, err := s.storage.CreateDeviceGroup(ctx, &pb.DeviceGroup{
+		OrgId:       req.OrgId,
+		Name:        req.Name,
+		Description: req.Description,
+		Kind:        req.Kind,
+		Rule:        req.Rule,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Device group created: id=%s, devices=%d", group.Id, group.DeviceCount)
+	return &pb.CreateDeviceGroupResponse{Group: group}, nil
+}
+
+// GetDeviceGroup retrieves a group by ID, with its device count.
+func (s *DeviceServer) GetDeviceGroup(ctx context.Context, req *pb.GetDeviceGroupRequest) (*pb.GetDeviceGroupResponse, error) {
+	log.Printf("📥 GetDeviceGroup: id=%s, org_id=%s", req.Id, req.OrgId)
+
+	group, err := s.storage.GetDeviceGroup(ctx, req.OrgId, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetDeviceGroupResponse{Group: group}, nil
+}
+
+// ListDeviceGroups returns the groups of an organization, or those
+// containing a device.
+func (s *DeviceServer) ListDeviceGroups(ctx context.Context, req *pb.ListDeviceGroupsRequest) (*pb.ListDeviceGroupsResponse, error) {
+	log.Printf("📥 ListDeviceGroups: device_id=%s, org_id=%s", req.DeviceId, req.OrgId)
+
+	groups, err := s.storage.ListDeviceGroups(ctx, req.OrgId, req.DeviceId)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ %d device groups found", len(groups))
+	return &pb.ListDeviceGroupsResponse{Groups: groups}, nil
+}
+
+// UpdateDeviceGroup changes the name, description or rule of a group. The
+// kind of a group cannot change.
+func (s *DeviceServer) UpdateDeviceGroup(ctx context.Context, req *pb.UpdateDeviceGroupRequest) (*pb.UpdateDeviceGroupResponse, error) {
+	log.Printf("📥 UpdateDeviceGroup: id=%s", req.Id)
+
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "ID required")
+	}
+
+	group, err := s.storage.GetDeviceGroup(ctx, req.OrgId, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if req.Name != "" {
+		group.Name = req.Name
+	}
+	if req.Description != nil {
+		group.Description = *req.Description
+	}
+	if req.Rule != nil {
+		if err := validateGroupRule(group.Kind, req.Rule); err != nil {
+			return nil, err
+		}
+		group.Rule = req.Rule
+	}
+
+	group, err = s.storage.UpdateDeviceGroup(ctx, req.OrgId, group)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Device group updated: id=%s, devices=%d", group.Id, group.DeviceCount)
+	return &pb.UpdateDeviceGroupResponse{Group: group}, nil
+}
+
+// DeleteDeviceGroup removes a group. Its devices are kept.
+func (s *DeviceServer) DeleteDeviceGroup(ctx context.Context, req *pb.DeleteDeviceGroupRequest) (*pb.DeleteDeviceGroupResponse, error) {
+	log.Printf("📥 DeleteDeviceGroup: id=%s", req.Id)
+
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "ID required")
+	}
+
+	if err := s.storage.DeleteDeviceGroup(ctx, req.OrgId, req.Id); err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Device group deleted: id=%s", req.Id)
+	return &pb.DeleteDeviceGroupResponse{
+		Success: true,
+		Message: fmt.Sprintf("Device group %s deleted", req.Id),
+	}, nil
+}
+
+// AddGroupDevices adds devices of the organization of a static group to it.
+func (s *DeviceServer) AddGroupDevices(ctx context.Context, req *pb.GroupDevicesRequest) (*pb.GroupDevicesResponse, error) {
+	log.Printf("📥 AddGroupDevices: group_id=%s, devices=%d", req.GroupId, len(req.DeviceIds))
+
+	if err := s.checkStaticGroup(ctx, req); err != nil {
+		return nil, err
+	}
+	if err := s.storage.AddGroupDevices(ctx, req.GroupId, req.DeviceIds); err != nil {
+		return nil, err
+	}
+
+	return s.groupDevicesResponse(ctx, req)
+}
+
+// RemoveGroupDevices removes devices from a static group.
+func (s *DeviceServer) RemoveGroupDevices(ctx context.Context, req *pb.GroupDevicesRequest) (*pb.GroupDevicesResponse, error) {
+	log.Printf("📥 RemoveGroupDevices: group_id=%s, devices=%d", req.GroupId, len(req.DeviceIds))
+
+	if err := s.checkStaticGroup(ctx, req); err != nil {
+		return nil, err
+	}
+	if err := s.storage.RemoveGroupDevices(ctx, req.GroupId, req.DeviceIds); err != nil {
+		return nil, err
+	}
+
+	return s.groupDevicesResponse(ctx, req)
+}
+
+// checkStaticGroup validates a membership change: the devices of a dynamic
+// group follow its rule and cannot be changed.
+func (s *DeviceServer) checkStaticGroup(ctx context.Context, req *pb.GroupDevicesRequest) error {
+	if req.GroupId == "" {
+		return status.Error(codes.InvalidArgument, "group_id required")
+	}
+	if len(req.DeviceIds) == 0 {
+		return status.Error(codes.InvalidArgument, "device_ids required")
+	}
+
+	group, err := s.storage.GetDeviceGroup(ctx, req.OrgId, req.GroupId)
+	if err != nil {
+		return err
+	}
+	if group.Kind != pb.DeviceGroupKind_GROUP_STATIC {
+		return status.Errorf(codes.FailedPrecondition, "devices of dynamic group %s are selected by its rule", group.Name)
+	}
+	return nil
+}
+
+// groupDevicesResponse returns the group after a membership change.
+func (s *DeviceServer) groupDevicesResponse(ctx context.Context, req *pb.GroupDevicesRequest) (*pb.GroupDevicesResponse, error) {
+	group, err := s.storage.GetDeviceGroup(ctx, req.OrgId, req.GroupId)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Device group %s has %d devices", group.Id, group.DeviceCount)
+	return &pb.GroupDevicesResponse{Group: group}, nil
+}
+
+// validateGroupRule checks that dynamic groups, and only them, have a rule
+// selecting devices by type and/or metadata.
+func validateGroupRule(kind pb.DeviceGroupKind, rule *pb.DeviceGroupRule) error {
+	if kind == pb.DeviceGroupKind_GROUP_STATIC {
+		if rule != nil {
+			return status.Error(codes.InvalidArgument, "static groups have no rule")
+		}
+		return nil
+	}
+	if rule.GetType() == "" && len(rule.GetMetadata()) == 0 {
+		return status.Error(codes.InvalidArgument, "rule of a dynamic group requires a type or metadata")
+	}
+	return nil
+}
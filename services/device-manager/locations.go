@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/yourusername/iot-platform/shared/proto/device"
+)
+
+// CreateLocation creates a site, or a building, floor or room inside a
+// location of the level above in the same organization.
+func (s *DeviceServer) CreateLocation(ctx context.Context, req *pb.CreateLocationRequest) (*pb.CreateLocationResponse, error) {
+	log.Printf("📥 CreateLocation: name=%s, kind=%s, parent_id=%s, org_id=%s", req.Name, req.Kind, req.ParentId, req.OrgId)
+
+	if req.OrgId == "" {
+		return nil, status.Error(codes.InvalidArgument, "org_id required")
+	}
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name required")
+	}
+	if _, ok := pb.LocationKind_name[int32(req.Kind)]; !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown location kind %d", req.Kind)
+	}
+
+	if req.Kind == pb.LocationKind_LOCATION_SITE {
+		if req.ParentId != "" {
+			return nil, status.Error(codes.InvalidArgument, "a site has no parent location")
+		}
+	} else {
+		if req.ParentId == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "parent_id required for a %s", locationKindName(req.Kind))
+		}
+		parent, err := s.storage.GetLocation(ctx, req.OrgId, req.ParentId)
+		if status.Code(err) == codes.NotFound {
+			return nil, status.Errorf(codes.InvalidArgument, "parent location %s not found", req.ParentId)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if parent.Kind != req.Kind-1 {
+			return nil, status.Errorf(codes.InvalidArgument, "a %s must be inside a %s, not a %s",
+				locationKindName(req.Kind), locationKindName(req.Kind-1), locationKindName(parent.Kind))
+		}
+	}
+
+	location, err := s.storage.CreateLocation(ctx, &pb.Location{
+		OrgId:    req.OrgId,
+		ParentId: req.ParentId,
+		Kind:     req.Kind,
+		Name:     req.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Location created: id=%s", location.Id)
+	return &pb.CreateLocationResponse{Location: location}, nil
+}
+
+// GetLocation retrieves a location by ID.
+func (s *DeviceServer) GetLocation(ctx context.Context, req *pb.GetLocationRequest) (*pb.GetLocationResponse, error) {
+	log.Printf("📥 GetLocation: id=%s, org_id=%s", req.Id, req.OrgId)
+
+	location, err := s.storage.GetLocation(ctx, req.OrgId, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetLocationResponse{Location: location}, nil
+}
+
+// ListLocations returns the locations of an organization, or the direct
+// children of a location.
+func (s *DeviceServer) ListLocations(ctx context.Context, req *pb.ListLocationsRequest) (*pb.ListLocationsResponse, error) {
+	log.Printf("📥 ListLocations: parent_id=%s, org_id=%s", req.ParentId, req.OrgId)
+
+	locations, err := s.storage.ListLocations(ctx, req.OrgId, req.ParentId)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ %d locations found", len(locations))
+	return &pb.ListLocationsResponse{Locations: locations}, nil
+}
+
+// UpdateLocation renames a location.
+func (s *DeviceServer) UpdateLocation(ctx context.Context, req *pb.UpdateLocationRequest) (*pb.UpdateLocationResponse, error) {
+	log.Printf("📥 UpdateLocation: id=%s, name=%s", req.Id, req.Name)
+
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "ID required")
+	}
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name required")
+	}
+
+	location, err := s.storage.UpdateLocation(ctx, req.OrgId, req.Id, req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Location updated: id=%s", location.Id)
+	return &pb.UpdateLocationResponse{Location: location}, nil
+}
+
+// DeleteLocation removes a location without sub-locations. Its devices are
+// kept, without location.
+func (s *DeviceServer) DeleteLocation(ctx context.Context, req *pb.DeleteLocationRequest) (*pb.DeleteLocationResponse, error) {
+	log.Printf("📥 DeleteLocation: id=%s", req.Id)
+
+	if req.Id == "" {
+		return nil, status.Error(codes.InvalidArgument, "ID required")
+	}
+
+	if err := s.storage.DeleteLocation(ctx, req.OrgId, req.Id); err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Location deleted: id=%s", req.Id)
+	return &pb.DeleteLocationResponse{
+		Success: true,
+		Message: fmt.Sprintf("Location %s deleted", req.Id),
+	}, nil
+}
+
+// checkLocation verifies that a device can be placed in a location of the
+// organization orgID.
+func (s *DeviceServer) checkLocation(ctx context.Context, orgID, locationID string) error {
+	_, err := s.storage.GetLocation(ctx, orgID, locationID)
+	if status.Code(err) == codes.NotFound {
+		return status.Errorf(codes.InvalidArgument, "location %s not found", locationID)
+	}
+	return err
+}
+
+// locationKindName returns the lower-case name of a location kind ("site").
+func locationKindName(kind pb.LocationKind) string {
+	return strings.ToLower(strings.TrimPrefix(kind.String(), "LOCATION_"))
+}
@@ -4,6 +4,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net"
@@ -18,10 +19,12 @@ import (
 
 	pb "github.com/yourusername/iot-platform/shared/proto/device"
 	"github.com/yourusername/iot-platform/services/device-manager/storage"
+	"github.com/yourusername/iot-platform/shared/eventbus"
 )
 
 // DeviceServer implements pb.DeviceServiceServer interface.
-// Uses pluggable Storage backend (PostgreSQL or in-memory).
+// Uses pluggable Storage backend (PostgreSQL or in-memory) and publishes
+// device lifecycle events on the event bus.
 //
 // TODO Production:
 //   - Add interceptors (logging, auth, metrics)
@@ -29,35 +32,48 @@ import (
 type DeviceServer struct {
 	pb.UnimplementedDeviceServiceServer
 	storage storage.Storage
+	bus     eventbus.Bus
 }
 
-// NewDeviceServer creates a new server instance with the given storage backend.
-func NewDeviceServer(store storage.Storage) *DeviceServer {
+// NewDeviceServer creates a new server instance with the given storage backend
+// and event bus.
+func NewDeviceServer(store storage.Storage, bus eventbus.Bus) *DeviceServer {
 	return &DeviceServer{
 		storage: store,
+		bus:     bus,
 	}
 }
 
 // CreateDevice creates a new device with generated UUID and timestamps.
 func (s *DeviceServer) CreateDevice(ctx context.Context, req *pb.CreateDeviceRequest) (*pb.CreateDeviceResponse, error) {
-	log.Printf("📥 CreateDevice: name=%s, type=%s", req.Name, req.Type)
+	log.Printf("📥 CreateDevice: name=%s, type=%s, org_id=%s", req.Name, req.Type, req.OrgId)
 
+	if req.OrgId == "" {
+		return nil, status.Error(codes.InvalidArgument, "org_id required")
+	}
 	if req.Name == "" {
 		return nil, status.Error(codes.InvalidArgument, "name required")
 	}
 	if req.Type == "" {
 		return nil, status.Error(codes.InvalidArgument, "type required")
 	}
+	if req.LocationId != "" {
+		if err := s.checkLocation(ctx, req.OrgId, req.LocationId); err != nil {
+			return nil, err
+		}
+	}
 
 	now := time.Now().Unix()
 	device := &pb.Device{
-		Id:        uuid.New().String(),
-		Name:      req.Name,
-		Type:      req.Type,
-		Status:    pb.DeviceStatus_ONLINE,
-		CreatedAt: now,
-		LastSeen:  now,
-		Metadata:  req.Metadata,
+		Id:         uuid.New().String(),
+		Name:       req.Name,
+		Type:       req.Type,
+		Status:     pb.DeviceStatus_ONLINE,
+		CreatedAt:  now,
+		LastSeen:   now,
+		Metadata:   req.Metadata,
+		OrgId:      req.OrgId,
+		LocationId: req.LocationId,
 	}
 
 	createdDevice, err := s.storage.CreateDevice(ctx, device)
@@ -66,14 +82,16 @@ func (s *DeviceServer) CreateDevice(ctx context.Context, req *pb.CreateDeviceReq
 	}
 
 	log.Printf("✅ Device created: id=%s", createdDevice.Id)
+	s.publishDeviceEvent(ctx, eventbus.DeviceCreated, createdDevice.Id, createdDevice)
 	return &pb.CreateDeviceResponse{Device: createdDevice}, nil
 }
 
-// GetDevice retrieves a device by ID.
+// GetDevice retrieves a device by ID, within the organization of the request
+// if set.
 func (s *DeviceServer) GetDevice(ctx context.Context, req *pb.GetDeviceRequest) (*pb.GetDeviceResponse, error) {
-	log.Printf("📥 GetDevice: id=%s", req.Id)
+	log.Printf("📥 GetDevice: id=%s, org_id=%s", req.Id, req.OrgId)
 
-	device, err := s.storage.GetDevice(ctx, req.Id)
+	device, err := s.storage.GetDevice(ctx, req.OrgId, req.Id)
 	if err != nil {
 		return nil, err
 	}
@@ -82,11 +100,20 @@ func (s *DeviceServer) GetDevice(ctx context.Context, req *pb.GetDeviceRequest)
 	return &pb.GetDeviceResponse{Device: device}, nil
 }
 
-// ListDevices returns paginated device list.
+// ListDevices returns paginated device list, of the organization of the
+// request if set. Type, status, group, location and ACL filters are applied
+// by the storage, so that the total counts the matching devices.
 func (s *DeviceServer) ListDevices(ctx context.Context, req *pb.ListDevicesRequest) (*pb.ListDevicesResponse, error) {
-	log.Printf("📥 ListDevices: page=%d, pageSize=%d", req.Page, req.PageSize)
-
-	devices, total, err := s.storage.ListDevices(ctx, req.Page, req.PageSize)
+	log.Printf("📥 ListDevices: page=%d, pageSize=%d, org_id=%s, group_id=%s, location_id=%s", req.Page, req.PageSize, req.OrgId, req.GroupId, req.LocationId)
+
+	filter := storage.DeviceFilter{
+		Type:       req.Type,
+		Status:     req.Status,
+		GroupID:    req.GroupId,
+		LocationID: req.LocationId,
+		Subject:    req.Subject,
+	}
+	devices, total, err := s.storage.ListDevices(ctx, req.OrgId, filter, req.Page, req.PageSize)
 	if err != nil {
 		return nil, err
 	}
@@ -101,7 +128,8 @@ func (s *DeviceServer) ListDevices(ctx context.Context, req *pb.ListDevicesReque
 }
 
 // UpdateDevice updates an existing device.
-// Mutable fields: Name, Status, Metadata. ID and CreatedAt are immutable.
+// Mutable fields: Name, Status, Metadata, LocationId. ID and CreatedAt are
+// immutable. The location is kept unless the request sets it.
 func (s *DeviceServer) UpdateDevice(ctx context.Context, req *pb.UpdateDeviceRequest) (*pb.UpdateDeviceResponse, error) {
 	log.Printf("📥 UpdateDevice: id=%s", req.Id)
 
@@ -109,21 +137,36 @@ func (s *DeviceServer) UpdateDevice(ctx context.Context, req *pb.UpdateDeviceReq
 		return nil, status.Error(codes.InvalidArgument, "ID required")
 	}
 
+	existing, err := s.storage.GetDevice(ctx, req.OrgId, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
 	// Prepare update with fields to change
 	device := &pb.Device{
-		Id:       req.Id,
-		Name:     req.Name,
-		Status:   req.Status,
-		Metadata: req.Metadata,
-		LastSeen: time.Now().Unix(),
+		Id:         req.Id,
+		Name:       req.Name,
+		Status:     req.Status,
+		Metadata:   req.Metadata,
+		LastSeen:   time.Now().Unix(),
+		LocationId: existing.LocationId,
+	}
+	if req.LocationId != nil {
+		if *req.LocationId != "" {
+			if err := s.checkLocation(ctx, existing.OrgId, *req.LocationId); err != nil {
+				return nil, err
+			}
+		}
+		device.LocationId = *req.LocationId
 	}
 
-	updatedDevice, err := s.storage.UpdateDevice(ctx, device)
+	updatedDevice, err := s.storage.UpdateDevice(ctx, req.OrgId, device)
 	if err != nil {
 		return nil, err
 	}
 
 	log.Printf("✅ Device updated: id=%s", updatedDevice.Id)
+	s.publishDeviceEvent(ctx, eventbus.DeviceUpdated, updatedDevice.Id, updatedDevice)
 	return &pb.UpdateDeviceResponse{Device: updatedDevice}, nil
 }
 
@@ -135,17 +178,150 @@ func (s *DeviceServer) DeleteDevice(ctx context.Context, req *pb.DeleteDeviceReq
 		return nil, status.Error(codes.InvalidArgument, "ID required")
 	}
 
-	if err := s.storage.DeleteDevice(ctx, req.Id); err != nil {
+	if err := s.storage.DeleteDevice(ctx, req.OrgId, req.Id); err != nil {
 		return nil, err
 	}
 
 	log.Printf("✅ Device deleted: id=%s", req.Id)
+	s.publishDeviceEvent(ctx, eventbus.DeviceDeleted, req.Id, nil)
 	return &pb.DeleteDeviceResponse{
 		Success: true,
 		Message: fmt.Sprintf("Device %s deleted", req.Id),
 	}, nil
 }
 
+// publishDeviceEvent publishes a lifecycle event on devices.<id>. The change
+// is already committed, so failures are logged and not returned to the caller.
+func (s *DeviceServer) publishDeviceEvent(ctx context.Context, eventType, id string, device *pb.Device) {
+	event := eventbus.DeviceEvent{
+		Type:      eventType,
+		DeviceID:  id,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+	if device != nil {
+		event.OrgID = device.OrgId
+		event.Name = device.Name
+		event.DeviceType = device.Type
+		event.Status = device.Status.String()
+		event.CreatedAt = device.CreatedAt
+		event.LastSeen = device.LastSeen
+		event.Metadata = device.Metadata
+		event.LocationID = device.LocationId
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("⚠️  Failed to marshal device event: %v", err)
+		return
+	}
+	if err := s.bus.Publish(ctx, eventbus.Subject(eventbus.SubjectDevices, id), payload); err != nil {
+		log.Printf("⚠️  Failed to publish device event: %v", err)
+	}
+}
+
+// UpsertDeviceType creates or replaces a device type and its metric catalog.
+func (s *DeviceServer) UpsertDeviceType(ctx context.Context, req *pb.UpsertDeviceTypeRequest) (*pb.UpsertDeviceTypeResponse, error) {
+	if req.DeviceType == nil {
+		return nil, status.Error(codes.InvalidArgument, "device_type required")
+	}
+	log.Printf("📥 UpsertDeviceType: name=%s, metrics=%d", req.DeviceType.Name, len(req.DeviceType.Metrics))
+
+	if err := validateDeviceType(req.DeviceType); err != nil {
+		return nil, err
+	}
+
+	deviceType, err := s.storage.UpsertDeviceType(ctx, req.DeviceType)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Device type saved: name=%s", deviceType.Name)
+	return &pb.UpsertDeviceTypeResponse{DeviceType: deviceType}, nil
+}
+
+// GetDeviceType retrieves a device type and its metric catalog.
+func (s *DeviceServer) GetDeviceType(ctx context.Context, req *pb.GetDeviceTypeRequest) (*pb.GetDeviceTypeResponse, error) {
+	log.Printf("📥 GetDeviceType: name=%s", req.Name)
+
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name required")
+	}
+
+	deviceType, err := s.storage.GetDeviceType(ctx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetDeviceTypeResponse{DeviceType: deviceType}, nil
+}
+
+// ListDeviceTypes returns all registered device types.
+func (s *DeviceServer) ListDeviceTypes(ctx context.Context, req *pb.ListDeviceTypesRequest) (*pb.ListDeviceTypesResponse, error) {
+	log.Printf("📥 ListDeviceTypes")
+
+	deviceTypes, err := s.storage.ListDeviceTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ %d device types found", len(deviceTypes))
+	return &pb.ListDeviceTypesResponse{DeviceTypes: deviceTypes}, nil
+}
+
+// DeleteDeviceType removes a device type. Devices of this type are kept,
+// their telemetry is no longer validated.
+func (s *DeviceServer) DeleteDeviceType(ctx context.Context, req *pb.DeleteDeviceTypeRequest) (*pb.DeleteDeviceTypeResponse, error) {
+	log.Printf("📥 DeleteDeviceType: name=%s", req.Name)
+
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name required")
+	}
+
+	if err := s.storage.DeleteDeviceType(ctx, req.Name); err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Device type deleted: name=%s", req.Name)
+	return &pb.DeleteDeviceTypeResponse{
+		Success: true,
+		Message: fmt.Sprintf("Device type %s deleted", req.Name),
+	}, nil
+}
+
+// validateDeviceType checks a device type before it is stored.
+// Display names default to the identifiers.
+func validateDeviceType(deviceType *pb.DeviceType) error {
+	if deviceType.Name == "" {
+		return status.Error(codes.InvalidArgument, "name required")
+	}
+	if deviceType.DisplayName == "" {
+		deviceType.DisplayName = deviceType.Name
+	}
+
+	seen := make(map[string]bool, len(deviceType.Metrics))
+	for _, metric := range deviceType.Metrics {
+		if metric.Name == "" {
+			return status.Error(codes.InvalidArgument, "metric name required")
+		}
+		if seen[metric.Name] {
+			return status.Errorf(codes.InvalidArgument, "metric %s declared twice", metric.Name)
+		}
+		seen[metric.Name] = true
+
+		if metric.DisplayName == "" {
+			metric.DisplayName = metric.Name
+		}
+		if metric.MinValue != nil && metric.MaxValue != nil && *metric.MinValue > *metric.MaxValue {
+			return status.Errorf(codes.InvalidArgument, "metric %s: min_value greater than max_value", metric.Name)
+		}
+		if metric.ReportingIntervalSeconds < 0 {
+			return status.Errorf(codes.InvalidArgument, "metric %s: reporting_interval_seconds must not be negative", metric.Name)
+		}
+	}
+
+	return nil
+}
+
 // main initializes and starts the Device Manager gRPC server.
 //
 // Configuration via environment variables:
@@ -156,6 +332,11 @@ func (s *DeviceServer) DeleteDevice(ctx context.Context, req *pb.DeleteDeviceReq
 //   - DB_USER: Database user (default: iot_user)
 //   - DB_PASSWORD: Database password (default: iot_password)
 //   - DB_SSLMODE: SSL mode (default: disable)
+//   - EVENT_BUS: Device event transport: redis, nats or memory (default: redis,
+//     in-process if the broker is unreachable)
+//   - REDIS_HOST, REDIS_PORT, REDIS_PASSWORD, REDIS_DB: Redis connection (default: localhost:6379)
+//   - REDIS_TRANSPORT: Redis fan-out: pubsub, streams or both (default: streams)
+//   - NATS_URL: NATS server URL (default: nats://localhost:4222)
 //
 // TODO Production:
 //   - TLS/mTLS support
@@ -197,13 +378,29 @@ func main() {
 		log.Printf("✅ Using in-memory storage")
 	}
 
+	// Configure event bus
+	busConfig := eventbus.ConfigFromEnv(eventbus.Config{
+		Transport:    eventbus.TransportRedis,
+		Redis:        eventbus.RedisConfig{Host: "localhost", Port: 6379, MaxLen: 100000},
+		NATS:         eventbus.NATSConfig{URL: "nats://localhost:4222"},
+		MemoryMaxLen: 1000,
+	})
+	bus, err := eventbus.New(ctx, busConfig)
+	if err != nil {
+		// Device events only feed live subscriptions: keep serving without them
+		log.Printf("⚠️  Failed to connect to event bus: %v (device events stay in-process)", err)
+		busConfig.Transport = eventbus.TransportMemory
+		bus = eventbus.NewMemory(busConfig.MemoryMaxLen)
+	}
+	defer bus.Close()
+
 	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
 		log.Fatalf("❌ Failed to create listener: %v", err)
 	}
 
 	grpcServer := grpc.NewServer()
-	deviceServer := NewDeviceServer(store)
+	deviceServer := NewDeviceServer(store, bus)
 	pb.RegisterDeviceServiceServer(grpcServer, deviceServer)
 
 	log.Println("=====================================")
@@ -212,6 +409,7 @@ func main() {
 	log.Printf("Protocol: gRPC (HTTP/2)")
 	log.Printf("Port: %d", port)
 	log.Printf("Storage: %s", storageType)
+	log.Printf("Event bus: %s", busConfig.Transport)
 	log.Printf("Address: http://localhost:%d", port)
 	log.Println("-------------------------------------")
 	log.Printf("✅ Server started")
@@ -4,15 +4,21 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"sync"
 	"testing"
+	"time"
 
 	pb "github.com/yourusername/iot-platform/shared/proto/device"
 	"github.com/yourusername/iot-platform/services/device-manager/storage"
+	"github.com/yourusername/iot-platform/shared/eventbus"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+// testOrgID is the organization of the devices created by the tests
+const testOrgID = "org-1"
+
 // TestCreateDevice tests device creation functionality.
 func TestCreateDevice(t *testing.T) {
 	tests := []struct {
@@ -25,8 +31,9 @@ func TestCreateDevice(t *testing.T) {
 		{
 			name: "valid_device",
 			request: &pb.CreateDeviceRequest{
-				Name: "Test Sensor",
-				Type: "temperature",
+				OrgId: testOrgID,
+				Name:  "Test Sensor",
+				Type:  "temperature",
 				Metadata: map[string]string{
 					"location": "room-101",
 				},
@@ -37,16 +44,28 @@ func TestCreateDevice(t *testing.T) {
 		{
 			name: "missing_name",
 			request: &pb.CreateDeviceRequest{
-				Type: "temperature",
+				OrgId: testOrgID,
+				Type:  "temperature",
 			},
 			wantErr:     true,
 			wantCode:    codes.InvalidArgument,
 			description: "should return error when name is missing",
 		},
 		{
-			name: "missing_type",
+			name: "missing_organization",
 			request: &pb.CreateDeviceRequest{
 				Name: "Test Sensor",
+				Type: "temperature",
+			},
+			wantErr:     true,
+			wantCode:    codes.InvalidArgument,
+			description: "should return error when the organization is missing",
+		},
+		{
+			name: "missing_type",
+			request: &pb.CreateDeviceRequest{
+				OrgId: testOrgID,
+				Name:  "Test Sensor",
 			},
 			wantErr:     true,
 			wantCode:    codes.InvalidArgument,
@@ -55,8 +74,9 @@ func TestCreateDevice(t *testing.T) {
 		{
 			name: "with_metadata",
 			request: &pb.CreateDeviceRequest{
-				Name: "Smart Thermostat",
-				Type: "hvac",
+				OrgId: testOrgID,
+				Name:  "Smart Thermostat",
+				Type:  "hvac",
 				Metadata: map[string]string{
 					"floor":    "2",
 					"building": "A",
@@ -70,7 +90,7 @@ func TestCreateDevice(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			server := NewDeviceServer(storage.NewMemoryStorage())
+			server := NewDeviceServer(storage.NewMemoryStorage(), eventbus.NewMemory(0))
 			ctx := context.Background()
 
 			resp, err := server.CreateDevice(ctx, tt.request)
@@ -146,13 +166,14 @@ func TestCreateDevice(t *testing.T) {
 
 // TestGetDevice tests device retrieval functionality.
 func TestGetDevice(t *testing.T) {
-	server := NewDeviceServer(storage.NewMemoryStorage())
+	server := NewDeviceServer(storage.NewMemoryStorage(), eventbus.NewMemory(0))
 	ctx := context.Background()
 
 	// Create a test device first
 	createResp, err := server.CreateDevice(ctx, &pb.CreateDeviceRequest{
-		Name: "Test Device",
-		Type: "sensor",
+		OrgId: testOrgID,
+		Name:  "Test Device",
+		Type:  "sensor",
 	})
 	if err != nil {
 		t.Fatalf("failed to create test device: %v", err)
@@ -219,7 +240,7 @@ func TestGetDevice(t *testing.T) {
 
 // TestListDevices tests device listing functionality.
 func TestListDevices(t *testing.T) {
-	server := NewDeviceServer(storage.NewMemoryStorage())
+	server := NewDeviceServer(storage.NewMemoryStorage(), eventbus.NewMemory(0))
 	ctx := context.Background()
 
 	// Test empty list
@@ -245,8 +266,9 @@ func TestListDevices(t *testing.T) {
 	deviceIDs := make([]string, 3)
 	for i := 0; i < 3; i++ {
 		createResp, err := server.CreateDevice(ctx, &pb.CreateDeviceRequest{
-			Name: "Device " + string(rune('A'+i)),
-			Type: "sensor",
+			OrgId: testOrgID,
+			Name:  "Device " + string(rune('A'+i)),
+			Type:  "sensor",
 		})
 		if err != nil {
 			t.Fatalf("failed to create test device: %v", err)
@@ -287,13 +309,14 @@ func TestListDevices(t *testing.T) {
 
 // TestUpdateDevice tests device update functionality.
 func TestUpdateDevice(t *testing.T) {
-	server := NewDeviceServer(storage.NewMemoryStorage())
+	server := NewDeviceServer(storage.NewMemoryStorage(), eventbus.NewMemory(0))
 	ctx := context.Background()
 
 	// Create a test device
 	createResp, err := server.CreateDevice(ctx, &pb.CreateDeviceRequest{
-		Name: "Original Device",
-		Type: "sensor",
+		OrgId: testOrgID,
+		Name:  "Original Device",
+		Type:  "sensor",
 		Metadata: map[string]string{
 			"version": "1.0",
 		},
@@ -420,13 +443,14 @@ func TestUpdateDevice(t *testing.T) {
 
 // TestDeleteDevice tests device deletion functionality.
 func TestDeleteDevice(t *testing.T) {
-	server := NewDeviceServer(storage.NewMemoryStorage())
+	server := NewDeviceServer(storage.NewMemoryStorage(), eventbus.NewMemory(0))
 	ctx := context.Background()
 
 	// Create a test device
 	createResp, err := server.CreateDevice(ctx, &pb.CreateDeviceRequest{
-		Name: "Device to Delete",
-		Type: "sensor",
+		OrgId: testOrgID,
+		Name:  "Device to Delete",
+		Type:  "sensor",
 	})
 	if err != nil {
 		t.Fatalf("failed to create test device: %v", err)
@@ -501,9 +525,102 @@ func TestDeleteDevice(t *testing.T) {
 	}
 }
 
+// TestOrganizationScope tests that requests with an organization only reach
+// the devices of that organization.
+func TestOrganizationScope(t *testing.T) {
+	server := NewDeviceServer(storage.NewMemoryStorage(), eventbus.NewMemory(0))
+	ctx := context.Background()
+
+	createResp, err := server.CreateDevice(ctx, &pb.CreateDeviceRequest{OrgId: testOrgID, Name: "Sensor", Type: "temperature"})
+	if err != nil {
+		t.Fatalf("CreateDevice failed: %v", err)
+	}
+	id := createResp.Device.Id
+	if createResp.Device.OrgId != testOrgID {
+		t.Errorf("expected organization %s, got %s", testOrgID, createResp.Device.OrgId)
+	}
+
+	if _, err := server.GetDevice(ctx, &pb.GetDeviceRequest{Id: id, OrgId: "org-2"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound from another organization, got %v", err)
+	}
+	if _, err := server.UpdateDevice(ctx, &pb.UpdateDeviceRequest{Id: id, OrgId: "org-2", Name: "Renamed"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound updating from another organization, got %v", err)
+	}
+	if _, err := server.DeleteDevice(ctx, &pb.DeleteDeviceRequest{Id: id, OrgId: "org-2"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound deleting from another organization, got %v", err)
+	}
+
+	listResp, err := server.ListDevices(ctx, &pb.ListDevicesRequest{Page: 1, PageSize: 10, OrgId: "org-2"})
+	if err != nil {
+		t.Fatalf("ListDevices failed: %v", err)
+	}
+	if listResp.Total != 0 {
+		t.Errorf("expected no device in another organization, got %d", listResp.Total)
+	}
+
+	if _, err := server.GetDevice(ctx, &pb.GetDeviceRequest{Id: id, OrgId: testOrgID}); err != nil {
+		t.Errorf("GetDevice in its organization failed: %v", err)
+	}
+}
+
+// TestDeviceEvents tests that lifecycle events are published on the event bus.
+func TestDeviceEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := eventbus.NewMemory(0)
+	defer bus.Close()
+
+	events := make(chan *eventbus.Message, 10)
+	if err := bus.Subscribe(ctx, "devices.>", func(msg *eventbus.Message) { events <- msg }); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	server := NewDeviceServer(storage.NewMemoryStorage(), bus)
+	createResp, err := server.CreateDevice(ctx, &pb.CreateDeviceRequest{OrgId: testOrgID, Name: "Sensor", Type: "temperature"})
+	if err != nil {
+		t.Fatalf("CreateDevice failed: %v", err)
+	}
+	id := createResp.Device.Id
+
+	if _, err := server.UpdateDevice(ctx, &pb.UpdateDeviceRequest{Id: id, Name: "Renamed", Status: pb.DeviceStatus_OFFLINE}); err != nil {
+		t.Fatalf("UpdateDevice failed: %v", err)
+	}
+	if _, err := server.DeleteDevice(ctx, &pb.DeleteDeviceRequest{Id: id}); err != nil {
+		t.Fatalf("DeleteDevice failed: %v", err)
+	}
+
+	want := []struct {
+		eventType string
+		name      string
+		orgID     string
+	}{
+		{eventbus.DeviceCreated, "Sensor", testOrgID},
+		{eventbus.DeviceUpdated, "Renamed", testOrgID},
+		{eventbus.DeviceDeleted, "", ""},
+	}
+	for _, w := range want {
+		select {
+		case msg := <-events:
+			if msg.Subject != "devices."+id {
+				t.Errorf("expected subject devices.%s, got %s", id, msg.Subject)
+			}
+			var event eventbus.DeviceEvent
+			if err := json.Unmarshal(msg.Data, &event); err != nil {
+				t.Fatalf("invalid event payload: %v", err)
+			}
+			if event.Type != w.eventType || event.DeviceID != id || event.Name != w.name || event.OrgID != w.orgID {
+				t.Errorf("expected %s event for %s named %q in %q, got %+v", w.eventType, id, w.name, w.orgID, event)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s event not published", w.eventType)
+		}
+	}
+}
+
 // TestConcurrentOperations tests thread safety with concurrent access.
 func TestConcurrentOperations(t *testing.T) {
-	server := NewDeviceServer(storage.NewMemoryStorage())
+	server := NewDeviceServer(storage.NewMemoryStorage(), eventbus.NewMemory(0))
 	ctx := context.Background()
 
 	// Number of concurrent goroutines
@@ -517,8 +634,9 @@ func TestConcurrentOperations(t *testing.T) {
 			go func(id int) {
 				defer wg.Done()
 				_, err := server.CreateDevice(ctx, &pb.CreateDeviceRequest{
-					Name: "Concurrent Device",
-					Type: "sensor",
+					OrgId: testOrgID,
+					Name:  "Concurrent Device",
+					Type:  "sensor",
 				})
 				if err != nil {
 					t.Errorf("concurrent create failed: %v", err)
@@ -544,8 +662,9 @@ func TestConcurrentOperations(t *testing.T) {
 	t.Run("concurrent_read_write", func(t *testing.T) {
 		// Create a device to update
 		createResp, err := server.CreateDevice(ctx, &pb.CreateDeviceRequest{
-			Name: "Shared Device",
-			Type: "sensor",
+			OrgId: testOrgID,
+			Name:  "Shared Device",
+			Type:  "sensor",
 		})
 		if err != nil {
 			t.Fatalf("failed to create device: %v", err)
@@ -595,3 +714,454 @@ func TestConcurrentOperations(t *testing.T) {
 		}
 	})
 }
+
+// TestUpsertDeviceType tests device type validation and registration.
+func TestUpsertDeviceType(t *testing.T) {
+	lower, upper := -10.0, 10.0
+
+	tests := []struct {
+		name        string
+		request     *pb.UpsertDeviceTypeRequest
+		wantErr     bool
+		wantCode    codes.Code
+		description string
+	}{
+		{
+			name: "valid_type",
+			request: &pb.UpsertDeviceTypeRequest{
+				DeviceType: &pb.DeviceType{
+					Name: "thermometer",
+					Metrics: []*pb.MetricDefinition{
+						{Name: "temperature", Unit: "celsius", MinValue: &lower, MaxValue: &upper, ReportingIntervalSeconds: 60},
+						{Name: "battery", Unit: "percent", DataType: pb.MetricDataType_METRIC_INTEGER},
+					},
+				},
+			},
+			wantErr:     false,
+			description: "should register a valid device type",
+		},
+		{
+			name:        "missing_type",
+			request:     &pb.UpsertDeviceTypeRequest{},
+			wantErr:     true,
+			wantCode:    codes.InvalidArgument,
+			description: "should return error when device type is missing",
+		},
+		{
+			name: "missing_name",
+			request: &pb.UpsertDeviceTypeRequest{
+				DeviceType: &pb.DeviceType{DisplayName: "Thermometer"},
+			},
+			wantErr:     true,
+			wantCode:    codes.InvalidArgument,
+			description: "should return error when name is missing",
+		},
+		{
+			name: "duplicate_metric",
+			request: &pb.UpsertDeviceTypeRequest{
+				DeviceType: &pb.DeviceType{
+					Name: "thermometer",
+					Metrics: []*pb.MetricDefinition{
+						{Name: "temperature"},
+						{Name: "temperature"},
+					},
+				},
+			},
+			wantErr:     true,
+			wantCode:    codes.InvalidArgument,
+			description: "should return error when a metric is declared twice",
+		},
+		{
+			name: "inverted_range",
+			request: &pb.UpsertDeviceTypeRequest{
+				DeviceType: &pb.DeviceType{
+					Name: "thermometer",
+					Metrics: []*pb.MetricDefinition{
+						{Name: "temperature", MinValue: &upper, MaxValue: &lower},
+					},
+				},
+			},
+			wantErr:     true,
+			wantCode:    codes.InvalidArgument,
+			description: "should return error when min is greater than max",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewDeviceServer(storage.NewMemoryStorage(), eventbus.NewMemory(0))
+			ctx := context.Background()
+
+			resp, err := server.UpsertDeviceType(ctx, tt.request)
+
+			if tt.wantErr {
+				if status.Code(err) != tt.wantCode {
+					t.Errorf("%s: expected code %v, got %v", tt.description, tt.wantCode, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", tt.description, err)
+			}
+
+			deviceType := resp.DeviceType
+			if deviceType.DisplayName != tt.request.DeviceType.Name {
+				t.Errorf("%s: display name should default to name, got %s", tt.description, deviceType.DisplayName)
+			}
+			if len(deviceType.Metrics) != len(tt.request.DeviceType.Metrics) {
+				t.Fatalf("%s: expected %d metrics, got %d", tt.description, len(tt.request.DeviceType.Metrics), len(deviceType.Metrics))
+			}
+
+			got, err := server.GetDeviceType(ctx, &pb.GetDeviceTypeRequest{Name: deviceType.Name})
+			if err != nil {
+				t.Fatalf("%s: GetDeviceType failed: %v", tt.description, err)
+			}
+			if got.DeviceType.Metrics[0].ReportingIntervalSeconds != 60 {
+				t.Errorf("%s: expected reporting interval 60, got %d", tt.description, got.DeviceType.Metrics[0].ReportingIntervalSeconds)
+			}
+		})
+	}
+}
+
+// TestDeleteDeviceType tests device type removal.
+func TestDeleteDeviceType(t *testing.T) {
+	server := NewDeviceServer(storage.NewMemoryStorage(), eventbus.NewMemory(0))
+	ctx := context.Background()
+
+	_, err := server.UpsertDeviceType(ctx, &pb.UpsertDeviceTypeRequest{
+		DeviceType: &pb.DeviceType{Name: "thermometer"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create test device type: %v", err)
+	}
+
+	resp, err := server.DeleteDeviceType(ctx, &pb.DeleteDeviceTypeRequest{Name: "thermometer"})
+	if err != nil {
+		t.Fatalf("DeleteDeviceType failed: %v", err)
+	}
+	if !resp.Success {
+		t.Error("expected success")
+	}
+
+	list, err := server.ListDeviceTypes(ctx, &pb.ListDeviceTypesRequest{})
+	if err != nil {
+		t.Fatalf("ListDeviceTypes failed: %v", err)
+	}
+	if len(list.DeviceTypes) != 0 {
+		t.Errorf("expected no device types, got %d", len(list.DeviceTypes))
+	}
+
+	_, err = server.DeleteDeviceType(ctx, &pb.DeleteDeviceTypeRequest{Name: "thermometer"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+}
+
+// TestLocations tests the location hierarchy and placing devices in it.
+func TestLocations(t *testing.T) {
+	server := NewDeviceServer(storage.NewMemoryStorage(), eventbus.NewMemory(0))
+	ctx := context.Background()
+
+	create := func(kind pb.LocationKind, parentID, name string) (*pb.Location, error) {
+		resp, err := server.CreateLocation(ctx, &pb.CreateLocationRequest{OrgId: testOrgID, Kind: kind, ParentId: parentID, Name: name})
+		if err != nil {
+			return nil, err
+		}
+		return resp.Location, nil
+	}
+
+	site, err := create(pb.LocationKind_LOCATION_SITE, "", "Lyon")
+	if err != nil {
+		t.Fatalf("CreateLocation(site) failed: %v", err)
+	}
+	building, err := create(pb.LocationKind_LOCATION_BUILDING, site.Id, "Bâtiment A")
+	if err != nil {
+		t.Fatalf("CreateLocation(building) failed: %v", err)
+	}
+	floor, err := create(pb.LocationKind_LOCATION_FLOOR, building.Id, "Étage 1")
+	if err != nil {
+		t.Fatalf("CreateLocation(floor) failed: %v", err)
+	}
+	room, err := create(pb.LocationKind_LOCATION_ROOM, floor.Id, "Salle 101")
+	if err != nil {
+		t.Fatalf("CreateLocation(room) failed: %v", err)
+	}
+
+	invalid := []struct {
+		name     string
+		kind     pb.LocationKind
+		parentID string
+	}{
+		{"site with parent", pb.LocationKind_LOCATION_SITE, site.Id},
+		{"building without parent", pb.LocationKind_LOCATION_BUILDING, ""},
+		{"room in a building", pb.LocationKind_LOCATION_ROOM, building.Id},
+		{"unknown parent", pb.LocationKind_LOCATION_BUILDING, "missing"},
+	}
+	for _, tt := range invalid {
+		if _, err := create(tt.kind, tt.parentID, tt.name); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: expected InvalidArgument, got %v", tt.name, err)
+		}
+	}
+	if _, err := server.CreateLocation(ctx, &pb.CreateLocationRequest{OrgId: "org-2", Kind: pb.LocationKind_LOCATION_BUILDING, ParentId: site.Id, Name: "Autre"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for a parent of another organization, got %v", err)
+	}
+
+	inRoom, err := server.CreateDevice(ctx, &pb.CreateDeviceRequest{OrgId: testOrgID, Name: "Sensor", Type: "temperature", LocationId: room.Id})
+	if err != nil {
+		t.Fatalf("CreateDevice in a room failed: %v", err)
+	}
+	if _, err := server.CreateDevice(ctx, &pb.CreateDeviceRequest{OrgId: testOrgID, Name: "Sensor", Type: "temperature"}); err != nil {
+		t.Fatalf("CreateDevice without location failed: %v", err)
+	}
+	if _, err := server.CreateDevice(ctx, &pb.CreateDeviceRequest{OrgId: "org-2", Name: "Sensor", Type: "temperature", LocationId: room.Id}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for a location of another organization, got %v", err)
+	}
+
+	// The devices of a site are those of its rooms
+	listResp, err := server.ListDevices(ctx, &pb.ListDevicesRequest{Page: 1, PageSize: 10, OrgId: testOrgID, LocationId: site.Id})
+	if err != nil {
+		t.Fatalf("ListDevices failed: %v", err)
+	}
+	if listResp.Total != 1 || listResp.Devices[0].Id != inRoom.Device.Id {
+		t.Errorf("expected the device of the room in the site, got %v", listResp.Devices)
+	}
+
+	// The location is kept by updates that do not set it, and cleared by an
+	// empty one
+	updateResp, err := server.UpdateDevice(ctx, &pb.UpdateDeviceRequest{Id: inRoom.Device.Id, Name: "Renamed"})
+	if err != nil {
+		t.Fatalf("UpdateDevice failed: %v", err)
+	}
+	if updateResp.Device.LocationId != room.Id {
+		t.Errorf("expected location %s to be kept, got %q", room.Id, updateResp.Device.LocationId)
+	}
+	noLocation := ""
+	updateResp, err = server.UpdateDevice(ctx, &pb.UpdateDeviceRequest{Id: inRoom.Device.Id, LocationId: &noLocation})
+	if err != nil {
+		t.Fatalf("UpdateDevice failed: %v", err)
+	}
+	if updateResp.Device.LocationId != "" {
+		t.Errorf("expected location to be cleared, got %q", updateResp.Device.LocationId)
+	}
+
+	if _, err := server.DeleteLocation(ctx, &pb.DeleteLocationRequest{Id: floor.Id, OrgId: testOrgID}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition deleting a location with sub-locations, got %v", err)
+	}
+	if _, err := server.DeleteLocation(ctx, &pb.DeleteLocationRequest{Id: room.Id, OrgId: testOrgID}); err != nil {
+		t.Errorf("DeleteLocation failed: %v", err)
+	}
+}
+
+// TestDeviceGroups tests static and dynamic groups and filtering devices by
+// group.
+func TestDeviceGroups(t *testing.T) {
+	server := NewDeviceServer(storage.NewMemoryStorage(), eventbus.NewMemory(0))
+	ctx := context.Background()
+
+	var ids []string
+	for _, req := range []*pb.CreateDeviceRequest{
+		{OrgId: testOrgID, Name: "Thermo 1", Type: "temperature", Metadata: map[string]string{"zone": "nord"}},
+		{OrgId: testOrgID, Name: "Thermo 2", Type: "temperature", Metadata: map[string]string{"zone": "sud"}},
+		{OrgId: testOrgID, Name: "Hygro", Type: "humidity", Metadata: map[string]string{"zone": "nord"}},
+	} {
+		resp, err := server.CreateDevice(ctx, req)
+		if err != nil {
+			t.Fatalf("CreateDevice failed: %v", err)
+		}
+		ids = append(ids, resp.Device.Id)
+	}
+	other, err := server.CreateDevice(ctx, &pb.CreateDeviceRequest{OrgId: "org-2", Name: "Thermo", Type: "temperature", Metadata: map[string]string{"zone": "nord"}})
+	if err != nil {
+		t.Fatalf("CreateDevice failed: %v", err)
+	}
+
+	dynamic, err := server.CreateDeviceGroup(ctx, &pb.CreateDeviceGroupRequest{
+		OrgId: testOrgID,
+		Name:  "Températures nord",
+		Kind:  pb.DeviceGroupKind_GROUP_DYNAMIC,
+		Rule:  &pb.DeviceGroupRule{Type: "temperature", Metadata: map[string]string{"zone": "nord"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateDeviceGroup(dynamic) failed: %v", err)
+	}
+	if dynamic.Group.DeviceCount != 1 {
+		t.Errorf("expected 1 device in the dynamic group, got %d", dynamic.Group.DeviceCount)
+	}
+
+	static, err := server.CreateDeviceGroup(ctx, &pb.CreateDeviceGroupRequest{OrgId: testOrgID, Name: "Pilote", Kind: pb.DeviceGroupKind_GROUP_STATIC})
+	if err != nil {
+		t.Fatalf("CreateDeviceGroup(static) failed: %v", err)
+	}
+	if _, err := server.CreateDeviceGroup(ctx, &pb.CreateDeviceGroupRequest{OrgId: testOrgID, Name: "Pilote", Kind: pb.DeviceGroupKind_GROUP_STATIC}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("expected AlreadyExists for a duplicate name, got %v", err)
+	}
+	if _, err := server.CreateDeviceGroup(ctx, &pb.CreateDeviceGroupRequest{OrgId: testOrgID, Name: "Vide", Kind: pb.DeviceGroupKind_GROUP_DYNAMIC}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for a dynamic group without rule, got %v", err)
+	}
+
+	addResp, err := server.AddGroupDevices(ctx, &pb.GroupDevicesRequest{GroupId: static.Group.Id, DeviceIds: []string{ids[1], ids[2]}, OrgId: testOrgID})
+	if err != nil {
+		t.Fatalf("AddGroupDevices failed: %v", err)
+	}
+	if addResp.Group.DeviceCount != 2 {
+		t.Errorf("expected 2 devices in the static group, got %d", addResp.Group.DeviceCount)
+	}
+	if _, err := server.AddGroupDevices(ctx, &pb.GroupDevicesRequest{GroupId: static.Group.Id, DeviceIds: []string{other.Device.Id}}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound adding a device of another organization, got %v", err)
+	}
+	if _, err := server.AddGroupDevices(ctx, &pb.GroupDevicesRequest{GroupId: dynamic.Group.Id, DeviceIds: []string{ids[1]}}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition adding to a dynamic group, got %v", err)
+	}
+
+	listResp, err := server.ListDevices(ctx, &pb.ListDevicesRequest{Page: 1, PageSize: 10, OrgId: testOrgID, GroupId: static.Group.Id, Type: "temperature"})
+	if err != nil {
+		t.Fatalf("ListDevices failed: %v", err)
+	}
+	if listResp.Total != 1 || listResp.Devices[0].Id != ids[1] {
+		t.Errorf("expected the temperature device of the static group, got %v", listResp.Devices)
+	}
+
+	groupsResp, err := server.ListDeviceGroups(ctx, &pb.ListDeviceGroupsRequest{OrgId: testOrgID, DeviceId: ids[0]})
+	if err != nil {
+		t.Fatalf("ListDeviceGroups failed: %v", err)
+	}
+	if len(groupsResp.Groups) != 1 || groupsResp.Groups[0].Id != dynamic.Group.Id {
+		t.Errorf("expected device %s in the dynamic group only, got %v", ids[0], groupsResp.Groups)
+	}
+
+	// Widening the rule selects the humidity sensor as well
+	updateResp, err := server.UpdateDeviceGroup(ctx, &pb.UpdateDeviceGroupRequest{
+		Id:    dynamic.Group.Id,
+		OrgId: testOrgID,
+		Rule:  &pb.DeviceGroupRule{Metadata: map[string]string{"zone": "nord"}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateDeviceGroup failed: %v", err)
+	}
+	if updateResp.Group.DeviceCount != 2 || updateResp.Group.Name != "Températures nord" {
+		t.Errorf("unexpected updated group %+v", updateResp.Group)
+	}
+
+	removeResp, err := server.RemoveGroupDevices(ctx, &pb.GroupDevicesRequest{GroupId: static.Group.Id, DeviceIds: []string{ids[2]}, OrgId: testOrgID})
+	if err != nil {
+		t.Fatalf("RemoveGroupDevices failed: %v", err)
+	}
+	if removeResp.Group.DeviceCount != 1 {
+		t.Errorf("expected 1 device left in the static group, got %d", removeResp.Group.DeviceCount)
+	}
+
+	if _, err := server.GetDeviceGroup(ctx, &pb.GetDeviceGroupRequest{Id: static.Group.Id, OrgId: "org-2"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound from another organization, got %v", err)
+	}
+	if _, err := server.DeleteDeviceGroup(ctx, &pb.DeleteDeviceGroupRequest{Id: static.Group.Id, OrgId: testOrgID}); err != nil {
+		t.Errorf("DeleteDeviceGroup failed: %v", err)
+	}
+}
+
+func TestAccessControl(t *testing.T) {
+	server := NewDeviceServer(storage.NewMemoryStorage(), eventbus.NewMemory(0))
+	ctx := context.Background()
+
+	var ids []string
+	for _, name := range []string{"Thermo site A", "Hygro site A", "Thermo site B"} {
+		resp, err := server.CreateDevice(ctx, &pb.CreateDeviceRequest{OrgId: testOrgID, Name: name, Type: "sensor"})
+		if err != nil {
+			t.Fatalf("CreateDevice failed: %v", err)
+		}
+		ids = append(ids, resp.Device.Id)
+	}
+	other, err := server.CreateDevice(ctx, &pb.CreateDeviceRequest{OrgId: "org-2", Name: "Thermo", Type: "sensor"})
+	if err != nil {
+		t.Fatalf("CreateDevice failed: %v", err)
+	}
+	siteA, err := server.CreateDeviceGroup(ctx, &pb.CreateDeviceGroupRequest{OrgId: testOrgID, Name: "Site A", Kind: pb.DeviceGroupKind_GROUP_STATIC})
+	if err != nil {
+		t.Fatalf("CreateDeviceGroup failed: %v", err)
+	}
+	if _, err := server.AddGroupDevices(ctx, &pb.GroupDevicesRequest{GroupId: siteA.Group.Id, DeviceIds: ids[:2], OrgId: testOrgID}); err != nil {
+		t.Fatalf("AddGroupDevices failed: %v", err)
+	}
+
+	groupEntry, err := server.CreateAccessEntry(ctx, &pb.CreateAccessEntryRequest{OrgId: testOrgID, UserId: "user-1", GroupId: siteA.Group.Id, Access: pb.AccessLevel_ACCESS_READ})
+	if err != nil {
+		t.Fatalf("CreateAccessEntry(group) failed: %v", err)
+	}
+	if _, err := server.CreateAccessEntry(ctx, &pb.CreateAccessEntryRequest{OrgId: testOrgID, UserId: "user-1", DeviceId: ids[0], Access: pb.AccessLevel_ACCESS_WRITE}); err != nil {
+		t.Fatalf("CreateAccessEntry(device) failed: %v", err)
+	}
+	if _, err := server.CreateAccessEntry(ctx, &pb.CreateAccessEntryRequest{OrgId: testOrgID, UserId: "user-1", GroupId: siteA.Group.Id, Access: pb.AccessLevel_ACCESS_READ}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("expected AlreadyExists for a duplicate entry, got %v", err)
+	}
+	if _, err := server.CreateAccessEntry(ctx, &pb.CreateAccessEntryRequest{OrgId: testOrgID, UserId: "user-1", Role: "viewer", DeviceId: ids[0]}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for two subjects, got %v", err)
+	}
+	if _, err := server.CreateAccessEntry(ctx, &pb.CreateAccessEntryRequest{OrgId: testOrgID, UserId: "user-1", DeviceId: other.Device.Id}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for a device of another organization, got %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		subject *pb.AccessSubject
+		want    int32
+	}{
+		{"restricted user", &pb.AccessSubject{UserId: "user-1", Role: "member"}, 2},
+		{"unrestricted user", &pb.AccessSubject{UserId: "user-2", Role: "member"}, 3},
+		{"no subject", nil, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := server.ListDevices(ctx, &pb.ListDevicesRequest{Page: 1, PageSize: 10, OrgId: testOrgID, Subject: tt.subject})
+			if err != nil {
+				t.Fatalf("ListDevices failed: %v", err)
+			}
+			if resp.Total != tt.want || int32(len(resp.Devices)) != tt.want {
+				t.Errorf("expected %d devices, got %d (total %d)", tt.want, len(resp.Devices), resp.Total)
+			}
+		})
+	}
+
+	subject := &pb.AccessSubject{UserId: "user-1", Role: "member"}
+	checkResp, err := server.CheckDeviceAccess(ctx, &pb.CheckDeviceAccessRequest{OrgId: testOrgID, Subject: subject, DeviceIds: ids, Access: pb.AccessLevel_ACCESS_READ})
+	if err != nil {
+		t.Fatalf("CheckDeviceAccess failed: %v", err)
+	}
+	if !checkResp.Restricted || len(checkResp.DeniedDeviceIds) != 1 || checkResp.DeniedDeviceIds[0] != ids[2] {
+		t.Errorf("expected read access denied to %s only, got %+v", ids[2], checkResp)
+	}
+	checkResp, err = server.CheckDeviceAccess(ctx, &pb.CheckDeviceAccessRequest{OrgId: testOrgID, Subject: subject, DeviceIds: ids[:2], Access: pb.AccessLevel_ACCESS_WRITE})
+	if err != nil {
+		t.Fatalf("CheckDeviceAccess failed: %v", err)
+	}
+	if len(checkResp.DeniedDeviceIds) != 1 || checkResp.DeniedDeviceIds[0] != ids[1] {
+		t.Errorf("expected write access denied to %s only, got %+v", ids[1], checkResp)
+	}
+
+	// Role entries restrict every member with the role
+	if _, err := server.CreateAccessEntry(ctx, &pb.CreateAccessEntryRequest{OrgId: testOrgID, Role: "viewer", DeviceId: ids[2]}); err != nil {
+		t.Fatalf("CreateAccessEntry(role) failed: %v", err)
+	}
+	listResp, err := server.ListDevices(ctx, &pb.ListDevicesRequest{Page: 1, PageSize: 10, OrgId: testOrgID, Subject: &pb.AccessSubject{UserId: "user-3", Role: "viewer"}})
+	if err != nil {
+		t.Fatalf("ListDevices failed: %v", err)
+	}
+	if listResp.Total != 1 || listResp.Devices[0].Id != ids[2] {
+		t.Errorf("expected only %s for the viewer role, got %v", ids[2], listResp.Devices)
+	}
+
+	entriesResp, err := server.ListAccessEntries(ctx, &pb.ListAccessEntriesRequest{OrgId: testOrgID, UserId: "user-1"})
+	if err != nil {
+		t.Fatalf("ListAccessEntries failed: %v", err)
+	}
+	if len(entriesResp.Entries) != 2 {
+		t.Errorf("expected 2 entries for user-1, got %d", len(entriesResp.Entries))
+	}
+
+	// Deleting the group drops its entries
+	if _, err := server.DeleteDeviceGroup(ctx, &pb.DeleteDeviceGroupRequest{Id: siteA.Group.Id, OrgId: testOrgID}); err != nil {
+		t.Fatalf("DeleteDeviceGroup failed: %v", err)
+	}
+	if _, err := server.DeleteAccessEntry(ctx, &pb.DeleteAccessEntryRequest{Id: groupEntry.Entry.Id, OrgId: testOrgID}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an entry of a deleted group, got %v", err)
+	}
+}
@@ -2,7 +2,11 @@ package storage
 
 import (
 	"context"
+	"sort"
 	"sync"
+	"time"
+
+	"github.com/google/uuid"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -13,14 +17,24 @@ import (
 // MemoryStorage implements Storage interface using in-memory map.
 // Thread-safe using RWMutex. Primarily for testing and development.
 type MemoryStorage struct {
-	mu      sync.RWMutex
-	devices map[string]*pb.Device
+	mu            sync.RWMutex
+	devices       map[string]*pb.Device
+	deviceTypes   map[string]*pb.DeviceType
+	locations     map[string]*pb.Location
+	groups        map[string]*pb.DeviceGroup
+	groupMembers  map[string]map[string]bool // group ID -> device IDs of static groups
+	accessEntries map[string]*pb.AccessEntry
 }
 
 // NewMemoryStorage creates a new in-memory storage instance.
 func NewMemoryStorage() *MemoryStorage {
 	return &MemoryStorage{
-		devices: make(map[string]*pb.Device),
+		devices:       make(map[string]*pb.Device),
+		deviceTypes:   make(map[string]*pb.DeviceType),
+		locations:     make(map[string]*pb.Location),
+		groups:        make(map[string]*pb.DeviceGroup),
+		groupMembers:  make(map[string]map[string]bool),
+		accessEntries: make(map[string]*pb.AccessEntry),
 	}
 }
 
@@ -30,73 +44,52 @@ func (s *MemoryStorage) CreateDevice(ctx context.Context, device *pb.Device) (*p
 	defer s.mu.Unlock()
 
 	// Store a copy to avoid external mutations
-	stored := &pb.Device{
-		Id:        device.Id,
-		Name:      device.Name,
-		Type:      device.Type,
-		Status:    device.Status,
-		CreatedAt: device.CreatedAt,
-		LastSeen:  device.LastSeen,
-		Metadata:  copyMetadata(device.Metadata),
-	}
+	stored := copyDevice(device)
 
 	s.devices[device.Id] = stored
-	return stored, nil
+	return copyDevice(stored), nil
 }
 
 // GetDevice implements Storage.GetDevice.
-func (s *MemoryStorage) GetDevice(ctx context.Context, id string) (*pb.Device, error) {
+func (s *MemoryStorage) GetDevice(ctx context.Context, orgID, id string) (*pb.Device, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	device, exists := s.devices[id]
-	if !exists {
+	if !exists || !inOrg(device, orgID) {
 		return nil, status.Errorf(codes.NotFound, "device %s not found", id)
 	}
 
-	// Return a copy
-	return &pb.Device{
-		Id:        device.Id,
-		Name:      device.Name,
-		Type:      device.Type,
-		Status:    device.Status,
-		CreatedAt: device.CreatedAt,
-		LastSeen:  device.LastSeen,
-		Metadata:  copyMetadata(device.Metadata),
-	}, nil
+	return copyDevice(device), nil
 }
 
 // ListDevices implements Storage.ListDevices.
-func (s *MemoryStorage) ListDevices(ctx context.Context, page, pageSize int32) ([]*pb.Device, int32, error) {
+func (s *MemoryStorage) ListDevices(ctx context.Context, orgID string, filter DeviceFilter, page, pageSize int32) ([]*pb.Device, int32, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	total := int32(len(s.devices))
-
 	// Simple pagination - collect all devices
 	devices := make([]*pb.Device, 0, len(s.devices))
 	for _, device := range s.devices {
-		devices = append(devices, &pb.Device{
-			Id:        device.Id,
-			Name:      device.Name,
-			Type:      device.Type,
-			Status:    device.Status,
-			CreatedAt: device.CreatedAt,
-			LastSeen:  device.LastSeen,
-			Metadata:  copyMetadata(device.Metadata),
-		})
+		if !inOrg(device, orgID) || !s.matchFilter(device, filter) {
+			continue
+		}
+		if entries := s.subjectEntries(orgID, filter.Subject); len(entries) > 0 && !s.granted(entries, device, pb.AccessLevel_ACCESS_READ) {
+			continue
+		}
+		devices = append(devices, copyDevice(device))
 	}
 
-	return devices, total, nil
+	return devices, int32(len(devices)), nil
 }
 
 // UpdateDevice implements Storage.UpdateDevice.
-func (s *MemoryStorage) UpdateDevice(ctx context.Context, device *pb.Device) (*pb.Device, error) {
+func (s *MemoryStorage) UpdateDevice(ctx context.Context, orgID string, device *pb.Device) (*pb.Device, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	existing, exists := s.devices[device.Id]
-	if !exists {
+	if !exists || !inOrg(existing, orgID) {
 		return nil, status.Errorf(codes.NotFound, "device %s not found", device.Id)
 	}
 
@@ -111,38 +104,584 @@ func (s *MemoryStorage) UpdateDevice(ctx context.Context, device *pb.Device) (*p
 		existing.Metadata = copyMetadata(device.Metadata)
 	}
 	existing.LastSeen = device.LastSeen
+	existing.LocationId = device.LocationId
 
-	// Return copy
-	return &pb.Device{
-		Id:        existing.Id,
-		Name:      existing.Name,
-		Type:      existing.Type,
-		Status:    existing.Status,
-		CreatedAt: existing.CreatedAt,
-		LastSeen:  existing.LastSeen,
-		Metadata:  copyMetadata(existing.Metadata),
-	}, nil
+	return copyDevice(existing), nil
 }
 
 // DeleteDevice implements Storage.DeleteDevice.
-func (s *MemoryStorage) DeleteDevice(ctx context.Context, id string) error {
+func (s *MemoryStorage) DeleteDevice(ctx context.Context, orgID, id string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.devices[id]; !exists {
+	if device, exists := s.devices[id]; !exists || !inOrg(device, orgID) {
 		return status.Errorf(codes.NotFound, "device %s not found", id)
 	}
 
 	delete(s.devices, id)
+	for _, members := range s.groupMembers {
+		delete(members, id)
+	}
+	for entryID, entry := range s.accessEntries {
+		if entry.DeviceId == id {
+			delete(s.accessEntries, entryID)
+		}
+	}
+	return nil
+}
+
+// UpsertDeviceType implements Storage.UpsertDeviceType.
+func (s *MemoryStorage) UpsertDeviceType(ctx context.Context, deviceType *pb.DeviceType) (*pb.DeviceType, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().Unix()
+	stored := copyDeviceType(deviceType)
+	stored.CreatedAt = now
+	stored.UpdatedAt = now
+	if existing, exists := s.deviceTypes[deviceType.Name]; exists {
+		stored.CreatedAt = existing.CreatedAt
+	}
+
+	s.deviceTypes[deviceType.Name] = stored
+	return copyDeviceType(stored), nil
+}
+
+// GetDeviceType implements Storage.GetDeviceType.
+func (s *MemoryStorage) GetDeviceType(ctx context.Context, name string) (*pb.DeviceType, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	deviceType, exists := s.deviceTypes[name]
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "device type %s not found", name)
+	}
+
+	return copyDeviceType(deviceType), nil
+}
+
+// ListDeviceTypes implements Storage.ListDeviceTypes.
+func (s *MemoryStorage) ListDeviceTypes(ctx context.Context) ([]*pb.DeviceType, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	deviceTypes := make([]*pb.DeviceType, 0, len(s.deviceTypes))
+	for _, deviceType := range s.deviceTypes {
+		deviceTypes = append(deviceTypes, copyDeviceType(deviceType))
+	}
+	sort.Slice(deviceTypes, func(i, j int) bool {
+		return deviceTypes[i].Name < deviceTypes[j].Name
+	})
+
+	return deviceTypes, nil
+}
+
+// DeleteDeviceType implements Storage.DeleteDeviceType.
+func (s *MemoryStorage) DeleteDeviceType(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.deviceTypes[name]; !exists {
+		return status.Errorf(codes.NotFound, "device type %s not found", name)
+	}
+
+	delete(s.deviceTypes, name)
 	return nil
 }
 
+// CreateLocation implements Storage.CreateLocation.
+func (s *MemoryStorage) CreateLocation(ctx context.Context, location *pb.Location) (*pb.Location, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := &pb.Location{
+		Id:        uuid.New().String(),
+		OrgId:     location.OrgId,
+		ParentId:  location.ParentId,
+		Kind:      location.Kind,
+		Name:      location.Name,
+		CreatedAt: time.Now().Unix(),
+	}
+
+	s.locations[stored.Id] = stored
+	return copyLocation(stored), nil
+}
+
+// GetLocation implements Storage.GetLocation.
+func (s *MemoryStorage) GetLocation(ctx context.Context, orgID, id string) (*pb.Location, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	location, exists := s.locations[id]
+	if !exists || (orgID != "" && location.OrgId != orgID) {
+		return nil, status.Errorf(codes.NotFound, "location %s not found", id)
+	}
+
+	return copyLocation(location), nil
+}
+
+// ListLocations implements Storage.ListLocations.
+func (s *MemoryStorage) ListLocations(ctx context.Context, orgID, parentID string) ([]*pb.Location, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	locations := make([]*pb.Location, 0, len(s.locations))
+	for _, location := range s.locations {
+		if orgID != "" && location.OrgId != orgID {
+			continue
+		}
+		if parentID != "" && location.ParentId != parentID {
+			continue
+		}
+		locations = append(locations, copyLocation(location))
+	}
+	sort.Slice(locations, func(i, j int) bool {
+		if locations[i].Name != locations[j].Name {
+			return locations[i].Name < locations[j].Name
+		}
+		return locations[i].Id < locations[j].Id
+	})
+
+	return locations, nil
+}
+
+// UpdateLocation implements Storage.UpdateLocation.
+func (s *MemoryStorage) UpdateLocation(ctx context.Context, orgID, id, name string) (*pb.Location, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	location, exists := s.locations[id]
+	if !exists || (orgID != "" && location.OrgId != orgID) {
+		return nil, status.Errorf(codes.NotFound, "location %s not found", id)
+	}
+
+	location.Name = name
+	return copyLocation(location), nil
+}
+
+// DeleteLocation implements Storage.DeleteLocation.
+func (s *MemoryStorage) DeleteLocation(ctx context.Context, orgID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	location, exists := s.locations[id]
+	if !exists || (orgID != "" && location.OrgId != orgID) {
+		return status.Errorf(codes.NotFound, "location %s not found", id)
+	}
+	for _, child := range s.locations {
+		if child.ParentId == id {
+			return status.Errorf(codes.FailedPrecondition, "location %s has sub-locations", id)
+		}
+	}
+
+	delete(s.locations, id)
+	for _, device := range s.devices {
+		if device.LocationId == id {
+			device.LocationId = ""
+		}
+	}
+	return nil
+}
+
+// CreateDeviceGroup implements Storage.CreateDeviceGroup.
+func (s *MemoryStorage) CreateDeviceGroup(ctx context.Context, group *pb.DeviceGroup) (*pb.DeviceGroup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.groups {
+		if existing.OrgId == group.OrgId && existing.Name == group.Name {
+			return nil, status.Errorf(codes.AlreadyExists, "device group %s already exists", group.Name)
+		}
+	}
+
+	now := time.Now().Unix()
+	stored := copyDeviceGroup(group)
+	stored.Id = uuid.New().String()
+	stored.DeviceCount = 0
+	stored.CreatedAt = now
+	stored.UpdatedAt = now
+
+	s.groups[stored.Id] = stored
+	if stored.Kind == pb.DeviceGroupKind_GROUP_STATIC {
+		s.groupMembers[stored.Id] = make(map[string]bool)
+	}
+	return s.groupWithCount(stored), nil
+}
+
+// GetDeviceGroup implements Storage.GetDeviceGroup.
+func (s *MemoryStorage) GetDeviceGroup(ctx context.Context, orgID, id string) (*pb.DeviceGroup, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	group, exists := s.groups[id]
+	if !exists || (orgID != "" && group.OrgId != orgID) {
+		return nil, status.Errorf(codes.NotFound, "device group %s not found", id)
+	}
+
+	return s.groupWithCount(group), nil
+}
+
+// ListDeviceGroups implements Storage.ListDeviceGroups.
+func (s *MemoryStorage) ListDeviceGroups(ctx context.Context, orgID, deviceID string) ([]*pb.DeviceGroup, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	device := s.devices[deviceID]
+	groups := make([]*pb.DeviceGroup, 0, len(s.groups))
+	for _, group := range s.groups {
+		if orgID != "" && group.OrgId != orgID {
+			continue
+		}
+		if deviceID != "" && (device == nil || !s.inGroup(device, group)) {
+			continue
+		}
+		groups = append(groups, s.groupWithCount(group))
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Name < groups[j].Name
+	})
+
+	return groups, nil
+}
+
+// UpdateDeviceGroup implements Storage.UpdateDeviceGroup.
+func (s *MemoryStorage) UpdateDeviceGroup(ctx context.Context, orgID string, group *pb.DeviceGroup) (*pb.DeviceGroup, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.groups[group.Id]
+	if !exists || (orgID != "" && existing.OrgId != orgID) {
+		return nil, status.Errorf(codes.NotFound, "device group %s not found", group.Id)
+	}
+	for _, other := range s.groups {
+		if other.Id != group.Id && other.OrgId == existing.OrgId && other.Name == group.Name {
+			return nil, status.Errorf(codes.AlreadyExists, "device group %s already exists", group.Name)
+		}
+	}
+
+	existing.Name = group.Name
+	existing.Description = group.Description
+	existing.Rule = copyGroupRule(group.Rule)
+	existing.UpdatedAt = time.Now().Unix()
+	return s.groupWithCount(existing), nil
+}
+
+// DeleteDeviceGroup implements Storage.DeleteDeviceGroup.
+func (s *MemoryStorage) DeleteDeviceGroup(ctx context.Context, orgID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if group, exists := s.groups[id]; !exists || (orgID != "" && group.OrgId != orgID) {
+		return status.Errorf(codes.NotFound, "device group %s not found", id)
+	}
+
+	delete(s.groups, id)
+	delete(s.groupMembers, id)
+	for entryID, entry := range s.accessEntries {
+		if entry.GroupId == id {
+			delete(s.accessEntries, entryID)
+		}
+	}
+	return nil
+}
+
+// AddGroupDevices implements Storage.AddGroupDevices.
+func (s *MemoryStorage) AddGroupDevices(ctx context.Context, groupID string, deviceIDs []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group, exists := s.groups[groupID]
+	if !exists {
+		return status.Errorf(codes.NotFound, "device group %s not found", groupID)
+	}
+	for _, id := range deviceIDs {
+		if device, exists := s.devices[id]; !exists || device.OrgId != group.OrgId {
+			return status.Errorf(codes.NotFound, "device %s not found", id)
+		}
+	}
+
+	members := s.groupMembers[groupID]
+	for _, id := range deviceIDs {
+		members[id] = true
+	}
+	return nil
+}
+
+// RemoveGroupDevices implements Storage.RemoveGroupDevices.
+func (s *MemoryStorage) RemoveGroupDevices(ctx context.Context, groupID string, deviceIDs []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.groups[groupID]; !exists {
+		return status.Errorf(codes.NotFound, "device group %s not found", groupID)
+	}
+
+	for _, id := range deviceIDs {
+		delete(s.groupMembers[groupID], id)
+	}
+	return nil
+}
+
+// CreateAccessEntry implements Storage.CreateAccessEntry.
+func (s *MemoryStorage) CreateAccessEntry(ctx context.Context, entry *pb.AccessEntry) (*pb.AccessEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.accessEntries {
+		if existing.OrgId == entry.OrgId && existing.UserId == entry.UserId && existing.Role == entry.Role &&
+			existing.DeviceId == entry.DeviceId && existing.GroupId == entry.GroupId && existing.Access == entry.Access {
+			return nil, status.Error(codes.AlreadyExists, "access entry already exists")
+		}
+	}
+
+	stored := copyAccessEntry(entry)
+	stored.Id = uuid.New().String()
+	stored.CreatedAt = time.Now().Unix()
+
+	s.accessEntries[stored.Id] = stored
+	return copyAccessEntry(stored), nil
+}
+
+// ListAccessEntries implements Storage.ListAccessEntries.
+func (s *MemoryStorage) ListAccessEntries(ctx context.Context, orgID string, filter AccessEntryFilter) ([]*pb.AccessEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := make([]*pb.AccessEntry, 0, len(s.accessEntries))
+	for _, entry := range s.accessEntries {
+		if (orgID != "" && entry.OrgId != orgID) ||
+			(filter.UserID != "" && entry.UserId != filter.UserID) ||
+			(filter.Role != "" && entry.Role != filter.Role) ||
+			(filter.DeviceID != "" && entry.DeviceId != filter.DeviceID) ||
+			(filter.GroupID != "" && entry.GroupId != filter.GroupID) {
+			continue
+		}
+		entries = append(entries, copyAccessEntry(entry))
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].CreatedAt != entries[j].CreatedAt {
+			return entries[i].CreatedAt < entries[j].CreatedAt
+		}
+		return entries[i].Id < entries[j].Id
+	})
+
+	return entries, nil
+}
+
+// DeleteAccessEntry implements Storage.DeleteAccessEntry.
+func (s *MemoryStorage) DeleteAccessEntry(ctx context.Context, orgID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, exists := s.accessEntries[id]; !exists || (orgID != "" && entry.OrgId != orgID) {
+		return status.Errorf(codes.NotFound, "access entry %s not found", id)
+	}
+
+	delete(s.accessEntries, id)
+	return nil
+}
+
+// CheckDeviceAccess implements Storage.CheckDeviceAccess.
+func (s *MemoryStorage) CheckDeviceAccess(ctx context.Context, orgID string, subject *pb.AccessSubject, deviceIDs []string, access pb.AccessLevel) (bool, []string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := s.subjectEntries(orgID, subject)
+	if len(entries) == 0 {
+		return false, nil, nil
+	}
+
+	var denied []string
+	for _, id := range deviceIDs {
+		device, exists := s.devices[id]
+		if !exists || !s.granted(entries, device, access) {
+			denied = append(denied, id)
+		}
+	}
+	return true, denied, nil
+}
+
+// subjectEntries returns the ACL entries of a user in orgID, directly or
+// through their role. Callers hold the lock.
+func (s *MemoryStorage) subjectEntries(orgID string, subject *pb.AccessSubject) []*pb.AccessEntry {
+	if orgID == "" || subject == nil {
+		return nil
+	}
+
+	var entries []*pb.AccessEntry
+	for _, entry := range s.accessEntries {
+		if entry.OrgId != orgID {
+			continue
+		}
+		if (subject.UserId != "" && entry.UserId == subject.UserId) || (subject.Role != "" && entry.Role == subject.Role) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// granted reports whether entries grant access to a device. Any entry grants
+// read access. Callers hold the lock.
+func (s *MemoryStorage) granted(entries []*pb.AccessEntry, device *pb.Device, access pb.AccessLevel) bool {
+	for _, entry := range entries {
+		if access != pb.AccessLevel_ACCESS_READ && entry.Access != access {
+			continue
+		}
+		if entry.DeviceId == device.Id {
+			return true
+		}
+		if group, exists := s.groups[entry.GroupId]; exists && s.inGroup(device, group) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFilter reports whether a device matches filter. Callers hold the lock.
+func (s *MemoryStorage) matchFilter(device *pb.Device, filter DeviceFilter) bool {
+	if filter.Type != "" && device.Type != filter.Type {
+		return false
+	}
+	if filter.Status != pb.DeviceStatus_UNKNOWN && device.Status != filter.Status {
+		return false
+	}
+	if filter.GroupID != "" {
+		group, exists := s.groups[filter.GroupID]
+		if !exists || !s.inGroup(device, group) {
+			return false
+		}
+	}
+	if filter.LocationID != "" && !s.inLocation(device.LocationId, filter.LocationID) {
+		return false
+	}
+	return true
+}
+
+// inGroup reports whether a device belongs to a group. Callers hold the lock.
+func (s *MemoryStorage) inGroup(device *pb.Device, group *pb.DeviceGroup) bool {
+	if device.OrgId != group.OrgId {
+		return false
+	}
+	if group.Kind == pb.DeviceGroupKind_GROUP_STATIC {
+		return s.groupMembers[group.Id][device.Id]
+	}
+	if group.Rule.GetType() != "" && device.Type != group.Rule.GetType() {
+		return false
+	}
+	for key, value := range group.Rule.GetMetadata() {
+		if v, ok := device.Metadata[key]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// inLocation reports whether locationID is ancestorID or one of its
+// sub-locations. Callers hold the lock.
+func (s *MemoryStorage) inLocation(locationID, ancestorID string) bool {
+	for locationID != "" {
+		if locationID == ancestorID {
+			return true
+		}
+		location, exists := s.locations[locationID]
+		if !exists {
+			return false
+		}
+		locationID = location.ParentId
+	}
+	return false
+}
+
+// groupWithCount returns a copy of a group with its device count. Callers
+// hold the lock.
+func (s *MemoryStorage) groupWithCount(group *pb.DeviceGroup) *pb.DeviceGroup {
+	dst := copyDeviceGroup(group)
+	for _, device := range s.devices {
+		if s.inGroup(device, group) {
+			dst.DeviceCount++
+		}
+	}
+	return dst
+}
+
 // Close implements Storage.Close.
 func (s *MemoryStorage) Close() error {
 	// Nothing to clean up for in-memory storage
 	return nil
 }
 
+// Helper function to check that a device belongs to orgID, any organization if empty
+func inOrg(device *pb.Device, orgID string) bool {
+	return orgID == "" || device.OrgId == orgID
+}
+
+// Helper function to copy a device
+func copyDevice(src *pb.Device) *pb.Device {
+	return &pb.Device{
+		Id:         src.Id,
+		Name:       src.Name,
+		Type:       src.Type,
+		Status:     src.Status,
+		CreatedAt:  src.CreatedAt,
+		LastSeen:   src.LastSeen,
+		Metadata:   copyMetadata(src.Metadata),
+		OrgId:      src.OrgId,
+		LocationId: src.LocationId,
+	}
+}
+
+// Helper function to copy a location
+func copyLocation(src *pb.Location) *pb.Location {
+	return &pb.Location{
+		Id:        src.Id,
+		OrgId:     src.OrgId,
+		ParentId:  src.ParentId,
+		Kind:      src.Kind,
+		Name:      src.Name,
+		CreatedAt: src.CreatedAt,
+	}
+}
+
+// Helper function to copy a device group and its rule
+func copyDeviceGroup(src *pb.DeviceGroup) *pb.DeviceGroup {
+	return &pb.DeviceGroup{
+		Id:          src.Id,
+		OrgId:       src.OrgId,
+		Name:        src.Name,
+		Description: src.Description,
+		Kind:        src.Kind,
+		Rule:        copyGroupRule(src.Rule),
+		DeviceCount: src.DeviceCount,
+		CreatedAt:   src.CreatedAt,
+		UpdatedAt:   src.UpdatedAt,
+	}
+}
+
+// Helper function to copy an ACL entry
+func copyAccessEntry(src *pb.AccessEntry) *pb.AccessEntry {
+	return &pb.AccessEntry{
+		Id:        src.Id,
+		OrgId:     src.OrgId,
+		UserId:    src.UserId,
+		Role:      src.Role,
+		DeviceId:  src.DeviceId,
+		GroupId:   src.GroupId,
+		Access:    src.Access,
+		CreatedAt: src.CreatedAt,
+	}
+}
+
+// Helper function to copy a group rule
+func copyGroupRule(src *pb.DeviceGroupRule) *pb.DeviceGroupRule {
+	if src == nil {
+		return nil
+	}
+	return &pb.DeviceGroupRule{
+		Type:     src.Type,
+		Metadata: copyMetadata(src.Metadata),
+	}
+}
+
 // Helper function to copy metadata map
 func copyMetadata(src map[string]string) map[string]string {
 	if src == nil {
@@ -154,3 +693,36 @@ func copyMetadata(src map[string]string) map[string]string {
 	}
 	return dst
 }
+
+// Helper function to deep copy a device type and its metrics
+func copyDeviceType(src *pb.DeviceType) *pb.DeviceType {
+	dst := &pb.DeviceType{
+		Name:        src.Name,
+		DisplayName: src.DisplayName,
+		Description: src.Description,
+		CreatedAt:   src.CreatedAt,
+		UpdatedAt:   src.UpdatedAt,
+		Metrics:     make([]*pb.MetricDefinition, len(src.Metrics)),
+	}
+	for i, metric := range src.Metrics {
+		dst.Metrics[i] = &pb.MetricDefinition{
+			Name:                     metric.Name,
+			DisplayName:              metric.DisplayName,
+			Unit:                     metric.Unit,
+			DataType:                 metric.DataType,
+			MinValue:                 copyFloat(metric.MinValue),
+			MaxValue:                 copyFloat(metric.MaxValue),
+			ReportingIntervalSeconds: metric.ReportingIntervalSeconds,
+		}
+	}
+	return dst
+}
+
+// Helper function to copy an optional value
+func copyFloat(src *float64) *float64 {
+	if src == nil {
+		return nil
+	}
+	v := *src
+	return &v
+}
@@ -58,7 +58,7 @@ func TestMemoryStorage_GetDevice(t *testing.T) {
 	}
 
 	// Get existing device
-	retrieved, err := storage.GetDevice(ctx, "device-123")
+	retrieved, err := storage.GetDevice(ctx, "", "device-123")
 	if err != nil {
 		t.Fatalf("GetDevice() failed: %v", err)
 	}
@@ -68,7 +68,7 @@ func TestMemoryStorage_GetDevice(t *testing.T) {
 	}
 
 	// Get non-existent device
-	_, err = storage.GetDevice(ctx, "non-existent")
+	_, err = storage.GetDevice(ctx, "", "non-existent")
 	if err == nil {
 		t.Error("GetDevice() should fail for non-existent device")
 	}
@@ -96,7 +96,7 @@ func TestMemoryStorage_UpdateDevice(t *testing.T) {
 	device.Name = "Updated Name"
 	device.Status = pb.DeviceStatus_OFFLINE
 
-	updated, err := storage.UpdateDevice(ctx, device)
+	updated, err := storage.UpdateDevice(ctx, "", device)
 	if err != nil {
 		t.Fatalf("UpdateDevice() failed: %v", err)
 	}
@@ -128,13 +128,13 @@ func TestMemoryStorage_DeleteDevice(t *testing.T) {
 	}
 
 	// Delete device
-	err = storage.DeleteDevice(ctx, "device-123")
+	err = storage.DeleteDevice(ctx, "", "device-123")
 	if err != nil {
 		t.Fatalf("DeleteDevice() failed: %v", err)
 	}
 
 	// Verify device is deleted
-	_, err = storage.GetDevice(ctx, "device-123")
+	_, err = storage.GetDevice(ctx, "", "device-123")
 	if err == nil {
 		t.Error("GetDevice() should fail after deletion")
 	}
@@ -161,7 +161,7 @@ func TestMemoryStorage_ListDevices(t *testing.T) {
 	}
 
 	// List all devices
-	devices, total, err := storage.ListDevices(ctx, 1, 10)
+	devices, total, err := storage.ListDevices(ctx, "", DeviceFilter{}, 1, 10)
 	if err != nil {
 		t.Fatalf("ListDevices() failed: %v", err)
 	}
@@ -174,6 +174,47 @@ func TestMemoryStorage_ListDevices(t *testing.T) {
 	}
 }
 
+func TestMemoryStorage_OrganizationScope(t *testing.T) {
+	ctx := context.Background()
+	storage := NewMemoryStorage()
+
+	for _, device := range []*pb.Device{
+		{Id: "device-1", Name: "Device A", Type: "sensor", OrgId: "org-1"},
+		{Id: "device-2", Name: "Device B", Type: "sensor", OrgId: "org-2"},
+	} {
+		if _, err := storage.CreateDevice(ctx, device); err != nil {
+			t.Fatalf("CreateDevice() failed: %v", err)
+		}
+	}
+
+	devices, total, err := storage.ListDevices(ctx, "org-1", DeviceFilter{}, 1, 10)
+	if err != nil {
+		t.Fatalf("ListDevices() failed: %v", err)
+	}
+	if total != 1 || len(devices) != 1 || devices[0].Id != "device-1" {
+		t.Errorf("ListDevices(org-1) = %v (total %d), want device-1", devices, total)
+	}
+
+	if _, err := storage.GetDevice(ctx, "org-1", "device-2"); err == nil {
+		t.Error("GetDevice() should fail for a device of another organization")
+	}
+	if _, err := storage.UpdateDevice(ctx, "org-1", &pb.Device{Id: "device-2", Name: "Renamed"}); err == nil {
+		t.Error("UpdateDevice() should fail for a device of another organization")
+	}
+	if err := storage.DeleteDevice(ctx, "org-1", "device-2"); err == nil {
+		t.Error("DeleteDevice() should fail for a device of another organization")
+	}
+
+	// Unscoped calls see every organization
+	device, err := storage.GetDevice(ctx, "", "device-2")
+	if err != nil {
+		t.Fatalf("GetDevice() failed: %v", err)
+	}
+	if device.OrgId != "org-2" || device.Name != "Device B" {
+		t.Errorf("GetDevice() = %v, want Device B of org-2", device)
+	}
+}
+
 func TestMemoryStorage_Close(t *testing.T) {
 	storage := NewMemoryStorage()
 	err := storage.Close()
@@ -181,3 +222,64 @@ func TestMemoryStorage_Close(t *testing.T) {
 		t.Errorf("Close() should not return error: %v", err)
 	}
 }
+
+func TestMemoryStorage_DeviceTypes(t *testing.T) {
+	ctx := context.Background()
+	storage := NewMemoryStorage()
+
+	maxValue := 50.0
+	deviceType := &pb.DeviceType{
+		Name:        "thermometer",
+		DisplayName: "Thermometer",
+		Metrics: []*pb.MetricDefinition{
+			{Name: "temperature", DisplayName: "Temperature", Unit: "celsius", MaxValue: &maxValue},
+		},
+	}
+
+	created, err := storage.UpsertDeviceType(ctx, deviceType)
+	if err != nil {
+		t.Fatalf("UpsertDeviceType() failed: %v", err)
+	}
+	if created.CreatedAt == 0 {
+		t.Error("CreatedAt should be set")
+	}
+
+	// Stored copy must not share the caller's values
+	maxValue = 100
+	got, err := storage.GetDeviceType(ctx, "thermometer")
+	if err != nil {
+		t.Fatalf("GetDeviceType() failed: %v", err)
+	}
+	if len(got.Metrics) != 1 || got.Metrics[0].GetMaxValue() != 50 {
+		t.Errorf("Metrics = %v, want temperature with max 50", got.Metrics)
+	}
+
+	// Upsert replaces the catalog
+	_, err = storage.UpsertDeviceType(ctx, &pb.DeviceType{Name: "thermometer", DisplayName: "Thermometer"})
+	if err != nil {
+		t.Fatalf("UpsertDeviceType() failed: %v", err)
+	}
+	got, _ = storage.GetDeviceType(ctx, "thermometer")
+	if len(got.Metrics) != 0 {
+		t.Errorf("Metrics count = %d, want 0", len(got.Metrics))
+	}
+
+	_, _ = storage.UpsertDeviceType(ctx, &pb.DeviceType{Name: "gateway", DisplayName: "Gateway"})
+	types, err := storage.ListDeviceTypes(ctx)
+	if err != nil {
+		t.Fatalf("ListDeviceTypes() failed: %v", err)
+	}
+	if len(types) != 2 || types[0].Name != "gateway" {
+		t.Errorf("ListDeviceTypes() = %v, want gateway then thermometer", types)
+	}
+
+	if err := storage.DeleteDeviceType(ctx, "thermometer"); err != nil {
+		t.Fatalf("DeleteDeviceType() failed: %v", err)
+	}
+	if _, err := storage.GetDeviceType(ctx, "thermometer"); err == nil {
+		t.Error("GetDeviceType() should fail after deletion")
+	}
+	if err := storage.DeleteDeviceType(ctx, "thermometer"); err == nil {
+		t.Error("DeleteDeviceType() should fail for unknown type")
+	}
+}
@@ -3,10 +3,12 @@ package storage
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"google.golang.org/grpc/codes"
@@ -42,6 +44,38 @@ func NewPostgresStorage(ctx context.Context, dsn string) (*PostgresStorage, erro
 	}, nil
 }
 
+// withOrg runs fn with queries scoped to orgID. Besides the explicit org_id
+// filters of the queries, the transaction sets app.org_id for the row-level
+// security policy of the devices table. An empty orgID runs fn unscoped.
+func (s *PostgresStorage) withOrg(ctx context.Context, orgID string, fn func(queries *sqlc.Queries, org pgtype.UUID) error) error {
+	if orgID == "" {
+		return fn(s.queries, pgtype.UUID{})
+	}
+
+	var org pgtype.UUID
+	if err := org.Scan(orgID); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid organization ID: %v", err)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, "SELECT set_config('app.org_id', $1, true)", orgID); err != nil {
+		return fmt.Errorf("failed to set organization: %w", err)
+	}
+	if err := fn(s.queries.WithTx(tx), org); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
 // CreateDevice implements Storage.CreateDevice.
 func (s *PostgresStorage) CreateDevice(ctx context.Context, device *pb.Device) (*pb.Device, error) {
 	// Convert metadata map to JSONB
@@ -70,17 +104,34 @@ func (s *PostgresStorage) CreateDevice(ctx context.Context, device *pb.Device) (
 		return nil, fmt.Errorf("failed to parse last_seen: %w", err)
 	}
 
+	location, err := optionalUUID(device.LocationId, "location")
+	if err != nil {
+		return nil, err
+	}
+
 	// Insert device
-	dbDevice, err := s.queries.CreateDevice(ctx, sqlc.CreateDeviceParams{
-		ID:        pgUUID,
-		Name:      device.Name,
-		Type:      device.Type,
-		Status:    dbStatus,
-		CreatedAt: createdAt,
-		LastSeen:  lastSeen,
-		Metadata:  metadataJSON,
+	var dbDevice sqlc.Device
+	err = s.withOrg(ctx, device.OrgId, func(queries *sqlc.Queries, org pgtype.UUID) error {
+		if !org.Valid {
+			return status.Error(codes.InvalidArgument, "organization ID is required")
+		}
+		dbDevice, err = queries.CreateDevice(ctx, sqlc.CreateDeviceParams{
+			ID:         pgUUID,
+			Name:       device.Name,
+			Type:       device.Type,
+			Status:     dbStatus,
+			CreatedAt:  createdAt,
+			LastSeen:   lastSeen,
+			Metadata:   metadataJSON,
+			OrgID:      org,
+			LocationID: location,
+		})
+		return err
 	})
 	if err != nil {
+		if status.Code(err) != codes.Unknown {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to create device: %w", err)
 	}
 
@@ -88,17 +139,25 @@ func (s *PostgresStorage) CreateDevice(ctx context.Context, device *pb.Device) (
 }
 
 // GetDevice implements Storage.GetDevice.
-func (s *PostgresStorage) GetDevice(ctx context.Context, id string) (*pb.Device, error) {
+func (s *PostgresStorage) GetDevice(ctx context.Context, orgID, id string) (*pb.Device, error) {
 	var pgUUID pgtype.UUID
 	if err := pgUUID.Scan(id); err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid device ID: %v", err)
 	}
 
-	dbDevice, err := s.queries.GetDevice(ctx, pgUUID)
+	var dbDevice sqlc.Device
+	err := s.withOrg(ctx, orgID, func(queries *sqlc.Queries, org pgtype.UUID) error {
+		var err error
+		dbDevice, err = queries.GetDevice(ctx, sqlc.GetDeviceParams{ID: pgUUID, OrgID: org})
+		return err
+	})
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, status.Errorf(codes.NotFound, "device %s not found", id)
 		}
+		if status.Code(err) == codes.InvalidArgument {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to get device: %w", err)
 	}
 
@@ -106,23 +165,65 @@ func (s *PostgresStorage) GetDevice(ctx context.Context, id string) (*pb.Device,
 }
 
 // ListDevices implements Storage.ListDevices.
-func (s *PostgresStorage) ListDevices(ctx context.Context, page, pageSize int32) ([]*pb.Device, int32, error) {
-	// Get total count
-	total, err := s.queries.CountDevices(ctx)
+func (s *PostgresStorage) ListDevices(ctx context.Context, orgID string, filter DeviceFilter, page, pageSize int32) ([]*pb.Device, int32, error) {
+	// Calculate offset
+	offset := (page - 1) * pageSize
+
+	groupID, err := optionalUUID(filter.GroupID, "group")
+	if err != nil {
+		return nil, 0, err
+	}
+	locationID, err := optionalUUID(filter.LocationID, "location")
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count devices: %w", err)
+		return nil, 0, err
 	}
+	deviceStatus := sqlc.NullDeviceStatus{
+		DeviceStatus: protoStatusToDBStatus(filter.Status),
+		Valid:        filter.Status != pb.DeviceStatus_UNKNOWN,
+	}
+	aclUserID, err := optionalUUID(filter.Subject.GetUserId(), "user")
+	if err != nil {
+		return nil, 0, err
+	}
+	aclRole := nullableString(filter.Subject.GetRole())
 
-	// Calculate offset
-	offset := (page - 1) * pageSize
+	var total int64
+	var dbDevices []sqlc.Device
+	err = s.withOrg(ctx, orgID, func(queries *sqlc.Queries, org pgtype.UUID) error {
+		// Get total count
+		var err error
+		total, err = queries.CountDevices(ctx, sqlc.CountDevicesParams{
+			OrgID:      org,
+			Type:       nullableString(filter.Type),
+			Status:     deviceStatus,
+			GroupID:    groupID,
+			LocationID: locationID,
+			AclUserID:  aclUserID,
+			AclRole:    aclRole,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to count devices: %w", err)
+		}
 
-	// Get devices
-	dbDevices, err := s.queries.ListDevices(ctx, sqlc.ListDevicesParams{
-		Limit:  pageSize,
-		Offset: offset,
+		// Get devices
+		dbDevices, err = queries.ListDevices(ctx, sqlc.ListDevicesParams{
+			OrgID:      org,
+			Type:       nullableString(filter.Type),
+			Status:     deviceStatus,
+			GroupID:    groupID,
+			LocationID: locationID,
+			AclUserID:  aclUserID,
+			AclRole:    aclRole,
+			PageLimit:  pageSize,
+			PageOffset: offset,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list devices: %w", err)
+		}
+		return nil
 	})
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list devices: %w", err)
+		return nil, 0, err
 	}
 
 	// Convert to proto
@@ -139,7 +240,7 @@ func (s *PostgresStorage) ListDevices(ctx context.Context, page, pageSize int32)
 }
 
 // UpdateDevice implements Storage.UpdateDevice.
-func (s *PostgresStorage) UpdateDevice(ctx context.Context, device *pb.Device) (*pb.Device, error) {
+func (s *PostgresStorage) UpdateDevice(ctx context.Context, orgID string, device *pb.Device) (*pb.Device, error) {
 	var pgUUID pgtype.UUID
 	if err := pgUUID.Scan(device.Id); err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid device ID: %v", err)
@@ -164,18 +265,32 @@ func (s *PostgresStorage) UpdateDevice(ctx context.Context, device *pb.Device) (
 		return nil, fmt.Errorf("failed to parse last_seen: %w", err)
 	}
 
+	location, err := optionalUUID(device.LocationId, "location")
+	if err != nil {
+		return nil, err
+	}
+
 	// Update device
-	dbDevice, err := s.queries.UpdateDevice(ctx, sqlc.UpdateDeviceParams{
-		ID:       pgUUID,
-		Name:     device.Name,
-		Status:   dbStatus,
-		Metadata: metadataJSON,
-		LastSeen: lastSeen,
+	var dbDevice sqlc.Device
+	err = s.withOrg(ctx, orgID, func(queries *sqlc.Queries, org pgtype.UUID) error {
+		dbDevice, err = queries.UpdateDevice(ctx, sqlc.UpdateDeviceParams{
+			ID:         pgUUID,
+			Name:       device.Name,
+			Status:     dbStatus,
+			Metadata:   metadataJSON,
+			LastSeen:   lastSeen,
+			LocationID: location,
+			OrgID:      org,
+		})
+		return err
 	})
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, status.Errorf(codes.NotFound, "device %s not found", device.Id)
 		}
+		if status.Code(err) == codes.InvalidArgument {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to update device: %w", err)
 	}
 
@@ -183,29 +298,692 @@ func (s *PostgresStorage) UpdateDevice(ctx context.Context, device *pb.Device) (
 }
 
 // DeleteDevice implements Storage.DeleteDevice.
-func (s *PostgresStorage) DeleteDevice(ctx context.Context, id string) error {
+func (s *PostgresStorage) DeleteDevice(ctx context.Context, orgID, id string) error {
 	var pgUUID pgtype.UUID
 	if err := pgUUID.Scan(id); err != nil {
 		return status.Errorf(codes.InvalidArgument, "invalid device ID: %v", err)
 	}
 
-	// Check if device exists first
-	_, err := s.queries.GetDevice(ctx, pgUUID)
+	var deleted int64
+	err := s.withOrg(ctx, orgID, func(queries *sqlc.Queries, org pgtype.UUID) error {
+		var err error
+		deleted, err = queries.DeleteDevice(ctx, sqlc.DeleteDeviceParams{ID: pgUUID, OrgID: org})
+		return err
+	})
+	if err != nil {
+		if status.Code(err) == codes.InvalidArgument {
+			return err
+		}
+		return fmt.Errorf("failed to delete device: %w", err)
+	}
+	if deleted == 0 {
+		return status.Errorf(codes.NotFound, "device %s not found", id)
+	}
+
+	return nil
+}
+
+// UpsertDeviceType implements Storage.UpsertDeviceType.
+// The type and its whole metric catalog are replaced in one transaction.
+func (s *PostgresStorage) UpsertDeviceType(ctx context.Context, deviceType *pb.DeviceType) (*pb.DeviceType, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	queries := s.queries.WithTx(tx)
+
+	dbType, err := queries.UpsertDeviceType(ctx, sqlc.UpsertDeviceTypeParams{
+		Name:        deviceType.Name,
+		DisplayName: deviceType.DisplayName,
+		Description: nullableString(deviceType.Description),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert device type: %w", err)
+	}
+
+	if err := queries.DeleteDeviceTypeMetrics(ctx, deviceType.Name); err != nil {
+		return nil, fmt.Errorf("failed to delete device type metrics: %w", err)
+	}
+
+	dbMetrics := make([]sqlc.DeviceTypeMetric, 0, len(deviceType.Metrics))
+	for _, metric := range deviceType.Metrics {
+		params := sqlc.InsertDeviceTypeMetricParams{
+			DeviceType:  deviceType.Name,
+			Name:        metric.Name,
+			DisplayName: metric.DisplayName,
+			Unit:        nullableString(metric.Unit),
+			DataType:    protoDataTypeToDBDataType(metric.DataType),
+			MinValue:    metric.MinValue,
+			MaxValue:    metric.MaxValue,
+		}
+		if metric.ReportingIntervalSeconds > 0 {
+			interval := metric.ReportingIntervalSeconds
+			params.ReportingIntervalSeconds = &interval
+		}
+		if err := queries.InsertDeviceTypeMetric(ctx, params); err != nil {
+			return nil, fmt.Errorf("failed to insert metric %s: %w", metric.Name, err)
+		}
+		dbMetrics = append(dbMetrics, sqlc.DeviceTypeMetric(params))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return dbDeviceTypeToProto(dbType, dbMetrics), nil
+}
+
+// GetDeviceType implements Storage.GetDeviceType.
+func (s *PostgresStorage) GetDeviceType(ctx context.Context, name string) (*pb.DeviceType, error) {
+	dbType, err := s.queries.GetDeviceType(ctx, name)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return status.Errorf(codes.NotFound, "device %s not found", id)
+			return nil, status.Errorf(codes.NotFound, "device type %s not found", name)
 		}
-		return fmt.Errorf("failed to check device existence: %w", err)
+		return nil, fmt.Errorf("failed to get device type: %w", err)
 	}
 
-	// Delete device
-	if err := s.queries.DeleteDevice(ctx, pgUUID); err != nil {
-		return fmt.Errorf("failed to delete device: %w", err)
+	dbMetrics, err := s.queries.ListDeviceTypeMetrics(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list device type metrics: %w", err)
+	}
+
+	return dbDeviceTypeToProto(dbType, dbMetrics), nil
+}
+
+// ListDeviceTypes implements Storage.ListDeviceTypes.
+func (s *PostgresStorage) ListDeviceTypes(ctx context.Context) ([]*pb.DeviceType, error) {
+	dbTypes, err := s.queries.ListDeviceTypes(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list device types: %w", err)
+	}
+
+	dbMetrics, err := s.queries.ListAllDeviceTypeMetrics(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list device type metrics: %w", err)
+	}
+
+	// Group metrics by type
+	metricsByType := make(map[string][]sqlc.DeviceTypeMetric)
+	for _, metric := range dbMetrics {
+		metricsByType[metric.DeviceType] = append(metricsByType[metric.DeviceType], metric)
+	}
+
+	deviceTypes := make([]*pb.DeviceType, len(dbTypes))
+	for i, dbType := range dbTypes {
+		deviceTypes[i] = dbDeviceTypeToProto(dbType, metricsByType[dbType.Name])
+	}
+
+	return deviceTypes, nil
+}
+
+// DeleteDeviceType implements Storage.DeleteDeviceType.
+func (s *PostgresStorage) DeleteDeviceType(ctx context.Context, name string) error {
+	deleted, err := s.queries.DeleteDeviceType(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete device type: %w", err)
+	}
+	if deleted == 0 {
+		return status.Errorf(codes.NotFound, "device type %s not found", name)
+	}
+
+	return nil
+}
+
+// CreateLocation implements Storage.CreateLocation.
+func (s *PostgresStorage) CreateLocation(ctx context.Context, location *pb.Location) (*pb.Location, error) {
+	parent, err := optionalUUID(location.ParentId, "parent location")
+	if err != nil {
+		return nil, err
+	}
+
+	var dbLocation sqlc.Location
+	err = s.withOrg(ctx, location.OrgId, func(queries *sqlc.Queries, org pgtype.UUID) error {
+		if !org.Valid {
+			return status.Error(codes.InvalidArgument, "organization ID is required")
+		}
+		dbLocation, err = queries.CreateLocation(ctx, sqlc.CreateLocationParams{
+			OrgID:    org,
+			ParentID: parent,
+			Kind:     protoLocationKindToDBKind(location.Kind),
+			Name:     location.Name,
+		})
+		return err
+	})
+	if err != nil {
+		if status.Code(err) != codes.Unknown {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create location: %w", err)
+	}
+
+	return dbLocationToProto(dbLocation), nil
+}
+
+// GetLocation implements Storage.GetLocation.
+func (s *PostgresStorage) GetLocation(ctx context.Context, orgID, id string) (*pb.Location, error) {
+	var pgUUID pgtype.UUID
+	if err := pgUUID.Scan(id); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid location ID: %v", err)
+	}
+
+	var dbLocation sqlc.Location
+	err := s.withOrg(ctx, orgID, func(queries *sqlc.Queries, org pgtype.UUID) error {
+		var err error
+		dbLocation, err = queries.GetLocation(ctx, sqlc.GetLocationParams{ID: pgUUID, OrgID: org})
+		return err
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "location %s not found", id)
+		}
+		if status.Code(err) == codes.InvalidArgument {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get location: %w", err)
+	}
+
+	return dbLocationToProto(dbLocation), nil
+}
+
+// ListLocations implements Storage.ListLocations.
+func (s *PostgresStorage) ListLocations(ctx context.Context, orgID, parentID string) ([]*pb.Location, error) {
+	parent, err := optionalUUID(parentID, "parent location")
+	if err != nil {
+		return nil, err
+	}
+
+	var dbLocations []sqlc.Location
+	err = s.withOrg(ctx, orgID, func(queries *sqlc.Queries, org pgtype.UUID) error {
+		dbLocations, err = queries.ListLocations(ctx, sqlc.ListLocationsParams{OrgID: org, ParentID: parent})
+		return err
+	})
+	if err != nil {
+		if status.Code(err) == codes.InvalidArgument {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to list locations: %w", err)
+	}
+
+	locations := make([]*pb.Location, len(dbLocations))
+	for i, dbLocation := range dbLocations {
+		locations[i] = dbLocationToProto(dbLocation)
+	}
+	return locations, nil
+}
+
+// UpdateLocation implements Storage.UpdateLocation.
+func (s *PostgresStorage) UpdateLocation(ctx context.Context, orgID, id, name string) (*pb.Location, error) {
+	var pgUUID pgtype.UUID
+	if err := pgUUID.Scan(id); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid location ID: %v", err)
+	}
+
+	var dbLocation sqlc.Location
+	err := s.withOrg(ctx, orgID, func(queries *sqlc.Queries, org pgtype.UUID) error {
+		var err error
+		dbLocation, err = queries.UpdateLocation(ctx, sqlc.UpdateLocationParams{Name: name, ID: pgUUID, OrgID: org})
+		return err
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "location %s not found", id)
+		}
+		if status.Code(err) == codes.InvalidArgument {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update location: %w", err)
+	}
+
+	return dbLocationToProto(dbLocation), nil
+}
+
+// DeleteLocation implements Storage.DeleteLocation.
+func (s *PostgresStorage) DeleteLocation(ctx context.Context, orgID, id string) error {
+	var pgUUID pgtype.UUID
+	if err := pgUUID.Scan(id); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid location ID: %v", err)
+	}
+
+	var deleted int64
+	err := s.withOrg(ctx, orgID, func(queries *sqlc.Queries, org pgtype.UUID) error {
+		var err error
+		deleted, err = queries.DeleteLocation(ctx, sqlc.DeleteLocationParams{ID: pgUUID, OrgID: org})
+		return err
+	})
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return status.Errorf(codes.FailedPrecondition, "location %s has sub-locations", id)
+		}
+		if status.Code(err) == codes.InvalidArgument {
+			return err
+		}
+		return fmt.Errorf("failed to delete location: %w", err)
+	}
+	if deleted == 0 {
+		return status.Errorf(codes.NotFound, "location %s not found", id)
 	}
 
 	return nil
 }
 
+// CreateDeviceGroup implements Storage.CreateDeviceGroup.
+func (s *PostgresStorage) CreateDeviceGroup(ctx context.Context, group *pb.DeviceGroup) (*pb.DeviceGroup, error) {
+	ruleMetadata, err := ruleMetadataJSON(group.Rule)
+	if err != nil {
+		return nil, err
+	}
+
+	var created *pb.DeviceGroup
+	err = s.withOrg(ctx, group.OrgId, func(queries *sqlc.Queries, org pgtype.UUID) error {
+		if !org.Valid {
+			return status.Error(codes.InvalidArgument, "organization ID is required")
+		}
+		dbGroup, err := queries.CreateDeviceGroup(ctx, sqlc.CreateDeviceGroupParams{
+			OrgID:        org,
+			Name:         group.Name,
+			Description:  nullableString(group.Description),
+			Kind:         protoGroupKindToDBKind(group.Kind),
+			RuleType:     nullableString(group.Rule.GetType()),
+			RuleMetadata: ruleMetadata,
+		})
+		if err != nil {
+			return err
+		}
+		groups, err := withDeviceCounts(ctx, queries, []sqlc.DeviceGroup{dbGroup})
+		if err != nil {
+			return err
+		}
+		created = groups[0]
+		return nil
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, status.Errorf(codes.AlreadyExists, "device group %s already exists", group.Name)
+		}
+		if status.Code(err) != codes.Unknown {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create device group: %w", err)
+	}
+
+	return created, nil
+}
+
+// GetDeviceGroup implements Storage.GetDeviceGroup.
+func (s *PostgresStorage) GetDeviceGroup(ctx context.Context, orgID, id string) (*pb.DeviceGroup, error) {
+	var pgUUID pgtype.UUID
+	if err := pgUUID.Scan(id); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid group ID: %v", err)
+	}
+
+	var group *pb.DeviceGroup
+	err := s.withOrg(ctx, orgID, func(queries *sqlc.Queries, org pgtype.UUID) error {
+		dbGroup, err := queries.GetDeviceGroup(ctx, sqlc.GetDeviceGroupParams{ID: pgUUID, OrgID: org})
+		if err != nil {
+			return err
+		}
+		groups, err := withDeviceCounts(ctx, queries, []sqlc.DeviceGroup{dbGroup})
+		if err != nil {
+			return err
+		}
+		group = groups[0]
+		return nil
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "device group %s not found", id)
+		}
+		if status.Code(err) == codes.InvalidArgument {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get device group: %w", err)
+	}
+
+	return group, nil
+}
+
+// ListDeviceGroups implements Storage.ListDeviceGroups.
+func (s *PostgresStorage) ListDeviceGroups(ctx context.Context, orgID, deviceID string) ([]*pb.DeviceGroup, error) {
+	device, err := optionalUUID(deviceID, "device")
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []*pb.DeviceGroup
+	err = s.withOrg(ctx, orgID, func(queries *sqlc.Queries, org pgtype.UUID) error {
+		dbGroups, err := queries.ListDeviceGroups(ctx, sqlc.ListDeviceGroupsParams{OrgID: org, DeviceID: device})
+		if err != nil {
+			return err
+		}
+		groups, err = withDeviceCounts(ctx, queries, dbGroups)
+		return err
+	})
+	if err != nil {
+		if status.Code(err) == codes.InvalidArgument {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to list device groups: %w", err)
+	}
+
+	return groups, nil
+}
+
+// UpdateDeviceGroup implements Storage.UpdateDeviceGroup.
+func (s *PostgresStorage) UpdateDeviceGroup(ctx context.Context, orgID string, group *pb.DeviceGroup) (*pb.DeviceGroup, error) {
+	var pgUUID pgtype.UUID
+	if err := pgUUID.Scan(group.Id); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid group ID: %v", err)
+	}
+	ruleMetadata, err := ruleMetadataJSON(group.Rule)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated *pb.DeviceGroup
+	err = s.withOrg(ctx, orgID, func(queries *sqlc.Queries, org pgtype.UUID) error {
+		dbGroup, err := queries.UpdateDeviceGroup(ctx, sqlc.UpdateDeviceGroupParams{
+			Name:         group.Name,
+			Description:  nullableString(group.Description),
+			RuleType:     nullableString(group.Rule.GetType()),
+			RuleMetadata: ruleMetadata,
+			ID:           pgUUID,
+			OrgID:        org,
+		})
+		if err != nil {
+			return err
+		}
+		groups, err := withDeviceCounts(ctx, queries, []sqlc.DeviceGroup{dbGroup})
+		if err != nil {
+			return err
+		}
+		updated = groups[0]
+		return nil
+	})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "device group %s not found", group.Id)
+		}
+		if isUniqueViolation(err) {
+			return nil, status.Errorf(codes.AlreadyExists, "device group %s already exists", group.Name)
+		}
+		if status.Code(err) == codes.InvalidArgument {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update device group: %w", err)
+	}
+
+	return updated, nil
+}
+
+// DeleteDeviceGroup implements Storage.DeleteDeviceGroup.
+func (s *PostgresStorage) DeleteDeviceGroup(ctx context.Context, orgID, id string) error {
+	var pgUUID pgtype.UUID
+	if err := pgUUID.Scan(id); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid group ID: %v", err)
+	}
+
+	var deleted int64
+	err := s.withOrg(ctx, orgID, func(queries *sqlc.Queries, org pgtype.UUID) error {
+		var err error
+		deleted, err = queries.DeleteDeviceGroup(ctx, sqlc.DeleteDeviceGroupParams{ID: pgUUID, OrgID: org})
+		return err
+	})
+	if err != nil {
+		if status.Code(err) == codes.InvalidArgument {
+			return err
+		}
+		return fmt.Errorf("failed to delete device group: %w", err)
+	}
+	if deleted == 0 {
+		return status.Errorf(codes.NotFound, "device group %s not found", id)
+	}
+
+	return nil
+}
+
+// AddGroupDevices implements Storage.AddGroupDevices.
+// The devices are checked and added in one transaction.
+func (s *PostgresStorage) AddGroupDevices(ctx context.Context, groupID string, deviceIDs []string) error {
+	group, devices, err := groupDeviceIDs(groupID, deviceIDs)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	queries := s.queries.WithTx(tx)
+
+	found, err := queries.CountGroupOrgDevices(ctx, sqlc.CountGroupOrgDevicesParams{GroupID: group, DeviceIds: devices})
+	if err != nil {
+		return fmt.Errorf("failed to check devices: %w", err)
+	}
+	if int(found) < len(devices) {
+		return status.Errorf(codes.NotFound, "%d of %d devices not found", len(devices)-int(found), len(devices))
+	}
+
+	if _, err := queries.AddDeviceGroupMembers(ctx, sqlc.AddDeviceGroupMembersParams{GroupID: group, DeviceIds: devices}); err != nil {
+		return fmt.Errorf("failed to add group devices: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// RemoveGroupDevices implements Storage.RemoveGroupDevices.
+func (s *PostgresStorage) RemoveGroupDevices(ctx context.Context, groupID string, deviceIDs []string) error {
+	group, devices, err := groupDeviceIDs(groupID, deviceIDs)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.queries.RemoveDeviceGroupMembers(ctx, sqlc.RemoveDeviceGroupMembersParams{GroupID: group, DeviceIds: devices}); err != nil {
+		return fmt.Errorf("failed to remove group devices: %w", err)
+	}
+	return nil
+}
+
+// CreateAccessEntry implements Storage.CreateAccessEntry.
+func (s *PostgresStorage) CreateAccessEntry(ctx context.Context, entry *pb.AccessEntry) (*pb.AccessEntry, error) {
+	params, err := accessEntryParams(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	var created sqlc.DeviceAclEntry
+	err = s.withOrg(ctx, entry.OrgId, func(queries *sqlc.Queries, org pgtype.UUID) error {
+		if !org.Valid {
+			return status.Error(codes.InvalidArgument, "organization ID is required")
+		}
+		params.OrgID = org
+		var err error
+		created, err = queries.CreateDeviceAclEntry(ctx, params)
+		return err
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, status.Error(codes.AlreadyExists, "access entry already exists")
+		}
+		if isForeignKeyViolation(err) {
+			return nil, status.Error(codes.NotFound, "access entry subject or target not found")
+		}
+		if status.Code(err) != codes.Unknown {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to create access entry: %w", err)
+	}
+
+	return dbAccessEntryToProto(created), nil
+}
+
+// ListAccessEntries implements Storage.ListAccessEntries.
+func (s *PostgresStorage) ListAccessEntries(ctx context.Context, orgID string, filter AccessEntryFilter) ([]*pb.AccessEntry, error) {
+	params := sqlc.ListDeviceAclEntriesParams{Role: nullableString(filter.Role)}
+	var err error
+	if params.UserID, err = optionalUUID(filter.UserID, "user"); err != nil {
+		return nil, err
+	}
+	if params.DeviceID, err = optionalUUID(filter.DeviceID, "device"); err != nil {
+		return nil, err
+	}
+	if params.GroupID, err = optionalUUID(filter.GroupID, "group"); err != nil {
+		return nil, err
+	}
+
+	var dbEntries []sqlc.DeviceAclEntry
+	err = s.withOrg(ctx, orgID, func(queries *sqlc.Queries, org pgtype.UUID) error {
+		params.OrgID = org
+		var err error
+		dbEntries, err = queries.ListDeviceAclEntries(ctx, params)
+		return err
+	})
+	if err != nil {
+		if status.Code(err) == codes.InvalidArgument {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to list access entries: %w", err)
+	}
+
+	entries := make([]*pb.AccessEntry, len(dbEntries))
+	for i, dbEntry := range dbEntries {
+		entries[i] = dbAccessEntryToProto(dbEntry)
+	}
+	return entries, nil
+}
+
+// DeleteAccessEntry implements Storage.DeleteAccessEntry.
+func (s *PostgresStorage) DeleteAccessEntry(ctx context.Context, orgID, id string) error {
+	var pgUUID pgtype.UUID
+	if err := pgUUID.Scan(id); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid access entry ID: %v", err)
+	}
+
+	var deleted int64
+	err := s.withOrg(ctx, orgID, func(queries *sqlc.Queries, org pgtype.UUID) error {
+		var err error
+		deleted, err = queries.DeleteDeviceAclEntry(ctx, sqlc.DeleteDeviceAclEntryParams{ID: pgUUID, OrgID: org})
+		return err
+	})
+	if err != nil {
+		if status.Code(err) == codes.InvalidArgument {
+			return err
+		}
+		return fmt.Errorf("failed to delete access entry: %w", err)
+	}
+	if deleted == 0 {
+		return status.Errorf(codes.NotFound, "access entry %s not found", id)
+	}
+
+	return nil
+}
+
+// CheckDeviceAccess implements Storage.CheckDeviceAccess.
+func (s *PostgresStorage) CheckDeviceAccess(ctx context.Context, orgID string, subject *pb.AccessSubject, deviceIDs []string, access pb.AccessLevel) (bool, []string, error) {
+	if orgID == "" || subject == nil {
+		return false, nil, nil
+	}
+	userID, err := optionalUUID(subject.UserId, "user")
+	if err != nil {
+		return false, nil, err
+	}
+	role := nullableString(subject.Role)
+
+	// Unknown device IDs are denied rather than rejected
+	devices := make([]pgtype.UUID, 0, len(deviceIDs))
+	for _, id := range deviceIDs {
+		var device pgtype.UUID
+		if err := device.Scan(id); err == nil {
+			devices = append(devices, device)
+		}
+	}
+
+	var restricted bool
+	granted := make(map[string]bool, len(deviceIDs))
+	err = s.withOrg(ctx, orgID, func(queries *sqlc.Queries, org pgtype.UUID) error {
+		count, err := queries.CountSubjectAclEntries(ctx, sqlc.CountSubjectAclEntriesParams{
+			OrgID:  org,
+			UserID: userID,
+			Role:   role,
+		})
+		if err != nil || count == 0 {
+			return err
+		}
+		restricted = true
+
+		allowed, err := queries.ListSubjectAclDevices(ctx, sqlc.ListSubjectAclDevicesParams{
+			OrgID:     org,
+			UserID:    userID,
+			Role:      role,
+			Accesses:  grantingAccessLevels(access),
+			DeviceIds: devices,
+		})
+		if err != nil {
+			return err
+		}
+		for _, id := range allowed {
+			granted[id.String()] = true
+		}
+		return nil
+	})
+	if err != nil {
+		if status.Code(err) == codes.InvalidArgument {
+			return false, nil, err
+		}
+		return false, nil, fmt.Errorf("failed to check device access: %w", err)
+	}
+	if !restricted {
+		return false, nil, nil
+	}
+
+	var denied []string
+	for _, id := range deviceIDs {
+		if !granted[id] {
+			denied = append(denied, id)
+		}
+	}
+	return true, denied, nil
+}
+
+// withDeviceCounts converts groups to proto with the number of devices of
+// each, counted in a single query.
+func withDeviceCounts(ctx context.Context, queries *sqlc.Queries, dbGroups []sqlc.DeviceGroup) ([]*pb.DeviceGroup, error) {
+	groups := make([]*pb.DeviceGroup, len(dbGroups))
+	ids := make([]pgtype.UUID, len(dbGroups))
+	for i, dbGroup := range dbGroups {
+		group, err := dbGroupToProto(dbGroup)
+		if err != nil {
+			return nil, err
+		}
+		groups[i] = group
+		ids[i] = dbGroup.ID
+	}
+	if len(groups) == 0 {
+		return groups, nil
+	}
+
+	counts, err := queries.CountDeviceGroupDevices(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count group devices: %w", err)
+	}
+	countByGroup := make(map[string]int32, len(counts))
+	for _, count := range counts {
+		countByGroup[count.GroupID.String()] = int32(count.DeviceCount)
+	}
+	for _, group := range groups {
+		group.DeviceCount = countByGroup[group.Id]
+	}
+
+	return groups, nil
+}
+
 // Close implements Storage.Close.
 func (s *PostgresStorage) Close() error {
 	s.pool.Close()
@@ -224,16 +1002,222 @@ func dbDeviceToProto(dbDevice sqlc.Device) (*pb.Device, error) {
 	}
 
 	return &pb.Device{
-		Id:        dbDevice.ID.String(),
-		Name:      dbDevice.Name,
-		Type:      dbDevice.Type,
-		Status:    dbStatusToProtoStatus(dbDevice.Status),
-		CreatedAt: dbDevice.CreatedAt.Time.Unix(),
-		LastSeen:  dbDevice.LastSeen.Time.Unix(),
-		Metadata:  metadata,
+		Id:         dbDevice.ID.String(),
+		Name:       dbDevice.Name,
+		Type:       dbDevice.Type,
+		Status:     dbStatusToProtoStatus(dbDevice.Status),
+		CreatedAt:  dbDevice.CreatedAt.Time.Unix(),
+		LastSeen:   dbDevice.LastSeen.Time.Unix(),
+		Metadata:   metadata,
+		OrgId:      dbDevice.OrgID.String(),
+		LocationId: dbDevice.LocationID.String(),
 	}, nil
 }
 
+func dbLocationToProto(dbLocation sqlc.Location) *pb.Location {
+	return &pb.Location{
+		Id:        dbLocation.ID.String(),
+		OrgId:     dbLocation.OrgID.String(),
+		ParentId:  dbLocation.ParentID.String(),
+		Kind:      dbLocationKindToProtoKind(dbLocation.Kind),
+		Name:      dbLocation.Name,
+		CreatedAt: dbLocation.CreatedAt.Time.Unix(),
+	}
+}
+
+func protoLocationKindToDBKind(kind pb.LocationKind) string {
+	switch kind {
+	case pb.LocationKind_LOCATION_BUILDING:
+		return "building"
+	case pb.LocationKind_LOCATION_FLOOR:
+		return "floor"
+	case pb.LocationKind_LOCATION_ROOM:
+		return "room"
+	default:
+		return "site"
+	}
+}
+
+func dbLocationKindToProtoKind(kind string) pb.LocationKind {
+	switch kind {
+	case "building":
+		return pb.LocationKind_LOCATION_BUILDING
+	case "floor":
+		return pb.LocationKind_LOCATION_FLOOR
+	case "room":
+		return pb.LocationKind_LOCATION_ROOM
+	default:
+		return pb.LocationKind_LOCATION_SITE
+	}
+}
+
+func dbGroupToProto(dbGroup sqlc.DeviceGroup) (*pb.DeviceGroup, error) {
+	group := &pb.DeviceGroup{
+		Id:        dbGroup.ID.String(),
+		OrgId:     dbGroup.OrgID.String(),
+		Name:      dbGroup.Name,
+		Kind:      dbGroupKindToProtoKind(dbGroup.Kind),
+		CreatedAt: dbGroup.CreatedAt.Time.Unix(),
+		UpdatedAt: dbGroup.UpdatedAt.Time.Unix(),
+	}
+	if dbGroup.Description != nil {
+		group.Description = *dbGroup.Description
+	}
+
+	if group.Kind == pb.DeviceGroupKind_GROUP_DYNAMIC {
+		group.Rule = &pb.DeviceGroupRule{}
+		if dbGroup.RuleType != nil {
+			group.Rule.Type = *dbGroup.RuleType
+		}
+		if err := json.Unmarshal(dbGroup.RuleMetadata, &group.Rule.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal rule metadata: %w", err)
+		}
+	}
+
+	return group, nil
+}
+
+func protoGroupKindToDBKind(kind pb.DeviceGroupKind) string {
+	if kind == pb.DeviceGroupKind_GROUP_DYNAMIC {
+		return "dynamic"
+	}
+	return "static"
+}
+
+func dbGroupKindToProtoKind(kind string) pb.DeviceGroupKind {
+	if kind == "dynamic" {
+		return pb.DeviceGroupKind_GROUP_DYNAMIC
+	}
+	return pb.DeviceGroupKind_GROUP_STATIC
+}
+
+// accessEntryParams parses the subject, target and access of an ACL entry.
+func accessEntryParams(entry *pb.AccessEntry) (sqlc.CreateDeviceAclEntryParams, error) {
+	params := sqlc.CreateDeviceAclEntryParams{
+		Role:   nullableString(entry.Role),
+		Access: protoAccessToDBAccess(entry.Access),
+	}
+	var err error
+	if params.UserID, err = optionalUUID(entry.UserId, "user"); err != nil {
+		return params, err
+	}
+	if params.DeviceID, err = optionalUUID(entry.DeviceId, "device"); err != nil {
+		return params, err
+	}
+	if params.GroupID, err = optionalUUID(entry.GroupId, "group"); err != nil {
+		return params, err
+	}
+	return params, nil
+}
+
+func dbAccessEntryToProto(dbEntry sqlc.DeviceAclEntry) *pb.AccessEntry {
+	entry := &pb.AccessEntry{
+		Id:        dbEntry.ID.String(),
+		OrgId:     dbEntry.OrgID.String(),
+		UserId:    dbEntry.UserID.String(),
+		DeviceId:  dbEntry.DeviceID.String(),
+		GroupId:   dbEntry.GroupID.String(),
+		Access:    dbAccessToProtoAccess(dbEntry.Access),
+		CreatedAt: dbEntry.CreatedAt.Time.Unix(),
+	}
+	if dbEntry.Role != nil {
+		entry.Role = *dbEntry.Role
+	}
+	return entry
+}
+
+func protoAccessToDBAccess(access pb.AccessLevel) string {
+	switch access {
+	case pb.AccessLevel_ACCESS_WRITE:
+		return "write"
+	case pb.AccessLevel_ACCESS_COMMAND:
+		return "command"
+	default:
+		return "read"
+	}
+}
+
+func dbAccessToProtoAccess(access string) pb.AccessLevel {
+	switch access {
+	case "write":
+		return pb.AccessLevel_ACCESS_WRITE
+	case "command":
+		return pb.AccessLevel_ACCESS_COMMAND
+	default:
+		return pb.AccessLevel_ACCESS_READ
+	}
+}
+
+// grantingAccessLevels returns the entry access levels that grant access:
+// every level grants read access.
+func grantingAccessLevels(access pb.AccessLevel) []string {
+	if access == pb.AccessLevel_ACCESS_READ {
+		return []string{"read", "write", "command"}
+	}
+	return []string{protoAccessToDBAccess(access)}
+}
+
+// ruleMetadataJSON encodes the metadata of a rule, an empty object when
+// there is none so that it matches every device.
+func ruleMetadataJSON(rule *pb.DeviceGroupRule) ([]byte, error) {
+	metadata := rule.GetMetadata()
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal rule metadata: %w", err)
+	}
+	return data, nil
+}
+
+// groupDeviceIDs parses the IDs of a group and of devices.
+func groupDeviceIDs(groupID string, deviceIDs []string) (pgtype.UUID, []pgtype.UUID, error) {
+	var group pgtype.UUID
+	if err := group.Scan(groupID); err != nil {
+		return group, nil, status.Errorf(codes.InvalidArgument, "invalid group ID: %v", err)
+	}
+
+	seen := make(map[string]bool, len(deviceIDs))
+	devices := make([]pgtype.UUID, 0, len(deviceIDs))
+	for _, id := range deviceIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		var device pgtype.UUID
+		if err := device.Scan(id); err != nil {
+			return group, nil, status.Errorf(codes.InvalidArgument, "invalid device ID: %v", err)
+		}
+		devices = append(devices, device)
+	}
+	return group, devices, nil
+}
+
+// optionalUUID parses an optional ID, NULL if empty.
+func optionalUUID(id, what string) (pgtype.UUID, error) {
+	var pgUUID pgtype.UUID
+	if id == "" {
+		return pgUUID, nil
+	}
+	if err := pgUUID.Scan(id); err != nil {
+		return pgUUID, status.Errorf(codes.InvalidArgument, "invalid %s ID: %v", what, err)
+	}
+	return pgUUID, nil
+}
+
+// isUniqueViolation reports whether err is a unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// isForeignKeyViolation reports whether err is a foreign key violation
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
 func protoStatusToDBStatus(status pb.DeviceStatus) sqlc.DeviceStatus {
 	switch status {
 	case pb.DeviceStatus_ONLINE:
@@ -263,3 +1247,64 @@ func dbStatusToProtoStatus(status sqlc.DeviceStatus) pb.DeviceStatus {
 		return pb.DeviceStatus_UNKNOWN
 	}
 }
+
+func dbDeviceTypeToProto(dbType sqlc.DeviceType, dbMetrics []sqlc.DeviceTypeMetric) *pb.DeviceType {
+	deviceType := &pb.DeviceType{
+		Name:        dbType.Name,
+		DisplayName: dbType.DisplayName,
+		CreatedAt:   dbType.CreatedAt.Time.Unix(),
+		UpdatedAt:   dbType.UpdatedAt.Time.Unix(),
+		Metrics:     make([]*pb.MetricDefinition, len(dbMetrics)),
+	}
+	if dbType.Description != nil {
+		deviceType.Description = *dbType.Description
+	}
+
+	for i, dbMetric := range dbMetrics {
+		metric := &pb.MetricDefinition{
+			Name:        dbMetric.Name,
+			DisplayName: dbMetric.DisplayName,
+			DataType:    dbDataTypeToProtoDataType(dbMetric.DataType),
+			MinValue:    dbMetric.MinValue,
+			MaxValue:    dbMetric.MaxValue,
+		}
+		if dbMetric.Unit != nil {
+			metric.Unit = *dbMetric.Unit
+		}
+		if dbMetric.ReportingIntervalSeconds != nil {
+			metric.ReportingIntervalSeconds = *dbMetric.ReportingIntervalSeconds
+		}
+		deviceType.Metrics[i] = metric
+	}
+
+	return deviceType
+}
+
+func protoDataTypeToDBDataType(dataType pb.MetricDataType) string {
+	switch dataType {
+	case pb.MetricDataType_METRIC_INTEGER:
+		return "integer"
+	case pb.MetricDataType_METRIC_BOOLEAN:
+		return "boolean"
+	default:
+		return "float"
+	}
+}
+
+func dbDataTypeToProtoDataType(dataType string) pb.MetricDataType {
+	switch dataType {
+	case "integer":
+		return pb.MetricDataType_METRIC_INTEGER
+	case "boolean":
+		return pb.MetricDataType_METRIC_BOOLEAN
+	default:
+		return pb.MetricDataType_METRIC_FLOAT
+	}
+}
+
+func nullableString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -56,18 +56,21 @@ func cleanDatabase(t *testing.T, store Storage) {
 	t.Helper()
 
 	ctx := context.Background()
-	devices, _, err := store.ListDevices(ctx, 1, 1000)
+	devices, _, err := store.ListDevices(ctx, "", DeviceFilter{}, 1, 1000)
 	if err != nil {
 		t.Fatalf("Failed to list devices: %v", err)
 	}
 
 	for _, device := range devices {
-		if err := store.DeleteDevice(ctx, device.Id); err != nil {
+		if err := store.DeleteDevice(ctx, "", device.Id); err != nil {
 			t.Logf("Warning: Failed to delete device %s: %v", device.Id, err)
 		}
 	}
 }
 
+// defaultOrgID is the organization created by migration 011_create_organizations.sql
+const defaultOrgID = "00000000-0000-0000-0000-000000000001"
+
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -82,6 +85,7 @@ func TestPostgresStorage_CreateDevice(t *testing.T) {
 
 	device := &pb.Device{
 		Id:        uuid.New().String(),
+		OrgId:     defaultOrgID,
 		Name:      "PostgreSQL Test Device",
 		Type:      "sensor",
 		Status:    pb.DeviceStatus_ONLINE,
@@ -117,6 +121,7 @@ func TestPostgresStorage_GetDevice(t *testing.T) {
 	deviceID := uuid.New().String()
 	device := &pb.Device{
 		Id:        deviceID,
+		OrgId:     defaultOrgID,
 		Name:      "Get Test Device",
 		Type:      "actuator",
 		Status:    pb.DeviceStatus_ONLINE,
@@ -130,7 +135,7 @@ func TestPostgresStorage_GetDevice(t *testing.T) {
 	}
 
 	// Get existing device
-	retrieved, err := store.GetDevice(ctx, deviceID)
+	retrieved, err := store.GetDevice(ctx, "", deviceID)
 	if err != nil {
 		t.Fatalf("GetDevice() failed: %v", err)
 	}
@@ -143,7 +148,7 @@ func TestPostgresStorage_GetDevice(t *testing.T) {
 	}
 
 	// Get non-existent device
-	_, err = store.GetDevice(ctx, uuid.New().String())
+	_, err = store.GetDevice(ctx, "", uuid.New().String())
 	if err == nil {
 		t.Error("GetDevice() should fail for non-existent device")
 	}
@@ -156,6 +161,7 @@ func TestPostgresStorage_UpdateDevice(t *testing.T) {
 
 	device := &pb.Device{
 		Id:        uuid.New().String(),
+		OrgId:     defaultOrgID,
 		Name:      "Original Name",
 		Type:      "sensor",
 		Status:    pb.DeviceStatus_ONLINE,
@@ -177,7 +183,7 @@ func TestPostgresStorage_UpdateDevice(t *testing.T) {
 	device.Metadata["version"] = "2.0"
 	device.Metadata["env"] = "prod"
 
-	updated, err := store.UpdateDevice(ctx, device)
+	updated, err := store.UpdateDevice(ctx, "", device)
 	if err != nil {
 		t.Fatalf("UpdateDevice() failed: %v", err)
 	}
@@ -201,6 +207,7 @@ func TestPostgresStorage_DeleteDevice(t *testing.T) {
 	deviceID := uuid.New().String()
 	device := &pb.Device{
 		Id:        deviceID,
+		OrgId:     defaultOrgID,
 		Name:      "Delete Test Device",
 		Type:      "gateway",
 		Status:    pb.DeviceStatus_ONLINE,
@@ -214,13 +221,13 @@ func TestPostgresStorage_DeleteDevice(t *testing.T) {
 	}
 
 	// Delete device
-	err = store.DeleteDevice(ctx, deviceID)
+	err = store.DeleteDevice(ctx, "", deviceID)
 	if err != nil {
 		t.Fatalf("DeleteDevice() failed: %v", err)
 	}
 
 	// Verify device is deleted
-	_, err = store.GetDevice(ctx, deviceID)
+	_, err = store.GetDevice(ctx, "", deviceID)
 	if err == nil {
 		t.Error("GetDevice() should fail after deletion")
 	}
@@ -235,6 +242,7 @@ func TestPostgresStorage_ListDevices(t *testing.T) {
 	for i := 0; i < 7; i++ {
 		device := &pb.Device{
 			Id:        uuid.New().String(),
+			OrgId:     defaultOrgID,
 			Name:      fmt.Sprintf("List Device %d", i),
 			Type:      "sensor",
 			Status:    pb.DeviceStatus_ONLINE,
@@ -248,7 +256,7 @@ func TestPostgresStorage_ListDevices(t *testing.T) {
 	}
 
 	// List all devices
-	devices, total, err := store.ListDevices(ctx, 1, 10)
+	devices, total, err := store.ListDevices(ctx, "", DeviceFilter{}, 1, 10)
 	if err != nil {
 		t.Fatalf("ListDevices() failed: %v", err)
 	}
@@ -261,7 +269,7 @@ func TestPostgresStorage_ListDevices(t *testing.T) {
 	}
 
 	// Test pagination
-	devices, total, err = store.ListDevices(ctx, 1, 3)
+	devices, total, err = store.ListDevices(ctx, "", DeviceFilter{}, 1, 3)
 	if err != nil {
 		t.Fatalf("ListDevices(page=1, pageSize=3) failed: %v", err)
 	}
@@ -274,7 +282,7 @@ func TestPostgresStorage_ListDevices(t *testing.T) {
 	}
 
 	// Second page
-	devices, total, err = store.ListDevices(ctx, 2, 3)
+	devices, total, err = store.ListDevices(ctx, "", DeviceFilter{}, 2, 3)
 	if err != nil {
 		t.Fatalf("ListDevices(page=2, pageSize=3) failed: %v", err)
 	}
@@ -300,6 +308,7 @@ func TestPostgresStorage_MetadataJSONB(t *testing.T) {
 	// Create device with complex metadata
 	device := &pb.Device{
 		Id:        deviceID,
+		OrgId:     defaultOrgID,
 		Name:      "Metadata Test",
 		Type:      "sensor",
 		Status:    pb.DeviceStatus_ONLINE,
@@ -323,7 +332,7 @@ func TestPostgresStorage_MetadataJSONB(t *testing.T) {
 	}
 
 	// Retrieve and verify metadata
-	retrieved, err := store.GetDevice(ctx, deviceID)
+	retrieved, err := store.GetDevice(ctx, "", deviceID)
 	if err != nil {
 		t.Fatalf("GetDevice() failed: %v", err)
 	}
@@ -352,6 +361,7 @@ func TestPostgresStorage_TimestampPersistence(t *testing.T) {
 
 	device := &pb.Device{
 		Id:        deviceID,
+		OrgId:     defaultOrgID,
 		Name:      "Timestamp Test",
 		Type:      "sensor",
 		Status:    pb.DeviceStatus_ONLINE,
@@ -365,7 +375,7 @@ func TestPostgresStorage_TimestampPersistence(t *testing.T) {
 	}
 
 	// Retrieve and verify timestamps
-	retrieved, err := store.GetDevice(ctx, deviceID)
+	retrieved, err := store.GetDevice(ctx, "", deviceID)
 	if err != nil {
 		t.Fatalf("GetDevice() failed: %v", err)
 	}
@@ -378,3 +388,246 @@ func TestPostgresStorage_TimestampPersistence(t *testing.T) {
 		t.Errorf("LastSeen = %d, want %d (±1s)", retrieved.LastSeen, lastSeen)
 	}
 }
+
+func TestPostgresStorage_OrganizationScope(t *testing.T) {
+	store := setupPostgresStorage(t)
+	cleanDatabase(t, store)
+	ctx := context.Background()
+
+	otherOrgID := uuid.New().String()
+	if _, err := store.pool.Exec(ctx, "INSERT INTO organizations (id, name, slug) VALUES ($1, 'Other', $2)", otherOrgID, "other-"+otherOrgID[:8]); err != nil {
+		t.Fatalf("Failed to create organization: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = store.pool.Exec(context.Background(), "DELETE FROM organizations WHERE id = $1", otherOrgID)
+	})
+
+	device, err := store.CreateDevice(ctx, &pb.Device{
+		Id:        uuid.New().String(),
+		OrgId:     defaultOrgID,
+		Name:      "Scoped Device",
+		Type:      "sensor",
+		Status:    pb.DeviceStatus_ONLINE,
+		CreatedAt: time.Now().Unix(),
+		LastSeen:  time.Now().Unix(),
+	})
+	if err != nil {
+		t.Fatalf("CreateDevice() failed: %v", err)
+	}
+	if device.OrgId != defaultOrgID {
+		t.Errorf("OrgId = %v, want %v", device.OrgId, defaultOrgID)
+	}
+
+	if _, err := store.GetDevice(ctx, defaultOrgID, device.Id); err != nil {
+		t.Errorf("GetDevice() in its organization failed: %v", err)
+	}
+	if _, err := store.GetDevice(ctx, otherOrgID, device.Id); err == nil {
+		t.Error("GetDevice() should fail from another organization")
+	}
+	if _, err := store.UpdateDevice(ctx, otherOrgID, &pb.Device{Id: device.Id, Name: "Stolen"}); err == nil {
+		t.Error("UpdateDevice() should fail from another organization")
+	}
+	if err := store.DeleteDevice(ctx, otherOrgID, device.Id); err == nil {
+		t.Error("DeleteDevice() should fail from another organization")
+	}
+	if _, total, err := store.ListDevices(ctx, otherOrgID, DeviceFilter{}, 1, 10); err != nil || total != 0 {
+		t.Errorf("ListDevices() from another organization = %d devices, %v; want none", total, err)
+	}
+	if _, err := store.CreateDevice(ctx, &pb.Device{Id: uuid.New().String(), Name: "Orphan", Type: "sensor"}); err == nil {
+		t.Error("CreateDevice() should fail without organization")
+	}
+}
+
+func TestPostgresStorage_GroupsAndLocations(t *testing.T) {
+	store := setupPostgresStorage(t)
+	cleanDatabase(t, store)
+	ctx := context.Background()
+
+	site, err := store.CreateLocation(ctx, &pb.Location{OrgId: defaultOrgID, Kind: pb.LocationKind_LOCATION_SITE, Name: "Site " + uuid.NewString()[:8]})
+	if err != nil {
+		t.Fatalf("CreateLocation(site) failed: %v", err)
+	}
+	building, err := store.CreateLocation(ctx, &pb.Location{OrgId: defaultOrgID, ParentId: site.Id, Kind: pb.LocationKind_LOCATION_BUILDING, Name: "Building"})
+	if err != nil {
+		t.Fatalf("CreateLocation(building) failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = store.DeleteLocation(context.Background(), "", building.Id)
+		_ = store.DeleteLocation(context.Background(), "", site.Id)
+	})
+
+	var ids []string
+	for i, metadata := range []map[string]string{{"zone": "nord"}, {"zone": "sud"}} {
+		device, err := store.CreateDevice(ctx, &pb.Device{
+			Id:         uuid.New().String(),
+			OrgId:      defaultOrgID,
+			Name:       fmt.Sprintf("Device %d", i),
+			Type:       "sensor",
+			Status:     pb.DeviceStatus_ONLINE,
+			CreatedAt:  time.Now().Unix(),
+			LastSeen:   time.Now().Unix(),
+			Metadata:   metadata,
+			LocationId: building.Id,
+		})
+		if err != nil {
+			t.Fatalf("CreateDevice() failed: %v", err)
+		}
+		ids = append(ids, device.Id)
+	}
+
+	devices, total, err := store.ListDevices(ctx, defaultOrgID, DeviceFilter{LocationID: site.Id}, 1, 10)
+	if err != nil {
+		t.Fatalf("ListDevices(location) failed: %v", err)
+	}
+	if total != 2 || len(devices) != 2 || devices[0].LocationId != building.Id {
+		t.Errorf("ListDevices(site) = %v (total %d), want the 2 devices of the building", devices, total)
+	}
+	if err := store.DeleteLocation(ctx, defaultOrgID, site.Id); err == nil {
+		t.Error("DeleteLocation() should fail for a location with sub-locations")
+	}
+
+	dynamic, err := store.CreateDeviceGroup(ctx, &pb.DeviceGroup{
+		OrgId: defaultOrgID,
+		Name:  "Nord " + uuid.NewString()[:8],
+		Kind:  pb.DeviceGroupKind_GROUP_DYNAMIC,
+		Rule:  &pb.DeviceGroupRule{Type: "sensor", Metadata: map[string]string{"zone": "nord"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateDeviceGroup(dynamic) failed: %v", err)
+	}
+	t.Cleanup(func() { _ = store.DeleteDeviceGroup(context.Background(), "", dynamic.Id) })
+	if dynamic.DeviceCount != 1 {
+		t.Errorf("DeviceCount = %d, want 1", dynamic.DeviceCount)
+	}
+
+	static, err := store.CreateDeviceGroup(ctx, &pb.DeviceGroup{OrgId: defaultOrgID, Name: "Static " + uuid.NewString()[:8], Kind: pb.DeviceGroupKind_GROUP_STATIC})
+	if err != nil {
+		t.Fatalf("CreateDeviceGroup(static) failed: %v", err)
+	}
+	t.Cleanup(func() { _ = store.DeleteDeviceGroup(context.Background(), "", static.Id) })
+	if _, err := store.CreateDeviceGroup(ctx, &pb.DeviceGroup{OrgId: defaultOrgID, Name: static.Name, Kind: pb.DeviceGroupKind_GROUP_STATIC}); err == nil {
+		t.Error("CreateDeviceGroup() should fail for a duplicate name")
+	}
+	if err := store.AddGroupDevices(ctx, static.Id, []string{ids[1]}); err != nil {
+		t.Fatalf("AddGroupDevices() failed: %v", err)
+	}
+
+	devices, total, err = store.ListDevices(ctx, defaultOrgID, DeviceFilter{GroupID: static.Id}, 1, 10)
+	if err != nil {
+		t.Fatalf("ListDevices(group) failed: %v", err)
+	}
+	if total != 1 || len(devices) != 1 || devices[0].Id != ids[1] {
+		t.Errorf("ListDevices(static group) = %v (total %d), want %s", devices, total, ids[1])
+	}
+
+	groups, err := store.ListDeviceGroups(ctx, defaultOrgID, ids[0])
+	if err != nil {
+		t.Fatalf("ListDeviceGroups() failed: %v", err)
+	}
+	if len(groups) != 1 || groups[0].Id != dynamic.Id {
+		t.Errorf("ListDeviceGroups(%s) = %v, want the dynamic group", ids[0], groups)
+	}
+
+	if err := store.RemoveGroupDevices(ctx, static.Id, []string{ids[1]}); err != nil {
+		t.Fatalf("RemoveGroupDevices() failed: %v", err)
+	}
+	if group, err := store.GetDeviceGroup(ctx, defaultOrgID, static.Id); err != nil || group.DeviceCount != 0 {
+		t.Errorf("GetDeviceGroup() after removal = %v, %v; want no device", group, err)
+	}
+}
+
+func TestPostgresStorage_AccessEntries(t *testing.T) {
+	store := setupPostgresStorage(t)
+	cleanDatabase(t, store)
+	ctx := context.Background()
+
+	userID := uuid.New().String()
+	if _, err := store.pool.Exec(ctx, "INSERT INTO users (id, email, password_hash, name) VALUES ($1, $2, 'x', 'ACL user')", userID, "acl-"+userID[:8]+"@example.com"); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = store.pool.Exec(context.Background(), "DELETE FROM users WHERE id = $1", userID)
+	})
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		device, err := store.CreateDevice(ctx, &pb.Device{
+			Id:        uuid.New().String(),
+			OrgId:     defaultOrgID,
+			Name:      fmt.Sprintf("Device %d", i),
+			Type:      "sensor",
+			Status:    pb.DeviceStatus_ONLINE,
+			CreatedAt: time.Now().Unix(),
+			LastSeen:  time.Now().Unix(),
+		})
+		if err != nil {
+			t.Fatalf("CreateDevice() failed: %v", err)
+		}
+		ids = append(ids, device.Id)
+	}
+
+	group, err := store.CreateDeviceGroup(ctx, &pb.DeviceGroup{OrgId: defaultOrgID, Name: "Site " + uuid.NewString()[:8], Kind: pb.DeviceGroupKind_GROUP_STATIC})
+	if err != nil {
+		t.Fatalf("CreateDeviceGroup() failed: %v", err)
+	}
+	t.Cleanup(func() { _ = store.DeleteDeviceGroup(context.Background(), "", group.Id) })
+	if err := store.AddGroupDevices(ctx, group.Id, ids[:2]); err != nil {
+		t.Fatalf("AddGroupDevices() failed: %v", err)
+	}
+
+	entry, err := store.CreateAccessEntry(ctx, &pb.AccessEntry{OrgId: defaultOrgID, UserId: userID, GroupId: group.Id, Access: pb.AccessLevel_ACCESS_READ})
+	if err != nil {
+		t.Fatalf("CreateAccessEntry(group) failed: %v", err)
+	}
+	if _, err := store.CreateAccessEntry(ctx, &pb.AccessEntry{OrgId: defaultOrgID, UserId: userID, GroupId: group.Id, Access: pb.AccessLevel_ACCESS_READ}); err == nil {
+		t.Error("CreateAccessEntry() should fail for a duplicate entry")
+	}
+	roleEntry, err := store.CreateAccessEntry(ctx, &pb.AccessEntry{OrgId: defaultOrgID, Role: "user", DeviceId: ids[2], Access: pb.AccessLevel_ACCESS_WRITE})
+	if err != nil {
+		t.Fatalf("CreateAccessEntry(role) failed: %v", err)
+	}
+	t.Cleanup(func() { _ = store.DeleteAccessEntry(context.Background(), "", roleEntry.Id) })
+
+	// Restricted to the group, pagination totals included
+	subject := &pb.AccessSubject{UserId: userID, Role: "admin"}
+	devices, total, err := store.ListDevices(ctx, defaultOrgID, DeviceFilter{Subject: subject}, 1, 1)
+	if err != nil {
+		t.Fatalf("ListDevices(subject) failed: %v", err)
+	}
+	if total != 2 || len(devices) != 1 {
+		t.Errorf("ListDevices(subject) = %d devices (total %d), want 1 of 2", len(devices), total)
+	}
+	if _, total, _ := store.ListDevices(ctx, defaultOrgID, DeviceFilter{Subject: &pb.AccessSubject{UserId: uuid.New().String(), Role: "admin"}}, 1, 10); total != 3 {
+		t.Errorf("ListDevices(unrestricted) total = %d, want 3", total)
+	}
+
+	restricted, denied, err := store.CheckDeviceAccess(ctx, defaultOrgID, subject, ids, pb.AccessLevel_ACCESS_READ)
+	if err != nil {
+		t.Fatalf("CheckDeviceAccess() failed: %v", err)
+	}
+	if !restricted || len(denied) != 1 || denied[0] != ids[2] {
+		t.Errorf("CheckDeviceAccess(read) = %v, %v; want %s denied", restricted, denied, ids[2])
+	}
+	_, denied, err = store.CheckDeviceAccess(ctx, defaultOrgID, &pb.AccessSubject{UserId: uuid.New().String(), Role: "user"}, ids[1:], pb.AccessLevel_ACCESS_WRITE)
+	if err != nil {
+		t.Fatalf("CheckDeviceAccess() failed: %v", err)
+	}
+	if len(denied) != 1 || denied[0] != ids[1] {
+		t.Errorf("CheckDeviceAccess(write) denied = %v, want %s", denied, ids[1])
+	}
+
+	entries, err := store.ListAccessEntries(ctx, defaultOrgID, AccessEntryFilter{UserID: userID})
+	if err != nil {
+		t.Fatalf("ListAccessEntries() failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].GroupId != group.Id || entries[0].UserId != userID {
+		t.Errorf("ListAccessEntries() = %v, want the group entry", entries)
+	}
+
+	if err := store.DeleteAccessEntry(ctx, defaultOrgID, entry.Id); err != nil {
+		t.Errorf("DeleteAccessEntry() failed: %v", err)
+	}
+	if err := store.DeleteAccessEntry(ctx, defaultOrgID, entry.Id); err == nil {
+		t.Error("DeleteAccessEntry() should fail for a deleted entry")
+	}
+}
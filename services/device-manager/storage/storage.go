@@ -10,27 +10,136 @@ import (
 
 // Storage defines the interface for device persistence operations.
 // Implementations: PostgresStorage (production), MemoryStorage (tests/dev).
+//
+// Device operations are scoped by orgID: devices of other organizations are
+// reported as missing. An empty orgID selects every organization, for
+// internal calls.
 type Storage interface {
-	// CreateDevice stores a new device and returns the stored device.
+	// CreateDevice stores a new device, owned by device.OrgId, and returns the
+	// stored device.
 	CreateDevice(ctx context.Context, device *pb.Device) (*pb.Device, error)
 
 	// GetDevice retrieves a device by ID.
 	// Returns nil, ErrNotFound if device doesn't exist.
-	GetDevice(ctx context.Context, id string) (*pb.Device, error)
+	GetDevice(ctx context.Context, orgID, id string) (*pb.Device, error)
 
-	// ListDevices returns a paginated list of devices.
+	// ListDevices returns a paginated list of the devices matching filter.
 	// Returns devices, total count, and error.
-	ListDevices(ctx context.Context, page, pageSize int32) ([]*pb.Device, int32, error)
+	ListDevices(ctx context.Context, orgID string, filter DeviceFilter, page, pageSize int32) ([]*pb.Device, int32, error)
 
 	// UpdateDevice updates an existing device.
-	// Only non-zero/non-nil fields are updated.
+	// Only non-zero/non-nil fields are updated, except LocationId which is
+	// always replaced.
 	// Returns updated device or ErrNotFound.
-	UpdateDevice(ctx context.Context, device *pb.Device) (*pb.Device, error)
+	UpdateDevice(ctx context.Context, orgID string, device *pb.Device) (*pb.Device, error)
 
 	// DeleteDevice removes a device by ID.
 	// Returns ErrNotFound if device doesn't exist.
-	DeleteDevice(ctx context.Context, id string) error
+	DeleteDevice(ctx context.Context, orgID, id string) error
+
+	// UpsertDeviceType creates or replaces a device type and its metric catalog.
+	UpsertDeviceType(ctx context.Context, deviceType *pb.DeviceType) (*pb.DeviceType, error)
+
+	// GetDeviceType retrieves a device type with its metrics.
+	// Returns nil, ErrNotFound if the type is not registered.
+	GetDeviceType(ctx context.Context, name string) (*pb.DeviceType, error)
+
+	// ListDeviceTypes returns all registered device types, sorted by name.
+	ListDeviceTypes(ctx context.Context) ([]*pb.DeviceType, error)
+
+	// DeleteDeviceType removes a device type and its metric catalog.
+	// Devices of this type are kept. Returns ErrNotFound if the type is not registered.
+	DeleteDeviceType(ctx context.Context, name string) error
+
+	// CreateLocation stores a new location, owned by location.OrgId. The
+	// parent is expected to be validated by the caller.
+	CreateLocation(ctx context.Context, location *pb.Location) (*pb.Location, error)
+
+	// GetLocation retrieves a location by ID.
+	// Returns nil, ErrNotFound if the location doesn't exist.
+	GetLocation(ctx context.Context, orgID, id string) (*pb.Location, error)
+
+	// ListLocations returns the locations sorted by name, only the direct
+	// children of parentID if set.
+	ListLocations(ctx context.Context, orgID, parentID string) ([]*pb.Location, error)
+
+	// UpdateLocation renames a location.
+	// Returns ErrNotFound if the location doesn't exist.
+	UpdateLocation(ctx context.Context, orgID, id, name string) (*pb.Location, error)
+
+	// DeleteLocation removes a location; its devices no longer have one.
+	// Returns ErrNotFound if the location doesn't exist, FailedPrecondition
+	// if it has sub-locations.
+	DeleteLocation(ctx context.Context, orgID, id string) error
+
+	// CreateDeviceGroup stores a new group, owned by group.OrgId.
+	// Returns AlreadyExists if the organization has a group of that name.
+	CreateDeviceGroup(ctx context.Context, group *pb.DeviceGroup) (*pb.DeviceGroup, error)
+
+	// GetDeviceGroup retrieves a group by ID, with its device count.
+	// Returns nil, ErrNotFound if the group doesn't exist.
+	GetDeviceGroup(ctx context.Context, orgID, id string) (*pb.DeviceGroup, error)
+
+	// ListDeviceGroups returns the groups sorted by name, only those
+	// containing deviceID if set.
+	ListDeviceGroups(ctx context.Context, orgID, deviceID string) ([]*pb.DeviceGroup, error)
+
+	// UpdateDeviceGroup replaces the name, description and rule of a group.
+	// Returns ErrNotFound if the group doesn't exist.
+	UpdateDeviceGroup(ctx context.Context, orgID string, group *pb.DeviceGroup) (*pb.DeviceGroup, error)
+
+	// DeleteDeviceGroup removes a group; its devices are kept.
+	// Returns ErrNotFound if the group doesn't exist.
+	DeleteDeviceGroup(ctx context.Context, orgID, id string) error
+
+	// AddGroupDevices adds devices to a static group. Devices already in the
+	// group are skipped. Returns ErrNotFound if a device is not in the
+	// organization of the group, and adds none.
+	AddGroupDevices(ctx context.Context, groupID string, deviceIDs []string) error
+
+	// RemoveGroupDevices removes devices from a static group. Devices not in
+	// the group are skipped.
+	RemoveGroupDevices(ctx context.Context, groupID string, deviceIDs []string) error
+
+	// CreateAccessEntry stores a new ACL entry, owned by entry.OrgId. The
+	// subject and target are expected to be validated by the caller.
+	// Returns AlreadyExists if the same access is already granted.
+	CreateAccessEntry(ctx context.Context, entry *pb.AccessEntry) (*pb.AccessEntry, error)
+
+	// ListAccessEntries returns the ACL entries matching filter, oldest first.
+	ListAccessEntries(ctx context.Context, orgID string, filter AccessEntryFilter) ([]*pb.AccessEntry, error)
+
+	// DeleteAccessEntry removes an ACL entry.
+	// Returns ErrNotFound if the entry doesn't exist.
+	DeleteAccessEntry(ctx context.Context, orgID, id string) error
+
+	// CheckDeviceAccess reports whether subject is restricted by ACL entries
+	// in orgID and, if so, which of deviceIDs it is not granted access to.
+	CheckDeviceAccess(ctx context.Context, orgID string, subject *pb.AccessSubject, deviceIDs []string, access pb.AccessLevel) (restricted bool, denied []string, err error)
 
 	// Close releases any resources held by the storage.
 	Close() error
 }
+
+// DeviceFilter selects the devices returned by ListDevices. Zero fields match
+// every device.
+type DeviceFilter struct {
+	Type   string
+	Status pb.DeviceStatus
+	// GroupID selects the devices of a group, static or dynamic.
+	GroupID string
+	// LocationID selects the devices of a location and of its sub-locations.
+	LocationID string
+	// Subject selects the devices granted to a user by their ACL entries in
+	// the organization, if they have any.
+	Subject *pb.AccessSubject
+}
+
+// AccessEntryFilter selects the entries returned by ListAccessEntries. Zero
+// fields match every entry.
+type AccessEntryFilter struct {
+	UserID   string
+	Role     string
+	DeviceID string
+	GroupID  string
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/yourusername/iot-platform/shared/proto/user"
+)
+
+const (
+	// apiKeyPrefix starts every API key, so that leaked keys are easy to
+	// recognize, e.g. by secret scanners
+	apiKeyPrefix = "iotk_"
+	// apiKeyDisplayLength is the number of characters of a key stored in clear
+	// to recognize it
+	apiKeyDisplayLength = 12
+	// apiKeyTouchInterval is how stale the last use of a key may get before
+	// it is written again, to spare a write per request
+	apiKeyTouchInterval = time.Minute
+	// serviceAccountEmailDomain completes the generated email of service
+	// accounts registered without one. The .invalid TLD never resolves.
+	serviceAccountEmailDomain = "@service-accounts.invalid"
+)
+
+// newAPIKey returns a random API key and the hash it is stored as.
+func newAPIKey() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, hashRefreshToken(key), nil
+}
+
+// checkPermissions returns the permissions deduplicated, or an
+// InvalidArgument error if one is malformed.
+func checkPermissions(permissions []string) ([]string, error) {
+	checked := make([]string, 0, len(permissions))
+	seen := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		if permission != "*" && !permissionPattern.MatchString(permission) {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid permission: %q", permission)
+		}
+		if !seen[permission] {
+			seen[permission] = true
+			checked = append(checked, permission)
+		}
+	}
+	return checked, nil
+}
+
+// CreateApiKey creates an API key for a member of an organization. The key
+// acts with the permissions of the member, narrowed to the requested ones if
+// any; it is returned once and only its hash is stored.
+func (s *UserServer) CreateApiKey(ctx context.Context, req *pb.CreateApiKeyRequest) (*pb.CreateApiKeyResponse, error) {
+	log.Printf("📥 CreateApiKey: user_id=%s, org_id=%s, name=%s, permissions=%v", req.UserId, req.OrgId, req.Name, req.Permissions)
+
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name required")
+	}
+	now := time.Now()
+	if req.ExpiresAt != 0 && req.ExpiresAt <= now.Unix() {
+		return nil, status.Error(codes.InvalidArgument, "expiry must be in the future")
+	}
+	permissions, err := checkPermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.storage.GetUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, status.Error(codes.FailedPrecondition, "user is deactivated")
+	}
+	if _, err := s.membershipFor(ctx, user, req.OrgId); err != nil {
+		return nil, err
+	}
+
+	key, keyHash, err := newAPIKey()
+	if err != nil {
+		log.Printf("❌ Failed to generate API key: %v", err)
+		return nil, status.Error(codes.Internal, "failed to generate API key")
+	}
+	apiKey, err := s.storage.CreateAPIKey(ctx, &pb.ApiKey{
+		Id:          uuid.New().String(),
+		UserId:      user.Id,
+		OrgId:       req.OrgId,
+		Name:        req.Name,
+		Prefix:      key[:apiKeyDisplayLength],
+		Permissions: permissions,
+		CreatedAt:   now.Unix(),
+		ExpiresAt:   req.ExpiresAt,
+	}, keyHash)
+	if err != nil {
+		log.Printf("❌ Failed to create API key: %v", err)
+		return nil, err
+	}
+
+	log.Printf("✅ API key created: id=%s, user_id=%s, org_id=%s", apiKey.Id, apiKey.UserId, apiKey.OrgId)
+	return &pb.CreateApiKeyResponse{ApiKey: apiKey, Key: key}, nil
+}
+
+// ListApiKeys returns the API keys that are not revoked, newest first.
+func (s *UserServer) ListApiKeys(ctx context.Context, req *pb.ListApiKeysRequest) (*pb.ListApiKeysResponse, error) {
+	log.Printf("📥 ListApiKeys: user_id=%s, org_id=%s", req.UserId, req.OrgId)
+
+	keys, err := s.storage.ListAPIKeys(ctx, req.UserId, req.OrgId)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ListApiKeysResponse{ApiKeys: keys}, nil
+}
+
+// RevokeApiKey revokes an API key. A key of another user or organization than
+// the requested ones is reported as not found.
+func (s *UserServer) RevokeApiKey(ctx context.Context, req *pb.RevokeApiKeyRequest) (*pb.RevokeApiKeyResponse, error) {
+	log.Printf("📥 RevokeApiKey: id=%s, user_id=%s, org_id=%s", req.Id, req.UserId, req.OrgId)
+
+	key, err := s.storage.GetAPIKey(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if (req.UserId != "" && key.UserId != req.UserId) || (req.OrgId != "" && key.OrgId != req.OrgId) {
+		return nil, status.Errorf(codes.NotFound, "API key %s not found", req.Id)
+	}
+	if err := s.storage.RevokeAPIKey(ctx, req.Id); err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ API key revoked: %s", req.Id)
+	return &pb.RevokeApiKeyResponse{Success: true}, nil
+}
+
+// AuthenticateApiKey returns the key, its owner and their membership in the
+// organization of the key. Unknown, revoked and expired keys, and keys of
+// deactivated users or of users who left the organization, are rejected with
+// Unauthenticated.
+func (s *UserServer) AuthenticateApiKey(ctx context.Context, req *pb.AuthenticateApiKeyRequest) (*pb.AuthenticateApiKeyResponse, error) {
+	// Called on every request authenticated by a key: successes are not logged
+	key, err := s.storage.GetAPIKeyByHash(ctx, hashRefreshToken(req.Key))
+	if status.Code(err) == codes.NotFound {
+		log.Printf("🔒 Unknown API key")
+		return nil, status.Error(codes.Unauthenticated, "invalid API key")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	switch {
+	case key.RevokedAt != 0:
+		log.Printf("🔒 Revoked API key used: id=%s", key.Id)
+		return nil, status.Error(codes.Unauthenticated, "API key revoked")
+	case key.ExpiresAt != 0 && key.ExpiresAt <= now.Unix():
+		log.Printf("🔒 Expired API key used: id=%s", key.Id)
+		return nil, status.Error(codes.Unauthenticated, "API key expired")
+	}
+
+	user, err := s.storage.GetUser(ctx, key.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		log.Printf("🔒 API key of a deactivated user used: id=%s", key.Id)
+		return nil, status.Error(codes.Unauthenticated, "user is deactivated")
+	}
+	membership, err := s.membershipFor(ctx, user, key.OrgId)
+	if status.Code(err) == codes.PermissionDenied || status.Code(err) == codes.NotFound {
+		log.Printf("🔒 API key of a former member used: id=%s", key.Id)
+		return nil, status.Error(codes.Unauthenticated, "user is no longer a member of the organization of the key")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user, err = s.withPermissions(ctx, user); err != nil {
+		return nil, err
+	}
+
+	if now.Sub(time.Unix(key.LastUsedAt, 0)) >= apiKeyTouchInterval {
+		if err := s.storage.TouchAPIKey(ctx, key.Id, now); err != nil {
+			log.Printf("⚠️  Failed to update API key last use: %v", err)
+		} else {
+			key.LastUsedAt = now.Unix()
+		}
+	}
+
+	return &pb.AuthenticateApiKeyResponse{ApiKey: key, User: user, Membership: membership}, nil
+}
@@ -0,0 +1,164 @@
+// +build unit
+
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/yourusername/iot-platform/shared/proto/user"
+	"github.com/yourusername/iot-platform/services/user-service/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newServiceAccount registers a service account of the default organization.
+func newServiceAccount(t *testing.T, server *UserServer) *pb.User {
+	t.Helper()
+	resp, err := server.Register(context.Background(), &pb.RegisterRequest{
+		Name:           "Gateway bot",
+		OrgId:          storage.DefaultOrganizationID,
+		OrgRole:        "user",
+		ServiceAccount: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create service account: %v", err)
+	}
+	return resp.User
+}
+
+func TestRegister_ServiceAccount(t *testing.T) {
+	server := NewUserServer(storage.NewMemoryStorage())
+	ctx := context.Background()
+
+	account := newServiceAccount(t, server)
+	if !account.ServiceAccount || !strings.HasSuffix(account.Email, serviceAccountEmailDomain) {
+		t.Errorf("unexpected service account %+v", account)
+	}
+
+	_, err := server.Register(ctx, &pb.RegisterRequest{Name: "Bot", Password: "Password123!", ServiceAccount: true})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Register with a password error = %v, want InvalidArgument", err)
+	}
+
+	// Service accounts cannot log in, even with an empty password
+	auth, err := server.Authenticate(ctx, &pb.AuthenticateRequest{Email: account.Email, Password: "x"})
+	if err != nil || auth.Success {
+		t.Errorf("Authenticate = %+v, %v, want a failure", auth, err)
+	}
+	_, err = server.CreateSession(ctx, &pb.CreateSessionRequest{UserId: account.Id})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("CreateSession error = %v, want PermissionDenied", err)
+	}
+}
+
+func TestCreateApiKey(t *testing.T) {
+	server := NewUserServer(storage.NewMemoryStorage())
+	ctx := context.Background()
+	account := newServiceAccount(t, server)
+	acme, err := server.CreateOrganization(ctx, &pb.CreateOrganizationRequest{Name: "Acme", Slug: "acme"})
+	if err != nil {
+		t.Fatalf("CreateOrganization failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		request  *pb.CreateApiKeyRequest
+		wantCode codes.Code
+	}{
+		{"valid", &pb.CreateApiKeyRequest{UserId: account.Id, OrgId: storage.DefaultOrganizationID, Name: "CI", Permissions: []string{"devices:read", "devices:read"}}, codes.OK},
+		{"missing_name", &pb.CreateApiKeyRequest{UserId: account.Id, OrgId: storage.DefaultOrganizationID}, codes.InvalidArgument},
+		{"invalid_permission", &pb.CreateApiKeyRequest{UserId: account.Id, OrgId: storage.DefaultOrganizationID, Name: "CI", Permissions: []string{"devices"}}, codes.InvalidArgument},
+		{"past_expiry", &pb.CreateApiKeyRequest{UserId: account.Id, OrgId: storage.DefaultOrganizationID, Name: "CI", ExpiresAt: time.Now().Add(-time.Hour).Unix()}, codes.InvalidArgument},
+		{"not_a_member", &pb.CreateApiKeyRequest{UserId: account.Id, OrgId: acme.Organization.Id, Name: "CI"}, codes.PermissionDenied},
+		{"unknown_user", &pb.CreateApiKeyRequest{UserId: "unknown", OrgId: storage.DefaultOrganizationID, Name: "CI"}, codes.NotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := server.CreateApiKey(ctx, tt.request)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("error = %v, want %v", err, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			if !strings.HasPrefix(resp.Key, apiKeyPrefix) || !strings.HasPrefix(resp.Key, resp.ApiKey.Prefix) {
+				t.Errorf("key %q does not start with %q", resp.Key, resp.ApiKey.Prefix)
+			}
+			if len(resp.ApiKey.Permissions) != 1 {
+				t.Errorf("permissions = %v, want them deduplicated", resp.ApiKey.Permissions)
+			}
+		})
+	}
+}
+
+func TestAuthenticateApiKey(t *testing.T) {
+	server := NewUserServer(storage.NewMemoryStorage())
+	ctx := context.Background()
+	account := newServiceAccount(t, server)
+
+	created, err := server.CreateApiKey(ctx, &pb.CreateApiKeyRequest{UserId: account.Id, OrgId: storage.DefaultOrganizationID, Name: "CI"})
+	if err != nil {
+		t.Fatalf("CreateApiKey failed: %v", err)
+	}
+
+	resp, err := server.AuthenticateApiKey(ctx, &pb.AuthenticateApiKeyRequest{Key: created.Key})
+	if err != nil {
+		t.Fatalf("AuthenticateApiKey failed: %v", err)
+	}
+	if resp.User.Id != account.Id || resp.Membership.Role != "user" || len(resp.Membership.Permissions) != 3 {
+		t.Errorf("unexpected user %+v, membership %+v", resp.User, resp.Membership)
+	}
+	if resp.ApiKey.LastUsedAt == 0 {
+		t.Error("expected the last use to be recorded")
+	}
+
+	_, err = server.AuthenticateApiKey(ctx, &pb.AuthenticateApiKeyRequest{Key: created.Key + "x"})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("AuthenticateApiKey with an unknown key error = %v, want Unauthenticated", err)
+	}
+
+	// Former members lose their keys
+	if _, err := server.RemoveMember(ctx, &pb.RemoveMemberRequest{OrgId: storage.DefaultOrganizationID, UserId: account.Id}); err != nil {
+		t.Fatalf("RemoveMember failed: %v", err)
+	}
+	_, err = server.AuthenticateApiKey(ctx, &pb.AuthenticateApiKeyRequest{Key: created.Key})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("AuthenticateApiKey of a former member error = %v, want Unauthenticated", err)
+	}
+}
+
+func TestRevokeApiKey(t *testing.T) {
+	server := NewUserServer(storage.NewMemoryStorage())
+	ctx := context.Background()
+	account := newServiceAccount(t, server)
+	other := newSessionUser(t, server, "other@example.com")
+
+	created, err := server.CreateApiKey(ctx, &pb.CreateApiKeyRequest{UserId: account.Id, OrgId: storage.DefaultOrganizationID, Name: "CI"})
+	if err != nil {
+		t.Fatalf("CreateApiKey failed: %v", err)
+	}
+
+	_, err = server.RevokeApiKey(ctx, &pb.RevokeApiKeyRequest{Id: created.ApiKey.Id, UserId: other.Id})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("RevokeApiKey of another user error = %v, want NotFound", err)
+	}
+	if _, err := server.RevokeApiKey(ctx, &pb.RevokeApiKeyRequest{Id: created.ApiKey.Id, OrgId: storage.DefaultOrganizationID}); err != nil {
+		t.Fatalf("RevokeApiKey failed: %v", err)
+	}
+
+	_, err = server.AuthenticateApiKey(ctx, &pb.AuthenticateApiKeyRequest{Key: created.Key})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("AuthenticateApiKey with a revoked key error = %v, want Unauthenticated", err)
+	}
+	keys, err := server.ListApiKeys(ctx, &pb.ListApiKeysRequest{UserId: account.Id})
+	if err != nil {
+		t.Fatalf("ListApiKeys failed: %v", err)
+	}
+	if len(keys.ApiKeys) != 0 {
+		t.Errorf("ListApiKeys returned revoked keys %+v", keys.ApiKeys)
+	}
+}
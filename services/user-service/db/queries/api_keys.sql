@@ -0,0 +1,42 @@
+-- IoT Platform - User Service API Key Queries
+
+-- name: CreateApiKey :one
+INSERT INTO api_keys (
+    id,
+    user_id,
+    org_id,
+    name,
+    prefix,
+    key_hash,
+    permissions,
+    created_at,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: GetApiKey :one
+SELECT * FROM api_keys
+WHERE id = $1;
+
+-- name: GetApiKeyByHash :one
+SELECT * FROM api_keys
+WHERE key_hash = $1;
+
+-- Active keys, a NULL user or organization matches all of them
+-- name: ListApiKeys :many
+SELECT * FROM api_keys
+WHERE revoked_at IS NULL
+    AND (sqlc.narg(user_id)::uuid IS NULL OR user_id = sqlc.narg(user_id))
+    AND (sqlc.narg(org_id)::uuid IS NULL OR org_id = sqlc.narg(org_id))
+ORDER BY created_at DESC;
+
+-- name: RevokeApiKey :execrows
+UPDATE api_keys
+SET revoked_at = $2
+WHERE id = $1 AND revoked_at IS NULL;
+
+-- name: TouchApiKey :exec
+UPDATE api_keys
+SET last_used_at = $2
+WHERE id = $1;
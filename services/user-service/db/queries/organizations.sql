@@ -0,0 +1,67 @@
+-- IoT Platform - User Service Organization Queries
+
+-- name: CreateOrganization :one
+INSERT INTO organizations (
+    id,
+    name,
+    slug,
+    created_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetOrganization :one
+SELECT * FROM organizations
+WHERE id = $1;
+
+-- name: ListOrganizations :many
+SELECT * FROM organizations
+ORDER BY created_at, id;
+
+-- Oldest membership first: the first organization is the default one of the user
+-- name: ListUserOrganizations :many
+SELECT o.id, o.name, o.slug, o.created_at, m.role
+FROM organizations o
+JOIN organization_members m ON m.org_id = o.id
+WHERE m.user_id = $1
+ORDER BY m.created_at, o.id;
+
+-- name: AddMember :one
+INSERT INTO organization_members (
+    org_id,
+    user_id,
+    role,
+    created_at
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetMember :one
+SELECT * FROM organization_members
+WHERE org_id = $1 AND user_id = $2;
+
+-- name: UpdateMember :one
+UPDATE organization_members
+SET role = $3
+WHERE org_id = $1 AND user_id = $2
+RETURNING *;
+
+-- name: RemoveMember :execrows
+DELETE FROM organization_members
+WHERE org_id = $1 AND user_id = $2;
+
+-- A NULL role lists the members of every role
+-- name: ListMembers :many
+SELECT u.id, u.email, u.password_hash, u.name, u.role, u.created_at, u.last_login, u.is_active, u.service_account, m.role AS org_role
+FROM users u
+JOIN organization_members m ON m.user_id = u.id
+WHERE m.org_id = sqlc.arg(org_id) AND (sqlc.narg(role)::text IS NULL OR m.role = sqlc.narg(role))
+ORDER BY u.created_at DESC
+LIMIT sqlc.arg(page_limit) OFFSET sqlc.arg(page_offset);
+
+-- name: CountMembers :one
+SELECT COUNT(*) FROM organization_members
+WHERE org_id = sqlc.arg(org_id) AND (sqlc.narg(role)::text IS NULL OR role = sqlc.narg(role));
+
+-- name: CountMembersByRole :one
+SELECT COUNT(*) FROM organization_members WHERE role = $1;
@@ -0,0 +1,32 @@
+-- IoT Platform - User Service Role Queries
+
+-- name: ListRoles :many
+SELECT * FROM roles
+ORDER BY name;
+
+-- name: GetRole :one
+SELECT * FROM roles
+WHERE name = $1;
+
+-- Built-in roles are left unchanged: no row is returned for them
+-- name: UpsertRole :one
+INSERT INTO roles (
+    name,
+    description,
+    permissions,
+    created_at,
+    updated_at
+) VALUES (
+    sqlc.arg(name), sqlc.arg(description), sqlc.arg(permissions), sqlc.arg(updated_at), sqlc.arg(updated_at)
+)
+ON CONFLICT (name) DO UPDATE
+SET
+    description = EXCLUDED.description,
+    permissions = EXCLUDED.permissions,
+    updated_at = EXCLUDED.updated_at
+WHERE roles.built_in = false
+RETURNING *;
+
+-- name: DeleteRole :execrows
+DELETE FROM roles
+WHERE name = $1 AND built_in = false;
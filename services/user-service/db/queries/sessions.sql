@@ -0,0 +1,73 @@
+-- IoT Platform - User Service Session Queries
+
+-- name: CreateSession :one
+INSERT INTO sessions (
+    id,
+    user_id,
+    device,
+    ip_address,
+    user_agent,
+    created_at,
+    last_used_at,
+    expires_at,
+    org_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: GetSession :one
+SELECT * FROM sessions
+WHERE id = $1;
+
+-- Records a refresh of a session still active at last_used_at
+-- name: TouchSession :one
+UPDATE sessions
+SET
+    last_used_at = sqlc.arg(last_used_at),
+    expires_at = sqlc.arg(expires_at),
+    ip_address = sqlc.arg(ip_address),
+    user_agent = sqlc.arg(user_agent)
+WHERE id = sqlc.arg(id) AND revoked_at IS NULL AND expires_at > sqlc.arg(last_used_at)
+RETURNING *;
+
+-- name: SetSessionOrganization :one
+UPDATE sessions
+SET org_id = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: ListActiveSessions :many
+SELECT * FROM sessions
+WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
+ORDER BY last_used_at DESC;
+
+-- name: RevokeSession :execrows
+UPDATE sessions
+SET revoked_at = $2
+WHERE id = $1 AND revoked_at IS NULL;
+
+-- A NULL organization revokes the sessions of all organizations
+-- name: RevokeUserSessions :execrows
+UPDATE sessions
+SET revoked_at = sqlc.arg(revoked_at)
+WHERE user_id = sqlc.arg(user_id) AND revoked_at IS NULL
+    AND (sqlc.narg(org_id)::uuid IS NULL OR org_id = sqlc.narg(org_id));
+
+-- name: InsertRefreshToken :exec
+INSERT INTO refresh_tokens (
+    token_hash,
+    session_id,
+    created_at
+) VALUES (
+    $1, $2, $3
+);
+
+-- name: GetRefreshToken :one
+SELECT * FROM refresh_tokens
+WHERE token_hash = $1;
+
+-- name: UseRefreshToken :one
+UPDATE refresh_tokens
+SET used_at = $2
+WHERE token_hash = $1 AND used_at IS NULL
+RETURNING session_id;
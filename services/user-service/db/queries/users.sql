@@ -9,9 +9,10 @@ INSERT INTO users (
     name,
     role,
     created_at,
-    is_active
+    is_active,
+    service_account
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
+    $1, $2, $3, $4, $5, $6, $7, $8
 ) RETURNING *;
 
 -- name: GetUser :one
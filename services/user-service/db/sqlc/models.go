@@ -56,6 +56,27 @@ func (ns NullDeviceStatus) Value() (driver.Value, error) {
 	return string(ns.DeviceStatus), nil
 }
 
+// API keys of users and service accounts
+type ApiKey struct {
+	ID     pgtype.UUID `json:"id"`
+	UserID pgtype.UUID `json:"user_id"`
+	// Organization the key acts in
+	OrgID pgtype.UUID `json:"org_id"`
+	Name  string      `json:"name"`
+	// First characters of the key, to recognize it
+	Prefix string `json:"prefix"`
+	// SHA-256 of the key, hex encoded
+	KeyHash string `json:"key_hash"`
+	// Subset of the permissions of the user, empty for all of them
+	Permissions []string           `json:"permissions"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	// Expiry, NULL for a key that does not expire
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	// Last authentication, updated at most once a minute
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at"`
+	RevokedAt  pgtype.Timestamptz `json:"revoked_at"`
+}
+
 // IoT devices managed by the platform
 type Device struct {
 	// Unique device identifier (UUID)
@@ -72,6 +93,68 @@ type Device struct {
 	LastSeen pgtype.Timestamptz `json:"last_seen"`
 	// Flexible key-value storage for device-specific data
 	Metadata []byte `json:"metadata"`
+	// Organization owning the device
+	OrgID pgtype.UUID `json:"org_id"`
+}
+
+// Tenants owning devices and their telemetry
+type Organization struct {
+	ID   pgtype.UUID `json:"id"`
+	Name string      `json:"name"`
+	// URL-friendly unique identifier
+	Slug      string             `json:"slug"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+// Membership of users in organizations
+type OrganizationMember struct {
+	OrgID  pgtype.UUID `json:"org_id"`
+	UserID pgtype.UUID `json:"user_id"`
+	// Role of the user in the organization, see roles
+	Role      string             `json:"role"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+// Refresh token family of each session
+type RefreshToken struct {
+	// SHA-256 of the token, hex encoded
+	TokenHash string             `json:"token_hash"`
+	SessionID pgtype.UUID        `json:"session_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	// Rotation time: the token may not be presented again
+	UsedAt pgtype.Timestamptz `json:"used_at"`
+}
+
+// Roles assignable to users
+type Role struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Granted permissions, resource:action or * for all
+	Permissions []string `json:"permissions"`
+	// Seeded role, read-only
+	BuiltIn   bool               `json:"built_in"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+// User sessions, one per login
+type Session struct {
+	ID     pgtype.UUID `json:"id"`
+	UserID pgtype.UUID `json:"user_id"`
+	// Device name given by the client at login
+	Device string `json:"device"`
+	// Client address of the last use
+	IpAddress string `json:"ip_address"`
+	// Client user agent of the last use
+	UserAgent  string             `json:"user_agent"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	LastUsedAt pgtype.Timestamptz `json:"last_used_at"`
+	// Expiry without use, extended by each refresh
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
+	// Logout, revocation or token reuse time
+	RevokedAt pgtype.Timestamptz `json:"revoked_at"`
+	// Organization the session is signed in to
+	OrgID pgtype.UUID `json:"org_id"`
 }
 
 // User accounts for authentication and authorization
@@ -84,7 +167,7 @@ type User struct {
 	PasswordHash string `json:"password_hash"`
 	// User full name
 	Name string `json:"name"`
-	// User role: admin, user, or device
+	// Platform role of the user, admin for every organization
 	Role string `json:"role"`
 	// Account creation timestamp
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
@@ -92,4 +175,6 @@ type User struct {
 	LastLogin pgtype.Timestamptz `json:"last_login"`
 	// Account active status (for soft delete)
 	IsActive bool `json:"is_active"`
+	// Machine client without password, authenticated by API keys only
+	ServiceAccount bool `json:"service_account"`
 }
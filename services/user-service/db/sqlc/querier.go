@@ -11,19 +11,59 @@ import (
 )
 
 type Querier interface {
+	AddMember(ctx context.Context, arg AddMemberParams) (OrganizationMember, error)
+	CountMembers(ctx context.Context, arg CountMembersParams) (int64, error)
+	CountMembersByRole(ctx context.Context, role string) (int64, error)
 	CountUsers(ctx context.Context) (int64, error)
 	CountUsersByRole(ctx context.Context, role string) (int64, error)
+	// IoT Platform - User Service API Key Queries
+	CreateApiKey(ctx context.Context, arg CreateApiKeyParams) (ApiKey, error)
+	// IoT Platform - User Service Organization Queries
+	CreateOrganization(ctx context.Context, arg CreateOrganizationParams) (Organization, error)
 	// IoT Platform - User Service Queries
 	// SQL queries with sqlc annotations for type-safe code generation
+	// IoT Platform - User Service Session Queries
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	DeleteRole(ctx context.Context, name string) (int64, error)
 	DeleteUser(ctx context.Context, id pgtype.UUID) error
+	GetApiKey(ctx context.Context, id pgtype.UUID) (ApiKey, error)
+	GetApiKeyByHash(ctx context.Context, keyHash string) (ApiKey, error)
+	GetMember(ctx context.Context, arg GetMemberParams) (OrganizationMember, error)
+	GetOrganization(ctx context.Context, id pgtype.UUID) (Organization, error)
 	GetPasswordHash(ctx context.Context, email string) (string, error)
+	GetRefreshToken(ctx context.Context, tokenHash string) (RefreshToken, error)
+	GetRole(ctx context.Context, name string) (Role, error)
+	GetSession(ctx context.Context, id pgtype.UUID) (Session, error)
 	GetUser(ctx context.Context, id pgtype.UUID) (User, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
+	InsertRefreshToken(ctx context.Context, arg InsertRefreshTokenParams) error
+	ListActiveSessions(ctx context.Context, arg ListActiveSessionsParams) ([]Session, error)
+	// Active keys, a NULL user or organization matches all of them
+	ListApiKeys(ctx context.Context, arg ListApiKeysParams) ([]ApiKey, error)
+	// A NULL role lists the members of every role
+	ListMembers(ctx context.Context, arg ListMembersParams) ([]ListMembersRow, error)
+	ListOrganizations(ctx context.Context) ([]Organization, error)
+	// IoT Platform - User Service Role Queries
+	ListRoles(ctx context.Context) ([]Role, error)
+	// Oldest membership first: the first organization is the default one of the user
+	ListUserOrganizations(ctx context.Context, userID pgtype.UUID) ([]ListUserOrganizationsRow, error)
 	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
 	ListUsersByRole(ctx context.Context, arg ListUsersByRoleParams) ([]User, error)
+	RemoveMember(ctx context.Context, arg RemoveMemberParams) (int64, error)
+	RevokeApiKey(ctx context.Context, arg RevokeApiKeyParams) (int64, error)
+	RevokeSession(ctx context.Context, arg RevokeSessionParams) (int64, error)
+	RevokeUserSessions(ctx context.Context, arg RevokeUserSessionsParams) (int64, error)
+	SetSessionOrganization(ctx context.Context, arg SetSessionOrganizationParams) (Session, error)
+	TouchApiKey(ctx context.Context, arg TouchApiKeyParams) error
+	// Records a refresh of a session still active at last_used_at
+	TouchSession(ctx context.Context, arg TouchSessionParams) (Session, error)
 	UpdateLastLogin(ctx context.Context, arg UpdateLastLoginParams) error
+	UpdateMember(ctx context.Context, arg UpdateMemberParams) (OrganizationMember, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	// Built-in roles are left unchanged: no row is returned for them
+	UpsertRole(ctx context.Context, arg UpsertRoleParams) (Role, error)
+	UseRefreshToken(ctx context.Context, arg UseRefreshTokenParams) (pgtype.UUID, error)
 }
 
 var _ Querier = (*Queries)(nil)
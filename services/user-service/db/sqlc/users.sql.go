@@ -42,20 +42,22 @@ INSERT INTO users (
     name,
     role,
     created_at,
-    is_active
+    is_active,
+    service_account
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
-) RETURNING id, email, password_hash, name, role, created_at, last_login, is_active
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id, email, password_hash, name, role, created_at, last_login, is_active, service_account
 `
 
 type CreateUserParams struct {
-	ID           pgtype.UUID        `json:"id"`
-	Email        string             `json:"email"`
-	PasswordHash string             `json:"password_hash"`
-	Name         string             `json:"name"`
-	Role         string             `json:"role"`
-	CreatedAt    pgtype.Timestamptz `json:"created_at"`
-	IsActive     bool               `json:"is_active"`
+	ID             pgtype.UUID        `json:"id"`
+	Email          string             `json:"email"`
+	PasswordHash   string             `json:"password_hash"`
+	Name           string             `json:"name"`
+	Role           string             `json:"role"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+	IsActive       bool               `json:"is_active"`
+	ServiceAccount bool               `json:"service_account"`
 }
 
 // IoT Platform - User Service Queries
@@ -69,6 +71,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.Role,
 		arg.CreatedAt,
 		arg.IsActive,
+		arg.ServiceAccount,
 	)
 	var i User
 	err := row.Scan(
@@ -80,6 +83,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.CreatedAt,
 		&i.LastLogin,
 		&i.IsActive,
+		&i.ServiceAccount,
 	)
 	return i, err
 }
@@ -107,7 +111,7 @@ func (q *Queries) GetPasswordHash(ctx context.Context, email string) (string, er
 }
 
 const getUser = `-- name: GetUser :one
-SELECT id, email, password_hash, name, role, created_at, last_login, is_active FROM users
+SELECT id, email, password_hash, name, role, created_at, last_login, is_active, service_account FROM users
 WHERE id = $1
 `
 
@@ -123,12 +127,13 @@ func (q *Queries) GetUser(ctx context.Context, id pgtype.UUID) (User, error) {
 		&i.CreatedAt,
 		&i.LastLogin,
 		&i.IsActive,
+		&i.ServiceAccount,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, password_hash, name, role, created_at, last_login, is_active FROM users
+SELECT id, email, password_hash, name, role, created_at, last_login, is_active, service_account FROM users
 WHERE email = $1
 `
 
@@ -144,12 +149,13 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.CreatedAt,
 		&i.LastLogin,
 		&i.IsActive,
+		&i.ServiceAccount,
 	)
 	return i, err
 }
 
 const listUsers = `-- name: ListUsers :many
-SELECT id, email, password_hash, name, role, created_at, last_login, is_active FROM users
+SELECT id, email, password_hash, name, role, created_at, last_login, is_active, service_account FROM users
 ORDER BY created_at DESC
 LIMIT $1 OFFSET $2
 `
@@ -177,6 +183,7 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 			&i.CreatedAt,
 			&i.LastLogin,
 			&i.IsActive,
+			&i.ServiceAccount,
 		); err != nil {
 			return nil, err
 		}
@@ -189,7 +196,7 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 }
 
 const listUsersByRole = `-- name: ListUsersByRole :many
-SELECT id, email, password_hash, name, role, created_at, last_login, is_active FROM users
+SELECT id, email, password_hash, name, role, created_at, last_login, is_active, service_account FROM users
 WHERE role = $1
 ORDER BY created_at DESC
 LIMIT $2 OFFSET $3
@@ -219,6 +226,7 @@ func (q *Queries) ListUsersByRole(ctx context.Context, arg ListUsersByRoleParams
 			&i.CreatedAt,
 			&i.LastLogin,
 			&i.IsActive,
+			&i.ServiceAccount,
 		); err != nil {
 			return nil, err
 		}
@@ -253,7 +261,7 @@ SET
     role = $2,
     is_active = $3
 WHERE id = $4
-RETURNING id, email, password_hash, name, role, created_at, last_login, is_active
+RETURNING id, email, password_hash, name, role, created_at, last_login, is_active, service_account
 `
 
 type UpdateUserParams struct {
@@ -280,6 +288,7 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.CreatedAt,
 		&i.LastLogin,
 		&i.IsActive,
+		&i.ServiceAccount,
 	)
 	return i, err
 }
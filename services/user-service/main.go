@@ -26,24 +26,34 @@ import (
 type UserServer struct {
 	pb.UnimplementedUserServiceServer
 	storage storage.Storage
+	// sessionTTL is the inactivity after which a session expires
+	sessionTTL time.Duration
 }
 
 // NewUserServer creates a new server instance with the given storage backend.
 func NewUserServer(store storage.Storage) *UserServer {
 	return &UserServer{
-		storage: store,
+		storage:    store,
+		sessionTTL: defaultSessionTTL,
 	}
 }
 
 // Register creates a new user account with hashed password.
 func (s *UserServer) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
-	log.Printf("📥 Register: email=%s, name=%s, role=%s", req.Email, req.Name, req.Role)
-
-	// Validate input
-	if req.Email == "" {
+	log.Printf("📥 Register: email=%s, name=%s, role=%s, org_id=%s, service_account=%t", req.Email, req.Name, req.Role, req.OrgId, req.ServiceAccount)
+
+	// Validate input. Service accounts have no password, and an email is
+	// generated for them if none is given.
+	id := uuid.New().String()
+	email := req.Email
+	switch {
+	case req.ServiceAccount && req.Password != "":
+		return nil, status.Error(codes.InvalidArgument, "service accounts have no password")
+	case req.ServiceAccount && email == "":
+		email = id + serviceAccountEmailDomain
+	case email == "":
 		return nil, status.Error(codes.InvalidArgument, "email required")
-	}
-	if req.Password == "" {
+	case req.Password == "":
 		return nil, status.Error(codes.InvalidArgument, "password required")
 	}
 	if req.Name == "" {
@@ -57,26 +67,45 @@ func (s *UserServer) Register(ctx context.Context, req *pb.RegisterRequest) (*pb
 	}
 
 	// Validate role
-	if role != "admin" && role != "user" && role != "device" {
-		return nil, status.Error(codes.InvalidArgument, "invalid role: must be admin, user, or device")
+	if err := s.checkRole(ctx, role); err != nil {
+		return nil, err
 	}
 
-	// Hash password
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-	if err != nil {
-		log.Printf("❌ Failed to hash password: %v", err)
-		return nil, status.Error(codes.Internal, "failed to process password")
+	// The organization joined, with the same role by default
+	orgRole := req.OrgRole
+	if orgRole == "" {
+		orgRole = role
+	}
+	if req.OrgId != "" {
+		if err := s.checkRole(ctx, orgRole); err != nil {
+			return nil, err
+		}
+		if _, err := s.storage.GetOrganization(ctx, req.OrgId); err != nil {
+			return nil, err
+		}
+	}
+
+	// Hash password. The empty hash of service accounts matches no password.
+	var passwordHash []byte
+	if !req.ServiceAccount {
+		var err error
+		passwordHash, err = bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			log.Printf("❌ Failed to hash password: %v", err)
+			return nil, status.Error(codes.Internal, "failed to process password")
+		}
 	}
 
 	// Create user
 	now := time.Now().Unix()
 	user := &pb.User{
-		Id:        uuid.New().String(),
-		Email:     req.Email,
-		Name:      req.Name,
-		Role:      role,
-		CreatedAt: now,
-		IsActive:  true,
+		Id:             id,
+		Email:          email,
+		Name:           req.Name,
+		Role:           role,
+		CreatedAt:      now,
+		IsActive:       true,
+		ServiceAccount: req.ServiceAccount,
 	}
 
 	createdUser, err := s.storage.CreateUser(ctx, user, string(passwordHash))
@@ -84,6 +113,18 @@ func (s *UserServer) Register(ctx context.Context, req *pb.RegisterRequest) (*pb
 		log.Printf("❌ Failed to create user: %v", err)
 		return nil, err
 	}
+	createdUser, err = s.withPermissions(ctx, createdUser)
+	if err != nil {
+		return nil, err
+	}
+	if req.OrgId != "" {
+		_, err := s.storage.AddMember(ctx, &pb.Membership{OrgId: req.OrgId, UserId: createdUser.Id, Role: orgRole, CreatedAt: now})
+		if err != nil {
+			log.Printf("❌ Failed to add user to organization %s: %v", req.OrgId, err)
+			return nil, err
+		}
+		createdUser.OrgRole = orgRole
+	}
 
 	log.Printf("✅ User registered: id=%s, email=%s", createdUser.Id, createdUser.Email)
 	return &pb.RegisterResponse{
@@ -123,6 +164,15 @@ func (s *UserServer) Authenticate(ctx context.Context, req *pb.AuthenticateReque
 		}, nil
 	}
 
+	// Service accounts only authenticate with API keys
+	if user.ServiceAccount {
+		log.Printf("🔒 Login attempt on service account: %s", req.Email)
+		return &pb.AuthenticateResponse{
+			Success: false,
+			Message: "Service accounts cannot log in",
+		}, nil
+	}
+
 	// Get password hash
 	passwordHash, err := s.storage.GetPasswordHash(ctx, req.Email)
 	if err != nil {
@@ -148,6 +198,11 @@ func (s *UserServer) Authenticate(ctx context.Context, req *pb.AuthenticateReque
 		log.Printf("⚠️  Failed to update last login: %v", err)
 	}
 
+	user, err = s.withPermissions(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
 	log.Printf("✅ Authentication successful: %s", req.Email)
 	return &pb.AuthenticateResponse{
 		User:    user,
@@ -164,6 +219,10 @@ func (s *UserServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.G
 	if err != nil {
 		return nil, err
 	}
+	user, err = s.withPermissions(ctx, user)
+	if err != nil {
+		return nil, err
+	}
 
 	log.Printf("✅ User found: id=%s, email=%s", user.Id, user.Email)
 	return &pb.GetUserResponse{User: user}, nil
@@ -177,19 +236,35 @@ func (s *UserServer) GetUserByEmail(ctx context.Context, req *pb.GetUserByEmailR
 	if err != nil {
 		return nil, err
 	}
+	user, err = s.withPermissions(ctx, user)
+	if err != nil {
+		return nil, err
+	}
 
 	log.Printf("✅ User found: id=%s, email=%s", user.Id, user.Email)
 	return &pb.GetUserByEmailResponse{User: user}, nil
 }
 
-// ListUsers returns paginated user list.
+// ListUsers returns paginated user list, or the members of an organization.
 func (s *UserServer) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
-	log.Printf("📥 ListUsers: page=%d, pageSize=%d, role=%s", req.Page, req.PageSize, req.Role)
+	log.Printf("📥 ListUsers: page=%d, pageSize=%d, role=%s, org_id=%s", req.Page, req.PageSize, req.Role, req.OrgId)
 
-	users, total, err := s.storage.ListUsers(ctx, req.Page, req.PageSize, req.Role)
+	var users []*pb.User
+	var total int32
+	var err error
+	if req.OrgId != "" {
+		users, total, err = s.storage.ListMembers(ctx, req.OrgId, req.Page, req.PageSize, req.Role)
+	} else {
+		users, total, err = s.storage.ListUsers(ctx, req.Page, req.PageSize, req.Role)
+	}
 	if err != nil {
 		return nil, err
 	}
+	for i, user := range users {
+		if users[i], err = s.withPermissions(ctx, user); err != nil {
+			return nil, err
+		}
+	}
 
 	log.Printf("✅ %d users found", len(users))
 	return &pb.ListUsersResponse{
@@ -204,6 +279,12 @@ func (s *UserServer) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*
 func (s *UserServer) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
 	log.Printf("📥 UpdateUser: id=%s", req.Id)
 
+	if req.Role != "" {
+		if err := s.checkRole(ctx, req.Role); err != nil {
+			return nil, err
+		}
+	}
+
 	user := &pb.User{
 		Id:       req.Id,
 		Name:     req.Name,
@@ -216,6 +297,20 @@ func (s *UserServer) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest)
 		return nil, err
 	}
 
+	// A deactivated user can no longer refresh tokens
+	if !updatedUser.IsActive {
+		if revoked, err := s.storage.RevokeUserSessions(ctx, updatedUser.Id, ""); err != nil {
+			log.Printf("⚠️  Failed to revoke sessions of user %s: %v", updatedUser.Id, err)
+		} else if revoked > 0 {
+			log.Printf("🔒 %d sessions revoked for deactivated user %s", revoked, updatedUser.Id)
+		}
+	}
+
+	updatedUser, err = s.withPermissions(ctx, updatedUser)
+	if err != nil {
+		return nil, err
+	}
+
 	log.Printf("✅ User updated: id=%s", updatedUser.Id)
 	return &pb.UpdateUserResponse{User: updatedUser}, nil
 }
@@ -242,6 +337,7 @@ func (s *UserServer) DeleteUser(ctx context.Context, req *pb.DeleteUserRequest)
 //   - USER_SERVICE_PORT: gRPC server port (default: 8082)
 //   - STORAGE_TYPE: "postgres" or "memory" (default: memory)
 //   - DB_HOST, DB_PORT, DB_NAME, DB_USER, DB_PASSWORD, DB_SSLMODE
+//   - SESSION_TTL_HOURS: session lifetime without refresh (default: 720)
 func main() {
 	ctx := context.Background()
 	port := getEnvInt("USER_SERVICE_PORT", 8082)
@@ -290,6 +386,7 @@ func main() {
 
 	grpcServer := grpc.NewServer()
 	userServer := NewUserServer(store)
+	userServer.sessionTTL = time.Duration(getEnvInt("SESSION_TTL_HOURS", int(defaultSessionTTL/time.Hour))) * time.Hour
 	pb.RegisterUserServiceServer(grpcServer, userServer)
 
 	log.Println("=====================================")
@@ -298,6 +395,7 @@ func main() {
 	log.Printf("Protocol: gRPC (HTTP/2)")
 	log.Printf("Port: %d", port)
 	log.Printf("Storage: %s", storageType)
+	log.Printf("Session TTL: %s", userServer.sessionTTL)
 	log.Printf("Address: http://localhost:%d", port)
 	log.Println("-------------------------------------")
 	log.Printf("✅ Server started")
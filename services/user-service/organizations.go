@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/yourusername/iot-platform/shared/proto/user"
+)
+
+// platformAdminRole is the platform role of users who administer every
+// organization, member or not.
+const platformAdminRole = "admin"
+
+// slugPattern is the format of organization slugs, also checked by the organizations table
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// membershipFor returns the membership of user in an organization, with the
+// permissions of its role. Platform admins who are not members act as
+// organization admins.
+func (s *UserServer) membershipFor(ctx context.Context, user *pb.User, orgID string) (*pb.Membership, error) {
+	if _, err := s.storage.GetOrganization(ctx, orgID); err != nil {
+		return nil, err
+	}
+
+	member, err := s.storage.GetMembership(ctx, orgID, user.Id)
+	switch {
+	case status.Code(err) == codes.NotFound && user.Role == platformAdminRole:
+		member = &pb.Membership{OrgId: orgID, UserId: user.Id, Role: platformAdminRole}
+	case status.Code(err) == codes.NotFound:
+		return nil, status.Errorf(codes.PermissionDenied, "user is not a member of organization %s", orgID)
+	case err != nil:
+		return nil, err
+	}
+
+	if member.Permissions, err = s.rolePermissions(ctx, member.Role); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// defaultOrganization returns the organization a user signs in to when none
+// is requested: their oldest membership, or the oldest organization for a
+// platform admin.
+func (s *UserServer) defaultOrganization(ctx context.Context, user *pb.User) (string, error) {
+	orgs, err := s.storage.ListUserOrganizations(ctx, user.Id)
+	if err != nil {
+		return "", err
+	}
+	if len(orgs) == 0 && user.Role == platformAdminRole {
+		if orgs, err = s.storage.ListOrganizations(ctx); err != nil {
+			return "", err
+		}
+	}
+	if len(orgs) == 0 {
+		return "", status.Error(codes.FailedPrecondition, "user is not a member of any organization")
+	}
+	return orgs[0].Id, nil
+}
+
+// CreateOrganization creates an organization, with an optional first admin.
+func (s *UserServer) CreateOrganization(ctx context.Context, req *pb.CreateOrganizationRequest) (*pb.CreateOrganizationResponse, error) {
+	log.Printf("📥 CreateOrganization: name=%s, slug=%s", req.Name, req.Slug)
+
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "name required")
+	}
+	if !slugPattern.MatchString(req.Slug) {
+		return nil, status.Error(codes.InvalidArgument, "invalid slug: lowercase letters, digits and '-', starting with a letter or digit")
+	}
+	if req.OwnerId != "" {
+		if _, err := s.storage.GetUser(ctx, req.OwnerId); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().Unix()
+	org, err := s.storage.CreateOrganization(ctx, &pb.Organization{
+		Id:        uuid.New().String(),
+		Name:      req.Name,
+		Slug:      req.Slug,
+		CreatedAt: now,
+	})
+	if err != nil {
+		log.Printf("❌ Failed to create organization: %v", err)
+		return nil, err
+	}
+
+	if req.OwnerId != "" {
+		_, err := s.storage.AddMember(ctx, &pb.Membership{OrgId: org.Id, UserId: req.OwnerId, Role: platformAdminRole, CreatedAt: now})
+		if err != nil {
+			log.Printf("❌ Failed to add owner to organization %s: %v", org.Id, err)
+			return nil, err
+		}
+		org.Role = platformAdminRole
+	}
+
+	log.Printf("✅ Organization created: id=%s, slug=%s", org.Id, org.Slug)
+	return &pb.CreateOrganizationResponse{Organization: org}, nil
+}
+
+// ListOrganizations returns every organization, or those of a user with
+// their role in each.
+func (s *UserServer) ListOrganizations(ctx context.Context, req *pb.ListOrganizationsRequest) (*pb.ListOrganizationsResponse, error) {
+	log.Printf("📥 ListOrganizations: user_id=%s", req.UserId)
+
+	var orgs []*pb.Organization
+	var err error
+	if req.UserId != "" {
+		orgs, err = s.storage.ListUserOrganizations(ctx, req.UserId)
+	} else {
+		orgs, err = s.storage.ListOrganizations(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ %d organizations found", len(orgs))
+	return &pb.ListOrganizationsResponse{Organizations: orgs}, nil
+}
+
+// AddMember adds a user to an organization with a role.
+func (s *UserServer) AddMember(ctx context.Context, req *pb.AddMemberRequest) (*pb.MemberResponse, error) {
+	log.Printf("📥 AddMember: org_id=%s, user_id=%s, role=%s", req.OrgId, req.UserId, req.Role)
+
+	if err := s.checkRole(ctx, req.Role); err != nil {
+		return nil, err
+	}
+	if _, err := s.storage.GetOrganization(ctx, req.OrgId); err != nil {
+		return nil, err
+	}
+	if _, err := s.storage.GetUser(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+
+	member, err := s.storage.AddMember(ctx, &pb.Membership{
+		OrgId:     req.OrgId,
+		UserId:    req.UserId,
+		Role:      req.Role,
+		CreatedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if member.Permissions, err = s.rolePermissions(ctx, member.Role); err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Member added: org_id=%s, user_id=%s", member.OrgId, member.UserId)
+	return &pb.MemberResponse{Membership: member}, nil
+}
+
+// UpdateMember changes the role of a member. The member gets its permissions
+// with their next access token.
+func (s *UserServer) UpdateMember(ctx context.Context, req *pb.UpdateMemberRequest) (*pb.MemberResponse, error) {
+	log.Printf("📥 UpdateMember: org_id=%s, user_id=%s, role=%s", req.OrgId, req.UserId, req.Role)
+
+	if err := s.checkRole(ctx, req.Role); err != nil {
+		return nil, err
+	}
+
+	member, err := s.storage.UpdateMember(ctx, &pb.Membership{OrgId: req.OrgId, UserId: req.UserId, Role: req.Role})
+	if err != nil {
+		return nil, err
+	}
+	if member.Permissions, err = s.rolePermissions(ctx, member.Role); err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Member updated: org_id=%s, user_id=%s", member.OrgId, member.UserId)
+	return &pb.MemberResponse{Membership: member}, nil
+}
+
+// GetMembership returns the membership of a user in an organization.
+func (s *UserServer) GetMembership(ctx context.Context, req *pb.GetMembershipRequest) (*pb.MemberResponse, error) {
+	log.Printf("📥 GetMembership: org_id=%s, user_id=%s", req.OrgId, req.UserId)
+
+	member, err := s.storage.GetMembership(ctx, req.OrgId, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if member.Permissions, err = s.rolePermissions(ctx, member.Role); err != nil {
+		return nil, err
+	}
+
+	return &pb.MemberResponse{Membership: member}, nil
+}
+
+// RemoveMember removes a user from an organization. Their sessions in it can
+// no longer be refreshed.
+func (s *UserServer) RemoveMember(ctx context.Context, req *pb.RemoveMemberRequest) (*pb.RemoveMemberResponse, error) {
+	log.Printf("📥 RemoveMember: org_id=%s, user_id=%s", req.OrgId, req.UserId)
+
+	if err := s.storage.RemoveMember(ctx, req.OrgId, req.UserId); err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Member removed: org_id=%s, user_id=%s", req.OrgId, req.UserId)
+	return &pb.RemoveMemberResponse{Success: true}, nil
+}
@@ -0,0 +1,187 @@
+// +build unit
+
+package main
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/yourusername/iot-platform/shared/proto/user"
+	"github.com/yourusername/iot-platform/services/user-service/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateOrganization(t *testing.T) {
+	server := NewUserServer(storage.NewMemoryStorage())
+	ctx := context.Background()
+	owner := newSessionUser(t, server, "owner@example.com")
+
+	tests := []struct {
+		name     string
+		request  *pb.CreateOrganizationRequest
+		wantCode codes.Code
+	}{
+		{"valid", &pb.CreateOrganizationRequest{Name: "Acme", Slug: "acme", OwnerId: owner.Id}, codes.OK},
+		{"taken_slug", &pb.CreateOrganizationRequest{Name: "Acme", Slug: "acme"}, codes.AlreadyExists},
+		{"invalid_slug", &pb.CreateOrganizationRequest{Name: "Acme", Slug: "Acme Corp"}, codes.InvalidArgument},
+		{"missing_name", &pb.CreateOrganizationRequest{Slug: "other"}, codes.InvalidArgument},
+		{"unknown_owner", &pb.CreateOrganizationRequest{Name: "Other", Slug: "other", OwnerId: "unknown"}, codes.NotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := server.CreateOrganization(ctx, tt.request)
+			if status.Code(err) != tt.wantCode {
+				t.Errorf("error = %v, want %v", err, tt.wantCode)
+			}
+		})
+	}
+
+	// The owner is an admin of the organization
+	orgs, err := server.ListOrganizations(ctx, &pb.ListOrganizationsRequest{UserId: owner.Id})
+	if err != nil {
+		t.Fatalf("ListOrganizations failed: %v", err)
+	}
+	if len(orgs.Organizations) != 2 || orgs.Organizations[1].Slug != "acme" || orgs.Organizations[1].Role != "admin" {
+		t.Errorf("unexpected organizations %+v", orgs.Organizations)
+	}
+}
+
+// TestCreateSession_Organization tests which organization a session is
+// signed in to.
+func TestCreateSession_Organization(t *testing.T) {
+	server := NewUserServer(storage.NewMemoryStorage())
+	ctx := context.Background()
+	user := newSessionUser(t, server, "member@example.com")
+	acme, err := server.CreateOrganization(ctx, &pb.CreateOrganizationRequest{Name: "Acme", Slug: "acme"})
+	if err != nil {
+		t.Fatalf("CreateOrganization failed: %v", err)
+	}
+	orgID := acme.Organization.Id
+
+	// Members only
+	_, err = server.CreateSession(ctx, &pb.CreateSessionRequest{UserId: user.Id, OrgId: orgID})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for a non-member, got %v", err)
+	}
+
+	if _, err := server.AddMember(ctx, &pb.AddMemberRequest{OrgId: orgID, UserId: user.Id, Role: "admin"}); err != nil {
+		t.Fatalf("AddMember failed: %v", err)
+	}
+	created, err := server.CreateSession(ctx, &pb.CreateSessionRequest{UserId: user.Id, OrgId: orgID})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if created.Session.OrgId != orgID || created.Membership.Role != "admin" || created.Membership.Permissions[0] != "*" {
+		t.Errorf("unexpected session %+v, membership %+v", created.Session, created.Membership)
+	}
+
+	// A user without organization cannot sign in, unless platform admin
+	loner, err := server.Register(ctx, &pb.RegisterRequest{Email: "loner@example.com", Password: "Password123!", Name: "Loner"})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	_, err = server.CreateSession(ctx, &pb.CreateSessionRequest{UserId: loner.User.Id})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition without organization, got %v", err)
+	}
+	admin, err := server.Register(ctx, &pb.RegisterRequest{Email: "root@example.com", Password: "Password123!", Name: "Root", Role: "admin"})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	created, err = server.CreateSession(ctx, &pb.CreateSessionRequest{UserId: admin.User.Id, OrgId: orgID})
+	if err != nil {
+		t.Fatalf("CreateSession failed for a platform admin: %v", err)
+	}
+	if created.Membership.Role != "admin" {
+		t.Errorf("unexpected membership %+v", created.Membership)
+	}
+}
+
+func TestSwitchSessionOrganization(t *testing.T) {
+	server := NewUserServer(storage.NewMemoryStorage())
+	ctx := context.Background()
+	user := newSessionUser(t, server, "member@example.com")
+	acme, err := server.CreateOrganization(ctx, &pb.CreateOrganizationRequest{Name: "Acme", Slug: "acme"})
+	if err != nil {
+		t.Fatalf("CreateOrganization failed: %v", err)
+	}
+	orgID := acme.Organization.Id
+
+	created, err := server.CreateSession(ctx, &pb.CreateSessionRequest{UserId: user.Id})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	req := &pb.SwitchSessionOrganizationRequest{SessionId: created.Session.Id, UserId: user.Id, OrgId: orgID}
+	if _, err := server.SwitchSessionOrganization(ctx, req); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for a non-member, got %v", err)
+	}
+
+	if _, err := server.AddMember(ctx, &pb.AddMemberRequest{OrgId: orgID, UserId: user.Id, Role: "user"}); err != nil {
+		t.Fatalf("AddMember failed: %v", err)
+	}
+	_, err = server.SwitchSessionOrganization(ctx, &pb.SwitchSessionOrganizationRequest{SessionId: created.Session.Id, UserId: "other", OrgId: orgID})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for the session of another user, got %v", err)
+	}
+	switched, err := server.SwitchSessionOrganization(ctx, req)
+	if err != nil {
+		t.Fatalf("SwitchSessionOrganization failed: %v", err)
+	}
+	if switched.Session.OrgId != orgID || switched.Membership.OrgId != orgID {
+		t.Errorf("unexpected session %+v", switched.Session)
+	}
+
+	// Refreshes stay in the new organization, until the user is removed from it
+	refreshed, err := server.RefreshSession(ctx, &pb.RefreshSessionRequest{RefreshToken: created.RefreshToken})
+	if err != nil {
+		t.Fatalf("RefreshSession failed: %v", err)
+	}
+	if refreshed.Session.OrgId != orgID || refreshed.Membership.Role != "user" {
+		t.Errorf("unexpected refresh %+v, membership %+v", refreshed.Session, refreshed.Membership)
+	}
+	if _, err := server.RemoveMember(ctx, &pb.RemoveMemberRequest{OrgId: orgID, UserId: user.Id}); err != nil {
+		t.Fatalf("RemoveMember failed: %v", err)
+	}
+	_, err = server.RefreshSession(ctx, &pb.RefreshSessionRequest{RefreshToken: refreshed.RefreshToken})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated after removal, got %v", err)
+	}
+}
+
+func TestListUsers_Organization(t *testing.T) {
+	server := NewUserServer(storage.NewMemoryStorage())
+	ctx := context.Background()
+	newSessionUser(t, server, "member@example.com")
+	if _, err := server.Register(ctx, &pb.RegisterRequest{Email: "outsider@example.com", Password: "Password123!", Name: "Outsider"}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	resp, err := server.ListUsers(ctx, &pb.ListUsersRequest{Page: 1, PageSize: 10, OrgId: storage.DefaultOrganizationID})
+	if err != nil {
+		t.Fatalf("ListUsers failed: %v", err)
+	}
+	if resp.Total != 1 || resp.Users[0].Email != "member@example.com" || resp.Users[0].OrgRole != "user" || len(resp.Users[0].Permissions) == 0 {
+		t.Errorf("unexpected members %+v", resp.Users)
+	}
+}
+
+func TestDeleteRole_AssignedToMembers(t *testing.T) {
+	server := NewUserServer(storage.NewMemoryStorage())
+	ctx := context.Background()
+	user := newSessionUser(t, server, "member@example.com")
+
+	if _, err := server.UpsertRole(ctx, &pb.UpsertRoleRequest{Name: "viewer", Permissions: []string{"devices:read"}}); err != nil {
+		t.Fatalf("UpsertRole failed: %v", err)
+	}
+	if _, err := server.UpdateMember(ctx, &pb.UpdateMemberRequest{OrgId: storage.DefaultOrganizationID, UserId: user.Id, Role: "viewer"}); err != nil {
+		t.Fatalf("UpdateMember failed: %v", err)
+	}
+	if _, err := server.DeleteRole(ctx, &pb.DeleteRoleRequest{Name: "viewer"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition for a role assigned to members, got %v", err)
+	}
+	if _, err := server.UpdateMember(ctx, &pb.UpdateMemberRequest{OrgId: storage.DefaultOrganizationID, UserId: user.Id, Role: "unknown"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an unknown role, got %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"log"
+	"regexp"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/yourusername/iot-platform/shared/proto/user"
+)
+
+var (
+	// rolePattern is the format of role names, also checked by the roles table
+	rolePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,49}$`)
+	// permissionPattern is the format of permissions, "*" aside. Which
+	// permissions exist is up to the services enforcing them.
+	permissionPattern = regexp.MustCompile(`^[a-z][a-z-]*:[a-z][a-z-]*$`)
+)
+
+// checkRole returns an InvalidArgument error unless the role exists.
+func (s *UserServer) checkRole(ctx context.Context, name string) error {
+	if _, err := s.storage.GetRole(ctx, name); err != nil {
+		if status.Code(err) == codes.NotFound {
+			return status.Errorf(codes.InvalidArgument, "unknown role: %s", name)
+		}
+		return err
+	}
+	return nil
+}
+
+// withPermissions returns a copy of user with the permissions of its role.
+// A user whose role is missing gets none.
+func (s *UserServer) withPermissions(ctx context.Context, user *pb.User) (*pb.User, error) {
+	user = proto.Clone(user).(*pb.User)
+	permissions, err := s.rolePermissions(ctx, user.Role)
+	if err != nil {
+		return nil, err
+	}
+	user.Permissions = permissions
+	return user, nil
+}
+
+// rolePermissions returns the permissions of a role, none if it is missing.
+func (s *UserServer) rolePermissions(ctx context.Context, name string) ([]string, error) {
+	role, err := s.storage.GetRole(ctx, name)
+	switch {
+	case status.Code(err) == codes.NotFound:
+		log.Printf("⚠️  Unknown role %s, no permissions granted", name)
+		return []string{}, nil
+	case err != nil:
+		return nil, err
+	}
+	return role.Permissions, nil
+}
+
+// ListRoles returns the roles, sorted by name.
+func (s *UserServer) ListRoles(ctx context.Context, req *pb.ListRolesRequest) (*pb.ListRolesResponse, error) {
+	log.Printf("📥 ListRoles")
+
+	roles, err := s.storage.ListRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ %d roles found", len(roles))
+	return &pb.ListRolesResponse{Roles: roles}, nil
+}
+
+// GetRole retrieves a role by name.
+func (s *UserServer) GetRole(ctx context.Context, req *pb.GetRoleRequest) (*pb.GetRoleResponse, error) {
+	log.Printf("📥 GetRole: name=%s", req.Name)
+
+	role, err := s.storage.GetRole(ctx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetRoleResponse{Role: role}, nil
+}
+
+// UpsertRole creates or updates a custom role. Users of the role get its new
+// permissions with their next access token.
+func (s *UserServer) UpsertRole(ctx context.Context, req *pb.UpsertRoleRequest) (*pb.UpsertRoleResponse, error) {
+	log.Printf("📥 UpsertRole: name=%s, permissions=%v", req.Name, req.Permissions)
+
+	if !rolePattern.MatchString(req.Name) {
+		return nil, status.Error(codes.InvalidArgument, "invalid role name: lowercase letters, digits, '-' and '_', starting with a letter")
+	}
+
+	// Duplicates are dropped, order is kept
+	permissions := make([]string, 0, len(req.Permissions))
+	seen := make(map[string]bool, len(req.Permissions))
+	for _, permission := range req.Permissions {
+		if permission != "*" && !permissionPattern.MatchString(permission) {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid permission %q: must be resource:action or *", permission)
+		}
+		if !seen[permission] {
+			seen[permission] = true
+			permissions = append(permissions, permission)
+		}
+	}
+
+	role, err := s.storage.UpsertRole(ctx, &pb.Role{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: permissions,
+		UpdatedAt:   time.Now().Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Role saved: name=%s", role.Name)
+	return &pb.UpsertRoleResponse{Role: role}, nil
+}
+
+// DeleteRole removes a custom role that is no longer assigned, to users or
+// organization members.
+func (s *UserServer) DeleteRole(ctx context.Context, req *pb.DeleteRoleRequest) (*pb.DeleteRoleResponse, error) {
+	log.Printf("📥 DeleteRole: name=%s", req.Name)
+
+	if err := s.storage.DeleteRole(ctx, req.Name); err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Role deleted: name=%s", req.Name)
+	return &pb.DeleteRoleResponse{Success: true}, nil
+}
@@ -0,0 +1,163 @@
+// +build unit
+
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	pb "github.com/yourusername/iot-platform/shared/proto/user"
+	"github.com/yourusername/iot-platform/services/user-service/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUpsertRole(t *testing.T) {
+	server := NewUserServer(storage.NewMemoryStorage())
+	ctx := context.Background()
+
+	resp, err := server.UpsertRole(ctx, &pb.UpsertRoleRequest{
+		Name:        "viewer",
+		Description: "Read-only access",
+		Permissions: []string{"devices:read", "telemetry:read", "devices:read"},
+	})
+	if err != nil {
+		t.Fatalf("UpsertRole failed: %v", err)
+	}
+	if want := []string{"devices:read", "telemetry:read"}; !reflect.DeepEqual(resp.Role.Permissions, want) {
+		t.Errorf("permissions = %v, want %v", resp.Role.Permissions, want)
+	}
+	if resp.Role.BuiltIn {
+		t.Error("custom role should not be built-in")
+	}
+
+	// Update keeps the creation time
+	updated, err := server.UpsertRole(ctx, &pb.UpsertRoleRequest{Name: "viewer", Permissions: []string{"devices:read"}})
+	if err != nil {
+		t.Fatalf("UpsertRole update failed: %v", err)
+	}
+	if updated.Role.CreatedAt != resp.Role.CreatedAt || len(updated.Role.Permissions) != 1 {
+		t.Errorf("unexpected updated role %+v", updated.Role)
+	}
+
+	tests := []struct {
+		name string
+		req  *pb.UpsertRoleRequest
+		code codes.Code
+	}{
+		{"invalid_name", &pb.UpsertRoleRequest{Name: "Viewer!"}, codes.InvalidArgument},
+		{"empty_name", &pb.UpsertRoleRequest{}, codes.InvalidArgument},
+		{"invalid_permission", &pb.UpsertRoleRequest{Name: "viewer", Permissions: []string{"devices"}}, codes.InvalidArgument},
+		{"built_in", &pb.UpsertRoleRequest{Name: "user", Permissions: []string{"*"}}, codes.FailedPrecondition},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := server.UpsertRole(ctx, tt.req); status.Code(err) != tt.code {
+				t.Errorf("expected %v, got %v", tt.code, err)
+			}
+		})
+	}
+
+	roles, err := server.ListRoles(ctx, &pb.ListRolesRequest{})
+	if err != nil {
+		t.Fatalf("ListRoles failed: %v", err)
+	}
+	var names []string
+	for _, role := range roles.Roles {
+		names = append(names, role.Name)
+	}
+	if want := []string{"admin", "device", "user", "viewer"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("roles = %v, want %v", names, want)
+	}
+}
+
+// TestCustomRole_Permissions tests that users of a custom role get its
+// current permissions.
+func TestCustomRole_Permissions(t *testing.T) {
+	server := NewUserServer(storage.NewMemoryStorage())
+	ctx := context.Background()
+
+	_, err := server.Register(ctx, &pb.RegisterRequest{Email: "viewer@example.com", Password: "Password123!", Name: "Viewer", Role: "viewer"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an unknown role, got %v", err)
+	}
+
+	if _, err := server.UpsertRole(ctx, &pb.UpsertRoleRequest{Name: "viewer", Permissions: []string{"devices:read"}}); err != nil {
+		t.Fatalf("UpsertRole failed: %v", err)
+	}
+	registered, err := server.Register(ctx, &pb.RegisterRequest{Email: "viewer@example.com", Password: "Password123!", Name: "Viewer", Role: "viewer", OrgId: storage.DefaultOrganizationID})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if !reflect.DeepEqual(registered.User.Permissions, []string{"devices:read"}) {
+		t.Errorf("permissions = %v, want [devices:read]", registered.User.Permissions)
+	}
+
+	// A refresh picks up the new permissions of the role
+	session, err := server.CreateSession(ctx, &pb.CreateSessionRequest{UserId: registered.User.Id})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if _, err := server.UpsertRole(ctx, &pb.UpsertRoleRequest{Name: "viewer", Permissions: []string{"devices:read", "telemetry:read"}}); err != nil {
+		t.Fatalf("UpsertRole failed: %v", err)
+	}
+	refreshed, err := server.RefreshSession(ctx, &pb.RefreshSessionRequest{RefreshToken: session.RefreshToken})
+	if err != nil {
+		t.Fatalf("RefreshSession failed: %v", err)
+	}
+	if len(refreshed.User.Permissions) != 2 || len(refreshed.Membership.Permissions) != 2 {
+		t.Errorf("expected the updated permissions, got %v and %v", refreshed.User.Permissions, refreshed.Membership.Permissions)
+	}
+
+	auth, err := server.Authenticate(ctx, &pb.AuthenticateRequest{Email: "viewer@example.com", Password: "Password123!"})
+	if err != nil || !auth.Success {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if len(auth.User.Permissions) != 2 {
+		t.Errorf("expected permissions on authentication, got %v", auth.User.Permissions)
+	}
+
+	// The stored user is not changed
+	stored, _ := server.storage.GetUser(ctx, registered.User.Id)
+	if stored.Permissions != nil {
+		t.Errorf("permissions should not be stored on the user, got %v", stored.Permissions)
+	}
+
+	if _, err := server.UpdateUser(ctx, &pb.UpdateUserRequest{Id: registered.User.Id, Name: "Viewer", Role: "superuser", IsActive: true}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for an unknown role, got %v", err)
+	}
+}
+
+func TestDeleteRole(t *testing.T) {
+	server := NewUserServer(storage.NewMemoryStorage())
+	ctx := context.Background()
+
+	if _, err := server.UpsertRole(ctx, &pb.UpsertRoleRequest{Name: "viewer", Permissions: []string{"devices:read"}}); err != nil {
+		t.Fatalf("UpsertRole failed: %v", err)
+	}
+	user, err := server.Register(ctx, &pb.RegisterRequest{Email: "viewer@example.com", Password: "Password123!", Name: "Viewer", Role: "viewer"})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	if _, err := server.DeleteRole(ctx, &pb.DeleteRoleRequest{Name: "viewer"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition for an assigned role, got %v", err)
+	}
+	if _, err := server.DeleteRole(ctx, &pb.DeleteRoleRequest{Name: "admin"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition for a built-in role, got %v", err)
+	}
+	if _, err := server.DeleteRole(ctx, &pb.DeleteRoleRequest{Name: "unknown"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+
+	if _, err := server.UpdateUser(ctx, &pb.UpdateUserRequest{Id: user.User.Id, Name: "Viewer", Role: "user", IsActive: true}); err != nil {
+		t.Fatalf("UpdateUser failed: %v", err)
+	}
+	if _, err := server.DeleteRole(ctx, &pb.DeleteRoleRequest{Name: "viewer"}); err != nil {
+		t.Fatalf("DeleteRole failed: %v", err)
+	}
+	if _, err := server.GetRole(ctx, &pb.GetRoleRequest{Name: "viewer"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound after deletion, got %v", err)
+	}
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/yourusername/iot-platform/shared/proto/user"
+	"github.com/yourusername/iot-platform/services/user-service/storage"
+)
+
+// defaultSessionTTL is the lifetime of a session that is not refreshed
+const defaultSessionTTL = 30 * 24 * time.Hour
+
+// newRefreshToken returns a random refresh token and the hash it is stored as.
+func newRefreshToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, hashRefreshToken(token), nil
+}
+
+// hashRefreshToken returns the SHA-256 of a refresh token. Tokens are random,
+// a fast hash is enough.
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateSession opens a session for an authenticated user and returns its
+// first refresh token. The session is signed in to the requested
+// organization, or by default to the oldest one of the user.
+func (s *UserServer) CreateSession(ctx context.Context, req *pb.CreateSessionRequest) (*pb.CreateSessionResponse, error) {
+	log.Printf("📥 CreateSession: user_id=%s, org_id=%s, device=%s, ip=%s", req.UserId, req.OrgId, req.Device, req.IpAddress)
+
+	user, err := s.storage.GetUser(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, status.Error(codes.PermissionDenied, "user is deactivated")
+	}
+	if user.ServiceAccount {
+		return nil, status.Error(codes.PermissionDenied, "service accounts cannot open sessions")
+	}
+
+	orgID := req.OrgId
+	if orgID == "" {
+		if orgID, err = s.defaultOrganization(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+	membership, err := s.membershipFor(ctx, user, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, hash, err := newRefreshToken()
+	if err != nil {
+		log.Printf("❌ Failed to generate refresh token: %v", err)
+		return nil, status.Error(codes.Internal, "failed to generate refresh token")
+	}
+
+	now := time.Now()
+	session, err := s.storage.CreateSession(ctx, &pb.Session{
+		Id:         uuid.New().String(),
+		UserId:     user.Id,
+		Device:     req.Device,
+		IpAddress:  req.IpAddress,
+		UserAgent:  req.UserAgent,
+		CreatedAt:  now.Unix(),
+		LastUsedAt: now.Unix(),
+		ExpiresAt:  now.Add(s.sessionTTL).Unix(),
+		OrgId:      orgID,
+	}, hash)
+	if err != nil {
+		log.Printf("❌ Failed to create session: %v", err)
+		return nil, err
+	}
+
+	log.Printf("✅ Session created: id=%s, user_id=%s, org_id=%s", session.Id, session.UserId, session.OrgId)
+	return &pb.CreateSessionResponse{Session: session, RefreshToken: token, Membership: membership}, nil
+}
+
+// RefreshSession exchanges a refresh token for a new one and extends its
+// session. A token used twice revokes its session: one of the two holders
+// stole it. So does the removal of the user from the organization of the
+// session.
+func (s *UserServer) RefreshSession(ctx context.Context, req *pb.RefreshSessionRequest) (*pb.RefreshSessionResponse, error) {
+	log.Printf("📥 RefreshSession: ip=%s", req.IpAddress)
+
+	if req.RefreshToken == "" {
+		return nil, status.Error(codes.InvalidArgument, "refresh token required")
+	}
+
+	token, hash, err := newRefreshToken()
+	if err != nil {
+		log.Printf("❌ Failed to generate refresh token: %v", err)
+		return nil, status.Error(codes.Internal, "failed to generate refresh token")
+	}
+
+	now := time.Now()
+	session, err := s.storage.RotateRefreshToken(ctx, hashRefreshToken(req.RefreshToken), hash, storage.SessionUse{
+		At:        now,
+		ExpiresAt: now.Add(s.sessionTTL),
+		IPAddress: req.IpAddress,
+		UserAgent: req.UserAgent,
+	})
+	switch {
+	case errors.Is(err, storage.ErrRefreshTokenReused):
+		log.Printf("🚨 Refresh token reuse detected: session=%s, user_id=%s, ip=%s", session.Id, session.UserId, req.IpAddress)
+		if err := s.storage.RevokeSession(ctx, session.Id); err != nil {
+			log.Printf("❌ Failed to revoke session %s: %v", session.Id, err)
+		}
+		return nil, status.Error(codes.Unauthenticated, "refresh token reuse detected")
+	case errors.Is(err, storage.ErrSessionInactive), status.Code(err) == codes.NotFound:
+		return nil, status.Error(codes.Unauthenticated, "invalid or expired refresh token")
+	case err != nil:
+		log.Printf("❌ Failed to rotate refresh token: %v", err)
+		return nil, err
+	}
+
+	user, err := s.storage.GetUser(ctx, session.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		if err := s.storage.RevokeSession(ctx, session.Id); err != nil {
+			log.Printf("❌ Failed to revoke session %s: %v", session.Id, err)
+		}
+		return nil, status.Error(codes.Unauthenticated, "user is deactivated")
+	}
+	// Sessions opened before organizations existed are moved to the default one
+	if session.OrgId == "" {
+		orgID, err := s.defaultOrganization(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		if session, err = s.storage.SetSessionOrganization(ctx, session.Id, orgID); err != nil {
+			return nil, err
+		}
+	}
+
+	// The new access token carries the current permissions of the roles
+	user, err = s.withPermissions(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	membership, err := s.membershipFor(ctx, user, session.OrgId)
+	if status.Code(err) == codes.PermissionDenied || status.Code(err) == codes.NotFound {
+		if err := s.storage.RevokeSession(ctx, session.Id); err != nil {
+			log.Printf("❌ Failed to revoke session %s: %v", session.Id, err)
+		}
+		return nil, status.Error(codes.Unauthenticated, "user is no longer a member of the organization")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Session refreshed: id=%s, user_id=%s", session.Id, session.UserId)
+	return &pb.RefreshSessionResponse{Session: session, RefreshToken: token, User: user, Membership: membership}, nil
+}
+
+// SwitchSessionOrganization signs an active session of a user in to another
+// organization the user is a member of. The refresh token is unchanged.
+func (s *UserServer) SwitchSessionOrganization(ctx context.Context, req *pb.SwitchSessionOrganizationRequest) (*pb.SwitchSessionOrganizationResponse, error) {
+	log.Printf("📥 SwitchSessionOrganization: session=%s, org_id=%s", req.SessionId, req.OrgId)
+
+	session, err := s.storage.GetSession(ctx, req.SessionId)
+	if err != nil {
+		return nil, err
+	}
+	// Sessions of other users are reported as missing, like inactive ones
+	if session.UserId != req.UserId || session.RevokedAt != 0 || session.ExpiresAt <= time.Now().Unix() {
+		return nil, status.Errorf(codes.NotFound, "session %s not found", req.SessionId)
+	}
+
+	user, err := s.storage.GetUser(ctx, session.UserId)
+	if err != nil {
+		return nil, err
+	}
+	user, err = s.withPermissions(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	membership, err := s.membershipFor(ctx, user, req.OrgId)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err = s.storage.SetSessionOrganization(ctx, session.Id, req.OrgId)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Session switched: id=%s, org_id=%s", session.Id, session.OrgId)
+	return &pb.SwitchSessionOrganizationResponse{Session: session, User: user, Membership: membership}, nil
+}
+
+// RevokeSession revokes a session, optionally checking it belongs to a user.
+func (s *UserServer) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	log.Printf("📥 RevokeSession: id=%s", req.Id)
+
+	if req.UserId != "" {
+		session, err := s.storage.GetSession(ctx, req.Id)
+		if err != nil {
+			return nil, err
+		}
+		// Sessions of other users are reported as missing
+		if session.UserId != req.UserId {
+			return nil, status.Errorf(codes.NotFound, "session %s not found", req.Id)
+		}
+	}
+
+	if err := s.storage.RevokeSession(ctx, req.Id); err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ Session revoked: id=%s", req.Id)
+	return &pb.RevokeSessionResponse{Success: true}, nil
+}
+
+// RevokeUserSessions revokes the active sessions of a user, in the
+// organization of the request or, without one, in all organizations.
+func (s *UserServer) RevokeUserSessions(ctx context.Context, req *pb.RevokeUserSessionsRequest) (*pb.RevokeUserSessionsResponse, error) {
+	log.Printf("📥 RevokeUserSessions: user_id=%s, org_id=%s", req.UserId, req.OrgId)
+
+	revoked, err := s.storage.RevokeUserSessions(ctx, req.UserId, req.OrgId)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ %d sessions revoked: user_id=%s, org_id=%s", revoked, req.UserId, req.OrgId)
+	return &pb.RevokeUserSessionsResponse{Revoked: revoked}, nil
+}
+
+// ListSessions returns the active sessions of a user, most recently used
+// first.
+func (s *UserServer) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	log.Printf("📥 ListSessions: user_id=%s", req.UserId)
+
+	sessions, err := s.storage.ListSessions(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("✅ %d sessions found", len(sessions))
+	return &pb.ListSessionsResponse{Sessions: sessions}, nil
+}
@@ -0,0 +1,259 @@
+// +build unit
+
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pb "github.com/yourusername/iot-platform/shared/proto/user"
+	"github.com/yourusername/iot-platform/services/user-service/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newSessionUser registers a user to open sessions for.
+func newSessionUser(t *testing.T, server *UserServer, email string) *pb.User {
+	t.Helper()
+	resp, err := server.Register(context.Background(), &pb.RegisterRequest{
+		Email:    email,
+		Password: "Password123!",
+		Name:     "Session User",
+		Role:     "user",
+		OrgId:    storage.DefaultOrganizationID,
+	})
+	if err != nil {
+		t.Fatalf("failed to create test user: %v", err)
+	}
+	return resp.User
+}
+
+func TestCreateSession(t *testing.T) {
+	server := NewUserServer(storage.NewMemoryStorage())
+	ctx := context.Background()
+	user := newSessionUser(t, server, "session@example.com")
+
+	resp, err := server.CreateSession(ctx, &pb.CreateSessionRequest{
+		UserId:    user.Id,
+		Device:    "Laptop",
+		IpAddress: "10.0.0.1",
+		UserAgent: "Mozilla/5.0",
+	})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if resp.RefreshToken == "" {
+		t.Error("expected a refresh token")
+	}
+	if resp.Session.Device != "Laptop" || resp.Session.IpAddress != "10.0.0.1" {
+		t.Errorf("unexpected session %+v", resp.Session)
+	}
+	if resp.Session.OrgId != storage.DefaultOrganizationID || resp.Membership.Role != "user" || len(resp.Membership.Permissions) != 3 {
+		t.Errorf("unexpected organization %q, membership %+v", resp.Session.OrgId, resp.Membership)
+	}
+	if expiry := time.Unix(resp.Session.ExpiresAt, 0); time.Until(expiry) < defaultSessionTTL-time.Minute {
+		t.Errorf("unexpected expiry %v", expiry)
+	}
+
+	_, err = server.CreateSession(ctx, &pb.CreateSessionRequest{UserId: "unknown"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for unknown user, got %v", err)
+	}
+}
+
+func TestRefreshSession_Rotation(t *testing.T) {
+	server := NewUserServer(storage.NewMemoryStorage())
+	ctx := context.Background()
+	user := newSessionUser(t, server, "rotation@example.com")
+
+	created, err := server.CreateSession(ctx, &pb.CreateSessionRequest{UserId: user.Id})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	refreshed, err := server.RefreshSession(ctx, &pb.RefreshSessionRequest{
+		RefreshToken: created.RefreshToken,
+		IpAddress:    "10.0.0.2",
+	})
+	if err != nil {
+		t.Fatalf("RefreshSession failed: %v", err)
+	}
+	if refreshed.RefreshToken == created.RefreshToken {
+		t.Error("refresh token should be rotated")
+	}
+	if refreshed.Session.Id != created.Session.Id {
+		t.Errorf("expected session %s, got %s", created.Session.Id, refreshed.Session.Id)
+	}
+	if refreshed.Session.IpAddress != "10.0.0.2" {
+		t.Errorf("expected IP to be updated, got %s", refreshed.Session.IpAddress)
+	}
+	if refreshed.User.Id != user.Id {
+		t.Errorf("expected user %s, got %s", user.Id, refreshed.User.Id)
+	}
+
+	// The new token keeps working
+	if _, err := server.RefreshSession(ctx, &pb.RefreshSessionRequest{RefreshToken: refreshed.RefreshToken}); err != nil {
+		t.Fatalf("RefreshSession with rotated token failed: %v", err)
+	}
+
+	_, err = server.RefreshSession(ctx, &pb.RefreshSessionRequest{RefreshToken: "unknown"})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated for unknown token, got %v", err)
+	}
+}
+
+// TestRefreshSession_ReuseDetection checks that replaying a rotated token
+// revokes the session, including the tokens issued after it.
+func TestRefreshSession_ReuseDetection(t *testing.T) {
+	server := NewUserServer(storage.NewMemoryStorage())
+	ctx := context.Background()
+	user := newSessionUser(t, server, "reuse@example.com")
+
+	created, err := server.CreateSession(ctx, &pb.CreateSessionRequest{UserId: user.Id})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	refreshed, err := server.RefreshSession(ctx, &pb.RefreshSessionRequest{RefreshToken: created.RefreshToken})
+	if err != nil {
+		t.Fatalf("RefreshSession failed: %v", err)
+	}
+
+	_, err = server.RefreshSession(ctx, &pb.RefreshSessionRequest{RefreshToken: created.RefreshToken})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated on reuse, got %v", err)
+	}
+	_, err = server.RefreshSession(ctx, &pb.RefreshSessionRequest{RefreshToken: refreshed.RefreshToken})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected the whole session to be revoked, got %v", err)
+	}
+
+	sessions, err := server.ListSessions(ctx, &pb.ListSessionsRequest{UserId: user.Id})
+	if err != nil {
+		t.Fatalf("ListSessions failed: %v", err)
+	}
+	if len(sessions.Sessions) != 0 {
+		t.Errorf("expected no active session, got %d", len(sessions.Sessions))
+	}
+}
+
+func TestRevokeSession(t *testing.T) {
+	server := NewUserServer(storage.NewMemoryStorage())
+	ctx := context.Background()
+	user := newSessionUser(t, server, "revoke@example.com")
+	other := newSessionUser(t, server, "other@example.com")
+
+	created, err := server.CreateSession(ctx, &pb.CreateSessionRequest{UserId: user.Id})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	_, err = server.RevokeSession(ctx, &pb.RevokeSessionRequest{Id: created.Session.Id, UserId: other.Id})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound revoking another user's session, got %v", err)
+	}
+
+	if _, err := server.RevokeSession(ctx, &pb.RevokeSessionRequest{Id: created.Session.Id, UserId: user.Id}); err != nil {
+		t.Fatalf("RevokeSession failed: %v", err)
+	}
+	_, err = server.RefreshSession(ctx, &pb.RefreshSessionRequest{RefreshToken: created.RefreshToken})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated after logout, got %v", err)
+	}
+}
+
+func TestRevokeUserSessions(t *testing.T) {
+	server := NewUserServer(storage.NewMemoryStorage())
+	ctx := context.Background()
+	user := newSessionUser(t, server, "all@example.com")
+
+	var tokens []string
+	for _, device := range []string{"Laptop", "Phone"} {
+		created, err := server.CreateSession(ctx, &pb.CreateSessionRequest{UserId: user.Id, Device: device})
+		if err != nil {
+			t.Fatalf("CreateSession failed: %v", err)
+		}
+		tokens = append(tokens, created.RefreshToken)
+	}
+
+	sessions, err := server.ListSessions(ctx, &pb.ListSessionsRequest{UserId: user.Id})
+	if err != nil {
+		t.Fatalf("ListSessions failed: %v", err)
+	}
+	if len(sessions.Sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(sessions.Sessions))
+	}
+
+	resp, err := server.RevokeUserSessions(ctx, &pb.RevokeUserSessionsRequest{UserId: user.Id})
+	if err != nil {
+		t.Fatalf("RevokeUserSessions failed: %v", err)
+	}
+	if resp.Revoked != 2 {
+		t.Errorf("expected 2 revoked sessions, got %d", resp.Revoked)
+	}
+	for _, token := range tokens {
+		_, err := server.RefreshSession(ctx, &pb.RefreshSessionRequest{RefreshToken: token})
+		if status.Code(err) != codes.Unauthenticated {
+			t.Errorf("expected Unauthenticated after revocation, got %v", err)
+		}
+	}
+}
+
+// TestRevokeUserSessions_Organization checks that an organization only
+// revokes the sessions signed in to it
+func TestRevokeUserSessions_Organization(t *testing.T) {
+	server := NewUserServer(storage.NewMemoryStorage())
+	ctx := context.Background()
+	user := newSessionUser(t, server, "two-orgs@example.com")
+	acme, err := server.CreateOrganization(ctx, &pb.CreateOrganizationRequest{Name: "Acme", Slug: "acme", OwnerId: user.Id})
+	if err != nil {
+		t.Fatalf("CreateOrganization failed: %v", err)
+	}
+
+	tokens := make(map[string]string)
+	for _, orgID := range []string{storage.DefaultOrganizationID, acme.Organization.Id} {
+		created, err := server.CreateSession(ctx, &pb.CreateSessionRequest{UserId: user.Id, OrgId: orgID})
+		if err != nil {
+			t.Fatalf("CreateSession failed: %v", err)
+		}
+		tokens[orgID] = created.RefreshToken
+	}
+
+	resp, err := server.RevokeUserSessions(ctx, &pb.RevokeUserSessionsRequest{UserId: user.Id, OrgId: acme.Organization.Id})
+	if err != nil {
+		t.Fatalf("RevokeUserSessions failed: %v", err)
+	}
+	if resp.Revoked != 1 {
+		t.Errorf("expected 1 revoked session, got %d", resp.Revoked)
+	}
+	if _, err := server.RefreshSession(ctx, &pb.RefreshSessionRequest{RefreshToken: tokens[acme.Organization.Id]}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated in the organization, got %v", err)
+	}
+	if _, err := server.RefreshSession(ctx, &pb.RefreshSessionRequest{RefreshToken: tokens[storage.DefaultOrganizationID]}); err != nil {
+		t.Errorf("session of the other organization revoked: %v", err)
+	}
+}
+
+func TestUpdateUser_DeactivationRevokesSessions(t *testing.T) {
+	server := NewUserServer(storage.NewMemoryStorage())
+	ctx := context.Background()
+	user := newSessionUser(t, server, "deactivated@example.com")
+
+	created, err := server.CreateSession(ctx, &pb.CreateSessionRequest{UserId: user.Id})
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	_, err = server.UpdateUser(ctx, &pb.UpdateUserRequest{Id: user.Id, Name: user.Name, Role: user.Role, IsActive: false})
+	if err != nil {
+		t.Fatalf("UpdateUser failed: %v", err)
+	}
+
+	_, err = server.RefreshSession(ctx, &pb.RefreshSessionRequest{RefreshToken: created.RefreshToken})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated for deactivated user, got %v", err)
+	}
+	_, err = server.CreateSession(ctx, &pb.CreateSessionRequest{UserId: user.Id})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected PermissionDenied for deactivated user, got %v", err)
+	}
+}
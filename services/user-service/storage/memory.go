@@ -4,12 +4,14 @@ package storage
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	pb "github.com/yourusername/iot-platform/shared/proto/user"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 // MemoryStorage implements Storage interface using an in-memory map.
@@ -18,16 +20,45 @@ type MemoryStorage struct {
 	users         map[string]*pb.User
 	passwordHash  map[string]string // email -> password hash
 	emailToID     map[string]string // email -> user ID
+	sessions      map[string]*pb.Session
+	refreshTokens map[string]*memoryRefreshToken // token hash -> token
+	roles         map[string]*pb.Role
+	organizations []*pb.Organization // creation order
+	members       []*pb.Membership   // creation order
+	apiKeys       map[string]*pb.ApiKey
+	apiKeyHashes  map[string]string // key hash -> key ID
 	mu            sync.RWMutex
 }
 
+type memoryRefreshToken struct {
+	sessionID string
+	used      bool
+}
+
 // NewMemoryStorage creates a new in-memory storage instance.
 func NewMemoryStorage() *MemoryStorage {
-	return &MemoryStorage{
-		users:        make(map[string]*pb.User),
-		passwordHash: make(map[string]string),
-		emailToID:    make(map[string]string),
+	m := &MemoryStorage{
+		users:         make(map[string]*pb.User),
+		passwordHash:  make(map[string]string),
+		emailToID:     make(map[string]string),
+		sessions:      make(map[string]*pb.Session),
+		refreshTokens: make(map[string]*memoryRefreshToken),
+		roles:         make(map[string]*pb.Role),
+		apiKeys:       make(map[string]*pb.ApiKey),
+		apiKeyHashes:  make(map[string]string),
 	}
+	now := time.Now().Unix()
+	for _, role := range BuiltInRoles() {
+		role.CreatedAt, role.UpdatedAt = now, now
+		m.roles[role.Name] = role
+	}
+	m.organizations = append(m.organizations, &pb.Organization{
+		Id:        DefaultOrganizationID,
+		Name:      "Default",
+		Slug:      "default",
+		CreatedAt: now,
+	})
+	return m
 }
 
 // CreateUser stores a new user in memory.
@@ -122,6 +153,42 @@ func (m *MemoryStorage) ListUsers(ctx context.Context, page, pageSize int32, rol
 	return allUsers[start:end], total, nil
 }
 
+// ListMembers returns a paginated list of the members of an organization.
+func (m *MemoryStorage) ListMembers(ctx context.Context, orgID string, page, pageSize int32, role string) ([]*pb.User, int32, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 20
+	}
+
+	users := []*pb.User{}
+	for _, member := range m.members {
+		if member.OrgId != orgID || (role != "" && member.Role != role) {
+			continue
+		}
+		user := proto.Clone(m.users[member.UserId]).(*pb.User)
+		user.OrgRole = member.Role
+		users = append(users, user)
+	}
+
+	total := int32(len(users))
+	start := (page - 1) * pageSize
+	end := start + pageSize
+
+	if start >= total {
+		return []*pb.User{}, total, nil
+	}
+	if end > total {
+		end = total
+	}
+
+	return users[start:end], total, nil
+}
+
 // UpdateUser updates an existing user.
 func (m *MemoryStorage) UpdateUser(ctx context.Context, user *pb.User) (*pb.User, error) {
 	m.mu.Lock()
@@ -173,6 +240,449 @@ func (m *MemoryStorage) DeleteUser(ctx context.Context, id string) error {
 	delete(m.passwordHash, user.Email)
 	delete(m.emailToID, user.Email)
 
+	// Sessions are deleted with their user
+	for hash, token := range m.refreshTokens {
+		if m.sessions[token.sessionID].UserId == id {
+			delete(m.refreshTokens, hash)
+		}
+	}
+	for sessionID, session := range m.sessions {
+		if session.UserId == id {
+			delete(m.sessions, sessionID)
+		}
+	}
+	members := m.members[:0]
+	for _, member := range m.members {
+		if member.UserId != id {
+			members = append(members, member)
+		}
+	}
+	m.members = members
+	for hash, keyID := range m.apiKeyHashes {
+		if m.apiKeys[keyID].UserId == id {
+			delete(m.apiKeys, keyID)
+			delete(m.apiKeyHashes, hash)
+		}
+	}
+
+	return nil
+}
+
+// CreateSession stores a new session with its first refresh token.
+func (m *MemoryStorage) CreateSession(ctx context.Context, session *pb.Session, tokenHash string) (*pb.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.users[session.UserId]; !exists {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+
+	m.sessions[session.Id] = proto.Clone(session).(*pb.Session)
+	m.refreshTokens[tokenHash] = &memoryRefreshToken{sessionID: session.Id}
+	return session, nil
+}
+
+// GetSession retrieves a session by ID.
+func (m *MemoryStorage) GetSession(ctx context.Context, id string) (*pb.Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	session, exists := m.sessions[id]
+	if !exists {
+		return nil, status.Error(codes.NotFound, "session not found")
+	}
+	return proto.Clone(session).(*pb.Session), nil
+}
+
+// RotateRefreshToken replaces a refresh token with its successor.
+func (m *MemoryStorage) RotateRefreshToken(ctx context.Context, tokenHash, newHash string, use SessionUse) (*pb.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	token, exists := m.refreshTokens[tokenHash]
+	if !exists {
+		return nil, status.Error(codes.NotFound, "refresh token not found")
+	}
+	session := m.sessions[token.sessionID]
+	if token.used {
+		return proto.Clone(session).(*pb.Session), ErrRefreshTokenReused
+	}
+	if session.RevokedAt != 0 || session.ExpiresAt <= use.At.Unix() {
+		return nil, ErrSessionInactive
+	}
+
+	token.used = true
+	m.refreshTokens[newHash] = &memoryRefreshToken{sessionID: session.Id}
+	session.LastUsedAt = use.At.Unix()
+	session.ExpiresAt = use.ExpiresAt.Unix()
+	session.IpAddress = use.IPAddress
+	session.UserAgent = use.UserAgent
+	return proto.Clone(session).(*pb.Session), nil
+}
+
+// ListSessions returns the active sessions of a user.
+func (m *MemoryStorage) ListSessions(ctx context.Context, userID string) ([]*pb.Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now().Unix()
+	sessions := []*pb.Session{}
+	for _, session := range m.sessions {
+		if session.UserId == userID && session.RevokedAt == 0 && session.ExpiresAt > now {
+			sessions = append(sessions, proto.Clone(session).(*pb.Session))
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastUsedAt > sessions[j].LastUsedAt })
+	return sessions, nil
+}
+
+// RevokeSession revokes a session.
+func (m *MemoryStorage) RevokeSession(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, exists := m.sessions[id]
+	if !exists {
+		return status.Error(codes.NotFound, "session not found")
+	}
+	if session.RevokedAt == 0 {
+		session.RevokedAt = time.Now().Unix()
+	}
+	return nil
+}
+
+// RevokeUserSessions revokes the active sessions of a user in an
+// organization, or in all of them if orgID is empty.
+func (m *MemoryStorage) RevokeUserSessions(ctx context.Context, userID, orgID string) (int32, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now().Unix()
+	var revoked int32
+	for _, session := range m.sessions {
+		if session.UserId == userID && session.RevokedAt == 0 && (orgID == "" || session.OrgId == orgID) {
+			session.RevokedAt = now
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+// SetSessionOrganization changes the organization of a session.
+func (m *MemoryStorage) SetSessionOrganization(ctx context.Context, sessionID, orgID string) (*pb.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, exists := m.sessions[sessionID]
+	if !exists {
+		return nil, status.Error(codes.NotFound, "session not found")
+	}
+	session.OrgId = orgID
+	return proto.Clone(session).(*pb.Session), nil
+}
+
+// ListRoles returns the roles, sorted by name.
+func (m *MemoryStorage) ListRoles(ctx context.Context) ([]*pb.Role, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	roles := make([]*pb.Role, 0, len(m.roles))
+	for _, role := range m.roles {
+		roles = append(roles, proto.Clone(role).(*pb.Role))
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].Name < roles[j].Name })
+	return roles, nil
+}
+
+// GetRole retrieves a role by name.
+func (m *MemoryStorage) GetRole(ctx context.Context, name string) (*pb.Role, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	role, exists := m.roles[name]
+	if !exists {
+		return nil, status.Errorf(codes.NotFound, "role %s not found", name)
+	}
+	return proto.Clone(role).(*pb.Role), nil
+}
+
+// UpsertRole creates or updates a custom role.
+func (m *MemoryStorage) UpsertRole(ctx context.Context, role *pb.Role) (*pb.Role, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := &pb.Role{
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: append([]string{}, role.Permissions...),
+		CreatedAt:   role.UpdatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+	if existing, exists := m.roles[role.Name]; exists {
+		if existing.BuiltIn {
+			return nil, status.Errorf(codes.FailedPrecondition, "built-in role %s cannot be changed", role.Name)
+		}
+		stored.CreatedAt = existing.CreatedAt
+	}
+
+	m.roles[role.Name] = stored
+	return proto.Clone(stored).(*pb.Role), nil
+}
+
+// DeleteRole removes a custom role that is not assigned to any user.
+func (m *MemoryStorage) DeleteRole(ctx context.Context, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	role, exists := m.roles[name]
+	if !exists {
+		return status.Errorf(codes.NotFound, "role %s not found", name)
+	}
+	if role.BuiltIn {
+		return status.Errorf(codes.FailedPrecondition, "built-in role %s cannot be deleted", name)
+	}
+	for _, user := range m.users {
+		if user.Role == name {
+			return status.Errorf(codes.FailedPrecondition, "role %s is assigned to users", name)
+		}
+	}
+	for _, member := range m.members {
+		if member.Role == name {
+			return status.Errorf(codes.FailedPrecondition, "role %s is assigned to organization members", name)
+		}
+	}
+
+	delete(m.roles, name)
+	return nil
+}
+
+// CreateOrganization stores a new organization.
+func (m *MemoryStorage) CreateOrganization(ctx context.Context, org *pb.Organization) (*pb.Organization, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.organizations {
+		if existing.Slug == org.Slug {
+			return nil, status.Errorf(codes.AlreadyExists, "organization %s already exists", org.Slug)
+		}
+	}
+
+	stored := proto.Clone(org).(*pb.Organization)
+	stored.Role = ""
+	m.organizations = append(m.organizations, stored)
+	return proto.Clone(stored).(*pb.Organization), nil
+}
+
+// GetOrganization retrieves an organization by ID.
+func (m *MemoryStorage) GetOrganization(ctx context.Context, id string) (*pb.Organization, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	org := m.organization(id)
+	if org == nil {
+		return nil, status.Errorf(codes.NotFound, "organization %s not found", id)
+	}
+	return proto.Clone(org).(*pb.Organization), nil
+}
+
+// organization returns the organization id, nil if missing. Callers hold the lock.
+func (m *MemoryStorage) organization(id string) *pb.Organization {
+	for _, org := range m.organizations {
+		if org.Id == id {
+			return org
+		}
+	}
+	return nil
+}
+
+// ListOrganizations returns the organizations, oldest first.
+func (m *MemoryStorage) ListOrganizations(ctx context.Context) ([]*pb.Organization, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	orgs := make([]*pb.Organization, len(m.organizations))
+	for i, org := range m.organizations {
+		orgs[i] = proto.Clone(org).(*pb.Organization)
+	}
+	return orgs, nil
+}
+
+// ListUserOrganizations returns the organizations of a user, oldest membership first.
+func (m *MemoryStorage) ListUserOrganizations(ctx context.Context, userID string) ([]*pb.Organization, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	orgs := []*pb.Organization{}
+	for _, member := range m.members {
+		if member.UserId == userID {
+			org := proto.Clone(m.organization(member.OrgId)).(*pb.Organization)
+			org.Role = member.Role
+			orgs = append(orgs, org)
+		}
+	}
+	return orgs, nil
+}
+
+// AddMember adds a user to an organization.
+func (m *MemoryStorage) AddMember(ctx context.Context, member *pb.Membership) (*pb.Membership, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.organization(member.OrgId) == nil {
+		return nil, status.Errorf(codes.NotFound, "organization %s not found", member.OrgId)
+	}
+	if _, exists := m.users[member.UserId]; !exists {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	if m.member(member.OrgId, member.UserId) != nil {
+		return nil, status.Errorf(codes.AlreadyExists, "user %s is already a member of organization %s", member.UserId, member.OrgId)
+	}
+
+	stored := &pb.Membership{
+		OrgId:     member.OrgId,
+		UserId:    member.UserId,
+		Role:      member.Role,
+		CreatedAt: member.CreatedAt,
+	}
+	m.members = append(m.members, stored)
+	return proto.Clone(stored).(*pb.Membership), nil
+}
+
+// member returns the membership of a user, nil if missing. Callers hold the lock.
+func (m *MemoryStorage) member(orgID, userID string) *pb.Membership {
+	for _, member := range m.members {
+		if member.OrgId == orgID && member.UserId == userID {
+			return member
+		}
+	}
+	return nil
+}
+
+// GetMembership retrieves the membership of a user in an organization.
+func (m *MemoryStorage) GetMembership(ctx context.Context, orgID, userID string) (*pb.Membership, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	member := m.member(orgID, userID)
+	if member == nil {
+		return nil, status.Errorf(codes.NotFound, "user %s is not a member of organization %s", userID, orgID)
+	}
+	return proto.Clone(member).(*pb.Membership), nil
+}
+
+// UpdateMember changes the role of a member.
+func (m *MemoryStorage) UpdateMember(ctx context.Context, member *pb.Membership) (*pb.Membership, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing := m.member(member.OrgId, member.UserId)
+	if existing == nil {
+		return nil, status.Errorf(codes.NotFound, "user %s is not a member of organization %s", member.UserId, member.OrgId)
+	}
+	existing.Role = member.Role
+	return proto.Clone(existing).(*pb.Membership), nil
+}
+
+// RemoveMember removes a user from an organization.
+func (m *MemoryStorage) RemoveMember(ctx context.Context, orgID, userID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, member := range m.members {
+		if member.OrgId == orgID && member.UserId == userID {
+			m.members = append(m.members[:i], m.members[i+1:]...)
+			return nil
+		}
+	}
+	return status.Errorf(codes.NotFound, "user %s is not a member of organization %s", userID, orgID)
+}
+
+// CreateAPIKey stores a new API key with the hash of its secret.
+func (m *MemoryStorage) CreateAPIKey(ctx context.Context, key *pb.ApiKey, keyHash string) (*pb.ApiKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.users[key.UserId]; !exists {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	if _, exists := m.apiKeyHashes[keyHash]; exists {
+		return nil, status.Error(codes.AlreadyExists, "API key already exists")
+	}
+
+	stored := proto.Clone(key).(*pb.ApiKey)
+	if stored.Permissions == nil {
+		stored.Permissions = []string{}
+	}
+	m.apiKeys[key.Id] = stored
+	m.apiKeyHashes[keyHash] = key.Id
+	return proto.Clone(stored).(*pb.ApiKey), nil
+}
+
+// GetAPIKey retrieves an API key by ID.
+func (m *MemoryStorage) GetAPIKey(ctx context.Context, id string) (*pb.ApiKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	key, exists := m.apiKeys[id]
+	if !exists {
+		return nil, status.Error(codes.NotFound, "API key not found")
+	}
+	return proto.Clone(key).(*pb.ApiKey), nil
+}
+
+// GetAPIKeyByHash retrieves an API key by the hash of its secret.
+func (m *MemoryStorage) GetAPIKeyByHash(ctx context.Context, keyHash string) (*pb.ApiKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	id, exists := m.apiKeyHashes[keyHash]
+	if !exists {
+		return nil, status.Error(codes.NotFound, "API key not found")
+	}
+	return proto.Clone(m.apiKeys[id]).(*pb.ApiKey), nil
+}
+
+// ListAPIKeys returns the keys that are not revoked, newest first.
+func (m *MemoryStorage) ListAPIKeys(ctx context.Context, userID, orgID string) ([]*pb.ApiKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := []*pb.ApiKey{}
+	for _, key := range m.apiKeys {
+		if key.RevokedAt != 0 || (userID != "" && key.UserId != userID) || (orgID != "" && key.OrgId != orgID) {
+			continue
+		}
+		keys = append(keys, proto.Clone(key).(*pb.ApiKey))
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt > keys[j].CreatedAt })
+	return keys, nil
+}
+
+// RevokeAPIKey revokes an API key.
+func (m *MemoryStorage) RevokeAPIKey(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key, exists := m.apiKeys[id]
+	if !exists {
+		return status.Error(codes.NotFound, "API key not found")
+	}
+	if key.RevokedAt == 0 {
+		key.RevokedAt = time.Now().Unix()
+	}
+	return nil
+}
+
+// TouchAPIKey records the use of an API key.
+func (m *MemoryStorage) TouchAPIKey(ctx context.Context, id string, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key, exists := m.apiKeys[id]
+	if !exists {
+		return status.Error(codes.NotFound, "API key not found")
+	}
+	key.LastUsedAt = at.Unix()
 	return nil
 }
 
@@ -4,10 +4,14 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	userpb "github.com/yourusername/iot-platform/shared/proto/user"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestMemoryStorage_CreateUser(t *testing.T) {
@@ -379,3 +383,239 @@ func TestMemoryStorage_ListUsers(t *testing.T) {
 		t.Errorf("Total with filter = %d, want 2", total)
 	}
 }
+
+func TestMemoryStorage_RotateRefreshToken(t *testing.T) {
+	ctx := context.Background()
+	storage := NewMemoryStorage()
+
+	_, err := storage.CreateUser(ctx, &userpb.User{Id: "user-123", Email: "test@example.com", IsActive: true}, "hashed-password")
+	if err != nil {
+		t.Fatalf("CreateUser() failed: %v", err)
+	}
+	now := time.Now()
+	_, err = storage.CreateSession(ctx, &userpb.Session{
+		Id:        "session-1",
+		UserId:    "user-123",
+		ExpiresAt: now.Add(time.Hour).Unix(),
+	}, "hash-1")
+	if err != nil {
+		t.Fatalf("CreateSession() failed: %v", err)
+	}
+
+	use := SessionUse{At: now, ExpiresAt: now.Add(2 * time.Hour), IPAddress: "10.0.0.1"}
+	session, err := storage.RotateRefreshToken(ctx, "hash-1", "hash-2", use)
+	if err != nil {
+		t.Fatalf("RotateRefreshToken() failed: %v", err)
+	}
+	if session.ExpiresAt != use.ExpiresAt.Unix() || session.IpAddress != "10.0.0.1" {
+		t.Errorf("session not touched: %+v", session)
+	}
+
+	// Replaying the first token is reported with its session
+	session, err = storage.RotateRefreshToken(ctx, "hash-1", "hash-3", use)
+	if !errors.Is(err, ErrRefreshTokenReused) || session == nil || session.Id != "session-1" {
+		t.Errorf("RotateRefreshToken() = %v, %v, want session-1, ErrRefreshTokenReused", session, err)
+	}
+
+	if _, err := storage.RotateRefreshToken(ctx, "unknown", "hash-4", use); status.Code(err) != codes.NotFound {
+		t.Errorf("RotateRefreshToken() error = %v, want NotFound", err)
+	}
+
+	// Revoked sessions keep their token unused
+	if err := storage.RevokeSession(ctx, "session-1"); err != nil {
+		t.Fatalf("RevokeSession() failed: %v", err)
+	}
+	if _, err := storage.RotateRefreshToken(ctx, "hash-2", "hash-5", use); !errors.Is(err, ErrSessionInactive) {
+		t.Errorf("RotateRefreshToken() error = %v, want ErrSessionInactive", err)
+	}
+	if _, err := storage.RotateRefreshToken(ctx, "hash-2", "hash-5", use); !errors.Is(err, ErrSessionInactive) {
+		t.Errorf("RotateRefreshToken() error = %v, want ErrSessionInactive on retry", err)
+	}
+}
+
+func TestMemoryStorage_DeleteUser_Sessions(t *testing.T) {
+	ctx := context.Background()
+	storage := NewMemoryStorage()
+
+	_, err := storage.CreateUser(ctx, &userpb.User{Id: "user-123", Email: "test@example.com", IsActive: true}, "hashed-password")
+	if err != nil {
+		t.Fatalf("CreateUser() failed: %v", err)
+	}
+	_, err = storage.CreateSession(ctx, &userpb.Session{Id: "session-1", UserId: "user-123", ExpiresAt: time.Now().Add(time.Hour).Unix()}, "hash-1")
+	if err != nil {
+		t.Fatalf("CreateSession() failed: %v", err)
+	}
+
+	if err := storage.DeleteUser(ctx, "user-123"); err != nil {
+		t.Fatalf("DeleteUser() failed: %v", err)
+	}
+	if _, err := storage.GetSession(ctx, "session-1"); status.Code(err) != codes.NotFound {
+		t.Errorf("GetSession() error = %v, want NotFound", err)
+	}
+	if _, err := storage.RotateRefreshToken(ctx, "hash-1", "hash-2", SessionUse{At: time.Now()}); status.Code(err) != codes.NotFound {
+		t.Errorf("RotateRefreshToken() error = %v, want NotFound", err)
+	}
+}
+
+func TestMemoryStorage_Roles(t *testing.T) {
+	ctx := context.Background()
+	storage := NewMemoryStorage()
+
+	roles, err := storage.ListRoles(ctx)
+	if err != nil {
+		t.Fatalf("ListRoles() failed: %v", err)
+	}
+	if len(roles) != 3 || roles[0].Name != "admin" || !roles[0].BuiltIn {
+		t.Fatalf("expected the built-in roles, got %+v", roles)
+	}
+
+	role, err := storage.UpsertRole(ctx, &userpb.Role{Name: "viewer", Permissions: []string{"devices:read"}, UpdatedAt: 1000})
+	if err != nil {
+		t.Fatalf("UpsertRole() failed: %v", err)
+	}
+	role.Permissions[0] = "devices:write"
+	stored, err := storage.GetRole(ctx, "viewer")
+	if err != nil {
+		t.Fatalf("GetRole() failed: %v", err)
+	}
+	if stored.Permissions[0] != "devices:read" || stored.CreatedAt != 1000 {
+		t.Errorf("unexpected stored role %+v", stored)
+	}
+
+	if _, err := storage.UpsertRole(ctx, &userpb.Role{Name: "admin", UpdatedAt: 1000}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("UpsertRole() error = %v, want FailedPrecondition", err)
+	}
+
+	_, err = storage.CreateUser(ctx, &userpb.User{Id: "user-123", Email: "test@example.com", Role: "viewer", IsActive: true}, "hashed-password")
+	if err != nil {
+		t.Fatalf("CreateUser() failed: %v", err)
+	}
+	if err := storage.DeleteRole(ctx, "viewer"); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("DeleteRole() error = %v, want FailedPrecondition", err)
+	}
+	if err := storage.DeleteUser(ctx, "user-123"); err != nil {
+		t.Fatalf("DeleteUser() failed: %v", err)
+	}
+	if err := storage.DeleteRole(ctx, "viewer"); err != nil {
+		t.Errorf("DeleteRole() failed: %v", err)
+	}
+	if _, err := storage.GetRole(ctx, "viewer"); status.Code(err) != codes.NotFound {
+		t.Errorf("GetRole() error = %v, want NotFound", err)
+	}
+}
+
+func TestMemoryStorage_Organizations(t *testing.T) {
+	ctx := context.Background()
+	storage := NewMemoryStorage()
+
+	orgs, err := storage.ListOrganizations(ctx)
+	if err != nil {
+		t.Fatalf("ListOrganizations() failed: %v", err)
+	}
+	if len(orgs) != 1 || orgs[0].Id != DefaultOrganizationID {
+		t.Fatalf("expected the default organization, got %+v", orgs)
+	}
+
+	if _, err := storage.CreateOrganization(ctx, &userpb.Organization{Id: "org-1", Name: "Acme", Slug: "acme"}); err != nil {
+		t.Fatalf("CreateOrganization() failed: %v", err)
+	}
+	if _, err := storage.CreateOrganization(ctx, &userpb.Organization{Id: "org-2", Name: "Acme 2", Slug: "acme"}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("CreateOrganization() error = %v, want AlreadyExists", err)
+	}
+
+	_, err = storage.CreateUser(ctx, &userpb.User{Id: "user-123", Email: "test@example.com", Role: "user", IsActive: true}, "hashed-password")
+	if err != nil {
+		t.Fatalf("CreateUser() failed: %v", err)
+	}
+	for _, orgID := range []string{"org-1", DefaultOrganizationID} {
+		if _, err := storage.AddMember(ctx, &userpb.Membership{OrgId: orgID, UserId: "user-123", Role: "user"}); err != nil {
+			t.Fatalf("AddMember() failed: %v", err)
+		}
+	}
+	if _, err := storage.AddMember(ctx, &userpb.Membership{OrgId: "org-1", UserId: "user-123", Role: "admin"}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("AddMember() error = %v, want AlreadyExists", err)
+	}
+	if _, err := storage.AddMember(ctx, &userpb.Membership{OrgId: "missing", UserId: "user-123", Role: "user"}); status.Code(err) != codes.NotFound {
+		t.Errorf("AddMember() error = %v, want NotFound", err)
+	}
+
+	// Oldest membership first
+	orgs, err = storage.ListUserOrganizations(ctx, "user-123")
+	if err != nil {
+		t.Fatalf("ListUserOrganizations() failed: %v", err)
+	}
+	if len(orgs) != 2 || orgs[0].Id != "org-1" || orgs[0].Role != "user" {
+		t.Errorf("unexpected organizations %+v", orgs)
+	}
+
+	if _, err := storage.UpdateMember(ctx, &userpb.Membership{OrgId: "org-1", UserId: "user-123", Role: "admin"}); err != nil {
+		t.Fatalf("UpdateMember() failed: %v", err)
+	}
+	users, total, err := storage.ListMembers(ctx, "org-1", 1, 10, "admin")
+	if err != nil {
+		t.Fatalf("ListMembers() failed: %v", err)
+	}
+	if total != 1 || users[0].OrgRole != "admin" || users[0].Role != "user" {
+		t.Errorf("unexpected members %+v (total %d)", users, total)
+	}
+
+	if err := storage.RemoveMember(ctx, "org-1", "user-123"); err != nil {
+		t.Fatalf("RemoveMember() failed: %v", err)
+	}
+	if _, err := storage.GetMembership(ctx, "org-1", "user-123"); status.Code(err) != codes.NotFound {
+		t.Errorf("GetMembership() error = %v, want NotFound", err)
+	}
+	if err := storage.RemoveMember(ctx, "org-1", "user-123"); status.Code(err) != codes.NotFound {
+		t.Errorf("RemoveMember() error = %v, want NotFound", err)
+	}
+
+	// Memberships are deleted with their user
+	if err := storage.DeleteUser(ctx, "user-123"); err != nil {
+		t.Fatalf("DeleteUser() failed: %v", err)
+	}
+	if _, total, _ := storage.ListMembers(ctx, DefaultOrganizationID, 1, 10, ""); total != 0 {
+		t.Errorf("expected no members left, got %d", total)
+	}
+}
+
+func TestMemoryStorage_APIKeys(t *testing.T) {
+	ctx := context.Background()
+	storage := NewMemoryStorage()
+
+	_, err := storage.CreateUser(ctx, &userpb.User{Id: "user-123", Email: "bot@example.com", IsActive: true, ServiceAccount: true}, "")
+	if err != nil {
+		t.Fatalf("CreateUser() failed: %v", err)
+	}
+	key := &userpb.ApiKey{Id: "key-1", UserId: "user-123", OrgId: DefaultOrganizationID, Name: "CI", CreatedAt: time.Now().Unix()}
+	if _, err := storage.CreateAPIKey(ctx, key, "hash-1"); err != nil {
+		t.Fatalf("CreateAPIKey() failed: %v", err)
+	}
+	if _, err := storage.CreateAPIKey(ctx, &userpb.ApiKey{Id: "key-2", UserId: "user-123"}, "hash-1"); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("CreateAPIKey() with a used hash error = %v, want AlreadyExists", err)
+	}
+
+	found, err := storage.GetAPIKeyByHash(ctx, "hash-1")
+	if err != nil || found.Id != "key-1" {
+		t.Fatalf("GetAPIKeyByHash() = %+v, %v", found, err)
+	}
+	if err := storage.TouchAPIKey(ctx, "key-1", time.Unix(1700000000, 0)); err != nil {
+		t.Fatalf("TouchAPIKey() failed: %v", err)
+	}
+	if found, _ := storage.GetAPIKey(ctx, "key-1"); found.LastUsedAt != 1700000000 {
+		t.Errorf("LastUsedAt = %d, want 1700000000", found.LastUsedAt)
+	}
+
+	if err := storage.RevokeAPIKey(ctx, "key-1"); err != nil {
+		t.Fatalf("RevokeAPIKey() failed: %v", err)
+	}
+	if keys, _ := storage.ListAPIKeys(ctx, "user-123", ""); len(keys) != 0 {
+		t.Errorf("ListAPIKeys() returned revoked keys %+v", keys)
+	}
+
+	if err := storage.DeleteUser(ctx, "user-123"); err != nil {
+		t.Fatalf("DeleteUser() failed: %v", err)
+	}
+	if _, err := storage.GetAPIKeyByHash(ctx, "hash-1"); status.Code(err) != codes.NotFound {
+		t.Errorf("GetAPIKeyByHash() after DeleteUser() error = %v, want NotFound", err)
+	}
+}
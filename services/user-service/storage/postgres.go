@@ -2,17 +2,19 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
-	pb "github.com/yourusername/iot-platform/shared/proto/user"
 	"github.com/yourusername/iot-platform/services/user-service/db/sqlc"
+	pb "github.com/yourusername/iot-platform/shared/proto/user"
 )
 
 // PostgresStorage implements Storage interface using PostgreSQL with pgx.
@@ -57,13 +59,14 @@ func (s *PostgresStorage) CreateUser(ctx context.Context, user *pb.User, passwor
 
 	// Insert user
 	dbUser, err := s.queries.CreateUser(ctx, sqlc.CreateUserParams{
-		ID:           pgUUID,
-		Email:        user.Email,
-		PasswordHash: passwordHash,
-		Name:         user.Name,
-		Role:         user.Role,
-		CreatedAt:    createdAt,
-		IsActive:     user.IsActive,
+		ID:             pgUUID,
+		Email:          user.Email,
+		PasswordHash:   passwordHash,
+		Name:           user.Name,
+		Role:           user.Role,
+		CreatedAt:      createdAt,
+		IsActive:       user.IsActive,
+		ServiceAccount: user.ServiceAccount,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
@@ -237,6 +240,650 @@ func (s *PostgresStorage) ListUsers(ctx context.Context, page, pageSize int32, r
 	return users, int32(total), nil
 }
 
+// CreateSession implements Storage.CreateSession.
+func (s *PostgresStorage) CreateSession(ctx context.Context, session *pb.Session, tokenHash string) (*pb.Session, error) {
+	var id, userID pgtype.UUID
+	if err := id.Scan(session.Id); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid session ID: %v", err)
+	}
+	if err := userID.Scan(session.UserId); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	queries := s.queries.WithTx(tx)
+
+	var orgID pgtype.UUID
+	if session.OrgId != "" {
+		if err := orgID.Scan(session.OrgId); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid organization ID: %v", err)
+		}
+	}
+
+	createdAt := timestamptz(session.CreatedAt)
+	dbSession, err := queries.CreateSession(ctx, sqlc.CreateSessionParams{
+		ID:         id,
+		UserID:     userID,
+		Device:     session.Device,
+		IpAddress:  session.IpAddress,
+		UserAgent:  session.UserAgent,
+		CreatedAt:  createdAt,
+		LastUsedAt: timestamptz(session.LastUsedAt),
+		ExpiresAt:  timestamptz(session.ExpiresAt),
+		OrgID:      orgID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	err = queries.InsertRefreshToken(ctx, sqlc.InsertRefreshTokenParams{
+		TokenHash: tokenHash,
+		SessionID: id,
+		CreatedAt: createdAt,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert refresh token: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit session: %w", err)
+	}
+	return dbSessionToProto(dbSession), nil
+}
+
+// GetSession implements Storage.GetSession.
+func (s *PostgresStorage) GetSession(ctx context.Context, id string) (*pb.Session, error) {
+	var pgUUID pgtype.UUID
+	if err := pgUUID.Scan(id); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid session ID: %v", err)
+	}
+
+	dbSession, err := s.queries.GetSession(ctx, pgUUID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "session %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return dbSessionToProto(dbSession), nil
+}
+
+// RotateRefreshToken implements Storage.RotateRefreshToken.
+func (s *PostgresStorage) RotateRefreshToken(ctx context.Context, tokenHash, newHash string, use SessionUse) (*pb.Session, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	queries := s.queries.WithTx(tx)
+
+	now := pgtype.Timestamptz{Time: use.At, Valid: true}
+	sessionID, err := queries.UseRefreshToken(ctx, sqlc.UseRefreshTokenParams{
+		TokenHash: tokenHash,
+		UsedAt:    now,
+	})
+	if err == pgx.ErrNoRows {
+		// Either unknown or already used
+		token, err := queries.GetRefreshToken(ctx, tokenHash)
+		if err == pgx.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "refresh token not found")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get refresh token: %w", err)
+		}
+		dbSession, err := queries.GetSession(ctx, token.SessionID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get session: %w", err)
+		}
+		return dbSessionToProto(dbSession), ErrRefreshTokenReused
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to use refresh token: %w", err)
+	}
+
+	dbSession, err := queries.TouchSession(ctx, sqlc.TouchSessionParams{
+		LastUsedAt: now,
+		ExpiresAt:  pgtype.Timestamptz{Time: use.ExpiresAt, Valid: true},
+		IpAddress:  use.IPAddress,
+		UserAgent:  use.UserAgent,
+		ID:         sessionID,
+	})
+	if err == pgx.ErrNoRows {
+		// Rolled back: the token stays unused
+		return nil, ErrSessionInactive
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to touch session: %w", err)
+	}
+	err = queries.InsertRefreshToken(ctx, sqlc.InsertRefreshTokenParams{
+		TokenHash: newHash,
+		SessionID: sessionID,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert refresh token: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit refresh token: %w", err)
+	}
+	return dbSessionToProto(dbSession), nil
+}
+
+// ListSessions implements Storage.ListSessions.
+func (s *PostgresStorage) ListSessions(ctx context.Context, userID string) ([]*pb.Session, error) {
+	var pgUUID pgtype.UUID
+	if err := pgUUID.Scan(userID); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
+	}
+
+	dbSessions, err := s.queries.ListActiveSessions(ctx, sqlc.ListActiveSessionsParams{
+		UserID:    pgUUID,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	sessions := make([]*pb.Session, len(dbSessions))
+	for i, dbSession := range dbSessions {
+		sessions[i] = dbSessionToProto(dbSession)
+	}
+	return sessions, nil
+}
+
+// RevokeSession implements Storage.RevokeSession.
+func (s *PostgresStorage) RevokeSession(ctx context.Context, id string) error {
+	var pgUUID pgtype.UUID
+	if err := pgUUID.Scan(id); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid session ID: %v", err)
+	}
+
+	rows, err := s.queries.RevokeSession(ctx, sqlc.RevokeSessionParams{
+		ID:        pgUUID,
+		RevokedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if rows == 0 {
+		// Already revoked, or missing
+		_, err := s.GetSession(ctx, id)
+		return err
+	}
+
+	return nil
+}
+
+// RevokeUserSessions implements Storage.RevokeUserSessions.
+func (s *PostgresStorage) RevokeUserSessions(ctx context.Context, userID, orgID string) (int32, error) {
+	params := sqlc.RevokeUserSessionsParams{RevokedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true}}
+	if err := params.UserID.Scan(userID); err != nil {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
+	}
+	if orgID != "" {
+		if err := params.OrgID.Scan(orgID); err != nil {
+			return 0, status.Errorf(codes.InvalidArgument, "invalid organization ID: %v", err)
+		}
+	}
+
+	rows, err := s.queries.RevokeUserSessions(ctx, params)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return int32(rows), nil
+}
+
+// SetSessionOrganization implements Storage.SetSessionOrganization.
+func (s *PostgresStorage) SetSessionOrganization(ctx context.Context, sessionID, orgID string) (*pb.Session, error) {
+	var id, org pgtype.UUID
+	if err := id.Scan(sessionID); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid session ID: %v", err)
+	}
+	if err := org.Scan(orgID); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid organization ID: %v", err)
+	}
+
+	dbSession, err := s.queries.SetSessionOrganization(ctx, sqlc.SetSessionOrganizationParams{ID: id, OrgID: org})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "session %s not found", sessionID)
+		}
+		return nil, fmt.Errorf("failed to set session organization: %w", err)
+	}
+	return dbSessionToProto(dbSession), nil
+}
+
+// ListRoles implements Storage.ListRoles.
+func (s *PostgresStorage) ListRoles(ctx context.Context) ([]*pb.Role, error) {
+	dbRoles, err := s.queries.ListRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	roles := make([]*pb.Role, len(dbRoles))
+	for i, dbRole := range dbRoles {
+		roles[i] = dbRoleToProto(dbRole)
+	}
+	return roles, nil
+}
+
+// GetRole implements Storage.GetRole.
+func (s *PostgresStorage) GetRole(ctx context.Context, name string) (*pb.Role, error) {
+	dbRole, err := s.queries.GetRole(ctx, name)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "role %s not found", name)
+		}
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	return dbRoleToProto(dbRole), nil
+}
+
+// UpsertRole implements Storage.UpsertRole.
+func (s *PostgresStorage) UpsertRole(ctx context.Context, role *pb.Role) (*pb.Role, error) {
+	permissions := role.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	dbRole, err := s.queries.UpsertRole(ctx, sqlc.UpsertRoleParams{
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: permissions,
+		UpdatedAt:   timestamptz(role.UpdatedAt),
+	})
+	if err != nil {
+		// The conflicting row is a built-in role
+		if err == pgx.ErrNoRows {
+			return nil, status.Errorf(codes.FailedPrecondition, "built-in role %s cannot be changed", role.Name)
+		}
+		return nil, fmt.Errorf("failed to upsert role: %w", err)
+	}
+	return dbRoleToProto(dbRole), nil
+}
+
+// DeleteRole implements Storage.DeleteRole.
+func (s *PostgresStorage) DeleteRole(ctx context.Context, name string) error {
+	role, err := s.GetRole(ctx, name)
+	if err != nil {
+		return err
+	}
+	if role.BuiltIn {
+		return status.Errorf(codes.FailedPrecondition, "built-in role %s cannot be deleted", name)
+	}
+
+	// The foreign key of users.role also rejects the deletion, this reports it
+	assigned, err := s.queries.CountUsersByRole(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to count users by role: %w", err)
+	}
+	if assigned > 0 {
+		return status.Errorf(codes.FailedPrecondition, "role %s is assigned to users", name)
+	}
+	members, err := s.queries.CountMembersByRole(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to count members by role: %w", err)
+	}
+	if members > 0 {
+		return status.Errorf(codes.FailedPrecondition, "role %s is assigned to organization members", name)
+	}
+
+	if _, err := s.queries.DeleteRole(ctx, name); err != nil {
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+	return nil
+}
+
+// CreateOrganization implements Storage.CreateOrganization.
+func (s *PostgresStorage) CreateOrganization(ctx context.Context, org *pb.Organization) (*pb.Organization, error) {
+	var id pgtype.UUID
+	if err := id.Scan(org.Id); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid organization ID: %v", err)
+	}
+
+	dbOrg, err := s.queries.CreateOrganization(ctx, sqlc.CreateOrganizationParams{
+		ID:        id,
+		Name:      org.Name,
+		Slug:      org.Slug,
+		CreatedAt: timestamptz(org.CreatedAt),
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, status.Errorf(codes.AlreadyExists, "organization %s already exists", org.Slug)
+		}
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+	return dbOrganizationToProto(dbOrg), nil
+}
+
+// GetOrganization implements Storage.GetOrganization.
+func (s *PostgresStorage) GetOrganization(ctx context.Context, id string) (*pb.Organization, error) {
+	var pgUUID pgtype.UUID
+	if err := pgUUID.Scan(id); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid organization ID: %v", err)
+	}
+
+	dbOrg, err := s.queries.GetOrganization(ctx, pgUUID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "organization %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	return dbOrganizationToProto(dbOrg), nil
+}
+
+// ListOrganizations implements Storage.ListOrganizations.
+func (s *PostgresStorage) ListOrganizations(ctx context.Context) ([]*pb.Organization, error) {
+	dbOrgs, err := s.queries.ListOrganizations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+
+	orgs := make([]*pb.Organization, len(dbOrgs))
+	for i, dbOrg := range dbOrgs {
+		orgs[i] = dbOrganizationToProto(dbOrg)
+	}
+	return orgs, nil
+}
+
+// ListUserOrganizations implements Storage.ListUserOrganizations.
+func (s *PostgresStorage) ListUserOrganizations(ctx context.Context, userID string) ([]*pb.Organization, error) {
+	var pgUUID pgtype.UUID
+	if err := pgUUID.Scan(userID); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
+	}
+
+	rows, err := s.queries.ListUserOrganizations(ctx, pgUUID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user organizations: %w", err)
+	}
+
+	orgs := make([]*pb.Organization, len(rows))
+	for i, row := range rows {
+		orgs[i] = dbOrganizationToProto(sqlc.Organization{ID: row.ID, Name: row.Name, Slug: row.Slug, CreatedAt: row.CreatedAt})
+		orgs[i].Role = row.Role
+	}
+	return orgs, nil
+}
+
+// AddMember implements Storage.AddMember.
+func (s *PostgresStorage) AddMember(ctx context.Context, member *pb.Membership) (*pb.Membership, error) {
+	orgID, userID, err := memberKey(member.OrgId, member.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	dbMember, err := s.queries.AddMember(ctx, sqlc.AddMemberParams{
+		OrgID:     orgID,
+		UserID:    userID,
+		Role:      member.Role,
+		CreatedAt: timestamptz(member.CreatedAt),
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, status.Errorf(codes.AlreadyExists, "user %s is already a member of organization %s", member.UserId, member.OrgId)
+		}
+		return nil, fmt.Errorf("failed to add member: %w", err)
+	}
+	return dbMemberToProto(dbMember), nil
+}
+
+// GetMembership implements Storage.GetMembership.
+func (s *PostgresStorage) GetMembership(ctx context.Context, orgID, userID string) (*pb.Membership, error) {
+	org, user, err := memberKey(orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	dbMember, err := s.queries.GetMember(ctx, sqlc.GetMemberParams{OrgID: org, UserID: user})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "user %s is not a member of organization %s", userID, orgID)
+		}
+		return nil, fmt.Errorf("failed to get member: %w", err)
+	}
+	return dbMemberToProto(dbMember), nil
+}
+
+// UpdateMember implements Storage.UpdateMember.
+func (s *PostgresStorage) UpdateMember(ctx context.Context, member *pb.Membership) (*pb.Membership, error) {
+	orgID, userID, err := memberKey(member.OrgId, member.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	dbMember, err := s.queries.UpdateMember(ctx, sqlc.UpdateMemberParams{OrgID: orgID, UserID: userID, Role: member.Role})
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "user %s is not a member of organization %s", member.UserId, member.OrgId)
+		}
+		return nil, fmt.Errorf("failed to update member: %w", err)
+	}
+	return dbMemberToProto(dbMember), nil
+}
+
+// RemoveMember implements Storage.RemoveMember.
+func (s *PostgresStorage) RemoveMember(ctx context.Context, orgID, userID string) error {
+	org, user, err := memberKey(orgID, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := s.queries.RemoveMember(ctx, sqlc.RemoveMemberParams{OrgID: org, UserID: user})
+	if err != nil {
+		return fmt.Errorf("failed to remove member: %w", err)
+	}
+	if rows == 0 {
+		return status.Errorf(codes.NotFound, "user %s is not a member of organization %s", userID, orgID)
+	}
+	return nil
+}
+
+// ListMembers implements Storage.ListMembers.
+func (s *PostgresStorage) ListMembers(ctx context.Context, orgID string, page, pageSize int32, roleFilter string) ([]*pb.User, int32, error) {
+	var org pgtype.UUID
+	if err := org.Scan(orgID); err != nil {
+		return nil, 0, status.Errorf(codes.InvalidArgument, "invalid organization ID: %v", err)
+	}
+	var role *string
+	if roleFilter != "" {
+		role = &roleFilter
+	}
+
+	rows, err := s.queries.ListMembers(ctx, sqlc.ListMembersParams{
+		OrgID:      org,
+		Role:       role,
+		PageLimit:  pageSize,
+		PageOffset: (page - 1) * pageSize,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list members: %w", err)
+	}
+	total, err := s.queries.CountMembers(ctx, sqlc.CountMembersParams{OrgID: org, Role: role})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count members: %w", err)
+	}
+
+	users := make([]*pb.User, len(rows))
+	for i, row := range rows {
+		users[i], err = dbUserToProto(sqlc.User{
+			ID:             row.ID,
+			Email:          row.Email,
+			Name:           row.Name,
+			Role:           row.Role,
+			CreatedAt:      row.CreatedAt,
+			LastLogin:      row.LastLogin,
+			IsActive:       row.IsActive,
+			ServiceAccount: row.ServiceAccount,
+		}, row.PasswordHash)
+		if err != nil {
+			return nil, 0, err
+		}
+		users[i].OrgRole = row.OrgRole
+	}
+	return users, int32(total), nil
+}
+
+// CreateAPIKey implements Storage.CreateAPIKey.
+func (s *PostgresStorage) CreateAPIKey(ctx context.Context, key *pb.ApiKey, keyHash string) (*pb.ApiKey, error) {
+	var id pgtype.UUID
+	if err := id.Scan(key.Id); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid API key ID: %v", err)
+	}
+	orgID, userID, err := memberKey(key.OrgId, key.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	var expiresAt pgtype.Timestamptz
+	if key.ExpiresAt != 0 {
+		expiresAt = timestamptz(key.ExpiresAt)
+	}
+	permissions := key.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	dbKey, err := s.queries.CreateApiKey(ctx, sqlc.CreateApiKeyParams{
+		ID:          id,
+		UserID:      userID,
+		OrgID:       orgID,
+		Name:        key.Name,
+		Prefix:      key.Prefix,
+		KeyHash:     keyHash,
+		Permissions: permissions,
+		CreatedAt:   timestamptz(key.CreatedAt),
+		ExpiresAt:   expiresAt,
+	})
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, status.Error(codes.AlreadyExists, "API key already exists")
+		}
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+	return dbAPIKeyToProto(dbKey), nil
+}
+
+// GetAPIKey implements Storage.GetAPIKey.
+func (s *PostgresStorage) GetAPIKey(ctx context.Context, id string) (*pb.ApiKey, error) {
+	var pgUUID pgtype.UUID
+	if err := pgUUID.Scan(id); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid API key ID: %v", err)
+	}
+
+	dbKey, err := s.queries.GetApiKey(ctx, pgUUID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "API key %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	return dbAPIKeyToProto(dbKey), nil
+}
+
+// GetAPIKeyByHash implements Storage.GetAPIKeyByHash.
+func (s *PostgresStorage) GetAPIKeyByHash(ctx context.Context, keyHash string) (*pb.ApiKey, error) {
+	dbKey, err := s.queries.GetApiKeyByHash(ctx, keyHash)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, status.Error(codes.NotFound, "API key not found")
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	return dbAPIKeyToProto(dbKey), nil
+}
+
+// ListAPIKeys implements Storage.ListAPIKeys.
+func (s *PostgresStorage) ListAPIKeys(ctx context.Context, userID, orgID string) ([]*pb.ApiKey, error) {
+	var params sqlc.ListApiKeysParams
+	if userID != "" {
+		if err := params.UserID.Scan(userID); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
+		}
+	}
+	if orgID != "" {
+		if err := params.OrgID.Scan(orgID); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid organization ID: %v", err)
+		}
+	}
+
+	dbKeys, err := s.queries.ListApiKeys(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+
+	keys := make([]*pb.ApiKey, len(dbKeys))
+	for i, dbKey := range dbKeys {
+		keys[i] = dbAPIKeyToProto(dbKey)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey implements Storage.RevokeAPIKey.
+func (s *PostgresStorage) RevokeAPIKey(ctx context.Context, id string) error {
+	var pgUUID pgtype.UUID
+	if err := pgUUID.Scan(id); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid API key ID: %v", err)
+	}
+
+	rows, err := s.queries.RevokeApiKey(ctx, sqlc.RevokeApiKeyParams{
+		ID:        pgUUID,
+		RevokedAt: pgtype.Timestamptz{Time: time.Now(), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	if rows == 0 {
+		// Already revoked, or missing
+		_, err := s.GetAPIKey(ctx, id)
+		return err
+	}
+
+	return nil
+}
+
+// TouchAPIKey implements Storage.TouchAPIKey.
+func (s *PostgresStorage) TouchAPIKey(ctx context.Context, id string, at time.Time) error {
+	var pgUUID pgtype.UUID
+	if err := pgUUID.Scan(id); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid API key ID: %v", err)
+	}
+
+	err := s.queries.TouchApiKey(ctx, sqlc.TouchApiKeyParams{
+		ID:         pgUUID,
+		LastUsedAt: pgtype.Timestamptz{Time: at, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to touch API key: %w", err)
+	}
+	return nil
+}
+
+// memberKey parses the key of a membership
+func memberKey(orgID, userID string) (pgtype.UUID, pgtype.UUID, error) {
+	var org, user pgtype.UUID
+	if err := org.Scan(orgID); err != nil {
+		return org, user, status.Errorf(codes.InvalidArgument, "invalid organization ID: %v", err)
+	}
+	if err := user.Scan(userID); err != nil {
+		return org, user, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
+	}
+	return org, user, nil
+}
+
+// isUniqueViolation reports whether err is a unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
 // Close closes the database connection pool.
 func (s *PostgresStorage) Close() error {
 	s.pool.Close()
@@ -246,11 +893,12 @@ func (s *PostgresStorage) Close() error {
 // Helper function to convert sqlc.User to pb.User
 func dbUserToProto(dbUser sqlc.User, passwordHash string) (*pb.User, error) {
 	user := &pb.User{
-		Id:       dbUser.ID.String(),
-		Email:    dbUser.Email,
-		Name:     dbUser.Name,
-		Role:     dbUser.Role,
-		IsActive: dbUser.IsActive,
+		Id:             dbUser.ID.String(),
+		Email:          dbUser.Email,
+		Name:           dbUser.Name,
+		Role:           dbUser.Role,
+		IsActive:       dbUser.IsActive,
+		ServiceAccount: dbUser.ServiceAccount,
 	}
 
 	// Convert timestamps
@@ -264,3 +912,113 @@ func dbUserToProto(dbUser sqlc.User, passwordHash string) (*pb.User, error) {
 
 	return user, nil
 }
+
+// Helper function to convert sqlc.Session to pb.Session
+func dbSessionToProto(dbSession sqlc.Session) *pb.Session {
+	session := &pb.Session{
+		Id:        dbSession.ID.String(),
+		UserId:    dbSession.UserID.String(),
+		Device:    dbSession.Device,
+		IpAddress: dbSession.IpAddress,
+		UserAgent: dbSession.UserAgent,
+	}
+
+	if dbSession.CreatedAt.Valid {
+		session.CreatedAt = dbSession.CreatedAt.Time.Unix()
+	}
+	if dbSession.LastUsedAt.Valid {
+		session.LastUsedAt = dbSession.LastUsedAt.Time.Unix()
+	}
+	if dbSession.ExpiresAt.Valid {
+		session.ExpiresAt = dbSession.ExpiresAt.Time.Unix()
+	}
+	if dbSession.RevokedAt.Valid {
+		session.RevokedAt = dbSession.RevokedAt.Time.Unix()
+	}
+	if dbSession.OrgID.Valid {
+		session.OrgId = dbSession.OrgID.String()
+	}
+
+	return session
+}
+
+// Helper function to convert sqlc.Role to pb.Role
+func dbRoleToProto(dbRole sqlc.Role) *pb.Role {
+	role := &pb.Role{
+		Name:        dbRole.Name,
+		Description: dbRole.Description,
+		Permissions: dbRole.Permissions,
+		BuiltIn:     dbRole.BuiltIn,
+	}
+
+	if dbRole.CreatedAt.Valid {
+		role.CreatedAt = dbRole.CreatedAt.Time.Unix()
+	}
+	if dbRole.UpdatedAt.Valid {
+		role.UpdatedAt = dbRole.UpdatedAt.Time.Unix()
+	}
+
+	return role
+}
+
+// Helper function to convert sqlc.Organization to pb.Organization
+func dbOrganizationToProto(dbOrg sqlc.Organization) *pb.Organization {
+	org := &pb.Organization{
+		Id:   dbOrg.ID.String(),
+		Name: dbOrg.Name,
+		Slug: dbOrg.Slug,
+	}
+
+	if dbOrg.CreatedAt.Valid {
+		org.CreatedAt = dbOrg.CreatedAt.Time.Unix()
+	}
+
+	return org
+}
+
+// Helper function to convert sqlc.OrganizationMember to pb.Membership
+func dbMemberToProto(dbMember sqlc.OrganizationMember) *pb.Membership {
+	member := &pb.Membership{
+		OrgId:  dbMember.OrgID.String(),
+		UserId: dbMember.UserID.String(),
+		Role:   dbMember.Role,
+	}
+
+	if dbMember.CreatedAt.Valid {
+		member.CreatedAt = dbMember.CreatedAt.Time.Unix()
+	}
+
+	return member
+}
+
+// Helper function to convert sqlc.ApiKey to pb.ApiKey
+func dbAPIKeyToProto(dbKey sqlc.ApiKey) *pb.ApiKey {
+	key := &pb.ApiKey{
+		Id:          dbKey.ID.String(),
+		UserId:      dbKey.UserID.String(),
+		OrgId:       dbKey.OrgID.String(),
+		Name:        dbKey.Name,
+		Prefix:      dbKey.Prefix,
+		Permissions: dbKey.Permissions,
+	}
+
+	if dbKey.CreatedAt.Valid {
+		key.CreatedAt = dbKey.CreatedAt.Time.Unix()
+	}
+	if dbKey.ExpiresAt.Valid {
+		key.ExpiresAt = dbKey.ExpiresAt.Time.Unix()
+	}
+	if dbKey.LastUsedAt.Valid {
+		key.LastUsedAt = dbKey.LastUsedAt.Time.Unix()
+	}
+	if dbKey.RevokedAt.Valid {
+		key.RevokedAt = dbKey.RevokedAt.Time.Unix()
+	}
+
+	return key
+}
+
+// timestamptz converts a Unix timestamp
+func timestamptz(unix int64) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: time.Unix(unix, 0), Valid: true}
+}
@@ -4,15 +4,19 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/joho/godotenv"
 	"golang.org/x/crypto/bcrypt"
 	pb "github.com/yourusername/iot-platform/shared/proto/user"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func init() {
@@ -482,3 +486,247 @@ func TestPostgresStorage_AuthenticationFlow(t *testing.T) {
 		t.Error("Authentication should fail with wrong password")
 	}
 }
+
+func TestPostgresStorage_RotateRefreshToken(t *testing.T) {
+	store := setupPostgresStorage(t)
+	cleanDatabase(t, store)
+	ctx := context.Background()
+
+	user := &pb.User{
+		Id:       uuid.New().String(),
+		Email:    "sessions@example.com",
+		Name:     "Sessions User",
+		Role:     "user",
+		IsActive: true,
+	}
+	if _, err := store.CreateUser(ctx, user, "hashed-password"); err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+
+	now := time.Now()
+	session, err := store.CreateSession(ctx, &pb.Session{
+		Id:         uuid.New().String(),
+		UserId:     user.Id,
+		Device:     "Laptop",
+		CreatedAt:  now.Unix(),
+		LastUsedAt: now.Unix(),
+		ExpiresAt:  now.Add(time.Hour).Unix(),
+	}, "hash-1")
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	use := SessionUse{At: now, ExpiresAt: now.Add(2 * time.Hour), IPAddress: "10.0.0.1"}
+	rotated, err := store.RotateRefreshToken(ctx, "hash-1", "hash-2", use)
+	if err != nil {
+		t.Fatalf("RotateRefreshToken failed: %v", err)
+	}
+	if rotated.Id != session.Id || rotated.ExpiresAt != use.ExpiresAt.Unix() {
+		t.Errorf("unexpected rotated session %+v", rotated)
+	}
+
+	reused, err := store.RotateRefreshToken(ctx, "hash-1", "hash-3", use)
+	if !errors.Is(err, ErrRefreshTokenReused) || reused == nil || reused.Id != session.Id {
+		t.Errorf("expected ErrRefreshTokenReused with session, got %v, %v", reused, err)
+	}
+
+	if err := store.RevokeSession(ctx, session.Id); err != nil {
+		t.Fatalf("RevokeSession failed: %v", err)
+	}
+	if err := store.RevokeSession(ctx, session.Id); err != nil {
+		t.Errorf("RevokeSession should be idempotent, got %v", err)
+	}
+	if _, err := store.RotateRefreshToken(ctx, "hash-2", "hash-4", use); !errors.Is(err, ErrSessionInactive) {
+		t.Errorf("expected ErrSessionInactive, got %v", err)
+	}
+
+	sessions, err := store.ListSessions(ctx, user.Id)
+	if err != nil {
+		t.Fatalf("ListSessions failed: %v", err)
+	}
+	if len(sessions) != 0 {
+		t.Errorf("expected no active session, got %d", len(sessions))
+	}
+}
+
+func TestPostgresStorage_Roles(t *testing.T) {
+	store := setupPostgresStorage(t)
+	cleanDatabase(t, store)
+	ctx := context.Background()
+	_ = store.DeleteRole(ctx, "test-viewer")
+
+	admin, err := store.GetRole(ctx, "admin")
+	if err != nil {
+		t.Fatalf("GetRole failed: %v", err)
+	}
+	if !admin.BuiltIn || len(admin.Permissions) != 1 || admin.Permissions[0] != "*" {
+		t.Errorf("unexpected admin role %+v", admin)
+	}
+
+	role, err := store.UpsertRole(ctx, &pb.Role{Name: "test-viewer", Description: "Read-only", Permissions: []string{"devices:read"}, UpdatedAt: time.Now().Unix()})
+	if err != nil {
+		t.Fatalf("UpsertRole failed: %v", err)
+	}
+	role, err = store.UpsertRole(ctx, &pb.Role{Name: "test-viewer", Permissions: []string{"devices:read", "telemetry:read"}, UpdatedAt: time.Now().Unix()})
+	if err != nil {
+		t.Fatalf("UpsertRole update failed: %v", err)
+	}
+	if len(role.Permissions) != 2 || role.BuiltIn {
+		t.Errorf("unexpected role %+v", role)
+	}
+	if _, err := store.UpsertRole(ctx, &pb.Role{Name: "admin", UpdatedAt: time.Now().Unix()}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition for a built-in role, got %v", err)
+	}
+
+	user := &pb.User{Id: uuid.New().String(), Email: "viewer@example.com", Name: "Viewer", Role: "test-viewer", IsActive: true}
+	if _, err := store.CreateUser(ctx, user, "hashed-password"); err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	if err := store.DeleteRole(ctx, "test-viewer"); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition for an assigned role, got %v", err)
+	}
+	if err := store.DeleteUser(ctx, user.Id); err != nil {
+		t.Fatalf("DeleteUser failed: %v", err)
+	}
+	if err := store.DeleteRole(ctx, "test-viewer"); err != nil {
+		t.Fatalf("DeleteRole failed: %v", err)
+	}
+	if _, err := store.GetRole(ctx, "test-viewer"); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound after deletion, got %v", err)
+	}
+}
+
+func TestPostgresStorage_Organizations(t *testing.T) {
+	store := setupPostgresStorage(t)
+	cleanDatabase(t, store)
+	ctx := context.Background()
+	slug := "test-" + uuid.New().String()[:8]
+
+	org, err := store.CreateOrganization(ctx, &pb.Organization{Id: uuid.New().String(), Name: "Test", Slug: slug, CreatedAt: time.Now().Unix()})
+	if err != nil {
+		t.Fatalf("CreateOrganization failed: %v", err)
+	}
+	t.Cleanup(func() { store.pool.Exec(context.Background(), "DELETE FROM organizations WHERE id = $1", org.Id) })
+	if _, err := store.CreateOrganization(ctx, &pb.Organization{Id: uuid.New().String(), Name: "Test", Slug: slug, CreatedAt: time.Now().Unix()}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("expected AlreadyExists for a taken slug, got %v", err)
+	}
+
+	user := &pb.User{Id: uuid.New().String(), Email: "member@example.com", Name: "Member", Role: "user", IsActive: true}
+	if _, err := store.CreateUser(ctx, user, "hashed-password"); err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	if _, err := store.AddMember(ctx, &pb.Membership{OrgId: org.Id, UserId: user.Id, Role: "user", CreatedAt: time.Now().Unix()}); err != nil {
+		t.Fatalf("AddMember failed: %v", err)
+	}
+	if _, err := store.AddMember(ctx, &pb.Membership{OrgId: org.Id, UserId: user.Id, Role: "user", CreatedAt: time.Now().Unix()}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("expected AlreadyExists for an existing member, got %v", err)
+	}
+
+	orgs, err := store.ListUserOrganizations(ctx, user.Id)
+	if err != nil {
+		t.Fatalf("ListUserOrganizations failed: %v", err)
+	}
+	if len(orgs) != 1 || orgs[0].Id != org.Id || orgs[0].Role != "user" {
+		t.Errorf("unexpected organizations %+v", orgs)
+	}
+
+	if _, err := store.UpdateMember(ctx, &pb.Membership{OrgId: org.Id, UserId: user.Id, Role: "admin"}); err != nil {
+		t.Fatalf("UpdateMember failed: %v", err)
+	}
+	users, total, err := store.ListMembers(ctx, org.Id, 1, 10, "admin")
+	if err != nil {
+		t.Fatalf("ListMembers failed: %v", err)
+	}
+	if total != 1 || users[0].Id != user.Id || users[0].OrgRole != "admin" {
+		t.Errorf("unexpected members %+v (total %d)", users, total)
+	}
+
+	session, err := store.CreateSession(ctx, &pb.Session{
+		Id:         uuid.New().String(),
+		UserId:     user.Id,
+		CreatedAt:  time.Now().Unix(),
+		LastUsedAt: time.Now().Unix(),
+		ExpiresAt:  time.Now().Add(time.Hour).Unix(),
+		OrgId:      DefaultOrganizationID,
+	}, uuid.New().String())
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	if session.OrgId != DefaultOrganizationID {
+		t.Errorf("OrgId = %q, want %q", session.OrgId, DefaultOrganizationID)
+	}
+	session, err = store.SetSessionOrganization(ctx, session.Id, org.Id)
+	if err != nil {
+		t.Fatalf("SetSessionOrganization failed: %v", err)
+	}
+	if session.OrgId != org.Id {
+		t.Errorf("OrgId = %q, want %q", session.OrgId, org.Id)
+	}
+
+	if err := store.RemoveMember(ctx, org.Id, user.Id); err != nil {
+		t.Fatalf("RemoveMember failed: %v", err)
+	}
+	if _, err := store.GetMembership(ctx, org.Id, user.Id); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound after removal, got %v", err)
+	}
+}
+
+func TestPostgresStorage_APIKeys(t *testing.T) {
+	store := setupPostgresStorage(t)
+	cleanDatabase(t, store)
+	ctx := context.Background()
+
+	user := &pb.User{Id: uuid.New().String(), Email: "bot@example.com", Name: "Bot", Role: "user", IsActive: true, ServiceAccount: true}
+	if _, err := store.CreateUser(ctx, user, ""); err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	if stored, err := store.GetUser(ctx, user.Id); err != nil || !stored.ServiceAccount {
+		t.Errorf("GetUser = %+v, %v, want a service account", stored, err)
+	}
+
+	keyHash := uuid.New().String()
+	key, err := store.CreateAPIKey(ctx, &pb.ApiKey{
+		Id:          uuid.New().String(),
+		UserId:      user.Id,
+		OrgId:       DefaultOrganizationID,
+		Name:        "CI",
+		Prefix:      "iotk_abcdefg",
+		Permissions: []string{"devices:read"},
+		CreatedAt:   time.Now().Unix(),
+	}, keyHash)
+	if err != nil {
+		t.Fatalf("CreateAPIKey failed: %v", err)
+	}
+	if key.ExpiresAt != 0 || key.LastUsedAt != 0 || len(key.Permissions) != 1 {
+		t.Errorf("unexpected key %+v", key)
+	}
+
+	found, err := store.GetAPIKeyByHash(ctx, keyHash)
+	if err != nil || found.Id != key.Id {
+		t.Fatalf("GetAPIKeyByHash = %+v, %v", found, err)
+	}
+	usedAt := time.Now().Truncate(time.Second)
+	if err := store.TouchAPIKey(ctx, key.Id, usedAt); err != nil {
+		t.Fatalf("TouchAPIKey failed: %v", err)
+	}
+	keys, err := store.ListAPIKeys(ctx, user.Id, DefaultOrganizationID)
+	if err != nil {
+		t.Fatalf("ListAPIKeys failed: %v", err)
+	}
+	if len(keys) != 1 || keys[0].LastUsedAt != usedAt.Unix() {
+		t.Errorf("unexpected keys %+v", keys)
+	}
+
+	if err := store.RevokeAPIKey(ctx, key.Id); err != nil {
+		t.Fatalf("RevokeAPIKey failed: %v", err)
+	}
+	if err := store.RevokeAPIKey(ctx, key.Id); err != nil {
+		t.Errorf("RevokeAPIKey on a revoked key failed: %v", err)
+	}
+	if keys, _ := store.ListAPIKeys(ctx, "", DefaultOrganizationID); len(keys) != 0 {
+		t.Errorf("ListAPIKeys returned revoked keys %+v", keys)
+	}
+	if err := store.RevokeAPIKey(ctx, uuid.New().String()); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an unknown key, got %v", err)
+	}
+}
@@ -4,10 +4,43 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	pb "github.com/yourusername/iot-platform/shared/proto/user"
 )
 
+var (
+	// ErrRefreshTokenReused is returned by RotateRefreshToken for a token
+	// that was already rotated: it has leaked, its session must be revoked.
+	ErrRefreshTokenReused = errors.New("refresh token already used")
+	// ErrSessionInactive is returned by RotateRefreshToken when the session
+	// of the token is revoked or expired.
+	ErrSessionInactive = errors.New("session revoked or expired")
+)
+
+// SessionUse describes a refresh of a session.
+type SessionUse struct {
+	At        time.Time
+	ExpiresAt time.Time // new expiry of the session
+	IPAddress string
+	UserAgent string
+}
+
+// DefaultOrganizationID is the organization created by migration
+// 011_create_organizations.sql, which existing users and devices were moved to.
+const DefaultOrganizationID = "00000000-0000-0000-0000-000000000001"
+
+// BuiltInRoles returns the roles every storage starts with. They are seeded
+// by migration 010_create_roles.sql for PostgreSQL and cannot be changed.
+func BuiltInRoles() []*pb.Role {
+	return []*pb.Role{
+		{Name: "admin", Description: "Full access", Permissions: []string{"*"}, BuiltIn: true},
+		{Name: "device", Description: "Device account, access to its own device only", Permissions: []string{}, BuiltIn: true},
+		{Name: "user", Description: "Manage devices and read their telemetry", Permissions: []string{"devices:read", "devices:write", "telemetry:read"}, BuiltIn: true},
+	}
+}
+
 // Storage defines the interface for user persistence operations.
 // Implementations: PostgresStorage (production), MemoryStorage (tests/dev).
 type Storage interface {
@@ -31,6 +64,11 @@ type Storage interface {
 	// Returns users, total count, and error.
 	ListUsers(ctx context.Context, page, pageSize int32, role string) ([]*pb.User, int32, error)
 
+	// ListMembers returns a paginated list of the members of an organization,
+	// with their role in it in User.OrgRole. role filters on that role.
+	// Returns users, total count, and error.
+	ListMembers(ctx context.Context, orgID string, page, pageSize int32, role string) ([]*pb.User, int32, error)
+
 	// UpdateUser updates an existing user.
 	// Only non-zero/non-nil fields are updated.
 	// Returns updated user or ErrNotFound.
@@ -39,10 +77,109 @@ type Storage interface {
 	// UpdateLastLogin updates the last_login timestamp for a user.
 	UpdateLastLogin(ctx context.Context, userID string) error
 
-	// DeleteUser removes a user by ID.
+	// DeleteUser removes a user by ID, with its sessions, memberships and API keys.
 	// Returns ErrNotFound if user doesn't exist.
 	DeleteUser(ctx context.Context, id string) error
 
+	// CreateSession stores a new session with the hash of its first refresh token.
+	CreateSession(ctx context.Context, session *pb.Session, tokenHash string) (*pb.Session, error)
+
+	// GetSession retrieves a session by ID, revoked or expired ones included.
+	// Returns nil, ErrNotFound if session doesn't exist.
+	GetSession(ctx context.Context, id string) (*pb.Session, error)
+
+	// RotateRefreshToken marks the refresh token tokenHash as used, stores
+	// newHash as its successor and records use on the session, atomically.
+	// Returns the session and ErrRefreshTokenReused if tokenHash was already
+	// used, ErrSessionInactive if its session is revoked or expired, or
+	// ErrNotFound if it is unknown.
+	RotateRefreshToken(ctx context.Context, tokenHash, newHash string, use SessionUse) (*pb.Session, error)
+
+	// ListSessions returns the active sessions of a user, most recently used first.
+	ListSessions(ctx context.Context, userID string) ([]*pb.Session, error)
+
+	// RevokeSession revokes a session. Revoking a revoked session is a no-op.
+	// Returns ErrNotFound if session doesn't exist.
+	RevokeSession(ctx context.Context, id string) error
+
+	// RevokeUserSessions revokes the active sessions of a user in an
+	// organization, or in all of them if orgID is empty, and returns their count.
+	RevokeUserSessions(ctx context.Context, userID, orgID string) (int32, error)
+
+	// SetSessionOrganization changes the organization a session is signed in to.
+	// Returns nil, ErrNotFound if session doesn't exist.
+	SetSessionOrganization(ctx context.Context, sessionID, orgID string) (*pb.Session, error)
+
+	// ListRoles returns the roles, sorted by name.
+	ListRoles(ctx context.Context) ([]*pb.Role, error)
+
+	// GetRole retrieves a role by name.
+	// Returns nil, ErrNotFound if role doesn't exist.
+	GetRole(ctx context.Context, name string) (*pb.Role, error)
+
+	// UpsertRole creates a custom role or replaces its description and
+	// permissions, at role.UpdatedAt.
+	// Returns FailedPrecondition for a built-in role.
+	UpsertRole(ctx context.Context, role *pb.Role) (*pb.Role, error)
+
+	// DeleteRole removes a custom role.
+	// Returns ErrNotFound if role doesn't exist, FailedPrecondition if it is
+	// built-in or assigned to users or members.
+	DeleteRole(ctx context.Context, name string) error
+
+	// CreateOrganization stores a new organization.
+	// Returns AlreadyExists if its slug is taken.
+	CreateOrganization(ctx context.Context, org *pb.Organization) (*pb.Organization, error)
+
+	// GetOrganization retrieves an organization by ID.
+	// Returns nil, ErrNotFound if organization doesn't exist.
+	GetOrganization(ctx context.Context, id string) (*pb.Organization, error)
+
+	// ListOrganizations returns the organizations, oldest first.
+	ListOrganizations(ctx context.Context) ([]*pb.Organization, error)
+
+	// ListUserOrganizations returns the organizations a user is a member of,
+	// with their role in Organization.Role, oldest membership first.
+	ListUserOrganizations(ctx context.Context, userID string) ([]*pb.Organization, error)
+
+	// AddMember adds a user to an organization.
+	// Returns AlreadyExists if the user is already a member.
+	AddMember(ctx context.Context, member *pb.Membership) (*pb.Membership, error)
+
+	// GetMembership retrieves the membership of a user in an organization.
+	// Returns nil, ErrNotFound if the user is not a member.
+	GetMembership(ctx context.Context, orgID, userID string) (*pb.Membership, error)
+
+	// UpdateMember changes the role of a member.
+	// Returns nil, ErrNotFound if the user is not a member.
+	UpdateMember(ctx context.Context, member *pb.Membership) (*pb.Membership, error)
+
+	// RemoveMember removes a user from an organization.
+	// Returns ErrNotFound if the user is not a member.
+	RemoveMember(ctx context.Context, orgID, userID string) error
+
+	// CreateAPIKey stores a new API key with the hash of its secret.
+	CreateAPIKey(ctx context.Context, key *pb.ApiKey, keyHash string) (*pb.ApiKey, error)
+
+	// GetAPIKey retrieves an API key by ID, revoked or expired ones included.
+	// Returns nil, ErrNotFound if key doesn't exist.
+	GetAPIKey(ctx context.Context, id string) (*pb.ApiKey, error)
+
+	// GetAPIKeyByHash retrieves an API key by the hash of its secret.
+	// Returns nil, ErrNotFound if key doesn't exist.
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*pb.ApiKey, error)
+
+	// ListAPIKeys returns the keys that are not revoked, newest first.
+	// An empty userID or orgID matches all of them.
+	ListAPIKeys(ctx context.Context, userID, orgID string) ([]*pb.ApiKey, error)
+
+	// RevokeAPIKey revokes an API key. Revoking a revoked key is a no-op.
+	// Returns ErrNotFound if key doesn't exist.
+	RevokeAPIKey(ctx context.Context, id string) error
+
+	// TouchAPIKey records the use of an API key.
+	TouchAPIKey(ctx context.Context, id string, at time.Time) error
+
 	// Close releases any resources held by the storage.
 	Close() error
 }
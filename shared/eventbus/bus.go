@@ -0,0 +1,141 @@
+// Package eventbus carries events between services: telemetry, anomalies and
+// device lifecycle events. Publishers and subscribers only deal with subjects
+// and payloads; the transport (Redis, NATS JetStream or in-process) is chosen
+// by configuration.
+//
+// Subjects are dot-separated tokens whose first token names the kind of event
+// ("telemetry.<device_id>"). Subscription patterns accept the NATS wildcards
+// "*" (exactly one token) and ">" (one or more trailing tokens) after the
+// first token.
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Event kinds, used as the first token of subjects.
+const (
+	SubjectTelemetry = "telemetry"
+	SubjectAnomalies = "anomalies"
+	SubjectDevices   = "devices"
+	SubjectGateways  = "gateways"
+)
+
+// Errors returned by buses.
+var (
+	// ErrReplayUnsupported is returned by Replay on transports without history.
+	ErrReplayUnsupported = errors.New("event bus transport keeps no history")
+	// ErrInvalidPattern is returned for malformed subjects or patterns.
+	ErrInvalidPattern = errors.New("invalid subject pattern")
+	// ErrClosed is returned when publishing on a closed bus.
+	ErrClosed = errors.New("event bus closed")
+)
+
+// Message is an event delivered by a bus.
+type Message struct {
+	// ID orders the messages of a subject on durable transports: a Redis
+	// stream entry ID ("<ms>-<seq>") or a sequence number. Empty on Redis pub/sub.
+	ID      string
+	Subject string
+	Data    []byte
+}
+
+// Handler processes one message. Handlers of a subscription are called from a
+// single goroutine and should not block.
+type Handler func(msg *Message)
+
+// Bus publishes and delivers events.
+type Bus interface {
+	// Publish sends data on subject.
+	Publish(ctx context.Context, subject string, data []byte) error
+
+	// Subscribe calls handler for every message matching pattern, published
+	// from now on, until ctx is cancelled or the bus is closed. With a
+	// consumer group configured, durable transports resume after the last
+	// message handled by the group, so nothing is lost across restarts.
+	Subscribe(ctx context.Context, pattern string, handler Handler) error
+
+	// Replay calls fn, in order, for each retained message matching pattern
+	// published after the message afterID. Messages already trimmed from the
+	// transport's history are skipped.
+	Replay(ctx context.Context, pattern, afterID string, fn func(msg *Message) error) error
+
+	// Close releases the transport's resources.
+	Close() error
+}
+
+// Subject builds a subject from tokens.
+func Subject(tokens ...string) string {
+	return strings.Join(tokens, ".")
+}
+
+// Match reports whether subject matches pattern.
+func Match(pattern, subject string) bool {
+	patternTokens := strings.Split(pattern, ".")
+	subjectTokens := strings.Split(subject, ".")
+
+	for i, token := range patternTokens {
+		if token == ">" {
+			return len(subjectTokens) > i
+		}
+		if i >= len(subjectTokens) {
+			return false
+		}
+		if token != "*" && token != subjectTokens[i] {
+			return false
+		}
+	}
+	return len(patternTokens) == len(subjectTokens)
+}
+
+// kind returns the first token of a subject or pattern, which must not be a
+// wildcard: transports keep one stream per kind of event.
+func kind(pattern string) (string, error) {
+	first, _, _ := strings.Cut(pattern, ".")
+	if first == "" || first == "*" || first == ">" {
+		return "", ErrInvalidPattern
+	}
+	return first, nil
+}
+
+// CompareIDs orders two message IDs of the same transport, either Redis
+// stream IDs ("<ms>-<seq>") or sequence numbers. It returns -1, 0 or 1, and
+// false if either ID is malformed.
+func CompareIDs(a, b string) (int, bool) {
+	aMajor, aMinor, okA := parseID(a)
+	bMajor, bMinor, okB := parseID(b)
+	if !okA || !okB {
+		return 0, false
+	}
+	switch {
+	case aMajor < bMajor || (aMajor == bMajor && aMinor < bMinor):
+		return -1, true
+	case aMajor == bMajor && aMinor == bMinor:
+		return 0, true
+	default:
+		return 1, true
+	}
+}
+
+// ValidID reports whether id is a well-formed message ID.
+func ValidID(id string) bool {
+	_, _, ok := parseID(id)
+	return ok
+}
+
+func parseID(id string) (major, minor uint64, ok bool) {
+	majorPart, minorPart, hasMinor := strings.Cut(id, "-")
+	major, err := strconv.ParseUint(majorPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if hasMinor {
+		if minor, err = strconv.ParseUint(minorPart, 10, 64); err != nil {
+			return 0, 0, false
+		}
+	}
+	return major, minor, true
+}
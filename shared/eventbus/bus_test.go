@@ -0,0 +1,63 @@
+// +build unit
+
+package eventbus
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		subject string
+		want    bool
+	}{
+		{"telemetry.dev-1", "telemetry.dev-1", true},
+		{"telemetry.dev-1", "telemetry.dev-2", false},
+		{"telemetry.*", "telemetry.dev-1", true},
+		{"telemetry.*", "telemetry.dev-1.temperature", false},
+		{"telemetry.>", "telemetry.dev-1", true},
+		{"telemetry.>", "telemetry.dev-1.temperature", true},
+		{"telemetry.>", "telemetry", false},
+		{"telemetry.>", "devices.dev-1", false},
+		{"devices.*", "telemetry.dev-1", false},
+	}
+
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.subject); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.subject, got, tt.want)
+		}
+	}
+}
+
+func TestCompareIDs(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+		ok   bool
+	}{
+		{"1700000000000-0", "1700000000000-1", -1, true},
+		{"1700000000001-0", "1700000000000-5", 1, true},
+		{"1700000000000-3", "1700000000000-3", 0, true},
+		{"9", "10", -1, true},
+		{"10", "10", 0, true},
+		{"abc", "10", 0, false},
+		{"10-x", "10", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := CompareIDs(tt.a, tt.b)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("CompareIDs(%q, %q) = %d, %v, want %d, %v", tt.a, tt.b, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestInvalidPatterns(t *testing.T) {
+	bus := NewMemory(0)
+	defer bus.Close()
+
+	for _, pattern := range []string{"", "*", ">", "*.dev-1", ".dev-1"} {
+		if err := bus.Subscribe(t.Context(), pattern, func(*Message) {}); err != ErrInvalidPattern {
+			t.Errorf("Subscribe(%q) error = %v, want ErrInvalidPattern", pattern, err)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Transports.
+const (
+	TransportMemory = "memory"
+	TransportRedis  = "redis"
+	TransportNATS   = "nats"
+)
+
+// Config selects and configures a transport.
+type Config struct {
+	// Transport is memory, redis or nats
+	Transport string
+	Redis     RedisConfig
+	NATS      NATSConfig
+	// MemoryMaxLen is the history kept by the in-process bus
+	MemoryMaxLen int
+}
+
+// New creates the bus selected by cfg.Transport.
+func New(ctx context.Context, cfg Config) (Bus, error) {
+	switch cfg.Transport {
+	case TransportMemory:
+		return NewMemory(cfg.MemoryMaxLen), nil
+	case TransportRedis, "":
+		return NewRedis(ctx, cfg.Redis)
+	case TransportNATS:
+		return NewNATS(ctx, cfg.NATS)
+	default:
+		return nil, fmt.Errorf("invalid event bus transport %q (memory, redis or nats)", cfg.Transport)
+	}
+}
+
+// ConfigFromEnv overrides defaults with the environment:
+//
+//	EVENT_BUS            memory, redis or nats
+//	EVENT_BUS_GROUP      consumer group (Redis) or durable consumer prefix (NATS)
+//	EVENT_BUS_CONSUMER   consumer name within the Redis group
+//	EVENT_BUS_MAXLEN     history kept per stream (Redis MAXLEN, NATS max messages, memory)
+//	REDIS_HOST, REDIS_PORT, REDIS_PASSWORD, REDIS_DB, REDIS_TRANSPORT
+//	NATS_URL, NATS_STREAM
+func ConfigFromEnv(defaults Config) Config {
+	cfg := defaults
+	cfg.Transport = getEnv("EVENT_BUS", cfg.Transport)
+	cfg.Redis.Host = getEnv("REDIS_HOST", cfg.Redis.Host)
+	cfg.Redis.Port = getEnvInt("REDIS_PORT", cfg.Redis.Port)
+	cfg.Redis.Password = getEnv("REDIS_PASSWORD", cfg.Redis.Password)
+	cfg.Redis.DB = getEnvInt("REDIS_DB", cfg.Redis.DB)
+	cfg.Redis.Mode = getEnv("REDIS_TRANSPORT", cfg.Redis.Mode)
+	cfg.NATS.URL = getEnv("NATS_URL", cfg.NATS.URL)
+	cfg.NATS.Stream = getEnv("NATS_STREAM", cfg.NATS.Stream)
+
+	group := getEnv("EVENT_BUS_GROUP", cfg.Redis.Group)
+	cfg.Redis.Group = group
+	cfg.NATS.Group = group
+	cfg.Redis.Consumer = getEnv("EVENT_BUS_CONSUMER", cfg.Redis.Consumer)
+
+	maxLen := getEnvInt("EVENT_BUS_MAXLEN", int(cfg.Redis.MaxLen))
+	cfg.Redis.MaxLen = int64(maxLen)
+	cfg.NATS.MaxMsgs = int64(maxLen)
+	cfg.MemoryMaxLen = maxLen
+	return cfg
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+	}
+	return defaultValue
+}
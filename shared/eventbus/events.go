@@ -0,0 +1,54 @@
+package eventbus
+
+// TelemetryEvent is published on "telemetry.<device_id>" for each stored point.
+type TelemetryEvent struct {
+	DeviceID   string  `json:"device_id"`
+	MetricName string  `json:"metric_name"`
+	Value      float64 `json:"value"`
+	Unit       string  `json:"unit"`
+	Timestamp  string  `json:"timestamp"`
+}
+
+// AnomalyEvent is published on "anomalies.<device_id>" for each anomalous point.
+type AnomalyEvent struct {
+	DeviceID   string  `json:"device_id"`
+	MetricName string  `json:"metric_name"`
+	Value      float64 `json:"value"`
+	Expected   float64 `json:"expected"`
+	Score      float64 `json:"score"`
+	Method     string  `json:"method"`
+	Timestamp  string  `json:"timestamp"`
+}
+
+// Device event types.
+const (
+	DeviceCreated = "created"
+	DeviceUpdated = "updated"
+	DeviceDeleted = "deleted"
+)
+
+// DeviceEvent is published on "devices.<device_id>" when a device is created,
+// updated or deleted. Deleted events only carry the device ID.
+type DeviceEvent struct {
+	Type       string            `json:"type"`
+	DeviceID   string            `json:"device_id"`
+	OrgID      string            `json:"org_id,omitempty"`
+	Name       string            `json:"name,omitempty"`
+	DeviceType string            `json:"device_type,omitempty"`
+	Status     string            `json:"status,omitempty"`
+	CreatedAt  int64             `json:"created_at,omitempty"`
+	LastSeen   int64             `json:"last_seen,omitempty"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	LocationID string            `json:"location_id,omitempty"`
+	Timestamp  string            `json:"timestamp"`
+}
+
+// SubscriptionStatsEvent is published periodically on "gateways.<replica>" by
+// each API Gateway replica with its active subscriptions.
+type SubscriptionStatsEvent struct {
+	Replica       string         `json:"replica"`
+	Subscriptions int            `json:"subscriptions"`
+	Wildcards     int            `json:"wildcards"`
+	Devices       map[string]int `json:"devices,omitempty"` // subscriptions per device ID
+	Timestamp     string         `json:"timestamp"`
+}
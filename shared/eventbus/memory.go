@@ -0,0 +1,216 @@
+package eventbus
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"sync"
+)
+
+// memoryBufferSize is the number of messages a subscription may lag behind
+// before new messages are dropped for it.
+const memoryBufferSize = 1024
+
+// Memory is an in-process bus for tests and single-binary setups. It keeps the
+// last MaxLen messages for Replay.
+type Memory struct {
+	mu      sync.RWMutex
+	seq     uint64
+	history []*Message
+	maxLen  int
+	subs    map[*memorySubscription]struct{}
+	closed  bool
+}
+
+type memorySubscription struct {
+	pattern  string
+	patterns map[string]struct{} // subject subscriptions, guarded by Memory.mu
+	ch       chan *Message
+}
+
+// matches reports whether the subscription receives subject.
+func (s *memorySubscription) matches(subject string) bool {
+	if s.patterns == nil {
+		return Match(s.pattern, subject)
+	}
+	for pattern := range s.patterns {
+		if Match(pattern, subject) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewMemory creates an in-process bus keeping maxLen messages of history
+// (0 for none).
+func NewMemory(maxLen int) *Memory {
+	return &Memory{
+		maxLen: maxLen,
+		subs:   make(map[*memorySubscription]struct{}),
+	}
+}
+
+// Publish delivers data to the matching subscriptions without blocking.
+func (m *Memory) Publish(ctx context.Context, subject string, data []byte) error {
+	if _, err := kind(subject); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return ErrClosed
+	}
+
+	m.seq++
+	msg := &Message{ID: strconv.FormatUint(m.seq, 10), Subject: subject, Data: data}
+	if m.maxLen > 0 {
+		if len(m.history) >= m.maxLen {
+			m.history = append(m.history[:0], m.history[len(m.history)-m.maxLen+1:]...)
+		}
+		m.history = append(m.history, msg)
+	}
+
+	for sub := range m.subs {
+		if !sub.matches(subject) {
+			continue
+		}
+		select {
+		case sub.ch <- msg:
+		default:
+			log.Printf("⚠️ Event bus subscription %s is full, message %s dropped", sub.pattern, msg.ID)
+		}
+	}
+	return nil
+}
+
+// Subscribe delivers matching messages to handler on a dedicated goroutine.
+// Consumer groups do not apply: every subscription receives every message.
+func (m *Memory) Subscribe(ctx context.Context, pattern string, handler Handler) error {
+	if _, err := kind(pattern); err != nil {
+		return err
+	}
+
+	return m.start(ctx, &memorySubscription{pattern: pattern, ch: make(chan *Message, memoryBufferSize)}, handler)
+}
+
+// SubscribeSubjects delivers the messages of a changing set of subjects to
+// handler on a dedicated goroutine.
+func (m *Memory) SubscribeSubjects(ctx context.Context, handler Handler) (SubjectSet, error) {
+	sub := &memorySubscription{
+		patterns: make(map[string]struct{}),
+		ch:       make(chan *Message, memoryBufferSize),
+	}
+	if err := m.start(ctx, sub, handler); err != nil {
+		return nil, err
+	}
+	return &memorySubjects{bus: m, sub: sub}, nil
+}
+
+// start registers sub and delivers its messages until ctx is cancelled.
+func (m *Memory) start(ctx context.Context, sub *memorySubscription, handler Handler) error {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return ErrClosed
+	}
+	m.subs[sub] = struct{}{}
+	m.mu.Unlock()
+
+	go func() {
+		defer m.unsubscribe(sub)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-sub.ch:
+				if !ok {
+					return
+				}
+				handler(msg)
+			}
+		}
+	}()
+	return nil
+}
+
+// Replay reads the retained history.
+func (m *Memory) Replay(ctx context.Context, pattern, afterID string, fn func(msg *Message) error) error {
+	if _, err := kind(pattern); err != nil {
+		return err
+	}
+	if !ValidID(afterID) {
+		return ErrInvalidPattern
+	}
+
+	m.mu.RLock()
+	history := make([]*Message, len(m.history))
+	copy(history, m.history)
+	m.mu.RUnlock()
+
+	for _, msg := range history {
+		if order, _ := CompareIDs(msg.ID, afterID); order <= 0 || !Match(pattern, msg.Subject) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close ends all subscriptions.
+func (m *Memory) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+	for sub := range m.subs {
+		delete(m.subs, sub)
+		close(sub.ch)
+	}
+	return nil
+}
+
+// memorySubjects is the subject set of a memory subscription.
+type memorySubjects struct {
+	bus *Memory
+	sub *memorySubscription
+}
+
+// Add starts receiving the messages of subjects.
+func (s *memorySubjects) Add(ctx context.Context, subjects ...string) error {
+	if err := validSubjects(subjects); err != nil {
+		return err
+	}
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	for _, subject := range subjects {
+		s.sub.patterns[subject] = struct{}{}
+	}
+	return nil
+}
+
+// Remove stops receiving the messages of subjects.
+func (s *memorySubjects) Remove(ctx context.Context, subjects ...string) error {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	for _, subject := range subjects {
+		delete(s.sub.patterns, subject)
+	}
+	return nil
+}
+
+func (m *Memory) unsubscribe(sub *memorySubscription) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.subs[sub]; ok {
+		delete(m.subs, sub)
+		close(sub.ch)
+	}
+}
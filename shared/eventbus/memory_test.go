@@ -0,0 +1,162 @@
+// +build unit
+
+package eventbus
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemory_PublishSubscribe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := NewMemory(0)
+	defer bus.Close()
+
+	received := make(chan *Message, 10)
+	if err := bus.Subscribe(ctx, "telemetry.dev-1", func(msg *Message) { received <- msg }); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	bus.Publish(ctx, "telemetry.dev-2", []byte("other"))
+	bus.Publish(ctx, "telemetry.dev-1", []byte("mine"))
+
+	select {
+	case msg := <-received:
+		if msg.Subject != "telemetry.dev-1" || string(msg.Data) != "mine" {
+			t.Errorf("Unexpected message %s %q", msg.Subject, msg.Data)
+		}
+		if msg.ID != "2" {
+			t.Errorf("Expected ID 2, got %s", msg.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Message not delivered")
+	}
+
+	select {
+	case msg := <-received:
+		t.Errorf("Unexpected extra message %s", msg.Subject)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestMemory_SubscriptionEndsWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	bus := NewMemory(0)
+	defer bus.Close()
+
+	if err := bus.Subscribe(ctx, "devices.>", func(*Message) {}); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	cancel()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		bus.mu.RLock()
+		n := len(bus.subs)
+		bus.mu.RUnlock()
+		if n == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Subscription not removed after context cancellation")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestMemory_Replay(t *testing.T) {
+	ctx := context.Background()
+	bus := NewMemory(3)
+	defer bus.Close()
+
+	for _, subject := range []string{"telemetry.a", "telemetry.b", "telemetry.a", "telemetry.a", "telemetry.b"} {
+		bus.Publish(ctx, subject, nil)
+	}
+
+	// History keeps IDs 3, 4 and 5
+	var ids []string
+	err := bus.Replay(ctx, "telemetry.a", "0", func(msg *Message) error {
+		ids = append(ids, msg.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "3" || ids[1] != "4" {
+		t.Errorf("Expected IDs [3 4], got %v", ids)
+	}
+
+	ids = nil
+	bus.Replay(ctx, "telemetry.>", "3", func(msg *Message) error {
+		ids = append(ids, msg.ID)
+		return nil
+	})
+	if len(ids) != 2 || ids[0] != "4" || ids[1] != "5" {
+		t.Errorf("Expected IDs [4 5], got %v", ids)
+	}
+
+	if err := bus.Replay(ctx, "telemetry.a", "not-an-id", func(*Message) error { return nil }); err != ErrInvalidPattern {
+		t.Errorf("Expected ErrInvalidPattern, got %v", err)
+	}
+}
+
+func TestMemory_Closed(t *testing.T) {
+	bus := NewMemory(0)
+	bus.Close()
+
+	if err := bus.Publish(context.Background(), "telemetry.a", nil); err != ErrClosed {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+	if err := bus.Subscribe(context.Background(), "telemetry.a", func(*Message) {}); err != ErrClosed {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+}
+
+func TestMemory_SubscribeSubjects(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := NewMemory(0)
+	defer bus.Close()
+
+	received := make(chan string, 10)
+	subjects, err := bus.SubscribeSubjects(ctx, func(msg *Message) { received <- string(msg.Data) })
+	if err != nil {
+		t.Fatalf("SubscribeSubjects failed: %v", err)
+	}
+	if err := subjects.Add(ctx, "telemetry.dev-1", "anomalies.>"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := subjects.Add(ctx, "*.dev-1"); err != ErrInvalidPattern {
+		t.Errorf("Expected ErrInvalidPattern, got %v", err)
+	}
+
+	bus.Publish(ctx, "telemetry.dev-1", []byte("1"))
+	bus.Publish(ctx, "telemetry.dev-2", []byte("2")) // not subscribed
+	bus.Publish(ctx, "anomalies.dev-2", []byte("3"))
+	subjects.Remove(ctx, "telemetry.dev-1")
+	bus.Publish(ctx, "telemetry.dev-1", []byte("4")) // removed
+	subjects.Add(ctx, "telemetry.dev-2")
+	bus.Publish(ctx, "telemetry.dev-2", []byte("5"))
+
+	var got []string
+	for len(got) < 3 {
+		select {
+		case data := <-received:
+			got = append(got, data)
+		case <-time.After(time.Second):
+			t.Fatalf("Messages not delivered, got %v", got)
+		}
+	}
+	if got[0] != "1" || got[1] != "3" || got[2] != "5" {
+		t.Errorf("Expected [1 3 5], got %v", got)
+	}
+	select {
+	case data := <-received:
+		t.Errorf("Unexpected extra message %s", data)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,372 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// natsPrefix prefixes subjects on NATS ("iot.telemetry.<device_id>").
+const natsPrefix = "iot"
+
+// NATSConfig holds NATS connection and JetStream configuration.
+type NATSConfig struct {
+	URL string
+	// Stream is the JetStream stream holding all events (default: IOT)
+	Stream string
+	// MaxMsgs caps the stream, oldest messages are discarded first (0: unlimited)
+	MaxMsgs int64
+	// Group names the durable consumers of this service instance, one per
+	// subscription; empty for ephemeral consumers that start at the end of the stream.
+	Group string
+}
+
+// NATS is a bus over NATS JetStream.
+type NATS struct {
+	conn   *nats.Conn
+	js     jetstream.JetStream
+	cfg    NATSConfig
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewNATS connects to NATS and creates or updates the JetStream stream.
+func NewNATS(ctx context.Context, cfg NATSConfig) (*NATS, error) {
+	if cfg.Stream == "" {
+		cfg.Stream = "IOT"
+	}
+
+	conn, err := nats.Connect(cfg.URL, nats.Name(cfg.Group), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to open JetStream: %w", err)
+	}
+
+	_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     cfg.Stream,
+		Subjects: []string{natsPrefix + ".>"},
+		MaxMsgs:  maxMsgs(cfg.MaxMsgs),
+		Discard:  jetstream.DiscardOld,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create JetStream stream: %w", err)
+	}
+
+	log.Printf("✅ Connected to NATS at %s (stream %s)", cfg.URL, cfg.Stream)
+
+	busCtx, cancel := context.WithCancel(context.Background())
+	return &NATS{conn: conn, js: js, cfg: cfg, ctx: busCtx, cancel: cancel}, nil
+}
+
+// Publish stores data in the stream.
+func (n *NATS) Publish(ctx context.Context, subject string, data []byte) error {
+	if _, err := kind(subject); err != nil {
+		return err
+	}
+	if _, err := n.js.Publish(ctx, natsPrefix+"."+subject, data); err != nil {
+		return fmt.Errorf("failed to publish to NATS: %w", err)
+	}
+	return nil
+}
+
+// Subscribe consumes new messages through a durable consumer named after the
+// group and pattern, or an ephemeral ordered consumer without group.
+func (n *NATS) Subscribe(ctx context.Context, pattern string, handler Handler) error {
+	if _, err := kind(pattern); err != nil {
+		return err
+	}
+	filter := natsPrefix + "." + pattern
+
+	var consumer jetstream.Consumer
+	var err error
+	if n.cfg.Group != "" {
+		consumer, err = n.js.CreateOrUpdateConsumer(ctx, n.cfg.Stream, jetstream.ConsumerConfig{
+			Durable:       durableName(n.cfg.Group, pattern),
+			FilterSubject: filter,
+			DeliverPolicy: jetstream.DeliverNewPolicy,
+			AckPolicy:     jetstream.AckExplicitPolicy,
+		})
+	} else {
+		consumer, err = n.js.OrderedConsumer(ctx, n.cfg.Stream, jetstream.OrderedConsumerConfig{
+			FilterSubjects: []string{filter},
+			DeliverPolicy:  jetstream.DeliverNewPolicy,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create NATS consumer: %w", err)
+	}
+
+	consumeCtx, err := consumer.Consume(func(m jetstream.Msg) {
+		handler(natsMessage(m))
+		if n.cfg.Group != "" {
+			if err := m.Ack(); err != nil {
+				log.Printf("⚠️ Failed to acknowledge NATS message: %v", err)
+			}
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to consume NATS stream: %w", err)
+	}
+	log.Printf("📡 Consuming NATS subject %s", filter)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-n.ctx.Done():
+		}
+		consumeCtx.Stop()
+	}()
+	return nil
+}
+
+// SubscribeSubjects consumes the stream through an ordered consumer filtered
+// on a changing set of subjects, so that the server only sends their
+// messages. Messages keep their stream sequence as ID.
+func (n *NATS) SubscribeSubjects(ctx context.Context, handler Handler) (SubjectSet, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(n.ctx, cancel)
+
+	set := &natsSubjects{bus: n, ctx: ctx, handler: handler, subjects: make(map[string]uint64)}
+	go func() {
+		<-ctx.Done()
+		stop()
+		set.mu.Lock()
+		defer set.mu.Unlock()
+		set.stopConsumer()
+	}()
+	return set, nil
+}
+
+// natsSubjects is the subject set of a NATS subject subscription. JetStream
+// consumers cannot change their filters: the consumer is replaced on every
+// change, resuming after the last message it delivered.
+type natsSubjects struct {
+	bus     *NATS
+	ctx     context.Context
+	handler Handler
+
+	mu       sync.Mutex
+	subjects map[string]uint64 // subject or pattern -> last stream sequence when added
+	consumer jetstream.ConsumeContext
+	gen      int    // generation of consumer, messages of older ones are dropped
+	next     uint64 // stream sequence the next consumer starts at
+}
+
+// Add starts receiving the messages of subjects published from now on.
+func (s *natsSubjects) Add(ctx context.Context, subjects ...string) error {
+	if err := validSubjects(subjects); err != nil {
+		return err
+	}
+	last, err := s.lastSequence(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.next == 0 {
+		s.next = last + 1
+	}
+	for _, subject := range subjects {
+		if _, ok := s.subjects[subject]; !ok {
+			s.subjects[subject] = last
+		}
+	}
+	return s.restart(ctx)
+}
+
+// Remove stops receiving the messages of subjects.
+func (s *natsSubjects) Remove(ctx context.Context, subjects ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, subject := range subjects {
+		delete(s.subjects, subject)
+	}
+	if len(s.subjects) == 0 {
+		s.next = 0
+	}
+	return s.restart(ctx)
+}
+
+// lastSequence returns the sequence of the last message of the stream.
+func (s *natsSubjects) lastSequence(ctx context.Context) (uint64, error) {
+	stream, err := s.bus.js.Stream(ctx, s.bus.cfg.Stream)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get JetStream stream: %w", err)
+	}
+	info, err := stream.Info(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get JetStream stream: %w", err)
+	}
+	return info.State.LastSeq, nil
+}
+
+// restart replaces the consumer by one filtered on the current subjects,
+// starting after the last message delivered. Callers hold s.mu.
+func (s *natsSubjects) restart(ctx context.Context) error {
+	s.stopConsumer()
+	filters := natsFilters(s.subjects)
+	if len(filters) == 0 || s.ctx.Err() != nil {
+		return nil
+	}
+
+	consumer, err := s.bus.js.OrderedConsumer(ctx, s.bus.cfg.Stream, jetstream.OrderedConsumerConfig{
+		FilterSubjects: filters,
+		DeliverPolicy:  jetstream.DeliverByStartSequencePolicy,
+		OptStartSeq:    s.next,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create NATS consumer: %w", err)
+	}
+	gen := s.gen
+	consumeCtx, err := consumer.Consume(func(m jetstream.Msg) { s.deliver(gen, m) })
+	if err != nil {
+		return fmt.Errorf("failed to consume NATS stream: %w", err)
+	}
+	s.consumer = consumeCtx
+	return nil
+}
+
+// stopConsumer stops the current consumer. Callers hold s.mu.
+func (s *natsSubjects) stopConsumer() {
+	s.gen++
+	if s.consumer != nil {
+		s.consumer.Stop()
+		s.consumer = nil
+	}
+}
+
+// deliver passes a message of consumer generation gen to the handler, unless
+// that consumer was replaced (its successor delivers the message again) or
+// the message was published before its subject was added. The handler runs
+// under s.mu, so that consumers being replaced never call it concurrently.
+func (s *natsSubjects) deliver(gen int, m jetstream.Msg) {
+	meta, err := m.Metadata()
+	if err != nil {
+		log.Printf("⚠️ Failed to read NATS message metadata: %v", err)
+		return
+	}
+	msg := natsMessage(m)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if gen != s.gen {
+		return
+	}
+	s.next = meta.Sequence.Stream + 1
+	for subject, added := range s.subjects {
+		if meta.Sequence.Stream > added && Match(subject, msg.Subject) {
+			s.handler(msg)
+			return
+		}
+	}
+}
+
+// natsFilters returns the filter subjects of a consumer receiving subjects.
+// Filters may not overlap: subjects matched by a pattern of the set are left
+// to the pattern.
+func natsFilters(subjects map[string]uint64) []string {
+	filters := make([]string, 0, len(subjects))
+	for subject := range subjects {
+		covered := false
+		for pattern := range subjects {
+			if pattern != subject && strings.ContainsAny(pattern, "*>") && Match(pattern, subject) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			filters = append(filters, natsPrefix+"."+subject)
+		}
+	}
+	sort.Strings(filters)
+	return filters
+}
+
+// Replay reads the stream from the sequence after afterID with an ordered
+// consumer, until it has caught up.
+func (n *NATS) Replay(ctx context.Context, pattern, afterID string, fn func(msg *Message) error) error {
+	if _, err := kind(pattern); err != nil {
+		return err
+	}
+	after, err := strconv.ParseUint(afterID, 10, 64)
+	if err != nil {
+		return ErrInvalidPattern
+	}
+
+	consumer, err := n.js.OrderedConsumer(ctx, n.cfg.Stream, jetstream.OrderedConsumerConfig{
+		FilterSubjects: []string{natsPrefix + "." + pattern},
+		DeliverPolicy:  jetstream.DeliverByStartSequencePolicy,
+		OptStartSeq:    after + 1,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create NATS consumer: %w", err)
+	}
+
+	for {
+		batch, err := consumer.Fetch(100, jetstream.FetchMaxWait(time.Second))
+		if err != nil {
+			return fmt.Errorf("failed to read NATS stream: %w", err)
+		}
+
+		received := 0
+		caughtUp := false
+		for m := range batch.Messages() {
+			received++
+			if err := fn(natsMessage(m)); err != nil {
+				return err
+			}
+			if meta, err := m.Metadata(); err == nil && meta.NumPending == 0 {
+				caughtUp = true
+			}
+		}
+		if err := batch.Error(); err != nil && !errors.Is(err, nats.ErrTimeout) {
+			return fmt.Errorf("failed to read NATS stream: %w", err)
+		}
+		if received == 0 || caughtUp {
+			return nil
+		}
+	}
+}
+
+// Close stops the subscriptions and closes the connection.
+func (n *NATS) Close() error {
+	n.cancel()
+	return n.conn.Drain()
+}
+
+func natsMessage(m jetstream.Msg) *Message {
+	msg := &Message{Subject: strings.TrimPrefix(m.Subject(), natsPrefix+"."), Data: m.Data()}
+	if meta, err := m.Metadata(); err == nil {
+		msg.ID = strconv.FormatUint(meta.Sequence.Stream, 10)
+	}
+	return msg
+}
+
+// durableName derives a consumer name from the group and pattern; consumer
+// names may not contain dots or wildcards.
+func durableName(group, pattern string) string {
+	replacer := strings.NewReplacer(".", "_", "*", "any", ">", "all", " ", "_")
+	return replacer.Replace(group + "_" + pattern)
+}
+
+func maxMsgs(n int64) int64 {
+	if n <= 0 {
+		return -1
+	}
+	return n
+}
@@ -0,0 +1,34 @@
+// +build unit
+
+package eventbus
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNATSFilters(t *testing.T) {
+	tests := []struct {
+		name     string
+		subjects []string
+		want     []string
+	}{
+		{"devices", []string{"telemetry.dev-2", "telemetry.dev-1"}, []string{"iot.telemetry.dev-1", "iot.telemetry.dev-2"}},
+		// While switching to all telemetry, both are in the set
+		{"covered_by_pattern", []string{"telemetry.dev-1", "telemetry.>"}, []string{"iot.telemetry.>"}},
+		{"other_kind", []string{"telemetry.*", "devices.dev-1"}, []string{"iot.devices.dev-1", "iot.telemetry.*"}},
+		{"empty", nil, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subjects := make(map[string]uint64)
+			for _, subject := range tt.subjects {
+				subjects[subject] = 0
+			}
+			if got := natsFilters(subjects); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("natsFilters(%v) = %v, want %v", tt.subjects, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,727 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis transport modes. Streams keep recent events so that consumers can
+// catch up after a disconnection; pub/sub is kept during the migration.
+const (
+	RedisPubSub  = "pubsub"
+	RedisStreams = "streams"
+	// RedisBoth publishes on both; subscribers read streams.
+	RedisBoth = "both"
+)
+
+// redisPrefix prefixes stream keys ("iot:telemetry", "iot:telemetry:<device_id>")
+// and pub/sub channels ("iot:telemetry:<device_id>").
+const redisPrefix = "iot"
+
+// redisReplayPageSize is the number of stream entries read per XRANGE call.
+const redisReplayPageSize = 500
+
+// Subject streams ("iot:telemetry:<device_id>") copy each entry of a kind's
+// stream under the same ID, so that SubscribeSubjects only reads the subjects
+// it holds. They only need to hold the entries not yet read: they are capped
+// to redisSubjectMaxLen entries and expire redisSubjectTTL after the last
+// event of their subject.
+const (
+	redisSubjectMaxLen = 1000
+	redisSubjectTTL    = 24 * time.Hour
+	// redisSubjectBlock is how long SubscribeSubjects waits for new entries;
+	// subjects added meanwhile are read from the next call
+	redisSubjectBlock = 250 * time.Millisecond
+)
+
+// redisPublishScript appends an event to the kind's stream (KEYS[1], trimmed
+// to ARGV[1] entries unless 0) and to the subject's stream (KEYS[2]) under
+// the same ID. A script runs atomically: the subject streams receive their
+// entries in ID order, whatever the number of publishers.
+var redisPublishScript = redis.NewScript(`
+local id
+if ARGV[1] == '0' then
+	id = redis.call('XADD', KEYS[1], '*', 'subject', ARGV[2], 'data', ARGV[3])
+else
+	id = redis.call('XADD', KEYS[1], 'MAXLEN', '~', ARGV[1], '*', 'subject', ARGV[2], 'data', ARGV[3])
+end
+redis.call('XADD', KEYS[2], 'MAXLEN', '~', ARGV[4], id, 'subject', ARGV[2], 'data', ARGV[3])
+redis.call('EXPIRE', KEYS[2], ARGV[5])
+return id
+`)
+
+// RedisConfig holds Redis connection and transport configuration.
+type RedisConfig struct {
+	Host     string
+	Port     int
+	Password string
+	DB       int
+
+	// Mode is pubsub, streams or both (default: streams)
+	Mode string
+	// MaxLen approximately caps each stream, 0 for no trimming
+	MaxLen int64
+	// Group is the consumer group of this service instance. Every instance
+	// needs its own group to receive all events; a stable name lets a
+	// restarted instance resume where it stopped.
+	Group string
+	// Consumer is the consumer name within the group
+	Consumer string
+	// StaleGroupPrefix, when set, makes Subscribe destroy the other groups
+	// with this prefix whose consumers have all been idle for StaleGroupIdle:
+	// the groups left behind by instances that will not come back, with their
+	// pending entries
+	StaleGroupPrefix string
+	// StaleGroupIdle is the idle time after which a group is stale (default: 1h)
+	StaleGroupIdle time.Duration
+}
+
+// defaultStaleGroupIdle is the default RedisConfig.StaleGroupIdle.
+const defaultStaleGroupIdle = time.Hour
+
+// Redis is a bus over Redis Streams (one stream per kind of event, trimmed
+// with MAXLEN, and a short stream per subject) and/or Redis Pub/Sub.
+type Redis struct {
+	client *redis.Client
+	cfg    RedisConfig
+	cancel context.CancelFunc
+	ctx    context.Context
+}
+
+// NewRedis connects to Redis.
+func NewRedis(ctx context.Context, cfg RedisConfig) (*Redis, error) {
+	switch cfg.Mode {
+	case "":
+		cfg.Mode = RedisStreams
+	case RedisPubSub, RedisStreams, RedisBoth:
+	default:
+		return nil, fmt.Errorf("invalid Redis transport %q (pubsub, streams or both)", cfg.Mode)
+	}
+	if cfg.StaleGroupIdle <= 0 {
+		cfg.StaleGroupIdle = defaultStaleGroupIdle
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:         fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Password:     cfg.Password,
+		DB:           cfg.DB,
+		DialTimeout:  5 * time.Second,
+		ReadTimeout:  0, // No timeout for blocking reads
+		WriteTimeout: 3 * time.Second,
+		PoolSize:     10,
+	})
+
+	// Test connection
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	log.Printf("✅ Connected to Redis at %s:%d (%s)", cfg.Host, cfg.Port, cfg.Mode)
+
+	busCtx, cancel := context.WithCancel(context.Background())
+	return &Redis{client: client, cfg: cfg, ctx: busCtx, cancel: cancel}, nil
+}
+
+// Publish appends to the stream of the subject's kind and/or publishes on the
+// subject's channel, depending on the mode.
+func (r *Redis) Publish(ctx context.Context, subject string, data []byte) error {
+	first, err := kind(subject)
+	if err != nil {
+		return err
+	}
+
+	if r.cfg.Mode != RedisPubSub {
+		// Append to the kind's stream, trimmed approximately to MaxLen
+		// entries, and to the subject's stream
+		keys := []string{streamKey(first), subjectStreamKey(subject)}
+		err := redisPublishScript.Run(ctx, r.client, keys,
+			r.cfg.MaxLen, subject, data, redisSubjectMaxLen, int(redisSubjectTTL.Seconds())).Err()
+		if err != nil {
+			return fmt.Errorf("failed to append to Redis stream: %w", err)
+		}
+	}
+
+	if r.cfg.Mode != RedisStreams {
+		if err := r.client.Publish(ctx, channel(subject), data).Err(); err != nil {
+			return fmt.Errorf("failed to publish to Redis: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Subscribe consumes the kind's stream through the consumer group, or
+// pattern-subscribes to the channels in pubsub mode.
+func (r *Redis) Subscribe(ctx context.Context, pattern string, handler Handler) error {
+	first, err := kind(pattern)
+	if err != nil {
+		return err
+	}
+	ctx, stop := r.mergeContext(ctx)
+
+	if r.cfg.Mode == RedisPubSub {
+		// Redis globs are looser than subject wildcards: filter again on delivery
+		ps := r.client.PSubscribe(ctx, channel(strings.ReplaceAll(pattern, ">", "*")))
+		if _, err := ps.Receive(ctx); err != nil {
+			stop()
+			ps.Close()
+			return fmt.Errorf("failed to subscribe to Redis: %w", err)
+		}
+		go r.listen(ctx, stop, ps, pattern, handler)
+		return nil
+	}
+
+	if r.cfg.Group == "" || r.cfg.Consumer == "" {
+		stop()
+		return errors.New("consumer group and consumer name required for Redis streams")
+	}
+	// Create the group at the end of the stream: a new consumer does not replay history
+	err = r.client.XGroupCreateMkStream(ctx, streamKey(first), r.cfg.Group, "$").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		stop()
+		return fmt.Errorf("failed to create consumer group: %w", err)
+	}
+	if r.cfg.StaleGroupPrefix != "" {
+		r.pruneGroups(ctx, streamKey(first))
+	}
+	go r.consume(ctx, stop, streamKey(first), pattern, handler)
+	return nil
+}
+
+// pruneGroups destroys the stale groups of the stream. A group without
+// consumers is kept: its instance has not read yet. Failures are only logged,
+// the next instance to start tries again.
+func (r *Redis) pruneGroups(ctx context.Context, stream string) {
+	groups, err := r.client.XInfoGroups(ctx, stream).Result()
+	if err != nil {
+		log.Printf("⚠️ Failed to list consumer groups of %s: %v", stream, err)
+		return
+	}
+	for _, group := range groups {
+		if group.Name == r.cfg.Group || !strings.HasPrefix(group.Name, r.cfg.StaleGroupPrefix) || group.Consumers == 0 {
+			continue
+		}
+		consumers, err := r.client.XInfoConsumers(ctx, stream, group.Name).Result()
+		if err != nil {
+			log.Printf("⚠️ Failed to list consumers of group %s: %v", group.Name, err)
+			continue
+		}
+		stale := true
+		for _, consumer := range consumers {
+			if consumer.Idle < r.cfg.StaleGroupIdle {
+				stale = false
+				break
+			}
+		}
+		if !stale {
+			continue
+		}
+		if err := r.client.XGroupDestroy(ctx, stream, group.Name).Err(); err != nil {
+			log.Printf("⚠️ Failed to destroy consumer group %s: %v", group.Name, err)
+			continue
+		}
+		log.Printf("🧹 Destroyed stale consumer group %s of %s (%d pending entries)", group.Name, stream, group.Pending)
+	}
+}
+
+// listen dispatches pub/sub messages.
+func (r *Redis) listen(ctx context.Context, stop context.CancelFunc, ps *redis.PubSub, pattern string, handler Handler) {
+	defer stop()
+	defer ps.Close()
+	log.Printf("📡 Subscribed to Redis pattern: %s", channel(pattern))
+
+	ch := ps.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("⏹️ Redis subscriber stopped")
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				log.Printf("⚠️ Redis pub/sub channel closed")
+				return
+			}
+			subject := strings.ReplaceAll(strings.TrimPrefix(msg.Channel, redisPrefix+":"), ":", ".")
+			if Match(pattern, subject) {
+				handler(&Message{Subject: subject, Data: []byte(msg.Payload)})
+			}
+		}
+	}
+}
+
+// SubscribeSubjects receives a changing set of subjects. In pubsub mode, it
+// subscribes to their channels over a single connection: exact subjects use
+// SUBSCRIBE, patterns PSUBSCRIBE. Otherwise it reads the streams of the exact
+// subjects and, for patterns, the kind's stream, without consumer group;
+// messages keep their stream entry ID.
+func (r *Redis) SubscribeSubjects(ctx context.Context, handler Handler) (SubjectSet, error) {
+	ctx, stop := r.mergeContext(ctx)
+
+	if r.cfg.Mode == RedisPubSub {
+		set := &redisSubjects{ps: r.client.Subscribe(ctx), globs: make(map[string]string)}
+		go set.listen(ctx, stop, handler)
+		return set, nil
+	}
+
+	set := &redisStreamSubjects{
+		client:   r.client,
+		handler:  handler,
+		subjects: make(map[string]string),
+		cursors:  make(map[string]string),
+		changed:  make(chan struct{}, 1),
+	}
+	go set.read(ctx, stop)
+	return set, nil
+}
+
+// redisSubjects is the subject set of a Redis pub/sub connection.
+type redisSubjects struct {
+	ps *redis.PubSub
+
+	mu    sync.RWMutex
+	globs map[string]string // Redis glob -> subscribed pattern
+}
+
+// Add subscribes to the channels of subjects.
+func (s *redisSubjects) Add(ctx context.Context, subjects ...string) error {
+	if err := validSubjects(subjects); err != nil {
+		return err
+	}
+	channels, globs := s.split(subjects)
+	if len(channels) > 0 {
+		if err := s.ps.Subscribe(ctx, channels...); err != nil {
+			return fmt.Errorf("failed to subscribe to Redis: %w", err)
+		}
+	}
+	if len(globs) > 0 {
+		s.mu.Lock()
+		for glob, pattern := range globs {
+			s.globs[glob] = pattern
+		}
+		s.mu.Unlock()
+		if err := s.ps.PSubscribe(ctx, keys(globs)...); err != nil {
+			return fmt.Errorf("failed to subscribe to Redis: %w", err)
+		}
+	}
+	return nil
+}
+
+// Remove unsubscribes from the channels of subjects.
+func (s *redisSubjects) Remove(ctx context.Context, subjects ...string) error {
+	channels, globs := s.split(subjects)
+	if len(channels) > 0 {
+		if err := s.ps.Unsubscribe(ctx, channels...); err != nil {
+			return fmt.Errorf("failed to unsubscribe from Redis: %w", err)
+		}
+	}
+	if len(globs) > 0 {
+		if err := s.ps.PUnsubscribe(ctx, keys(globs)...); err != nil {
+			return fmt.Errorf("failed to unsubscribe from Redis: %w", err)
+		}
+		s.mu.Lock()
+		for glob := range globs {
+			delete(s.globs, glob)
+		}
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+// split separates exact subjects, as channels, from patterns, as Redis globs.
+func (s *redisSubjects) split(subjects []string) (channels []string, globs map[string]string) {
+	globs = make(map[string]string)
+	for _, subject := range subjects {
+		if strings.ContainsAny(subject, "*>") {
+			globs[channel(strings.ReplaceAll(subject, ">", "*"))] = subject
+		} else {
+			channels = append(channels, channel(subject))
+		}
+	}
+	return channels, globs
+}
+
+// listen dispatches the messages of the subscribed channels and patterns.
+func (s *redisSubjects) listen(ctx context.Context, stop context.CancelFunc, handler Handler) {
+	defer stop()
+	defer s.ps.Close()
+
+	ch := s.ps.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("⏹️ Redis subject subscriber stopped")
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				log.Printf("⚠️ Redis pub/sub channel closed")
+				return
+			}
+			subject := strings.ReplaceAll(strings.TrimPrefix(msg.Channel, redisPrefix+":"), ":", ".")
+			if msg.Pattern != "" {
+				// Redis globs are looser than subject wildcards: filter again
+				s.mu.RLock()
+				pattern, ok := s.globs[msg.Pattern]
+				s.mu.RUnlock()
+				if !ok || !Match(pattern, subject) {
+					continue
+				}
+			}
+			handler(&Message{Subject: subject, Data: []byte(msg.Payload)})
+		}
+	}
+}
+
+// redisStreamSubjects is the subject set of a Redis streams subject
+// subscription. All the streams of a kind are read from a common cursor, the
+// ID of the last entry read: the subject streams share the IDs of the kind's
+// stream, so that exact subjects and patterns can be swapped without losing
+// or repeating entries.
+type redisStreamSubjects struct {
+	client  *redis.Client
+	handler Handler
+
+	mu       sync.Mutex
+	subjects map[string]string // subject or pattern -> last ID of its kind's stream when added
+	cursors  map[string]string // kind -> ID of the last entry read
+	changed  chan struct{}
+}
+
+// Add starts receiving the messages of subjects published from now on.
+func (s *redisStreamSubjects) Add(ctx context.Context, subjects ...string) error {
+	if err := validSubjects(subjects); err != nil {
+		return err
+	}
+	last := make(map[string]string)
+	for _, subject := range subjects {
+		first, _ := kind(subject)
+		if _, ok := last[first]; ok {
+			continue
+		}
+		id, err := lastStreamID(ctx, s.client, streamKey(first))
+		if err != nil {
+			return err
+		}
+		last[first] = id
+	}
+
+	s.mu.Lock()
+	for _, subject := range subjects {
+		first, _ := kind(subject)
+		if _, ok := s.cursors[first]; !ok {
+			s.cursors[first] = last[first]
+		}
+		if _, ok := s.subjects[subject]; !ok {
+			s.subjects[subject] = last[first]
+		}
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Remove stops receiving the messages of subjects.
+func (s *redisStreamSubjects) Remove(ctx context.Context, subjects ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, subject := range subjects {
+		delete(s.subjects, subject)
+	}
+	// Kinds without subjects restart from the end of their stream
+	for first := range s.cursors {
+		if !s.hasKind(first) {
+			delete(s.cursors, first)
+		}
+	}
+	return nil
+}
+
+// hasKind reports whether a subject of the set has kind first. Callers hold s.mu.
+func (s *redisStreamSubjects) hasKind(first string) bool {
+	for subject := range s.subjects {
+		if k, _ := kind(subject); k == first {
+			return true
+		}
+	}
+	return false
+}
+
+// streams returns the streams to read and the kind of each. Subjects matched
+// by a pattern of the set are left to the pattern. Callers hold s.mu.
+func (s *redisStreamSubjects) streams() map[string]string {
+	streams := make(map[string]string)
+	for subject := range s.subjects {
+		first, _ := kind(subject)
+		if strings.ContainsAny(subject, "*>") {
+			streams[streamKey(first)] = first
+			continue
+		}
+		covered := false
+		for pattern := range s.subjects {
+			if pattern != subject && strings.ContainsAny(pattern, "*>") && Match(pattern, subject) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			streams[subjectStreamKey(subject)] = first
+		}
+	}
+	return streams
+}
+
+// read reads the streams of the set until ctx is cancelled.
+func (s *redisStreamSubjects) read(ctx context.Context, stop context.CancelFunc) {
+	defer stop()
+	const count = 100
+
+	for {
+		s.mu.Lock()
+		streams := s.streams()
+		keys := make([]string, 0, len(streams))
+		for key := range streams {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		args := append([]string(nil), keys...)
+		for _, key := range keys {
+			args = append(args, s.cursors[streams[key]])
+		}
+		s.mu.Unlock()
+
+		if len(keys) == 0 {
+			select {
+			case <-ctx.Done():
+				log.Printf("⏹️ Redis subject subscriber stopped")
+				return
+			case <-s.changed:
+			}
+			continue
+		}
+
+		results, err := s.client.XRead(ctx, &redis.XReadArgs{Streams: args, Count: count, Block: redisSubjectBlock}).Result()
+		if ctx.Err() != nil {
+			log.Printf("⏹️ Redis subject subscriber stopped")
+			return
+		}
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			log.Printf("⚠️ Failed to read Redis subject streams: %v", err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		// Entries of a kind are delivered in ID order. When a stream returns
+		// a full page, later entries of the other streams wait for the next
+		// read, so that the cursor does not skip the rest of that stream.
+		entries := make(map[string][]redis.XMessage)
+		limits := make(map[string]string)
+		for _, result := range results {
+			first := streams[result.Stream]
+			entries[first] = append(entries[first], result.Messages...)
+			if len(result.Messages) == count {
+				last := result.Messages[count-1].ID
+				if limit, ok := limits[first]; !ok || compareIDs(last, limit) < 0 {
+					limits[first] = last
+				}
+			}
+		}
+		for first, messages := range entries {
+			sort.Slice(messages, func(i, j int) bool { return compareIDs(messages[i].ID, messages[j].ID) < 0 })
+			if limit, ok := limits[first]; ok {
+				n := sort.Search(len(messages), func(i int) bool { return compareIDs(messages[i].ID, limit) > 0 })
+				messages = messages[:n]
+			}
+			s.deliver(first, messages)
+		}
+	}
+}
+
+// deliver passes the entries of kind first read after its cursor to the
+// handler, unless their subject was removed or added after them, and moves
+// the cursor. The handler runs under s.mu, like for NATS.
+func (s *redisStreamSubjects) deliver(first string, entries []redis.XMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, entry := range entries {
+		cursor, ok := s.cursors[first]
+		if !ok {
+			// No subject of this kind left
+			return
+		}
+		if compareIDs(entry.ID, cursor) <= 0 {
+			// Read again after the kind was removed and added back
+			continue
+		}
+		s.cursors[first] = entry.ID
+
+		msg := streamMessage(entry)
+		for subject, added := range s.subjects {
+			if compareIDs(entry.ID, added) > 0 && Match(subject, msg.Subject) {
+				s.handler(msg)
+				break
+			}
+		}
+	}
+}
+
+// lastStreamID returns the ID of the last entry of a stream, "0-0" when empty.
+func lastStreamID(ctx context.Context, client *redis.Client, key string) (string, error) {
+	entries, err := client.XRevRangeN(ctx, key, "+", "-", 1).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to read Redis stream: %w", err)
+	}
+	if len(entries) == 0 {
+		return "0-0", nil
+	}
+	return entries[0].ID, nil
+}
+
+// compareIDs orders two Redis stream IDs.
+func compareIDs(a, b string) int {
+	cmp, _ := CompareIDs(a, b)
+	return cmp
+}
+
+func keys(m map[string]string) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
+
+// consume reads a stream through the consumer group. Entries left pending by
+// a previous run (delivered but not acknowledged) are read first.
+func (r *Redis) consume(ctx context.Context, stop context.CancelFunc, stream, pattern string, handler Handler) {
+	defer stop()
+	log.Printf("📡 Consuming Redis stream %s (group %s, consumer %s)", stream, r.cfg.Group, r.cfg.Consumer)
+
+	// "0" reads this consumer's pending entries, ">" new ones
+	next := "0"
+	for {
+		streams, err := r.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    r.cfg.Group,
+			Consumer: r.cfg.Consumer,
+			Streams:  []string{stream, next},
+			Count:    100,
+			Block:    5 * time.Second,
+		}).Result()
+		if ctx.Err() != nil {
+			log.Printf("⏹️ Redis stream consumer stopped")
+			return
+		}
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			log.Printf("⚠️ Failed to read Redis stream: %v", err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		messages := streams[0].Messages
+		if next != ">" {
+			if len(messages) == 0 {
+				next = ">"
+				continue
+			}
+			next = messages[len(messages)-1].ID
+		}
+
+		ids := make([]string, len(messages))
+		for i, entry := range messages {
+			ids[i] = entry.ID
+			if msg := streamMessage(entry); Match(pattern, msg.Subject) {
+				handler(msg)
+			}
+		}
+		if err := r.client.XAck(ctx, stream, r.cfg.Group, ids...).Err(); err != nil {
+			log.Printf("⚠️ Failed to acknowledge Redis stream entries: %v", err)
+		}
+	}
+}
+
+// Replay reads the kind's stream after afterID.
+func (r *Redis) Replay(ctx context.Context, pattern, afterID string, fn func(msg *Message) error) error {
+	if r.cfg.Mode == RedisPubSub {
+		return ErrReplayUnsupported
+	}
+	first, err := kind(pattern)
+	if err != nil {
+		return err
+	}
+	if !ValidID(afterID) {
+		return ErrInvalidPattern
+	}
+
+	start := "(" + afterID
+	for {
+		entries, err := r.client.XRangeN(ctx, streamKey(first), start, "+", redisReplayPageSize).Result()
+		if err != nil {
+			return fmt.Errorf("failed to read Redis stream: %w", err)
+		}
+
+		for _, entry := range entries {
+			if msg := streamMessage(entry); Match(pattern, msg.Subject) {
+				if err := fn(msg); err != nil {
+					return err
+				}
+			}
+		}
+
+		if len(entries) < redisReplayPageSize {
+			return nil
+		}
+		start = "(" + entries[len(entries)-1].ID
+	}
+}
+
+// Close stops the subscriptions and closes the Redis connection.
+func (r *Redis) Close() error {
+	r.cancel()
+	return r.client.Close()
+}
+
+// mergeContext returns a context cancelled with either ctx or the bus.
+func (r *Redis) mergeContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	merged, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(r.ctx, cancel)
+	return merged, func() {
+		stop()
+		cancel()
+	}
+}
+
+func streamKey(kind string) string {
+	return redisPrefix + ":" + kind
+}
+
+// subjectStreamKey is the stream of a single subject ("iot:telemetry:<device_id>").
+func subjectStreamKey(subject string) string {
+	return redisPrefix + ":" + strings.ReplaceAll(subject, ".", ":")
+}
+
+func channel(subject string) string {
+	return redisPrefix + ":" + strings.ReplaceAll(subject, ".", ":")
+}
+
+func streamMessage(entry redis.XMessage) *Message {
+	subject, _ := entry.Values["subject"].(string)
+	data, _ := entry.Values["data"].(string)
+	return &Message{ID: entry.ID, Subject: subject, Data: []byte(data)}
+}
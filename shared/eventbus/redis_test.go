@@ -0,0 +1,457 @@
+// +build unit
+
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestRedis connects a bus to the miniredis server s.
+func newTestRedis(t *testing.T, s *miniredis.Miniredis, cfg RedisConfig) *Redis {
+	t.Helper()
+	port, err := strconv.Atoi(s.Port())
+	if err != nil {
+		t.Fatalf("invalid miniredis port %q", s.Port())
+	}
+	cfg.Host, cfg.Port = s.Host(), port
+	bus, err := NewRedis(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("NewRedis failed: %v", err)
+	}
+	t.Cleanup(func() { bus.Close() })
+	return bus
+}
+
+// receive waits for the next message.
+func receive(t *testing.T, received <-chan *Message) *Message {
+	t.Helper()
+	select {
+	case msg := <-received:
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("Message not delivered")
+		return nil
+	}
+}
+
+// waitPending waits until the group has no pending entry left.
+func waitPending(t *testing.T, bus *Redis, stream, group string) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		pending, err := bus.client.XPending(context.Background(), stream, group).Result()
+		if err != nil {
+			t.Fatalf("XPENDING failed: %v", err)
+		}
+		if pending.Count == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d entries still pending", pending.Count)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRedis_SubscribeCreatesGroupAtEnd(t *testing.T) {
+	s := miniredis.RunT(t)
+	ctx := context.Background()
+	bus := newTestRedis(t, s, RedisConfig{Group: "gateway", Consumer: "gateway-1"})
+
+	if err := bus.Publish(ctx, "telemetry.dev-1", []byte("before")); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	received := make(chan *Message, 10)
+	if err := bus.Subscribe(ctx, "telemetry.>", func(msg *Message) { received <- msg }); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	// Subscribing again with the same group is not an error
+	if err := bus.Subscribe(ctx, "telemetry.>", func(*Message) {}); err != nil {
+		t.Fatalf("second Subscribe failed: %v", err)
+	}
+
+	groups, err := bus.client.XInfoGroups(ctx, "iot:telemetry").Result()
+	if err != nil {
+		t.Fatalf("XINFO GROUPS failed: %v", err)
+	}
+	if len(groups) != 1 || groups[0].Name != "gateway" {
+		t.Fatalf("expected the gateway group, got %+v", groups)
+	}
+
+	if err := bus.Publish(ctx, "telemetry.dev-1", []byte("after")); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	// A new group does not replay the history
+	msg := receive(t, received)
+	if msg.Subject != "telemetry.dev-1" || string(msg.Data) != "after" || !ValidID(msg.ID) {
+		t.Errorf("Unexpected message %s %q %s", msg.Subject, msg.Data, msg.ID)
+	}
+	waitPending(t, bus, "iot:telemetry", "gateway")
+}
+
+func TestRedis_SubscribeRequiresGroup(t *testing.T) {
+	s := miniredis.RunT(t)
+	bus := newTestRedis(t, s, RedisConfig{})
+
+	if err := bus.Subscribe(context.Background(), "telemetry.>", func(*Message) {}); err == nil {
+		t.Error("expected an error without consumer group")
+	}
+}
+
+// TestRedis_RedeliversPendingAfterRestart checks that entries delivered to a
+// consumer that stopped before acknowledging them are delivered again, first
+func TestRedis_RedeliversPendingAfterRestart(t *testing.T) {
+	s := miniredis.RunT(t)
+	ctx := context.Background()
+	cfg := RedisConfig{Group: "device-manager", Consumer: "device-manager-1"}
+	bus := newTestRedis(t, s, cfg)
+
+	// A previous run read two entries and stopped before XACK
+	if err := bus.client.XGroupCreateMkStream(ctx, "iot:devices", cfg.Group, "$").Err(); err != nil {
+		t.Fatalf("XGROUP CREATE failed: %v", err)
+	}
+	for _, data := range []string{"one", "two"} {
+		if err := bus.Publish(ctx, "devices.dev-1", []byte(data)); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+	err := bus.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group: cfg.Group, Consumer: cfg.Consumer, Streams: []string{"iot:devices", ">"},
+	}).Err()
+	if err != nil {
+		t.Fatalf("XREADGROUP failed: %v", err)
+	}
+	// Published while stopped
+	if err := bus.Publish(ctx, "devices.dev-1", []byte("three")); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	received := make(chan *Message, 10)
+	if err := bus.Subscribe(ctx, "devices.>", func(msg *Message) { received <- msg }); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	if err := bus.Publish(ctx, "devices.dev-1", []byte("four")); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+
+	var last string
+	for _, want := range []string{"one", "two", "three", "four"} {
+		msg := receive(t, received)
+		if string(msg.Data) != want {
+			t.Fatalf("expected %q, got %q", want, msg.Data)
+		}
+		if last != "" {
+			if cmp, _ := CompareIDs(last, msg.ID); cmp >= 0 {
+				t.Errorf("ID %s delivered after %s", msg.ID, last)
+			}
+		}
+		last = msg.ID
+	}
+	waitPending(t, bus, "iot:devices", cfg.Group)
+}
+
+func TestRedis_PublishTrimsStream(t *testing.T) {
+	s := miniredis.RunT(t)
+	ctx := context.Background()
+	bus := newTestRedis(t, s, RedisConfig{MaxLen: 10})
+
+	for i := 0; i < 25; i++ {
+		if err := bus.Publish(ctx, "telemetry.dev-1", []byte(strconv.Itoa(i))); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+	// miniredis trims exactly; Redis keeps at least MaxLen entries
+	entries, err := bus.client.XRange(ctx, "iot:telemetry", "-", "+").Result()
+	if err != nil {
+		t.Fatalf("XRANGE failed: %v", err)
+	}
+	if len(entries) != 10 || streamMessage(entries[0]).Subject != "telemetry.dev-1" || string(streamMessage(entries[0]).Data) != "15" {
+		t.Errorf("expected the last 10 entries, got %d starting with %v", len(entries), entries[0].Values)
+	}
+}
+
+func TestRedis_PubSubModeDoesNotAppend(t *testing.T) {
+	s := miniredis.RunT(t)
+	ctx := context.Background()
+	bus := newTestRedis(t, s, RedisConfig{Mode: RedisPubSub})
+
+	if err := bus.Publish(ctx, "telemetry.dev-1", []byte("live")); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if s.Exists("iot:telemetry") {
+		t.Error("pubsub mode must not append to the stream")
+	}
+	if err := bus.Replay(ctx, "telemetry.dev-1", "0-0", func(*Message) error { return nil }); !errors.Is(err, ErrReplayUnsupported) {
+		t.Errorf("expected ErrReplayUnsupported, got %v", err)
+	}
+}
+
+// TestRedis_ReplayAfterID resumes from an event ID over several XRANGE pages
+func TestRedis_ReplayAfterID(t *testing.T) {
+	s := miniredis.RunT(t)
+	ctx := context.Background()
+	bus := newTestRedis(t, s, RedisConfig{})
+
+	for i := 0; i < redisReplayPageSize+100; i++ {
+		device := fmt.Sprintf("dev-%d", i%2)
+		if err := bus.Publish(ctx, Subject(SubjectTelemetry, device), []byte(strconv.Itoa(i))); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+	entries, err := bus.client.XRangeN(ctx, "iot:telemetry", "-", "+", 2).Result()
+	if err != nil {
+		t.Fatalf("XRANGE failed: %v", err)
+	}
+	afterID := entries[1].ID // "1", published on dev-1
+
+	var got []string
+	err = bus.Replay(ctx, "telemetry.dev-1", afterID, func(msg *Message) error {
+		if msg.Subject != "telemetry.dev-1" {
+			t.Fatalf("Unexpected subject %s", msg.Subject)
+		}
+		if cmp, _ := CompareIDs(afterID, msg.ID); cmp >= 0 {
+			t.Fatalf("ID %s replayed after %s", msg.ID, afterID)
+		}
+		got = append(got, string(msg.Data))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if len(got) != (redisReplayPageSize+100)/2-1 || got[0] != "3" || got[len(got)-1] != strconv.Itoa(redisReplayPageSize+99) {
+		t.Errorf("expected dev-1 events after %s, got %d from %v", afterID, len(got), got[:1])
+	}
+
+	// The callback error stops the replay
+	stop := errors.New("stop")
+	if err := bus.Replay(ctx, "telemetry.>", afterID, func(*Message) error { return stop }); !errors.Is(err, stop) {
+		t.Errorf("expected the callback error, got %v", err)
+	}
+	if err := bus.Replay(ctx, "telemetry.dev-1", "not-an-id", func(*Message) error { return nil }); !errors.Is(err, ErrInvalidPattern) {
+		t.Errorf("expected ErrInvalidPattern, got %v", err)
+	}
+}
+
+// TestRedis_SubscribePrunesStaleGroups checks that a replica starting with a
+// new group destroys the groups of the replicas gone for StaleGroupIdle
+func TestRedis_SubscribePrunesStaleGroups(t *testing.T) {
+	s := miniredis.RunT(t)
+	ctx := context.Background()
+	start := time.Now()
+	s.SetTime(start)
+	bus := newTestRedis(t, s, RedisConfig{Group: "api-gateway-new", Consumer: "new", StaleGroupPrefix: "api-gateway-"})
+
+	if err := bus.Publish(ctx, "telemetry.dev-1", []byte("pending")); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	// gone and alive read at start, alive again later; empty never read;
+	// device-manager has another prefix
+	read := map[string]time.Time{
+		"api-gateway-gone":  start,
+		"api-gateway-alive": start.Add(90 * time.Minute),
+		"api-gateway-empty": {},
+		"device-manager":    start,
+	}
+	for group, at := range read {
+		if err := bus.client.XGroupCreateMkStream(ctx, "iot:telemetry", group, "0").Err(); err != nil {
+			t.Fatalf("XGROUP CREATE failed: %v", err)
+		}
+		if at.IsZero() {
+			continue
+		}
+		s.SetTime(at)
+		streams, err := bus.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group: group, Consumer: "c", Streams: []string{"iot:telemetry", ">"},
+		}).Result()
+		if err != nil {
+			t.Fatalf("XREADGROUP failed: %v", err)
+		}
+		// miniredis only records consumer activity on XCLAIM
+		err = bus.client.XClaim(ctx, &redis.XClaimArgs{
+			Stream: "iot:telemetry", Group: group, Consumer: "c", Messages: []string{streams[0].Messages[0].ID},
+		}).Err()
+		if err != nil {
+			t.Fatalf("XCLAIM failed: %v", err)
+		}
+	}
+
+	s.SetTime(start.Add(2 * time.Hour))
+	if err := bus.Subscribe(ctx, "telemetry.>", func(*Message) {}); err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	groups, err := bus.client.XInfoGroups(ctx, "iot:telemetry").Result()
+	if err != nil {
+		t.Fatalf("XINFO GROUPS failed: %v", err)
+	}
+	remaining := make(map[string]bool)
+	for _, group := range groups {
+		remaining[group.Name] = true
+	}
+	for _, group := range []string{"api-gateway-new", "api-gateway-alive", "api-gateway-empty", "device-manager"} {
+		if !remaining[group] {
+			t.Errorf("group %s must be kept", group)
+		}
+	}
+	if remaining["api-gateway-gone"] {
+		t.Error("stale group api-gateway-gone not destroyed")
+	}
+}
+
+// subjectReceiver records the subjects delivered to a subject subscription.
+type subjectReceiver struct {
+	mu       sync.Mutex
+	received []*Message
+}
+
+func (r *subjectReceiver) handle(msg *Message) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.received = append(r.received, msg)
+}
+
+// take waits until n messages were received, then returns and clears them.
+func (r *subjectReceiver) take(t *testing.T, n int) []*Message {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		r.mu.Lock()
+		received := r.received
+		if len(received) >= n || time.Now().After(deadline) {
+			r.received = nil
+			r.mu.Unlock()
+			if len(received) != n {
+				t.Fatalf("expected %d messages, got %s", n, subjects(received))
+			}
+			return received
+		}
+		r.mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// subjects lists the subjects of messages.
+func subjects(messages []*Message) string {
+	var out []string
+	for _, msg := range messages {
+		out = append(out, msg.Subject)
+	}
+	return strings.Join(out, ",")
+}
+
+// TestRedis_SubscribeSubjects checks with the default transport (streams)
+// that two replicas only receive the subjects of their own set.
+func TestRedis_SubscribeSubjects(t *testing.T) {
+	s := miniredis.RunT(t)
+	ctx := context.Background()
+	publisher := newTestRedis(t, s, RedisConfig{MaxLen: 100})
+	if publisher.cfg.Mode != RedisStreams {
+		t.Fatalf("expected the streams default, got %s", publisher.cfg.Mode)
+	}
+	publish := func(subjects ...string) {
+		t.Helper()
+		for _, subject := range subjects {
+			if err := publisher.Publish(ctx, subject, []byte("x")); err != nil {
+				t.Fatalf("Publish failed: %v", err)
+			}
+		}
+	}
+	// Published before any subscription
+	publish("telemetry.dev-1")
+
+	var replicaA, replicaB subjectReceiver
+	setA, err := newTestRedis(t, s, RedisConfig{}).SubscribeSubjects(ctx, replicaA.handle)
+	if err != nil {
+		t.Fatalf("SubscribeSubjects failed: %v", err)
+	}
+	setB, err := newTestRedis(t, s, RedisConfig{}).SubscribeSubjects(ctx, replicaB.handle)
+	if err != nil {
+		t.Fatalf("SubscribeSubjects failed: %v", err)
+	}
+	if err := setA.Add(ctx, "telemetry.dev-1", "telemetry.dev-2"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := setB.Add(ctx, "telemetry.dev-2"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	publish("telemetry.dev-1", "telemetry.dev-2", "telemetry.dev-3", "devices.dev-1")
+	if got := subjects(replicaA.take(t, 2)); got != "telemetry.dev-1,telemetry.dev-2" {
+		t.Errorf("replica A received %s", got)
+	}
+	if got := subjects(replicaB.take(t, 1)); got != "telemetry.dev-2" {
+		t.Errorf("replica B received %s", got)
+	}
+
+	// Messages keep the ID of the kind's stream, used by Replay
+	publish("telemetry.dev-1")
+	msg := replicaA.take(t, 1)[0]
+	if last, err := lastStreamID(ctx, publisher.client, "iot:telemetry"); err != nil || msg.ID != last {
+		t.Errorf("message ID %s, want %s (%v)", msg.ID, last, err)
+	}
+
+	// Swapping device subjects for a pattern neither loses nor repeats entries
+	if err := setB.Add(ctx, "telemetry.>"); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	publish("telemetry.dev-2", "telemetry.dev-3")
+	if got := subjects(replicaB.take(t, 2)); got != "telemetry.dev-2,telemetry.dev-3" {
+		t.Errorf("replica B received %s", got)
+	}
+	if err := setB.Remove(ctx, "telemetry.>"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	publish("telemetry.dev-2", "telemetry.dev-3")
+	if got := subjects(replicaB.take(t, 1)); got != "telemetry.dev-2" {
+		t.Errorf("replica B received %s", got)
+	}
+	if got := subjects(replicaA.take(t, 2)); got != "telemetry.dev-2,telemetry.dev-2" {
+		t.Errorf("replica A received %s", got)
+	}
+
+	// Removed subjects are no longer received
+	if err := setA.Remove(ctx, "telemetry.dev-1", "telemetry.dev-2"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	publish("telemetry.dev-1", "telemetry.dev-2")
+	replicaB.take(t, 1)
+	time.Sleep(2 * redisSubjectBlock)
+	replicaA.take(t, 0)
+}
+
+func TestRedis_PublishCopiesToSubjectStream(t *testing.T) {
+	s := miniredis.RunT(t)
+	ctx := context.Background()
+	bus := newTestRedis(t, s, RedisConfig{})
+
+	for i := 0; i < redisSubjectMaxLen+10; i++ {
+		if err := bus.Publish(ctx, "telemetry.dev-1", []byte(strconv.Itoa(i))); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+	kindEntries, _ := bus.client.XRevRangeN(ctx, "iot:telemetry", "+", "-", 1).Result()
+	subjectEntries, _ := bus.client.XRevRangeN(ctx, "iot:telemetry:dev-1", "+", "-", 1).Result()
+	if len(kindEntries) != 1 || len(subjectEntries) != 1 || kindEntries[0].ID != subjectEntries[0].ID {
+		t.Errorf("subject stream entry %v, want the ID of %v", subjectEntries, kindEntries)
+	}
+	if n := bus.client.XLen(ctx, "iot:telemetry").Val(); n != redisSubjectMaxLen+10 {
+		t.Errorf("kind stream must not be trimmed without MaxLen, has %d entries", n)
+	}
+	if n := bus.client.XLen(ctx, "iot:telemetry:dev-1").Val(); n != redisSubjectMaxLen {
+		t.Errorf("subject stream has %d entries, want %d", n, redisSubjectMaxLen)
+	}
+	if ttl := s.TTL("iot:telemetry:dev-1"); ttl != redisSubjectTTL {
+		t.Errorf("subject stream TTL %s, want %s", ttl, redisSubjectTTL)
+	}
+}
@@ -0,0 +1,44 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrSubjectsUnsupported is returned by SubscribeSubjects when the transport,
+// or its mode, does not route messages by subject.
+var ErrSubjectsUnsupported = errors.New("event bus transport does not route subjects")
+
+// SubjectSubscriber is implemented by transports that route messages by
+// subject: a subject subscription only transfers the subjects it holds, so a
+// consumer interested in a few devices does not receive the traffic of the
+// others. NATS, Redis (subject streams, or channels in pubsub mode) and
+// Memory implement it.
+type SubjectSubscriber interface {
+	// SubscribeSubjects calls handler for every message published on the
+	// subjects or patterns later added to the returned set, until ctx is
+	// cancelled or the bus is closed. Consumer groups do not apply, and
+	// messages published while a subject is being added may be missed.
+	// Message IDs are set by transports with history (NATS, Redis streams).
+	SubscribeSubjects(ctx context.Context, handler Handler) (SubjectSet, error)
+}
+
+// SubjectSet is the changing set of subjects and patterns of a subject
+// subscription. A message matching several entries may be delivered once per
+// matching entry.
+type SubjectSet interface {
+	// Add starts receiving the messages of subjects.
+	Add(ctx context.Context, subjects ...string) error
+	// Remove stops receiving the messages of subjects.
+	Remove(ctx context.Context, subjects ...string) error
+}
+
+// validSubjects checks that subjects are valid subjects or patterns.
+func validSubjects(subjects []string) error {
+	for _, subject := range subjects {
+		if _, err := kind(subject); err != nil {
+			return err
+		}
+	}
+	return nil
+}
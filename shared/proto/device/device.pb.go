@@ -81,6 +81,206 @@ func (DeviceStatus) EnumDescriptor() ([]byte, []int) {
 	return file_device_device_proto_rawDescGZIP(), []int{0}
 }
 
+// Type de valeur d'une métrique
+type MetricDataType int32
+
+const (
+	MetricDataType_METRIC_FLOAT   MetricDataType = 0 // Nombre réel
+	MetricDataType_METRIC_INTEGER MetricDataType = 1 // Nombre entier
+	MetricDataType_METRIC_BOOLEAN MetricDataType = 2 // 0 ou 1
+)
+
+// Enum value maps for MetricDataType.
+var (
+	MetricDataType_name = map[int32]string{
+		0: "METRIC_FLOAT",
+		1: "METRIC_INTEGER",
+		2: "METRIC_BOOLEAN",
+	}
+	MetricDataType_value = map[string]int32{
+		"METRIC_FLOAT":   0,
+		"METRIC_INTEGER": 1,
+		"METRIC_BOOLEAN": 2,
+	}
+)
+
+func (x MetricDataType) Enum() *MetricDataType {
+	p := new(MetricDataType)
+	*p = x
+	return p
+}
+
+func (x MetricDataType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MetricDataType) Descriptor() protoreflect.EnumDescriptor {
+	return file_device_device_proto_enumTypes[1].Descriptor()
+}
+
+func (MetricDataType) Type() protoreflect.EnumType {
+	return &file_device_device_proto_enumTypes[1]
+}
+
+func (x MetricDataType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MetricDataType.Descriptor instead.
+func (MetricDataType) EnumDescriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{1}
+}
+
+// Niveau d'un emplacement dans la hiérarchie site > bâtiment > étage > pièce
+type LocationKind int32
+
+const (
+	LocationKind_LOCATION_SITE     LocationKind = 0 // Site (racine)
+	LocationKind_LOCATION_BUILDING LocationKind = 1 // Bâtiment d'un site
+	LocationKind_LOCATION_FLOOR    LocationKind = 2 // Étage d'un bâtiment
+	LocationKind_LOCATION_ROOM     LocationKind = 3 // Pièce d'un étage
+)
+
+// Enum value maps for LocationKind.
+var (
+	LocationKind_name = map[int32]string{
+		0: "LOCATION_SITE",
+		1: "LOCATION_BUILDING",
+		2: "LOCATION_FLOOR",
+		3: "LOCATION_ROOM",
+	}
+	LocationKind_value = map[string]int32{
+		"LOCATION_SITE":     0,
+		"LOCATION_BUILDING": 1,
+		"LOCATION_FLOOR":    2,
+		"LOCATION_ROOM":     3,
+	}
+)
+
+func (x LocationKind) Enum() *LocationKind {
+	p := new(LocationKind)
+	*p = x
+	return p
+}
+
+func (x LocationKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LocationKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_device_device_proto_enumTypes[2].Descriptor()
+}
+
+func (LocationKind) Type() protoreflect.EnumType {
+	return &file_device_device_proto_enumTypes[2]
+}
+
+func (x LocationKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LocationKind.Descriptor instead.
+func (LocationKind) EnumDescriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{2}
+}
+
+// Type d'appartenance à un groupe
+type DeviceGroupKind int32
+
+const (
+	DeviceGroupKind_GROUP_STATIC  DeviceGroupKind = 0 // Liste explicite de devices
+	DeviceGroupKind_GROUP_DYNAMIC DeviceGroupKind = 1 // Devices correspondant à une règle
+)
+
+// Enum value maps for DeviceGroupKind.
+var (
+	DeviceGroupKind_name = map[int32]string{
+		0: "GROUP_STATIC",
+		1: "GROUP_DYNAMIC",
+	}
+	DeviceGroupKind_value = map[string]int32{
+		"GROUP_STATIC":  0,
+		"GROUP_DYNAMIC": 1,
+	}
+)
+
+func (x DeviceGroupKind) Enum() *DeviceGroupKind {
+	p := new(DeviceGroupKind)
+	*p = x
+	return p
+}
+
+func (x DeviceGroupKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DeviceGroupKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_device_device_proto_enumTypes[3].Descriptor()
+}
+
+func (DeviceGroupKind) Type() protoreflect.EnumType {
+	return &file_device_device_proto_enumTypes[3]
+}
+
+func (x DeviceGroupKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DeviceGroupKind.Descriptor instead.
+func (DeviceGroupKind) EnumDescriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{3}
+}
+
+// Niveau d'accès d'une entrée d'ACL (write et command impliquent read)
+type AccessLevel int32
+
+const (
+	AccessLevel_ACCESS_READ    AccessLevel = 0
+	AccessLevel_ACCESS_WRITE   AccessLevel = 1
+	AccessLevel_ACCESS_COMMAND AccessLevel = 2
+)
+
+// Enum value maps for AccessLevel.
+var (
+	AccessLevel_name = map[int32]string{
+		0: "ACCESS_READ",
+		1: "ACCESS_WRITE",
+		2: "ACCESS_COMMAND",
+	}
+	AccessLevel_value = map[string]int32{
+		"ACCESS_READ":    0,
+		"ACCESS_WRITE":   1,
+		"ACCESS_COMMAND": 2,
+	}
+)
+
+func (x AccessLevel) Enum() *AccessLevel {
+	p := new(AccessLevel)
+	*p = x
+	return p
+}
+
+func (x AccessLevel) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AccessLevel) Descriptor() protoreflect.EnumDescriptor {
+	return file_device_device_proto_enumTypes[4].Descriptor()
+}
+
+func (AccessLevel) Type() protoreflect.EnumType {
+	return &file_device_device_proto_enumTypes[4]
+}
+
+func (x AccessLevel) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AccessLevel.Descriptor instead.
+func (AccessLevel) EnumDescriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{4}
+}
+
 // Représente un appareil IoT
 type Device struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -91,6 +291,8 @@ type Device struct {
 	CreatedAt     int64                  `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`                                                       // Timestamp de création (Unix timestamp)
 	LastSeen      int64                  `protobuf:"varint,6,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`                                                          // Dernière fois vu en ligne
 	Metadata      map[string]string      `protobuf:"bytes,7,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Métadonnées additionnelles (clé-valeur)
+	OrgId         string                 `protobuf:"bytes,8,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`                                                                    // Organisation propriétaire du device
+	LocationId    string                 `protobuf:"bytes,9,opt,name=location_id,json=locationId,proto3" json:"location_id,omitempty"`                                                     // Emplacement du device (vide: aucun)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -174,12 +376,28 @@ func (x *Device) GetMetadata() map[string]string {
 	return nil
 }
 
+func (x *Device) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *Device) GetLocationId() string {
+	if x != nil {
+		return x.LocationId
+	}
+	return ""
+}
+
 // Requête pour créer un device
 type CreateDeviceRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
 	Metadata      map[string]string      `protobuf:"bytes,3,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	OrgId         string                 `protobuf:"bytes,4,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`                // Organisation propriétaire (obligatoire)
+	LocationId    string                 `protobuf:"bytes,5,opt,name=location_id,json=locationId,proto3" json:"location_id,omitempty"` // Emplacement (optionnel)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -235,6 +453,20 @@ func (x *CreateDeviceRequest) GetMetadata() map[string]string {
 	return nil
 }
 
+func (x *CreateDeviceRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *CreateDeviceRequest) GetLocationId() string {
+	if x != nil {
+		return x.LocationId
+	}
+	return ""
+}
+
 // Réponse après création
 type CreateDeviceResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -284,6 +516,7 @@ func (x *CreateDeviceResponse) GetDevice() *Device {
 type GetDeviceRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	OrgId         string                 `protobuf:"bytes,2,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"` // Organisation de l'appelant (vide: toutes, appels internes)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -325,6 +558,13 @@ func (x *GetDeviceRequest) GetId() string {
 	return ""
 }
 
+func (x *GetDeviceRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
 // Réponse avec un device
 type GetDeviceResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -377,6 +617,10 @@ type ListDevicesRequest struct {
 	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`      // Taille de page (défaut: 20)
 	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`                               // Filtrer par type (optionnel)
 	Status        DeviceStatus           `protobuf:"varint,4,opt,name=status,proto3,enum=device.DeviceStatus" json:"status,omitempty"` // Filtrer par statut (optionnel)
+	OrgId         string                 `protobuf:"bytes,5,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`                // Organisation de l'appelant (vide: toutes, appels internes)
+	GroupId       string                 `protobuf:"bytes,6,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`          // Filtrer par groupe (optionnel)
+	LocationId    string                 `protobuf:"bytes,7,opt,name=location_id,json=locationId,proto3" json:"location_id,omitempty"` // Filtrer par emplacement, sous-emplacements inclus (optionnel)
+	Subject       *AccessSubject         `protobuf:"bytes,8,opt,name=subject,proto3" json:"subject,omitempty"`                         // Restreindre aux devices autorisés par les ACL (optionnel)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -439,6 +683,34 @@ func (x *ListDevicesRequest) GetStatus() DeviceStatus {
 	return DeviceStatus_UNKNOWN
 }
 
+func (x *ListDevicesRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *ListDevicesRequest) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *ListDevicesRequest) GetLocationId() string {
+	if x != nil {
+		return x.LocationId
+	}
+	return ""
+}
+
+func (x *ListDevicesRequest) GetSubject() *AccessSubject {
+	if x != nil {
+		return x.Subject
+	}
+	return nil
+}
+
 // Réponse avec une liste de devices
 type ListDevicesResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -515,6 +787,8 @@ type UpdateDeviceRequest struct {
 	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
 	Status        DeviceStatus           `protobuf:"varint,3,opt,name=status,proto3,enum=device.DeviceStatus" json:"status,omitempty"`
 	Metadata      map[string]string      `protobuf:"bytes,4,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	OrgId         string                 `protobuf:"bytes,5,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`                      // Organisation de l'appelant (vide: toutes, appels internes)
+	LocationId    *string                `protobuf:"bytes,6,opt,name=location_id,json=locationId,proto3,oneof" json:"location_id,omitempty"` // Nouvel emplacement (absent: inchangé, vide: aucun)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -577,6 +851,20 @@ func (x *UpdateDeviceRequest) GetMetadata() map[string]string {
 	return nil
 }
 
+func (x *UpdateDeviceRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *UpdateDeviceRequest) GetLocationId() string {
+	if x != nil && x.LocationId != nil {
+		return *x.LocationId
+	}
+	return ""
+}
+
 // Réponse après mise à jour
 type UpdateDeviceResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -626,6 +914,7 @@ func (x *UpdateDeviceResponse) GetDevice() *Device {
 type DeleteDeviceRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	OrgId         string                 `protobuf:"bytes,2,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"` // Organisation de l'appelant (vide: toutes, appels internes)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -667,6 +956,13 @@ func (x *DeleteDeviceRequest) GetId() string {
 	return ""
 }
 
+func (x *DeleteDeviceRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
 // Réponse après suppression
 type DeleteDeviceResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -720,27 +1016,34 @@ func (x *DeleteDeviceResponse) GetMessage() string {
 	return ""
 }
 
-// Message vide (pour les requêtes sans paramètres)
-type Empty struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+// Métrique attendue pour un type de device
+type MetricDefinition struct {
+	state                    protoimpl.MessageState `protogen:"open.v1"`
+	Name                     string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`                                  // Nom de la métrique (ex: "temperature")
+	DisplayName              string                 `protobuf:"bytes,2,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"` // Libellé (ex: "Température")
+	Unit                     string                 `protobuf:"bytes,3,opt,name=unit,proto3" json:"unit,omitempty"`                                  // Unité (ex: "celsius")
+	DataType                 MetricDataType         `protobuf:"varint,4,opt,name=data_type,json=dataType,proto3,enum=device.MetricDataType" json:"data_type,omitempty"`
+	MinValue                 *float64               `protobuf:"fixed64,5,opt,name=min_value,json=minValue,proto3,oneof" json:"min_value,omitempty"`                                            // Valeur minimale valide (optionnel)
+	MaxValue                 *float64               `protobuf:"fixed64,6,opt,name=max_value,json=maxValue,proto3,oneof" json:"max_value,omitempty"`                                            // Valeur maximale valide (optionnel)
+	ReportingIntervalSeconds int32                  `protobuf:"varint,7,opt,name=reporting_interval_seconds,json=reportingIntervalSeconds,proto3" json:"reporting_interval_seconds,omitempty"` // Intervalle d'envoi attendu (0: inconnu)
+	unknownFields            protoimpl.UnknownFields
+	sizeCache                protoimpl.SizeCache
 }
 
-func (x *Empty) Reset() {
-	*x = Empty{}
+func (x *MetricDefinition) Reset() {
+	*x = MetricDefinition{}
 	mi := &file_device_device_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Empty) String() string {
+func (x *MetricDefinition) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Empty) ProtoMessage() {}
+func (*MetricDefinition) ProtoMessage() {}
 
-func (x *Empty) ProtoReflect() protoreflect.Message {
+func (x *MetricDefinition) ProtoReflect() protoreflect.Message {
 	mi := &file_device_device_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -752,66 +1055,2856 @@ func (x *Empty) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
-func (*Empty) Descriptor() ([]byte, []int) {
+// Deprecated: Use MetricDefinition.ProtoReflect.Descriptor instead.
+func (*MetricDefinition) Descriptor() ([]byte, []int) {
 	return file_device_device_proto_rawDescGZIP(), []int{11}
 }
 
-var File_device_device_proto protoreflect.FileDescriptor
+func (x *MetricDefinition) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
 
-const file_device_device_proto_rawDesc = "" +
-	"\n" +
-	"\x13device/device.proto\x12\x06device\"\xa1\x02\n" +
-	"\x06Device\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
-	"\x04type\x18\x03 \x01(\tR\x04type\x12,\n" +
-	"\x06status\x18\x04 \x01(\x0e2\x14.device.DeviceStatusR\x06status\x12\x1d\n" +
-	"\n" +
-	"created_at\x18\x05 \x01(\x03R\tcreatedAt\x12\x1b\n" +
-	"\tlast_seen\x18\x06 \x01(\x03R\blastSeen\x128\n" +
-	"\bmetadata\x18\a \x03(\v2\x1c.device.Device.MetadataEntryR\bmetadata\x1a;\n" +
-	"\rMetadataEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc1\x01\n" +
-	"\x13CreateDeviceRequest\x12\x12\n" +
-	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
-	"\x04type\x18\x02 \x01(\tR\x04type\x12E\n" +
-	"\bmetadata\x18\x03 \x03(\v2).device.CreateDeviceRequest.MetadataEntryR\bmetadata\x1a;\n" +
-	"\rMetadataEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\">\n" +
-	"\x14CreateDeviceResponse\x12&\n" +
-	"\x06device\x18\x01 \x01(\v2\x0e.device.DeviceR\x06device\"\"\n" +
-	"\x10GetDeviceRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\";\n" +
-	"\x11GetDeviceResponse\x12&\n" +
-	"\x06device\x18\x01 \x01(\v2\x0e.device.DeviceR\x06device\"\x87\x01\n" +
-	"\x12ListDevicesRequest\x12\x12\n" +
-	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x1b\n" +
-	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x12\n" +
-	"\x04type\x18\x03 \x01(\tR\x04type\x12,\n" +
-	"\x06status\x18\x04 \x01(\x0e2\x14.device.DeviceStatusR\x06status\"\x86\x01\n" +
-	"\x13ListDevicesResponse\x12(\n" +
-	"\adevices\x18\x01 \x03(\v2\x0e.device.DeviceR\adevices\x12\x14\n" +
-	"\x05total\x18\x02 \x01(\x05R\x05total\x12\x12\n" +
-	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x1b\n" +
-	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\"\xeb\x01\n" +
-	"\x13UpdateDeviceRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\x12,\n" +
-	"\x06status\x18\x03 \x01(\x0e2\x14.device.DeviceStatusR\x06status\x12E\n" +
-	"\bmetadata\x18\x04 \x03(\v2).device.UpdateDeviceRequest.MetadataEntryR\bmetadata\x1a;\n" +
-	"\rMetadataEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\">\n" +
-	"\x14UpdateDeviceResponse\x12&\n" +
-	"\x06device\x18\x01 \x01(\v2\x0e.device.DeviceR\x06device\"%\n" +
-	"\x13DeleteDeviceRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\tR\x02id\"J\n" +
-	"\x14DeleteDeviceResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"\a\n" +
+func (x *MetricDefinition) GetDisplayName() string {
+	if x != nil {
+		return x.DisplayName
+	}
+	return ""
+}
+
+func (x *MetricDefinition) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *MetricDefinition) GetDataType() MetricDataType {
+	if x != nil {
+		return x.DataType
+	}
+	return MetricDataType_METRIC_FLOAT
+}
+
+func (x *MetricDefinition) GetMinValue() float64 {
+	if x != nil && x.MinValue != nil {
+		return *x.MinValue
+	}
+	return 0
+}
+
+func (x *MetricDefinition) GetMaxValue() float64 {
+	if x != nil && x.MaxValue != nil {
+		return *x.MaxValue
+	}
+	return 0
+}
+
+func (x *MetricDefinition) GetReportingIntervalSeconds() int32 {
+	if x != nil {
+		return x.ReportingIntervalSeconds
+	}
+	return 0
+}
+
+// Type de device déclaré dans le registre
+type DeviceType struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // Identifiant, utilisé dans Device.type
+	DisplayName   string                 `protobuf:"bytes,2,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Metrics       []*MetricDefinition    `protobuf:"bytes,4,rep,name=metrics,proto3" json:"metrics,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     int64                  `protobuf:"varint,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeviceType) Reset() {
+	*x = DeviceType{}
+	mi := &file_device_device_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceType) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceType) ProtoMessage() {}
+
+func (x *DeviceType) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceType.ProtoReflect.Descriptor instead.
+func (*DeviceType) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DeviceType) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DeviceType) GetDisplayName() string {
+	if x != nil {
+		return x.DisplayName
+	}
+	return ""
+}
+
+func (x *DeviceType) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *DeviceType) GetMetrics() []*MetricDefinition {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+func (x *DeviceType) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *DeviceType) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+// Requête pour créer ou remplacer un type de device (et son catalogue)
+type UpsertDeviceTypeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceType    *DeviceType            `protobuf:"bytes,1,opt,name=device_type,json=deviceType,proto3" json:"device_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpsertDeviceTypeRequest) Reset() {
+	*x = UpsertDeviceTypeRequest{}
+	mi := &file_device_device_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpsertDeviceTypeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpsertDeviceTypeRequest) ProtoMessage() {}
+
+func (x *UpsertDeviceTypeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpsertDeviceTypeRequest.ProtoReflect.Descriptor instead.
+func (*UpsertDeviceTypeRequest) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *UpsertDeviceTypeRequest) GetDeviceType() *DeviceType {
+	if x != nil {
+		return x.DeviceType
+	}
+	return nil
+}
+
+// Réponse après création ou remplacement
+type UpsertDeviceTypeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceType    *DeviceType            `protobuf:"bytes,1,opt,name=device_type,json=deviceType,proto3" json:"device_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpsertDeviceTypeResponse) Reset() {
+	*x = UpsertDeviceTypeResponse{}
+	mi := &file_device_device_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpsertDeviceTypeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpsertDeviceTypeResponse) ProtoMessage() {}
+
+func (x *UpsertDeviceTypeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpsertDeviceTypeResponse.ProtoReflect.Descriptor instead.
+func (*UpsertDeviceTypeResponse) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *UpsertDeviceTypeResponse) GetDeviceType() *DeviceType {
+	if x != nil {
+		return x.DeviceType
+	}
+	return nil
+}
+
+// Requête pour récupérer un type de device
+type GetDeviceTypeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDeviceTypeRequest) Reset() {
+	*x = GetDeviceTypeRequest{}
+	mi := &file_device_device_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDeviceTypeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeviceTypeRequest) ProtoMessage() {}
+
+func (x *GetDeviceTypeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeviceTypeRequest.ProtoReflect.Descriptor instead.
+func (*GetDeviceTypeRequest) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GetDeviceTypeRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// Réponse avec un type de device
+type GetDeviceTypeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceType    *DeviceType            `protobuf:"bytes,1,opt,name=device_type,json=deviceType,proto3" json:"device_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDeviceTypeResponse) Reset() {
+	*x = GetDeviceTypeResponse{}
+	mi := &file_device_device_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDeviceTypeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeviceTypeResponse) ProtoMessage() {}
+
+func (x *GetDeviceTypeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeviceTypeResponse.ProtoReflect.Descriptor instead.
+func (*GetDeviceTypeResponse) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetDeviceTypeResponse) GetDeviceType() *DeviceType {
+	if x != nil {
+		return x.DeviceType
+	}
+	return nil
+}
+
+// Requête pour lister les types de devices
+type ListDeviceTypesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDeviceTypesRequest) Reset() {
+	*x = ListDeviceTypesRequest{}
+	mi := &file_device_device_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDeviceTypesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDeviceTypesRequest) ProtoMessage() {}
+
+func (x *ListDeviceTypesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDeviceTypesRequest.ProtoReflect.Descriptor instead.
+func (*ListDeviceTypesRequest) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{17}
+}
+
+// Réponse avec la liste des types
+type ListDeviceTypesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceTypes   []*DeviceType          `protobuf:"bytes,1,rep,name=device_types,json=deviceTypes,proto3" json:"device_types,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDeviceTypesResponse) Reset() {
+	*x = ListDeviceTypesResponse{}
+	mi := &file_device_device_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDeviceTypesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDeviceTypesResponse) ProtoMessage() {}
+
+func (x *ListDeviceTypesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDeviceTypesResponse.ProtoReflect.Descriptor instead.
+func (*ListDeviceTypesResponse) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListDeviceTypesResponse) GetDeviceTypes() []*DeviceType {
+	if x != nil {
+		return x.DeviceTypes
+	}
+	return nil
+}
+
+// Requête pour supprimer un type de device
+type DeleteDeviceTypeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDeviceTypeRequest) Reset() {
+	*x = DeleteDeviceTypeRequest{}
+	mi := &file_device_device_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDeviceTypeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDeviceTypeRequest) ProtoMessage() {}
+
+func (x *DeleteDeviceTypeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDeviceTypeRequest.ProtoReflect.Descriptor instead.
+func (*DeleteDeviceTypeRequest) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *DeleteDeviceTypeRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// Réponse après suppression
+type DeleteDeviceTypeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDeviceTypeResponse) Reset() {
+	*x = DeleteDeviceTypeResponse{}
+	mi := &file_device_device_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDeviceTypeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDeviceTypeResponse) ProtoMessage() {}
+
+func (x *DeleteDeviceTypeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDeviceTypeResponse.ProtoReflect.Descriptor instead.
+func (*DeleteDeviceTypeResponse) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *DeleteDeviceTypeResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *DeleteDeviceTypeResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Emplacement d'une organisation
+type Location struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	OrgId         string                 `protobuf:"bytes,2,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	ParentId      string                 `protobuf:"bytes,3,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"` // Emplacement parent (vide pour un site)
+	Kind          LocationKind           `protobuf:"varint,4,opt,name=kind,proto3,enum=device.LocationKind" json:"kind,omitempty"`
+	Name          string                 `protobuf:"bytes,5,opt,name=name,proto3" json:"name,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Location) Reset() {
+	*x = Location{}
+	mi := &file_device_device_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Location) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Location) ProtoMessage() {}
+
+func (x *Location) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Location.ProtoReflect.Descriptor instead.
+func (*Location) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *Location) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Location) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *Location) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+func (x *Location) GetKind() LocationKind {
+	if x != nil {
+		return x.Kind
+	}
+	return LocationKind_LOCATION_SITE
+}
+
+func (x *Location) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Location) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+// Requête pour créer un emplacement
+type CreateLocationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrgId         string                 `protobuf:"bytes,1,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`          // Organisation propriétaire (obligatoire)
+	ParentId      string                 `protobuf:"bytes,2,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"` // Parent du niveau supérieur (obligatoire sauf pour un site)
+	Kind          LocationKind           `protobuf:"varint,3,opt,name=kind,proto3,enum=device.LocationKind" json:"kind,omitempty"`
+	Name          string                 `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateLocationRequest) Reset() {
+	*x = CreateLocationRequest{}
+	mi := &file_device_device_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateLocationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateLocationRequest) ProtoMessage() {}
+
+func (x *CreateLocationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateLocationRequest.ProtoReflect.Descriptor instead.
+func (*CreateLocationRequest) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CreateLocationRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *CreateLocationRequest) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+func (x *CreateLocationRequest) GetKind() LocationKind {
+	if x != nil {
+		return x.Kind
+	}
+	return LocationKind_LOCATION_SITE
+}
+
+func (x *CreateLocationRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// Réponse après création
+type CreateLocationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Location      *Location              `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateLocationResponse) Reset() {
+	*x = CreateLocationResponse{}
+	mi := &file_device_device_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateLocationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateLocationResponse) ProtoMessage() {}
+
+func (x *CreateLocationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateLocationResponse.ProtoReflect.Descriptor instead.
+func (*CreateLocationResponse) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *CreateLocationResponse) GetLocation() *Location {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+// Requête pour récupérer un emplacement
+type GetLocationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	OrgId         string                 `protobuf:"bytes,2,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"` // Organisation de l'appelant (vide: toutes, appels internes)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLocationRequest) Reset() {
+	*x = GetLocationRequest{}
+	mi := &file_device_device_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLocationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLocationRequest) ProtoMessage() {}
+
+func (x *GetLocationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLocationRequest.ProtoReflect.Descriptor instead.
+func (*GetLocationRequest) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GetLocationRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetLocationRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+// Réponse avec un emplacement
+type GetLocationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Location      *Location              `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLocationResponse) Reset() {
+	*x = GetLocationResponse{}
+	mi := &file_device_device_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLocationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLocationResponse) ProtoMessage() {}
+
+func (x *GetLocationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLocationResponse.ProtoReflect.Descriptor instead.
+func (*GetLocationResponse) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *GetLocationResponse) GetLocation() *Location {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+// Requête pour lister les emplacements
+type ListLocationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrgId         string                 `protobuf:"bytes,1,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`          // Organisation de l'appelant (vide: toutes, appels internes)
+	ParentId      string                 `protobuf:"bytes,2,opt,name=parent_id,json=parentId,proto3" json:"parent_id,omitempty"` // Enfants directs de cet emplacement (optionnel, défaut: tous)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLocationsRequest) Reset() {
+	*x = ListLocationsRequest{}
+	mi := &file_device_device_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLocationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLocationsRequest) ProtoMessage() {}
+
+func (x *ListLocationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLocationsRequest.ProtoReflect.Descriptor instead.
+func (*ListLocationsRequest) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ListLocationsRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *ListLocationsRequest) GetParentId() string {
+	if x != nil {
+		return x.ParentId
+	}
+	return ""
+}
+
+// Réponse avec la liste des emplacements
+type ListLocationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Locations     []*Location            `protobuf:"bytes,1,rep,name=locations,proto3" json:"locations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListLocationsResponse) Reset() {
+	*x = ListLocationsResponse{}
+	mi := &file_device_device_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListLocationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListLocationsResponse) ProtoMessage() {}
+
+func (x *ListLocationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListLocationsResponse.ProtoReflect.Descriptor instead.
+func (*ListLocationsResponse) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ListLocationsResponse) GetLocations() []*Location {
+	if x != nil {
+		return x.Locations
+	}
+	return nil
+}
+
+// Requête pour renommer un emplacement
+type UpdateLocationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	OrgId         string                 `protobuf:"bytes,3,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"` // Organisation de l'appelant (vide: toutes, appels internes)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateLocationRequest) Reset() {
+	*x = UpdateLocationRequest{}
+	mi := &file_device_device_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateLocationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateLocationRequest) ProtoMessage() {}
+
+func (x *UpdateLocationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateLocationRequest.ProtoReflect.Descriptor instead.
+func (*UpdateLocationRequest) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *UpdateLocationRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateLocationRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpdateLocationRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+// Réponse après modification
+type UpdateLocationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Location      *Location              `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateLocationResponse) Reset() {
+	*x = UpdateLocationResponse{}
+	mi := &file_device_device_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateLocationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateLocationResponse) ProtoMessage() {}
+
+func (x *UpdateLocationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateLocationResponse.ProtoReflect.Descriptor instead.
+func (*UpdateLocationResponse) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *UpdateLocationResponse) GetLocation() *Location {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+// Requête pour supprimer un emplacement (sans sous-emplacements)
+type DeleteLocationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	OrgId         string                 `protobuf:"bytes,2,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"` // Organisation de l'appelant (vide: toutes, appels internes)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteLocationRequest) Reset() {
+	*x = DeleteLocationRequest{}
+	mi := &file_device_device_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteLocationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteLocationRequest) ProtoMessage() {}
+
+func (x *DeleteLocationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteLocationRequest.ProtoReflect.Descriptor instead.
+func (*DeleteLocationRequest) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *DeleteLocationRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DeleteLocationRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+// Réponse après suppression
+type DeleteLocationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteLocationResponse) Reset() {
+	*x = DeleteLocationResponse{}
+	mi := &file_device_device_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteLocationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteLocationResponse) ProtoMessage() {}
+
+func (x *DeleteLocationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteLocationResponse.ProtoReflect.Descriptor instead.
+func (*DeleteLocationResponse) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *DeleteLocationResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *DeleteLocationResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Règle d'un groupe dynamique : les devices du type donné (tous si vide)
+// dont les métadonnées contiennent toutes les entrées données
+type DeviceGroupRule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Metadata      map[string]string      `protobuf:"bytes,2,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeviceGroupRule) Reset() {
+	*x = DeviceGroupRule{}
+	mi := &file_device_device_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceGroupRule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceGroupRule) ProtoMessage() {}
+
+func (x *DeviceGroupRule) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceGroupRule.ProtoReflect.Descriptor instead.
+func (*DeviceGroupRule) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *DeviceGroupRule) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *DeviceGroupRule) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+// Groupe de devices d'une organisation
+type DeviceGroup struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	OrgId         string                 `protobuf:"bytes,2,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	Kind          DeviceGroupKind        `protobuf:"varint,5,opt,name=kind,proto3,enum=device.DeviceGroupKind" json:"kind,omitempty"`
+	Rule          *DeviceGroupRule       `protobuf:"bytes,6,opt,name=rule,proto3" json:"rule,omitempty"`                                   // Groupes dynamiques uniquement
+	DeviceCount   int32                  `protobuf:"varint,7,opt,name=device_count,json=deviceCount,proto3" json:"device_count,omitempty"` // Nombre de devices du groupe
+	CreatedAt     int64                  `protobuf:"varint,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     int64                  `protobuf:"varint,9,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeviceGroup) Reset() {
+	*x = DeviceGroup{}
+	mi := &file_device_device_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceGroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceGroup) ProtoMessage() {}
+
+func (x *DeviceGroup) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceGroup.ProtoReflect.Descriptor instead.
+func (*DeviceGroup) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *DeviceGroup) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DeviceGroup) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *DeviceGroup) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DeviceGroup) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *DeviceGroup) GetKind() DeviceGroupKind {
+	if x != nil {
+		return x.Kind
+	}
+	return DeviceGroupKind_GROUP_STATIC
+}
+
+func (x *DeviceGroup) GetRule() *DeviceGroupRule {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
+func (x *DeviceGroup) GetDeviceCount() int32 {
+	if x != nil {
+		return x.DeviceCount
+	}
+	return 0
+}
+
+func (x *DeviceGroup) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *DeviceGroup) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+// Requête pour créer un groupe
+type CreateDeviceGroupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrgId         string                 `protobuf:"bytes,1,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"` // Organisation propriétaire (obligatoire)
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`                // Nom, unique dans l'organisation
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Kind          DeviceGroupKind        `protobuf:"varint,4,opt,name=kind,proto3,enum=device.DeviceGroupKind" json:"kind,omitempty"`
+	Rule          *DeviceGroupRule       `protobuf:"bytes,5,opt,name=rule,proto3" json:"rule,omitempty"` // Obligatoire pour un groupe dynamique
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateDeviceGroupRequest) Reset() {
+	*x = CreateDeviceGroupRequest{}
+	mi := &file_device_device_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDeviceGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDeviceGroupRequest) ProtoMessage() {}
+
+func (x *CreateDeviceGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDeviceGroupRequest.ProtoReflect.Descriptor instead.
+func (*CreateDeviceGroupRequest) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *CreateDeviceGroupRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *CreateDeviceGroupRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateDeviceGroupRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateDeviceGroupRequest) GetKind() DeviceGroupKind {
+	if x != nil {
+		return x.Kind
+	}
+	return DeviceGroupKind_GROUP_STATIC
+}
+
+func (x *CreateDeviceGroupRequest) GetRule() *DeviceGroupRule {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
+// Réponse après création
+type CreateDeviceGroupResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Group         *DeviceGroup           `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateDeviceGroupResponse) Reset() {
+	*x = CreateDeviceGroupResponse{}
+	mi := &file_device_device_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDeviceGroupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDeviceGroupResponse) ProtoMessage() {}
+
+func (x *CreateDeviceGroupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDeviceGroupResponse.ProtoReflect.Descriptor instead.
+func (*CreateDeviceGroupResponse) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *CreateDeviceGroupResponse) GetGroup() *DeviceGroup {
+	if x != nil {
+		return x.Group
+	}
+	return nil
+}
+
+// Requête pour récupérer un groupe
+type GetDeviceGroupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	OrgId         string                 `protobuf:"bytes,2,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"` // Organisation de l'appelant (vide: toutes, appels internes)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDeviceGroupRequest) Reset() {
+	*x = GetDeviceGroupRequest{}
+	mi := &file_device_device_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDeviceGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeviceGroupRequest) ProtoMessage() {}
+
+func (x *GetDeviceGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeviceGroupRequest.ProtoReflect.Descriptor instead.
+func (*GetDeviceGroupRequest) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *GetDeviceGroupRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetDeviceGroupRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+// Réponse avec un groupe
+type GetDeviceGroupResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Group         *DeviceGroup           `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDeviceGroupResponse) Reset() {
+	*x = GetDeviceGroupResponse{}
+	mi := &file_device_device_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDeviceGroupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDeviceGroupResponse) ProtoMessage() {}
+
+func (x *GetDeviceGroupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDeviceGroupResponse.ProtoReflect.Descriptor instead.
+func (*GetDeviceGroupResponse) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *GetDeviceGroupResponse) GetGroup() *DeviceGroup {
+	if x != nil {
+		return x.Group
+	}
+	return nil
+}
+
+// Requête pour lister les groupes
+type ListDeviceGroupsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrgId         string                 `protobuf:"bytes,1,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`          // Organisation de l'appelant (vide: toutes, appels internes)
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"` // Groupes contenant ce device (optionnel)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDeviceGroupsRequest) Reset() {
+	*x = ListDeviceGroupsRequest{}
+	mi := &file_device_device_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDeviceGroupsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDeviceGroupsRequest) ProtoMessage() {}
+
+func (x *ListDeviceGroupsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDeviceGroupsRequest.ProtoReflect.Descriptor instead.
+func (*ListDeviceGroupsRequest) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *ListDeviceGroupsRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *ListDeviceGroupsRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+// Réponse avec la liste des groupes
+type ListDeviceGroupsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Groups        []*DeviceGroup         `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDeviceGroupsResponse) Reset() {
+	*x = ListDeviceGroupsResponse{}
+	mi := &file_device_device_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDeviceGroupsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDeviceGroupsResponse) ProtoMessage() {}
+
+func (x *ListDeviceGroupsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDeviceGroupsResponse.ProtoReflect.Descriptor instead.
+func (*ListDeviceGroupsResponse) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *ListDeviceGroupsResponse) GetGroups() []*DeviceGroup {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+// Requête pour modifier un groupe (le type d'appartenance est immuable)
+type UpdateDeviceGroupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`                     // Nouveau nom (vide: inchangé)
+	Description   *string                `protobuf:"bytes,3,opt,name=description,proto3,oneof" json:"description,omitempty"` // Nouvelle description (absent: inchangée)
+	Rule          *DeviceGroupRule       `protobuf:"bytes,4,opt,name=rule,proto3" json:"rule,omitempty"`                     // Nouvelle règle d'un groupe dynamique (absent: inchangée)
+	OrgId         string                 `protobuf:"bytes,5,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`      // Organisation de l'appelant (vide: toutes, appels internes)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateDeviceGroupRequest) Reset() {
+	*x = UpdateDeviceGroupRequest{}
+	mi := &file_device_device_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateDeviceGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateDeviceGroupRequest) ProtoMessage() {}
+
+func (x *UpdateDeviceGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateDeviceGroupRequest.ProtoReflect.Descriptor instead.
+func (*UpdateDeviceGroupRequest) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *UpdateDeviceGroupRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateDeviceGroupRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpdateDeviceGroupRequest) GetDescription() string {
+	if x != nil && x.Description != nil {
+		return *x.Description
+	}
+	return ""
+}
+
+func (x *UpdateDeviceGroupRequest) GetRule() *DeviceGroupRule {
+	if x != nil {
+		return x.Rule
+	}
+	return nil
+}
+
+func (x *UpdateDeviceGroupRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+// Réponse après modification
+type UpdateDeviceGroupResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Group         *DeviceGroup           `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateDeviceGroupResponse) Reset() {
+	*x = UpdateDeviceGroupResponse{}
+	mi := &file_device_device_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateDeviceGroupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateDeviceGroupResponse) ProtoMessage() {}
+
+func (x *UpdateDeviceGroupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateDeviceGroupResponse.ProtoReflect.Descriptor instead.
+func (*UpdateDeviceGroupResponse) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *UpdateDeviceGroupResponse) GetGroup() *DeviceGroup {
+	if x != nil {
+		return x.Group
+	}
+	return nil
+}
+
+// Requête pour supprimer un groupe (les devices sont conservés)
+type DeleteDeviceGroupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	OrgId         string                 `protobuf:"bytes,2,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"` // Organisation de l'appelant (vide: toutes, appels internes)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDeviceGroupRequest) Reset() {
+	*x = DeleteDeviceGroupRequest{}
+	mi := &file_device_device_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDeviceGroupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDeviceGroupRequest) ProtoMessage() {}
+
+func (x *DeleteDeviceGroupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDeviceGroupRequest.ProtoReflect.Descriptor instead.
+func (*DeleteDeviceGroupRequest) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *DeleteDeviceGroupRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DeleteDeviceGroupRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+// Réponse après suppression
+type DeleteDeviceGroupResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDeviceGroupResponse) Reset() {
+	*x = DeleteDeviceGroupResponse{}
+	mi := &file_device_device_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDeviceGroupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDeviceGroupResponse) ProtoMessage() {}
+
+func (x *DeleteDeviceGroupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDeviceGroupResponse.ProtoReflect.Descriptor instead.
+func (*DeleteDeviceGroupResponse) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *DeleteDeviceGroupResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *DeleteDeviceGroupResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Requête pour ajouter ou retirer des devices d'un groupe statique
+type GroupDevicesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GroupId       string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	DeviceIds     []string               `protobuf:"bytes,2,rep,name=device_ids,json=deviceIds,proto3" json:"device_ids,omitempty"`
+	OrgId         string                 `protobuf:"bytes,3,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"` // Organisation de l'appelant (vide: toutes, appels internes)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GroupDevicesRequest) Reset() {
+	*x = GroupDevicesRequest{}
+	mi := &file_device_device_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupDevicesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupDevicesRequest) ProtoMessage() {}
+
+func (x *GroupDevicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupDevicesRequest.ProtoReflect.Descriptor instead.
+func (*GroupDevicesRequest) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *GroupDevicesRequest) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *GroupDevicesRequest) GetDeviceIds() []string {
+	if x != nil {
+		return x.DeviceIds
+	}
+	return nil
+}
+
+func (x *GroupDevicesRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+// Réponse avec le groupe modifié
+type GroupDevicesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Group         *DeviceGroup           `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GroupDevicesResponse) Reset() {
+	*x = GroupDevicesResponse{}
+	mi := &file_device_device_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupDevicesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupDevicesResponse) ProtoMessage() {}
+
+func (x *GroupDevicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupDevicesResponse.ProtoReflect.Descriptor instead.
+func (*GroupDevicesResponse) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *GroupDevicesResponse) GetGroup() *DeviceGroup {
+	if x != nil {
+		return x.Group
+	}
+	return nil
+}
+
+// Appelant soumis aux ACL : un utilisateur et son rôle dans l'organisation
+type AccessSubject struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Role          string                 `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AccessSubject) Reset() {
+	*x = AccessSubject{}
+	mi := &file_device_device_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccessSubject) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccessSubject) ProtoMessage() {}
+
+func (x *AccessSubject) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccessSubject.ProtoReflect.Descriptor instead.
+func (*AccessSubject) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *AccessSubject) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *AccessSubject) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+// Entrée d'ACL : un utilisateur (user_id) ou les membres ayant un rôle (role)
+// ont accès à un device (device_id) ou aux devices d'un groupe (group_id).
+// Un utilisateur concerné par au moins une entrée de son organisation ne voit
+// que les devices de ses entrées ; les autres gardent l'accès de leur rôle.
+type AccessEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	OrgId         string                 `protobuf:"bytes,2,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,3,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Role          string                 `protobuf:"bytes,4,opt,name=role,proto3" json:"role,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,5,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	GroupId       string                 `protobuf:"bytes,6,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	Access        AccessLevel            `protobuf:"varint,7,opt,name=access,proto3,enum=device.AccessLevel" json:"access,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AccessEntry) Reset() {
+	*x = AccessEntry{}
+	mi := &file_device_device_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccessEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccessEntry) ProtoMessage() {}
+
+func (x *AccessEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccessEntry.ProtoReflect.Descriptor instead.
+func (*AccessEntry) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *AccessEntry) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AccessEntry) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *AccessEntry) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *AccessEntry) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *AccessEntry) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *AccessEntry) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *AccessEntry) GetAccess() AccessLevel {
+	if x != nil {
+		return x.Access
+	}
+	return AccessLevel_ACCESS_READ
+}
+
+func (x *AccessEntry) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+// Requête pour créer une entrée d'ACL (un seul sujet, une seule cible)
+type CreateAccessEntryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrgId         string                 `protobuf:"bytes,1,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"` // Organisation propriétaire (obligatoire)
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Role          string                 `protobuf:"bytes,3,opt,name=role,proto3" json:"role,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,4,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	GroupId       string                 `protobuf:"bytes,5,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	Access        AccessLevel            `protobuf:"varint,6,opt,name=access,proto3,enum=device.AccessLevel" json:"access,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAccessEntryRequest) Reset() {
+	*x = CreateAccessEntryRequest{}
+	mi := &file_device_device_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAccessEntryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAccessEntryRequest) ProtoMessage() {}
+
+func (x *CreateAccessEntryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAccessEntryRequest.ProtoReflect.Descriptor instead.
+func (*CreateAccessEntryRequest) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *CreateAccessEntryRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *CreateAccessEntryRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CreateAccessEntryRequest) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *CreateAccessEntryRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *CreateAccessEntryRequest) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *CreateAccessEntryRequest) GetAccess() AccessLevel {
+	if x != nil {
+		return x.Access
+	}
+	return AccessLevel_ACCESS_READ
+}
+
+// Réponse après création
+type CreateAccessEntryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entry         *AccessEntry           `protobuf:"bytes,1,opt,name=entry,proto3" json:"entry,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateAccessEntryResponse) Reset() {
+	*x = CreateAccessEntryResponse{}
+	mi := &file_device_device_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateAccessEntryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateAccessEntryResponse) ProtoMessage() {}
+
+func (x *CreateAccessEntryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateAccessEntryResponse.ProtoReflect.Descriptor instead.
+func (*CreateAccessEntryResponse) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *CreateAccessEntryResponse) GetEntry() *AccessEntry {
+	if x != nil {
+		return x.Entry
+	}
+	return nil
+}
+
+// Requête pour lister les entrées d'ACL
+type ListAccessEntriesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrgId         string                 `protobuf:"bytes,1,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`          // Organisation de l'appelant (vide: toutes, appels internes)
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`       // Entrées de cet utilisateur (optionnel)
+	Role          string                 `protobuf:"bytes,3,opt,name=role,proto3" json:"role,omitempty"`                         // Entrées de ce rôle (optionnel)
+	DeviceId      string                 `protobuf:"bytes,4,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"` // Entrées ciblant ce device (optionnel)
+	GroupId       string                 `protobuf:"bytes,5,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`    // Entrées ciblant ce groupe (optionnel)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAccessEntriesRequest) Reset() {
+	*x = ListAccessEntriesRequest{}
+	mi := &file_device_device_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAccessEntriesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAccessEntriesRequest) ProtoMessage() {}
+
+func (x *ListAccessEntriesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAccessEntriesRequest.ProtoReflect.Descriptor instead.
+func (*ListAccessEntriesRequest) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *ListAccessEntriesRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *ListAccessEntriesRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListAccessEntriesRequest) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *ListAccessEntriesRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *ListAccessEntriesRequest) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+// Réponse avec la liste des entrées
+type ListAccessEntriesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*AccessEntry         `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAccessEntriesResponse) Reset() {
+	*x = ListAccessEntriesResponse{}
+	mi := &file_device_device_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAccessEntriesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAccessEntriesResponse) ProtoMessage() {}
+
+func (x *ListAccessEntriesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAccessEntriesResponse.ProtoReflect.Descriptor instead.
+func (*ListAccessEntriesResponse) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *ListAccessEntriesResponse) GetEntries() []*AccessEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+// Requête pour supprimer une entrée d'ACL
+type DeleteAccessEntryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	OrgId         string                 `protobuf:"bytes,2,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"` // Organisation de l'appelant (vide: toutes, appels internes)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteAccessEntryRequest) Reset() {
+	*x = DeleteAccessEntryRequest{}
+	mi := &file_device_device_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteAccessEntryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteAccessEntryRequest) ProtoMessage() {}
+
+func (x *DeleteAccessEntryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteAccessEntryRequest.ProtoReflect.Descriptor instead.
+func (*DeleteAccessEntryRequest) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *DeleteAccessEntryRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DeleteAccessEntryRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+// Réponse après suppression
+type DeleteAccessEntryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteAccessEntryResponse) Reset() {
+	*x = DeleteAccessEntryResponse{}
+	mi := &file_device_device_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteAccessEntryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteAccessEntryResponse) ProtoMessage() {}
+
+func (x *DeleteAccessEntryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteAccessEntryResponse.ProtoReflect.Descriptor instead.
+func (*DeleteAccessEntryResponse) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *DeleteAccessEntryResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *DeleteAccessEntryResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Requête pour vérifier l'accès d'un appelant à des devices
+type CheckDeviceAccessRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrgId         string                 `protobuf:"bytes,1,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"` // Organisation de l'appelant (obligatoire)
+	Subject       *AccessSubject         `protobuf:"bytes,2,opt,name=subject,proto3" json:"subject,omitempty"`
+	DeviceIds     []string               `protobuf:"bytes,3,rep,name=device_ids,json=deviceIds,proto3" json:"device_ids,omitempty"`
+	Access        AccessLevel            `protobuf:"varint,4,opt,name=access,proto3,enum=device.AccessLevel" json:"access,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckDeviceAccessRequest) Reset() {
+	*x = CheckDeviceAccessRequest{}
+	mi := &file_device_device_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckDeviceAccessRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckDeviceAccessRequest) ProtoMessage() {}
+
+func (x *CheckDeviceAccessRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckDeviceAccessRequest.ProtoReflect.Descriptor instead.
+func (*CheckDeviceAccessRequest) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *CheckDeviceAccessRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *CheckDeviceAccessRequest) GetSubject() *AccessSubject {
+	if x != nil {
+		return x.Subject
+	}
+	return nil
+}
+
+func (x *CheckDeviceAccessRequest) GetDeviceIds() []string {
+	if x != nil {
+		return x.DeviceIds
+	}
+	return nil
+}
+
+func (x *CheckDeviceAccessRequest) GetAccess() AccessLevel {
+	if x != nil {
+		return x.Access
+	}
+	return AccessLevel_ACCESS_READ
+}
+
+// Réponse de vérification
+type CheckDeviceAccessResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Restricted      bool                   `protobuf:"varint,1,opt,name=restricted,proto3" json:"restricted,omitempty"`                                   // L'appelant est soumis à des ACL
+	DeniedDeviceIds []string               `protobuf:"bytes,2,rep,name=denied_device_ids,json=deniedDeviceIds,proto3" json:"denied_device_ids,omitempty"` // Devices demandés non autorisés
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CheckDeviceAccessResponse) Reset() {
+	*x = CheckDeviceAccessResponse{}
+	mi := &file_device_device_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckDeviceAccessResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckDeviceAccessResponse) ProtoMessage() {}
+
+func (x *CheckDeviceAccessResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckDeviceAccessResponse.ProtoReflect.Descriptor instead.
+func (*CheckDeviceAccessResponse) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *CheckDeviceAccessResponse) GetRestricted() bool {
+	if x != nil {
+		return x.Restricted
+	}
+	return false
+}
+
+func (x *CheckDeviceAccessResponse) GetDeniedDeviceIds() []string {
+	if x != nil {
+		return x.DeniedDeviceIds
+	}
+	return nil
+}
+
+// Message vide (pour les requêtes sans paramètres)
+type Empty struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Empty) Reset() {
+	*x = Empty{}
+	mi := &file_device_device_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Empty) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Empty) ProtoMessage() {}
+
+func (x *Empty) ProtoReflect() protoreflect.Message {
+	mi := &file_device_device_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
+func (*Empty) Descriptor() ([]byte, []int) {
+	return file_device_device_proto_rawDescGZIP(), []int{56}
+}
+
+var File_device_device_proto protoreflect.FileDescriptor
+
+const file_device_device_proto_rawDesc = "" +
+	"\n" +
+	"\x13device/device.proto\x12\x06device\"\xd9\x02\n" +
+	"\x06Device\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12,\n" +
+	"\x06status\x18\x04 \x01(\x0e2\x14.device.DeviceStatusR\x06status\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\x03R\tcreatedAt\x12\x1b\n" +
+	"\tlast_seen\x18\x06 \x01(\x03R\blastSeen\x128\n" +
+	"\bmetadata\x18\a \x03(\v2\x1c.device.Device.MetadataEntryR\bmetadata\x12\x15\n" +
+	"\x06org_id\x18\b \x01(\tR\x05orgId\x12\x1f\n" +
+	"\vlocation_id\x18\t \x01(\tR\n" +
+	"locationId\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xf9\x01\n" +
+	"\x13CreateDeviceRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12E\n" +
+	"\bmetadata\x18\x03 \x03(\v2).device.CreateDeviceRequest.MetadataEntryR\bmetadata\x12\x15\n" +
+	"\x06org_id\x18\x04 \x01(\tR\x05orgId\x12\x1f\n" +
+	"\vlocation_id\x18\x05 \x01(\tR\n" +
+	"locationId\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\">\n" +
+	"\x14CreateDeviceResponse\x12&\n" +
+	"\x06device\x18\x01 \x01(\v2\x0e.device.DeviceR\x06device\"9\n" +
+	"\x10GetDeviceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x15\n" +
+	"\x06org_id\x18\x02 \x01(\tR\x05orgId\";\n" +
+	"\x11GetDeviceResponse\x12&\n" +
+	"\x06device\x18\x01 \x01(\v2\x0e.device.DeviceR\x06device\"\x8b\x02\n" +
+	"\x12ListDevicesRequest\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12,\n" +
+	"\x06status\x18\x04 \x01(\x0e2\x14.device.DeviceStatusR\x06status\x12\x15\n" +
+	"\x06org_id\x18\x05 \x01(\tR\x05orgId\x12\x19\n" +
+	"\bgroup_id\x18\x06 \x01(\tR\agroupId\x12\x1f\n" +
+	"\vlocation_id\x18\a \x01(\tR\n" +
+	"locationId\x12/\n" +
+	"\asubject\x18\b \x01(\v2\x15.device.AccessSubjectR\asubject\"\x86\x01\n" +
+	"\x13ListDevicesResponse\x12(\n" +
+	"\adevices\x18\x01 \x03(\v2\x0e.device.DeviceR\adevices\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\"\xb8\x02\n" +
+	"\x13UpdateDeviceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12,\n" +
+	"\x06status\x18\x03 \x01(\x0e2\x14.device.DeviceStatusR\x06status\x12E\n" +
+	"\bmetadata\x18\x04 \x03(\v2).device.UpdateDeviceRequest.MetadataEntryR\bmetadata\x12\x15\n" +
+	"\x06org_id\x18\x05 \x01(\tR\x05orgId\x12$\n" +
+	"\vlocation_id\x18\x06 \x01(\tH\x00R\n" +
+	"locationId\x88\x01\x01\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\x0e\n" +
+	"\f_location_id\">\n" +
+	"\x14UpdateDeviceResponse\x12&\n" +
+	"\x06device\x18\x01 \x01(\v2\x0e.device.DeviceR\x06device\"<\n" +
+	"\x13DeleteDeviceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x15\n" +
+	"\x06org_id\x18\x02 \x01(\tR\x05orgId\"J\n" +
+	"\x14DeleteDeviceResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xb0\x02\n" +
+	"\x10MetricDefinition\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12!\n" +
+	"\fdisplay_name\x18\x02 \x01(\tR\vdisplayName\x12\x12\n" +
+	"\x04unit\x18\x03 \x01(\tR\x04unit\x123\n" +
+	"\tdata_type\x18\x04 \x01(\x0e2\x16.device.MetricDataTypeR\bdataType\x12 \n" +
+	"\tmin_value\x18\x05 \x01(\x01H\x00R\bminValue\x88\x01\x01\x12 \n" +
+	"\tmax_value\x18\x06 \x01(\x01H\x01R\bmaxValue\x88\x01\x01\x12<\n" +
+	"\x1areporting_interval_seconds\x18\a \x01(\x05R\x18reportingIntervalSecondsB\f\n" +
+	"\n" +
+	"_min_valueB\f\n" +
+	"\n" +
+	"_max_value\"\xd7\x01\n" +
+	"\n" +
+	"DeviceType\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12!\n" +
+	"\fdisplay_name\x18\x02 \x01(\tR\vdisplayName\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x122\n" +
+	"\ametrics\x18\x04 \x03(\v2\x18.device.MetricDefinitionR\ametrics\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x06 \x01(\x03R\tupdatedAt\"N\n" +
+	"\x17UpsertDeviceTypeRequest\x123\n" +
+	"\vdevice_type\x18\x01 \x01(\v2\x12.device.DeviceTypeR\n" +
+	"deviceType\"O\n" +
+	"\x18UpsertDeviceTypeResponse\x123\n" +
+	"\vdevice_type\x18\x01 \x01(\v2\x12.device.DeviceTypeR\n" +
+	"deviceType\"*\n" +
+	"\x14GetDeviceTypeRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"L\n" +
+	"\x15GetDeviceTypeResponse\x123\n" +
+	"\vdevice_type\x18\x01 \x01(\v2\x12.device.DeviceTypeR\n" +
+	"deviceType\"\x18\n" +
+	"\x16ListDeviceTypesRequest\"P\n" +
+	"\x17ListDeviceTypesResponse\x125\n" +
+	"\fdevice_types\x18\x01 \x03(\v2\x12.device.DeviceTypeR\vdeviceTypes\"-\n" +
+	"\x17DeleteDeviceTypeRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"N\n" +
+	"\x18DeleteDeviceTypeResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xab\x01\n" +
+	"\bLocation\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x15\n" +
+	"\x06org_id\x18\x02 \x01(\tR\x05orgId\x12\x1b\n" +
+	"\tparent_id\x18\x03 \x01(\tR\bparentId\x12(\n" +
+	"\x04kind\x18\x04 \x01(\x0e2\x14.device.LocationKindR\x04kind\x12\x12\n" +
+	"\x04name\x18\x05 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\x03R\tcreatedAt\"\x89\x01\n" +
+	"\x15CreateLocationRequest\x12\x15\n" +
+	"\x06org_id\x18\x01 \x01(\tR\x05orgId\x12\x1b\n" +
+	"\tparent_id\x18\x02 \x01(\tR\bparentId\x12(\n" +
+	"\x04kind\x18\x03 \x01(\x0e2\x14.device.LocationKindR\x04kind\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\"F\n" +
+	"\x16CreateLocationResponse\x12,\n" +
+	"\blocation\x18\x01 \x01(\v2\x10.device.LocationR\blocation\";\n" +
+	"\x12GetLocationRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x15\n" +
+	"\x06org_id\x18\x02 \x01(\tR\x05orgId\"C\n" +
+	"\x13GetLocationResponse\x12,\n" +
+	"\blocation\x18\x01 \x01(\v2\x10.device.LocationR\blocation\"J\n" +
+	"\x14ListLocationsRequest\x12\x15\n" +
+	"\x06org_id\x18\x01 \x01(\tR\x05orgId\x12\x1b\n" +
+	"\tparent_id\x18\x02 \x01(\tR\bparentId\"G\n" +
+	"\x15ListLocationsResponse\x12.\n" +
+	"\tlocations\x18\x01 \x03(\v2\x10.device.LocationR\tlocations\"R\n" +
+	"\x15UpdateLocationRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x15\n" +
+	"\x06org_id\x18\x03 \x01(\tR\x05orgId\"F\n" +
+	"\x16UpdateLocationResponse\x12,\n" +
+	"\blocation\x18\x01 \x01(\v2\x10.device.LocationR\blocation\">\n" +
+	"\x15DeleteLocationRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x15\n" +
+	"\x06org_id\x18\x02 \x01(\tR\x05orgId\"L\n" +
+	"\x16DeleteLocationResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xa5\x01\n" +
+	"\x0fDeviceGroupRule\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12A\n" +
+	"\bmetadata\x18\x02 \x03(\v2%.device.DeviceGroupRule.MetadataEntryR\bmetadata\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xa5\x02\n" +
+	"\vDeviceGroup\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x15\n" +
+	"\x06org_id\x18\x02 \x01(\tR\x05orgId\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12+\n" +
+	"\x04kind\x18\x05 \x01(\x0e2\x17.device.DeviceGroupKindR\x04kind\x12+\n" +
+	"\x04rule\x18\x06 \x01(\v2\x17.device.DeviceGroupRuleR\x04rule\x12!\n" +
+	"\fdevice_count\x18\a \x01(\x05R\vdeviceCount\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\b \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\t \x01(\x03R\tupdatedAt\"\xc1\x01\n" +
+	"\x18CreateDeviceGroupRequest\x12\x15\n" +
+	"\x06org_id\x18\x01 \x01(\tR\x05orgId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12+\n" +
+	"\x04kind\x18\x04 \x01(\x0e2\x17.device.DeviceGroupKindR\x04kind\x12+\n" +
+	"\x04rule\x18\x05 \x01(\v2\x17.device.DeviceGroupRuleR\x04rule\"F\n" +
+	"\x19CreateDeviceGroupResponse\x12)\n" +
+	"\x05group\x18\x01 \x01(\v2\x13.device.DeviceGroupR\x05group\">\n" +
+	"\x15GetDeviceGroupRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x15\n" +
+	"\x06org_id\x18\x02 \x01(\tR\x05orgId\"C\n" +
+	"\x16GetDeviceGroupResponse\x12)\n" +
+	"\x05group\x18\x01 \x01(\v2\x13.device.DeviceGroupR\x05group\"M\n" +
+	"\x17ListDeviceGroupsRequest\x12\x15\n" +
+	"\x06org_id\x18\x01 \x01(\tR\x05orgId\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\"G\n" +
+	"\x18ListDeviceGroupsResponse\x12+\n" +
+	"\x06groups\x18\x01 \x03(\v2\x13.device.DeviceGroupR\x06groups\"\xb9\x01\n" +
+	"\x18UpdateDeviceGroupRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12%\n" +
+	"\vdescription\x18\x03 \x01(\tH\x00R\vdescription\x88\x01\x01\x12+\n" +
+	"\x04rule\x18\x04 \x01(\v2\x17.device.DeviceGroupRuleR\x04rule\x12\x15\n" +
+	"\x06org_id\x18\x05 \x01(\tR\x05orgIdB\x0e\n" +
+	"\f_description\"F\n" +
+	"\x19UpdateDeviceGroupResponse\x12)\n" +
+	"\x05group\x18\x01 \x01(\v2\x13.device.DeviceGroupR\x05group\"A\n" +
+	"\x18DeleteDeviceGroupRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x15\n" +
+	"\x06org_id\x18\x02 \x01(\tR\x05orgId\"O\n" +
+	"\x19DeleteDeviceGroupResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"f\n" +
+	"\x13GroupDevicesRequest\x12\x19\n" +
+	"\bgroup_id\x18\x01 \x01(\tR\agroupId\x12\x1d\n" +
+	"\n" +
+	"device_ids\x18\x02 \x03(\tR\tdeviceIds\x12\x15\n" +
+	"\x06org_id\x18\x03 \x01(\tR\x05orgId\"A\n" +
+	"\x14GroupDevicesResponse\x12)\n" +
+	"\x05group\x18\x01 \x01(\v2\x13.device.DeviceGroupR\x05group\"<\n" +
+	"\rAccessSubject\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04role\x18\x02 \x01(\tR\x04role\"\xe5\x01\n" +
+	"\vAccessEntry\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x15\n" +
+	"\x06org_id\x18\x02 \x01(\tR\x05orgId\x12\x17\n" +
+	"\auser_id\x18\x03 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04role\x18\x04 \x01(\tR\x04role\x12\x1b\n" +
+	"\tdevice_id\x18\x05 \x01(\tR\bdeviceId\x12\x19\n" +
+	"\bgroup_id\x18\x06 \x01(\tR\agroupId\x12+\n" +
+	"\x06access\x18\a \x01(\x0e2\x13.device.AccessLevelR\x06access\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\b \x01(\x03R\tcreatedAt\"\xc3\x01\n" +
+	"\x18CreateAccessEntryRequest\x12\x15\n" +
+	"\x06org_id\x18\x01 \x01(\tR\x05orgId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04role\x18\x03 \x01(\tR\x04role\x12\x1b\n" +
+	"\tdevice_id\x18\x04 \x01(\tR\bdeviceId\x12\x19\n" +
+	"\bgroup_id\x18\x05 \x01(\tR\agroupId\x12+\n" +
+	"\x06access\x18\x06 \x01(\x0e2\x13.device.AccessLevelR\x06access\"F\n" +
+	"\x19CreateAccessEntryResponse\x12)\n" +
+	"\x05entry\x18\x01 \x01(\v2\x13.device.AccessEntryR\x05entry\"\x96\x01\n" +
+	"\x18ListAccessEntriesRequest\x12\x15\n" +
+	"\x06org_id\x18\x01 \x01(\tR\x05orgId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x12\n" +
+	"\x04role\x18\x03 \x01(\tR\x04role\x12\x1b\n" +
+	"\tdevice_id\x18\x04 \x01(\tR\bdeviceId\x12\x19\n" +
+	"\bgroup_id\x18\x05 \x01(\tR\agroupId\"J\n" +
+	"\x19ListAccessEntriesResponse\x12-\n" +
+	"\aentries\x18\x01 \x03(\v2\x13.device.AccessEntryR\aentries\"A\n" +
+	"\x18DeleteAccessEntryRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x15\n" +
+	"\x06org_id\x18\x02 \x01(\tR\x05orgId\"O\n" +
+	"\x19DeleteAccessEntryResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xae\x01\n" +
+	"\x18CheckDeviceAccessRequest\x12\x15\n" +
+	"\x06org_id\x18\x01 \x01(\tR\x05orgId\x12/\n" +
+	"\asubject\x18\x02 \x01(\v2\x15.device.AccessSubjectR\asubject\x12\x1d\n" +
+	"\n" +
+	"device_ids\x18\x03 \x03(\tR\tdeviceIds\x12+\n" +
+	"\x06access\x18\x04 \x01(\x0e2\x13.device.AccessLevelR\x06access\"g\n" +
+	"\x19CheckDeviceAccessResponse\x12\x1e\n" +
+	"\n" +
+	"restricted\x18\x01 \x01(\bR\n" +
+	"restricted\x12*\n" +
+	"\x11denied_device_ids\x18\x02 \x03(\tR\x0fdeniedDeviceIds\"\a\n" +
 	"\x05Empty*P\n" +
 	"\fDeviceStatus\x12\v\n" +
 	"\aUNKNOWN\x10\x00\x12\n" +
@@ -819,14 +3912,50 @@ const file_device_device_proto_rawDesc = "" +
 	"\x06ONLINE\x10\x01\x12\v\n" +
 	"\aOFFLINE\x10\x02\x12\t\n" +
 	"\x05ERROR\x10\x03\x12\x0f\n" +
-	"\vMAINTENANCE\x10\x042\xab\x03\n" +
+	"\vMAINTENANCE\x10\x04*J\n" +
+	"\x0eMetricDataType\x12\x10\n" +
+	"\fMETRIC_FLOAT\x10\x00\x12\x12\n" +
+	"\x0eMETRIC_INTEGER\x10\x01\x12\x12\n" +
+	"\x0eMETRIC_BOOLEAN\x10\x02*_\n" +
+	"\fLocationKind\x12\x11\n" +
+	"\rLOCATION_SITE\x10\x00\x12\x15\n" +
+	"\x11LOCATION_BUILDING\x10\x01\x12\x12\n" +
+	"\x0eLOCATION_FLOOR\x10\x02\x12\x11\n" +
+	"\rLOCATION_ROOM\x10\x03*6\n" +
+	"\x0fDeviceGroupKind\x12\x10\n" +
+	"\fGROUP_STATIC\x10\x00\x12\x11\n" +
+	"\rGROUP_DYNAMIC\x10\x01*D\n" +
+	"\vAccessLevel\x12\x0f\n" +
+	"\vACCESS_READ\x10\x00\x12\x10\n" +
+	"\fACCESS_WRITE\x10\x01\x12\x12\n" +
+	"\x0eACCESS_COMMAND\x10\x022\xc1\x10\n" +
 	"\rDeviceService\x12I\n" +
 	"\fCreateDevice\x12\x1b.device.CreateDeviceRequest\x1a\x1c.device.CreateDeviceResponse\x12@\n" +
 	"\tGetDevice\x12\x18.device.GetDeviceRequest\x1a\x19.device.GetDeviceResponse\x12F\n" +
 	"\vListDevices\x12\x1a.device.ListDevicesRequest\x1a\x1b.device.ListDevicesResponse\x12I\n" +
 	"\fUpdateDevice\x12\x1b.device.UpdateDeviceRequest\x1a\x1c.device.UpdateDeviceResponse\x12I\n" +
 	"\fDeleteDevice\x12\x1b.device.DeleteDeviceRequest\x1a\x1c.device.DeleteDeviceResponse\x12/\n" +
-	"\fWatchDevices\x12\r.device.Empty\x1a\x0e.device.Device0\x01B:Z8github.com/yourusername/iot-platform/shared/proto/deviceb\x06proto3"
+	"\fWatchDevices\x12\r.device.Empty\x1a\x0e.device.Device0\x01\x12U\n" +
+	"\x10UpsertDeviceType\x12\x1f.device.UpsertDeviceTypeRequest\x1a .device.UpsertDeviceTypeResponse\x12L\n" +
+	"\rGetDeviceType\x12\x1c.device.GetDeviceTypeRequest\x1a\x1d.device.GetDeviceTypeResponse\x12R\n" +
+	"\x0fListDeviceTypes\x12\x1e.device.ListDeviceTypesRequest\x1a\x1f.device.ListDeviceTypesResponse\x12U\n" +
+	"\x10DeleteDeviceType\x12\x1f.device.DeleteDeviceTypeRequest\x1a .device.DeleteDeviceTypeResponse\x12O\n" +
+	"\x0eCreateLocation\x12\x1d.device.CreateLocationRequest\x1a\x1e.device.CreateLocationResponse\x12F\n" +
+	"\vGetLocation\x12\x1a.device.GetLocationRequest\x1a\x1b.device.GetLocationResponse\x12L\n" +
+	"\rListLocations\x12\x1c.device.ListLocationsRequest\x1a\x1d.device.ListLocationsResponse\x12O\n" +
+	"\x0eUpdateLocation\x12\x1d.device.UpdateLocationRequest\x1a\x1e.device.UpdateLocationResponse\x12O\n" +
+	"\x0eDeleteLocation\x12\x1d.device.DeleteLocationRequest\x1a\x1e.device.DeleteLocationResponse\x12X\n" +
+	"\x11CreateDeviceGroup\x12 .device.CreateDeviceGroupRequest\x1a!.device.CreateDeviceGroupResponse\x12O\n" +
+	"\x0eGetDeviceGroup\x12\x1d.device.GetDeviceGroupRequest\x1a\x1e.device.GetDeviceGroupResponse\x12U\n" +
+	"\x10ListDeviceGroups\x12\x1f.device.ListDeviceGroupsRequest\x1a .device.ListDeviceGroupsResponse\x12X\n" +
+	"\x11UpdateDeviceGroup\x12 .device.UpdateDeviceGroupRequest\x1a!.device.UpdateDeviceGroupResponse\x12X\n" +
+	"\x11DeleteDeviceGroup\x12 .device.DeleteDeviceGroupRequest\x1a!.device.DeleteDeviceGroupResponse\x12L\n" +
+	"\x0fAddGroupDevices\x12\x1b.device.GroupDevicesRequest\x1a\x1c.device.GroupDevicesResponse\x12O\n" +
+	"\x12RemoveGroupDevices\x12\x1b.device.GroupDevicesRequest\x1a\x1c.device.GroupDevicesResponse\x12X\n" +
+	"\x11CreateAccessEntry\x12 .device.CreateAccessEntryRequest\x1a!.device.CreateAccessEntryResponse\x12X\n" +
+	"\x11ListAccessEntries\x12 .device.ListAccessEntriesRequest\x1a!.device.ListAccessEntriesResponse\x12X\n" +
+	"\x11DeleteAccessEntry\x12 .device.DeleteAccessEntryRequest\x1a!.device.DeleteAccessEntryResponse\x12X\n" +
+	"\x11CheckDeviceAccess\x12 .device.CheckDeviceAccessRequest\x1a!.device.CheckDeviceAccessResponseB:Z8github.com/yourusername/iot-platform/shared/proto/deviceb\x06proto3"
 
 var (
 	file_device_device_proto_rawDescOnce sync.Once
@@ -840,54 +3969,174 @@ func file_device_device_proto_rawDescGZIP() []byte {
 	return file_device_device_proto_rawDescData
 }
 
-var file_device_device_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_device_device_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_device_device_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_device_device_proto_msgTypes = make([]protoimpl.MessageInfo, 61)
 var file_device_device_proto_goTypes = []any{
-	(DeviceStatus)(0),            // 0: device.DeviceStatus
-	(*Device)(nil),               // 1: device.Device
-	(*CreateDeviceRequest)(nil),  // 2: device.CreateDeviceRequest
-	(*CreateDeviceResponse)(nil), // 3: device.CreateDeviceResponse
-	(*GetDeviceRequest)(nil),     // 4: device.GetDeviceRequest
-	(*GetDeviceResponse)(nil),    // 5: device.GetDeviceResponse
-	(*ListDevicesRequest)(nil),   // 6: device.ListDevicesRequest
-	(*ListDevicesResponse)(nil),  // 7: device.ListDevicesResponse
-	(*UpdateDeviceRequest)(nil),  // 8: device.UpdateDeviceRequest
-	(*UpdateDeviceResponse)(nil), // 9: device.UpdateDeviceResponse
-	(*DeleteDeviceRequest)(nil),  // 10: device.DeleteDeviceRequest
-	(*DeleteDeviceResponse)(nil), // 11: device.DeleteDeviceResponse
-	(*Empty)(nil),                // 12: device.Empty
-	nil,                          // 13: device.Device.MetadataEntry
-	nil,                          // 14: device.CreateDeviceRequest.MetadataEntry
-	nil,                          // 15: device.UpdateDeviceRequest.MetadataEntry
+	(DeviceStatus)(0),                 // 0: device.DeviceStatus
+	(MetricDataType)(0),               // 1: device.MetricDataType
+	(LocationKind)(0),                 // 2: device.LocationKind
+	(DeviceGroupKind)(0),              // 3: device.DeviceGroupKind
+	(AccessLevel)(0),                  // 4: device.AccessLevel
+	(*Device)(nil),                    // 5: device.Device
+	(*CreateDeviceRequest)(nil),       // 6: device.CreateDeviceRequest
+	(*CreateDeviceResponse)(nil),      // 7: device.CreateDeviceResponse
+	(*GetDeviceRequest)(nil),          // 8: device.GetDeviceRequest
+	(*GetDeviceResponse)(nil),         // 9: device.GetDeviceResponse
+	(*ListDevicesRequest)(nil),        // 10: device.ListDevicesRequest
+	(*ListDevicesResponse)(nil),       // 11: device.ListDevicesResponse
+	(*UpdateDeviceRequest)(nil),       // 12: device.UpdateDeviceRequest
+	(*UpdateDeviceResponse)(nil),      // 13: device.UpdateDeviceResponse
+	(*DeleteDeviceRequest)(nil),       // 14: device.DeleteDeviceRequest
+	(*DeleteDeviceResponse)(nil),      // 15: device.DeleteDeviceResponse
+	(*MetricDefinition)(nil),          // 16: device.MetricDefinition
+	(*DeviceType)(nil),                // 17: device.DeviceType
+	(*UpsertDeviceTypeRequest)(nil),   // 18: device.UpsertDeviceTypeRequest
+	(*UpsertDeviceTypeResponse)(nil),  // 19: device.UpsertDeviceTypeResponse
+	(*GetDeviceTypeRequest)(nil),      // 20: device.GetDeviceTypeRequest
+	(*GetDeviceTypeResponse)(nil),     // 21: device.GetDeviceTypeResponse
+	(*ListDeviceTypesRequest)(nil),    // 22: device.ListDeviceTypesRequest
+	(*ListDeviceTypesResponse)(nil),   // 23: device.ListDeviceTypesResponse
+	(*DeleteDeviceTypeRequest)(nil),   // 24: device.DeleteDeviceTypeRequest
+	(*DeleteDeviceTypeResponse)(nil),  // 25: device.DeleteDeviceTypeResponse
+	(*Location)(nil),                  // 26: device.Location
+	(*CreateLocationRequest)(nil),     // 27: device.CreateLocationRequest
+	(*CreateLocationResponse)(nil),    // 28: device.CreateLocationResponse
+	(*GetLocationRequest)(nil),        // 29: device.GetLocationRequest
+	(*GetLocationResponse)(nil),       // 30: device.GetLocationResponse
+	(*ListLocationsRequest)(nil),      // 31: device.ListLocationsRequest
+	(*ListLocationsResponse)(nil),     // 32: device.ListLocationsResponse
+	(*UpdateLocationRequest)(nil),     // 33: device.UpdateLocationRequest
+	(*UpdateLocationResponse)(nil),    // 34: device.UpdateLocationResponse
+	(*DeleteLocationRequest)(nil),     // 35: device.DeleteLocationRequest
+	(*DeleteLocationResponse)(nil),    // 36: device.DeleteLocationResponse
+	(*DeviceGroupRule)(nil),           // 37: device.DeviceGroupRule
+	(*DeviceGroup)(nil),               // 38: device.DeviceGroup
+	(*CreateDeviceGroupRequest)(nil),  // 39: device.CreateDeviceGroupRequest
+	(*CreateDeviceGroupResponse)(nil), // 40: device.CreateDeviceGroupResponse
+	(*GetDeviceGroupRequest)(nil),     // 41: device.GetDeviceGroupRequest
+	(*GetDeviceGroupResponse)(nil),    // 42: device.GetDeviceGroupResponse
+	(*ListDeviceGroupsRequest)(nil),   // 43: device.ListDeviceGroupsRequest
+	(*ListDeviceGroupsResponse)(nil),  // 44: device.ListDeviceGroupsResponse
+	(*UpdateDeviceGroupRequest)(nil),  // 45: device.UpdateDeviceGroupRequest
+	(*UpdateDeviceGroupResponse)(nil), // 46: device.UpdateDeviceGroupResponse
+	(*DeleteDeviceGroupRequest)(nil),  // 47: device.DeleteDeviceGroupRequest
+	(*DeleteDeviceGroupResponse)(nil), // 48: device.DeleteDeviceGroupResponse
+	(*GroupDevicesRequest)(nil),       // 49: device.GroupDevicesRequest
+	(*GroupDevicesResponse)(nil),      // 50: device.GroupDevicesResponse
+	(*AccessSubject)(nil),             // 51: device.AccessSubject
+	(*AccessEntry)(nil),               // 52: device.AccessEntry
+	(*CreateAccessEntryRequest)(nil),  // 53: device.CreateAccessEntryRequest
+	(*CreateAccessEntryResponse)(nil), // 54: device.CreateAccessEntryResponse
+	(*ListAccessEntriesRequest)(nil),  // 55: device.ListAccessEntriesRequest
+	(*ListAccessEntriesResponse)(nil), // 56: device.ListAccessEntriesResponse
+	(*DeleteAccessEntryRequest)(nil),  // 57: device.DeleteAccessEntryRequest
+	(*DeleteAccessEntryResponse)(nil), // 58: device.DeleteAccessEntryResponse
+	(*CheckDeviceAccessRequest)(nil),  // 59: device.CheckDeviceAccessRequest
+	(*CheckDeviceAccessResponse)(nil), // 60: device.CheckDeviceAccessResponse
+	(*Empty)(nil),                     // 61: device.Empty
+	nil,                               // 62: device.Device.MetadataEntry
+	nil,                               // 63: device.CreateDeviceRequest.MetadataEntry
+	nil,                               // 64: device.UpdateDeviceRequest.MetadataEntry
+	nil,                               // 65: device.DeviceGroupRule.MetadataEntry
 }
 var file_device_device_proto_depIdxs = []int32{
 	0,  // 0: device.Device.status:type_name -> device.DeviceStatus
-	13, // 1: device.Device.metadata:type_name -> device.Device.MetadataEntry
-	14, // 2: device.CreateDeviceRequest.metadata:type_name -> device.CreateDeviceRequest.MetadataEntry
-	1,  // 3: device.CreateDeviceResponse.device:type_name -> device.Device
-	1,  // 4: device.GetDeviceResponse.device:type_name -> device.Device
+	62, // 1: device.Device.metadata:type_name -> device.Device.MetadataEntry
+	63, // 2: device.CreateDeviceRequest.metadata:type_name -> device.CreateDeviceRequest.MetadataEntry
+	5,  // 3: device.CreateDeviceResponse.device:type_name -> device.Device
+	5,  // 4: device.GetDeviceResponse.device:type_name -> device.Device
 	0,  // 5: device.ListDevicesRequest.status:type_name -> device.DeviceStatus
-	1,  // 6: device.ListDevicesResponse.devices:type_name -> device.Device
-	0,  // 7: device.UpdateDeviceRequest.status:type_name -> device.DeviceStatus
-	15, // 8: device.UpdateDeviceRequest.metadata:type_name -> device.UpdateDeviceRequest.MetadataEntry
-	1,  // 9: device.UpdateDeviceResponse.device:type_name -> device.Device
-	2,  // 10: device.DeviceService.CreateDevice:input_type -> device.CreateDeviceRequest
-	4,  // 11: device.DeviceService.GetDevice:input_type -> device.GetDeviceRequest
-	6,  // 12: device.DeviceService.ListDevices:input_type -> device.ListDevicesRequest
-	8,  // 13: device.DeviceService.UpdateDevice:input_type -> device.UpdateDeviceRequest
-	10, // 14: device.DeviceService.DeleteDevice:input_type -> device.DeleteDeviceRequest
-	12, // 15: device.DeviceService.WatchDevices:input_type -> device.Empty
-	3,  // 16: device.DeviceService.CreateDevice:output_type -> device.CreateDeviceResponse
-	5,  // 17: device.DeviceService.GetDevice:output_type -> device.GetDeviceResponse
-	7,  // 18: device.DeviceService.ListDevices:output_type -> device.ListDevicesResponse
-	9,  // 19: device.DeviceService.UpdateDevice:output_type -> device.UpdateDeviceResponse
-	11, // 20: device.DeviceService.DeleteDevice:output_type -> device.DeleteDeviceResponse
-	1,  // 21: device.DeviceService.WatchDevices:output_type -> device.Device
-	16, // [16:22] is the sub-list for method output_type
-	10, // [10:16] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	51, // 6: device.ListDevicesRequest.subject:type_name -> device.AccessSubject
+	5,  // 7: device.ListDevicesResponse.devices:type_name -> device.Device
+	0,  // 8: device.UpdateDeviceRequest.status:type_name -> device.DeviceStatus
+	64, // 9: device.UpdateDeviceRequest.metadata:type_name -> device.UpdateDeviceRequest.MetadataEntry
+	5,  // 10: device.UpdateDeviceResponse.device:type_name -> device.Device
+	1,  // 11: device.MetricDefinition.data_type:type_name -> device.MetricDataType
+	16, // 12: device.DeviceType.metrics:type_name -> device.MetricDefinition
+	17, // 13: device.UpsertDeviceTypeRequest.device_type:type_name -> device.DeviceType
+	17, // 14: device.UpsertDeviceTypeResponse.device_type:type_name -> device.DeviceType
+	17, // 15: device.GetDeviceTypeResponse.device_type:type_name -> device.DeviceType
+	17, // 16: device.ListDeviceTypesResponse.device_types:type_name -> device.DeviceType
+	2,  // 17: device.Location.kind:type_name -> device.LocationKind
+	2,  // 18: device.CreateLocationRequest.kind:type_name -> device.LocationKind
+	26, // 19: device.CreateLocationResponse.location:type_name -> device.Location
+	26, // 20: device.GetLocationResponse.location:type_name -> device.Location
+	26, // 21: device.ListLocationsResponse.locations:type_name -> device.Location
+	26, // 22: device.UpdateLocationResponse.location:type_name -> device.Location
+	65, // 23: device.DeviceGroupRule.metadata:type_name -> device.DeviceGroupRule.MetadataEntry
+	3,  // 24: device.DeviceGroup.kind:type_name -> device.DeviceGroupKind
+	37, // 25: device.DeviceGroup.rule:type_name -> device.DeviceGroupRule
+	3,  // 26: device.CreateDeviceGroupRequest.kind:type_name -> device.DeviceGroupKind
+	37, // 27: device.CreateDeviceGroupRequest.rule:type_name -> device.DeviceGroupRule
+	38, // 28: device.CreateDeviceGroupResponse.group:type_name -> device.DeviceGroup
+	38, // 29: device.GetDeviceGroupResponse.group:type_name -> device.DeviceGroup
+	38, // 30: device.ListDeviceGroupsResponse.groups:type_name -> device.DeviceGroup
+	37, // 31: device.UpdateDeviceGroupRequest.rule:type_name -> device.DeviceGroupRule
+	38, // 32: device.UpdateDeviceGroupResponse.group:type_name -> device.DeviceGroup
+	38, // 33: device.GroupDevicesResponse.group:type_name -> device.DeviceGroup
+	4,  // 34: device.AccessEntry.access:type_name -> device.AccessLevel
+	4,  // 35: device.CreateAccessEntryRequest.access:type_name -> device.AccessLevel
+	52, // 36: device.CreateAccessEntryResponse.entry:type_name -> device.AccessEntry
+	52, // 37: device.ListAccessEntriesResponse.entries:type_name -> device.AccessEntry
+	51, // 38: device.CheckDeviceAccessRequest.subject:type_name -> device.AccessSubject
+	4,  // 39: device.CheckDeviceAccessRequest.access:type_name -> device.AccessLevel
+	6,  // 40: device.DeviceService.CreateDevice:input_type -> device.CreateDeviceRequest
+	8,  // 41: device.DeviceService.GetDevice:input_type -> device.GetDeviceRequest
+	10, // 42: device.DeviceService.ListDevices:input_type -> device.ListDevicesRequest
+	12, // 43: device.DeviceService.UpdateDevice:input_type -> device.UpdateDeviceRequest
+	14, // 44: device.DeviceService.DeleteDevice:input_type -> device.DeleteDeviceRequest
+	61, // 45: device.DeviceService.WatchDevices:input_type -> device.Empty
+	18, // 46: device.DeviceService.UpsertDeviceType:input_type -> device.UpsertDeviceTypeRequest
+	20, // 47: device.DeviceService.GetDeviceType:input_type -> device.GetDeviceTypeRequest
+	22, // 48: device.DeviceService.ListDeviceTypes:input_type -> device.ListDeviceTypesRequest
+	24, // 49: device.DeviceService.DeleteDeviceType:input_type -> device.DeleteDeviceTypeRequest
+	27, // 50: device.DeviceService.CreateLocation:input_type -> device.CreateLocationRequest
+	29, // 51: device.DeviceService.GetLocation:input_type -> device.GetLocationRequest
+	31, // 52: device.DeviceService.ListLocations:input_type -> device.ListLocationsRequest
+	33, // 53: device.DeviceService.UpdateLocation:input_type -> device.UpdateLocationRequest
+	35, // 54: device.DeviceService.DeleteLocation:input_type -> device.DeleteLocationRequest
+	39, // 55: device.DeviceService.CreateDeviceGroup:input_type -> device.CreateDeviceGroupRequest
+	41, // 56: device.DeviceService.GetDeviceGroup:input_type -> device.GetDeviceGroupRequest
+	43, // 57: device.DeviceService.ListDeviceGroups:input_type -> device.ListDeviceGroupsRequest
+	45, // 58: device.DeviceService.UpdateDeviceGroup:input_type -> device.UpdateDeviceGroupRequest
+	47, // 59: device.DeviceService.DeleteDeviceGroup:input_type -> device.DeleteDeviceGroupRequest
+	49, // 60: device.DeviceService.AddGroupDevices:input_type -> device.GroupDevicesRequest
+	49, // 61: device.DeviceService.RemoveGroupDevices:input_type -> device.GroupDevicesRequest
+	53, // 62: device.DeviceService.CreateAccessEntry:input_type -> device.CreateAccessEntryRequest
+	55, // 63: device.DeviceService.ListAccessEntries:input_type -> device.ListAccessEntriesRequest
+	57, // 64: device.DeviceService.DeleteAccessEntry:input_type -> device.DeleteAccessEntryRequest
+	59, // 65: device.DeviceService.CheckDeviceAccess:input_type -> device.CheckDeviceAccessRequest
+	7,  // 66: device.DeviceService.CreateDevice:output_type -> device.CreateDeviceResponse
+	9,  // 67: device.DeviceService.GetDevice:output_type -> device.GetDeviceResponse
+	11, // 68: device.DeviceService.ListDevices:output_type -> device.ListDevicesResponse
+	13, // 69: device.DeviceService.UpdateDevice:output_type -> device.UpdateDeviceResponse
+	15, // 70: device.DeviceService.DeleteDevice:output_type -> device.DeleteDeviceResponse
+	5,  // 71: device.DeviceService.WatchDevices:output_type -> device.Device
+	19, // 72: device.DeviceService.UpsertDeviceType:output_type -> device.UpsertDeviceTypeResponse
+	21, // 73: device.DeviceService.GetDeviceType:output_type -> device.GetDeviceTypeResponse
+	23, // 74: device.DeviceService.ListDeviceTypes:output_type -> device.ListDeviceTypesResponse
+	25, // 75: device.DeviceService.DeleteDeviceType:output_type -> device.DeleteDeviceTypeResponse
+	28, // 76: device.DeviceService.CreateLocation:output_type -> device.CreateLocationResponse
+	30, // 77: device.DeviceService.GetLocation:output_type -> device.GetLocationResponse
+	32, // 78: device.DeviceService.ListLocations:output_type -> device.ListLocationsResponse
+	34, // 79: device.DeviceService.UpdateLocation:output_type -> device.UpdateLocationResponse
+	36, // 80: device.DeviceService.DeleteLocation:output_type -> device.DeleteLocationResponse
+	40, // 81: device.DeviceService.CreateDeviceGroup:output_type -> device.CreateDeviceGroupResponse
+	42, // 82: device.DeviceService.GetDeviceGroup:output_type -> device.GetDeviceGroupResponse
+	44, // 83: device.DeviceService.ListDeviceGroups:output_type -> device.ListDeviceGroupsResponse
+	46, // 84: device.DeviceService.UpdateDeviceGroup:output_type -> device.UpdateDeviceGroupResponse
+	48, // 85: device.DeviceService.DeleteDeviceGroup:output_type -> device.DeleteDeviceGroupResponse
+	50, // 86: device.DeviceService.AddGroupDevices:output_type -> device.GroupDevicesResponse
+	50, // 87: device.DeviceService.RemoveGroupDevices:output_type -> device.GroupDevicesResponse
+	54, // 88: device.DeviceService.CreateAccessEntry:output_type -> device.CreateAccessEntryResponse
+	56, // 89: device.DeviceService.ListAccessEntries:output_type -> device.ListAccessEntriesResponse
+	58, // 90: device.DeviceService.DeleteAccessEntry:output_type -> device.DeleteAccessEntryResponse
+	60, // 91: device.DeviceService.CheckDeviceAccess:output_type -> device.CheckDeviceAccessResponse
+	66, // [66:92] is the sub-list for method output_type
+	40, // [40:66] is the sub-list for method input_type
+	40, // [40:40] is the sub-list for extension type_name
+	40, // [40:40] is the sub-list for extension extendee
+	0,  // [0:40] is the sub-list for field type_name
 }
 
 func init() { file_device_device_proto_init() }
@@ -895,13 +4144,16 @@ func file_device_device_proto_init() {
 	if File_device_device_proto != nil {
 		return
 	}
+	file_device_device_proto_msgTypes[7].OneofWrappers = []any{}
+	file_device_device_proto_msgTypes[11].OneofWrappers = []any{}
+	file_device_device_proto_msgTypes[40].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_device_device_proto_rawDesc), len(file_device_device_proto_rawDesc)),
-			NumEnums:      1,
-			NumMessages:   15,
+			NumEnums:      5,
+			NumMessages:   61,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -23,12 +23,32 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	DeviceService_CreateDevice_FullMethodName = "/device.DeviceService/CreateDevice"
-	DeviceService_GetDevice_FullMethodName    = "/device.DeviceService/GetDevice"
-	DeviceService_ListDevices_FullMethodName  = "/device.DeviceService/ListDevices"
-	DeviceService_UpdateDevice_FullMethodName = "/device.DeviceService/UpdateDevice"
-	DeviceService_DeleteDevice_FullMethodName = "/device.DeviceService/DeleteDevice"
-	DeviceService_WatchDevices_FullMethodName = "/device.DeviceService/WatchDevices"
+	DeviceService_CreateDevice_FullMethodName       = "/device.DeviceService/CreateDevice"
+	DeviceService_GetDevice_FullMethodName          = "/device.DeviceService/GetDevice"
+	DeviceService_ListDevices_FullMethodName        = "/device.DeviceService/ListDevices"
+	DeviceService_UpdateDevice_FullMethodName       = "/device.DeviceService/UpdateDevice"
+	DeviceService_DeleteDevice_FullMethodName       = "/device.DeviceService/DeleteDevice"
+	DeviceService_WatchDevices_FullMethodName       = "/device.DeviceService/WatchDevices"
+	DeviceService_UpsertDeviceType_FullMethodName   = "/device.DeviceService/UpsertDeviceType"
+	DeviceService_GetDeviceType_FullMethodName      = "/device.DeviceService/GetDeviceType"
+	DeviceService_ListDeviceTypes_FullMethodName    = "/device.DeviceService/ListDeviceTypes"
+	DeviceService_DeleteDeviceType_FullMethodName   = "/device.DeviceService/DeleteDeviceType"
+	DeviceService_CreateLocation_FullMethodName     = "/device.DeviceService/CreateLocation"
+	DeviceService_GetLocation_FullMethodName        = "/device.DeviceService/GetLocation"
+	DeviceService_ListLocations_FullMethodName      = "/device.DeviceService/ListLocations"
+	DeviceService_UpdateLocation_FullMethodName     = "/device.DeviceService/UpdateLocation"
+	DeviceService_DeleteLocation_FullMethodName     = "/device.DeviceService/DeleteLocation"
+	DeviceService_CreateDeviceGroup_FullMethodName  = "/device.DeviceService/CreateDeviceGroup"
+	DeviceService_GetDeviceGroup_FullMethodName     = "/device.DeviceService/GetDeviceGroup"
+	DeviceService_ListDeviceGroups_FullMethodName   = "/device.DeviceService/ListDeviceGroups"
+	DeviceService_UpdateDeviceGroup_FullMethodName  = "/device.DeviceService/UpdateDeviceGroup"
+	DeviceService_DeleteDeviceGroup_FullMethodName  = "/device.DeviceService/DeleteDeviceGroup"
+	DeviceService_AddGroupDevices_FullMethodName    = "/device.DeviceService/AddGroupDevices"
+	DeviceService_RemoveGroupDevices_FullMethodName = "/device.DeviceService/RemoveGroupDevices"
+	DeviceService_CreateAccessEntry_FullMethodName  = "/device.DeviceService/CreateAccessEntry"
+	DeviceService_ListAccessEntries_FullMethodName  = "/device.DeviceService/ListAccessEntries"
+	DeviceService_DeleteAccessEntry_FullMethodName  = "/device.DeviceService/DeleteAccessEntry"
+	DeviceService_CheckDeviceAccess_FullMethodName  = "/device.DeviceService/CheckDeviceAccess"
 )
 
 // DeviceServiceClient is the client API for DeviceService service.
@@ -50,6 +70,46 @@ type DeviceServiceClient interface {
 	// Stream de mise à jour en temps réel (pour le monitoring)
 	// Le serveur envoie des updates en continu
 	WatchDevices(ctx context.Context, in *Empty, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Device], error)
+	// Créer ou remplacer un type de device et son catalogue de métriques
+	UpsertDeviceType(ctx context.Context, in *UpsertDeviceTypeRequest, opts ...grpc.CallOption) (*UpsertDeviceTypeResponse, error)
+	// Récupérer un type de device
+	GetDeviceType(ctx context.Context, in *GetDeviceTypeRequest, opts ...grpc.CallOption) (*GetDeviceTypeResponse, error)
+	// Lister les types de devices
+	ListDeviceTypes(ctx context.Context, in *ListDeviceTypesRequest, opts ...grpc.CallOption) (*ListDeviceTypesResponse, error)
+	// Supprimer un type de device (les devices existants sont conservés)
+	DeleteDeviceType(ctx context.Context, in *DeleteDeviceTypeRequest, opts ...grpc.CallOption) (*DeleteDeviceTypeResponse, error)
+	// Créer un emplacement (site, bâtiment, étage ou pièce)
+	CreateLocation(ctx context.Context, in *CreateLocationRequest, opts ...grpc.CallOption) (*CreateLocationResponse, error)
+	// Récupérer un emplacement
+	GetLocation(ctx context.Context, in *GetLocationRequest, opts ...grpc.CallOption) (*GetLocationResponse, error)
+	// Lister les emplacements
+	ListLocations(ctx context.Context, in *ListLocationsRequest, opts ...grpc.CallOption) (*ListLocationsResponse, error)
+	// Renommer un emplacement
+	UpdateLocation(ctx context.Context, in *UpdateLocationRequest, opts ...grpc.CallOption) (*UpdateLocationResponse, error)
+	// Supprimer un emplacement (ses devices n'ont plus d'emplacement)
+	DeleteLocation(ctx context.Context, in *DeleteLocationRequest, opts ...grpc.CallOption) (*DeleteLocationResponse, error)
+	// Créer un groupe de devices, statique ou dynamique
+	CreateDeviceGroup(ctx context.Context, in *CreateDeviceGroupRequest, opts ...grpc.CallOption) (*CreateDeviceGroupResponse, error)
+	// Récupérer un groupe
+	GetDeviceGroup(ctx context.Context, in *GetDeviceGroupRequest, opts ...grpc.CallOption) (*GetDeviceGroupResponse, error)
+	// Lister les groupes
+	ListDeviceGroups(ctx context.Context, in *ListDeviceGroupsRequest, opts ...grpc.CallOption) (*ListDeviceGroupsResponse, error)
+	// Modifier un groupe
+	UpdateDeviceGroup(ctx context.Context, in *UpdateDeviceGroupRequest, opts ...grpc.CallOption) (*UpdateDeviceGroupResponse, error)
+	// Supprimer un groupe
+	DeleteDeviceGroup(ctx context.Context, in *DeleteDeviceGroupRequest, opts ...grpc.CallOption) (*DeleteDeviceGroupResponse, error)
+	// Ajouter des devices à un groupe statique
+	AddGroupDevices(ctx context.Context, in *GroupDevicesRequest, opts ...grpc.CallOption) (*GroupDevicesResponse, error)
+	// Retirer des devices d'un groupe statique
+	RemoveGroupDevices(ctx context.Context, in *GroupDevicesRequest, opts ...grpc.CallOption) (*GroupDevicesResponse, error)
+	// Accorder un accès à un device ou à un groupe
+	CreateAccessEntry(ctx context.Context, in *CreateAccessEntryRequest, opts ...grpc.CallOption) (*CreateAccessEntryResponse, error)
+	// Lister les entrées d'ACL
+	ListAccessEntries(ctx context.Context, in *ListAccessEntriesRequest, opts ...grpc.CallOption) (*ListAccessEntriesResponse, error)
+	// Retirer un accès
+	DeleteAccessEntry(ctx context.Context, in *DeleteAccessEntryRequest, opts ...grpc.CallOption) (*DeleteAccessEntryResponse, error)
+	// Vérifier l'accès d'un appelant à des devices
+	CheckDeviceAccess(ctx context.Context, in *CheckDeviceAccessRequest, opts ...grpc.CallOption) (*CheckDeviceAccessResponse, error)
 }
 
 type deviceServiceClient struct {
@@ -129,6 +189,206 @@ func (c *deviceServiceClient) WatchDevices(ctx context.Context, in *Empty, opts
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type DeviceService_WatchDevicesClient = grpc.ServerStreamingClient[Device]
 
+func (c *deviceServiceClient) UpsertDeviceType(ctx context.Context, in *UpsertDeviceTypeRequest, opts ...grpc.CallOption) (*UpsertDeviceTypeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpsertDeviceTypeResponse)
+	err := c.cc.Invoke(ctx, DeviceService_UpsertDeviceType_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceServiceClient) GetDeviceType(ctx context.Context, in *GetDeviceTypeRequest, opts ...grpc.CallOption) (*GetDeviceTypeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetDeviceTypeResponse)
+	err := c.cc.Invoke(ctx, DeviceService_GetDeviceType_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceServiceClient) ListDeviceTypes(ctx context.Context, in *ListDeviceTypesRequest, opts ...grpc.CallOption) (*ListDeviceTypesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDeviceTypesResponse)
+	err := c.cc.Invoke(ctx, DeviceService_ListDeviceTypes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceServiceClient) DeleteDeviceType(ctx context.Context, in *DeleteDeviceTypeRequest, opts ...grpc.CallOption) (*DeleteDeviceTypeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteDeviceTypeResponse)
+	err := c.cc.Invoke(ctx, DeviceService_DeleteDeviceType_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceServiceClient) CreateLocation(ctx context.Context, in *CreateLocationRequest, opts ...grpc.CallOption) (*CreateLocationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateLocationResponse)
+	err := c.cc.Invoke(ctx, DeviceService_CreateLocation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceServiceClient) GetLocation(ctx context.Context, in *GetLocationRequest, opts ...grpc.CallOption) (*GetLocationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetLocationResponse)
+	err := c.cc.Invoke(ctx, DeviceService_GetLocation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceServiceClient) ListLocations(ctx context.Context, in *ListLocationsRequest, opts ...grpc.CallOption) (*ListLocationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListLocationsResponse)
+	err := c.cc.Invoke(ctx, DeviceService_ListLocations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceServiceClient) UpdateLocation(ctx context.Context, in *UpdateLocationRequest, opts ...grpc.CallOption) (*UpdateLocationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateLocationResponse)
+	err := c.cc.Invoke(ctx, DeviceService_UpdateLocation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceServiceClient) DeleteLocation(ctx context.Context, in *DeleteLocationRequest, opts ...grpc.CallOption) (*DeleteLocationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteLocationResponse)
+	err := c.cc.Invoke(ctx, DeviceService_DeleteLocation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceServiceClient) CreateDeviceGroup(ctx context.Context, in *CreateDeviceGroupRequest, opts ...grpc.CallOption) (*CreateDeviceGroupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateDeviceGroupResponse)
+	err := c.cc.Invoke(ctx, DeviceService_CreateDeviceGroup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceServiceClient) GetDeviceGroup(ctx context.Context, in *GetDeviceGroupRequest, opts ...grpc.CallOption) (*GetDeviceGroupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetDeviceGroupResponse)
+	err := c.cc.Invoke(ctx, DeviceService_GetDeviceGroup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceServiceClient) ListDeviceGroups(ctx context.Context, in *ListDeviceGroupsRequest, opts ...grpc.CallOption) (*ListDeviceGroupsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDeviceGroupsResponse)
+	err := c.cc.Invoke(ctx, DeviceService_ListDeviceGroups_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceServiceClient) UpdateDeviceGroup(ctx context.Context, in *UpdateDeviceGroupRequest, opts ...grpc.CallOption) (*UpdateDeviceGroupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateDeviceGroupResponse)
+	err := c.cc.Invoke(ctx, DeviceService_UpdateDeviceGroup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceServiceClient) DeleteDeviceGroup(ctx context.Context, in *DeleteDeviceGroupRequest, opts ...grpc.CallOption) (*DeleteDeviceGroupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteDeviceGroupResponse)
+	err := c.cc.Invoke(ctx, DeviceService_DeleteDeviceGroup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceServiceClient) AddGroupDevices(ctx context.Context, in *GroupDevicesRequest, opts ...grpc.CallOption) (*GroupDevicesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GroupDevicesResponse)
+	err := c.cc.Invoke(ctx, DeviceService_AddGroupDevices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceServiceClient) RemoveGroupDevices(ctx context.Context, in *GroupDevicesRequest, opts ...grpc.CallOption) (*GroupDevicesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GroupDevicesResponse)
+	err := c.cc.Invoke(ctx, DeviceService_RemoveGroupDevices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceServiceClient) CreateAccessEntry(ctx context.Context, in *CreateAccessEntryRequest, opts ...grpc.CallOption) (*CreateAccessEntryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateAccessEntryResponse)
+	err := c.cc.Invoke(ctx, DeviceService_CreateAccessEntry_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceServiceClient) ListAccessEntries(ctx context.Context, in *ListAccessEntriesRequest, opts ...grpc.CallOption) (*ListAccessEntriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAccessEntriesResponse)
+	err := c.cc.Invoke(ctx, DeviceService_ListAccessEntries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceServiceClient) DeleteAccessEntry(ctx context.Context, in *DeleteAccessEntryRequest, opts ...grpc.CallOption) (*DeleteAccessEntryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteAccessEntryResponse)
+	err := c.cc.Invoke(ctx, DeviceService_DeleteAccessEntry_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceServiceClient) CheckDeviceAccess(ctx context.Context, in *CheckDeviceAccessRequest, opts ...grpc.CallOption) (*CheckDeviceAccessResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckDeviceAccessResponse)
+	err := c.cc.Invoke(ctx, DeviceService_CheckDeviceAccess_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DeviceServiceServer is the server API for DeviceService service.
 // All implementations must embed UnimplementedDeviceServiceServer
 // for forward compatibility.
@@ -148,6 +408,46 @@ type DeviceServiceServer interface {
 	// Stream de mise à jour en temps réel (pour le monitoring)
 	// Le serveur envoie des updates en continu
 	WatchDevices(*Empty, grpc.ServerStreamingServer[Device]) error
+	// Créer ou remplacer un type de device et son catalogue de métriques
+	UpsertDeviceType(context.Context, *UpsertDeviceTypeRequest) (*UpsertDeviceTypeResponse, error)
+	// Récupérer un type de device
+	GetDeviceType(context.Context, *GetDeviceTypeRequest) (*GetDeviceTypeResponse, error)
+	// Lister les types de devices
+	ListDeviceTypes(context.Context, *ListDeviceTypesRequest) (*ListDeviceTypesResponse, error)
+	// Supprimer un type de device (les devices existants sont conservés)
+	DeleteDeviceType(context.Context, *DeleteDeviceTypeRequest) (*DeleteDeviceTypeResponse, error)
+	// Créer un emplacement (site, bâtiment, étage ou pièce)
+	CreateLocation(context.Context, *CreateLocationRequest) (*CreateLocationResponse, error)
+	// Récupérer un emplacement
+	GetLocation(context.Context, *GetLocationRequest) (*GetLocationResponse, error)
+	// Lister les emplacements
+	ListLocations(context.Context, *ListLocationsRequest) (*ListLocationsResponse, error)
+	// Renommer un emplacement
+	UpdateLocation(context.Context, *UpdateLocationRequest) (*UpdateLocationResponse, error)
+	// Supprimer un emplacement (ses devices n'ont plus d'emplacement)
+	DeleteLocation(context.Context, *DeleteLocationRequest) (*DeleteLocationResponse, error)
+	// Créer un groupe de devices, statique ou dynamique
+	CreateDeviceGroup(context.Context, *CreateDeviceGroupRequest) (*CreateDeviceGroupResponse, error)
+	// Récupérer un groupe
+	GetDeviceGroup(context.Context, *GetDeviceGroupRequest) (*GetDeviceGroupResponse, error)
+	// Lister les groupes
+	ListDeviceGroups(context.Context, *ListDeviceGroupsRequest) (*ListDeviceGroupsResponse, error)
+	// Modifier un groupe
+	UpdateDeviceGroup(context.Context, *UpdateDeviceGroupRequest) (*UpdateDeviceGroupResponse, error)
+	// Supprimer un groupe
+	DeleteDeviceGroup(context.Context, *DeleteDeviceGroupRequest) (*DeleteDeviceGroupResponse, error)
+	// Ajouter des devices à un groupe statique
+	AddGroupDevices(context.Context, *GroupDevicesRequest) (*GroupDevicesResponse, error)
+	// Retirer des devices d'un groupe statique
+	RemoveGroupDevices(context.Context, *GroupDevicesRequest) (*GroupDevicesResponse, error)
+	// Accorder un accès à un device ou à un groupe
+	CreateAccessEntry(context.Context, *CreateAccessEntryRequest) (*CreateAccessEntryResponse, error)
+	// Lister les entrées d'ACL
+	ListAccessEntries(context.Context, *ListAccessEntriesRequest) (*ListAccessEntriesResponse, error)
+	// Retirer un accès
+	DeleteAccessEntry(context.Context, *DeleteAccessEntryRequest) (*DeleteAccessEntryResponse, error)
+	// Vérifier l'accès d'un appelant à des devices
+	CheckDeviceAccess(context.Context, *CheckDeviceAccessRequest) (*CheckDeviceAccessResponse, error)
 	mustEmbedUnimplementedDeviceServiceServer()
 }
 
@@ -176,6 +476,66 @@ func (UnimplementedDeviceServiceServer) DeleteDevice(context.Context, *DeleteDev
 func (UnimplementedDeviceServiceServer) WatchDevices(*Empty, grpc.ServerStreamingServer[Device]) error {
 	return status.Error(codes.Unimplemented, "method WatchDevices not implemented")
 }
+func (UnimplementedDeviceServiceServer) UpsertDeviceType(context.Context, *UpsertDeviceTypeRequest) (*UpsertDeviceTypeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpsertDeviceType not implemented")
+}
+func (UnimplementedDeviceServiceServer) GetDeviceType(context.Context, *GetDeviceTypeRequest) (*GetDeviceTypeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetDeviceType not implemented")
+}
+func (UnimplementedDeviceServiceServer) ListDeviceTypes(context.Context, *ListDeviceTypesRequest) (*ListDeviceTypesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListDeviceTypes not implemented")
+}
+func (UnimplementedDeviceServiceServer) DeleteDeviceType(context.Context, *DeleteDeviceTypeRequest) (*DeleteDeviceTypeResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteDeviceType not implemented")
+}
+func (UnimplementedDeviceServiceServer) CreateLocation(context.Context, *CreateLocationRequest) (*CreateLocationResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateLocation not implemented")
+}
+func (UnimplementedDeviceServiceServer) GetLocation(context.Context, *GetLocationRequest) (*GetLocationResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetLocation not implemented")
+}
+func (UnimplementedDeviceServiceServer) ListLocations(context.Context, *ListLocationsRequest) (*ListLocationsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListLocations not implemented")
+}
+func (UnimplementedDeviceServiceServer) UpdateLocation(context.Context, *UpdateLocationRequest) (*UpdateLocationResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateLocation not implemented")
+}
+func (UnimplementedDeviceServiceServer) DeleteLocation(context.Context, *DeleteLocationRequest) (*DeleteLocationResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteLocation not implemented")
+}
+func (UnimplementedDeviceServiceServer) CreateDeviceGroup(context.Context, *CreateDeviceGroupRequest) (*CreateDeviceGroupResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateDeviceGroup not implemented")
+}
+func (UnimplementedDeviceServiceServer) GetDeviceGroup(context.Context, *GetDeviceGroupRequest) (*GetDeviceGroupResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetDeviceGroup not implemented")
+}
+func (UnimplementedDeviceServiceServer) ListDeviceGroups(context.Context, *ListDeviceGroupsRequest) (*ListDeviceGroupsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListDeviceGroups not implemented")
+}
+func (UnimplementedDeviceServiceServer) UpdateDeviceGroup(context.Context, *UpdateDeviceGroupRequest) (*UpdateDeviceGroupResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateDeviceGroup not implemented")
+}
+func (UnimplementedDeviceServiceServer) DeleteDeviceGroup(context.Context, *DeleteDeviceGroupRequest) (*DeleteDeviceGroupResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteDeviceGroup not implemented")
+}
+func (UnimplementedDeviceServiceServer) AddGroupDevices(context.Context, *GroupDevicesRequest) (*GroupDevicesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AddGroupDevices not implemented")
+}
+func (UnimplementedDeviceServiceServer) RemoveGroupDevices(context.Context, *GroupDevicesRequest) (*GroupDevicesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RemoveGroupDevices not implemented")
+}
+func (UnimplementedDeviceServiceServer) CreateAccessEntry(context.Context, *CreateAccessEntryRequest) (*CreateAccessEntryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateAccessEntry not implemented")
+}
+func (UnimplementedDeviceServiceServer) ListAccessEntries(context.Context, *ListAccessEntriesRequest) (*ListAccessEntriesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListAccessEntries not implemented")
+}
+func (UnimplementedDeviceServiceServer) DeleteAccessEntry(context.Context, *DeleteAccessEntryRequest) (*DeleteAccessEntryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteAccessEntry not implemented")
+}
+func (UnimplementedDeviceServiceServer) CheckDeviceAccess(context.Context, *CheckDeviceAccessRequest) (*CheckDeviceAccessResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CheckDeviceAccess not implemented")
+}
 func (UnimplementedDeviceServiceServer) mustEmbedUnimplementedDeviceServiceServer() {}
 func (UnimplementedDeviceServiceServer) testEmbeddedByValue()                       {}
 
@@ -298,6 +658,366 @@ func _DeviceService_WatchDevices_Handler(srv interface{}, stream grpc.ServerStre
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type DeviceService_WatchDevicesServer = grpc.ServerStreamingServer[Device]
 
+func _DeviceService_UpsertDeviceType_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpsertDeviceTypeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServiceServer).UpsertDeviceType(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceService_UpsertDeviceType_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServiceServer).UpsertDeviceType(ctx, req.(*UpsertDeviceTypeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceService_GetDeviceType_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDeviceTypeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServiceServer).GetDeviceType(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceService_GetDeviceType_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServiceServer).GetDeviceType(ctx, req.(*GetDeviceTypeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceService_ListDeviceTypes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeviceTypesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServiceServer).ListDeviceTypes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceService_ListDeviceTypes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServiceServer).ListDeviceTypes(ctx, req.(*ListDeviceTypesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceService_DeleteDeviceType_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteDeviceTypeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServiceServer).DeleteDeviceType(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceService_DeleteDeviceType_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServiceServer).DeleteDeviceType(ctx, req.(*DeleteDeviceTypeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceService_CreateLocation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateLocationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServiceServer).CreateLocation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceService_CreateLocation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServiceServer).CreateLocation(ctx, req.(*CreateLocationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceService_GetLocation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLocationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServiceServer).GetLocation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceService_GetLocation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServiceServer).GetLocation(ctx, req.(*GetLocationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceService_ListLocations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListLocationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServiceServer).ListLocations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceService_ListLocations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServiceServer).ListLocations(ctx, req.(*ListLocationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceService_UpdateLocation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateLocationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServiceServer).UpdateLocation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceService_UpdateLocation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServiceServer).UpdateLocation(ctx, req.(*UpdateLocationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceService_DeleteLocation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteLocationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServiceServer).DeleteLocation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceService_DeleteLocation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServiceServer).DeleteLocation(ctx, req.(*DeleteLocationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceService_CreateDeviceGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateDeviceGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServiceServer).CreateDeviceGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceService_CreateDeviceGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServiceServer).CreateDeviceGroup(ctx, req.(*CreateDeviceGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceService_GetDeviceGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDeviceGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServiceServer).GetDeviceGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceService_GetDeviceGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServiceServer).GetDeviceGroup(ctx, req.(*GetDeviceGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceService_ListDeviceGroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeviceGroupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServiceServer).ListDeviceGroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceService_ListDeviceGroups_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServiceServer).ListDeviceGroups(ctx, req.(*ListDeviceGroupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceService_UpdateDeviceGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateDeviceGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServiceServer).UpdateDeviceGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceService_UpdateDeviceGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServiceServer).UpdateDeviceGroup(ctx, req.(*UpdateDeviceGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceService_DeleteDeviceGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteDeviceGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServiceServer).DeleteDeviceGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceService_DeleteDeviceGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServiceServer).DeleteDeviceGroup(ctx, req.(*DeleteDeviceGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceService_AddGroupDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GroupDevicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServiceServer).AddGroupDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceService_AddGroupDevices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServiceServer).AddGroupDevices(ctx, req.(*GroupDevicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceService_RemoveGroupDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GroupDevicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServiceServer).RemoveGroupDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceService_RemoveGroupDevices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServiceServer).RemoveGroupDevices(ctx, req.(*GroupDevicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceService_CreateAccessEntry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAccessEntryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServiceServer).CreateAccessEntry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceService_CreateAccessEntry_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServiceServer).CreateAccessEntry(ctx, req.(*CreateAccessEntryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceService_ListAccessEntries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAccessEntriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServiceServer).ListAccessEntries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceService_ListAccessEntries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServiceServer).ListAccessEntries(ctx, req.(*ListAccessEntriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceService_DeleteAccessEntry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteAccessEntryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServiceServer).DeleteAccessEntry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceService_DeleteAccessEntry_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServiceServer).DeleteAccessEntry(ctx, req.(*DeleteAccessEntryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceService_CheckDeviceAccess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckDeviceAccessRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceServiceServer).CheckDeviceAccess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceService_CheckDeviceAccess_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceServiceServer).CheckDeviceAccess(ctx, req.(*CheckDeviceAccessRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // DeviceService_ServiceDesc is the grpc.ServiceDesc for DeviceService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -325,6 +1045,86 @@ var DeviceService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteDevice",
 			Handler:    _DeviceService_DeleteDevice_Handler,
 		},
+		{
+			MethodName: "UpsertDeviceType",
+			Handler:    _DeviceService_UpsertDeviceType_Handler,
+		},
+		{
+			MethodName: "GetDeviceType",
+			Handler:    _DeviceService_GetDeviceType_Handler,
+		},
+		{
+			MethodName: "ListDeviceTypes",
+			Handler:    _DeviceService_ListDeviceTypes_Handler,
+		},
+		{
+			MethodName: "DeleteDeviceType",
+			Handler:    _DeviceService_DeleteDeviceType_Handler,
+		},
+		{
+			MethodName: "CreateLocation",
+			Handler:    _DeviceService_CreateLocation_Handler,
+		},
+		{
+			MethodName: "GetLocation",
+			Handler:    _DeviceService_GetLocation_Handler,
+		},
+		{
+			MethodName: "ListLocations",
+			Handler:    _DeviceService_ListLocations_Handler,
+		},
+		{
+			MethodName: "UpdateLocation",
+			Handler:    _DeviceService_UpdateLocation_Handler,
+		},
+		{
+			MethodName: "DeleteLocation",
+			Handler:    _DeviceService_DeleteLocation_Handler,
+		},
+		{
+			MethodName: "CreateDeviceGroup",
+			Handler:    _DeviceService_CreateDeviceGroup_Handler,
+		},
+		{
+			MethodName: "GetDeviceGroup",
+			Handler:    _DeviceService_GetDeviceGroup_Handler,
+		},
+		{
+			MethodName: "ListDeviceGroups",
+			Handler:    _DeviceService_ListDeviceGroups_Handler,
+		},
+		{
+			MethodName: "UpdateDeviceGroup",
+			Handler:    _DeviceService_UpdateDeviceGroup_Handler,
+		},
+		{
+			MethodName: "DeleteDeviceGroup",
+			Handler:    _DeviceService_DeleteDeviceGroup_Handler,
+		},
+		{
+			MethodName: "AddGroupDevices",
+			Handler:    _DeviceService_AddGroupDevices_Handler,
+		},
+		{
+			MethodName: "RemoveGroupDevices",
+			Handler:    _DeviceService_RemoveGroupDevices_Handler,
+		},
+		{
+			MethodName: "CreateAccessEntry",
+			Handler:    _DeviceService_CreateAccessEntry_Handler,
+		},
+		{
+			MethodName: "ListAccessEntries",
+			Handler:    _DeviceService_ListAccessEntries_Handler,
+		},
+		{
+			MethodName: "DeleteAccessEntry",
+			Handler:    _DeviceService_DeleteAccessEntry_Handler,
+		},
+		{
+			MethodName: "CheckDeviceAccess",
+			Handler:    _DeviceService_CheckDeviceAccess_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
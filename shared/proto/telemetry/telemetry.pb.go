@@ -25,6 +25,162 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// How series are grouped in a batch query
+type TelemetryGroupBy int32
+
+const (
+	TelemetryGroupBy_GROUP_BY_DEVICE      TelemetryGroupBy = 0 // One series per device and metric (default)
+	TelemetryGroupBy_GROUP_BY_DEVICE_TYPE TelemetryGroupBy = 1 // Aggregate across devices of the same type
+	TelemetryGroupBy_GROUP_BY_ALL         TelemetryGroupBy = 2 // Aggregate across all matching devices
+	TelemetryGroupBy_GROUP_BY_GROUP       TelemetryGroupBy = 3 // Aggregate across devices of each group of filter.group_ids
+	TelemetryGroupBy_GROUP_BY_LOCATION    TelemetryGroupBy = 4 // Aggregate across devices of each location
+)
+
+// Enum value maps for TelemetryGroupBy.
+var (
+	TelemetryGroupBy_name = map[int32]string{
+		0: "GROUP_BY_DEVICE",
+		1: "GROUP_BY_DEVICE_TYPE",
+		2: "GROUP_BY_ALL",
+		3: "GROUP_BY_GROUP",
+		4: "GROUP_BY_LOCATION",
+	}
+	TelemetryGroupBy_value = map[string]int32{
+		"GROUP_BY_DEVICE":      0,
+		"GROUP_BY_DEVICE_TYPE": 1,
+		"GROUP_BY_ALL":         2,
+		"GROUP_BY_GROUP":       3,
+		"GROUP_BY_LOCATION":    4,
+	}
+)
+
+func (x TelemetryGroupBy) Enum() *TelemetryGroupBy {
+	p := new(TelemetryGroupBy)
+	*p = x
+	return p
+}
+
+func (x TelemetryGroupBy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TelemetryGroupBy) Descriptor() protoreflect.EnumDescriptor {
+	return file_telemetry_telemetry_proto_enumTypes[0].Descriptor()
+}
+
+func (TelemetryGroupBy) Type() protoreflect.EnumType {
+	return &file_telemetry_telemetry_proto_enumTypes[0]
+}
+
+func (x TelemetryGroupBy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TelemetryGroupBy.Descriptor instead.
+func (TelemetryGroupBy) EnumDescriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{0}
+}
+
+// How imported rows that already exist (same device, metric and time) are handled
+type ImportConflictPolicy int32
+
+const (
+	ImportConflictPolicy_IMPORT_CONFLICT_SKIP      ImportConflictPolicy = 0 // Keep the stored row (default)
+	ImportConflictPolicy_IMPORT_CONFLICT_OVERWRITE ImportConflictPolicy = 1 // Replace the stored row with the imported one
+	ImportConflictPolicy_IMPORT_CONFLICT_FAIL      ImportConflictPolicy = 2 // Abort the whole import
+)
+
+// Enum value maps for ImportConflictPolicy.
+var (
+	ImportConflictPolicy_name = map[int32]string{
+		0: "IMPORT_CONFLICT_SKIP",
+		1: "IMPORT_CONFLICT_OVERWRITE",
+		2: "IMPORT_CONFLICT_FAIL",
+	}
+	ImportConflictPolicy_value = map[string]int32{
+		"IMPORT_CONFLICT_SKIP":      0,
+		"IMPORT_CONFLICT_OVERWRITE": 1,
+		"IMPORT_CONFLICT_FAIL":      2,
+	}
+)
+
+func (x ImportConflictPolicy) Enum() *ImportConflictPolicy {
+	p := new(ImportConflictPolicy)
+	*p = x
+	return p
+}
+
+func (x ImportConflictPolicy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ImportConflictPolicy) Descriptor() protoreflect.EnumDescriptor {
+	return file_telemetry_telemetry_proto_enumTypes[1].Descriptor()
+}
+
+func (ImportConflictPolicy) Type() protoreflect.EnumType {
+	return &file_telemetry_telemetry_proto_enumTypes[1]
+}
+
+func (x ImportConflictPolicy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ImportConflictPolicy.Descriptor instead.
+func (ImportConflictPolicy) EnumDescriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{1}
+}
+
+// How a derived metric is computed
+type DerivedMetricKind int32
+
+const (
+	DerivedMetricKind_DERIVED_METRIC_EXPRESSION     DerivedMetricKind = 0 // Expression over the device's metrics
+	DerivedMetricKind_DERIVED_METRIC_INTEGRAL       DerivedMetricKind = 1 // Time integral of source_metric (x hours)
+	DerivedMetricKind_DERIVED_METRIC_MOVING_AVERAGE DerivedMetricKind = 2 // Average of source_metric over window
+)
+
+// Enum value maps for DerivedMetricKind.
+var (
+	DerivedMetricKind_name = map[int32]string{
+		0: "DERIVED_METRIC_EXPRESSION",
+		1: "DERIVED_METRIC_INTEGRAL",
+		2: "DERIVED_METRIC_MOVING_AVERAGE",
+	}
+	DerivedMetricKind_value = map[string]int32{
+		"DERIVED_METRIC_EXPRESSION":     0,
+		"DERIVED_METRIC_INTEGRAL":       1,
+		"DERIVED_METRIC_MOVING_AVERAGE": 2,
+	}
+)
+
+func (x DerivedMetricKind) Enum() *DerivedMetricKind {
+	p := new(DerivedMetricKind)
+	*p = x
+	return p
+}
+
+func (x DerivedMetricKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DerivedMetricKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_telemetry_telemetry_proto_enumTypes[2].Descriptor()
+}
+
+func (DerivedMetricKind) Type() protoreflect.EnumType {
+	return &file_telemetry_telemetry_proto_enumTypes[2]
+}
+
+func (x DerivedMetricKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DerivedMetricKind.Descriptor instead.
+func (DerivedMetricKind) EnumDescriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{2}
+}
+
 // A single telemetry data point
 type TelemetryPoint struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -171,6 +327,8 @@ type GetTelemetryRequest struct {
 	FromTime      int64                  `protobuf:"varint,3,opt,name=from_time,json=fromTime,proto3" json:"from_time,omitempty"`      // Start time (Unix timestamp)
 	ToTime        int64                  `protobuf:"varint,4,opt,name=to_time,json=toTime,proto3" json:"to_time,omitempty"`            // End time (Unix timestamp)
 	Limit         int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`                            // Maximum number of points (default: 1000)
+	Unit          string                 `protobuf:"bytes,6,opt,name=unit,proto3" json:"unit,omitempty"`                               // Target unit (optional, e.g. "fahrenheit")
+	OrgId         string                 `protobuf:"bytes,7,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`                // Organization of the caller (empty: all, internal calls)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -240,6 +398,20 @@ func (x *GetTelemetryRequest) GetLimit() int32 {
 	return 0
 }
 
+func (x *GetTelemetryRequest) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *GetTelemetryRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
 // Response with raw telemetry data
 type GetTelemetryResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -293,6 +465,8 @@ type GetTelemetryAggregatedRequest struct {
 	FromTime      int64                  `protobuf:"varint,3,opt,name=from_time,json=fromTime,proto3" json:"from_time,omitempty"`      // Start time (Unix timestamp)
 	ToTime        int64                  `protobuf:"varint,4,opt,name=to_time,json=toTime,proto3" json:"to_time,omitempty"`            // End time (Unix timestamp)
 	Interval      string                 `protobuf:"bytes,5,opt,name=interval,proto3" json:"interval,omitempty"`                       // Aggregation interval (e.g., "1 hour", "1 day")
+	Unit          string                 `protobuf:"bytes,6,opt,name=unit,proto3" json:"unit,omitempty"`                               // Target unit (optional)
+	OrgId         string                 `protobuf:"bytes,7,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`                // Organization of the caller (empty: all, internal calls)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -362,6 +536,20 @@ func (x *GetTelemetryAggregatedRequest) GetInterval() string {
 	return ""
 }
 
+func (x *GetTelemetryAggregatedRequest) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *GetTelemetryAggregatedRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
 // Response with aggregated telemetry data
 type GetTelemetryAggregatedResponse struct {
 	state         protoimpl.MessageState  `protogen:"open.v1"`
@@ -412,6 +600,8 @@ type GetLatestMetricRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`       // Device UUID
 	MetricName    string                 `protobuf:"bytes,2,opt,name=metric_name,json=metricName,proto3" json:"metric_name,omitempty"` // Metric name
+	Unit          string                 `protobuf:"bytes,3,opt,name=unit,proto3" json:"unit,omitempty"`                               // Target unit (optional)
+	OrgId         string                 `protobuf:"bytes,4,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`                // Organization of the caller (empty: all, internal calls)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -460,6 +650,20 @@ func (x *GetLatestMetricRequest) GetMetricName() string {
 	return ""
 }
 
+func (x *GetLatestMetricRequest) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *GetLatestMetricRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
 // Response with the latest metric value
 type GetLatestMetricResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -509,6 +713,7 @@ func (x *GetLatestMetricResponse) GetPoint() *TelemetryPoint {
 type GetDeviceMetricsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"` // Device UUID
+	OrgId         string                 `protobuf:"bytes,2,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`          // Organization of the caller (empty: all, internal calls)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -550,17 +755,134 @@ func (x *GetDeviceMetricsRequest) GetDeviceId() string {
 	return ""
 }
 
+func (x *GetDeviceMetricsRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+// A metric of a device: declared in its type's catalog, reported, or both
+type MetricInfo struct {
+	state                    protoimpl.MessageState `protogen:"open.v1"`
+	Name                     string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	DisplayName              string                 `protobuf:"bytes,2,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"` // Catalog display name, or name when undeclared
+	Unit                     string                 `protobuf:"bytes,3,opt,name=unit,proto3" json:"unit,omitempty"`                                  // Catalog unit, or last reported unit when undeclared
+	DataType                 string                 `protobuf:"bytes,4,opt,name=data_type,json=dataType,proto3" json:"data_type,omitempty"`          // "float", "integer" or "boolean"
+	MinValue                 *float64               `protobuf:"fixed64,5,opt,name=min_value,json=minValue,proto3,oneof" json:"min_value,omitempty"`
+	MaxValue                 *float64               `protobuf:"fixed64,6,opt,name=max_value,json=maxValue,proto3,oneof" json:"max_value,omitempty"`
+	ReportingIntervalSeconds int32                  `protobuf:"varint,7,opt,name=reporting_interval_seconds,json=reportingIntervalSeconds,proto3" json:"reporting_interval_seconds,omitempty"` // Expected interval (0: unknown)
+	Declared                 bool                   `protobuf:"varint,8,opt,name=declared,proto3" json:"declared,omitempty"`                                                                   // Declared in the device type catalog
+	LastTime                 int64                  `protobuf:"varint,9,opt,name=last_time,json=lastTime,proto3" json:"last_time,omitempty"`                                                   // Unix timestamp of the latest point (0: never reported)
+	unknownFields            protoimpl.UnknownFields
+	sizeCache                protoimpl.SizeCache
+}
+
+func (x *MetricInfo) Reset() {
+	*x = MetricInfo{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricInfo) ProtoMessage() {}
+
+func (x *MetricInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricInfo.ProtoReflect.Descriptor instead.
+func (*MetricInfo) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *MetricInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *MetricInfo) GetDisplayName() string {
+	if x != nil {
+		return x.DisplayName
+	}
+	return ""
+}
+
+func (x *MetricInfo) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *MetricInfo) GetDataType() string {
+	if x != nil {
+		return x.DataType
+	}
+	return ""
+}
+
+func (x *MetricInfo) GetMinValue() float64 {
+	if x != nil && x.MinValue != nil {
+		return *x.MinValue
+	}
+	return 0
+}
+
+func (x *MetricInfo) GetMaxValue() float64 {
+	if x != nil && x.MaxValue != nil {
+		return *x.MaxValue
+	}
+	return 0
+}
+
+func (x *MetricInfo) GetReportingIntervalSeconds() int32 {
+	if x != nil {
+		return x.ReportingIntervalSeconds
+	}
+	return 0
+}
+
+func (x *MetricInfo) GetDeclared() bool {
+	if x != nil {
+		return x.Declared
+	}
+	return false
+}
+
+func (x *MetricInfo) GetLastTime() int64 {
+	if x != nil {
+		return x.LastTime
+	}
+	return 0
+}
+
 // Response with list of metric names
 type GetDeviceMetricsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Metrics       []string               `protobuf:"bytes,1,rep,name=metrics,proto3" json:"metrics,omitempty"`
+	Catalog       []*MetricInfo          `protobuf:"bytes,2,rep,name=catalog,proto3" json:"catalog,omitempty"` // Same metrics, with catalog metadata
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetDeviceMetricsResponse) Reset() {
 	*x = GetDeviceMetricsResponse{}
-	mi := &file_telemetry_telemetry_proto_msgTypes[9]
+	mi := &file_telemetry_telemetry_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -572,7 +894,7 @@ func (x *GetDeviceMetricsResponse) String() string {
 func (*GetDeviceMetricsResponse) ProtoMessage() {}
 
 func (x *GetDeviceMetricsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_telemetry_telemetry_proto_msgTypes[9]
+	mi := &file_telemetry_telemetry_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -585,7 +907,7 @@ func (x *GetDeviceMetricsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetDeviceMetricsResponse.ProtoReflect.Descriptor instead.
 func (*GetDeviceMetricsResponse) Descriptor() ([]byte, []int) {
-	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{9}
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *GetDeviceMetricsResponse) GetMetrics() []string {
@@ -595,54 +917,2122 @@ func (x *GetDeviceMetricsResponse) GetMetrics() []string {
 	return nil
 }
 
-var File_telemetry_telemetry_proto protoreflect.FileDescriptor
+func (x *GetDeviceMetricsResponse) GetCatalog() []*MetricInfo {
+	if x != nil {
+		return x.Catalog
+	}
+	return nil
+}
 
-const file_telemetry_telemetry_proto_rawDesc = "" +
-	"\n" +
-	"\x19telemetry/telemetry.proto\x12\ttelemetry\"N\n" +
-	"\x0eTelemetryPoint\x12\x12\n" +
-	"\x04time\x18\x01 \x01(\x03R\x04time\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\x01R\x05value\x12\x12\n" +
-	"\x04unit\x18\x03 \x01(\tR\x04unit\"z\n" +
-	"\x14TelemetryAggregation\x12\x16\n" +
-	"\x06bucket\x18\x01 \x01(\tR\x06bucket\x12\x10\n" +
-	"\x03avg\x18\x02 \x01(\x01R\x03avg\x12\x10\n" +
-	"\x03min\x18\x03 \x01(\x01R\x03min\x12\x10\n" +
-	"\x03max\x18\x04 \x01(\x01R\x03max\x12\x14\n" +
-	"\x05count\x18\x05 \x01(\x03R\x05count\"\x9f\x01\n" +
-	"\x13GetTelemetryRequest\x12\x1b\n" +
-	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x1f\n" +
-	"\vmetric_name\x18\x02 \x01(\tR\n" +
-	"metricName\x12\x1b\n" +
-	"\tfrom_time\x18\x03 \x01(\x03R\bfromTime\x12\x17\n" +
-	"\ato_time\x18\x04 \x01(\x03R\x06toTime\x12\x14\n" +
-	"\x05limit\x18\x05 \x01(\x05R\x05limit\"I\n" +
-	"\x14GetTelemetryResponse\x121\n" +
-	"\x06points\x18\x01 \x03(\v2\x19.telemetry.TelemetryPointR\x06points\"\xaf\x01\n" +
-	"\x1dGetTelemetryAggregatedRequest\x12\x1b\n" +
-	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x1f\n" +
-	"\vmetric_name\x18\x02 \x01(\tR\n" +
-	"metricName\x12\x1b\n" +
-	"\tfrom_time\x18\x03 \x01(\x03R\bfromTime\x12\x17\n" +
-	"\ato_time\x18\x04 \x01(\x03R\x06toTime\x12\x1a\n" +
-	"\binterval\x18\x05 \x01(\tR\binterval\"e\n" +
-	"\x1eGetTelemetryAggregatedResponse\x12C\n" +
-	"\faggregations\x18\x01 \x03(\v2\x1f.telemetry.TelemetryAggregationR\faggregations\"V\n" +
-	"\x16GetLatestMetricRequest\x12\x1b\n" +
-	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x1f\n" +
-	"\vmetric_name\x18\x02 \x01(\tR\n" +
-	"metricName\"J\n" +
-	"\x17GetLatestMetricResponse\x12/\n" +
-	"\x05point\x18\x01 \x01(\v2\x19.telemetry.TelemetryPointR\x05point\"6\n" +
-	"\x17GetDeviceMetricsRequest\x12\x1b\n" +
-	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\"4\n" +
-	"\x18GetDeviceMetricsResponse\x12\x18\n" +
-	"\ametrics\x18\x01 \x03(\tR\ametrics2\x89\x03\n" +
+// Selects devices by type and/or metadata instead of explicit IDs
+type DeviceFilter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`                                                                                   // Device type (e.g., "temperature_sensor")
+	Metadata      map[string]string      `protobuf:"bytes,2,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Metadata entries that must all match
+	GroupIds      []string               `protobuf:"bytes,3,rep,name=group_ids,json=groupIds,proto3" json:"group_ids,omitempty"`                                                           // Devices of any of these groups
+	LocationId    string                 `protobuf:"bytes,4,opt,name=location_id,json=locationId,proto3" json:"location_id,omitempty"`                                                     // Devices of this location or its sub-locations
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeviceFilter) Reset() {
+	*x = DeviceFilter{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceFilter) ProtoMessage() {}
+
+func (x *DeviceFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceFilter.ProtoReflect.Descriptor instead.
+func (*DeviceFilter) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DeviceFilter) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *DeviceFilter) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *DeviceFilter) GetGroupIds() []string {
+	if x != nil {
+		return x.GroupIds
+	}
+	return nil
+}
+
+func (x *DeviceFilter) GetLocationId() string {
+	if x != nil {
+		return x.LocationId
+	}
+	return ""
+}
+
+// Request to query several devices and metrics at once
+type GetTelemetryBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceIds     []string               `protobuf:"bytes,1,rep,name=device_ids,json=deviceIds,proto3" json:"device_ids,omitempty"`                            // Device UUIDs (optional if filter is set)
+	Filter        *DeviceFilter          `protobuf:"bytes,2,opt,name=filter,proto3" json:"filter,omitempty"`                                                   // Device filter (optional if device_ids is set)
+	MetricNames   []string               `protobuf:"bytes,3,rep,name=metric_names,json=metricNames,proto3" json:"metric_names,omitempty"`                      // Metric names (at least one)
+	FromTime      int64                  `protobuf:"varint,4,opt,name=from_time,json=fromTime,proto3" json:"from_time,omitempty"`                              // Start time (Unix timestamp)
+	ToTime        int64                  `protobuf:"varint,5,opt,name=to_time,json=toTime,proto3" json:"to_time,omitempty"`                                    // End time (Unix timestamp)
+	Interval      string                 `protobuf:"bytes,6,opt,name=interval,proto3" json:"interval,omitempty"`                                               // Aggregation interval (e.g., "1 hour")
+	GroupBy       TelemetryGroupBy       `protobuf:"varint,7,opt,name=group_by,json=groupBy,proto3,enum=telemetry.TelemetryGroupBy" json:"group_by,omitempty"` // Grouping mode (default: per device)
+	Unit          string                 `protobuf:"bytes,8,opt,name=unit,proto3" json:"unit,omitempty"`                                                       // Target unit (optional)
+	OrgId         string                 `protobuf:"bytes,9,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`                                        // Organization of the caller (empty: all, internal calls)
+	LocationKind  string                 `protobuf:"bytes,10,opt,name=location_kind,json=locationKind,proto3" json:"location_kind,omitempty"`                  // GROUP_BY_LOCATION: level to aggregate at ("site", "building",
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTelemetryBatchRequest) Reset() {
+	*x = GetTelemetryBatchRequest{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTelemetryBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTelemetryBatchRequest) ProtoMessage() {}
+
+func (x *GetTelemetryBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTelemetryBatchRequest.ProtoReflect.Descriptor instead.
+func (*GetTelemetryBatchRequest) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetTelemetryBatchRequest) GetDeviceIds() []string {
+	if x != nil {
+		return x.DeviceIds
+	}
+	return nil
+}
+
+func (x *GetTelemetryBatchRequest) GetFilter() *DeviceFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+func (x *GetTelemetryBatchRequest) GetMetricNames() []string {
+	if x != nil {
+		return x.MetricNames
+	}
+	return nil
+}
+
+func (x *GetTelemetryBatchRequest) GetFromTime() int64 {
+	if x != nil {
+		return x.FromTime
+	}
+	return 0
+}
+
+func (x *GetTelemetryBatchRequest) GetToTime() int64 {
+	if x != nil {
+		return x.ToTime
+	}
+	return 0
+}
+
+func (x *GetTelemetryBatchRequest) GetInterval() string {
+	if x != nil {
+		return x.Interval
+	}
+	return ""
+}
+
+func (x *GetTelemetryBatchRequest) GetGroupBy() TelemetryGroupBy {
+	if x != nil {
+		return x.GroupBy
+	}
+	return TelemetryGroupBy_GROUP_BY_DEVICE
+}
+
+func (x *GetTelemetryBatchRequest) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *GetTelemetryBatchRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+func (x *GetTelemetryBatchRequest) GetLocationKind() string {
+	if x != nil {
+		return x.LocationKind
+	}
+	return ""
+}
+
+// A series of aligned aggregation buckets for one group and metric
+type TelemetryBatchSeries struct {
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	DeviceId      string                  `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`       // Device UUID (empty unless grouped by device)
+	DeviceType    string                  `protobuf:"bytes,2,opt,name=device_type,json=deviceType,proto3" json:"device_type,omitempty"` // Device type (empty when grouped across all devices)
+	MetricName    string                  `protobuf:"bytes,3,opt,name=metric_name,json=metricName,proto3" json:"metric_name,omitempty"` // Metric name
+	Aggregations  []*TelemetryAggregation `protobuf:"bytes,4,rep,name=aggregations,proto3" json:"aggregations,omitempty"`
+	GroupId       string                  `protobuf:"bytes,5,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`          // Device group (grouped by group only)
+	LocationId    string                  `protobuf:"bytes,6,opt,name=location_id,json=locationId,proto3" json:"location_id,omitempty"` // Location (grouped by location only, empty: no location)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TelemetryBatchSeries) Reset() {
+	*x = TelemetryBatchSeries{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TelemetryBatchSeries) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TelemetryBatchSeries) ProtoMessage() {}
+
+func (x *TelemetryBatchSeries) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TelemetryBatchSeries.ProtoReflect.Descriptor instead.
+func (*TelemetryBatchSeries) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *TelemetryBatchSeries) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *TelemetryBatchSeries) GetDeviceType() string {
+	if x != nil {
+		return x.DeviceType
+	}
+	return ""
+}
+
+func (x *TelemetryBatchSeries) GetMetricName() string {
+	if x != nil {
+		return x.MetricName
+	}
+	return ""
+}
+
+func (x *TelemetryBatchSeries) GetAggregations() []*TelemetryAggregation {
+	if x != nil {
+		return x.Aggregations
+	}
+	return nil
+}
+
+func (x *TelemetryBatchSeries) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *TelemetryBatchSeries) GetLocationId() string {
+	if x != nil {
+		return x.LocationId
+	}
+	return ""
+}
+
+// Response with one series per group and metric
+type GetTelemetryBatchResponse struct {
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	Series        []*TelemetryBatchSeries `protobuf:"bytes,1,rep,name=series,proto3" json:"series,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTelemetryBatchResponse) Reset() {
+	*x = GetTelemetryBatchResponse{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTelemetryBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTelemetryBatchResponse) ProtoMessage() {}
+
+func (x *GetTelemetryBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTelemetryBatchResponse.ProtoReflect.Descriptor instead.
+func (*GetTelemetryBatchResponse) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetTelemetryBatchResponse) GetSeries() []*TelemetryBatchSeries {
+	if x != nil {
+		return x.Series
+	}
+	return nil
+}
+
+// Request to export raw telemetry as a stream of chunks
+type ExportTelemetryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceIds     []string               `protobuf:"bytes,1,rep,name=device_ids,json=deviceIds,proto3" json:"device_ids,omitempty"`       // Device UUIDs (optional, default: all devices)
+	MetricNames   []string               `protobuf:"bytes,2,rep,name=metric_names,json=metricNames,proto3" json:"metric_names,omitempty"` // Metric names (optional, default: all metrics)
+	FromTime      int64                  `protobuf:"varint,3,opt,name=from_time,json=fromTime,proto3" json:"from_time,omitempty"`         // Start time (Unix timestamp)
+	ToTime        int64                  `protobuf:"varint,4,opt,name=to_time,json=toTime,proto3" json:"to_time,omitempty"`               // End time (Unix timestamp)
+	Cursor        string                 `protobuf:"bytes,5,opt,name=cursor,proto3" json:"cursor,omitempty"`                              // Resume after this position (optional)
+	PageSize      int32                  `protobuf:"varint,6,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`         // Records per chunk (default: 5000)
+	OrgId         string                 `protobuf:"bytes,7,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`                   // Organization of the caller (empty: all, internal calls)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportTelemetryRequest) Reset() {
+	*x = ExportTelemetryRequest{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportTelemetryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportTelemetryRequest) ProtoMessage() {}
+
+func (x *ExportTelemetryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportTelemetryRequest.ProtoReflect.Descriptor instead.
+func (*ExportTelemetryRequest) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ExportTelemetryRequest) GetDeviceIds() []string {
+	if x != nil {
+		return x.DeviceIds
+	}
+	return nil
+}
+
+func (x *ExportTelemetryRequest) GetMetricNames() []string {
+	if x != nil {
+		return x.MetricNames
+	}
+	return nil
+}
+
+func (x *ExportTelemetryRequest) GetFromTime() int64 {
+	if x != nil {
+		return x.FromTime
+	}
+	return 0
+}
+
+func (x *ExportTelemetryRequest) GetToTime() int64 {
+	if x != nil {
+		return x.ToTime
+	}
+	return 0
+}
+
+func (x *ExportTelemetryRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *ExportTelemetryRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ExportTelemetryRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+// A raw telemetry row with its device and metric
+type TelemetryRecord struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`       // Device UUID
+	MetricName    string                 `protobuf:"bytes,2,opt,name=metric_name,json=metricName,proto3" json:"metric_name,omitempty"` // Metric name
+	Time          int64                  `protobuf:"varint,3,opt,name=time,proto3" json:"time,omitempty"`                              // Unix timestamp
+	Value         float64                `protobuf:"fixed64,4,opt,name=value,proto3" json:"value,omitempty"`                           // Metric value
+	Unit          string                 `protobuf:"bytes,5,opt,name=unit,proto3" json:"unit,omitempty"`                               // Unit of measurement (optional)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TelemetryRecord) Reset() {
+	*x = TelemetryRecord{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TelemetryRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TelemetryRecord) ProtoMessage() {}
+
+func (x *TelemetryRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TelemetryRecord.ProtoReflect.Descriptor instead.
+func (*TelemetryRecord) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *TelemetryRecord) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *TelemetryRecord) GetMetricName() string {
+	if x != nil {
+		return x.MetricName
+	}
+	return ""
+}
+
+func (x *TelemetryRecord) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *TelemetryRecord) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *TelemetryRecord) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+// A page of exported telemetry rows
+type ExportTelemetryChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Records       []*TelemetryRecord     `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	Cursor        string                 `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"` // Position of the last record, to resume an interrupted export
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportTelemetryChunk) Reset() {
+	*x = ExportTelemetryChunk{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportTelemetryChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportTelemetryChunk) ProtoMessage() {}
+
+func (x *ExportTelemetryChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportTelemetryChunk.ProtoReflect.Descriptor instead.
+func (*ExportTelemetryChunk) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ExportTelemetryChunk) GetRecords() []*TelemetryRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+func (x *ExportTelemetryChunk) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+// Request to stream live telemetry, optionally replaying history first
+type StreamTelemetryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceIds     []string               `protobuf:"bytes,1,rep,name=device_ids,json=deviceIds,proto3" json:"device_ids,omitempty"`       // Device UUIDs (empty = all devices)
+	MetricNames   []string               `protobuf:"bytes,2,rep,name=metric_names,json=metricNames,proto3" json:"metric_names,omitempty"` // Metric names (empty = all metrics)
+	FromTime      int64                  `protobuf:"varint,3,opt,name=from_time,json=fromTime,proto3" json:"from_time,omitempty"`         // Replay stored points from this time before going live (0 = live only)
+	OrgId         string                 `protobuf:"bytes,4,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"`                   // Organization of the caller (empty: all, internal calls)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamTelemetryRequest) Reset() {
+	*x = StreamTelemetryRequest{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamTelemetryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamTelemetryRequest) ProtoMessage() {}
+
+func (x *StreamTelemetryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamTelemetryRequest.ProtoReflect.Descriptor instead.
+func (*StreamTelemetryRequest) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *StreamTelemetryRequest) GetDeviceIds() []string {
+	if x != nil {
+		return x.DeviceIds
+	}
+	return nil
+}
+
+func (x *StreamTelemetryRequest) GetMetricNames() []string {
+	if x != nil {
+		return x.MetricNames
+	}
+	return nil
+}
+
+func (x *StreamTelemetryRequest) GetFromTime() int64 {
+	if x != nil {
+		return x.FromTime
+	}
+	return 0
+}
+
+func (x *StreamTelemetryRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+// A telemetry point delivered by StreamTelemetry
+type StreamTelemetryEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Record        *TelemetryRecord       `protobuf:"bytes,1,opt,name=record,proto3" json:"record,omitempty"`
+	Replay        bool                   `protobuf:"varint,2,opt,name=replay,proto3" json:"replay,omitempty"` // Read from history rather than received live
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamTelemetryEvent) Reset() {
+	*x = StreamTelemetryEvent{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamTelemetryEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamTelemetryEvent) ProtoMessage() {}
+
+func (x *StreamTelemetryEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamTelemetryEvent.ProtoReflect.Descriptor instead.
+func (*StreamTelemetryEvent) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *StreamTelemetryEvent) GetRecord() *TelemetryRecord {
+	if x != nil {
+		return x.Record
+	}
+	return nil
+}
+
+func (x *StreamTelemetryEvent) GetReplay() bool {
+	if x != nil {
+		return x.Replay
+	}
+	return false
+}
+
+// A chunk of historical telemetry to import.
+// Options are read from the first message of the stream only.
+type ImportTelemetryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Records       []*TelemetryRecord     `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	OnConflict    ImportConflictPolicy   `protobuf:"varint,2,opt,name=on_conflict,json=onConflict,proto3,enum=telemetry.ImportConflictPolicy" json:"on_conflict,omitempty"`
+	OrgId         string                 `protobuf:"bytes,3,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"` // Organization of the caller: every device must belong to it (empty: any)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportTelemetryRequest) Reset() {
+	*x = ImportTelemetryRequest{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportTelemetryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportTelemetryRequest) ProtoMessage() {}
+
+func (x *ImportTelemetryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportTelemetryRequest.ProtoReflect.Descriptor instead.
+func (*ImportTelemetryRequest) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ImportTelemetryRequest) GetRecords() []*TelemetryRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+func (x *ImportTelemetryRequest) GetOnConflict() ImportConflictPolicy {
+	if x != nil {
+		return x.OnConflict
+	}
+	return ImportConflictPolicy_IMPORT_CONFLICT_SKIP
+}
+
+func (x *ImportTelemetryRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+// Summary of a completed import
+type ImportTelemetryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RowsReceived  int64                  `protobuf:"varint,1,opt,name=rows_received,json=rowsReceived,proto3" json:"rows_received,omitempty"` // Rows sent by the client
+	RowsWritten   int64                  `protobuf:"varint,2,opt,name=rows_written,json=rowsWritten,proto3" json:"rows_written,omitempty"`    // Rows inserted or overwritten
+	RowsSkipped   int64                  `protobuf:"varint,3,opt,name=rows_skipped,json=rowsSkipped,proto3" json:"rows_skipped,omitempty"`    // Rows dropped as duplicates or existing data
+	FromTime      int64                  `protobuf:"varint,4,opt,name=from_time,json=fromTime,proto3" json:"from_time,omitempty"`             // Earliest imported timestamp (Unix)
+	ToTime        int64                  `protobuf:"varint,5,opt,name=to_time,json=toTime,proto3" json:"to_time,omitempty"`                   // Latest imported timestamp (Unix)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportTelemetryResponse) Reset() {
+	*x = ImportTelemetryResponse{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportTelemetryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportTelemetryResponse) ProtoMessage() {}
+
+func (x *ImportTelemetryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportTelemetryResponse.ProtoReflect.Descriptor instead.
+func (*ImportTelemetryResponse) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ImportTelemetryResponse) GetRowsReceived() int64 {
+	if x != nil {
+		return x.RowsReceived
+	}
+	return 0
+}
+
+func (x *ImportTelemetryResponse) GetRowsWritten() int64 {
+	if x != nil {
+		return x.RowsWritten
+	}
+	return 0
+}
+
+func (x *ImportTelemetryResponse) GetRowsSkipped() int64 {
+	if x != nil {
+		return x.RowsSkipped
+	}
+	return 0
+}
+
+func (x *ImportTelemetryResponse) GetFromTime() int64 {
+	if x != nil {
+		return x.FromTime
+	}
+	return 0
+}
+
+func (x *ImportTelemetryResponse) GetToTime() int64 {
+	if x != nil {
+		return x.ToTime
+	}
+	return 0
+}
+
+// Retention policy for a device type and/or metric (empty = any).
+// Durations are PostgreSQL intervals such as "7 days" or "2 years".
+type RetentionPolicy struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Id                  string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	DeviceType          string                 `protobuf:"bytes,2,opt,name=device_type,json=deviceType,proto3" json:"device_type,omitempty"`                            // Empty: all device types
+	MetricName          string                 `protobuf:"bytes,3,opt,name=metric_name,json=metricName,proto3" json:"metric_name,omitempty"`                            // Empty: all metrics
+	RawRetention        string                 `protobuf:"bytes,4,opt,name=raw_retention,json=rawRetention,proto3" json:"raw_retention,omitempty"`                      // How long raw points are kept
+	DownsampleInterval  string                 `protobuf:"bytes,5,opt,name=downsample_interval,json=downsampleInterval,proto3" json:"downsample_interval,omitempty"`    // Rollup bucket before raw deletion (empty: no rollup)
+	DownsampleRetention string                 `protobuf:"bytes,6,opt,name=downsample_retention,json=downsampleRetention,proto3" json:"downsample_retention,omitempty"` // How long rollups are kept (empty: forever)
+	CreatedAt           int64                  `protobuf:"varint,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`                              // Unix timestamp
+	UpdatedAt           int64                  `protobuf:"varint,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`                              // Unix timestamp
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *RetentionPolicy) Reset() {
+	*x = RetentionPolicy{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetentionPolicy) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetentionPolicy) ProtoMessage() {}
+
+func (x *RetentionPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetentionPolicy.ProtoReflect.Descriptor instead.
+func (*RetentionPolicy) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *RetentionPolicy) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RetentionPolicy) GetDeviceType() string {
+	if x != nil {
+		return x.DeviceType
+	}
+	return ""
+}
+
+func (x *RetentionPolicy) GetMetricName() string {
+	if x != nil {
+		return x.MetricName
+	}
+	return ""
+}
+
+func (x *RetentionPolicy) GetRawRetention() string {
+	if x != nil {
+		return x.RawRetention
+	}
+	return ""
+}
+
+func (x *RetentionPolicy) GetDownsampleInterval() string {
+	if x != nil {
+		return x.DownsampleInterval
+	}
+	return ""
+}
+
+func (x *RetentionPolicy) GetDownsampleRetention() string {
+	if x != nil {
+		return x.DownsampleRetention
+	}
+	return ""
+}
+
+func (x *RetentionPolicy) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *RetentionPolicy) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+// Request to list retention policies
+type ListRetentionPoliciesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRetentionPoliciesRequest) Reset() {
+	*x = ListRetentionPoliciesRequest{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRetentionPoliciesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRetentionPoliciesRequest) ProtoMessage() {}
+
+func (x *ListRetentionPoliciesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRetentionPoliciesRequest.ProtoReflect.Descriptor instead.
+func (*ListRetentionPoliciesRequest) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{23}
+}
+
+// Response with all retention policies
+type ListRetentionPoliciesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Policies      []*RetentionPolicy     `protobuf:"bytes,1,rep,name=policies,proto3" json:"policies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRetentionPoliciesResponse) Reset() {
+	*x = ListRetentionPoliciesResponse{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRetentionPoliciesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRetentionPoliciesResponse) ProtoMessage() {}
+
+func (x *ListRetentionPoliciesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRetentionPoliciesResponse.ProtoReflect.Descriptor instead.
+func (*ListRetentionPoliciesResponse) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ListRetentionPoliciesResponse) GetPolicies() []*RetentionPolicy {
+	if x != nil {
+		return x.Policies
+	}
+	return nil
+}
+
+// Request to create or replace the policy for a (device_type, metric_name) scope
+type UpsertRetentionPolicyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Policy        *RetentionPolicy       `protobuf:"bytes,1,opt,name=policy,proto3" json:"policy,omitempty"` // id, created_at and updated_at are ignored
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpsertRetentionPolicyRequest) Reset() {
+	*x = UpsertRetentionPolicyRequest{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpsertRetentionPolicyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpsertRetentionPolicyRequest) ProtoMessage() {}
+
+func (x *UpsertRetentionPolicyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpsertRetentionPolicyRequest.ProtoReflect.Descriptor instead.
+func (*UpsertRetentionPolicyRequest) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *UpsertRetentionPolicyRequest) GetPolicy() *RetentionPolicy {
+	if x != nil {
+		return x.Policy
+	}
+	return nil
+}
+
+// Request to delete a retention policy
+type DeleteRetentionPolicyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRetentionPolicyRequest) Reset() {
+	*x = DeleteRetentionPolicyRequest{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRetentionPolicyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRetentionPolicyRequest) ProtoMessage() {}
+
+func (x *DeleteRetentionPolicyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRetentionPolicyRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRetentionPolicyRequest) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *DeleteRetentionPolicyRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// Response for policy deletion
+type DeleteRetentionPolicyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRetentionPolicyResponse) Reset() {
+	*x = DeleteRetentionPolicyResponse{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRetentionPolicyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRetentionPolicyResponse) ProtoMessage() {}
+
+func (x *DeleteRetentionPolicyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRetentionPolicyResponse.ProtoReflect.Descriptor instead.
+func (*DeleteRetentionPolicyResponse) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *DeleteRetentionPolicyResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// Request to enforce retention policies now
+type ApplyRetentionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DryRun        bool                   `protobuf:"varint,1,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"` // Only count the rows that would be affected
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApplyRetentionRequest) Reset() {
+	*x = ApplyRetentionRequest{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplyRetentionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyRetentionRequest) ProtoMessage() {}
+
+func (x *ApplyRetentionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyRetentionRequest.ProtoReflect.Descriptor instead.
+func (*ApplyRetentionRequest) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ApplyRetentionRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+// Rows affected by one policy
+type RetentionPolicyResult struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Policy            *RetentionPolicy       `protobuf:"bytes,1,opt,name=policy,proto3" json:"policy,omitempty"`
+	RawRowsDeleted    int64                  `protobuf:"varint,2,opt,name=raw_rows_deleted,json=rawRowsDeleted,proto3" json:"raw_rows_deleted,omitempty"`          // Raw points removed (or to remove)
+	BucketsWritten    int64                  `protobuf:"varint,3,opt,name=buckets_written,json=bucketsWritten,proto3" json:"buckets_written,omitempty"`            // Rollup buckets written before deletion
+	RollupRowsDeleted int64                  `protobuf:"varint,4,opt,name=rollup_rows_deleted,json=rollupRowsDeleted,proto3" json:"rollup_rows_deleted,omitempty"` // Rollups removed (or to remove)
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *RetentionPolicyResult) Reset() {
+	*x = RetentionPolicyResult{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RetentionPolicyResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetentionPolicyResult) ProtoMessage() {}
+
+func (x *RetentionPolicyResult) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetentionPolicyResult.ProtoReflect.Descriptor instead.
+func (*RetentionPolicyResult) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *RetentionPolicyResult) GetPolicy() *RetentionPolicy {
+	if x != nil {
+		return x.Policy
+	}
+	return nil
+}
+
+func (x *RetentionPolicyResult) GetRawRowsDeleted() int64 {
+	if x != nil {
+		return x.RawRowsDeleted
+	}
+	return 0
+}
+
+func (x *RetentionPolicyResult) GetBucketsWritten() int64 {
+	if x != nil {
+		return x.BucketsWritten
+	}
+	return 0
+}
+
+func (x *RetentionPolicyResult) GetRollupRowsDeleted() int64 {
+	if x != nil {
+		return x.RollupRowsDeleted
+	}
+	return 0
+}
+
+// Response with per-policy results
+type ApplyRetentionResponse struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Results       []*RetentionPolicyResult `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	DryRun        bool                     `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApplyRetentionResponse) Reset() {
+	*x = ApplyRetentionResponse{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplyRetentionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyRetentionResponse) ProtoMessage() {}
+
+func (x *ApplyRetentionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyRetentionResponse.ProtoReflect.Descriptor instead.
+func (*ApplyRetentionResponse) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ApplyRetentionResponse) GetResults() []*RetentionPolicyResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *ApplyRetentionResponse) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+// Metric computed at ingest time for every device of a type (virtual sensor)
+type DerivedMetric struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	DeviceType    string                 `protobuf:"bytes,2,opt,name=device_type,json=deviceType,proto3" json:"device_type,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"` // Name of the stored metric
+	Kind          DerivedMetricKind      `protobuf:"varint,4,opt,name=kind,proto3,enum=telemetry.DerivedMetricKind" json:"kind,omitempty"`
+	Expression    string                 `protobuf:"bytes,5,opt,name=expression,proto3" json:"expression,omitempty"`                         // EXPRESSION: e.g. "temperature - (100 - humidity) / 5"
+	SourceMetric  string                 `protobuf:"bytes,6,opt,name=source_metric,json=sourceMetric,proto3" json:"source_metric,omitempty"` // INTEGRAL, MOVING_AVERAGE: input metric
+	Window        string                 `protobuf:"bytes,7,opt,name=window,proto3" json:"window,omitempty"`                                 // MOVING_AVERAGE: Go duration such as "15m"
+	Unit          string                 `protobuf:"bytes,8,opt,name=unit,proto3" json:"unit,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`  // Unix timestamp
+	UpdatedAt     int64                  `protobuf:"varint,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"` // Unix timestamp
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DerivedMetric) Reset() {
+	*x = DerivedMetric{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DerivedMetric) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DerivedMetric) ProtoMessage() {}
+
+func (x *DerivedMetric) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DerivedMetric.ProtoReflect.Descriptor instead.
+func (*DerivedMetric) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *DerivedMetric) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DerivedMetric) GetDeviceType() string {
+	if x != nil {
+		return x.DeviceType
+	}
+	return ""
+}
+
+func (x *DerivedMetric) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DerivedMetric) GetKind() DerivedMetricKind {
+	if x != nil {
+		return x.Kind
+	}
+	return DerivedMetricKind_DERIVED_METRIC_EXPRESSION
+}
+
+func (x *DerivedMetric) GetExpression() string {
+	if x != nil {
+		return x.Expression
+	}
+	return ""
+}
+
+func (x *DerivedMetric) GetSourceMetric() string {
+	if x != nil {
+		return x.SourceMetric
+	}
+	return ""
+}
+
+func (x *DerivedMetric) GetWindow() string {
+	if x != nil {
+		return x.Window
+	}
+	return ""
+}
+
+func (x *DerivedMetric) GetUnit() string {
+	if x != nil {
+		return x.Unit
+	}
+	return ""
+}
+
+func (x *DerivedMetric) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *DerivedMetric) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+// Request to list derived metrics
+type ListDerivedMetricsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceType    string                 `protobuf:"bytes,1,opt,name=device_type,json=deviceType,proto3" json:"device_type,omitempty"` // Optional filter
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDerivedMetricsRequest) Reset() {
+	*x = ListDerivedMetricsRequest{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDerivedMetricsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDerivedMetricsRequest) ProtoMessage() {}
+
+func (x *ListDerivedMetricsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDerivedMetricsRequest.ProtoReflect.Descriptor instead.
+func (*ListDerivedMetricsRequest) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ListDerivedMetricsRequest) GetDeviceType() string {
+	if x != nil {
+		return x.DeviceType
+	}
+	return ""
+}
+
+// Response with derived metric definitions
+type ListDerivedMetricsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Metrics       []*DerivedMetric       `protobuf:"bytes,1,rep,name=metrics,proto3" json:"metrics,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDerivedMetricsResponse) Reset() {
+	*x = ListDerivedMetricsResponse{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDerivedMetricsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDerivedMetricsResponse) ProtoMessage() {}
+
+func (x *ListDerivedMetricsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDerivedMetricsResponse.ProtoReflect.Descriptor instead.
+func (*ListDerivedMetricsResponse) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ListDerivedMetricsResponse) GetMetrics() []*DerivedMetric {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+// Request to create or replace the definition of (device_type, name)
+type UpsertDerivedMetricRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Metric        *DerivedMetric         `protobuf:"bytes,1,opt,name=metric,proto3" json:"metric,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpsertDerivedMetricRequest) Reset() {
+	*x = UpsertDerivedMetricRequest{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpsertDerivedMetricRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpsertDerivedMetricRequest) ProtoMessage() {}
+
+func (x *UpsertDerivedMetricRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpsertDerivedMetricRequest.ProtoReflect.Descriptor instead.
+func (*UpsertDerivedMetricRequest) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *UpsertDerivedMetricRequest) GetMetric() *DerivedMetric {
+	if x != nil {
+		return x.Metric
+	}
+	return nil
+}
+
+// Request to delete a derived metric definition
+type DeleteDerivedMetricRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDerivedMetricRequest) Reset() {
+	*x = DeleteDerivedMetricRequest{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDerivedMetricRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDerivedMetricRequest) ProtoMessage() {}
+
+func (x *DeleteDerivedMetricRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDerivedMetricRequest.ProtoReflect.Descriptor instead.
+func (*DeleteDerivedMetricRequest) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *DeleteDerivedMetricRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// Response for derived metric deletion
+type DeleteDerivedMetricResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDerivedMetricResponse) Reset() {
+	*x = DeleteDerivedMetricResponse{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDerivedMetricResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDerivedMetricResponse) ProtoMessage() {}
+
+func (x *DeleteDerivedMetricResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDerivedMetricResponse.ProtoReflect.Descriptor instead.
+func (*DeleteDerivedMetricResponse) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *DeleteDerivedMetricResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// Telemetry point scored as anomalous at ingest time
+type Anomaly struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	MetricName    string                 `protobuf:"bytes,2,opt,name=metric_name,json=metricName,proto3" json:"metric_name,omitempty"`
+	Time          int64                  `protobuf:"varint,3,opt,name=time,proto3" json:"time,omitempty"` // Unix timestamp of the point
+	Value         float64                `protobuf:"fixed64,4,opt,name=value,proto3" json:"value,omitempty"`
+	Expected      float64                `protobuf:"fixed64,5,opt,name=expected,proto3" json:"expected,omitempty"` // Value predicted by the detector
+	Score         float64                `protobuf:"fixed64,6,opt,name=score,proto3" json:"score,omitempty"`       // Signed z-score: (value - expected) / stddev
+	Method        string                 `protobuf:"bytes,7,opt,name=method,proto3" json:"method,omitempty"`       // "ewma" or "seasonal"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Anomaly) Reset() {
+	*x = Anomaly{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Anomaly) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Anomaly) ProtoMessage() {}
+
+func (x *Anomaly) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Anomaly.ProtoReflect.Descriptor instead.
+func (*Anomaly) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *Anomaly) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *Anomaly) GetMetricName() string {
+	if x != nil {
+		return x.MetricName
+	}
+	return ""
+}
+
+func (x *Anomaly) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *Anomaly) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *Anomaly) GetExpected() float64 {
+	if x != nil {
+		return x.Expected
+	}
+	return 0
+}
+
+func (x *Anomaly) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *Anomaly) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+// Request for detected anomalies
+type GetAnomaliesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`       // Optional: all devices when empty
+	MetricName    string                 `protobuf:"bytes,2,opt,name=metric_name,json=metricName,proto3" json:"metric_name,omitempty"` // Optional: all metrics when empty
+	FromTime      int64                  `protobuf:"varint,3,opt,name=from_time,json=fromTime,proto3" json:"from_time,omitempty"`      // Unix timestamp
+	ToTime        int64                  `protobuf:"varint,4,opt,name=to_time,json=toTime,proto3" json:"to_time,omitempty"`            // Unix timestamp
+	MinScore      float64                `protobuf:"fixed64,5,opt,name=min_score,json=minScore,proto3" json:"min_score,omitempty"`     // Optional: minimum |score|
+	Limit         int32                  `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	OrgId         string                 `protobuf:"bytes,7,opt,name=org_id,json=orgId,proto3" json:"org_id,omitempty"` // Organization of the caller (empty: all, internal calls)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAnomaliesRequest) Reset() {
+	*x = GetAnomaliesRequest{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAnomaliesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAnomaliesRequest) ProtoMessage() {}
+
+func (x *GetAnomaliesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAnomaliesRequest.ProtoReflect.Descriptor instead.
+func (*GetAnomaliesRequest) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *GetAnomaliesRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *GetAnomaliesRequest) GetMetricName() string {
+	if x != nil {
+		return x.MetricName
+	}
+	return ""
+}
+
+func (x *GetAnomaliesRequest) GetFromTime() int64 {
+	if x != nil {
+		return x.FromTime
+	}
+	return 0
+}
+
+func (x *GetAnomaliesRequest) GetToTime() int64 {
+	if x != nil {
+		return x.ToTime
+	}
+	return 0
+}
+
+func (x *GetAnomaliesRequest) GetMinScore() float64 {
+	if x != nil {
+		return x.MinScore
+	}
+	return 0
+}
+
+func (x *GetAnomaliesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetAnomaliesRequest) GetOrgId() string {
+	if x != nil {
+		return x.OrgId
+	}
+	return ""
+}
+
+// Anomalies, most recent first
+type GetAnomaliesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Anomalies     []*Anomaly             `protobuf:"bytes,1,rep,name=anomalies,proto3" json:"anomalies,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAnomaliesResponse) Reset() {
+	*x = GetAnomaliesResponse{}
+	mi := &file_telemetry_telemetry_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAnomaliesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAnomaliesResponse) ProtoMessage() {}
+
+func (x *GetAnomaliesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_telemetry_telemetry_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAnomaliesResponse.ProtoReflect.Descriptor instead.
+func (*GetAnomaliesResponse) Descriptor() ([]byte, []int) {
+	return file_telemetry_telemetry_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *GetAnomaliesResponse) GetAnomalies() []*Anomaly {
+	if x != nil {
+		return x.Anomalies
+	}
+	return nil
+}
+
+var File_telemetry_telemetry_proto protoreflect.FileDescriptor
+
+const file_telemetry_telemetry_proto_rawDesc = "" +
+	"\n" +
+	"\x19telemetry/telemetry.proto\x12\ttelemetry\"N\n" +
+	"\x0eTelemetryPoint\x12\x12\n" +
+	"\x04time\x18\x01 \x01(\x03R\x04time\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value\x12\x12\n" +
+	"\x04unit\x18\x03 \x01(\tR\x04unit\"z\n" +
+	"\x14TelemetryAggregation\x12\x16\n" +
+	"\x06bucket\x18\x01 \x01(\tR\x06bucket\x12\x10\n" +
+	"\x03avg\x18\x02 \x01(\x01R\x03avg\x12\x10\n" +
+	"\x03min\x18\x03 \x01(\x01R\x03min\x12\x10\n" +
+	"\x03max\x18\x04 \x01(\x01R\x03max\x12\x14\n" +
+	"\x05count\x18\x05 \x01(\x03R\x05count\"\xca\x01\n" +
+	"\x13GetTelemetryRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x1f\n" +
+	"\vmetric_name\x18\x02 \x01(\tR\n" +
+	"metricName\x12\x1b\n" +
+	"\tfrom_time\x18\x03 \x01(\x03R\bfromTime\x12\x17\n" +
+	"\ato_time\x18\x04 \x01(\x03R\x06toTime\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\x12\x12\n" +
+	"\x04unit\x18\x06 \x01(\tR\x04unit\x12\x15\n" +
+	"\x06org_id\x18\a \x01(\tR\x05orgId\"I\n" +
+	"\x14GetTelemetryResponse\x121\n" +
+	"\x06points\x18\x01 \x03(\v2\x19.telemetry.TelemetryPointR\x06points\"\xda\x01\n" +
+	"\x1dGetTelemetryAggregatedRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x1f\n" +
+	"\vmetric_name\x18\x02 \x01(\tR\n" +
+	"metricName\x12\x1b\n" +
+	"\tfrom_time\x18\x03 \x01(\x03R\bfromTime\x12\x17\n" +
+	"\ato_time\x18\x04 \x01(\x03R\x06toTime\x12\x1a\n" +
+	"\binterval\x18\x05 \x01(\tR\binterval\x12\x12\n" +
+	"\x04unit\x18\x06 \x01(\tR\x04unit\x12\x15\n" +
+	"\x06org_id\x18\a \x01(\tR\x05orgId\"e\n" +
+	"\x1eGetTelemetryAggregatedResponse\x12C\n" +
+	"\faggregations\x18\x01 \x03(\v2\x1f.telemetry.TelemetryAggregationR\faggregations\"\x81\x01\n" +
+	"\x16GetLatestMetricRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x1f\n" +
+	"\vmetric_name\x18\x02 \x01(\tR\n" +
+	"metricName\x12\x12\n" +
+	"\x04unit\x18\x03 \x01(\tR\x04unit\x12\x15\n" +
+	"\x06org_id\x18\x04 \x01(\tR\x05orgId\"J\n" +
+	"\x17GetLatestMetricResponse\x12/\n" +
+	"\x05point\x18\x01 \x01(\v2\x19.telemetry.TelemetryPointR\x05point\"M\n" +
+	"\x17GetDeviceMetricsRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x15\n" +
+	"\x06org_id\x18\x02 \x01(\tR\x05orgId\"\xcb\x02\n" +
+	"\n" +
+	"MetricInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12!\n" +
+	"\fdisplay_name\x18\x02 \x01(\tR\vdisplayName\x12\x12\n" +
+	"\x04unit\x18\x03 \x01(\tR\x04unit\x12\x1b\n" +
+	"\tdata_type\x18\x04 \x01(\tR\bdataType\x12 \n" +
+	"\tmin_value\x18\x05 \x01(\x01H\x00R\bminValue\x88\x01\x01\x12 \n" +
+	"\tmax_value\x18\x06 \x01(\x01H\x01R\bmaxValue\x88\x01\x01\x12<\n" +
+	"\x1areporting_interval_seconds\x18\a \x01(\x05R\x18reportingIntervalSeconds\x12\x1a\n" +
+	"\bdeclared\x18\b \x01(\bR\bdeclared\x12\x1b\n" +
+	"\tlast_time\x18\t \x01(\x03R\blastTimeB\f\n" +
+	"\n" +
+	"_min_valueB\f\n" +
+	"\n" +
+	"_max_value\"e\n" +
+	"\x18GetDeviceMetricsResponse\x12\x18\n" +
+	"\ametrics\x18\x01 \x03(\tR\ametrics\x12/\n" +
+	"\acatalog\x18\x02 \x03(\v2\x15.telemetry.MetricInfoR\acatalog\"\xe0\x01\n" +
+	"\fDeviceFilter\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12A\n" +
+	"\bmetadata\x18\x02 \x03(\v2%.telemetry.DeviceFilter.MetadataEntryR\bmetadata\x12\x1b\n" +
+	"\tgroup_ids\x18\x03 \x03(\tR\bgroupIds\x12\x1f\n" +
+	"\vlocation_id\x18\x04 \x01(\tR\n" +
+	"locationId\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xe7\x02\n" +
+	"\x18GetTelemetryBatchRequest\x12\x1d\n" +
+	"\n" +
+	"device_ids\x18\x01 \x03(\tR\tdeviceIds\x12/\n" +
+	"\x06filter\x18\x02 \x01(\v2\x17.telemetry.DeviceFilterR\x06filter\x12!\n" +
+	"\fmetric_names\x18\x03 \x03(\tR\vmetricNames\x12\x1b\n" +
+	"\tfrom_time\x18\x04 \x01(\x03R\bfromTime\x12\x17\n" +
+	"\ato_time\x18\x05 \x01(\x03R\x06toTime\x12\x1a\n" +
+	"\binterval\x18\x06 \x01(\tR\binterval\x126\n" +
+	"\bgroup_by\x18\a \x01(\x0e2\x1b.telemetry.TelemetryGroupByR\agroupBy\x12\x12\n" +
+	"\x04unit\x18\b \x01(\tR\x04unit\x12\x15\n" +
+	"\x06org_id\x18\t \x01(\tR\x05orgId\x12#\n" +
+	"\rlocation_kind\x18\n" +
+	" \x01(\tR\flocationKind\"\xf6\x01\n" +
+	"\x14TelemetryBatchSeries\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x1f\n" +
+	"\vdevice_type\x18\x02 \x01(\tR\n" +
+	"deviceType\x12\x1f\n" +
+	"\vmetric_name\x18\x03 \x01(\tR\n" +
+	"metricName\x12C\n" +
+	"\faggregations\x18\x04 \x03(\v2\x1f.telemetry.TelemetryAggregationR\faggregations\x12\x19\n" +
+	"\bgroup_id\x18\x05 \x01(\tR\agroupId\x12\x1f\n" +
+	"\vlocation_id\x18\x06 \x01(\tR\n" +
+	"locationId\"T\n" +
+	"\x19GetTelemetryBatchResponse\x127\n" +
+	"\x06series\x18\x01 \x03(\v2\x1f.telemetry.TelemetryBatchSeriesR\x06series\"\xdc\x01\n" +
+	"\x16ExportTelemetryRequest\x12\x1d\n" +
+	"\n" +
+	"device_ids\x18\x01 \x03(\tR\tdeviceIds\x12!\n" +
+	"\fmetric_names\x18\x02 \x03(\tR\vmetricNames\x12\x1b\n" +
+	"\tfrom_time\x18\x03 \x01(\x03R\bfromTime\x12\x17\n" +
+	"\ato_time\x18\x04 \x01(\x03R\x06toTime\x12\x16\n" +
+	"\x06cursor\x18\x05 \x01(\tR\x06cursor\x12\x1b\n" +
+	"\tpage_size\x18\x06 \x01(\x05R\bpageSize\x12\x15\n" +
+	"\x06org_id\x18\a \x01(\tR\x05orgId\"\x8d\x01\n" +
+	"\x0fTelemetryRecord\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x1f\n" +
+	"\vmetric_name\x18\x02 \x01(\tR\n" +
+	"metricName\x12\x12\n" +
+	"\x04time\x18\x03 \x01(\x03R\x04time\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\x01R\x05value\x12\x12\n" +
+	"\x04unit\x18\x05 \x01(\tR\x04unit\"d\n" +
+	"\x14ExportTelemetryChunk\x124\n" +
+	"\arecords\x18\x01 \x03(\v2\x1a.telemetry.TelemetryRecordR\arecords\x12\x16\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\"\x8e\x01\n" +
+	"\x16StreamTelemetryRequest\x12\x1d\n" +
+	"\n" +
+	"device_ids\x18\x01 \x03(\tR\tdeviceIds\x12!\n" +
+	"\fmetric_names\x18\x02 \x03(\tR\vmetricNames\x12\x1b\n" +
+	"\tfrom_time\x18\x03 \x01(\x03R\bfromTime\x12\x15\n" +
+	"\x06org_id\x18\x04 \x01(\tR\x05orgId\"b\n" +
+	"\x14StreamTelemetryEvent\x122\n" +
+	"\x06record\x18\x01 \x01(\v2\x1a.telemetry.TelemetryRecordR\x06record\x12\x16\n" +
+	"\x06replay\x18\x02 \x01(\bR\x06replay\"\xa7\x01\n" +
+	"\x16ImportTelemetryRequest\x124\n" +
+	"\arecords\x18\x01 \x03(\v2\x1a.telemetry.TelemetryRecordR\arecords\x12@\n" +
+	"\von_conflict\x18\x02 \x01(\x0e2\x1f.telemetry.ImportConflictPolicyR\n" +
+	"onConflict\x12\x15\n" +
+	"\x06org_id\x18\x03 \x01(\tR\x05orgId\"\xba\x01\n" +
+	"\x17ImportTelemetryResponse\x12#\n" +
+	"\rrows_received\x18\x01 \x01(\x03R\frowsReceived\x12!\n" +
+	"\frows_written\x18\x02 \x01(\x03R\vrowsWritten\x12!\n" +
+	"\frows_skipped\x18\x03 \x01(\x03R\vrowsSkipped\x12\x1b\n" +
+	"\tfrom_time\x18\x04 \x01(\x03R\bfromTime\x12\x17\n" +
+	"\ato_time\x18\x05 \x01(\x03R\x06toTime\"\xaa\x02\n" +
+	"\x0fRetentionPolicy\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
+	"\vdevice_type\x18\x02 \x01(\tR\n" +
+	"deviceType\x12\x1f\n" +
+	"\vmetric_name\x18\x03 \x01(\tR\n" +
+	"metricName\x12#\n" +
+	"\rraw_retention\x18\x04 \x01(\tR\frawRetention\x12/\n" +
+	"\x13downsample_interval\x18\x05 \x01(\tR\x12downsampleInterval\x121\n" +
+	"\x14downsample_retention\x18\x06 \x01(\tR\x13downsampleRetention\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\a \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\b \x01(\x03R\tupdatedAt\"\x1e\n" +
+	"\x1cListRetentionPoliciesRequest\"W\n" +
+	"\x1dListRetentionPoliciesResponse\x126\n" +
+	"\bpolicies\x18\x01 \x03(\v2\x1a.telemetry.RetentionPolicyR\bpolicies\"R\n" +
+	"\x1cUpsertRetentionPolicyRequest\x122\n" +
+	"\x06policy\x18\x01 \x01(\v2\x1a.telemetry.RetentionPolicyR\x06policy\".\n" +
+	"\x1cDeleteRetentionPolicyRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"9\n" +
+	"\x1dDeleteRetentionPolicyResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"0\n" +
+	"\x15ApplyRetentionRequest\x12\x17\n" +
+	"\adry_run\x18\x01 \x01(\bR\x06dryRun\"\xce\x01\n" +
+	"\x15RetentionPolicyResult\x122\n" +
+	"\x06policy\x18\x01 \x01(\v2\x1a.telemetry.RetentionPolicyR\x06policy\x12(\n" +
+	"\x10raw_rows_deleted\x18\x02 \x01(\x03R\x0erawRowsDeleted\x12'\n" +
+	"\x0fbuckets_written\x18\x03 \x01(\x03R\x0ebucketsWritten\x12.\n" +
+	"\x13rollup_rows_deleted\x18\x04 \x01(\x03R\x11rollupRowsDeleted\"m\n" +
+	"\x16ApplyRetentionResponse\x12:\n" +
+	"\aresults\x18\x01 \x03(\v2 .telemetry.RetentionPolicyResultR\aresults\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\"\xb5\x02\n" +
+	"\rDerivedMetric\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
+	"\vdevice_type\x18\x02 \x01(\tR\n" +
+	"deviceType\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x120\n" +
+	"\x04kind\x18\x04 \x01(\x0e2\x1c.telemetry.DerivedMetricKindR\x04kind\x12\x1e\n" +
+	"\n" +
+	"expression\x18\x05 \x01(\tR\n" +
+	"expression\x12#\n" +
+	"\rsource_metric\x18\x06 \x01(\tR\fsourceMetric\x12\x16\n" +
+	"\x06window\x18\a \x01(\tR\x06window\x12\x12\n" +
+	"\x04unit\x18\b \x01(\tR\x04unit\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\t \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\x03R\tupdatedAt\"<\n" +
+	"\x19ListDerivedMetricsRequest\x12\x1f\n" +
+	"\vdevice_type\x18\x01 \x01(\tR\n" +
+	"deviceType\"P\n" +
+	"\x1aListDerivedMetricsResponse\x122\n" +
+	"\ametrics\x18\x01 \x03(\v2\x18.telemetry.DerivedMetricR\ametrics\"N\n" +
+	"\x1aUpsertDerivedMetricRequest\x120\n" +
+	"\x06metric\x18\x01 \x01(\v2\x18.telemetry.DerivedMetricR\x06metric\",\n" +
+	"\x1aDeleteDerivedMetricRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"7\n" +
+	"\x1bDeleteDerivedMetricResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\xbb\x01\n" +
+	"\aAnomaly\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x1f\n" +
+	"\vmetric_name\x18\x02 \x01(\tR\n" +
+	"metricName\x12\x12\n" +
+	"\x04time\x18\x03 \x01(\x03R\x04time\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\x01R\x05value\x12\x1a\n" +
+	"\bexpected\x18\x05 \x01(\x01R\bexpected\x12\x14\n" +
+	"\x05score\x18\x06 \x01(\x01R\x05score\x12\x16\n" +
+	"\x06method\x18\a \x01(\tR\x06method\"\xd3\x01\n" +
+	"\x13GetAnomaliesRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x1f\n" +
+	"\vmetric_name\x18\x02 \x01(\tR\n" +
+	"metricName\x12\x1b\n" +
+	"\tfrom_time\x18\x03 \x01(\x03R\bfromTime\x12\x17\n" +
+	"\ato_time\x18\x04 \x01(\x03R\x06toTime\x12\x1b\n" +
+	"\tmin_score\x18\x05 \x01(\x01R\bminScore\x12\x14\n" +
+	"\x05limit\x18\x06 \x01(\x05R\x05limit\x12\x15\n" +
+	"\x06org_id\x18\a \x01(\tR\x05orgId\"H\n" +
+	"\x14GetAnomaliesResponse\x120\n" +
+	"\tanomalies\x18\x01 \x03(\v2\x12.telemetry.AnomalyR\tanomalies*~\n" +
+	"\x10TelemetryGroupBy\x12\x13\n" +
+	"\x0fGROUP_BY_DEVICE\x10\x00\x12\x18\n" +
+	"\x14GROUP_BY_DEVICE_TYPE\x10\x01\x12\x10\n" +
+	"\fGROUP_BY_ALL\x10\x02\x12\x12\n" +
+	"\x0eGROUP_BY_GROUP\x10\x03\x12\x15\n" +
+	"\x11GROUP_BY_LOCATION\x10\x04*i\n" +
+	"\x14ImportConflictPolicy\x12\x18\n" +
+	"\x14IMPORT_CONFLICT_SKIP\x10\x00\x12\x1d\n" +
+	"\x19IMPORT_CONFLICT_OVERWRITE\x10\x01\x12\x18\n" +
+	"\x14IMPORT_CONFLICT_FAIL\x10\x02*r\n" +
+	"\x11DerivedMetricKind\x12\x1d\n" +
+	"\x19DERIVED_METRIC_EXPRESSION\x10\x00\x12\x1b\n" +
+	"\x17DERIVED_METRIC_INTEGRAL\x10\x01\x12!\n" +
+	"\x1dDERIVED_METRIC_MOVING_AVERAGE\x10\x022\xf6\v\n" +
 	"\x10TelemetryService\x12O\n" +
 	"\fGetTelemetry\x12\x1e.telemetry.GetTelemetryRequest\x1a\x1f.telemetry.GetTelemetryResponse\x12m\n" +
 	"\x16GetTelemetryAggregated\x12(.telemetry.GetTelemetryAggregatedRequest\x1a).telemetry.GetTelemetryAggregatedResponse\x12X\n" +
 	"\x0fGetLatestMetric\x12!.telemetry.GetLatestMetricRequest\x1a\".telemetry.GetLatestMetricResponse\x12[\n" +
-	"\x10GetDeviceMetrics\x12\".telemetry.GetDeviceMetricsRequest\x1a#.telemetry.GetDeviceMetricsResponseB=Z;github.com/yourusername/iot-platform/shared/proto/telemetryb\x06proto3"
+	"\x10GetDeviceMetrics\x12\".telemetry.GetDeviceMetricsRequest\x1a#.telemetry.GetDeviceMetricsResponse\x12^\n" +
+	"\x11GetTelemetryBatch\x12#.telemetry.GetTelemetryBatchRequest\x1a$.telemetry.GetTelemetryBatchResponse\x12W\n" +
+	"\x0fExportTelemetry\x12!.telemetry.ExportTelemetryRequest\x1a\x1f.telemetry.ExportTelemetryChunk0\x01\x12Z\n" +
+	"\x0fImportTelemetry\x12!.telemetry.ImportTelemetryRequest\x1a\".telemetry.ImportTelemetryResponse(\x01\x12W\n" +
+	"\x0fStreamTelemetry\x12!.telemetry.StreamTelemetryRequest\x1a\x1f.telemetry.StreamTelemetryEvent0\x01\x12j\n" +
+	"\x15ListRetentionPolicies\x12'.telemetry.ListRetentionPoliciesRequest\x1a(.telemetry.ListRetentionPoliciesResponse\x12\\\n" +
+	"\x15UpsertRetentionPolicy\x12'.telemetry.UpsertRetentionPolicyRequest\x1a\x1a.telemetry.RetentionPolicy\x12j\n" +
+	"\x15DeleteRetentionPolicy\x12'.telemetry.DeleteRetentionPolicyRequest\x1a(.telemetry.DeleteRetentionPolicyResponse\x12U\n" +
+	"\x0eApplyRetention\x12 .telemetry.ApplyRetentionRequest\x1a!.telemetry.ApplyRetentionResponse\x12a\n" +
+	"\x12ListDerivedMetrics\x12$.telemetry.ListDerivedMetricsRequest\x1a%.telemetry.ListDerivedMetricsResponse\x12V\n" +
+	"\x13UpsertDerivedMetric\x12%.telemetry.UpsertDerivedMetricRequest\x1a\x18.telemetry.DerivedMetric\x12d\n" +
+	"\x13DeleteDerivedMetric\x12%.telemetry.DeleteDerivedMetricRequest\x1a&.telemetry.DeleteDerivedMetricResponse\x12O\n" +
+	"\fGetAnomalies\x12\x1e.telemetry.GetAnomaliesRequest\x1a\x1f.telemetry.GetAnomaliesResponseB=Z;github.com/yourusername/iot-platform/shared/proto/telemetryb\x06proto3"
 
 var (
 	file_telemetry_telemetry_proto_rawDescOnce sync.Once
@@ -656,36 +3046,113 @@ func file_telemetry_telemetry_proto_rawDescGZIP() []byte {
 	return file_telemetry_telemetry_proto_rawDescData
 }
 
-var file_telemetry_telemetry_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_telemetry_telemetry_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_telemetry_telemetry_proto_msgTypes = make([]protoimpl.MessageInfo, 41)
 var file_telemetry_telemetry_proto_goTypes = []any{
-	(*TelemetryPoint)(nil),                 // 0: telemetry.TelemetryPoint
-	(*TelemetryAggregation)(nil),           // 1: telemetry.TelemetryAggregation
-	(*GetTelemetryRequest)(nil),            // 2: telemetry.GetTelemetryRequest
-	(*GetTelemetryResponse)(nil),           // 3: telemetry.GetTelemetryResponse
-	(*GetTelemetryAggregatedRequest)(nil),  // 4: telemetry.GetTelemetryAggregatedRequest
-	(*GetTelemetryAggregatedResponse)(nil), // 5: telemetry.GetTelemetryAggregatedResponse
-	(*GetLatestMetricRequest)(nil),         // 6: telemetry.GetLatestMetricRequest
-	(*GetLatestMetricResponse)(nil),        // 7: telemetry.GetLatestMetricResponse
-	(*GetDeviceMetricsRequest)(nil),        // 8: telemetry.GetDeviceMetricsRequest
-	(*GetDeviceMetricsResponse)(nil),       // 9: telemetry.GetDeviceMetricsResponse
+	(TelemetryGroupBy)(0),                  // 0: telemetry.TelemetryGroupBy
+	(ImportConflictPolicy)(0),              // 1: telemetry.ImportConflictPolicy
+	(DerivedMetricKind)(0),                 // 2: telemetry.DerivedMetricKind
+	(*TelemetryPoint)(nil),                 // 3: telemetry.TelemetryPoint
+	(*TelemetryAggregation)(nil),           // 4: telemetry.TelemetryAggregation
+	(*GetTelemetryRequest)(nil),            // 5: telemetry.GetTelemetryRequest
+	(*GetTelemetryResponse)(nil),           // 6: telemetry.GetTelemetryResponse
+	(*GetTelemetryAggregatedRequest)(nil),  // 7: telemetry.GetTelemetryAggregatedRequest
+	(*GetTelemetryAggregatedResponse)(nil), // 8: telemetry.GetTelemetryAggregatedResponse
+	(*GetLatestMetricRequest)(nil),         // 9: telemetry.GetLatestMetricRequest
+	(*GetLatestMetricResponse)(nil),        // 10: telemetry.GetLatestMetricResponse
+	(*GetDeviceMetricsRequest)(nil),        // 11: telemetry.GetDeviceMetricsRequest
+	(*MetricInfo)(nil),                     // 12: telemetry.MetricInfo
+	(*GetDeviceMetricsResponse)(nil),       // 13: telemetry.GetDeviceMetricsResponse
+	(*DeviceFilter)(nil),                   // 14: telemetry.DeviceFilter
+	(*GetTelemetryBatchRequest)(nil),       // 15: telemetry.GetTelemetryBatchRequest
+	(*TelemetryBatchSeries)(nil),           // 16: telemetry.TelemetryBatchSeries
+	(*GetTelemetryBatchResponse)(nil),      // 17: telemetry.GetTelemetryBatchResponse
+	(*ExportTelemetryRequest)(nil),         // 18: telemetry.ExportTelemetryRequest
+	(*TelemetryRecord)(nil),                // 19: telemetry.TelemetryRecord
+	(*ExportTelemetryChunk)(nil),           // 20: telemetry.ExportTelemetryChunk
+	(*StreamTelemetryRequest)(nil),         // 21: telemetry.StreamTelemetryRequest
+	(*StreamTelemetryEvent)(nil),           // 22: telemetry.StreamTelemetryEvent
+	(*ImportTelemetryRequest)(nil),         // 23: telemetry.ImportTelemetryRequest
+	(*ImportTelemetryResponse)(nil),        // 24: telemetry.ImportTelemetryResponse
+	(*RetentionPolicy)(nil),                // 25: telemetry.RetentionPolicy
+	(*ListRetentionPoliciesRequest)(nil),   // 26: telemetry.ListRetentionPoliciesRequest
+	(*ListRetentionPoliciesResponse)(nil),  // 27: telemetry.ListRetentionPoliciesResponse
+	(*UpsertRetentionPolicyRequest)(nil),   // 28: telemetry.UpsertRetentionPolicyRequest
+	(*DeleteRetentionPolicyRequest)(nil),   // 29: telemetry.DeleteRetentionPolicyRequest
+	(*DeleteRetentionPolicyResponse)(nil),  // 30: telemetry.DeleteRetentionPolicyResponse
+	(*ApplyRetentionRequest)(nil),          // 31: telemetry.ApplyRetentionRequest
+	(*RetentionPolicyResult)(nil),          // 32: telemetry.RetentionPolicyResult
+	(*ApplyRetentionResponse)(nil),         // 33: telemetry.ApplyRetentionResponse
+	(*DerivedMetric)(nil),                  // 34: telemetry.DerivedMetric
+	(*ListDerivedMetricsRequest)(nil),      // 35: telemetry.ListDerivedMetricsRequest
+	(*ListDerivedMetricsResponse)(nil),     // 36: telemetry.ListDerivedMetricsResponse
+	(*UpsertDerivedMetricRequest)(nil),     // 37: telemetry.UpsertDerivedMetricRequest
+	(*DeleteDerivedMetricRequest)(nil),     // 38: telemetry.DeleteDerivedMetricRequest
+	(*DeleteDerivedMetricResponse)(nil),    // 39: telemetry.DeleteDerivedMetricResponse
+	(*Anomaly)(nil),                        // 40: telemetry.Anomaly
+	(*GetAnomaliesRequest)(nil),            // 41: telemetry.GetAnomaliesRequest
+	(*GetAnomaliesResponse)(nil),           // 42: telemetry.GetAnomaliesResponse
+	nil,                                    // 43: telemetry.DeviceFilter.MetadataEntry
 }
 var file_telemetry_telemetry_proto_depIdxs = []int32{
-	0, // 0: telemetry.GetTelemetryResponse.points:type_name -> telemetry.TelemetryPoint
-	1, // 1: telemetry.GetTelemetryAggregatedResponse.aggregations:type_name -> telemetry.TelemetryAggregation
-	0, // 2: telemetry.GetLatestMetricResponse.point:type_name -> telemetry.TelemetryPoint
-	2, // 3: telemetry.TelemetryService.GetTelemetry:input_type -> telemetry.GetTelemetryRequest
-	4, // 4: telemetry.TelemetryService.GetTelemetryAggregated:input_type -> telemetry.GetTelemetryAggregatedRequest
-	6, // 5: telemetry.TelemetryService.GetLatestMetric:input_type -> telemetry.GetLatestMetricRequest
-	8, // 6: telemetry.TelemetryService.GetDeviceMetrics:input_type -> telemetry.GetDeviceMetricsRequest
-	3, // 7: telemetry.TelemetryService.GetTelemetry:output_type -> telemetry.GetTelemetryResponse
-	5, // 8: telemetry.TelemetryService.GetTelemetryAggregated:output_type -> telemetry.GetTelemetryAggregatedResponse
-	7, // 9: telemetry.TelemetryService.GetLatestMetric:output_type -> telemetry.GetLatestMetricResponse
-	9, // 10: telemetry.TelemetryService.GetDeviceMetrics:output_type -> telemetry.GetDeviceMetricsResponse
-	7, // [7:11] is the sub-list for method output_type
-	3, // [3:7] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	3,  // 0: telemetry.GetTelemetryResponse.points:type_name -> telemetry.TelemetryPoint
+	4,  // 1: telemetry.GetTelemetryAggregatedResponse.aggregations:type_name -> telemetry.TelemetryAggregation
+	3,  // 2: telemetry.GetLatestMetricResponse.point:type_name -> telemetry.TelemetryPoint
+	12, // 3: telemetry.GetDeviceMetricsResponse.catalog:type_name -> telemetry.MetricInfo
+	43, // 4: telemetry.DeviceFilter.metadata:type_name -> telemetry.DeviceFilter.MetadataEntry
+	14, // 5: telemetry.GetTelemetryBatchRequest.filter:type_name -> telemetry.DeviceFilter
+	0,  // 6: telemetry.GetTelemetryBatchRequest.group_by:type_name -> telemetry.TelemetryGroupBy
+	4,  // 7: telemetry.TelemetryBatchSeries.aggregations:type_name -> telemetry.TelemetryAggregation
+	16, // 8: telemetry.GetTelemetryBatchResponse.series:type_name -> telemetry.TelemetryBatchSeries
+	19, // 9: telemetry.ExportTelemetryChunk.records:type_name -> telemetry.TelemetryRecord
+	19, // 10: telemetry.StreamTelemetryEvent.record:type_name -> telemetry.TelemetryRecord
+	19, // 11: telemetry.ImportTelemetryRequest.records:type_name -> telemetry.TelemetryRecord
+	1,  // 12: telemetry.ImportTelemetryRequest.on_conflict:type_name -> telemetry.ImportConflictPolicy
+	25, // 13: telemetry.ListRetentionPoliciesResponse.policies:type_name -> telemetry.RetentionPolicy
+	25, // 14: telemetry.UpsertRetentionPolicyRequest.policy:type_name -> telemetry.RetentionPolicy
+	25, // 15: telemetry.RetentionPolicyResult.policy:type_name -> telemetry.RetentionPolicy
+	32, // 16: telemetry.ApplyRetentionResponse.results:type_name -> telemetry.RetentionPolicyResult
+	2,  // 17: telemetry.DerivedMetric.kind:type_name -> telemetry.DerivedMetricKind
+	34, // 18: telemetry.ListDerivedMetricsResponse.metrics:type_name -> telemetry.DerivedMetric
+	34, // 19: telemetry.UpsertDerivedMetricRequest.metric:type_name -> telemetry.DerivedMetric
+	40, // 20: telemetry.GetAnomaliesResponse.anomalies:type_name -> telemetry.Anomaly
+	5,  // 21: telemetry.TelemetryService.GetTelemetry:input_type -> telemetry.GetTelemetryRequest
+	7,  // 22: telemetry.TelemetryService.GetTelemetryAggregated:input_type -> telemetry.GetTelemetryAggregatedRequest
+	9,  // 23: telemetry.TelemetryService.GetLatestMetric:input_type -> telemetry.GetLatestMetricRequest
+	11, // 24: telemetry.TelemetryService.GetDeviceMetrics:input_type -> telemetry.GetDeviceMetricsRequest
+	15, // 25: telemetry.TelemetryService.GetTelemetryBatch:input_type -> telemetry.GetTelemetryBatchRequest
+	18, // 26: telemetry.TelemetryService.ExportTelemetry:input_type -> telemetry.ExportTelemetryRequest
+	23, // 27: telemetry.TelemetryService.ImportTelemetry:input_type -> telemetry.ImportTelemetryRequest
+	21, // 28: telemetry.TelemetryService.StreamTelemetry:input_type -> telemetry.StreamTelemetryRequest
+	26, // 29: telemetry.TelemetryService.ListRetentionPolicies:input_type -> telemetry.ListRetentionPoliciesRequest
+	28, // 30: telemetry.TelemetryService.UpsertRetentionPolicy:input_type -> telemetry.UpsertRetentionPolicyRequest
+	29, // 31: telemetry.TelemetryService.DeleteRetentionPolicy:input_type -> telemetry.DeleteRetentionPolicyRequest
+	31, // 32: telemetry.TelemetryService.ApplyRetention:input_type -> telemetry.ApplyRetentionRequest
+	35, // 33: telemetry.TelemetryService.ListDerivedMetrics:input_type -> telemetry.ListDerivedMetricsRequest
+	37, // 34: telemetry.TelemetryService.UpsertDerivedMetric:input_type -> telemetry.UpsertDerivedMetricRequest
+	38, // 35: telemetry.TelemetryService.DeleteDerivedMetric:input_type -> telemetry.DeleteDerivedMetricRequest
+	41, // 36: telemetry.TelemetryService.GetAnomalies:input_type -> telemetry.GetAnomaliesRequest
+	6,  // 37: telemetry.TelemetryService.GetTelemetry:output_type -> telemetry.GetTelemetryResponse
+	8,  // 38: telemetry.TelemetryService.GetTelemetryAggregated:output_type -> telemetry.GetTelemetryAggregatedResponse
+	10, // 39: telemetry.TelemetryService.GetLatestMetric:output_type -> telemetry.GetLatestMetricResponse
+	13, // 40: telemetry.TelemetryService.GetDeviceMetrics:output_type -> telemetry.GetDeviceMetricsResponse
+	17, // 41: telemetry.TelemetryService.GetTelemetryBatch:output_type -> telemetry.GetTelemetryBatchResponse
+	20, // 42: telemetry.TelemetryService.ExportTelemetry:output_type -> telemetry.ExportTelemetryChunk
+	24, // 43: telemetry.TelemetryService.ImportTelemetry:output_type -> telemetry.ImportTelemetryResponse
+	22, // 44: telemetry.TelemetryService.StreamTelemetry:output_type -> telemetry.StreamTelemetryEvent
+	27, // 45: telemetry.TelemetryService.ListRetentionPolicies:output_type -> telemetry.ListRetentionPoliciesResponse
+	25, // 46: telemetry.TelemetryService.UpsertRetentionPolicy:output_type -> telemetry.RetentionPolicy
+	30, // 47: telemetry.TelemetryService.DeleteRetentionPolicy:output_type -> telemetry.DeleteRetentionPolicyResponse
+	33, // 48: telemetry.TelemetryService.ApplyRetention:output_type -> telemetry.ApplyRetentionResponse
+	36, // 49: telemetry.TelemetryService.ListDerivedMetrics:output_type -> telemetry.ListDerivedMetricsResponse
+	34, // 50: telemetry.TelemetryService.UpsertDerivedMetric:output_type -> telemetry.DerivedMetric
+	39, // 51: telemetry.TelemetryService.DeleteDerivedMetric:output_type -> telemetry.DeleteDerivedMetricResponse
+	42, // 52: telemetry.TelemetryService.GetAnomalies:output_type -> telemetry.GetAnomaliesResponse
+	37, // [37:53] is the sub-list for method output_type
+	21, // [21:37] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
 }
 
 func init() { file_telemetry_telemetry_proto_init() }
@@ -693,18 +3160,20 @@ func file_telemetry_telemetry_proto_init() {
 	if File_telemetry_telemetry_proto != nil {
 		return
 	}
+	file_telemetry_telemetry_proto_msgTypes[9].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_telemetry_telemetry_proto_rawDesc), len(file_telemetry_telemetry_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   10,
+			NumEnums:      3,
+			NumMessages:   41,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_telemetry_telemetry_proto_goTypes,
 		DependencyIndexes: file_telemetry_telemetry_proto_depIdxs,
+		EnumInfos:         file_telemetry_telemetry_proto_enumTypes,
 		MessageInfos:      file_telemetry_telemetry_proto_msgTypes,
 	}.Build()
 	File_telemetry_telemetry_proto = out.File
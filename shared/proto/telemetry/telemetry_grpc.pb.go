@@ -27,6 +27,18 @@ const (
 	TelemetryService_GetTelemetryAggregated_FullMethodName = "/telemetry.TelemetryService/GetTelemetryAggregated"
 	TelemetryService_GetLatestMetric_FullMethodName        = "/telemetry.TelemetryService/GetLatestMetric"
 	TelemetryService_GetDeviceMetrics_FullMethodName       = "/telemetry.TelemetryService/GetDeviceMetrics"
+	TelemetryService_GetTelemetryBatch_FullMethodName      = "/telemetry.TelemetryService/GetTelemetryBatch"
+	TelemetryService_ExportTelemetry_FullMethodName        = "/telemetry.TelemetryService/ExportTelemetry"
+	TelemetryService_ImportTelemetry_FullMethodName        = "/telemetry.TelemetryService/ImportTelemetry"
+	TelemetryService_StreamTelemetry_FullMethodName        = "/telemetry.TelemetryService/StreamTelemetry"
+	TelemetryService_ListRetentionPolicies_FullMethodName  = "/telemetry.TelemetryService/ListRetentionPolicies"
+	TelemetryService_UpsertRetentionPolicy_FullMethodName  = "/telemetry.TelemetryService/UpsertRetentionPolicy"
+	TelemetryService_DeleteRetentionPolicy_FullMethodName  = "/telemetry.TelemetryService/DeleteRetentionPolicy"
+	TelemetryService_ApplyRetention_FullMethodName         = "/telemetry.TelemetryService/ApplyRetention"
+	TelemetryService_ListDerivedMetrics_FullMethodName     = "/telemetry.TelemetryService/ListDerivedMetrics"
+	TelemetryService_UpsertDerivedMetric_FullMethodName    = "/telemetry.TelemetryService/UpsertDerivedMetric"
+	TelemetryService_DeleteDerivedMetric_FullMethodName    = "/telemetry.TelemetryService/DeleteDerivedMetric"
+	TelemetryService_GetAnomalies_FullMethodName           = "/telemetry.TelemetryService/GetAnomalies"
 )
 
 // TelemetryServiceClient is the client API for TelemetryService service.
@@ -43,6 +55,30 @@ type TelemetryServiceClient interface {
 	GetLatestMetric(ctx context.Context, in *GetLatestMetricRequest, opts ...grpc.CallOption) (*GetLatestMetricResponse, error)
 	// Get all available metrics for a device
 	GetDeviceMetrics(ctx context.Context, in *GetDeviceMetricsRequest, opts ...grpc.CallOption) (*GetDeviceMetricsResponse, error)
+	// Get aggregated telemetry for several devices and metrics in one call
+	GetTelemetryBatch(ctx context.Context, in *GetTelemetryBatchRequest, opts ...grpc.CallOption) (*GetTelemetryBatchResponse, error)
+	// Export raw telemetry ordered by time, paged through a server-side cursor
+	ExportTelemetry(ctx context.Context, in *ExportTelemetryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportTelemetryChunk], error)
+	// Bulk-load historical telemetry (not published to live subscribers)
+	ImportTelemetry(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportTelemetryRequest, ImportTelemetryResponse], error)
+	// Stream live telemetry as it is ingested, optionally replaying history first
+	StreamTelemetry(ctx context.Context, in *StreamTelemetryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamTelemetryEvent], error)
+	// List retention policies
+	ListRetentionPolicies(ctx context.Context, in *ListRetentionPoliciesRequest, opts ...grpc.CallOption) (*ListRetentionPoliciesResponse, error)
+	// Create or replace a retention policy
+	UpsertRetentionPolicy(ctx context.Context, in *UpsertRetentionPolicyRequest, opts ...grpc.CallOption) (*RetentionPolicy, error)
+	// Delete a retention policy (the default policy cannot be deleted)
+	DeleteRetentionPolicy(ctx context.Context, in *DeleteRetentionPolicyRequest, opts ...grpc.CallOption) (*DeleteRetentionPolicyResponse, error)
+	// Enforce retention policies now, or count affected rows with dry_run
+	ApplyRetention(ctx context.Context, in *ApplyRetentionRequest, opts ...grpc.CallOption) (*ApplyRetentionResponse, error)
+	// List derived metric definitions
+	ListDerivedMetrics(ctx context.Context, in *ListDerivedMetricsRequest, opts ...grpc.CallOption) (*ListDerivedMetricsResponse, error)
+	// Create or replace a derived metric definition
+	UpsertDerivedMetric(ctx context.Context, in *UpsertDerivedMetricRequest, opts ...grpc.CallOption) (*DerivedMetric, error)
+	// Delete a derived metric definition (stored values are kept)
+	DeleteDerivedMetric(ctx context.Context, in *DeleteDerivedMetricRequest, opts ...grpc.CallOption) (*DeleteDerivedMetricResponse, error)
+	// Get anomalies detected at ingest time
+	GetAnomalies(ctx context.Context, in *GetAnomaliesRequest, opts ...grpc.CallOption) (*GetAnomaliesResponse, error)
 }
 
 type telemetryServiceClient struct {
@@ -93,6 +129,147 @@ func (c *telemetryServiceClient) GetDeviceMetrics(ctx context.Context, in *GetDe
 	return out, nil
 }
 
+func (c *telemetryServiceClient) GetTelemetryBatch(ctx context.Context, in *GetTelemetryBatchRequest, opts ...grpc.CallOption) (*GetTelemetryBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTelemetryBatchResponse)
+	err := c.cc.Invoke(ctx, TelemetryService_GetTelemetryBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *telemetryServiceClient) ExportTelemetry(ctx context.Context, in *ExportTelemetryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportTelemetryChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TelemetryService_ServiceDesc.Streams[0], TelemetryService_ExportTelemetry_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportTelemetryRequest, ExportTelemetryChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TelemetryService_ExportTelemetryClient = grpc.ServerStreamingClient[ExportTelemetryChunk]
+
+func (c *telemetryServiceClient) ImportTelemetry(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportTelemetryRequest, ImportTelemetryResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TelemetryService_ServiceDesc.Streams[1], TelemetryService_ImportTelemetry_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ImportTelemetryRequest, ImportTelemetryResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TelemetryService_ImportTelemetryClient = grpc.ClientStreamingClient[ImportTelemetryRequest, ImportTelemetryResponse]
+
+func (c *telemetryServiceClient) StreamTelemetry(ctx context.Context, in *StreamTelemetryRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StreamTelemetryEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TelemetryService_ServiceDesc.Streams[2], TelemetryService_StreamTelemetry_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamTelemetryRequest, StreamTelemetryEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TelemetryService_StreamTelemetryClient = grpc.ServerStreamingClient[StreamTelemetryEvent]
+
+func (c *telemetryServiceClient) ListRetentionPolicies(ctx context.Context, in *ListRetentionPoliciesRequest, opts ...grpc.CallOption) (*ListRetentionPoliciesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRetentionPoliciesResponse)
+	err := c.cc.Invoke(ctx, TelemetryService_ListRetentionPolicies_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *telemetryServiceClient) UpsertRetentionPolicy(ctx context.Context, in *UpsertRetentionPolicyRequest, opts ...grpc.CallOption) (*RetentionPolicy, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RetentionPolicy)
+	err := c.cc.Invoke(ctx, TelemetryService_UpsertRetentionPolicy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *telemetryServiceClient) DeleteRetentionPolicy(ctx context.Context, in *DeleteRetentionPolicyRequest, opts ...grpc.CallOption) (*DeleteRetentionPolicyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteRetentionPolicyResponse)
+	err := c.cc.Invoke(ctx, TelemetryService_DeleteRetentionPolicy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *telemetryServiceClient) ApplyRetention(ctx context.Context, in *ApplyRetentionRequest, opts ...grpc.CallOption) (*ApplyRetentionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ApplyRetentionResponse)
+	err := c.cc.Invoke(ctx, TelemetryService_ApplyRetention_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *telemetryServiceClient) ListDerivedMetrics(ctx context.Context, in *ListDerivedMetricsRequest, opts ...grpc.CallOption) (*ListDerivedMetricsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDerivedMetricsResponse)
+	err := c.cc.Invoke(ctx, TelemetryService_ListDerivedMetrics_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *telemetryServiceClient) UpsertDerivedMetric(ctx context.Context, in *UpsertDerivedMetricRequest, opts ...grpc.CallOption) (*DerivedMetric, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DerivedMetric)
+	err := c.cc.Invoke(ctx, TelemetryService_UpsertDerivedMetric_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *telemetryServiceClient) DeleteDerivedMetric(ctx context.Context, in *DeleteDerivedMetricRequest, opts ...grpc.CallOption) (*DeleteDerivedMetricResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteDerivedMetricResponse)
+	err := c.cc.Invoke(ctx, TelemetryService_DeleteDerivedMetric_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *telemetryServiceClient) GetAnomalies(ctx context.Context, in *GetAnomaliesRequest, opts ...grpc.CallOption) (*GetAnomaliesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAnomaliesResponse)
+	err := c.cc.Invoke(ctx, TelemetryService_GetAnomalies_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TelemetryServiceServer is the server API for TelemetryService service.
 // All implementations must embed UnimplementedTelemetryServiceServer
 // for forward compatibility.
@@ -107,6 +284,30 @@ type TelemetryServiceServer interface {
 	GetLatestMetric(context.Context, *GetLatestMetricRequest) (*GetLatestMetricResponse, error)
 	// Get all available metrics for a device
 	GetDeviceMetrics(context.Context, *GetDeviceMetricsRequest) (*GetDeviceMetricsResponse, error)
+	// Get aggregated telemetry for several devices and metrics in one call
+	GetTelemetryBatch(context.Context, *GetTelemetryBatchRequest) (*GetTelemetryBatchResponse, error)
+	// Export raw telemetry ordered by time, paged through a server-side cursor
+	ExportTelemetry(*ExportTelemetryRequest, grpc.ServerStreamingServer[ExportTelemetryChunk]) error
+	// Bulk-load historical telemetry (not published to live subscribers)
+	ImportTelemetry(grpc.ClientStreamingServer[ImportTelemetryRequest, ImportTelemetryResponse]) error
+	// Stream live telemetry as it is ingested, optionally replaying history first
+	StreamTelemetry(*StreamTelemetryRequest, grpc.ServerStreamingServer[StreamTelemetryEvent]) error
+	// List retention policies
+	ListRetentionPolicies(context.Context, *ListRetentionPoliciesRequest) (*ListRetentionPoliciesResponse, error)
+	// Create or replace a retention policy
+	UpsertRetentionPolicy(context.Context, *UpsertRetentionPolicyRequest) (*RetentionPolicy, error)
+	// Delete a retention policy (the default policy cannot be deleted)
+	DeleteRetentionPolicy(context.Context, *DeleteRetentionPolicyRequest) (*DeleteRetentionPolicyResponse, error)
+	// Enforce retention policies now, or count affected rows with dry_run
+	ApplyRetention(context.Context, *ApplyRetentionRequest) (*ApplyRetentionResponse, error)
+	// List derived metric definitions
+	ListDerivedMetrics(context.Context, *ListDerivedMetricsRequest) (*ListDerivedMetricsResponse, error)
+	// Create or replace a derived metric definition
+	UpsertDerivedMetric(context.Context, *UpsertDerivedMetricRequest) (*DerivedMetric, error)
+	// Delete a derived metric definition (stored values are kept)
+	DeleteDerivedMetric(context.Context, *DeleteDerivedMetricRequest) (*DeleteDerivedMetricResponse, error)
+	// Get anomalies detected at ingest time
+	GetAnomalies(context.Context, *GetAnomaliesRequest) (*GetAnomaliesResponse, error)
 	mustEmbedUnimplementedTelemetryServiceServer()
 }
 
@@ -129,6 +330,42 @@ func (UnimplementedTelemetryServiceServer) GetLatestMetric(context.Context, *Get
 func (UnimplementedTelemetryServiceServer) GetDeviceMetrics(context.Context, *GetDeviceMetricsRequest) (*GetDeviceMetricsResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetDeviceMetrics not implemented")
 }
+func (UnimplementedTelemetryServiceServer) GetTelemetryBatch(context.Context, *GetTelemetryBatchRequest) (*GetTelemetryBatchResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTelemetryBatch not implemented")
+}
+func (UnimplementedTelemetryServiceServer) ExportTelemetry(*ExportTelemetryRequest, grpc.ServerStreamingServer[ExportTelemetryChunk]) error {
+	return status.Error(codes.Unimplemented, "method ExportTelemetry not implemented")
+}
+func (UnimplementedTelemetryServiceServer) ImportTelemetry(grpc.ClientStreamingServer[ImportTelemetryRequest, ImportTelemetryResponse]) error {
+	return status.Error(codes.Unimplemented, "method ImportTelemetry not implemented")
+}
+func (UnimplementedTelemetryServiceServer) StreamTelemetry(*StreamTelemetryRequest, grpc.ServerStreamingServer[StreamTelemetryEvent]) error {
+	return status.Error(codes.Unimplemented, "method StreamTelemetry not implemented")
+}
+func (UnimplementedTelemetryServiceServer) ListRetentionPolicies(context.Context, *ListRetentionPoliciesRequest) (*ListRetentionPoliciesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListRetentionPolicies not implemented")
+}
+func (UnimplementedTelemetryServiceServer) UpsertRetentionPolicy(context.Context, *UpsertRetentionPolicyRequest) (*RetentionPolicy, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpsertRetentionPolicy not implemented")
+}
+func (UnimplementedTelemetryServiceServer) DeleteRetentionPolicy(context.Context, *DeleteRetentionPolicyRequest) (*DeleteRetentionPolicyResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteRetentionPolicy not implemented")
+}
+func (UnimplementedTelemetryServiceServer) ApplyRetention(context.Context, *ApplyRetentionRequest) (*ApplyRetentionResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ApplyRetention not implemented")
+}
+func (UnimplementedTelemetryServiceServer) ListDerivedMetrics(context.Context, *ListDerivedMetricsRequest) (*ListDerivedMetricsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListDerivedMetrics not implemented")
+}
+func (UnimplementedTelemetryServiceServer) UpsertDerivedMetric(context.Context, *UpsertDerivedMetricRequest) (*DerivedMetric, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpsertDerivedMetric not implemented")
+}
+func (UnimplementedTelemetryServiceServer) DeleteDerivedMetric(context.Context, *DeleteDerivedMetricRequest) (*DeleteDerivedMetricResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteDerivedMetric not implemented")
+}
+func (UnimplementedTelemetryServiceServer) GetAnomalies(context.Context, *GetAnomaliesRequest) (*GetAnomaliesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetAnomalies not implemented")
+}
 func (UnimplementedTelemetryServiceServer) mustEmbedUnimplementedTelemetryServiceServer() {}
 func (UnimplementedTelemetryServiceServer) testEmbeddedByValue()                          {}
 
@@ -222,6 +459,197 @@ func _TelemetryService_GetDeviceMetrics_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TelemetryService_GetTelemetryBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTelemetryBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TelemetryServiceServer).GetTelemetryBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TelemetryService_GetTelemetryBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TelemetryServiceServer).GetTelemetryBatch(ctx, req.(*GetTelemetryBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TelemetryService_ExportTelemetry_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportTelemetryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TelemetryServiceServer).ExportTelemetry(m, &grpc.GenericServerStream[ExportTelemetryRequest, ExportTelemetryChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TelemetryService_ExportTelemetryServer = grpc.ServerStreamingServer[ExportTelemetryChunk]
+
+func _TelemetryService_ImportTelemetry_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TelemetryServiceServer).ImportTelemetry(&grpc.GenericServerStream[ImportTelemetryRequest, ImportTelemetryResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TelemetryService_ImportTelemetryServer = grpc.ClientStreamingServer[ImportTelemetryRequest, ImportTelemetryResponse]
+
+func _TelemetryService_StreamTelemetry_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamTelemetryRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TelemetryServiceServer).StreamTelemetry(m, &grpc.GenericServerStream[StreamTelemetryRequest, StreamTelemetryEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TelemetryService_StreamTelemetryServer = grpc.ServerStreamingServer[StreamTelemetryEvent]
+
+func _TelemetryService_ListRetentionPolicies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRetentionPoliciesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TelemetryServiceServer).ListRetentionPolicies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TelemetryService_ListRetentionPolicies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TelemetryServiceServer).ListRetentionPolicies(ctx, req.(*ListRetentionPoliciesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TelemetryService_UpsertRetentionPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpsertRetentionPolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TelemetryServiceServer).UpsertRetentionPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TelemetryService_UpsertRetentionPolicy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TelemetryServiceServer).UpsertRetentionPolicy(ctx, req.(*UpsertRetentionPolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TelemetryService_DeleteRetentionPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRetentionPolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TelemetryServiceServer).DeleteRetentionPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TelemetryService_DeleteRetentionPolicy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TelemetryServiceServer).DeleteRetentionPolicy(ctx, req.(*DeleteRetentionPolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TelemetryService_ApplyRetention_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApplyRetentionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TelemetryServiceServer).ApplyRetention(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TelemetryService_ApplyRetention_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TelemetryServiceServer).ApplyRetention(ctx, req.(*ApplyRetentionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TelemetryService_ListDerivedMetrics_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDerivedMetricsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TelemetryServiceServer).ListDerivedMetrics(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TelemetryService_ListDerivedMetrics_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TelemetryServiceServer).ListDerivedMetrics(ctx, req.(*ListDerivedMetricsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TelemetryService_UpsertDerivedMetric_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpsertDerivedMetricRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TelemetryServiceServer).UpsertDerivedMetric(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TelemetryService_UpsertDerivedMetric_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TelemetryServiceServer).UpsertDerivedMetric(ctx, req.(*UpsertDerivedMetricRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TelemetryService_DeleteDerivedMetric_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteDerivedMetricRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TelemetryServiceServer).DeleteDerivedMetric(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TelemetryService_DeleteDerivedMetric_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TelemetryServiceServer).DeleteDerivedMetric(ctx, req.(*DeleteDerivedMetricRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TelemetryService_GetAnomalies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAnomaliesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TelemetryServiceServer).GetAnomalies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TelemetryService_GetAnomalies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TelemetryServiceServer).GetAnomalies(ctx, req.(*GetAnomaliesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // TelemetryService_ServiceDesc is the grpc.ServiceDesc for TelemetryService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -245,7 +673,59 @@ var TelemetryService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetDeviceMetrics",
 			Handler:    _TelemetryService_GetDeviceMetrics_Handler,
 		},
+		{
+			MethodName: "GetTelemetryBatch",
+			Handler:    _TelemetryService_GetTelemetryBatch_Handler,
+		},
+		{
+			MethodName: "ListRetentionPolicies",
+			Handler:    _TelemetryService_ListRetentionPolicies_Handler,
+		},
+		{
+			MethodName: "UpsertRetentionPolicy",
+			Handler:    _TelemetryService_UpsertRetentionPolicy_Handler,
+		},
+		{
+			MethodName: "DeleteRetentionPolicy",
+			Handler:    _TelemetryService_DeleteRetentionPolicy_Handler,
+		},
+		{
+			MethodName: "ApplyRetention",
+			Handler:    _TelemetryService_ApplyRetention_Handler,
+		},
+		{
+			MethodName: "ListDerivedMetrics",
+			Handler:    _TelemetryService_ListDerivedMetrics_Handler,
+		},
+		{
+			MethodName: "UpsertDerivedMetric",
+			Handler:    _TelemetryService_UpsertDerivedMetric_Handler,
+		},
+		{
+			MethodName: "DeleteDerivedMetric",
+			Handler:    _TelemetryService_DeleteDerivedMetric_Handler,
+		},
+		{
+			MethodName: "GetAnomalies",
+			Handler:    _TelemetryService_GetAnomalies_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportTelemetry",
+			Handler:       _TelemetryService_ExportTelemetry_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ImportTelemetry",
+			Handler:       _TelemetryService_ImportTelemetry_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "StreamTelemetry",
+			Handler:       _TelemetryService_StreamTelemetry_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "telemetry/telemetry.proto",
 }
@@ -93,6 +93,7 @@ func TestE2E_UserRegistrationAndLogin(t *testing.T) {
 	})
 
 	var jwtToken string
+	var refreshToken string
 
 	t.Run("login_with_valid_credentials", func(t *testing.T) {
 		mutation := map[string]interface{}{
@@ -100,6 +101,7 @@ func TestE2E_UserRegistrationAndLogin(t *testing.T) {
 				mutation Login($input: LoginInput!) {
 					login(input: $input) {
 						token
+						refreshToken
 						user {
 							id
 							email
@@ -129,6 +131,10 @@ func TestE2E_UserRegistrationAndLogin(t *testing.T) {
 		}
 
 		jwtToken = token
+		refreshToken, _ = login["refreshToken"].(string)
+		if refreshToken == "" {
+			t.Fatalf("Refresh token not returned: %+v", login)
+		}
 
 		// Verify user data
 		user := login["user"].(map[string]interface{})
@@ -139,6 +145,35 @@ func TestE2E_UserRegistrationAndLogin(t *testing.T) {
 		t.Logf("✓ Login successful, token received: %s...", token[:20])
 	})
 
+	t.Run("refresh_token_rotation_and_reuse", func(t *testing.T) {
+		refresh := func(token string) map[string]interface{} {
+			return graphqlRequest(t, client, gatewayURL, map[string]interface{}{
+				"query":     `mutation Refresh($token: String!) { refreshToken(refreshToken: $token) { token refreshToken } }`,
+				"variables": map[string]interface{}{"token": token},
+			}, "")
+		}
+
+		resp := refresh(refreshToken)
+		data, ok := resp["data"].(map[string]interface{})
+		if !ok || data["refreshToken"] == nil {
+			t.Fatalf("Refresh failed: %+v", resp)
+		}
+		rotated := data["refreshToken"].(map[string]interface{})["refreshToken"].(string)
+		if rotated == refreshToken {
+			t.Error("Refresh token was not rotated")
+		}
+
+		// Replaying the first token revokes the session, rotated token included
+		if resp := refresh(refreshToken); resp["errors"] == nil {
+			t.Fatalf("Expected reuse to be rejected, got: %+v", resp)
+		}
+		if resp := refresh(rotated); resp["errors"] == nil {
+			t.Fatalf("Expected session to be revoked after reuse, got: %+v", resp)
+		}
+
+		t.Logf("✓ Refresh token rotated, reuse revoked the session")
+	})
+
 	t.Run("login_with_invalid_password", func(t *testing.T) {
 		mutation := map[string]interface{}{
 			"query": `
@@ -456,3 +456,192 @@ func TestE2E_AdminUserManagement(t *testing.T) {
 		t.Logf("✓ Admin listed %d users", len(users))
 	})
 }
+
+// TestE2E_CustomRoles tests that custom roles grant their permissions, picked
+// up by tokens on refresh.
+func TestE2E_CustomRoles(t *testing.T) {
+	env := SetupE2EEnvironment(t)
+
+	client := &http.Client{}
+	gatewayURL := "http://" + env.APIGatewayAddr + "/query"
+
+	gql := func(t *testing.T, query string, variables map[string]interface{}, token string) map[string]interface{} {
+		t.Helper()
+		return graphqlRequest(t, client, gatewayURL, map[string]interface{}{"query": query, "variables": variables}, token)
+	}
+	hasErrors := func(resp map[string]interface{}) bool {
+		errors, ok := resp["errors"].([]interface{})
+		return ok && len(errors) > 0
+	}
+
+	// Unique names per test run, roles are not truncated between runs
+	uniqueID := time.Now().UnixNano()
+	roleName := fmt.Sprintf("viewer-%d", uniqueID)
+	viewerEmail := fmt.Sprintf("viewer-%d@example.com", uniqueID)
+
+	var adminToken, viewerToken, viewerRefreshToken, viewerID string
+
+	loginMutation := `
+		mutation Login($input: LoginInput!) {
+			login(input: $input) { token refreshToken user { id } }
+		}
+	`
+
+	// The admin bootstrapped by the User Service
+	t.Run("login_admin", func(t *testing.T) {
+		resp := gql(t, loginMutation, map[string]interface{}{
+			"input": map[string]interface{}{"email": "admin@iot.local", "password": "admin"},
+		}, "")
+		data, ok := resp["data"].(map[string]interface{})
+		if !ok || hasErrors(resp) {
+			t.Fatalf("Admin login failed: %+v", resp)
+		}
+		adminToken = data["login"].(map[string]interface{})["token"].(string)
+	})
+
+	t.Run("create_role", func(t *testing.T) {
+		resp := gql(t, `
+			mutation UpsertRole($input: RoleInput!) {
+				upsertRole(input: $input) { name permissions builtIn }
+			}
+		`, map[string]interface{}{
+			"input": map[string]interface{}{
+				"name":        roleName,
+				"description": "Read-only access",
+				"permissions": []string{"devices:read", "telemetry:read"},
+			},
+		}, adminToken)
+		if hasErrors(resp) {
+			t.Fatalf("upsertRole failed: %+v", resp)
+		}
+
+		resp = gql(t, `
+			mutation UpsertRole($input: RoleInput!) {
+				upsertRole(input: $input) { name }
+			}
+		`, map[string]interface{}{
+			"input": map[string]interface{}{"name": roleName + "-bad", "permissions": []string{"devices:delete"}},
+		}, adminToken)
+		if !hasErrors(resp) {
+			t.Fatalf("Expected an unknown permission to be rejected, got: %+v", resp)
+		}
+	})
+
+	t.Run("register_viewer", func(t *testing.T) {
+		resp := gql(t, `
+			mutation Register($input: RegisterInput!) {
+				register(input: $input) { user { id role permissions } }
+			}
+		`, map[string]interface{}{
+			"input": map[string]interface{}{
+				"email":    viewerEmail,
+				"password": "ViewerPass123!",
+				"name":     "Viewer",
+				"role":     roleName,
+			},
+		}, adminToken)
+		data, ok := resp["data"].(map[string]interface{})
+		if !ok || hasErrors(resp) {
+			t.Fatalf("register failed: %+v", resp)
+		}
+		user := data["register"].(map[string]interface{})["user"].(map[string]interface{})
+		viewerID = user["id"].(string)
+		if permissions := user["permissions"].([]interface{}); len(permissions) != 2 {
+			t.Errorf("Expected 2 permissions, got: %v", permissions)
+		}
+
+		resp = gql(t, loginMutation, map[string]interface{}{
+			"input": map[string]interface{}{"email": viewerEmail, "password": "ViewerPass123!"},
+		}, "")
+		data, ok = resp["data"].(map[string]interface{})
+		if !ok || hasErrors(resp) {
+			t.Fatalf("Viewer login failed: %+v", resp)
+		}
+		login := data["login"].(map[string]interface{})
+		viewerToken = login["token"].(string)
+		viewerRefreshToken = login["refreshToken"].(string)
+	})
+
+	createDevice := func(t *testing.T, token string) map[string]interface{} {
+		return gql(t, `
+			mutation CreateDevice($input: CreateDeviceInput!) {
+				createDevice(input: $input) { id }
+			}
+		`, map[string]interface{}{
+			"input": map[string]interface{}{"name": "Viewer Device", "type": "sensor"},
+		}, token)
+	}
+
+	t.Run("viewer_permissions", func(t *testing.T) {
+		if resp := gql(t, `query { devices(page: 1, pageSize: 10) { total } }`, nil, viewerToken); hasErrors(resp) {
+			t.Errorf("Expected viewer to list devices, got: %+v", resp)
+		}
+		if resp := createDevice(t, viewerToken); !hasErrors(resp) {
+			t.Errorf("Expected createDevice to be forbidden, got: %+v", resp)
+		}
+		if resp := gql(t, `query { users(page: 1, pageSize: 10) { total } }`, nil, viewerToken); !hasErrors(resp) {
+			t.Errorf("Expected users to be forbidden, got: %+v", resp)
+		}
+		if resp := gql(t, `query { roles { name } }`, nil, viewerToken); !hasErrors(resp) {
+			t.Errorf("Expected roles to be forbidden, got: %+v", resp)
+		}
+	})
+
+	t.Run("role_update_applies_on_refresh", func(t *testing.T) {
+		resp := gql(t, `
+			mutation UpsertRole($input: RoleInput!) {
+				upsertRole(input: $input) { name }
+			}
+		`, map[string]interface{}{
+			"input": map[string]interface{}{
+				"name":        roleName,
+				"permissions": []string{"devices:read", "devices:write", "telemetry:read"},
+			},
+		}, adminToken)
+		if hasErrors(resp) {
+			t.Fatalf("upsertRole failed: %+v", resp)
+		}
+
+		resp = gql(t, `
+			mutation RefreshToken($refreshToken: String!) {
+				refreshToken(refreshToken: $refreshToken) { token }
+			}
+		`, map[string]interface{}{"refreshToken": viewerRefreshToken}, "")
+		data, ok := resp["data"].(map[string]interface{})
+		if !ok || hasErrors(resp) {
+			t.Fatalf("refreshToken failed: %+v", resp)
+		}
+		viewerToken = data["refreshToken"].(map[string]interface{})["token"].(string)
+
+		if resp := createDevice(t, viewerToken); hasErrors(resp) {
+			t.Errorf("Expected createDevice to succeed after refresh, got: %+v", resp)
+		}
+	})
+
+	t.Run("delete_role", func(t *testing.T) {
+		deleteRole := `
+			mutation DeleteRole($name: String!) {
+				deleteRole(name: $name) { success }
+			}
+		`
+		if resp := gql(t, deleteRole, map[string]interface{}{"name": roleName}, adminToken); !hasErrors(resp) {
+			t.Fatalf("Expected an assigned role not to be deleted, got: %+v", resp)
+		}
+		if resp := gql(t, deleteRole, map[string]interface{}{"name": "admin"}, adminToken); !hasErrors(resp) {
+			t.Fatalf("Expected a built-in role not to be deleted, got: %+v", resp)
+		}
+
+		resp := gql(t, `
+			mutation UpdateUserRole($userId: ID!, $role: String!) {
+				updateUserRole(userId: $userId, role: $role) { role }
+			}
+		`, map[string]interface{}{"userId": viewerID, "role": "user"}, adminToken)
+		if hasErrors(resp) {
+			t.Fatalf("updateUserRole failed: %+v", resp)
+		}
+
+		if resp := gql(t, deleteRole, map[string]interface{}{"name": roleName}, adminToken); hasErrors(resp) {
+			t.Fatalf("deleteRole failed: %+v", resp)
+		}
+	})
+}